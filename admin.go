@@ -0,0 +1,216 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// AdminOptions configures CreateView, RenameCollection, DropCollection,
+// Compact, and RunCommand.
+type AdminOptions struct {
+	DB *mongo.Database
+
+	// DropTarget makes RenameCollection replace an existing collection of
+	// the new name instead of failing.
+	DropTarget bool
+}
+
+// CreateView creates a read-only view called name over the collection of
+// the source model, defined by an aggregation pipeline. It runs through the
+// middleware chain as an OpCreateView, with the pipeline as OpInfo.Filter.
+//
+// Example:
+//
+//	err := goodm.CreateView(ctx, "active_users", &User{}, bson.A{
+//	    bson.D{{Key: "$match", Value: bson.D{{Key: "active", Value: true}}}},
+//	})
+func CreateView(ctx context.Context, name string, source interface{}, pipeline interface{}, opts ...AdminOptions) error {
+	schema, err := getSchemaForModel(source)
+	if err != nil {
+		return err
+	}
+	var opt AdminOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpCreateView, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: source,
+		Filter: pipeline, Options: opt,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		if db.mem != nil {
+			return fmt.Errorf("goodm: create view %s: views are not supported by the in-memory test store", name)
+		}
+		if err := db.CreateView(ctx, name, schema.Collection, pipeline); err != nil {
+			return fmt.Errorf("goodm: create view %s failed: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// RenameCollection renames the collection of model to newName. The model
+// stays registered under its old collection name, so register it again
+// under the new name, or update its Register call, before using it. It runs
+// through the middleware chain as an OpRenameCollection.
+//
+// Example:
+//
+//	err := goodm.RenameCollection(ctx, &User{}, "users_archive_2024")
+func RenameCollection(ctx context.Context, model interface{}, newName string, opts ...AdminOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	var opt AdminOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpRenameCollection, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: model, Options: opt,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		if db.mem != nil {
+			if err := db.mem.Collection(schema.Collection).Rename(newName, opt.DropTarget); err != nil {
+				return fmt.Errorf("goodm: rename %s failed: %w", schema.Collection, err)
+			}
+			return nil
+		}
+
+		cmd := bson.D{
+			{Key: "renameCollection", Value: db.Name() + "." + schema.Collection},
+			{Key: "to", Value: db.Name() + "." + newName},
+			{Key: "dropTarget", Value: opt.DropTarget},
+		}
+		if err := db.Client().Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+			return fmt.Errorf("goodm: rename %s failed: %w", schema.Collection, err)
+		}
+		return nil
+	})
+}
+
+// DropCollection drops the collection of model, with its documents and
+// indexes. It runs through the middleware chain as an OpDropCollection.
+//
+// Example:
+//
+//	err := goodm.DropCollection(ctx, &User{})
+func DropCollection(ctx context.Context, model interface{}, opts ...AdminOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	var opt AdminOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpDropCollection, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: model, Options: opt,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		if db.mem != nil {
+			return db.mem.Collection(schema.Collection).Drop(ctx)
+		}
+		if err := db.Collection(schema.Collection).Drop(ctx); err != nil {
+			return fmt.Errorf("goodm: drop %s failed: %w", schema.Collection, err)
+		}
+		return nil
+	})
+}
+
+// Compact runs the compact command on the collection of model, releasing
+// unused disk space. It is a no-op on the in-memory test store. It runs
+// through the middleware chain as an OpCompact.
+//
+// Example:
+//
+//	err := goodm.Compact(ctx, &AuditEvent{})
+func Compact(ctx context.Context, model interface{}, opts ...AdminOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	var opt AdminOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpCompact, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: model, Options: opt,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		if db.mem != nil {
+			return nil
+		}
+		if err := db.RunCommand(ctx, bson.D{{Key: "compact", Value: schema.Collection}}).Err(); err != nil {
+			return fmt.Errorf("goodm: compact %s failed: %w", schema.Collection, err)
+		}
+		return nil
+	})
+}
+
+// RunCommand runs a database command and decodes its reply into result,
+// which may be nil to discard it. It runs through the middleware chain as an
+// OpRunCommand, with the command as OpInfo.Filter and no model, so audit
+// middleware sees commands issued by operational scripts.
+//
+// Example:
+//
+//	var stats bson.M
+//	err := goodm.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}, &stats)
+func RunCommand(ctx context.Context, cmd interface{}, result interface{}, opts ...AdminOptions) error {
+	var opt AdminOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpRunCommand, Filter: cmd,
+		Result: result, Options: opt,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		if db.mem != nil {
+			return fmt.Errorf("goodm: run command: commands are not supported by the in-memory test store")
+		}
+		res := db.RunCommand(ctx, cmd)
+		if err := res.Err(); err != nil {
+			return fmt.Errorf("goodm: run command failed: %w", err)
+		}
+		if result == nil {
+			return nil
+		}
+		if err := res.Decode(result); err != nil {
+			return fmt.Errorf("goodm: failed to decode command reply: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,103 @@
+package goodm
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestAdmin_TestStore(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+
+	var ops []OpType
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		ops = append(ops, op.Operation)
+		return next(ctx)
+	})
+
+	if err := Create(ctx, &testUser{Email: "admin@test.com", Name: "Admin"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := Compact(ctx, &testUser{}); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+
+	if err := RenameCollection(ctx, &testUser{}, "test_users_old"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if n, _ := activeTestStore().Collection("test_users_old").CountDocuments(ctx, bson.D{}); n != 1 {
+		t.Fatalf("renamed collection has %d documents, want 1", n)
+	}
+	if n, _ := activeTestStore().Collection("test_users").CountDocuments(ctx, bson.D{}); n != 0 {
+		t.Fatalf("old collection still has %d documents", n)
+	}
+
+	if err := Create(ctx, &testUser{Email: "admin2@test.com", Name: "Admin"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := RenameCollection(ctx, &testUser{}, "test_users_old"); err == nil {
+		t.Fatal("expected renaming onto an existing collection to fail")
+	}
+	if err := DropCollection(ctx, &testUser{}); err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	var users []testUser
+	if err := Find(ctx, bson.D{}, &users); err != nil || len(users) != 0 {
+		t.Fatalf("expected an empty collection after drop, got %d (%v)", len(users), err)
+	}
+
+	if err := RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}, nil); err == nil {
+		t.Error("expected RunCommand to fail on the test store")
+	}
+
+	want := []OpType{OpCreate, OpCompact, OpRenameCollection, OpCreate, OpRenameCollection, OpDropCollection, OpFind, OpRunCommand}
+	if len(ops) != len(want) {
+		t.Fatalf("middleware saw %v, want %v", ops, want)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Fatalf("middleware saw %v, want %v", ops, want)
+		}
+	}
+}
+
+func TestAdmin_Integration(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := Create(ctx, &testUser{Email: "view@test.com", Name: "View", Age: 40}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := Create(ctx, &testUser{Email: "young@test.com", Name: "Young", Age: 20}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	err := CreateView(ctx, "test_older_users", &testUser{}, bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "age", Value: bson.D{{Key: "$gte", Value: 30}}}}}},
+	})
+	if err != nil {
+		t.Fatalf("create view: %v", err)
+	}
+	n, err := db.Collection("test_older_users").CountDocuments(ctx, bson.D{})
+	if err != nil || n != 1 {
+		t.Fatalf("view has %d documents (%v), want 1", n, err)
+	}
+
+	var reply bson.M
+	if err := RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}, &reply); err != nil {
+		t.Fatalf("run command: %v", err)
+	}
+	if reply["ok"] == nil {
+		t.Errorf("unexpected ping reply: %v", reply)
+	}
+
+	if err := RenameCollection(ctx, &testUser{}, "test_users_renamed"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if n, _ := db.Collection("test_users_renamed").CountDocuments(ctx, bson.D{}); n != 2 {
+		t.Fatalf("renamed collection has %d documents, want 2", n)
+	}
+}
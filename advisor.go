@@ -0,0 +1,260 @@
+package goodm
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FilterShape describes the fields a query filter matches on, without values.
+type FilterShape struct {
+	Equality []string // fields matched by value (or $eq/$in), sorted
+	Range    []string // fields matched with other operators ($gt, $exists, ...), sorted
+}
+
+// key returns a stable identifier for the shape.
+func (s FilterShape) key() string {
+	return strings.Join(s.Equality, ",") + "|" + strings.Join(s.Range, ",")
+}
+
+// IndexSuggestion is a compound index the IndexAdvisor recommends.
+type IndexSuggestion struct {
+	ModelName  string
+	Collection string
+	Fields     []string // suggested key order: equality fields, then range fields
+	Seen       int      // number of observed queries with this shape
+}
+
+// IndexAdvisor records the shapes of query filters seen at runtime and
+// suggests compound indexes for shapes that no declared index serves. Install
+// its middleware to start recording.
+//
+// Example:
+//
+//	advisor := goodm.NewIndexAdvisor()
+//	goodm.Use(advisor.Middleware())
+//	// ... run the application or a load test ...
+//	for _, s := range advisor.Suggestions() {
+//	    log.Printf("%s: consider NewCompoundIndex(%v) (seen %d times)", s.ModelName, s.Fields, s.Seen)
+//	}
+type IndexAdvisor struct {
+	mu     sync.Mutex
+	shapes map[string]*observedShape
+}
+
+type observedShape struct {
+	modelName string
+	shape     FilterShape
+	seen      int
+}
+
+// NewIndexAdvisor creates an empty IndexAdvisor.
+func NewIndexAdvisor() *IndexAdvisor {
+	return &IndexAdvisor{shapes: make(map[string]*observedShape)}
+}
+
+// Middleware returns a MiddlewareFunc that records the filter of every find,
+// update, and delete operation before passing it on.
+func (a *IndexAdvisor) Middleware() MiddlewareFunc {
+	return func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		switch op.Operation {
+		case OpFind, OpUpdate, OpDelete, OpUpdateMany, OpDeleteMany:
+			a.Record(op.ModelName, op.Filter)
+		}
+		return next(ctx)
+	}
+}
+
+// Record adds one observation of filter for the named model. Filters that
+// cannot be encoded or match on no fields are ignored.
+func (a *IndexAdvisor) Record(modelName string, filter interface{}) {
+	shape, ok := filterShape(filter)
+	if !ok {
+		return
+	}
+	key := modelName + "#" + shape.key()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	obs, exists := a.shapes[key]
+	if !exists {
+		obs = &observedShape{modelName: modelName, shape: shape}
+		a.shapes[key] = obs
+	}
+	obs.seen++
+}
+
+// Reset discards all recorded observations.
+func (a *IndexAdvisor) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.shapes = make(map[string]*observedShape)
+}
+
+// Suggestions returns compound indexes for recorded filter shapes that are
+// not served by the model's declared indexes, most frequently seen first.
+// Shapes matching _id or a unique field by value are considered served.
+func (a *IndexAdvisor) Suggestions() []IndexSuggestion {
+	a.mu.Lock()
+	observed := make([]observedShape, 0, len(a.shapes))
+	for _, obs := range a.shapes {
+		observed = append(observed, *obs)
+	}
+	a.mu.Unlock()
+
+	var result []IndexSuggestion
+	for _, obs := range observed {
+		schema, ok := Get(obs.modelName)
+		if !ok || shapeIndexed(schema, obs.shape) {
+			continue
+		}
+		fields := append(append([]string{}, obs.shape.Equality...), obs.shape.Range...)
+		result = append(result, IndexSuggestion{
+			ModelName:  obs.modelName,
+			Collection: schema.Collection,
+			Fields:     fields,
+			Seen:       obs.seen,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Seen != result[j].Seen {
+			return result[i].Seen > result[j].Seen
+		}
+		if result[i].Collection != result[j].Collection {
+			return result[i].Collection < result[j].Collection
+		}
+		return strings.Join(result[i].Fields, ",") < strings.Join(result[j].Fields, ",")
+	})
+	return result
+}
+
+// filterShape extracts the top-level fields of a filter, flattening $and.
+// Returns false if the filter cannot be encoded or names no fields.
+func filterShape(filter interface{}) (FilterShape, bool) {
+	if filter == nil {
+		return FilterShape{}, false
+	}
+	raw, err := marshalBSON(filter)
+	if err != nil {
+		return FilterShape{}, false
+	}
+	var doc bson.D
+	if err := unmarshalBSON(raw, &doc); err != nil {
+		return FilterShape{}, false
+	}
+
+	eq := make(map[string]bool)
+	rng := make(map[string]bool)
+	collectShape(doc, eq, rng)
+
+	var shape FilterShape
+	for f := range eq {
+		shape.Equality = append(shape.Equality, f)
+	}
+	for f := range rng {
+		if !eq[f] {
+			shape.Range = append(shape.Range, f)
+		}
+	}
+	sort.Strings(shape.Equality)
+	sort.Strings(shape.Range)
+	return shape, len(shape.Equality)+len(shape.Range) > 0
+}
+
+// collectShape classifies the fields of a filter document as equality or
+// range matches. $and clauses are flattened; other logical operators are
+// skipped since a single index cannot serve them.
+func collectShape(doc bson.D, eq, rng map[string]bool) {
+	for _, e := range doc {
+		if e.Key == "$and" {
+			if clauses, ok := e.Value.(bson.A); ok {
+				for _, c := range clauses {
+					if cd, ok := c.(bson.D); ok {
+						collectShape(cd, eq, rng)
+					}
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(e.Key, "$") {
+			continue
+		}
+		if isRangeMatch(e.Value) {
+			rng[e.Key] = true
+		} else {
+			eq[e.Key] = true
+		}
+	}
+}
+
+// isRangeMatch reports whether a filter value is an operator expression other
+// than $eq or $in.
+func isRangeMatch(v interface{}) bool {
+	d, ok := v.(bson.D)
+	if !ok || len(d) == 0 || !strings.HasPrefix(d[0].Key, "$") {
+		return false
+	}
+	for _, op := range d {
+		if op.Key != "$eq" && op.Key != "$in" {
+			return true
+		}
+	}
+	return false
+}
+
+// shapeIndexed reports whether a declared index serves every field of shape,
+// or whether the shape matches _id or a unique field by value.
+func shapeIndexed(schema *Schema, shape FilterShape) bool {
+	for _, f := range shape.Equality {
+		if f == "_id" {
+			return true
+		}
+		if fs := schema.GetField(f); fs != nil && fs.Unique {
+			return true
+		}
+	}
+
+	want := make(map[string]bool)
+	for _, f := range shape.Equality {
+		want[f] = true
+	}
+	for _, f := range shape.Range {
+		want[f] = true
+	}
+
+	for _, keys := range declaredIndexKeys(schema) {
+		if len(keys) < len(want) {
+			continue
+		}
+		covered := true
+		for _, k := range keys[:len(want)] {
+			if !want[k] {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// declaredIndexKeys lists the key order of every index goodm declares for a
+// schema: single-field index/unique tags and compound indexes.
+func declaredIndexKeys(schema *Schema) [][]string {
+	var keys [][]string
+	for _, f := range schema.Fields {
+		if f.Index || f.Unique {
+			keys = append(keys, []string{f.BSONName})
+		}
+	}
+	for _, ci := range schema.CompoundIndexes {
+		keys = append(keys, ci.Fields)
+	}
+	return keys
+}
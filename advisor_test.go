@@ -0,0 +1,99 @@
+package goodm
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestFilterShape(t *testing.T) {
+	shape, ok := filterShape(bson.D{
+		{Key: "role", Value: "admin"},
+		{Key: "age", Value: bson.D{{Key: "$gte", Value: 18}}},
+		{Key: "$and", Value: bson.A{
+			bson.D{{Key: "name", Value: bson.D{{Key: "$in", Value: bson.A{"a", "b"}}}}},
+		}},
+		{Key: "$or", Value: bson.A{bson.D{{Key: "email", Value: "x"}}}},
+	})
+	if !ok {
+		t.Fatal("expected shape")
+	}
+	if len(shape.Equality) != 2 || shape.Equality[0] != "name" || shape.Equality[1] != "role" {
+		t.Fatalf("unexpected equality fields: %v", shape.Equality)
+	}
+	if len(shape.Range) != 1 || shape.Range[0] != "age" {
+		t.Fatalf("unexpected range fields: %v", shape.Range)
+	}
+
+	if _, ok := filterShape(bson.M{}); ok {
+		t.Fatal("expected empty filter to have no shape")
+	}
+	if _, ok := filterShape(nil); ok {
+		t.Fatal("expected nil filter to have no shape")
+	}
+}
+
+func TestShapeIndexed(t *testing.T) {
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{BSONName: "email", Unique: true},
+			{BSONName: "role", Index: true},
+			{BSONName: "age"},
+			{BSONName: "name"},
+		},
+		CompoundIndexes: []CompoundIndex{NewCompoundIndex("name", "age", "role")},
+	}
+
+	tests := []struct {
+		shape FilterShape
+		want  bool
+	}{
+		{FilterShape{Equality: []string{"_id", "age"}}, true},
+		{FilterShape{Equality: []string{"email", "age"}}, true},
+		{FilterShape{Equality: []string{"role"}}, true},
+		{FilterShape{Equality: []string{"name"}, Range: []string{"age"}}, true},
+		{FilterShape{Equality: []string{"age", "role"}}, false},
+		{FilterShape{Range: []string{"age"}}, false},
+	}
+	for _, tt := range tests {
+		if got := shapeIndexed(schema, tt.shape); got != tt.want {
+			t.Fatalf("shapeIndexed(%+v) = %v, want %v", tt.shape, got, tt.want)
+		}
+	}
+}
+
+func TestIndexAdvisor_Suggestions(t *testing.T) {
+	registerTestModels()
+	defer unregisterTestModels()
+	defer ClearMiddleware()
+
+	advisor := NewIndexAdvisor()
+	Use(advisor.Middleware())
+
+	// Record via middleware; the operation itself fails without a database.
+	_ = Find(context.Background(), bson.D{{Key: "role", Value: "user"}, {Key: "age", Value: bson.D{{Key: "$gt", Value: 30}}}}, &[]testUser{})
+	advisor.Record("testUser", bson.M{"role": "admin", "age": bson.M{"$lt": 10}})
+	advisor.Record("testUser", bson.D{{Key: "email", Value: "a@b.c"}})
+	advisor.Record("testProfile", bson.D{{Key: "bio", Value: "x"}})
+
+	got := advisor.Suggestions()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", got)
+	}
+	first := got[0]
+	if first.ModelName != "testUser" || first.Collection != "test_users" || first.Seen != 2 {
+		t.Fatalf("unexpected first suggestion: %+v", first)
+	}
+	if len(first.Fields) != 2 || first.Fields[0] != "role" || first.Fields[1] != "age" {
+		t.Fatalf("expected equality before range fields, got %v", first.Fields)
+	}
+	if got[1].ModelName != "testProfile" || got[1].Seen != 1 {
+		t.Fatalf("unexpected second suggestion: %+v", got[1])
+	}
+
+	advisor.Reset()
+	if got := advisor.Suggestions(); len(got) != 0 {
+		t.Fatalf("expected no suggestions after reset, got %+v", got)
+	}
+}
@@ -0,0 +1,126 @@
+package goodm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Auditable records who created and last updated a document. Embed it next
+// to Model; Create, CreateMany, Update, and UpdateFields fill it from the
+// actor set with WithActor.
+//
+// Any model with CreatedBy and UpdatedBy fields of type bson.ObjectID or
+// string is stamped the same way, so models identifying actors by name can
+// declare their own fields instead.
+//
+// Example:
+//
+//	type Invoice struct {
+//	    goodm.Model     `bson:",inline"`
+//	    goodm.Auditable `bson:",inline"`
+//	    Total int `bson:"total"`
+//	}
+type Auditable struct {
+	CreatedBy bson.ObjectID `bson:"created_by,omitempty"`
+	UpdatedBy bson.ObjectID `bson:"updated_by,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context naming the user or service performing writes,
+// as a bson.ObjectID or a string. Writes made with it stamp the CreatedBy and
+// UpdatedBy fields of the models they save.
+//
+// Example:
+//
+//	ctx = goodm.WithActor(ctx, session.UserID)
+//	err := goodm.Create(ctx, &invoice) // invoice.CreatedBy == session.UserID
+func WithActor(ctx context.Context, actor interface{}) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor, if any.
+func ActorFromContext(ctx context.Context) (interface{}, bool) {
+	actor := ctx.Value(actorKey{})
+	return actor, actor != nil
+}
+
+// stampActor sets UpdatedBy, and on create CreatedBy if it is empty, to the
+// context's actor.
+func stampActor(ctx context.Context, model interface{}, creating bool) {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return
+	}
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if creating {
+		if f := v.FieldByName("CreatedBy"); f.IsValid() && f.CanSet() && f.IsZero() {
+			setActor(f, actor)
+		}
+	}
+	if f := v.FieldByName("UpdatedBy"); f.IsValid() && f.CanSet() {
+		setActor(f, actor)
+	}
+}
+
+// actorField returns the bson name of the model's UpdatedBy field and the
+// value UpdateFields should $set it to, or ok false when there is nothing to
+// stamp.
+func actorField(ctx context.Context, model interface{}) (name string, value interface{}, ok bool) {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return "", nil, false
+	}
+	t := reflect.TypeOf(model)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	sf, found := t.FieldByName("UpdatedBy")
+	if !found {
+		return "", nil, false
+	}
+	name, _ = ParseBSONTag(sf.Tag.Get("bson"))
+	if name == "" {
+		name = strings.ToLower(sf.Name)
+	}
+	tmp := reflect.New(sf.Type).Elem()
+	if name == "-" || !setActor(tmp, actor) {
+		return "", nil, false
+	}
+	return name, tmp.Interface(), true
+}
+
+// setActor stores actor in f, converting between ObjectIDs and their hex
+// strings. It reports whether the types were compatible.
+func setActor(f reflect.Value, actor interface{}) bool {
+	av := reflect.ValueOf(actor)
+	switch {
+	case av.Type().AssignableTo(f.Type()):
+		f.Set(av)
+	case f.Kind() == reflect.String:
+		id, ok := actor.(bson.ObjectID)
+		if !ok {
+			return false
+		}
+		f.SetString(id.Hex())
+	case f.Type() == objectIDType:
+		s, ok := actor.(string)
+		if !ok {
+			return false
+		}
+		id, err := bson.ObjectIDFromHex(s)
+		if err != nil {
+			return false
+		}
+		f.Set(reflect.ValueOf(id))
+	default:
+		return false
+	}
+	return true
+}
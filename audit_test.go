@@ -0,0 +1,120 @@
+package goodm
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testInvoice struct {
+	Model     `bson:",inline"`
+	Auditable `bson:",inline"`
+	Total     int `bson:"total"`
+}
+
+type testNote struct {
+	Model     `bson:",inline"`
+	Text      string `bson:"text"`
+	CreatedBy string `bson:"author"`
+	UpdatedBy string `bson:"editor"`
+}
+
+func registerAuditModels(t *testing.T) {
+	t.Helper()
+	if err := Register(&testInvoice{}, "test_invoices"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := Register(&testNote{}, "test_notes"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testInvoice")
+		delete(registry, "testNote")
+		registryMu.Unlock()
+	})
+}
+
+func TestActorFromContext(t *testing.T) {
+	if _, ok := ActorFromContext(context.Background()); ok {
+		t.Fatal("expected no actor")
+	}
+	if actor, ok := ActorFromContext(WithActor(context.Background(), "svc")); !ok || actor != "svc" {
+		t.Fatalf("expected svc, got %v", actor)
+	}
+}
+
+func TestAuditable_Stamping(t *testing.T) {
+	ctx := useTestStore(t)
+	registerAuditModels(t)
+
+	alice, bob := bson.NewObjectID(), bson.NewObjectID()
+
+	inv := &testInvoice{Total: 10}
+	if err := Create(WithActor(ctx, alice), inv); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if inv.CreatedBy != alice || inv.UpdatedBy != alice {
+		t.Fatalf("expected alice to be stamped, got %+v", inv.Auditable)
+	}
+
+	inv.Total = 20
+	if err := Update(WithActor(ctx, bob), inv); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	found := &testInvoice{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: inv.ID}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.CreatedBy != alice || found.UpdatedBy != bob {
+		t.Fatalf("expected created by alice and updated by bob, got %+v", found.Auditable)
+	}
+
+	if err := UpdateFields(WithActor(ctx, alice.Hex()), found, bson.M{"total": 30}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	if found.UpdatedBy != alice {
+		t.Fatalf("expected the model to be stamped by UpdateFields, got %v", found.UpdatedBy)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "updated_by", Value: alice}}, &testInvoice{}); err != nil {
+		t.Fatalf("expected updated_by to be stored: %v", err)
+	}
+
+	// Without an actor, nothing is stamped.
+	anon := &testInvoice{}
+	if err := Create(ctx, anon); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !anon.CreatedBy.IsZero() || !anon.UpdatedBy.IsZero() {
+		t.Fatalf("expected no actor, got %+v", anon.Auditable)
+	}
+}
+
+func TestAuditable_StringFields(t *testing.T) {
+	ctx := useTestStore(t)
+	registerAuditModels(t)
+
+	id := bson.NewObjectID()
+	notes := []testNote{{Text: "a"}, {Text: "b", CreatedBy: "import"}}
+	if _, err := CreateMany(WithActor(ctx, id), notes); err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	if notes[0].CreatedBy != id.Hex() || notes[0].UpdatedBy != id.Hex() {
+		t.Fatalf("expected the actor's hex ID, got %+v", notes[0])
+	}
+	if notes[1].CreatedBy != "import" {
+		t.Fatalf("expected an explicit CreatedBy to be kept, got %q", notes[1].CreatedBy)
+	}
+
+	if err := UpdateFields(WithActor(ctx, "cron"), &notes[0], bson.M{"text": "c"}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	found := &testNote{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: notes[0].ID}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.UpdatedBy != "cron" || found.CreatedBy != id.Hex() {
+		t.Fatalf("unexpected actors: %+v", found)
+	}
+}
@@ -2,12 +2,14 @@ package goodm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // BulkResult contains the outcome of a bulk operation.
@@ -16,6 +18,15 @@ type BulkResult struct {
 	MatchedCount  int64
 	ModifiedCount int64
 	DeletedCount  int64
+	UpsertedCount int64
+
+	// InsertedIDs holds the IDs of the models CreateMany inserted, in the
+	// order of the input slice.
+	InsertedIDs []bson.ObjectID
+
+	// WriteErrors maps the index in the input slice of each model
+	// CreateMany failed to insert to the server's error for it.
+	WriteErrors map[int]error
 }
 
 // CreateMany inserts multiple documents. It generates IDs, sets timestamps,
@@ -24,54 +35,93 @@ type BulkResult struct {
 //
 // models must be a slice of structs or struct pointers (e.g. []User or []*User).
 //
+// The result lists the IDs of the inserted models. If the insert fails with
+// write errors, such as duplicate keys, the result is returned along with
+// the error: by default the insert stops at the first failing model, so only
+// the models before it are inserted; with CreateOptions.Unordered every
+// other model is inserted, and WriteErrors holds an error for each one that
+// was not. AfterCreate hooks do not run when the insert fails. A model that
+// fails validation or a BeforeCreate hook aborts the call before anything is
+// written, with a nil result.
+//
 // Performance: hooks and validation run per-model. For large batches where
 // you don't need the ODM lifecycle, use the mongo driver's InsertMany directly.
-func CreateMany(ctx context.Context, models interface{}, opts ...CreateOptions) error {
+func CreateMany(ctx context.Context, models interface{}, opts ...CreateOptions) (*BulkResult, error) {
 	rv := reflect.ValueOf(models)
 	if rv.Kind() == reflect.Ptr {
 		rv = rv.Elem()
 	}
 	if rv.Kind() != reflect.Slice {
-		return fmt.Errorf("goodm: CreateMany expects a slice, got %T", models)
+		return nil, fmt.Errorf("goodm: CreateMany expects a slice, got %T", models)
 	}
 	if rv.Len() == 0 {
-		return nil
+		return &BulkResult{}, nil
 	}
 
 	schema, err := getSchemaForModel(elemModel(rv.Index(0)))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var optDB *mongo.Database
+	var opt CreateOptions
 	if len(opts) > 0 {
-		optDB = opts[0].DB
+		opt = opts[0]
 	}
-	db, err := getDB(optDB)
+	db, err := getDB(opt.DB)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return runMiddleware(ctx, &OpInfo{
+	var result *BulkResult
+	err = runMiddleware(ctx, &OpInfo{
 		Operation:  OpCreateMany,
 		Collection: schema.Collection,
 		ModelName:  schema.ModelName,
 	}, func(ctx context.Context) error {
-		now := time.Now()
+		bindOpDB(ctx, db)
+		now := clockNow()
 		docs := make([]interface{}, rv.Len())
+		coll := writeCollection(db, schema, opt.WriteConcern)
+		batch := &createBatch{db: db, coll: coll, slugs: make(map[string]bool), seqs: newSequenceBlock()}
+		var paths map[bson.ObjectID]string
+		if schema.TreePath != "" {
+			paths = make(map[bson.ObjectID]string, rv.Len())
+		}
 
 		for i := 0; i < rv.Len(); i++ {
-			model, err := prepareCreateItem(ctx, rv.Index(i), now, schema, i)
+			batch.remaining = rv.Len() - i
+			model, err := prepareCreateItem(ctx, rv.Index(i), now, schema, i, batch)
+			if err != nil {
+				return err
+			}
+			if paths != nil {
+				path, err := setTreePath(ctx, coll, model, schema, paths)
+				if err != nil {
+					return fmt.Errorf("goodm: tree path failed on item %d: %w", i, err)
+				}
+				id, _ := getModelID(model)
+				paths[id] = path
+			}
+			doc, err := insertDocument(model, schema)
 			if err != nil {
 				return err
 			}
-			docs[i] = model
+			docs[i] = doc
 		}
 
-		coll := getCollection(db, schema)
-		if _, err := coll.InsertMany(ctx, docs); err != nil {
+		if _, err := coll.InsertMany(ctx, docs, withComment(ctx, options.InsertMany().SetOrdered(!opt.Unordered))); err != nil {
+			result = partialInsert(rv, err, !opt.Unordered)
+			if verrs := uniqueWithErrors(schema, err); verrs != nil {
+				return verrs
+			}
 			return fmt.Errorf("goodm: insert many failed: %w", err)
 		}
+		result = &BulkResult{InsertedCount: int64(rv.Len()), InsertedIDs: make([]bson.ObjectID, rv.Len())}
+		for i := 0; i < rv.Len(); i++ {
+			model := elemModel(rv.Index(i))
+			result.InsertedIDs[i], _ = getModelID(model)
+			track(model)
+		}
 
 		// AfterCreate hooks
 		for i := 0; i < rv.Len(); i++ {
@@ -85,6 +135,51 @@ func CreateMany(ctx context.Context, models interface{}, opts ...CreateOptions)
 
 		return nil
 	})
+	return result, err
+}
+
+// partialInsert returns the result of an InsertMany of the models in rv that
+// failed with err, or nil if err carries no write errors, in which case what
+// was inserted is unknown. In ordered mode the insert stopped at the first
+// failing model. Inserted models are tracked.
+func partialInsert(rv reflect.Value, err error, ordered bool) *BulkResult {
+	var writeErrs []mongo.WriteError
+	var bwe mongo.BulkWriteException
+	var we mongo.WriteException
+	switch {
+	case errors.As(err, &bwe):
+		for _, e := range bwe.WriteErrors {
+			writeErrs = append(writeErrs, e.WriteError)
+		}
+	case errors.As(err, &we):
+		writeErrs = we.WriteErrors
+	}
+	if len(writeErrs) == 0 {
+		return nil
+	}
+
+	result := &BulkResult{WriteErrors: make(map[int]error, len(writeErrs))}
+	stop := rv.Len()
+	for _, e := range writeErrs {
+		result.WriteErrors[e.Index] = e
+		if e.Index < stop {
+			stop = e.Index
+		}
+	}
+	if !ordered {
+		stop = rv.Len()
+	}
+	for i := 0; i < stop; i++ {
+		if _, failed := result.WriteErrors[i]; failed {
+			continue
+		}
+		model := elemModel(rv.Index(i))
+		id, _ := getModelID(model)
+		result.InsertedIDs = append(result.InsertedIDs, id)
+		track(model)
+	}
+	result.InsertedCount = int64(len(result.InsertedIDs))
+	return result
 }
 
 // elemModel returns a pointer-to-struct interface from a reflect.Value,
@@ -96,9 +191,19 @@ func elemModel(v reflect.Value) interface{} {
 	return v.Addr().Interface()
 }
 
+// createBatch carries the state CreateMany shares across its models.
+type createBatch struct {
+	db        dbHandle
+	coll      collection
+	slugs     map[string]bool // slugs generated earlier in the batch
+	seqs      *sequenceBlock  // counter values reserved for the batch
+	remaining int             // models left, including the current one
+}
+
 // prepareCreateItem initialises a single model for insertion: sets ID, timestamps,
-// defaults, version, runs BeforeCreate, and validates.
-func prepareCreateItem(ctx context.Context, elem reflect.Value, now time.Time, schema *Schema, index int) (interface{}, error) {
+// defaults, sequences, version, runs BeforeCreate, normalizes, generates
+// slugs, and validates.
+func prepareCreateItem(ctx context.Context, elem reflect.Value, now time.Time, schema *Schema, index int, batch *createBatch) (interface{}, error) {
 	model := elemModel(elem)
 
 	id, err := getModelID(model)
@@ -110,12 +215,18 @@ func prepareCreateItem(ctx context.Context, elem reflect.Value, now time.Time, s
 	}
 
 	setTimestamps(model, now)
+	stampActor(ctx, model, true)
 
 	if err := applyDefaults(model, schema); err != nil {
 		return nil, err
 	}
 
+	if err := setSequences(ctx, batch.db, model, schema, batch.seqs, batch.remaining); err != nil {
+		return nil, fmt.Errorf("goodm: autoincrement failed on item %d: %w", index, err)
+	}
+
 	setModelVersion(model, 0)
+	setDiscriminator(model, schema)
 
 	if hook, ok := model.(BeforeCreate); ok {
 		if err := hook.BeforeCreate(ctx); err != nil {
@@ -123,6 +234,14 @@ func prepareCreateItem(ctx context.Context, elem reflect.Value, now time.Time, s
 		}
 	}
 
+	if err := applyNormalizers(model, schema); err != nil {
+		return nil, fmt.Errorf("goodm: normalization failed on item %d: %w", index, err)
+	}
+
+	if err := setSlugs(ctx, batch.coll, model, schema, batch.slugs); err != nil {
+		return nil, fmt.Errorf("goodm: slug generation failed on item %d: %w", index, err)
+	}
+
 	if errs := Validate(model, schema); len(errs) > 0 {
 		return nil, fmt.Errorf("goodm: validation failed on item %d: %w", index, ValidationErrors(errs))
 	}
@@ -133,6 +252,8 @@ func prepareCreateItem(ctx context.Context, elem reflect.Value, now time.Time, s
 // UpdateMany updates all documents matching filter with the given update document.
 // The model parameter is used only for schema/collection lookup (e.g. &User{}).
 //
+// UpdateOptions.ArrayFilters, Upsert, and Collation are passed to the driver.
+//
 // Performance: This is a direct passthrough to MongoDB's UpdateMany. It bypasses
 // hooks, validation, and immutable field enforcement. Use Update for the full
 // ODM lifecycle on individual documents.
@@ -142,11 +263,16 @@ func UpdateMany(ctx context.Context, filter, update interface{}, model interface
 		return nil, err
 	}
 
-	var optDB *mongo.Database
+	var opt UpdateOptions
 	if len(opts) > 0 {
-		optDB = opts[0].DB
+		opt = opts[0]
 	}
-	db, err := getDB(optDB)
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err = coerceFilter(schema, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -158,15 +284,18 @@ func UpdateMany(ctx context.Context, filter, update interface{}, model interface
 		ModelName:  schema.ModelName,
 		Model:      model,
 		Filter:     filter,
+		filterable: true,
 	}, func(ctx context.Context) error {
+		bindOpDB(ctx, db)
 		coll := getCollection(db, schema)
-		res, err := coll.UpdateMany(ctx, filter, update)
+		res, err := coll.UpdateMany(ctx, scopeFilter(ctx, schema, filter), update, withComment(ctx, opt.updateManyOptions()))
 		if err != nil {
 			return fmt.Errorf("goodm: update many failed: %w", err)
 		}
 		result = &BulkResult{
 			MatchedCount:  res.MatchedCount,
 			ModifiedCount: res.ModifiedCount,
+			UpsertedCount: res.UpsertedCount,
 		}
 		return nil
 	})
@@ -179,17 +308,23 @@ func UpdateMany(ctx context.Context, filter, update interface{}, model interface
 //
 // Performance: This is a direct passthrough to MongoDB's DeleteMany. It bypasses
 // hooks entirely. Use Delete for the full ODM lifecycle on individual documents.
+// For models embedding SoftDeleteModel, the documents are marked deleted instead.
 func DeleteMany(ctx context.Context, filter interface{}, model interface{}, opts ...DeleteOptions) (*BulkResult, error) {
 	schema, err := getSchemaForModel(model)
 	if err != nil {
 		return nil, err
 	}
 
-	var optDB *mongo.Database
+	var opt DeleteOptions
 	if len(opts) > 0 {
-		optDB = opts[0].DB
+		opt = opts[0]
 	}
-	db, err := getDB(optDB)
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err = coerceFilter(schema, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -200,9 +335,19 @@ func DeleteMany(ctx context.Context, filter interface{}, model interface{}, opts
 		Collection: schema.Collection,
 		ModelName:  schema.ModelName,
 		Filter:     filter,
+		filterable: true,
 	}, func(ctx context.Context) error {
-		coll := getCollection(db, schema)
-		res, err := coll.DeleteMany(ctx, filter)
+		bindOpDB(ctx, db)
+		coll := writeCollection(db, schema, opt.WriteConcern)
+		if schema.SoftDelete != "" {
+			res, err := coll.UpdateMany(ctx, scopeFilter(ctx, schema, filter), softDeleteUpdate(schema, clockNow()), withComment(ctx, options.UpdateMany()))
+			if err != nil {
+				return fmt.Errorf("goodm: delete many failed: %w", err)
+			}
+			result = &BulkResult{DeletedCount: res.ModifiedCount}
+			return nil
+		}
+		res, err := coll.DeleteMany(ctx, scopeFilter(ctx, schema, filter), withComment(ctx, options.DeleteMany()))
 		if err != nil {
 			return fmt.Errorf("goodm: delete many failed: %w", err)
 		}
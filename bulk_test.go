@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 var fixedTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -19,7 +20,7 @@ func TestCreateMany_Integration(t *testing.T) {
 		{Email: "bulk3@test.com", Name: "Bulk3", Age: 22, Role: "admin"},
 	}
 
-	if err := CreateMany(ctx, users); err != nil {
+	if _, err := CreateMany(ctx, users); err != nil {
 		t.Fatalf("create many: %v", err)
 	}
 
@@ -52,7 +53,7 @@ func TestCreateMany_WithPointers(t *testing.T) {
 		{Email: "ptr2@test.com", Name: "Ptr2", Age: 21, Role: "user"},
 	}
 
-	if err := CreateMany(ctx, users); err != nil {
+	if _, err := CreateMany(ctx, users); err != nil {
 		t.Fatalf("create many ptrs: %v", err)
 	}
 
@@ -68,7 +69,7 @@ func TestCreateMany_Empty(t *testing.T) {
 	defer cleanup()
 
 	var users []testUser
-	if err := CreateMany(ctx, users); err != nil {
+	if _, err := CreateMany(ctx, users); err != nil {
 		t.Fatalf("create many empty should not error: %v", err)
 	}
 }
@@ -82,7 +83,7 @@ func TestCreateMany_ValidationFailure(t *testing.T) {
 		{Email: "", Name: "Bad", Age: 20, Role: "user"}, // missing required email
 	}
 
-	err := CreateMany(ctx, users)
+	_, err := CreateMany(ctx, users)
 	if err == nil {
 		t.Fatal("expected validation error")
 	}
@@ -97,7 +98,7 @@ func TestCreateMany_Hooks(t *testing.T) {
 		{Email: "hook2@test.com", Name: "Hook2"},
 	}
 
-	if err := CreateMany(ctx, users); err != nil {
+	if _, err := CreateMany(ctx, users); err != nil {
 		t.Fatalf("create many hooks: %v", err)
 	}
 
@@ -111,6 +112,65 @@ func TestCreateMany_Hooks(t *testing.T) {
 	}
 }
 
+func TestCreateMany_Result(t *testing.T) {
+	ctx := useTestStore(t)
+
+	users := []testUser{
+		{Email: "r1@test.com", Name: "R1"},
+		{Email: "r2@test.com", Name: "R2"},
+	}
+	res, err := CreateMany(ctx, users)
+	if err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	if res.InsertedCount != 2 || len(res.InsertedIDs) != 2 || res.InsertedIDs[0] != users[0].ID || res.InsertedIDs[1] != users[1].ID {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestCreateMany_PartialFailure(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Create(ctx, &testUser{Email: "taken@test.com", Name: "Taken"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	batch := func() []testUser {
+		return []testUser{
+			{Email: "p1@test.com", Name: "P1"},
+			{Email: "taken@test.com", Name: "P2"},
+			{Email: "p3@test.com", Name: "P3"},
+		}
+	}
+
+	users := batch()
+	res, err := CreateMany(ctx, users)
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("expected a duplicate key error, got %v", err)
+	}
+	if res == nil || res.InsertedCount != 1 || res.InsertedIDs[0] != users[0].ID {
+		t.Fatalf("expected only the first model inserted, got %+v", res)
+	}
+	if _, ok := res.WriteErrors[1]; !ok || len(res.WriteErrors) != 1 {
+		t.Fatalf("expected a write error for item 1, got %v", res.WriteErrors)
+	}
+
+	users = batch()
+	users[0].Email = "u1@test.com"
+	users[2].Email = "u3@test.com"
+	res, err = CreateMany(ctx, users, CreateOptions{Unordered: true})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if res == nil || res.InsertedCount != 2 || res.InsertedIDs[0] != users[0].ID || res.InsertedIDs[1] != users[2].ID {
+		t.Fatalf("expected the first and last models inserted, got %+v", res)
+	}
+	if _, ok := res.WriteErrors[1]; !ok || len(res.WriteErrors) != 1 {
+		t.Fatalf("expected a write error for item 1, got %v", res.WriteErrors)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "email", Value: "u3@test.com"}}, &testUser{}); err != nil {
+		t.Errorf("expected the model after the failure to be stored: %v", err)
+	}
+}
+
 func TestUpdateMany_Integration(t *testing.T) {
 	ctx, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -120,7 +180,7 @@ func TestUpdateMany_Integration(t *testing.T) {
 		{Email: "um2@test.com", Name: "UM2", Age: 21, Role: "user"},
 		{Email: "um3@test.com", Name: "UM3", Age: 22, Role: "admin"},
 	}
-	if err := CreateMany(ctx, users); err != nil {
+	if _, err := CreateMany(ctx, users); err != nil {
 		t.Fatalf("create many: %v", err)
 	}
 
@@ -149,7 +209,7 @@ func TestDeleteMany_Integration(t *testing.T) {
 		{Email: "dm2@test.com", Name: "DM2", Age: 21, Role: "user"},
 		{Email: "dm3@test.com", Name: "DM3", Age: 22, Role: "admin"},
 	}
-	if err := CreateMany(ctx, users); err != nil {
+	if _, err := CreateMany(ctx, users); err != nil {
 		t.Fatalf("create many: %v", err)
 	}
 
@@ -0,0 +1,204 @@
+package goodm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// DefaultCacheTTL is how long QueryCache keeps results when no TTL is set.
+const DefaultCacheTTL = time.Minute
+
+// CacheOptions configures a QueryCache.
+type CacheOptions struct {
+	TTL        time.Duration            // default lifetime of cached results (default DefaultCacheTTL)
+	ModelTTLs  map[string]time.Duration // per-model lifetimes by model name; negative disables caching
+	MaxEntries int                      // upper bound on cached results (default 10000)
+}
+
+// QueryCache caches the results of FindOne and Find, keyed on the collection,
+// the result type, and a hash of the filter and find options. Any create,
+// update, or delete through goodm on a collection invalidates its cached
+// results. Install its middleware to start caching.
+//
+// Writes that bypass goodm's CRUD functions, such as raw driver calls,
+// pipelines, or writes from other processes, are not seen; rely on the TTL
+// for those or call Invalidate. Reads inside a transaction are never cached,
+// nor are FindCursor and FindPolymorphic.
+//
+// Example:
+//
+//	cache := goodm.NewQueryCache(goodm.CacheOptions{
+//	    TTL:       30 * time.Second,
+//	    ModelTTLs: map[string]time.Duration{"Country": time.Hour, "Order": -1},
+//	})
+//	goodm.Use(cache.Middleware())
+type QueryCache struct {
+	opt CacheOptions
+
+	mu          sync.Mutex
+	entries     map[string]*cacheEntry
+	generations map[string]uint64 // per collection, bumped by every write
+}
+
+type cacheEntry struct {
+	collection string
+	doc        bson.Raw
+	expires    time.Time
+}
+
+// NewQueryCache creates an empty QueryCache.
+func NewQueryCache(opts ...CacheOptions) *QueryCache {
+	var opt CacheOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.TTL <= 0 {
+		opt.TTL = DefaultCacheTTL
+	}
+	if opt.MaxEntries <= 0 {
+		opt.MaxEntries = 10000
+	}
+	return &QueryCache{opt: opt, entries: make(map[string]*cacheEntry), generations: make(map[string]uint64)}
+}
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context whose reads bypass any QueryCache, for
+// reads that must see the latest data.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// Middleware returns a MiddlewareFunc that serves finds from the cache and
+// invalidates a collection's results after every write to it.
+func (c *QueryCache) Middleware() MiddlewareFunc {
+	return func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		if op.Operation != OpFind {
+			err := next(ctx)
+			c.Invalidate(op.Collection)
+			return err
+		}
+
+		ttl := c.ttl(op.ModelName)
+		if ttl <= 0 || op.Result == nil || ctx.Value(noCacheKey{}) != nil || mongo.SessionFromContext(ctx) != nil {
+			return next(ctx)
+		}
+		key, ok := cacheKey(ctx, op)
+		if !ok {
+			return next(ctx)
+		}
+
+		c.mu.Lock()
+		entry := c.entries[key]
+		gen := c.generations[op.Collection]
+		c.mu.Unlock()
+		if entry != nil && time.Now().Before(entry.expires) {
+			if err := entry.doc.Lookup("v").UnmarshalWithRegistry(CodecRegistry(), op.Result); err == nil {
+				return afterLoad(ctx, op.Result)
+			}
+		}
+
+		if err := next(ctx); err != nil {
+			return err
+		}
+		c.store(key, op.Collection, gen, ttl, op.Result)
+		return nil
+	}
+}
+
+// Invalidate discards the cached results for a collection.
+func (c *QueryCache) Invalidate(collection string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[collection]++
+	for key, e := range c.entries {
+		if e.collection == collection {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Clear discards all cached results.
+func (c *QueryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for collection := range c.generations {
+		c.generations[collection]++
+	}
+	c.entries = make(map[string]*cacheEntry)
+}
+
+// Len returns the number of cached results, including expired ones not yet
+// evicted.
+func (c *QueryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// ttl returns the lifetime of cached results for a model.
+func (c *QueryCache) ttl(modelName string) time.Duration {
+	if ttl, ok := c.opt.ModelTTLs[modelName]; ok {
+		return ttl
+	}
+	return c.opt.TTL
+}
+
+// store caches result unless the collection was written since gen was read,
+// which means the result may already be stale.
+func (c *QueryCache) store(key, collection string, gen uint64, ttl time.Duration, result interface{}) {
+	doc, err := marshalBSON(bson.D{{Key: "v", Value: reflect.ValueOf(result).Elem().Interface()}})
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[collection] != gen {
+		return
+	}
+	now := time.Now()
+	if len(c.entries) >= c.opt.MaxEntries {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= c.opt.MaxEntries {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = &cacheEntry{collection: collection, doc: doc, expires: now.Add(ttl)}
+}
+
+// cacheKey hashes everything that determines a find's result. ok is false
+// when the options are not FindOptions.
+func cacheKey(ctx context.Context, op *OpInfo) (string, bool) {
+	opt, ok := op.Options.(FindOptions)
+	if !ok {
+		return "", false
+	}
+	var collation interface{}
+	if opt.Collation != nil {
+		collation = *opt.Collation
+	}
+	// %#v prints map keys in sorted order, so equal bson.M filters hash alike.
+	// Strict is part of the key so a strict read is never served an entry
+	// that skipped the unknown fields check, and so is the soft-delete mode,
+	// so Find is never served the deleted documents of FindWithDeleted.
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s|%T|%#v|%p|%d|%d|%#v|%t|%#v|%t|%t",
+		op.Collection, op.Result, op.Filter, opt.DB, opt.Limit, opt.Skip, opt.Sort, opt.IncludeHidden, collation, opt.Strict,
+		includesDeleted(ctx))))
+	return op.Collection + "#" + hex.EncodeToString(h[:]), true
+}
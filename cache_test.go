@@ -0,0 +1,201 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// useQueryCache installs cache followed by a middleware counting the finds
+// that reach the store.
+func useQueryCache(t *testing.T, cache *QueryCache) *int {
+	t.Helper()
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	finds := 0
+	Use(cache.Middleware(), func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		if op.Operation == OpFind {
+			finds++
+		}
+		return next(ctx)
+	})
+	return &finds
+}
+
+func TestQueryCache_HitsAndInvalidation(t *testing.T) {
+	ctx := useTestStore(t)
+	cache := NewQueryCache()
+	finds := useQueryCache(t, cache)
+
+	u := &testUser{Email: "cache@test.com", Name: "Cache", Age: 30}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	filter := bson.M{"email": "cache@test.com", "age": 30}
+	for i := 0; i < 2; i++ {
+		found := &testUser{}
+		if err := FindOne(ctx, filter, found); err != nil {
+			t.Fatalf("find one: %v", err)
+		}
+		if found.ID != u.ID || found.Age != 30 {
+			t.Fatalf("unexpected document: %+v", found)
+		}
+		var all []testUser
+		if err := Find(ctx, bson.D{}, &all, FindOptions{Limit: 10}); err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if len(all) != 1 || all[0].ID != u.ID {
+			t.Fatalf("unexpected results: %+v", all)
+		}
+	}
+	if *finds != 2 {
+		t.Fatalf("expected 2 finds to reach the store, got %d", *finds)
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 cached results, got %d", cache.Len())
+	}
+
+	// Different options are cached separately.
+	var limited []testUser
+	if err := Find(ctx, bson.D{}, &limited, FindOptions{Limit: 1}); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if *finds != 3 {
+		t.Fatalf("expected a different limit to miss, got %d finds", *finds)
+	}
+
+	if err := UpdateOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, bson.D{{Key: "$set", Value: bson.D{{Key: "age", Value: 31}}}}, &testUser{}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("expected the update to invalidate, %d results cached", cache.Len())
+	}
+	found := &testUser{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.Age != 31 || *finds != 4 {
+		t.Fatalf("expected a fresh read, got age %d after %d finds", found.Age, *finds)
+	}
+
+	// Misses are not cached.
+	for i := 0; i < 2; i++ {
+		if err := FindOne(ctx, bson.D{{Key: "email", Value: "nobody"}}, &testUser{}); err != ErrNotFound {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if *finds != 6 {
+		t.Fatalf("expected not-found results to skip the cache, got %d finds", *finds)
+	}
+}
+
+func TestQueryCache_TTLAndBypass(t *testing.T) {
+	ctx := useTestStore(t)
+	cache := NewQueryCache(CacheOptions{
+		TTL:       20 * time.Millisecond,
+		ModelTTLs: map[string]time.Duration{"testProfile": -1},
+	})
+	finds := useQueryCache(t, cache)
+
+	if err := Create(ctx, &testUser{Email: "ttl@test.com", Name: "TTL"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := Create(ctx, &testProfile{Bio: "hi"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	filter := bson.D{{Key: "email", Value: "ttl@test.com"}}
+
+	_ = FindOne(ctx, filter, &testUser{})
+	_ = FindOne(ctx, filter, &testUser{})
+	_ = FindOne(WithoutCache(ctx), filter, &testUser{})
+	if *finds != 2 {
+		t.Fatalf("expected WithoutCache to bypass the cache, got %d finds", *finds)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	_ = FindOne(ctx, filter, &testUser{})
+	if *finds != 3 {
+		t.Fatalf("expected the entry to expire, got %d finds", *finds)
+	}
+
+	_ = FindOne(ctx, bson.D{}, &testProfile{})
+	_ = FindOne(ctx, bson.D{}, &testProfile{})
+	if *finds != 5 {
+		t.Fatalf("expected a negative model TTL to disable caching, got %d finds", *finds)
+	}
+}
+
+func TestQueryCache_ReturnsCopies(t *testing.T) {
+	ctx := useTestStore(t)
+	cache := NewQueryCache()
+	useQueryCache(t, cache)
+
+	if err := Create(ctx, &testUser{Email: "copy@test.com", Name: "Copy", Age: 5}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var first []testUser
+	if err := Find(ctx, bson.D{}, &first); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	first[0].Age = 99
+
+	var second []testUser
+	if err := Find(ctx, bson.D{}, &second); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(second) != 1 || second[0].Age != 5 {
+		t.Fatalf("cached result was shared with the caller: %+v", second)
+	}
+}
+
+func TestQueryCache_StrictNotServedFromLenientEntry(t *testing.T) {
+	ctx := useTestStore(t)
+	cache := NewQueryCache()
+	useQueryCache(t, cache)
+	insertDriftedUsers(t, ctx)
+
+	filter := bson.D{{Key: "email", Value: "drift@test.com"}}
+	if err := FindOne(ctx, filter, &testUser{}); err != nil {
+		t.Fatalf("lenient find: %v", err)
+	}
+	var uerr *UnknownFieldsError
+	if err := FindOne(ctx, filter, &testUser{}, FindOptions{Strict: true}); !errors.As(err, &uerr) {
+		t.Fatalf("expected the strict read to bypass the lenient entry, got %v", err)
+	}
+}
+
+func TestQueryCache_SoftDeleteModeInKey(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testComment{}, "comments"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testComment")
+		registryMu.Unlock()
+	})
+	useQueryCache(t, NewQueryCache())
+
+	live, deleted := &testComment{Post: "p1", Body: "live"}, &testComment{Post: "p1", Body: "deleted"}
+	for _, c := range []*testComment{live, deleted} {
+		if err := Create(ctx, c); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if err := Delete(ctx, deleted); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	filter := bson.D{{Key: "post", Value: "p1"}}
+	var all, found []testComment
+	if err := FindWithDeleted(ctx, filter, &all); err != nil || len(all) != 2 {
+		t.Fatalf("expected FindWithDeleted to return 2 comments, got %d (%v)", len(all), err)
+	}
+	if err := Find(ctx, filter, &found); err != nil || len(found) != 1 || found[0].ID != live.ID {
+		t.Fatalf("expected Find to skip the cached deleted comment, got %+v (%v)", found, err)
+	}
+}
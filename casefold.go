@@ -0,0 +1,127 @@
+package goodm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Case-insensitive index strategies for the ci tag.
+const (
+	CaseInsensitiveCollation = "collation" // ci: index with a case-insensitive collation
+	CaseInsensitiveShadow    = "shadow"    // ci=shadow: index a lowercased copy of the field
+)
+
+// shadowSuffix names the lowercased copy ci=shadow stores next to a field.
+const shadowSuffix = "_ci"
+
+// shadowName returns the bson name of f's lowercased copy, or "" when f does
+// not use ci=shadow.
+func shadowName(f FieldSchema) string {
+	if f.CaseInsensitive != CaseInsensitiveShadow {
+		return ""
+	}
+	return f.BSONName + shadowSuffix
+}
+
+// indexedName returns the bson name goodm indexes for f: its lowercased copy
+// under ci=shadow, otherwise the field itself.
+func indexedName(f FieldSchema) string {
+	if name := shadowName(f); name != "" {
+		return name
+	}
+	return f.BSONName
+}
+
+// checkCaseInsensitive validates the ci tags of schema's fields. ci gives the
+// field's index the en_ci collation unless it declares a case-insensitive
+// collation of its own. ci=shadow replaces the field's index with one on the
+// lowercased copy, for servers without collation support.
+func checkCaseInsensitive(schema *Schema) error {
+	for i := range schema.Fields {
+		f := &schema.Fields[i]
+		if f.CaseInsensitive == "" {
+			continue
+		}
+		if f.Type != "string" {
+			return fmt.Errorf("goodm: %s.%s: ci requires a string field", schema.ModelName, f.Name)
+		}
+		if !f.Unique && !f.Index && len(f.UniqueWith) == 0 {
+			return fmt.Errorf("goodm: %s.%s: ci requires unique, uniquewith, or index", schema.ModelName, f.Name)
+		}
+		switch f.CaseInsensitive {
+		case CaseInsensitiveCollation:
+			if f.Collation == "" {
+				f.Collation = "en_ci"
+			} else if c, err := ParseCollation(f.Collation); err == nil && c.Strength == 0 {
+				return fmt.Errorf("goodm: %s.%s: ci conflicts with case-sensitive collation %q", schema.ModelName, f.Name, f.Collation)
+			}
+		case CaseInsensitiveShadow:
+			if f.Collation != "" {
+				return fmt.Errorf("goodm: %s.%s: ci=shadow cannot be combined with collation", schema.ModelName, f.Name)
+			}
+			if schema.HasField(shadowName(*f)) {
+				return fmt.Errorf("goodm: %s.%s: ci=shadow field %q collides with a model field", schema.ModelName, f.Name, shadowName(*f))
+			}
+			if f.Unique || f.Index {
+				schema.CompoundIndexes = append(schema.CompoundIndexes, CompoundIndex{
+					Fields: []string{shadowName(*f)},
+					Unique: f.Unique,
+				})
+				f.Unique, f.Index = false, false
+			}
+		default:
+			return fmt.Errorf("goodm: %s.%s: unknown ci strategy %q (want collation or shadow)", schema.ModelName, f.Name, f.CaseInsensitive)
+		}
+	}
+	return nil
+}
+
+// hasShadowFields returns true if any field in the schema uses ci=shadow.
+func hasShadowFields(schema *Schema) bool {
+	for _, f := range schema.Fields {
+		if shadowName(f) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// shadowValues returns the lowercased copies of model's ci=shadow fields,
+// keyed by shadow name. Like the field itself, the copy is not stored when
+// the field is omitempty and empty.
+func shadowValues(model interface{}, schema *Schema) bson.M {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	values := bson.M{}
+	for _, f := range schema.Fields {
+		name := shadowName(f)
+		if name == "" {
+			continue
+		}
+		fv := v.FieldByName(f.Name)
+		if !fv.IsValid() || fv.Kind() != reflect.String || f.OmitEmpty && fv.Len() == 0 {
+			continue
+		}
+		values[name] = strings.ToLower(fv.String())
+	}
+	return values
+}
+
+// shadowUpdate adds the lowercased copies of the ci=shadow fields an
+// UpdateFields call sets or unsets, and returns the $unset document.
+func shadowUpdate(schema *Schema, set, unset bson.M) bson.M {
+	for _, f := range schema.Fields {
+		name := shadowName(f)
+		if name == "" {
+			continue
+		}
+		if s, ok := set[f.BSONName].(string); ok {
+			set[name] = strings.ToLower(s)
+		} else if _, ok := unset[f.BSONName]; ok {
+			unset[name] = ""
+		}
+	}
+	return unset
+}
@@ -0,0 +1,115 @@
+package goodm
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type testLogin struct {
+	Model    `bson:",inline"`
+	Username string `bson:"username" goodm:"unique,ci"`
+}
+
+type testHandle struct {
+	Model    `bson:",inline"`
+	TenantID string `bson:"tenant_id"`
+	Handle   string `bson:"handle" goodm:"unique,ci=shadow"`
+	Nick     string `bson:"nick" goodm:"uniquewith=tenant_id,ci=shadow"`
+}
+
+func registerCaseModels(t *testing.T) {
+	t.Helper()
+	if err := Register(&testLogin{}, "test_logins"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := Register(&testHandle{}, "test_handles"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testLogin")
+		delete(registry, "testHandle")
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister_CaseInsensitive(t *testing.T) {
+	registerCaseModels(t)
+
+	logins, _ := Get("testLogin")
+	if f := logins.GetField("username"); !f.Unique || f.Collation != "en_ci" {
+		t.Fatalf("expected a unique en_ci index, got %+v", f)
+	}
+
+	handles, _ := Get("testHandle")
+	if f := handles.GetField("handle"); f.Unique {
+		t.Fatal("expected the unique index to move to the shadow field")
+	}
+	var names []string
+	for _, ci := range handles.CompoundIndexes {
+		names = append(names, compoundIndexName(ci))
+	}
+	if len(names) != 2 || names[0] != "handle_ci_1" || names[1] != "tenant_id_1_nick_ci_1" {
+		t.Fatalf("expected shadow indexes, got %v", names)
+	}
+
+	type notIndexed struct {
+		Model `bson:",inline"`
+		Name  string `bson:"name" goodm:"ci"`
+	}
+	type sensitive struct {
+		Model `bson:",inline"`
+		Name  string `bson:"name" goodm:"unique,ci,collation=en"`
+	}
+	type unknown struct {
+		Model `bson:",inline"`
+		Name  string `bson:"name" goodm:"unique,ci=lower"`
+	}
+	for _, m := range []interface{}{&notIndexed{}, &sensitive{}, &unknown{}} {
+		if err := Register(m, "test_bad_ci"); err == nil {
+			t.Fatalf("expected %T to be rejected", m)
+		}
+	}
+}
+
+func TestCaseInsensitive_Shadow(t *testing.T) {
+	ctx := useTestStore(t)
+	registerCaseModels(t)
+
+	h := &testHandle{TenantID: "a", Handle: "Alice", Nick: "Al"}
+	if err := Create(ctx, h); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var stored bson.M
+	if err := activeTestStore().Collection("test_handles").FindOne(ctx, bson.D{{Key: "_id", Value: h.ID}}).Decode(&stored); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if stored["handle"] != "Alice" || stored["handle_ci"] != "alice" || stored["nick_ci"] != "al" {
+		t.Fatalf("expected the original and lowercased values, got %v", stored)
+	}
+
+	if err := Create(ctx, &testHandle{TenantID: "b", Handle: "ALICE"}); !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("expected a case-insensitive duplicate, got %v", err)
+	}
+	var verrs ValidationErrors
+	if err := Create(ctx, &testHandle{TenantID: "a", Handle: "bob", Nick: "AL"}); !errors.As(err, &verrs) {
+		t.Fatalf("expected a scoped validation error, got %v", err)
+	}
+
+	if err := UpdateFields(ctx, h, bson.M{"handle": "Alicia"}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "handle_ci", Value: "alicia"}}, &testHandle{}); err != nil {
+		t.Fatalf("expected UpdateFields to refresh the shadow field: %v", err)
+	}
+	h.Handle = "ALI"
+	if err := Update(ctx, h); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "handle_ci", Value: "ali"}}, &testHandle{}, FindOptions{Strict: true}); err != nil {
+		t.Fatalf("expected Update to refresh the shadow field: %v", err)
+	}
+}
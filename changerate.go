@@ -0,0 +1,221 @@
+package goodm
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultRateWindow is the sliding window a ChangeRateCollector counts writes
+// over when no Window is set.
+const DefaultRateWindow = time.Minute
+
+// rateBuckets is the number of buckets a window is divided into; the window
+// slides one bucket at a time.
+const rateBuckets = 12
+
+// ChangeRateOptions configures a ChangeRateCollector.
+type ChangeRateOptions struct {
+	Window    time.Duration     // sliding window writes are counted over (default DefaultRateWindow)
+	Max       int64             // a window count above Max is an anomaly; 0 disables
+	Factor    float64           // a window count above Factor times the baseline is an anomaly; 0 disables
+	MinCount  int64             // window counts below this never trigger a Factor anomaly (default 10)
+	OnAnomaly func(RateAnomaly) // called when a rate crosses Max or Factor
+}
+
+// ChangeRate is the write rate of one kind of change to one model.
+type ChangeRate struct {
+	ModelName string
+	Operation OpType  // OpCreate, OpUpdate, or OpDelete; the *Many variants are counted with these
+	Count     int64   // writes in the current window
+	Total     int64   // writes since the first one seen, or since Reset
+	Baseline  float64 // average writes per window before the current one; 0 until a full window has passed
+}
+
+// RateAnomaly reports a change rate that crossed a threshold.
+type RateAnomaly struct {
+	ChangeRate
+	Reason string // "max" or "factor"
+}
+
+// ChangeRateCollector counts successful creates, updates, and deletes per
+// model over a sliding window, and calls OnAnomaly when a model's rate
+// exceeds a fixed maximum or deviates from its own history, for example
+// when a runaway job starts rewriting a collection. Install its middleware
+// to start counting; Rates returns the current counts for metrics export.
+//
+// Each call counts once: CreateMany, UpdateMany, and DeleteMany count as one
+// write regardless of how many documents they touch. OnAnomaly runs on the
+// goroutine of the write that crossed the threshold, once per crossing; the
+// rate must fall back below the threshold before it fires again.
+//
+// Example:
+//
+//	rates := goodm.NewChangeRateCollector(goodm.ChangeRateOptions{
+//	    Window: time.Minute,
+//	    Factor: 5,
+//	    OnAnomaly: func(a goodm.RateAnomaly) {
+//	        log.Printf("%s %s rate %d/min, usually %.0f", a.ModelName, a.Operation, a.Count, a.Baseline)
+//	    },
+//	})
+//	goodm.Use(rates.Middleware())
+type ChangeRateCollector struct {
+	opt ChangeRateOptions
+	now func() time.Time
+
+	mu    sync.Mutex
+	rates map[rateKey]*rateCounter
+}
+
+type rateKey struct {
+	modelName string
+	op        OpType
+}
+
+// rateCounter holds the bucketed counts of one model and kind of change.
+type rateCounter struct {
+	buckets   [rateBuckets]int64
+	head      int       // index of the current bucket
+	headStart time.Time // when the current bucket began
+	since     time.Time // first write seen
+	total     int64
+	alerting  bool // a threshold is crossed; OnAnomaly already fired
+}
+
+// NewChangeRateCollector creates a ChangeRateCollector with no counts.
+func NewChangeRateCollector(opts ...ChangeRateOptions) *ChangeRateCollector {
+	var opt ChangeRateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Window <= 0 {
+		opt.Window = DefaultRateWindow
+	}
+	if opt.MinCount <= 0 {
+		opt.MinCount = 10
+	}
+	return &ChangeRateCollector{opt: opt, now: time.Now, rates: make(map[rateKey]*rateCounter)}
+}
+
+// Middleware returns a MiddlewareFunc that records every create, update, and
+// delete that succeeds.
+func (c *ChangeRateCollector) Middleware() MiddlewareFunc {
+	return func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		var kind OpType
+		switch op.Operation {
+		case OpCreate, OpCreateMany:
+			kind = OpCreate
+		case OpUpdate, OpUpdateMany:
+			kind = OpUpdate
+		case OpDelete, OpDeleteMany:
+			kind = OpDelete
+		default:
+			return next(ctx)
+		}
+		if err := next(ctx); err != nil {
+			return err
+		}
+		c.Record(op.ModelName, kind)
+		return nil
+	}
+}
+
+// Record counts one write of kind (OpCreate, OpUpdate, or OpDelete) to the
+// named model, for writes made outside goodm's CRUD functions.
+func (c *ChangeRateCollector) Record(modelName string, kind OpType) {
+	now := c.now()
+	key := rateKey{modelName, kind}
+
+	c.mu.Lock()
+	r := c.rates[key]
+	if r == nil {
+		r = &rateCounter{headStart: now, since: now}
+		c.rates[key] = r
+	}
+	c.advance(r, now)
+	r.buckets[r.head]++
+	r.total++
+	rate := c.rate(key, r, now)
+
+	reason := ""
+	switch {
+	case c.opt.Max > 0 && rate.Count > c.opt.Max:
+		reason = "max"
+	case c.opt.Factor > 0 && rate.Count >= c.opt.MinCount && c.hasBaseline(r, now) &&
+		float64(rate.Count) > c.opt.Factor*rate.Baseline:
+		reason = "factor"
+	}
+	fire := reason != "" && !r.alerting
+	r.alerting = reason != ""
+	c.mu.Unlock()
+
+	if fire && c.opt.OnAnomaly != nil {
+		c.opt.OnAnomaly(RateAnomaly{ChangeRate: rate, Reason: reason})
+	}
+}
+
+// Rates returns the current rate of every model and kind of change seen,
+// sorted by model name and operation.
+func (c *ChangeRateCollector) Rates() []ChangeRate {
+	now := c.now()
+	c.mu.Lock()
+	out := make([]ChangeRate, 0, len(c.rates))
+	for key, r := range c.rates {
+		c.advance(r, now)
+		out = append(out, c.rate(key, r, now))
+	}
+	c.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ModelName != out[j].ModelName {
+			return out[i].ModelName < out[j].ModelName
+		}
+		return out[i].Operation < out[j].Operation
+	})
+	return out
+}
+
+// Reset discards all counts and history.
+func (c *ChangeRateCollector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rates = make(map[rateKey]*rateCounter)
+}
+
+// advance slides r's window forward to now, clearing the buckets it passes.
+func (c *ChangeRateCollector) advance(r *rateCounter, now time.Time) {
+	size := c.opt.Window / rateBuckets
+	steps := int(now.Sub(r.headStart) / size)
+	if steps <= 0 {
+		return
+	}
+	if steps >= rateBuckets {
+		r.buckets = [rateBuckets]int64{}
+	} else {
+		for i := 1; i <= steps; i++ {
+			r.buckets[(r.head+i)%rateBuckets] = 0
+		}
+	}
+	r.head = (r.head + steps) % rateBuckets
+	r.headStart = r.headStart.Add(time.Duration(steps) * size)
+}
+
+// rate returns r's counts and baseline as of now.
+func (c *ChangeRateCollector) rate(key rateKey, r *rateCounter, now time.Time) ChangeRate {
+	rate := ChangeRate{ModelName: key.modelName, Operation: key.op, Total: r.total}
+	for _, n := range r.buckets {
+		rate.Count += n
+	}
+	if c.hasBaseline(r, now) {
+		windows := float64(now.Sub(r.since)-c.opt.Window) / float64(c.opt.Window)
+		rate.Baseline = float64(r.total-rate.Count) / windows
+	}
+	return rate
+}
+
+// hasBaseline reports whether r has at least one full window of history
+// before the current one.
+func (c *ChangeRateCollector) hasBaseline(r *rateCounter, now time.Time) bool {
+	return now.Sub(r.since) >= 2*c.opt.Window
+}
@@ -0,0 +1,144 @@
+package goodm
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeClock is a settable time source for ChangeRateCollector tests.
+type fakeClock struct{ t time.Time }
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// rateOf returns the window count of op in rates, or -1 if it is missing.
+func rateOf(rates []ChangeRate, op OpType) int64 {
+	for _, r := range rates {
+		if r.Operation == op {
+			return r.Count
+		}
+	}
+	return -1
+}
+
+func TestChangeRateCollector_Middleware(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	rates := NewChangeRateCollector()
+	Use(rates.Middleware())
+
+	u := &testUser{Email: "rate@test.com", Name: "Rate"}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	u.Age = 5
+	if err := Update(ctx, u); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := UpdateMany(ctx, bson.D{}, bson.D{{Key: "$set", Value: bson.D{{Key: "age", Value: 6}}}}, &testUser{}); err != nil {
+		t.Fatalf("update many: %v", err)
+	}
+	if err := Create(ctx, &testUser{Email: "rate@test.com", Name: "Dup"}); err == nil {
+		t.Fatal("expected a duplicate key error")
+	}
+	var found []testUser
+	if err := Find(ctx, bson.D{}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	got := rates.Rates()
+	if len(got) != 2 || got[0].ModelName != "testUser" {
+		t.Fatalf("unexpected rates: %+v", got)
+	}
+	if rateOf(got, OpCreate) != 1 || rateOf(got, OpUpdate) != 2 {
+		t.Errorf("unexpected counts: %+v", got)
+	}
+
+	rates.Reset()
+	if len(rates.Rates()) != 0 {
+		t.Error("Reset kept counts")
+	}
+}
+
+func TestChangeRateCollector_Window(t *testing.T) {
+	clock := newFakeClock()
+	rates := NewChangeRateCollector(ChangeRateOptions{Window: time.Minute})
+	rates.now = clock.now
+
+	for i := 0; i < 3; i++ {
+		rates.Record("Order", OpCreate)
+	}
+	clock.advance(30 * time.Second)
+	rates.Record("Order", OpCreate)
+	if n := rateOf(rates.Rates(), OpCreate); n != 4 {
+		t.Fatalf("Count = %d, want 4", n)
+	}
+
+	clock.advance(35 * time.Second)
+	if n := rateOf(rates.Rates(), OpCreate); n != 1 {
+		t.Fatalf("Count = %d after the first writes left the window, want 1", n)
+	}
+	clock.advance(time.Hour)
+	r := rates.Rates()[0]
+	if r.Count != 0 || r.Total != 4 {
+		t.Fatalf("unexpected rate after an hour: %+v", r)
+	}
+}
+
+func TestChangeRateCollector_Anomalies(t *testing.T) {
+	clock := newFakeClock()
+	var anomalies []RateAnomaly
+	rates := NewChangeRateCollector(ChangeRateOptions{
+		Window:    time.Minute,
+		Max:       100,
+		Factor:    3,
+		MinCount:  5,
+		OnAnomaly: func(a RateAnomaly) { anomalies = append(anomalies, a) },
+	})
+	rates.now = clock.now
+
+	// Ten minutes at 4 deletes a minute establish the baseline.
+	for m := 0; m < 10; m++ {
+		for i := 0; i < 4; i++ {
+			rates.Record("Order", OpDelete)
+		}
+		clock.advance(time.Minute)
+	}
+	if len(anomalies) != 0 {
+		t.Fatalf("steady rate reported: %+v", anomalies)
+	}
+
+	for i := 0; i < 20; i++ {
+		rates.Record("Order", OpDelete)
+	}
+	if len(anomalies) != 1 {
+		t.Fatalf("expected one factor anomaly, got %+v", anomalies)
+	}
+	a := anomalies[0]
+	if a.Reason != "factor" || a.ModelName != "Order" || a.Operation != OpDelete || a.Count != 14 || a.Baseline < 3.5 || a.Baseline > 4.5 {
+		t.Errorf("unexpected anomaly: %+v", a)
+	}
+
+	for i := 0; i < 100; i++ {
+		rates.Record("Order", OpDelete)
+	}
+	if len(anomalies) != 1 {
+		t.Fatalf("anomaly fired again without recovering: %+v", anomalies[1:])
+	}
+
+	clock.advance(2 * time.Minute)
+	rates.Record("Order", OpDelete)
+	for i := 0; i < 101; i++ {
+		rates.Record("Customer", OpCreate)
+	}
+	if len(anomalies) != 2 || anomalies[1].Reason != "max" || anomalies[1].ModelName != "Customer" || anomalies[1].Count != 101 {
+		t.Errorf("expected a max anomaly for Customer, got %+v", anomalies)
+	}
+}
@@ -0,0 +1,71 @@
+package goodm
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time for the timestamps goodm sets.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+var (
+	clockMu           sync.RWMutex
+	clock             Clock
+	clockTruncateToMS bool
+)
+
+// SetClock replaces the clock goodm reads when it sets CreatedAt and
+// UpdatedAt, in Create, CreateMany, Update, UpdateFields, PartialUpdate, Touch,
+// FindOneAndUpdate, and tree moves. Tests use it to freeze time. A nil clock
+// restores the system clock.
+//
+// Example:
+//
+//	frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+//	goodm.SetClock(goodm.ClockFunc(func() time.Time { return frozen }))
+//	defer goodm.SetClock(nil)
+func SetClock(c Clock) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	clock = c
+}
+
+// SetMillisecondTimestamps enables or disables truncating the timestamps goodm
+// sets to whole milliseconds. BSON datetimes store milliseconds, so with
+// truncation a model's CreatedAt and UpdatedAt equal those of the same
+// document read back, and reflect.DeepEqual comparisons in tests hold.
+// Disabled by default.
+func SetMillisecondTimestamps(enabled bool) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	clockTruncateToMS = enabled
+}
+
+// clockNow returns the current time of the clock set with SetClock,
+// truncated to milliseconds if SetMillisecondTimestamps is enabled.
+func clockNow() time.Time {
+	clockMu.RLock()
+	c, truncate := clock, clockTruncateToMS
+	clockMu.RUnlock()
+
+	var now time.Time
+	if c != nil {
+		now = c.Now()
+	} else {
+		now = time.Now()
+	}
+	if truncate {
+		now = now.Truncate(time.Millisecond)
+	}
+	return now
+}
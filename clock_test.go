@@ -0,0 +1,83 @@
+package goodm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSetClock(t *testing.T) {
+	ctx := useTestStore(t)
+	t.Cleanup(func() { SetClock(nil) })
+
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	current := created
+	SetClock(ClockFunc(func() time.Time { return current }))
+
+	u := &testUser{Email: "clock@test.com", Name: "Clock"}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !u.CreatedAt.Equal(created) || !u.UpdatedAt.Equal(created) {
+		t.Errorf("expected frozen timestamps, got %v and %v", u.CreatedAt, u.UpdatedAt)
+	}
+
+	current = created.Add(time.Hour)
+	u.Age = 31
+	if err := Update(ctx, u); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !u.CreatedAt.Equal(created) || !u.UpdatedAt.Equal(current) {
+		t.Errorf("expected UpdatedAt to follow the clock, got %v and %v", u.CreatedAt, u.UpdatedAt)
+	}
+
+	current = created.Add(2 * time.Hour)
+	if err := Touch(ctx, u); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	var found testUser
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if !found.UpdatedAt.Equal(current) {
+		t.Errorf("expected touched UpdatedAt %v, got %v", current, found.UpdatedAt)
+	}
+}
+
+func TestSetMillisecondTimestamps(t *testing.T) {
+	ctx := useTestStore(t)
+	t.Cleanup(func() {
+		SetClock(nil)
+		SetMillisecondTimestamps(false)
+	})
+	SetClock(ClockFunc(func() time.Time {
+		return time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	}))
+
+	SetMillisecondTimestamps(true)
+	u := &testUser{Email: "ms@test.com", Name: "Millis"}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if u.CreatedAt.Nanosecond() != 123000000 {
+		t.Errorf("expected millisecond precision, got %v", u.CreatedAt)
+	}
+	var found testUser
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if !reflect.DeepEqual(found.CreatedAt.UTC(), u.CreatedAt.UTC()) {
+		t.Errorf("expected the stored timestamp to match, got %v and %v", found.CreatedAt, u.CreatedAt)
+	}
+
+	SetMillisecondTimestamps(false)
+	v := &testUser{Email: "ns@test.com", Name: "Nanos"}
+	if err := Create(ctx, v); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if v.CreatedAt.Nanosecond() != 123456789 {
+		t.Errorf("expected full precision without truncation, got %v", v.CreatedAt)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dwoolworth/goodm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	driftURI      string
+	driftDB       string
+	driftWatch    bool
+	driftInterval time.Duration
+	driftSample   int
+	driftWebhook  string
+)
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Detect fields in the database that registered schemas do not declare",
+	Long:  "Sample the collections of all registered models and report fields that exist in the database but not in the schema. With --watch, keep checking every --interval and report new drift as it appears, optionally to a webhook.",
+	RunE:  runDrift,
+}
+
+func init() {
+	driftCmd.Flags().StringVar(&driftURI, "uri", "mongodb://localhost:27017", "MongoDB connection URI")
+	driftCmd.Flags().StringVar(&driftDB, "db", "", "MongoDB database name")
+	driftCmd.Flags().BoolVar(&driftWatch, "watch", false, "Keep checking until interrupted")
+	driftCmd.Flags().DurationVar(&driftInterval, "interval", goodm.DefaultDriftWatchInterval, "Time between checks with --watch")
+	driftCmd.Flags().IntVar(&driftSample, "sample", goodm.DefaultDriftSampleSize, "Documents sampled per collection")
+	driftCmd.Flags().StringVar(&driftWebhook, "webhook", "", "URL to POST new drift to as JSON, with --watch")
+	_ = driftCmd.MarkFlagRequired("db")
+}
+
+func runDrift(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	db, err := goodm.Connect(connectCtx, driftURI, driftDB)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	schemas := goodm.GetAll()
+	if len(schemas) == 0 {
+		fmt.Println("No models registered. Import your model packages to register them.")
+		return nil
+	}
+
+	if !driftWatch {
+		if driftWebhook != "" {
+			return fmt.Errorf("--webhook requires --watch")
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		total := 0
+		for _, schema := range schemas {
+			for _, d := range goodm.DetectDrift(checkCtx, db, schema, driftSample) {
+				fmt.Printf("  ⚠ %s.%s: %s\n", d.Collection, d.Field, d.Message)
+				total++
+			}
+		}
+		if total > 0 {
+			return fmt.Errorf("%d drifted fields", total)
+		}
+		fmt.Println("✓ No drift detected")
+		return nil
+	}
+
+	fmt.Printf("Watching %s for drift every %s (Ctrl-C to stop)\n", driftDB, driftInterval)
+	w, err := goodm.StartDriftWatcher(ctx, goodm.DriftWatch{
+		Interval:   driftInterval,
+		SampleSize: driftSample,
+		Webhook:    driftWebhook,
+		DB:         db,
+		OnDrift: func(ctx context.Context, drifts []goodm.DriftError) {
+			now := time.Now().Format(time.RFC3339)
+			for _, d := range drifts {
+				fmt.Printf("%s ⚠ %s.%s: %s\n", now, d.Collection, d.Field, d.Message)
+			}
+		},
+		OnError: func(err error) {
+			fmt.Fprintf(os.Stderr, "%s ✗ %v\n", time.Now().Format(time.RFC3339), err)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return w.Stop()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dwoolworth/goodm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fieldsURI        string
+	fieldsDB         string
+	fieldsCollection string
+	fieldsSample     int
+)
+
+var fieldsCmd = &cobra.Command{
+	Use:   "fields",
+	Short: "Report how often each field of a collection is present, null, and of which type",
+	Long:  "Sample documents of a collection and report, for every top-level field, the share of documents holding it, how often it is null, and the types it holds. Fields that no registered model declares are marked, to help decide which drift fields are safe to prune.",
+	RunE:  runFields,
+}
+
+func init() {
+	fieldsCmd.Flags().StringVar(&fieldsURI, "uri", "mongodb://localhost:27017", "MongoDB connection URI")
+	fieldsCmd.Flags().StringVar(&fieldsDB, "db", "", "MongoDB database name")
+	fieldsCmd.Flags().StringVar(&fieldsCollection, "collection", "", "Collection to analyze")
+	fieldsCmd.Flags().IntVar(&fieldsSample, "sample-size", goodm.DefaultFieldStatsSampleSize, "Number of documents to sample")
+	_ = fieldsCmd.MarkFlagRequired("db")
+	_ = fieldsCmd.MarkFlagRequired("collection")
+}
+
+func runFields(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	db, err := goodm.Connect(ctx, fieldsURI, fieldsDB)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	report, err := goodm.FieldStats(ctx, db, fieldsCollection, fieldsSample)
+	if err != nil {
+		return err
+	}
+	if report.Sampled == 0 {
+		fmt.Printf("%s has no documents.\n", fieldsCollection)
+		return nil
+	}
+
+	fmt.Printf("%s (sampled %d of %d documents)\n\n", report.Collection, report.Sampled, report.Total)
+
+	width := len("FIELD")
+	for _, f := range report.Fields {
+		if len(f.Field) > width {
+			width = len(f.Field)
+		}
+	}
+	fmt.Printf("  %-*s  %8s  %6s  %s\n", width, "FIELD", "PRESENT", "NULL", "TYPES")
+	for _, f := range report.Fields {
+		types := strings.Join(f.TypeNames(), ", ")
+		if types == "" {
+			types = "null"
+		}
+		line := fmt.Sprintf("  %-*s  %7.1f%%  %5.1f%%  %s", width, f.Field,
+			100*f.Presence(report.Sampled), 100*f.NullRate(), types)
+		if report.Registered && !f.Declared {
+			line += "  ⚠ not in schema"
+		}
+		fmt.Println(line)
+	}
+	if !report.Registered {
+		fmt.Println()
+		fmt.Println("No registered model uses this collection; import your model packages to mark undeclared fields.")
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dwoolworth/goodm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	genOut       string
+	genBSONNames bool
+	genQuery     bool
+	genModel     string
+	genRouter    string
+	genModels    []string
+
+	// Each command binds its own --package variable: pflag writes a flag's
+	// default into its variable on registration, so a shared variable would
+	// take the default of whichever command registered last.
+	genRESTPackage   string
+	genEnumsPackage  string
+	genCodecsPackage string
+)
+
+var genCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "Generate code from registered models",
+}
+
+var genTSCmd = &cobra.Command{
+	Use:   "ts",
+	Short: "Generate TypeScript interfaces from registered models",
+	Long:  "Write a TypeScript interface for every registered model and subdocument type, so frontend code shares the models' shape.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemas := goodm.GetAll()
+		if len(schemas) == 0 {
+			fmt.Println("No models registered. Import your model packages to register them.")
+			return nil
+		}
+		return writeGenerated(goodm.GenerateTypeScript(schemas, goodm.TypeScriptOptions{BSONNames: genBSONNames}))
+	},
+}
+
+var genGraphQLCmd = &cobra.Command{
+	Use:   "graphql",
+	Short: "Generate a GraphQL schema from registered models",
+	Long:  "Write GraphQL SDL with an object type for every registered model and subdocument type, including fields for ref, has_one, and has_many relations.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemas := goodm.GetAll()
+		if len(schemas) == 0 {
+			fmt.Println("No models registered. Import your model packages to register them.")
+			return nil
+		}
+		return writeGenerated(goodm.GenerateGraphQL(schemas, goodm.GraphQLOptions{BSONNames: genBSONNames, Query: genQuery}))
+	},
+}
+
+var genRESTCmd = &cobra.Command{
+	Use:   "rest",
+	Short: "Generate REST CRUD handlers for a registered model",
+	Long:  "Write HTTP handlers for list (paginated), get, create, update, and delete on one registered model, built on goodm.Store, with goodm errors mapped to HTTP status codes.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, ok := goodm.Get(genModel)
+		if !ok {
+			return fmt.Errorf("model %q is not registered", genModel)
+		}
+		src, err := goodm.GenerateREST(schema, goodm.RESTOptions{PackageName: genRESTPackage, Router: genRouter})
+		if err != nil {
+			return err
+		}
+		return writeGenerated(string(src))
+	},
+}
+
+var genEnumsCmd = &cobra.Command{
+	Use:   "enums",
+	Short: "Generate Go constants for enum-tagged fields",
+	Long:  "Write a string-typed Go type for every enum-tagged field of the registered models, with a constant per allowed value and IsValid and Values methods, so code and tags cannot drift apart.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemas := goodm.GetAll()
+		if len(schemas) == 0 {
+			fmt.Println("No models registered. Import your model packages to register them.")
+			return nil
+		}
+		src, err := goodm.GenerateEnums(schemas, goodm.EnumOptions{PackageName: genEnumsPackage})
+		if err != nil {
+			return err
+		}
+		return writeGenerated(string(src))
+	},
+}
+
+var genCodecsCmd = &cobra.Command{
+	Use:   "codecs",
+	Short: "Generate BSON codecs that bypass the struct codec for registered models",
+	Long:  "Write MarshalBSON and UnmarshalBSON methods for registered models, so encoding and decoding them on hot paths skips the driver's reflection-based struct codec. Place the file in the package declaring the models and regenerate it whenever they change.\n\nThe generated code uses the driver's experimental go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore package, which has no compatibility promise, so also regenerate it after upgrading the driver.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemas := goodm.GetAll()
+		if len(genModels) > 0 {
+			selected := make(map[string]*goodm.Schema, len(genModels))
+			for _, name := range genModels {
+				schema, ok := schemas[name]
+				if !ok {
+					return fmt.Errorf("model %q is not registered", name)
+				}
+				selected[name] = schema
+			}
+			schemas = selected
+		}
+		if len(schemas) == 0 {
+			fmt.Println("No models registered. Import your model packages to register them.")
+			return nil
+		}
+		src, err := goodm.GenerateCodecs(schemas, goodm.CodecOptions{PackageName: genCodecsPackage})
+		if err != nil {
+			return err
+		}
+		return writeGenerated(string(src))
+	},
+}
+
+func init() {
+	genCmd.PersistentFlags().StringVarP(&genOut, "out", "o", "", "Output file (default: stdout)")
+	genTSCmd.Flags().BoolVar(&genBSONNames, "bson-names", false, "Name properties after bson tags instead of JSON names")
+	genGraphQLCmd.Flags().BoolVar(&genBSONNames, "bson-names", false, "Name fields after bson tags instead of JSON names")
+	genGraphQLCmd.Flags().BoolVar(&genQuery, "query", false, "Add a Query type with a lookup and a list for every model")
+	genRESTCmd.Flags().StringVar(&genModel, "model", "", "Registered model name (e.g. User)")
+	genRESTCmd.Flags().StringVar(&genRouter, "router", "stdlib", "Router to target: stdlib or chi")
+	genRESTCmd.Flags().StringVar(&genRESTPackage, "package", "handlers", "Go package name for the generated file")
+	_ = genRESTCmd.MarkFlagRequired("model")
+	genEnumsCmd.Flags().StringVar(&genEnumsPackage, "package", "models", "Go package name for the generated file")
+	genCmd.AddCommand(genTSCmd)
+	genCmd.AddCommand(genGraphQLCmd)
+	genCmd.AddCommand(genRESTCmd)
+	genCodecsCmd.Flags().StringVar(&genCodecsPackage, "package", "models", "Go package name for the generated file")
+	genCodecsCmd.Flags().StringSliceVar(&genModels, "model", nil, "Registered model names to generate codecs for (default: all)")
+	genCmd.AddCommand(genEnumsCmd)
+	genCmd.AddCommand(genCodecsCmd)
+}
+
+// writeGenerated writes generated code to --out, or to stdout.
+func writeGenerated(code string) error {
+	if genOut == "" {
+		fmt.Print(code)
+		return nil
+	}
+	if err := os.WriteFile(genOut, []byte(code), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", genOut)
+	return nil
+}
@@ -2,25 +2,30 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/dwoolworth/goodm"
+	"github.com/dwoolworth/goodm/internal"
 	"github.com/spf13/cobra"
 )
 
 var (
-	diffFlag bool
-	mongoURI string
-	dbName   string
+	diffFlag      bool
+	erdFlag       bool
+	inspectFormat string
+	mongoURI      string
+	dbName        string
 )
 
 var inspectCmd = &cobra.Command{
 	Use:   "inspect",
 	Short: "Inspect registered model schemas",
-	Long:  "Display all registered model schemas with fields, indexes, and relations. Use --diff to compare against a live MongoDB instance.",
+	Long:  "Display all registered model schemas with fields, indexes, and relations. Use --diff to compare against a live MongoDB instance, or --format json|yaml for machine-readable output.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		schemas := goodm.GetAll()
 		if len(schemas) == 0 {
@@ -28,6 +33,18 @@ var inspectCmd = &cobra.Command{
 			return nil
 		}
 
+		if inspectFormat != "text" {
+			if diffFlag || erdFlag {
+				return fmt.Errorf("--diff and --erd cannot be combined with --format %s", inspectFormat)
+			}
+			return printSchemasAs(schemas, inspectFormat)
+		}
+
+		if erdFlag {
+			fmt.Print(goodm.GenerateERD(schemas))
+			return nil
+		}
+
 		for _, schema := range schemas {
 			printSchema(schema)
 
@@ -44,10 +61,44 @@ var inspectCmd = &cobra.Command{
 
 func init() {
 	inspectCmd.Flags().BoolVar(&diffFlag, "diff", false, "Compare schemas against live MongoDB")
+	inspectCmd.Flags().BoolVar(&erdFlag, "erd", false, "Print relations as a Mermaid entity-relationship diagram")
+	inspectCmd.Flags().StringVar(&inspectFormat, "format", "text", "Output format: text, json, or yaml")
 	inspectCmd.Flags().StringVar(&mongoURI, "uri", "mongodb://localhost:27017", "MongoDB connection URI")
 	inspectCmd.Flags().StringVar(&dbName, "db", "", "MongoDB database name (required with --diff)")
 }
 
+// printSchemasAs prints the schemas, sorted by model name, as a JSON or YAML
+// list of goodm.Schema.
+func printSchemasAs(schemas map[string]*goodm.Schema, format string) error {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]*goodm.Schema, len(names))
+	for i, name := range names {
+		list[i] = schemas[name]
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		fmt.Println(string(data))
+	case "yaml":
+		out, err := internal.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+	default:
+		return fmt.Errorf("unknown --format %q: expected text, json, or yaml", format)
+	}
+	return nil
+}
+
 func printSchema(schema *goodm.Schema) {
 	fmt.Printf("%s (collection: %s)\n", schema.ModelName, schema.Collection)
 
@@ -69,12 +120,11 @@ func printSchema(schema *goodm.Schema) {
 	printIndexes(schema)
 
 	// Print relations
-	refs := collectRefs(schema)
-	if len(refs) > 0 {
+	if len(schema.Relations) > 0 {
 		fmt.Println()
 		fmt.Println("  Relations:")
-		for _, r := range refs {
-			fmt.Printf("    → %s (1:1 via %s)\n", r.ref, r.field)
+		for _, r := range schema.Relations {
+			fmt.Printf("    %s\n", formatRelation(r))
 		}
 	}
 
@@ -125,6 +175,20 @@ func formatFieldAttrs(f goodm.FieldSchema) string {
 	if f.Immutable {
 		parts = append(parts, "immutable")
 	}
+	if f.WriteOnce {
+		parts = append(parts, "writeonce")
+	}
+	if f.Hidden {
+		parts = append(parts, "hidden")
+	}
+	if f.Deprecated && f.DeprecatedNote != "" {
+		parts = append(parts, fmt.Sprintf("deprecated: %s", f.DeprecatedNote))
+	} else if f.Deprecated {
+		parts = append(parts, "deprecated")
+	}
+	if f.RenamedFrom != "" {
+		parts = append(parts, fmt.Sprintf("renamed from: %s", f.RenamedFrom))
+	}
 	if len(f.Enum) > 0 {
 		parts = append(parts, fmt.Sprintf("enum(%s)", strings.Join(f.Enum, "|")))
 	}
@@ -137,6 +201,9 @@ func formatFieldAttrs(f goodm.FieldSchema) string {
 	if f.Max != nil {
 		parts = append(parts, fmt.Sprintf("max: %d", *f.Max))
 	}
+	if len(f.Normalize) > 0 {
+		parts = append(parts, fmt.Sprintf("normalize(%s)", strings.Join(f.Normalize, "|")))
+	}
 	return strings.Join(parts, ", ")
 }
 
@@ -157,19 +224,20 @@ func compoundName(ci goodm.CompoundIndex) string {
 	return strings.Join(parts, "_")
 }
 
-type refInfo struct {
-	field string
-	ref   string
-}
-
-func collectRefs(schema *goodm.Schema) []refInfo {
-	var refs []refInfo
-	for _, f := range schema.Fields {
-		if f.Ref != "" {
-			refs = append(refs, refInfo{field: f.BSONName, ref: f.Ref})
-		}
+func formatRelation(r goodm.Relation) string {
+	var s string
+	switch r.Kind {
+	case goodm.RelationBelongsTo:
+		s = fmt.Sprintf("→ %s (belongs to via %s)", r.Collection, r.LocalField)
+	case goodm.RelationHasOne:
+		s = fmt.Sprintf("← %s (has one %s via %s.%s)", r.Collection, r.Name, r.Collection, r.ForeignKey)
+	case goodm.RelationHasMany:
+		s = fmt.Sprintf("← %s (has many %s via %s.%s)", r.Collection, r.Name, r.Collection, r.ForeignKey)
+	}
+	if r.OnDelete != goodm.OnDeleteNone {
+		s += fmt.Sprintf(" [ondelete=%s]", r.OnDelete)
 	}
-	return refs
+	return s
 }
 
 func printDiff(schema *goodm.Schema) error {
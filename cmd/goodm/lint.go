@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dwoolworth/goodm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintFix    bool
+	lintRename bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check model source files for common mistakes",
+}
+
+var lintJSONCmd = &cobra.Command{
+	Use:   "json [paths...]",
+	Short: "Check that json tags match bson names",
+	Long:  "Report struct fields whose json tag is missing or names the field differently than its bson tag, in Go files under the given paths (default: the current directory). With --fix, add the missing json tags.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		files, err := goFiles(args)
+		if err != nil {
+			return err
+		}
+
+		remaining, fixed := 0, 0
+		for _, file := range files {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			out, issues, err := goodm.FixJSONTags(file, src, goodm.JSONTagOptions{Rename: lintRename})
+			if err != nil {
+				return err
+			}
+			for _, issue := range issues {
+				if lintFix && (issue.JSONName == "" || lintRename) {
+					fmt.Printf("  ✓ %s\n", issue)
+					fixed++
+					continue
+				}
+				fmt.Printf("  ✗ %s\n", issue)
+				remaining++
+			}
+			if lintFix && string(out) != string(src) {
+				if err := os.WriteFile(file, out, 0o644); err != nil {
+					return err
+				}
+			}
+		}
+
+		if remaining == 0 && fixed == 0 {
+			fmt.Println("✓ json tags match bson names")
+			return nil
+		}
+		fmt.Println()
+		fmt.Printf("Summary: %d fixed, %d remaining\n", fixed, remaining)
+		if remaining > 0 {
+			return fmt.Errorf("%d fields with missing or mismatched json tags", remaining)
+		}
+		return nil
+	},
+}
+
+var lintDeprecatedCmd = &cobra.Command{
+	Use:   "deprecated [paths...]",
+	Short: "Find uses of fields tagged deprecated",
+	Long:  "Report references to struct fields tagged goodm:\"deprecated\" in Go files under the given paths (default: the current directory): field selectors, struct literal keys, and the field's bson name as a filter key. Include the packages that declare the models.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		paths, err := goFiles(args)
+		if err != nil {
+			return err
+		}
+		files := make(map[string][]byte, len(paths))
+		for _, path := range paths {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[path] = src
+		}
+
+		uses, err := goodm.FindDeprecatedUses(files)
+		if err != nil {
+			return err
+		}
+		if len(uses) == 0 {
+			fmt.Println("✓ no uses of deprecated fields")
+			return nil
+		}
+		for _, use := range uses {
+			fmt.Printf("  ✗ %s\n", use)
+		}
+		fmt.Println()
+		return fmt.Errorf("%d uses of deprecated fields", len(uses))
+	},
+}
+
+var lintRegexCmd = &cobra.Command{
+	Use:   "regex [paths...]",
+	Short: "Find regexes that prevent index use",
+	Long:  "Report regex patterns written as string literals in Go files under the given paths (default: the current directory) that cannot use an index efficiently: patterns not anchored with ^, starting with a wildcard, or case-insensitive. Patterns passed to goodm.Regex, bson.Regex literals, and \"$regex\" values are checked.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		paths, err := goFiles(args)
+		if err != nil {
+			return err
+		}
+		files := make(map[string][]byte, len(paths))
+		for _, path := range paths {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[path] = src
+		}
+
+		issues, err := goodm.FindRegexIssues(files)
+		if err != nil {
+			return err
+		}
+		if len(issues) == 0 {
+			fmt.Println("✓ all regexes can use an index")
+			return nil
+		}
+		for _, issue := range issues {
+			fmt.Printf("  ⚠ %s\n", issue)
+		}
+		fmt.Println()
+		return fmt.Errorf("%d regexes that prevent index use", len(issues))
+	},
+}
+
+func init() {
+	lintCmd.AddCommand(lintDeprecatedCmd)
+	lintCmd.AddCommand(lintRegexCmd)
+	lintJSONCmd.Flags().BoolVar(&lintFix, "fix", false, "Add missing json tags in place")
+	lintJSONCmd.Flags().BoolVar(&lintRename, "rename", false, "With --fix, also rename json names that differ from the bson name")
+	lintCmd.AddCommand(lintJSONCmd)
+}
+
+// goFiles returns the Go files named by paths, walking directories and
+// skipping vendor, testdata, and hidden directories.
+func goFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != p && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, ".go") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -18,6 +18,13 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(discoverCmd)
 	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(genCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(driftCmd)
+	rootCmd.AddCommand(fieldsCmd)
+	rootCmd.AddCommand(reindexCmd)
 }
 
 func main() {
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dwoolworth/goodm"
@@ -14,6 +16,11 @@ var (
 	migrateDB         string
 	migrateDryRun     bool
 	migrateDropExtras bool
+	migratePrune      []string
+	migratePruneBatch int
+	migrateOnly       []string
+	migrateAsk        bool
+	migrateDropColls  []string
 )
 
 var migrateCmd = &cobra.Command{
@@ -28,11 +35,22 @@ func init() {
 	migrateCmd.Flags().StringVar(&migrateDB, "db", "", "MongoDB database name")
 	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show planned changes without applying them")
 	migrateCmd.Flags().BoolVar(&migrateDropExtras, "drop-extras", false, "Drop indexes not defined in schemas")
+	migrateCmd.Flags().StringArrayVar(&migratePrune, "prune", nil, "Unset a retired field from every document, as collection.field (repeatable)")
+	migrateCmd.Flags().IntVar(&migratePruneBatch, "prune-batch", 1000, "Documents updated per batch when pruning")
+	migrateCmd.Flags().StringArrayVar(&migrateOnly, "only", nil, "Apply only the actions on a collection, or on one index or pruned field as collection:name (repeatable)")
+	migrateCmd.Flags().StringArrayVar(&migrateDropColls, "drop-collection", nil, "Drop an obsolete collection that no registered model uses (repeatable)")
+	migrateCmd.Flags().BoolVarP(&migrateAsk, "interactive", "i", false, "Ask before applying each action")
 	_ = migrateCmd.MarkFlagRequired("db")
 }
 
 func runMigrate(cmd *cobra.Command, args []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	// Pruning large collections can take much longer than index changes,
+	// and an operator answering prompts should not run into the timeout.
+	timeout := 60 * time.Second
+	if len(migratePrune) > 0 || migrateAsk {
+		timeout = 24 * time.Hour
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	db, err := goodm.Connect(ctx, migrateURI, migrateDB)
@@ -46,10 +64,26 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	prune := make(map[string][]string)
+	for _, p := range migratePrune {
+		coll, field, ok := strings.Cut(p, ".")
+		if !ok || coll == "" || field == "" {
+			return fmt.Errorf("invalid --prune %q: expected collection.field", p)
+		}
+		prune[coll] = append(prune[coll], field)
+	}
+
 	plan, err := goodm.PlanMigration(ctx, db, schemas)
 	if err != nil {
 		return err
 	}
+	plan.Actions = append(plan.Actions, goodm.PlanPrune(prune)...)
+	plan.Actions = append(plan.Actions, goodm.PlanDropCollections(migrateDropColls)...)
+	if len(migrateOnly) > 0 {
+		if plan, err = plan.Only(migrateOnly...); err != nil {
+			return err
+		}
+	}
 
 	fmt.Printf("Migration Plan for %s\n", migrateDB)
 	fmt.Println(repeat("=", len("Migration Plan for ")+len(migrateDB)))
@@ -62,30 +96,43 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		collectionOrder = append(collectionOrder, schema.Collection)
 	}
 	for _, action := range plan.Actions {
+		if action.Type == goodm.ActionDropCollection {
+			collectionOrder = append(collectionOrder, action.Collection)
+		}
 		collectionActions[action.Collection] = append(collectionActions[action.Collection], action)
 	}
 
-	createCount, dropCount, warnCount := 0, 0, 0
+	createCount, dropCount, pruneCount, warnCount := 0, 0, 0, 0
 	for _, collName := range collectionOrder {
 		fmt.Printf("%s:\n", collName)
-		c, d, w := displayPlanActions(collectionActions[collName])
+		c, d, p, w := displayPlanActions(collectionActions[collName])
 		createCount += c
 		dropCount += d
+		pruneCount += p
 		warnCount += w
 		fmt.Println()
 	}
 
-	fmt.Printf("Summary: %d to create, %d to drop, %d warning(s)\n", createCount, dropCount, warnCount)
+	fmt.Printf("Summary: %d to create, %d to drop, %d to prune, %d warning(s)\n", createCount, dropCount, pruneCount, warnCount)
 
 	if migrateDryRun {
 		fmt.Println("Run without --dry-run to apply.")
 		return nil
 	}
 
+	if migrateAsk {
+		plan = confirmActions(cmd, plan)
+		fmt.Println()
+	}
+
 	// Execute
 	opts := goodm.MigrateOptions{
 		DryRun:     false,
 		DropExtras: migrateDropExtras,
+		PruneBatch: migratePruneBatch,
+		OnPrune: func(p goodm.PruneResult) {
+			fmt.Printf("  ~ %s: unset %d/%d documents\n", p.Collection, p.Pruned, p.Total)
+		},
 	}
 	result, err := goodm.ExecuteMigration(ctx, db, plan, opts)
 	if err != nil {
@@ -105,22 +152,54 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func displayPlanActions(actions []goodm.MigrationAction) (created, dropped, warned int) {
+// confirmActions asks on the command's input whether to apply each action
+// that changes the database, and returns the plan with the approved ones.
+// Answering "a" approves the action and all that follow, "q" skips it and all
+// that follow. Drift warnings are kept without asking.
+func confirmActions(cmd *cobra.Command, plan goodm.MigrationPlan) goodm.MigrationPlan {
+	in := bufio.NewScanner(cmd.InOrStdin())
+	answer := ""
+	return plan.Filter(func(a goodm.MigrationAction) bool {
+		if a.Type == goodm.ActionFieldDrift || a.Type == goodm.ActionDropIndex && !migrateDropExtras {
+			return true
+		}
+		switch answer {
+		case "a":
+			return true
+		case "q":
+			return false
+		}
+		fmt.Printf("%s: %s? [y/N/a/q] ", a.Collection, a.Description)
+		reply := "q"
+		if in.Scan() {
+			reply = strings.ToLower(strings.TrimSpace(in.Text()))
+		}
+		if reply == "a" || reply == "q" {
+			answer = reply
+		}
+		return reply == "y" || reply == "yes" || reply == "a"
+	})
+}
+
+func displayPlanActions(actions []goodm.MigrationAction) (created, dropped, pruned, warned int) {
 	if len(actions) == 0 {
 		fmt.Println("  ✓ No changes needed")
-		return 0, 0, 0
+		return 0, 0, 0, 0
 	}
 	for _, action := range actions {
 		switch action.Type {
 		case goodm.ActionCreateIndex:
 			fmt.Printf("  + %s\n", action.Description)
 			created++
-		case goodm.ActionDropIndex:
+		case goodm.ActionDropIndex, goodm.ActionDropCollection:
 			fmt.Printf("  - %s\n", action.Description)
 			dropped++
 		case goodm.ActionFieldDrift:
 			fmt.Printf("  ⚠ %s\n", action.Description)
 			warned++
+		case goodm.ActionPruneFields:
+			fmt.Printf("  ~ %s\n", action.Description)
+			pruned++
 		}
 	}
 	return
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dwoolworth/goodm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotFile    string
+	exportService   string
+	exportVersion   string
+	exportOut       string
+	refsImportFiles []string
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Snapshot registered schemas and check them for breaking changes",
+}
+
+var schemaSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Write the registered schemas to a snapshot file",
+	Long:  "Write a canonical manifest of all registered model schemas to a snapshot file, to be committed and checked with `goodm schema check`.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemas := goodm.GetAll()
+		if len(schemas) == 0 {
+			fmt.Println("No models registered. Import your model packages to register them.")
+			return nil
+		}
+
+		f, err := os.Create(snapshotFile)
+		if err != nil {
+			return err
+		}
+		if err := goodm.WriteSnapshot(f, goodm.Snapshot(schemas)); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %d models to %s\n", len(schemas), snapshotFile)
+		return nil
+	},
+}
+
+var schemaCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Compare registered schemas against a snapshot file",
+	Long:  "Diff the registered model schemas against a snapshot file and fail if any change is breaking: a removed model or field, a type change, or a new required field without a default.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		previous, err := goodm.LoadSnapshot(snapshotFile)
+		if err != nil {
+			return err
+		}
+
+		changes := goodm.DiffSnapshots(previous, goodm.Snapshot(goodm.GetAll()))
+		if len(changes) == 0 {
+			fmt.Println("✓ Schemas match the snapshot")
+			return nil
+		}
+
+		breaking := 0
+		for _, c := range changes {
+			marker := "~"
+			if c.Breaking {
+				marker = "✗"
+				breaking++
+			}
+			fmt.Printf("  %s %s\n", marker, c)
+		}
+		fmt.Println()
+		fmt.Printf("Summary: %d changes, %d breaking\n", len(changes), breaking)
+
+		if breaking > 0 {
+			return fmt.Errorf("%d breaking schema changes", breaking)
+		}
+		fmt.Println("Run `goodm schema snapshot` to accept the non-breaking changes.")
+		return nil
+	},
+}
+
+var schemaExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the registered schemas as a versioned artifact for other services",
+	Long:  "Write the registered model schemas, labelled with a service name and version, to an artifact file that other services load with goodm.ImportSchemas to check their filters and refs.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemas := goodm.GetAll()
+		if len(schemas) == 0 {
+			fmt.Println("No models registered. Import your model packages to register them.")
+			return nil
+		}
+
+		out := exportOut
+		if out == "" {
+			out = exportService + ".schema.json"
+		}
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		if err := goodm.WriteArtifact(f, goodm.ExportSchemas(exportService, exportVersion, schemas)); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %d models of %s %s to %s\n", len(schemas), exportService, exportVersion, out)
+		return nil
+	},
+}
+
+var schemaRefsCmd = &cobra.Command{
+	Use:   "refs",
+	Short: "Check that ref fields name known collections",
+	Long:  "Import the given schema artifacts and fail if a ref field of a registered model names a collection that no registered or imported model owns.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, path := range refsImportFiles {
+			art, err := goodm.LoadArtifact(path)
+			if err != nil {
+				return err
+			}
+			if err := goodm.ImportSchemas(art); err != nil {
+				return err
+			}
+			fmt.Printf("Imported %d models of %s %s\n", len(art.Models), art.Service, art.Version)
+		}
+		if err := goodm.CheckRefs(goodm.GetAll()); err != nil {
+			return err
+		}
+		fmt.Println("✓ All refs resolve")
+		return nil
+	},
+}
+
+func init() {
+	schemaCmd.PersistentFlags().StringVar(&snapshotFile, "file", "goodm.schema.json", "Snapshot file path")
+	schemaExportCmd.Flags().StringVar(&exportService, "service", "", "Name of the service owning the schemas")
+	schemaExportCmd.Flags().StringVar(&exportVersion, "version", "", "Version of the published schemas")
+	schemaExportCmd.Flags().StringVar(&exportOut, "out", "", "Artifact file path (default <service>.schema.json)")
+	_ = schemaExportCmd.MarkFlagRequired("service")
+	_ = schemaExportCmd.MarkFlagRequired("version")
+	schemaRefsCmd.Flags().StringArrayVar(&refsImportFiles, "import", nil, "Schema artifact of another service (repeatable)")
+	schemaCmd.AddCommand(schemaSnapshotCmd)
+	schemaCmd.AddCommand(schemaCheckCmd)
+	schemaCmd.AddCommand(schemaExportCmd)
+	schemaCmd.AddCommand(schemaRefsCmd)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dwoolworth/goodm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyURI string
+	verifyDB  string
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the database was enforced with the registered schemas",
+	Long:  "Compare the registered model schemas against the schema hashes Enforce recorded in the goodm_meta collection, and fail if any model was never enforced, changed since, or is no longer registered.",
+	RunE:  runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyURI, "uri", "mongodb://localhost:27017", "MongoDB connection URI")
+	verifyCmd.Flags().StringVar(&verifyDB, "db", "", "MongoDB database name")
+	_ = verifyCmd.MarkFlagRequired("db")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := goodm.Connect(ctx, verifyURI, verifyDB)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	schemas := goodm.GetAll()
+	if len(schemas) == 0 {
+		fmt.Println("No models registered. Import your model packages to register them.")
+		return nil
+	}
+
+	mismatches, err := goodm.VerifySchemas(ctx, db, schemas)
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		fmt.Printf("✓ %d models match the schemas enforced on %s\n", len(schemas), verifyDB)
+		return nil
+	}
+	for _, m := range mismatches {
+		fmt.Printf("  ✗ %s\n", m)
+	}
+	fmt.Println()
+	return fmt.Errorf("%d models differ from the schemas enforced on %s; run goodm.Enforce against it", len(mismatches), verifyDB)
+}
@@ -0,0 +1,296 @@
+package goodm
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	codecMu        sync.Mutex
+	codecRegistry  = newCodecRegistry()
+	customEncoders = make(map[reflect.Type]bool) // types and interfaces given encoders by RegisterCodec or RegisterEncoder
+	customDecoders = make(map[reflect.Type]bool) // types and interfaces given decoders by RegisterCodec or RegisterDecoder
+	ifaceCodecs    = make(map[reflect.Type]bool) // interfaces registered with RegisterInterface
+)
+
+// Codec encodes and decodes the values of one type.
+type Codec interface {
+	bson.ValueEncoder
+	bson.ValueDecoder
+}
+
+// CodecRegistry returns the BSON registry holding goodm's custom codecs, such as
+// those installed by RegisterInterface and RegisterCodec. Connect and
+// ConnectWithOptions install it on the client they create; pass it to
+// options.Client().SetRegistry when building your own client.
+//
+// Clients read the registry without goodm's lock, so register all codecs
+// during program initialization, before connecting: registering while a
+// client is encoding or decoding is a data race.
+func CodecRegistry() *bson.Registry {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	return codecRegistry
+}
+
+// RegisterInterface enables fields declared as an interface type to be stored
+// and loaded. Each concrete implementation is registered under a name that is
+// written to typeKey inside the encoded subdocument; on read, typeKey selects
+// the concrete type to decode into.
+//
+// iface must be a nil pointer to the interface, e.g. (*Payload)(nil).
+// impls maps names to zero values of the implementations, either values or
+// pointers; decoding produces the same form that was registered.
+//
+// Each interface can be registered once; a second call returns an error.
+// Call it before Connect, as described on CodecRegistry.
+//
+// Example:
+//
+//	type Payload interface{ Channel() string }
+//
+//	goodm.RegisterInterface((*Payload)(nil), "type", map[string]interface{}{
+//	    "email": &EmailPayload{},
+//	    "sms":   &SMSPayload{},
+//	})
+func RegisterInterface(iface interface{}, typeKey string, impls map[string]interface{}) error {
+	it := reflect.TypeOf(iface)
+	if it == nil || it.Kind() != reflect.Ptr || it.Elem().Kind() != reflect.Interface {
+		return fmt.Errorf("goodm: RegisterInterface expects a nil pointer to an interface, got %T", iface)
+	}
+	it = it.Elem()
+
+	codec := &interfaceCodec{
+		iface:   it,
+		typeKey: typeKey,
+		byName:  make(map[string]reflect.Type, len(impls)),
+		byType:  make(map[reflect.Type]string, len(impls)),
+	}
+	for name, impl := range impls {
+		t := reflect.TypeOf(impl)
+		if t == nil || !t.Implements(it) {
+			return fmt.Errorf("goodm: %T does not implement %s", impl, it)
+		}
+		codec.byName[name] = t
+		codec.byType[t] = name
+	}
+
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	if ifaceCodecs[it] {
+		return fmt.Errorf("goodm: interface %s is already registered", it)
+	}
+	codecRegistry.RegisterTypeEncoder(it, codec)
+	codecRegistry.RegisterTypeDecoder(it, codec)
+	ifaceCodecs[it] = true
+	return nil
+}
+
+// RegisterCodec installs codec for the type of value in goodm's codec
+// registry, so every field of that type is stored and loaded with it: enums
+// stored as integers, times in a custom format, or values encrypted at rest.
+//
+// value is a zero value of the type, e.g. Priority("") or time.Time{}. A nil
+// pointer to an interface, e.g. (*Sealed)(nil), installs codec for every type
+// implementing the interface instead.
+//
+// A type can be given one encoder and one decoder; registering a second
+// returns an error. Register codecs before Connect, as described on
+// CodecRegistry.
+//
+// Example:
+//
+//	goodm.RegisterCodec(Priority(""), priorityCodec{}) // writes "high" as 2
+func RegisterCodec(value interface{}, codec Codec) error {
+	if codec == nil {
+		return fmt.Errorf("goodm: RegisterCodec expects a codec, got nil")
+	}
+	return registerCodec("RegisterCodec", value, codec, codec)
+}
+
+// RegisterEncoder installs enc for the type of value in goodm's codec
+// registry, leaving decoding to the default codec or RegisterDecoder. value
+// is interpreted as by RegisterCodec.
+func RegisterEncoder(value interface{}, enc bson.ValueEncoder) error {
+	if enc == nil {
+		return fmt.Errorf("goodm: RegisterEncoder expects an encoder, got nil")
+	}
+	return registerCodec("RegisterEncoder", value, enc, nil)
+}
+
+// RegisterDecoder installs dec for the type of value in goodm's codec
+// registry, leaving encoding to the default codec or RegisterEncoder. value
+// is interpreted as by RegisterCodec.
+func RegisterDecoder(value interface{}, dec bson.ValueDecoder) error {
+	if dec == nil {
+		return fmt.Errorf("goodm: RegisterDecoder expects a decoder, got nil")
+	}
+	return registerCodec("RegisterDecoder", value, nil, dec)
+}
+
+func registerCodec(fn string, value interface{}, enc bson.ValueEncoder, dec bson.ValueDecoder) error {
+	t := reflect.TypeOf(value)
+	if t == nil {
+		return fmt.Errorf("goodm: %s expects a value of the type to register, got nil", fn)
+	}
+	iface := t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface
+	if iface {
+		t = t.Elem()
+	}
+
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	if enc != nil && customEncoders[t] {
+		return fmt.Errorf("goodm: %s: %s already has a registered encoder", fn, t)
+	}
+	if dec != nil && customDecoders[t] {
+		return fmt.Errorf("goodm: %s: %s already has a registered decoder", fn, t)
+	}
+	switch {
+	case iface && enc != nil:
+		codecRegistry.RegisterInterfaceEncoder(t, enc)
+	case enc != nil:
+		codecRegistry.RegisterTypeEncoder(t, enc)
+	}
+	switch {
+	case iface && dec != nil:
+		codecRegistry.RegisterInterfaceDecoder(t, dec)
+	case dec != nil:
+		codecRegistry.RegisterTypeDecoder(t, dec)
+	}
+	if enc != nil {
+		customEncoders[t] = true
+	}
+	if dec != nil {
+		customDecoders[t] = true
+	}
+	return nil
+}
+
+// hasCustomCodec reports whether values of t are encoded or decoded by a
+// codec registered with RegisterCodec, RegisterEncoder, or RegisterDecoder.
+func hasCustomCodec(t reflect.Type) bool {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	for _, registered := range []map[reflect.Type]bool{customEncoders, customDecoders} {
+		for c := range registered {
+			if t == c || c.Kind() == reflect.Interface && (t.Implements(c) || reflect.PtrTo(t).Implements(c)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// interfaceCodec encodes interface-typed fields as subdocuments tagged with the
+// implementation name, and decodes them back into the registered concrete type.
+type interfaceCodec struct {
+	iface   reflect.Type
+	typeKey string
+	byName  map[string]reflect.Type
+	byType  map[reflect.Type]string
+}
+
+func (c *interfaceCodec) EncodeValue(ec bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+	if val.IsNil() {
+		return vw.WriteNull()
+	}
+	concrete := val.Elem()
+	name, ok := c.byType[concrete.Type()]
+	if !ok {
+		return fmt.Errorf("goodm: %s is not a registered implementation of %s", concrete.Type(), c.iface)
+	}
+
+	raw, err := marshalWithRegistry(ec.Registry, concrete.Interface())
+	if err != nil {
+		return err
+	}
+	elems, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return err
+	}
+
+	doc := make(bson.D, 0, len(elems)+1)
+	doc = append(doc, bson.E{Key: c.typeKey, Value: name})
+	for _, e := range elems {
+		if e.Key() == c.typeKey {
+			continue
+		}
+		doc = append(doc, bson.E{Key: e.Key(), Value: e.Value()})
+	}
+
+	enc, err := ec.LookupEncoder(reflect.TypeOf(doc))
+	if err != nil {
+		return err
+	}
+	return enc.EncodeValue(ec, vw, reflect.ValueOf(doc))
+}
+
+func (c *interfaceCodec) DecodeValue(dc bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+	if vr.Type() == bson.TypeNull {
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	}
+
+	rawVal := reflect.New(reflect.TypeOf(bson.Raw{})).Elem()
+	dec, err := dc.LookupDecoder(rawVal.Type())
+	if err != nil {
+		return err
+	}
+	if err := dec.DecodeValue(dc, vr, rawVal); err != nil {
+		return err
+	}
+	raw := rawVal.Interface().(bson.Raw)
+
+	name, _ := raw.Lookup(c.typeKey).StringValueOK()
+	t, ok := c.byName[name]
+	if !ok {
+		return fmt.Errorf("goodm: unknown %s %q for %s", c.typeKey, name, c.iface)
+	}
+
+	var target reflect.Value
+	if t.Kind() == reflect.Ptr {
+		target = reflect.New(t.Elem())
+		if err := unmarshalWithRegistry(dc.Registry, raw, target.Interface()); err != nil {
+			return err
+		}
+	} else {
+		ptr := reflect.New(t)
+		if err := unmarshalWithRegistry(dc.Registry, raw, ptr.Interface()); err != nil {
+			return err
+		}
+		target = ptr.Elem()
+	}
+	val.Set(target)
+	return nil
+}
+
+// marshalBSON encodes v using goodm's codec registry.
+func marshalBSON(v interface{}) ([]byte, error) {
+	return marshalWithRegistry(CodecRegistry(), v)
+}
+
+// unmarshalBSON decodes data into v using goodm's codec registry.
+func unmarshalBSON(data []byte, v interface{}) error {
+	return unmarshalWithRegistry(CodecRegistry(), data, v)
+}
+
+func marshalWithRegistry(reg *bson.Registry, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := bson.NewEncoder(bson.NewDocumentWriter(&buf))
+	enc.SetRegistry(reg)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalWithRegistry(reg *bson.Registry, data []byte, v interface{}) error {
+	dec := bson.NewDecoder(bson.NewDocumentReader(bytes.NewReader(data)))
+	dec.SetRegistry(reg)
+	return dec.Decode(v)
+}
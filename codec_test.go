@@ -0,0 +1,244 @@
+package goodm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testPayload interface {
+	Channel() string
+}
+
+type testEmailPayload struct {
+	To      string `bson:"to"`
+	Subject string `bson:"subject"`
+}
+
+func (p *testEmailPayload) Channel() string { return "email" }
+
+type testSMSPayload struct {
+	Phone string `bson:"phone"`
+}
+
+func (p testSMSPayload) Channel() string { return "sms" }
+
+type testNotification struct {
+	Model   `bson:",inline"`
+	Payload testPayload `bson:"payload"`
+}
+
+func init() {
+	if err := RegisterInterface((*testPayload)(nil), "type", map[string]interface{}{
+		"email": &testEmailPayload{},
+		"sms":   testSMSPayload{},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterInterface_Invalid(t *testing.T) {
+	if err := RegisterInterface(testSMSPayload{}, "type", nil); err == nil {
+		t.Fatal("expected error for non-interface pointer")
+	}
+	if err := RegisterInterface((*testPayload)(nil), "type", map[string]interface{}{"bad": 42}); err == nil {
+		t.Fatal("expected error for non-implementing type")
+	}
+	if err := RegisterInterface((*testPayload)(nil), "kind", map[string]interface{}{"sms": testSMSPayload{}}); err == nil {
+		t.Fatal("expected error for an interface registered twice")
+	}
+}
+
+func TestInterfaceCodec_RoundTrip(t *testing.T) {
+	in := &testNotification{Payload: &testEmailPayload{To: "a@b.c", Subject: "hi"}}
+
+	raw, err := marshalBSON(in)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	typ, _ := bson.Raw(raw).Lookup("payload", "type").StringValueOK()
+	if typ != "email" {
+		t.Fatalf("expected payload.type=email, got %q", typ)
+	}
+
+	out := &testNotification{}
+	if err := unmarshalBSON(raw, out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	email, ok := out.Payload.(*testEmailPayload)
+	if !ok {
+		t.Fatalf("expected *testEmailPayload, got %T", out.Payload)
+	}
+	if email.To != "a@b.c" || email.Subject != "hi" {
+		t.Fatalf("unexpected payload: %+v", email)
+	}
+}
+
+func TestInterfaceCodec_ValueImplAndNil(t *testing.T) {
+	raw, err := marshalBSON(&testNotification{Payload: testSMSPayload{Phone: "555"}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	out := &testNotification{}
+	if err := unmarshalBSON(raw, out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if sms, ok := out.Payload.(testSMSPayload); !ok || sms.Phone != "555" {
+		t.Fatalf("expected testSMSPayload{555}, got %#v", out.Payload)
+	}
+
+	raw, err = marshalBSON(&testNotification{})
+	if err != nil {
+		t.Fatalf("marshal nil payload: %v", err)
+	}
+	out = &testNotification{Payload: testSMSPayload{}}
+	if err := unmarshalBSON(raw, out); err != nil {
+		t.Fatalf("unmarshal nil payload: %v", err)
+	}
+	if out.Payload != nil {
+		t.Fatalf("expected nil payload, got %#v", out.Payload)
+	}
+}
+
+func TestInterfaceCodec_UnknownType(t *testing.T) {
+	raw, _ := bson.Marshal(bson.D{{Key: "payload", Value: bson.D{{Key: "type", Value: "fax"}}}})
+	if err := unmarshalBSON(raw, &testNotification{}); err == nil {
+		t.Fatal("expected error for unknown implementation name")
+	}
+}
+
+type testPriority string
+
+var testPriorities = []testPriority{"low", "normal", "high"}
+
+// testPriorityCodec stores priorities as their index in testPriorities.
+type testPriorityCodec struct{}
+
+func (testPriorityCodec) EncodeValue(_ bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+	for i, p := range testPriorities {
+		if p == val.Interface().(testPriority) {
+			return vw.WriteInt32(int32(i))
+		}
+	}
+	return fmt.Errorf("unknown priority %q", val.String())
+}
+
+func (testPriorityCodec) DecodeValue(_ bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+	i, err := vr.ReadInt32()
+	if err != nil {
+		return err
+	}
+	if i < 0 || int(i) >= len(testPriorities) {
+		return fmt.Errorf("unknown priority %d", i)
+	}
+	val.SetString(string(testPriorities[i]))
+	return nil
+}
+
+type testSealer interface {
+	Sealed()
+}
+
+// testSecret is stored reversed, standing in for encryption at rest.
+type testSecret string
+
+func (testSecret) Sealed() {}
+
+func testReverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+type testIssue struct {
+	Model    `bson:",inline"`
+	Priority testPriority  `bson:"priority"`
+	Escalate *testPriority `bson:"escalate"`
+	Token    testSecret    `bson:"token"`
+}
+
+func init() {
+	if err := RegisterCodec(testPriority(""), testPriorityCodec{}); err != nil {
+		panic(err)
+	}
+	if err := RegisterEncoder((*testSealer)(nil), bson.ValueEncoderFunc(func(_ bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+		return vw.WriteString(testReverse(val.String()))
+	})); err != nil {
+		panic(err)
+	}
+	if err := RegisterDecoder((*testSealer)(nil), bson.ValueDecoderFunc(func(_ bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+		s, err := vr.ReadString()
+		val.SetString(testReverse(s))
+		return err
+	})); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterCodec_Invalid(t *testing.T) {
+	if err := RegisterCodec(nil, testPriorityCodec{}); err == nil {
+		t.Error("expected error for a nil value")
+	}
+	if err := RegisterCodec(testPriority(""), nil); err == nil {
+		t.Error("expected error for a nil codec")
+	}
+	if err := RegisterEncoder(testPriority(""), nil); err == nil {
+		t.Error("expected error for a nil encoder")
+	}
+	if err := RegisterDecoder(testPriority(""), nil); err == nil {
+		t.Error("expected error for a nil decoder")
+	}
+	if err := RegisterCodec(testPriority(""), testPriorityCodec{}); err == nil {
+		t.Error("expected error for a type registered twice")
+	}
+	if err := RegisterDecoder((*testSealer)(nil), testPriorityCodec{}); err == nil {
+		t.Error("expected error for a second decoder of an interface")
+	}
+}
+
+func TestRegisterCodec_RoundTrip(t *testing.T) {
+	high := testPriority("high")
+	in := &testIssue{Priority: "normal", Escalate: &high, Token: "s3cret"}
+
+	raw, err := marshalBSON(in)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if p, ok := bson.Raw(raw).Lookup("priority").Int32OK(); !ok || p != 1 {
+		t.Errorf("expected priority stored as 1, got %v", bson.Raw(raw).Lookup("priority"))
+	}
+	if p, ok := bson.Raw(raw).Lookup("escalate").Int32OK(); !ok || p != 2 {
+		t.Errorf("expected escalate stored as 2, got %v", bson.Raw(raw).Lookup("escalate"))
+	}
+	if s, _ := bson.Raw(raw).Lookup("token").StringValueOK(); s != "terc3s" {
+		t.Errorf("expected the token sealed, got %q", s)
+	}
+
+	var out testIssue
+	if err := unmarshalBSON(raw, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Priority != "normal" || out.Escalate == nil || *out.Escalate != "high" || out.Token != "s3cret" {
+		t.Errorf("unexpected round trip: %+v", out)
+	}
+
+	if _, err := marshalBSON(&testIssue{Priority: "urgent"}); err == nil {
+		t.Error("expected the codec's error for an unknown priority")
+	}
+}
+
+func TestRegisterCodec_GeneratedCodecFallsBack(t *testing.T) {
+	pkg := reflect.TypeOf(testIssue{}).PkgPath()
+	for _, v := range []interface{}{testPriority(""), (*testPriority)(nil), testSecret("")} {
+		if base := codecBase(reflect.TypeOf(v), pkg); base != nil {
+			t.Errorf("expected %T to fall back to the codec registry, got %v", v, base)
+		}
+	}
+	if codecBase(reflect.TypeOf(testCodecKind("")), pkg) == nil {
+		t.Error("expected an unregistered named string to be written directly")
+	}
+}
@@ -0,0 +1,472 @@
+package goodm
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dwoolworth/goodm/internal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// CodecOptions controls codec code generation.
+type CodecOptions struct {
+	PackageName string // Go package name (default "models")
+}
+
+var (
+	marshalerType        = reflect.TypeOf((*bson.Marshaler)(nil)).Elem()
+	valueMarshalerType   = reflect.TypeOf((*bson.ValueMarshaler)(nil)).Elem()
+	unmarshalerType      = reflect.TypeOf((*bson.Unmarshaler)(nil)).Elem()
+	valueUnmarshalerType = reflect.TypeOf((*bson.ValueUnmarshaler)(nil)).Elem()
+	zeroerType           = reflect.TypeOf((*bson.Zeroer)(nil)).Elem()
+)
+
+// codecField is a field of a model a generated codec reads and writes.
+type codecField struct {
+	Key       string // document key
+	Expr      string // Go expression selecting the field on m
+	Source    string // e.g. "User.age", for error messages
+	Type      reflect.Type
+	Base      reflect.Type // type written directly, or nil to use the codec registry
+	OmitEmpty bool
+}
+
+// GenerateCodecs generates Go source implementing bson.Marshaler and
+// bson.Unmarshaler on each of the given models, so Create, Find, and every
+// other encode or decode of them skips the driver's reflection-based struct
+// codec. The generated file belongs in the package declaring the models.
+// Encoding a model with only direct fields allocates once; decoding still
+// allocates for strings, slices, and pointers.
+//
+// Fields of type string, bool, int, int8, int16, int32, int64, float64,
+// time.Time, bson.ObjectID, and []string, pointers to those, and named types
+// of the model's package based on the basic ones are encoded and decoded
+// directly. Other fields, such as subdocuments and fields of types registered
+// with RegisterInterface or RegisterCodec, fall back to goodm's codec
+// registry, as do stored values of a different BSON type than the field.
+// Documents are byte-for-byte what the struct codec writes.
+//
+// Regenerate the file whenever the models change; a generated codec does not
+// see fields added after it was generated. The generated code builds on the
+// driver's experimental x/bsonx/bsoncore package, which may change in a minor
+// driver release, so also regenerate it after upgrading the driver.
+//
+// Example:
+//
+//	src, err := goodm.GenerateCodecs(map[string]*goodm.Schema{"Event": schema}, goodm.CodecOptions{PackageName: "models"})
+func GenerateCodecs(schemas map[string]*Schema, opts ...CodecOptions) ([]byte, error) {
+	var opt CodecOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.PackageName == "" {
+		opt.PackageName = "models"
+	}
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var body bytes.Buffer
+	needsTime := false
+	pkg := ""
+	for _, name := range names {
+		t := schemas[name].modelType
+		if t == nil || t.Name() == "" {
+			return nil, fmt.Errorf("goodm: gen codecs: %s has no named struct type", name)
+		}
+		if pkg != "" && t.PkgPath() != pkg {
+			return nil, fmt.Errorf("goodm: gen codecs: %s is declared in %s, not %s; generate a file per package", name, t.PkgPath(), pkg)
+		}
+		pkg = t.PkgPath()
+
+		fields, err := codecFields(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			if f.Base == timeType {
+				needsTime = true
+			}
+		}
+		writeMarshal(&body, t, fields)
+		writeUnmarshal(&body, t, fields)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by goodm gen codecs. DO NOT EDIT.\n\n")
+	b.WriteString("// This file uses go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore, an\n")
+	b.WriteString("// experimental driver package without a compatibility promise. Regenerate it\n")
+	b.WriteString("// after upgrading the driver.\n\n")
+	fmt.Fprintf(&b, "package %s\n\nimport (\n\t\"bytes\"\n\t\"fmt\"\n\t\"strconv\"\n", opt.PackageName)
+	if needsTime {
+		b.WriteString("\t\"time\"\n")
+	}
+	b.WriteString("\n\t\"github.com/dwoolworth/goodm\"\n")
+	b.WriteString("\t\"go.mongodb.org/mongo-driver/v2/bson\"\n")
+	b.WriteString("\t\"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore\"\n)\n")
+	b.Write(body.Bytes())
+	b.WriteString(codecHelpers)
+
+	formatted, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("goodm: gen codecs: %w", err)
+	}
+	return formatted, nil
+}
+
+// codecFields returns the fields of the struct t in encoding order.
+func codecFields(t reflect.Type) ([]codecField, error) {
+	var fields []codecField
+	for _, f := range internal.PromotedFields(t) {
+		key := internal.BSONKey(f.StructField)
+		if key == "-" {
+			continue
+		}
+		expr := "m"
+		for _, v := range f.Via {
+			if v.Type.Kind() == reflect.Ptr {
+				return nil, fmt.Errorf("goodm: gen codecs: %s.%s is promoted through the pointer %s, which is not supported", t.Name(), f.Name, v.Name)
+			}
+			expr += "." + v.Name
+		}
+		cf := codecField{
+			Key:    key,
+			Expr:   expr + "." + f.Name,
+			Source: t.Name() + "." + key,
+			Type:   f.Type,
+			Base:   codecBase(f.Type, t.PkgPath()),
+		}
+		for _, o := range strings.Split(f.Tag.Get("bson"), ",")[1:] {
+			switch o {
+			case "omitempty":
+				cf.OmitEmpty = true
+			case "":
+			default:
+				return nil, fmt.Errorf("goodm: gen codecs: bson option %q of %s is not supported", o, cf.Source)
+			}
+		}
+		fields = append(fields, cf)
+	}
+	return fields, nil
+}
+
+// codecBase returns the type a generated codec in package pkg writes
+// directly for t, dereferencing one pointer, or nil if t falls back to the
+// codec registry.
+func codecBase(t reflect.Type, pkg string) reflect.Type {
+	ptr := t.Kind() == reflect.Ptr
+	if ptr {
+		t = t.Elem()
+	}
+	if hasCustomCodec(t) || ptr && hasCustomCodec(reflect.PtrTo(t)) {
+		return nil // encoded by a codec registered with RegisterCodec
+	}
+	if t == timeType || t == objectIDType {
+		return t
+	}
+	if t.PkgPath() != "" && (ptr || t.PkgPath() != pkg) {
+		return nil // named types of other packages, and pointers to named types
+	}
+	if t.Implements(marshalerType) || t.Implements(valueMarshalerType) ||
+		reflect.PtrTo(t).Implements(unmarshalerType) || reflect.PtrTo(t).Implements(valueUnmarshalerType) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float64:
+		return t
+	case reflect.Slice:
+		if t.Name() == "" && t.Elem() == reflect.TypeOf("") {
+			return t
+		}
+	}
+	return nil
+}
+
+// convertTo returns expr, of the basic type from, converted to t, which
+// must be a basic type or a named type of the model's package.
+func convertTo(expr, from string, t reflect.Type) string {
+	if t.PkgPath() == "" && t.Name() == from {
+		return expr
+	}
+	return t.Name() + "(" + expr + ")"
+}
+
+// presentExpr returns a Go condition reporting whether expr, of type t, is
+// written despite omitempty, as the bson struct codec decides it, or "" if it
+// always is.
+func presentExpr(t reflect.Type, expr string) string {
+	switch {
+	case t.Kind() == reflect.Ptr && t.Implements(zeroerType):
+		return expr + " != nil && !" + expr + ".IsZero()"
+	case t.Kind() != reflect.Ptr && t.Implements(zeroerType):
+		return "!" + expr + ".IsZero()"
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return expr + " != nil"
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return "len(" + expr + ") != 0"
+	case reflect.Bool:
+		return expr
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return expr + " != 0"
+	}
+	return ""
+}
+
+// convert returns expr, of type from, converted to type to, if they differ.
+func convert(expr string, from reflect.Type, to string) string {
+	if from.PkgPath() == "" && from.Name() == to {
+		return expr
+	}
+	return to + "(" + expr + ")"
+}
+
+func writeMarshal(b *bytes.Buffer, t reflect.Type, fields []codecField) {
+	size := 5
+	var dynamic []string
+	fallback := false
+	for _, f := range fields {
+		size += len(f.Key) + 2
+		base := f.Base
+		switch {
+		case base == nil:
+			size += 32
+			fallback = true
+		case base == objectIDType:
+			size += 12
+		case base.Kind() == reflect.String:
+			size += 5
+			if f.Type.Kind() != reflect.Ptr {
+				dynamic = append(dynamic, "len("+f.Expr+")")
+			}
+		case base.Kind() == reflect.Slice:
+			size += 5
+			if f.Type.Kind() != reflect.Ptr {
+				dynamic = append(dynamic, "16*len("+f.Expr+")")
+			}
+		case base.Kind() == reflect.Bool:
+			size++
+		default:
+			size += 8
+		}
+	}
+
+	fmt.Fprintf(b, "\n// MarshalBSON encodes m as the bson struct codec would, without reflection.\n")
+	fmt.Fprintf(b, "func (m *%s) MarshalBSON() ([]byte, error) {\n", t.Name())
+	fmt.Fprintf(b, "\tdst := make([]byte, 0, %d%s)\n", size, prefixEach(" + ", dynamic))
+	b.WriteString("\tidx, dst := bsoncore.AppendDocumentStart(dst)\n")
+	if fallback {
+		b.WriteString("\tvar err error\n")
+	}
+	for _, f := range fields {
+		present := ""
+		if f.OmitEmpty {
+			present = presentExpr(f.Type, f.Expr)
+		}
+		if present != "" {
+			fmt.Fprintf(b, "\tif %s {\n", present)
+		}
+		writeEncodeField(b, f, present != "")
+		if present != "" {
+			b.WriteString("\t}\n")
+		}
+	}
+	b.WriteString("\treturn bsoncore.AppendDocumentEnd(dst, idx)\n}\n")
+}
+
+// writeEncodeField writes the code appending f. nonNil is true if f is
+// known not to be a nil pointer.
+func writeEncodeField(b *bytes.Buffer, f codecField, nonNil bool) {
+	key := strconv.Quote(f.Key)
+	base := f.Base
+	if base == nil {
+		fmt.Fprintf(b, "\tif dst, err = goodmCodecAppend(dst, %s, &%s); err != nil {\n", key, f.Expr)
+		fmt.Fprintf(b, "\t\treturn nil, fmt.Errorf(\"goodm: encoding %s: %%w\", err)\n\t}\n", f.Source)
+		return
+	}
+	value := f.Expr
+	ptr := f.Type.Kind() == reflect.Ptr
+	if ptr {
+		if !nonNil {
+			fmt.Fprintf(b, "\tif %s == nil {\n\t\tdst = bsoncore.AppendNullElement(dst, %s)\n\t} else {\n", f.Expr, key)
+		}
+		value = "*" + f.Expr
+	}
+	switch {
+	case base == timeType:
+		if ptr {
+			value = "(" + value + ")"
+		}
+		fmt.Fprintf(b, "\tdst = bsoncore.AppendDateTimeElement(dst, %s, %s.UnixMilli())\n", key, value)
+	case base == objectIDType:
+		fmt.Fprintf(b, "\tdst = bsoncore.AppendObjectIDElement(dst, %s, %s)\n", key, value)
+	case base.Kind() == reflect.String:
+		fmt.Fprintf(b, "\tdst = bsoncore.AppendStringElement(dst, %s, %s)\n", key, convert(value, base, "string"))
+	case base.Kind() == reflect.Bool:
+		fmt.Fprintf(b, "\tdst = bsoncore.AppendBooleanElement(dst, %s, %s)\n", key, convert(value, base, "bool"))
+	case base.Kind() == reflect.Int:
+		fmt.Fprintf(b, "\tdst = goodmCodecAppendInt(dst, %s, int64(%s))\n", key, value)
+	case base.Kind() == reflect.Int64:
+		fmt.Fprintf(b, "\tdst = bsoncore.AppendInt64Element(dst, %s, %s)\n", key, convert(value, base, "int64"))
+	case base.Kind() == reflect.Float64:
+		fmt.Fprintf(b, "\tdst = bsoncore.AppendDoubleElement(dst, %s, %s)\n", key, convert(value, base, "float64"))
+	case base.Kind() == reflect.Slice:
+		fmt.Fprintf(b, "\tdst = goodmCodecAppendStrings(dst, %s, %s)\n", key, value)
+	default: // int8, int16, int32
+		fmt.Fprintf(b, "\tdst = bsoncore.AppendInt32Element(dst, %s, %s)\n", key, convert(value, base, "int32"))
+	}
+	if ptr && !nonNil {
+		b.WriteString("\t}\n")
+	}
+}
+
+func writeUnmarshal(b *bytes.Buffer, t reflect.Type, fields []codecField) {
+	fmt.Fprintf(b, "\n// UnmarshalBSON decodes data into m as the bson struct codec would, without\n// reflection.\n")
+	fmt.Fprintf(b, "func (m *%s) UnmarshalBSON(data []byte) error {\n", t.Name())
+	fmt.Fprintf(b, "\tif length, _, ok := bsoncore.ReadLength(data); !ok || int(length) != len(data) || length < 5 {\n")
+	fmt.Fprintf(b, "\t\treturn fmt.Errorf(\"goodm: decoding %s: malformed document\")\n\t}\n", t.Name())
+	b.WriteString("\tfor rem := data[4 : len(data)-1]; len(rem) > 0; {\n")
+	b.WriteString("\t\telem, rest, ok := bsoncore.ReadElement(rem)\n\t\tif !ok {\n")
+	fmt.Fprintf(b, "\t\t\treturn fmt.Errorf(\"goodm: decoding %s: malformed document\")\n\t\t}\n", t.Name())
+	b.WriteString("\t\trem = rest\n\t\tv := elem.Value()\n\t\tswitch string(elem.KeyBytes()) {\n")
+	for _, f := range fields {
+		fmt.Fprintf(b, "\t\tcase %s:\n", strconv.Quote(f.Key))
+		writeDecodeField(b, f)
+	}
+	b.WriteString("\t\t}\n\t}\n\treturn nil\n}\n")
+}
+
+func writeDecodeField(b *bytes.Buffer, f codecField) {
+	fallback := fmt.Sprintf("if err := goodmCodecDecode(v, &%s); err != nil {\n\t\t\t\treturn fmt.Errorf(\"goodm: decoding %s: %%w\", err)\n\t\t\t}", f.Expr, f.Source)
+	base := f.Base
+	if base == nil {
+		fmt.Fprintf(b, "\t\t\t%s\n", fallback)
+		return
+	}
+
+	var read, conv string
+	switch {
+	case base == timeType:
+		read, conv = "v.DateTimeOK()", "time.UnixMilli(x).UTC()"
+	case base == objectIDType:
+		read, conv = "v.ObjectIDOK()", "bson.ObjectID(x)"
+	case base.Kind() == reflect.String:
+		read, conv = "v.StringValueOK()", convertTo("x", "string", base)
+	case base.Kind() == reflect.Bool:
+		read, conv = "v.BooleanOK()", convertTo("x", "bool", base)
+	case base.Kind() == reflect.Float64:
+		read, conv = "v.DoubleOK()", convertTo("x", "float64", base)
+	case base.Kind() == reflect.Slice:
+		read, conv = "goodmCodecStrings(v)", "x"
+	default:
+		read = fmt.Sprintf("goodmCodecInt(v, %d)", base.Bits())
+		conv = convertTo("x", "int64", base)
+	}
+
+	if f.Type.Kind() == reflect.Ptr {
+		fmt.Fprintf(b, "\t\t\tif v.Type == bsoncore.TypeNull {\n\t\t\t\t%s = nil\n", f.Expr)
+		fmt.Fprintf(b, "\t\t\t} else if x, ok := %s; ok {\n\t\t\t\ty := %s\n\t\t\t\t%s = &y\n", read, conv, f.Expr)
+	} else {
+		fmt.Fprintf(b, "\t\t\tif x, ok := %s; ok {\n\t\t\t\t%s = %s\n", read, f.Expr, conv)
+	}
+	fmt.Fprintf(b, "\t\t\t} else %s\n", fallback)
+}
+
+// prefixEach joins items, writing sep before each.
+func prefixEach(sep string, items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return sep + strings.Join(items, sep)
+}
+
+// codecHelpers are the functions generated codecs share.
+const codecHelpers = `
+// goodmCodecAppendInt appends an int as the bson struct codec writes it: as
+// an int32 if it fits, otherwise as an int64.
+func goodmCodecAppendInt(dst []byte, key string, i int64) []byte {
+	if i >= -1<<31 && i < 1<<31 {
+		return bsoncore.AppendInt32Element(dst, key, int32(i))
+	}
+	return bsoncore.AppendInt64Element(dst, key, i)
+}
+
+// goodmCodecAppendStrings appends a []string, or null if it is nil.
+func goodmCodecAppendStrings(dst []byte, key string, s []string) []byte {
+	if s == nil {
+		return bsoncore.AppendNullElement(dst, key)
+	}
+	idx, dst := bsoncore.AppendArrayElementStart(dst, key)
+	for i, v := range s {
+		dst = bsoncore.AppendStringElement(dst, strconv.Itoa(i), v)
+	}
+	dst, _ = bsoncore.AppendArrayEnd(dst, idx)
+	return dst
+}
+
+// goodmCodecAppend appends the value v points to, encoded with goodm's
+// codec registry.
+func goodmCodecAppend(dst []byte, key string, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := bson.NewEncoder(bson.NewDocumentWriter(&buf))
+	enc.SetRegistry(goodm.CodecRegistry())
+	if err := enc.Encode(bson.D{{Key: key, Value: v}}); err != nil {
+		return dst, err
+	}
+	doc := buf.Bytes()
+	return append(dst, doc[4:len(doc)-1]...), nil
+}
+
+// goodmCodecDecode decodes v into the value dst points to with goodm's codec
+// registry.
+func goodmCodecDecode(v bsoncore.Value, dst interface{}) error {
+	return bson.RawValue{Type: bson.Type(v.Type), Value: v.Data}.UnmarshalWithRegistry(goodm.CodecRegistry(), dst)
+}
+
+// goodmCodecInt reads an int32 or int64 that fits in bits.
+func goodmCodecInt(v bsoncore.Value, bits int) (int64, bool) {
+	var i int64
+	switch v.Type {
+	case bsoncore.TypeInt32:
+		i = int64(v.Int32())
+	case bsoncore.TypeInt64:
+		i = v.Int64()
+	default:
+		return 0, false
+	}
+	if bits < 64 && (i < -1<<(bits-1) || i >= 1<<(bits-1)) {
+		return 0, false
+	}
+	return i, true
+}
+
+// goodmCodecStrings reads an array of strings.
+func goodmCodecStrings(v bsoncore.Value) ([]string, bool) {
+	arr, ok := v.ArrayOK()
+	if !ok {
+		return nil, false
+	}
+	values, err := arr.Values()
+	if err != nil {
+		return nil, false
+	}
+	s := make([]string, len(values))
+	for i, e := range values {
+		if s[i], ok = e.StringValueOK(); !ok {
+			return nil, false
+		}
+	}
+	return s, true
+}
+`
@@ -0,0 +1,165 @@
+package goodm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	coerceMu      sync.RWMutex
+	coerceEnabled bool
+)
+
+// SetObjectIDCoercion enables or disables converting hex strings in query
+// filters to bson.ObjectID for fields the schema types as ObjectID, including
+// _id. A hex string never matches a stored ObjectID, so without coercion such
+// filters silently match nothing. Applies to FindOne, Find, FindCursor,
+// FindPolymorphic, Explain, UpdateOne, UpdateMany, DeleteOne, and DeleteMany.
+// Disabled by default.
+func SetObjectIDCoercion(enabled bool) {
+	coerceMu.Lock()
+	defer coerceMu.Unlock()
+	coerceEnabled = enabled
+}
+
+// ObjectIDCoercionEnabled reports whether filters are coerced.
+func ObjectIDCoercionEnabled() bool {
+	coerceMu.RLock()
+	defer coerceMu.RUnlock()
+	return coerceEnabled
+}
+
+// CoerceObjectIDs returns filter with hex strings converted to bson.ObjectID
+// wherever they are matched against an ObjectID field of model: as the value,
+// as the operand of a comparison, or as an element of $in, $nin, or $all.
+// $and, $or, $nor, $not, and $elemMatch on subdocument arrays are followed.
+// A string that is not a valid ObjectID hex is an error.
+func CoerceObjectIDs(model interface{}, filter interface{}) (interface{}, error) {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return nil, err
+	}
+	return coerceFilterIDs(schema, filter)
+}
+
+// coerceFilter applies ObjectID coercion to filter when it is enabled.
+func coerceFilter(schema *Schema, filter interface{}) (interface{}, error) {
+	if !ObjectIDCoercionEnabled() {
+		return filter, nil
+	}
+	return coerceFilterIDs(schema, filter)
+}
+
+// coerceFilterIDs is CoerceObjectIDs for a resolved schema.
+func coerceFilterIDs(schema *Schema, filter interface{}) (interface{}, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	raw, err := marshalBSON(filter)
+	if err != nil {
+		return nil, fmt.Errorf("goodm: failed to encode filter: %w", err)
+	}
+	var doc bson.D
+	if err := unmarshalBSON(raw, &doc); err != nil {
+		return nil, fmt.Errorf("goodm: failed to decode filter: %w", err)
+	}
+	if err := coerceFilterDoc(filterFields(schema), doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// coerceFilterDoc converts, in place, the hex strings in doc matched against
+// ObjectID fields.
+func coerceFilterDoc(fields []FieldSchema, doc bson.D) error {
+	for i, e := range doc {
+		switch {
+		case e.Key == "$and" || e.Key == "$or" || e.Key == "$nor":
+			if clauses, ok := e.Value.(bson.A); ok {
+				for _, c := range clauses {
+					if cd, ok := c.(bson.D); ok {
+						if err := coerceFilterDoc(fields, cd); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		case strings.HasPrefix(e.Key, "$"):
+		default:
+			f, _ := filterField(fields, e.Key)
+			if f == nil {
+				continue
+			}
+			v, err := coerceOperand(f, e.Key, e.Value)
+			if err != nil {
+				return err
+			}
+			doc[i].Value = v
+		}
+	}
+	return nil
+}
+
+// coerceOperand converts the hex strings in the value matched against f.
+func coerceOperand(f *FieldSchema, path string, v interface{}) (interface{}, error) {
+	ops, ok := v.(bson.D)
+	if !ok || len(ops) == 0 || !strings.HasPrefix(ops[0].Key, "$") {
+		if isObjectIDField(f) {
+			return coerceHex(path, v)
+		}
+		return v, nil
+	}
+
+	for i, op := range ops {
+		var err error
+		switch op.Key {
+		case "$not":
+			ops[i].Value, err = coerceOperand(f, path, op.Value)
+		case "$elemMatch":
+			if query, ok := op.Value.(bson.D); ok && len(f.SubFields) > 0 && len(query) > 0 && !strings.HasPrefix(query[0].Key, "$") {
+				err = coerceFilterDoc(f.SubFields, query)
+			} else if isObjectIDField(f) {
+				ops[i].Value, err = coerceOperand(f, path, op.Value)
+			}
+		case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte":
+			if isObjectIDField(f) {
+				ops[i].Value, err = coerceHex(path, op.Value)
+			}
+		case "$in", "$nin", "$all":
+			if list, ok := op.Value.(bson.A); ok && isObjectIDField(f) {
+				for j := range list {
+					if list[j], err = coerceHex(path, list[j]); err != nil {
+						break
+					}
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ops, nil
+}
+
+// isObjectIDField reports whether f holds ObjectIDs: bson.ObjectID, a pointer
+// to one, or a slice of either.
+func isObjectIDField(f *FieldSchema) bool {
+	return strings.TrimLeft(f.Type, "[]*") == "bson.ObjectID"
+}
+
+// coerceHex converts a hex string to an ObjectID. Other values are returned
+// unchanged.
+func coerceHex(path string, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+	id, err := bson.ObjectIDFromHex(s)
+	if err != nil {
+		return nil, fmt.Errorf("goodm: filter value %q for %s is not an ObjectID", s, path)
+	}
+	return id, nil
+}
@@ -0,0 +1,71 @@
+package goodm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestCoerceObjectIDs(t *testing.T) {
+	useTestStore(t)
+	registerGraphModels(t)
+	id1, id2 := bson.NewObjectID(), bson.NewObjectID()
+
+	got, err := CoerceObjectIDs(&testGraphPost{}, bson.D{
+		{Key: "_id", Value: id1.Hex()},
+		{Key: "title", Value: id2.Hex()},
+		{Key: "author", Value: bson.D{{Key: "$in", Value: bson.A{id1.Hex(), id2}}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "tags", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$eq", Value: id2.Hex()}}}}}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("coerce: %v", err)
+	}
+	want := bson.D{
+		{Key: "_id", Value: id1},
+		{Key: "title", Value: id2.Hex()},
+		{Key: "author", Value: bson.D{{Key: "$in", Value: bson.A{id1, id2}}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "tags", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$eq", Value: id2}}}}}},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected filter:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestCoerceObjectIDs_InvalidHex(t *testing.T) {
+	useTestStore(t)
+	_, err := CoerceObjectIDs(&testUser{}, bson.M{"profile": "not-an-id"})
+	if err == nil || !strings.Contains(err.Error(), `"not-an-id" for profile`) {
+		t.Fatalf("expected invalid ObjectID error, got %v", err)
+	}
+}
+
+func TestSetObjectIDCoercion_Find(t *testing.T) {
+	ctx := useTestStore(t)
+	user := &testUser{Email: "hex@test.com", Name: "Hex"}
+	if err := Create(ctx, user); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	filter := bson.D{{Key: "_id", Value: user.ID.Hex()}}
+
+	if err := FindOne(ctx, filter, &testUser{}); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound without coercion, got %v", err)
+	}
+
+	SetObjectIDCoercion(true)
+	defer SetObjectIDCoercion(false)
+
+	found := &testUser{}
+	if err := FindOne(ctx, filter, found); err != nil || found.Email != "hex@test.com" {
+		t.Fatalf("expected user with coercion, got %+v, %v", found, err)
+	}
+	res, err := DeleteMany(ctx, filter, &testUser{})
+	if err != nil || res.DeletedCount != 1 {
+		t.Fatalf("expected 1 deleted, got %+v, %v", res, err)
+	}
+}
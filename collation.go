@@ -0,0 +1,99 @@
+package goodm
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ParseCollation parses a collation spec as used by the collation= tag and
+// CompoundIndex.WithCollation: an ICU locale, optionally followed by a
+// strength suffix.
+//
+//	en      locale defaults (case- and accent-sensitive)
+//	en_ci   case-insensitive (strength 2)
+//	en_ai   case- and accent-insensitive (strength 1)
+//
+// Example:
+//
+//	goodm.FindOne(ctx, filter, &user, goodm.FindOptions{Collation: goodm.MustParseCollation("en_ci")})
+func ParseCollation(spec string) (*options.Collation, error) {
+	locale, strength := spec, 0
+	switch {
+	case strings.HasSuffix(spec, "_ci"):
+		locale, strength = strings.TrimSuffix(spec, "_ci"), 2
+	case strings.HasSuffix(spec, "_ai"):
+		locale, strength = strings.TrimSuffix(spec, "_ai"), 1
+	}
+	if locale == "" || strings.ContainsAny(locale, " |,=") {
+		return nil, fmt.Errorf("goodm: invalid collation %q", spec)
+	}
+	return &options.Collation{Locale: locale, Strength: strength}, nil
+}
+
+// MustParseCollation is like ParseCollation but panics on an invalid spec.
+func MustParseCollation(spec string) *options.Collation {
+	c, err := ParseCollation(spec)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// checkCollations validates the collation specs declared on schema's field
+// tags and compound indexes.
+func checkCollations(schema *Schema) error {
+	for _, f := range schema.Fields {
+		if f.Collation == "" {
+			continue
+		}
+		if !f.Unique && !f.Index {
+			return fmt.Errorf("goodm: %s.%s: collation requires unique or index", schema.ModelName, f.Name)
+		}
+		if _, err := ParseCollation(f.Collation); err != nil {
+			return fmt.Errorf("goodm: %s.%s: %w", schema.ModelName, f.Name, err)
+		}
+	}
+	for _, ci := range schema.CompoundIndexes {
+		if ci.Collation == "" {
+			continue
+		}
+		if _, err := ParseCollation(ci.Collation); err != nil {
+			return fmt.Errorf("goodm: %s index %s: %w", schema.ModelName, compoundIndexName(ci), err)
+		}
+	}
+	return nil
+}
+
+// collationDoc returns c as a collation document for a raw command, using the
+// server's camelCase option names.
+func collationDoc(c *options.Collation) bson.D {
+	doc := bson.D{{Key: "locale", Value: c.Locale}}
+	if c.CaseLevel {
+		doc = append(doc, bson.E{Key: "caseLevel", Value: true})
+	}
+	if c.CaseFirst != "" {
+		doc = append(doc, bson.E{Key: "caseFirst", Value: c.CaseFirst})
+	}
+	if c.Strength != 0 {
+		doc = append(doc, bson.E{Key: "strength", Value: int32(c.Strength)})
+	}
+	if c.NumericOrdering {
+		doc = append(doc, bson.E{Key: "numericOrdering", Value: true})
+	}
+	if c.Alternate != "" {
+		doc = append(doc, bson.E{Key: "alternate", Value: c.Alternate})
+	}
+	if c.MaxVariable != "" {
+		doc = append(doc, bson.E{Key: "maxVariable", Value: c.MaxVariable})
+	}
+	if c.Normalization {
+		doc = append(doc, bson.E{Key: "normalization", Value: true})
+	}
+	if c.Backwards {
+		doc = append(doc, bson.E{Key: "backwards", Value: true})
+	}
+	return doc
+}
@@ -0,0 +1,119 @@
+package goodm
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type testCollated struct {
+	Model `bson:",inline"`
+	Email string `bson:"email" goodm:"unique,collation=en_ci"`
+	Name  string `bson:"name"`
+}
+
+type testBadCollation struct {
+	Model `bson:",inline"`
+	Email string `bson:"email" goodm:"collation=en_ci"`
+}
+
+func registerCollatedModel(t *testing.T) {
+	t.Helper()
+	if err := Register(&testCollated{}, "test_collated"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testCollated")
+		registryMu.Unlock()
+	})
+}
+
+func TestParseCollation(t *testing.T) {
+	tests := []struct {
+		spec     string
+		locale   string
+		strength int
+	}{
+		{"en", "en", 0},
+		{"en_ci", "en", 2},
+		{"en_US_ci", "en_US", 2},
+		{"fr_ai", "fr", 1},
+	}
+	for _, tt := range tests {
+		c, err := ParseCollation(tt.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.spec, err)
+		}
+		if c.Locale != tt.locale || c.Strength != tt.strength {
+			t.Fatalf("%s: expected %s/%d, got %s/%d", tt.spec, tt.locale, tt.strength, c.Locale, c.Strength)
+		}
+	}
+	for _, spec := range []string{"", "_ci", "en us"} {
+		if _, err := ParseCollation(spec); err == nil {
+			t.Fatalf("expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestRegister_Collation(t *testing.T) {
+	registerCollatedModel(t)
+
+	schema, _ := Get("testCollated")
+	if f := schema.GetField("email"); f == nil || f.Collation != "en_ci" || !f.Unique {
+		t.Fatalf("unexpected email field: %+v", f)
+	}
+
+	if err := Register(&testBadCollation{}, "test_bad_collation"); err == nil {
+		registryMu.Lock()
+		delete(registry, "testBadCollation")
+		registryMu.Unlock()
+		t.Fatal("expected collation without an index to be rejected")
+	}
+}
+
+func TestCollationDoc(t *testing.T) {
+	doc := collationDoc(&options.Collation{Locale: "en", Strength: 2, NumericOrdering: true})
+	want := bson.D{{Key: "locale", Value: "en"}, {Key: "strength", Value: int32(2)}, {Key: "numericOrdering", Value: true}}
+	if len(doc) != len(want) {
+		t.Fatalf("expected %v, got %v", want, doc)
+	}
+	for i := range want {
+		if doc[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, doc)
+		}
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestCollation_Integration(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+	registerCollatedModel(t)
+
+	if _, err := Enforce(ctx, db); err != nil {
+		t.Fatalf("enforce: %v", err)
+	}
+	if err := Create(ctx, &testCollated{Email: "Alice@Example.com", Name: "b"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	err := Create(ctx, &testCollated{Email: "alice@example.com"})
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("expected case-insensitive duplicate key error, got %v", err)
+	}
+
+	if err := Create(ctx, &testCollated{Email: "bob@example.com", Name: "a"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	found := &testCollated{}
+	if err := FindOne(ctx, bson.D{{Key: "email", Value: "ALICE@EXAMPLE.COM"}}, found,
+		FindOptions{Collation: MustParseCollation("en_ci")}); err != nil {
+		t.Fatalf("find with collation: %v", err)
+	}
+	if found.Name != "b" {
+		t.Fatalf("expected alice, got %+v", found)
+	}
+}
@@ -0,0 +1,117 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	commentMu      sync.RWMutex
+	commentEnabled bool
+)
+
+// goodmFuncPrefix is the qualified-name prefix of functions in this package.
+var goodmFuncPrefix = reflect.TypeOf(Model{}).PkgPath() + "."
+
+type queryCommentKey struct{}
+
+// SetQueryComments enables or disables tagging every query with a comment
+// naming the model, the goodm operation, the calling source location, and
+// the operation ID, e.g.
+// "goodm:User.Find (ordersvc/handler.go:42) op=65f1c0e2a4b9d3e1f0a1b2c3".
+// Comments show up in the database profiler, currentOp, and slow query logs.
+// Disabled by default.
+func SetQueryComments(enabled bool) {
+	commentMu.Lock()
+	defer commentMu.Unlock()
+	commentEnabled = enabled
+}
+
+// QueryCommentsEnabled reports whether queries are tagged with comments.
+func QueryCommentsEnabled() bool {
+	commentMu.RLock()
+	defer commentMu.RUnlock()
+	return commentEnabled
+}
+
+// withQueryComment attaches the query comment for the current goodm operation
+// to ctx when query comments are enabled. The operation and caller are taken
+// from the call stack: the outermost goodm function and the frame calling it.
+func withQueryComment(ctx context.Context, modelName string) context.Context {
+	if !QueryCommentsEnabled() {
+		return ctx
+	}
+
+	op, caller := callSite()
+	comment := "goodm:" + modelName
+	if op != "" {
+		comment += "." + op
+	}
+	if caller != "" {
+		comment += " (" + caller + ")"
+	}
+	if id := OpIDFromContext(ctx); id != "" {
+		comment += " op=" + id
+	}
+	return context.WithValue(ctx, queryCommentKey{}, comment)
+}
+
+// callSite returns the outermost goodm function on the call stack and the
+// source location of application code calling it.
+func callSite() (op, caller string) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if isGoodmFrame(frame) {
+			if name := strings.TrimPrefix(frame.Function, goodmFuncPrefix); !strings.Contains(name, ".func") {
+				op = strings.NewReplacer("(*", "", ")", "").Replace(name)
+			}
+		} else if frame.Function != "" {
+			caller = fmt.Sprintf("%s:%d", shortPath(frame.File), frame.Line)
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	return op, caller
+}
+
+// isGoodmFrame reports whether a stack frame belongs to goodm itself rather
+// than to application code. Test files count as application code.
+func isGoodmFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, goodmFuncPrefix) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// shortPath trims a source path to its directory and file name.
+func shortPath(path string) string {
+	dir, file := filepath.Split(path)
+	return filepath.ToSlash(filepath.Join(filepath.Base(dir), file))
+}
+
+// queryComment returns the comment attached by withQueryComment, if any.
+func queryComment(ctx context.Context) (string, bool) {
+	c, ok := ctx.Value(queryCommentKey{}).(string)
+	return c, ok
+}
+
+// commentSetter is implemented by the driver's options builders.
+type commentSetter[T any] interface {
+	SetComment(comment interface{}) T
+}
+
+// withComment sets the query comment from ctx on a driver options builder.
+func withComment[T commentSetter[T]](ctx context.Context, opts T) T {
+	if c, ok := queryComment(ctx); ok {
+		return opts.SetComment(c)
+	}
+	return opts
+}
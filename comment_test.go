@@ -0,0 +1,70 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestQueryComment_Disabled(t *testing.T) {
+	ctx := withQueryComment(context.Background(), "testUser")
+	if c, ok := queryComment(ctx); ok {
+		t.Fatalf("expected no comment when disabled, got %q", c)
+	}
+}
+
+func TestQueryComment_TagsModelOperationAndCaller(t *testing.T) {
+	registerTestModels()
+	defer unregisterTestModels()
+	SetQueryComments(true)
+	defer SetQueryComments(false)
+	defer ClearMiddleware()
+
+	stop := errors.New("stop")
+	var got string
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		got, _ = queryComment(ctx)
+		return stop
+	})
+
+	err := FindOne(context.Background(), bson.D{}, &testUser{})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected middleware to stop the operation, got %v", err)
+	}
+	if !strings.HasPrefix(got, "goodm:testUser.FindOne (") || !strings.Contains(got, "comment_test.go:") {
+		t.Fatalf("unexpected comment: %q", got)
+	}
+}
+
+func TestWithComment_SetsDriverOption(t *testing.T) {
+	ctx := context.WithValue(context.Background(), queryCommentKey{}, "goodm:testUser.Find")
+
+	var opts options.FindOptions
+	for _, apply := range withComment(ctx, options.Find()).Opts {
+		if err := apply(&opts); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+	}
+	c, ok := opts.Comment.(*interface{})
+	if !ok || *c != "goodm:testUser.Find" {
+		t.Fatalf("expected comment to be set, got %v", opts.Comment)
+	}
+
+	var plain options.FindOptions
+	for _, apply := range withComment(context.Background(), options.Find()).Opts {
+		_ = apply(&plain)
+	}
+	if plain.Comment != nil {
+		t.Fatalf("expected no comment, got %v", plain.Comment)
+	}
+}
+
+func TestShortPath(t *testing.T) {
+	if got := shortPath("/src/ordersvc/handler.go"); got != "ordersvc/handler.go" {
+		t.Fatalf("expected ordersvc/handler.go, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package goodm
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// LogLevel controls which operations LoggingMiddleware logs.
+type LogLevel int
+
+const (
+	LogAll    LogLevel = iota // every operation
+	LogSlow                   // failed operations and those at or above Config.SlowQueryThreshold
+	LogErrors                 // failed operations only
+	LogOff                    // nothing
+)
+
+// Config holds runtime settings that can be changed while the application
+// runs, with Configure. The zero Config is the default.
+type Config struct {
+	// DriftPolicy is the drift policy of Enforce calls made without
+	// EnforceOptions.
+	DriftPolicy DriftPolicy
+
+	// SlowQueryThreshold marks operations taking at least this long as slow
+	// in LoggingMiddleware. Zero marks none.
+	SlowQueryThreshold time.Duration
+
+	// OperationTimeout bounds every operation whose context has no deadline.
+	// Zero leaves such operations unbounded.
+	OperationTimeout time.Duration
+
+	// LogLevel is the verbosity of LoggingMiddleware.
+	LogLevel LogLevel
+}
+
+var config atomic.Pointer[Config]
+
+// Configure replaces the runtime configuration. It is safe to call at any
+// time, e.g. from a config file watcher or an admin endpoint; operations
+// started afterwards use cfg.
+//
+// Example:
+//
+//	cfg := goodm.CurrentConfig()
+//	cfg.LogLevel = goodm.LogSlow
+//	cfg.SlowQueryThreshold = 200 * time.Millisecond
+//	goodm.Configure(cfg)
+func Configure(cfg Config) {
+	config.Store(&cfg)
+}
+
+// CurrentConfig returns the runtime configuration set with Configure.
+func CurrentConfig() Config {
+	if cfg := config.Load(); cfg != nil {
+		return *cfg
+	}
+	return Config{}
+}
+
+// withOperationTimeout bounds ctx by Config.OperationTimeout unless it
+// already has a deadline.
+func withOperationTimeout(ctx context.Context, cfg Config) (context.Context, context.CancelFunc) {
+	if cfg.OperationTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, cfg.OperationTimeout)
+}
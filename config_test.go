@@ -0,0 +1,87 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestConfigure_OperationTimeout(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	t.Cleanup(func() { Configure(Config{}) })
+
+	var deadline time.Time
+	var bounded bool
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		deadline, bounded = ctx.Deadline()
+		return next(ctx)
+	})
+
+	_ = FindOne(ctx, bson.D{}, &testUser{})
+	if bounded {
+		t.Fatal("expected no deadline by default")
+	}
+
+	Configure(Config{OperationTimeout: time.Minute})
+	_ = FindOne(ctx, bson.D{}, &testUser{})
+	if !bounded || time.Until(deadline) > time.Minute {
+		t.Fatalf("expected a deadline within a minute, got %v (%v)", deadline, bounded)
+	}
+
+	own, cancel := context.WithTimeout(ctx, time.Hour)
+	defer cancel()
+	_ = FindOne(own, bson.D{}, &testUser{})
+	if time.Until(deadline) < 59*time.Minute {
+		t.Fatalf("expected the caller's deadline to be kept, got %v", deadline)
+	}
+}
+
+func TestConfigure_LogLevel(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	t.Cleanup(func() { Configure(Config{}) })
+
+	var lines []string
+	Use(LoggingMiddleware(func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}))
+	run := func() int {
+		lines = nil
+		_ = FindOne(ctx, bson.D{}, &testUser{})                                        // fails: not found
+		_ = Find(ctx, bson.D{}, &[]testUser{})                                         // succeeds
+		_ = FindOne(ctx, bson.D{{Key: "_id", Value: bson.NewObjectID()}}, &testUser{}) // fails: not found
+		return len(lines)
+	}
+
+	for _, tc := range []struct {
+		cfg  Config
+		want int
+	}{
+		{Config{}, 3},
+		{Config{LogLevel: LogErrors}, 2},
+		{Config{LogLevel: LogSlow, SlowQueryThreshold: time.Hour}, 2},
+		{Config{LogLevel: LogSlow, SlowQueryThreshold: time.Nanosecond}, 3},
+		{Config{LogLevel: LogOff}, 0},
+	} {
+		Configure(tc.cfg)
+		if got := run(); got != tc.want {
+			t.Errorf("%+v: expected %d lines, got %d: %q", tc.cfg, tc.want, got, lines)
+		}
+	}
+
+	Configure(Config{SlowQueryThreshold: time.Nanosecond})
+	run()
+	if len(lines) != 3 || !strings.Contains(lines[1], "slow=true") {
+		t.Errorf("expected slow operations to be marked, got %q", lines)
+	}
+	if got := CurrentConfig(); got.SlowQueryThreshold != time.Nanosecond {
+		t.Errorf("expected CurrentConfig to return the configured settings, got %+v", got)
+	}
+}
@@ -0,0 +1,147 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ConflictStrategy selects how Update reacts when the stored document's
+// version no longer matches the model's.
+type ConflictStrategy int
+
+const (
+	// ConflictFail returns ErrVersionConflict. This is the default.
+	ConflictFail ConflictStrategy = iota
+
+	// ConflictLastWriteWins skips the version check and overwrites the
+	// stored document with the caller's model.
+	ConflictLastWriteWins
+
+	// ConflictMerge re-reads the document and applies the caller's changed
+	// fields when they don't overlap with the other writer's changes, as
+	// WithRetry does. Overlaps return a *MergeConflictError.
+	ConflictMerge
+
+	// ConflictResolve re-reads the document and hands it to the model's
+	// ResolveConflict method, then saves the result.
+	ConflictResolve
+)
+
+// String returns the strategy's name: "fail", "last_write_wins", "merge", or
+// "resolve".
+func (s ConflictStrategy) String() string {
+	switch s {
+	case ConflictFail:
+		return "fail"
+	case ConflictLastWriteWins:
+		return "last_write_wins"
+	case ConflictMerge:
+		return "merge"
+	case ConflictResolve:
+		return "resolve"
+	}
+	return fmt.Sprintf("ConflictStrategy(%d)", int(s))
+}
+
+// MarshalText encodes the strategy by name, e.g. in JSON schema output.
+func (s ConflictStrategy) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// defaultConflictRetries bounds the number of merge or resolve attempts when
+// a model selects a strategy but the caller does not pass WithRetry.
+const defaultConflictRetries = 3
+
+// ConflictHandler is implemented by models that choose how version conflicts
+// on Update are handled.
+//
+// Example:
+//
+//	func (h *Heartbeat) ConflictStrategy() goodm.ConflictStrategy {
+//	    return goodm.ConflictLastWriteWins
+//	}
+type ConflictHandler interface {
+	ConflictStrategy() ConflictStrategy
+}
+
+// ConflictResolver is implemented by models that resolve version conflicts
+// themselves. The receiver holds the caller's changes ("mine"); theirs is a
+// freshly loaded copy of the stored document, of the same type. Modify the
+// receiver into the state that should be saved, or return an error to abort
+// the Update. Implementing ConflictResolver selects ConflictResolve unless the
+// model also implements ConflictHandler.
+//
+// Example:
+//
+//	func (c *Counter) ResolveConflict(ctx context.Context, theirs interface{}) error {
+//	    c.Hits += theirs.(*Counter).Hits
+//	    return nil
+//	}
+type ConflictResolver interface {
+	ResolveConflict(ctx context.Context, theirs interface{}) error
+}
+
+// detectConflictStrategy returns the strategy a model selects through
+// ConflictHandler or ConflictResolver.
+func detectConflictStrategy(model interface{}, modelName string) (ConflictStrategy, error) {
+	_, canResolve := model.(ConflictResolver)
+	strategy := ConflictFail
+	if handler, ok := model.(ConflictHandler); ok {
+		strategy = handler.ConflictStrategy()
+	} else if canResolve {
+		strategy = ConflictResolve
+	}
+
+	if strategy < ConflictFail || strategy > ConflictResolve {
+		return ConflictFail, fmt.Errorf("goodm: model %q has unknown conflict strategy %d", modelName, strategy)
+	}
+	if strategy == ConflictResolve && !canResolve {
+		return ConflictFail, fmt.Errorf("goodm: model %q uses ConflictResolve but has no ResolveConflict method", modelName)
+	}
+	return strategy, nil
+}
+
+// saveLastWriteWins performs a versioned save and, if another writer got there
+// first, overwrites their document regardless of its version.
+func saveLastWriteWins(ctx context.Context, coll collection, model interface{}, opt UpdateOptions, carry bson.M, id bson.ObjectID) error {
+	err := attemptSave(ctx, coll, model, opt.Unset, carry, id)
+	if err != ErrVersionConflict {
+		return err
+	}
+	refreshModelVersion(ctx, coll, model, id)
+	return saveWithFilter(ctx, coll, model, opt.Unset, carry, id, bson.D{{Key: "_id", Value: id}})
+}
+
+// resolveFromDB re-reads the document and lets the model's ResolveConflict
+// method reconcile it with the caller's changes. The resolved model is
+// re-validated and takes over the stored version for the next attempt.
+func resolveFromDB(ctx context.Context, coll collection, model interface{}, schema *Schema, id bson.ObjectID) error {
+	resolver, ok := model.(ConflictResolver)
+	if !ok {
+		return ErrVersionConflict
+	}
+
+	theirs := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}}, withComment(ctx, options.FindOne())).Decode(theirs); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrNotFound
+		}
+		return fmt.Errorf("goodm: failed to re-read document for conflict resolution: %w", err)
+	}
+
+	if err := resolver.ResolveConflict(ctx, theirs); err != nil {
+		return err
+	}
+	if errs := Validate(model, schema); len(errs) > 0 {
+		return ValidationErrors(errs)
+	}
+
+	version, _ := getModelVersion(theirs)
+	setModelVersion(model, version)
+	return nil
+}
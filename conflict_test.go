@@ -0,0 +1,248 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testHeartbeat struct {
+	Model `bson:",inline"`
+	Node  string `bson:"node" goodm:"required"`
+	Seen  int    `bson:"seen"`
+}
+
+func (h *testHeartbeat) ConflictStrategy() ConflictStrategy { return ConflictLastWriteWins }
+
+type testCounter struct {
+	Model `bson:",inline"`
+	Name  string `bson:"name" goodm:"required"`
+	Hits  int    `bson:"hits"`
+}
+
+func (c *testCounter) ResolveConflict(ctx context.Context, theirs interface{}) error {
+	c.Hits += theirs.(*testCounter).Hits
+	return nil
+}
+
+type testBadStrategy struct {
+	Model `bson:",inline"`
+}
+
+func (b *testBadStrategy) ConflictStrategy() ConflictStrategy { return ConflictResolve }
+
+func TestDetectConflictStrategy(t *testing.T) {
+	tests := []struct {
+		model interface{}
+		want  ConflictStrategy
+	}{
+		{&testUser{}, ConflictFail},
+		{&testHeartbeat{}, ConflictLastWriteWins},
+		{&testCounter{}, ConflictResolve},
+	}
+	for _, tt := range tests {
+		got, err := detectConflictStrategy(tt.model, "m")
+		if err != nil {
+			t.Fatalf("%T: unexpected error: %v", tt.model, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%T: expected strategy %d, got %d", tt.model, tt.want, got)
+		}
+	}
+
+	if _, err := detectConflictStrategy(&testBadStrategy{}, "m"); err == nil {
+		t.Fatal("expected error for ConflictResolve without ResolveConflict")
+	}
+}
+
+func TestRegister_ConflictStrategy(t *testing.T) {
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "testHeartbeat")
+		delete(registry, "testBadStrategy")
+		registryMu.Unlock()
+	}()
+
+	if err := Register(&testHeartbeat{}, "test_heartbeats"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	schema, _ := Get("testHeartbeat")
+	if schema.Conflict != ConflictLastWriteWins {
+		t.Fatalf("expected ConflictLastWriteWins, got %d", schema.Conflict)
+	}
+
+	if err := Register(&testBadStrategy{}, "test_bad"); err == nil {
+		t.Fatal("expected Register to reject ConflictResolve without ResolveConflict")
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestUpdate_LastWriteWins(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+	_ = Register(&testHeartbeat{}, "test_heartbeats")
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "testHeartbeat")
+		registryMu.Unlock()
+	}()
+
+	h := &testHeartbeat{Node: "a"}
+	if err := Create(ctx, h); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	stale := &testHeartbeat{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: h.ID}}, stale); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	h.Seen = 1
+	if err := Update(ctx, h); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	stale.Seen = 2
+	if err := Update(ctx, stale); err != nil {
+		t.Fatalf("expected last write to win, got %v", err)
+	}
+
+	found := &testHeartbeat{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: h.ID}}, found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Seen != 2 || found.Version != 2 {
+		t.Fatalf("expected seen=2 at version 2, got seen=%d version=%d", found.Seen, found.Version)
+	}
+}
+
+func TestUpdate_ResolveConflict(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+	_ = Register(&testCounter{}, "test_counters")
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "testCounter")
+		registryMu.Unlock()
+	}()
+
+	c := &testCounter{Name: "views"}
+	if err := Create(ctx, c); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	other := &testCounter{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: c.ID}}, other); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	c.Hits = 3
+	if err := Update(ctx, c); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	other.Hits = 5
+	if err := Update(ctx, other); err != nil {
+		t.Fatalf("expected conflict to be resolved, got %v", err)
+	}
+	if other.Hits != 8 {
+		t.Fatalf("expected resolved hits=8, got %d", other.Hits)
+	}
+
+	found := &testCounter{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: c.ID}}, found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Hits != 8 {
+		t.Fatalf("expected stored hits=8, got %d", found.Hits)
+	}
+}
+
+func TestUpdate_DefaultStrategyFails(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	u := &testUser{Email: "strategy@test.com", Name: "Strategy", Role: "user"}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	stale := &testUser{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, stale); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	u.Age = 1
+	if err := Update(ctx, u); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	stale.Age = 2
+	if err := Update(ctx, stale); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected default strategy to fail with ErrVersionConflict, got %v", err)
+	}
+}
+
+type testMergeable struct {
+	Model `bson:",inline"`
+	A     string `bson:"a"`
+	B     string `bson:"b"`
+}
+
+func (m *testMergeable) ConflictStrategy() ConflictStrategy { return ConflictMerge }
+
+func TestUpdate_MergeDisjointChanges(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testMergeable{}, "test_mergeables"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testMergeable")
+		registryMu.Unlock()
+	})
+
+	doc := &testMergeable{A: "a0", B: "b0"}
+	if err := Create(ctx, doc); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	load := func() *testMergeable {
+		t.Helper()
+		m := &testMergeable{}
+		if err := FindOne(ctx, bson.D{{Key: "_id", Value: doc.ID}}, m); err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		return m
+	}
+
+	mine, theirs := load(), load()
+	theirs.A = "a1"
+	if err := Update(ctx, theirs); err != nil {
+		t.Fatalf("their update: %v", err)
+	}
+	mine.B = "b1"
+	if err := Update(ctx, mine); err != nil {
+		t.Fatalf("expected the stale update to merge, got %v", err)
+	}
+	if found := load(); found.A != "a1" || found.B != "b1" || found.Version != 2 {
+		t.Errorf("expected both changes at version 2, got %+v", found)
+	}
+
+	// A second merge against the same original keeps working.
+	stale := load()
+	other := load()
+	other.A = "a2"
+	if err := Update(ctx, other); err != nil {
+		t.Fatalf("other update: %v", err)
+	}
+	stale.A = "a3"
+	var mergeErr *MergeConflictError
+	if err := Update(ctx, stale); !errors.As(err, &mergeErr) || len(mergeErr.Fields) != 1 || mergeErr.Fields[0] != "a" {
+		t.Errorf("expected a merge conflict on a, got %v", err)
+	}
+
+	// Without a loaded original there is nothing to merge against.
+	untracked := &testMergeable{Model: Model{ID: doc.ID}, A: "a2", B: "b9"}
+	if err := Update(ctx, untracked); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict for an untracked model, got %v", err)
+	}
+	if found := load(); found.B != "b1" {
+		t.Errorf("expected the untracked update to be rejected, got %+v", found)
+	}
+}
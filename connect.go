@@ -17,8 +17,27 @@ var (
 // Connect establishes a connection to MongoDB and returns the database handle.
 // It also stores the database reference globally for use by Enforce and the CLI.
 func Connect(ctx context.Context, uri string, dbName string) (*mongo.Database, error) {
-	clientOpts := options.Client().ApplyURI(uri)
-	client, err := mongo.Connect(clientOpts)
+	return ConnectWithOptions(ctx, options.Client().ApplyURI(uri), dbName)
+}
+
+// ConnectWithOptions is like Connect, but creates the client from opts, for
+// settings a URI cannot carry, such as a custom dialer, monitors, or
+// credentials from a secret store. goodm's codec registry, holding the codecs
+// installed by RegisterCodec and RegisterInterface, is installed on opts
+// unless it already sets a registry.
+//
+// Example:
+//
+//	opts := options.Client().ApplyURI(uri).SetAuth(cred).SetMaxPoolSize(50)
+//	db, err := goodm.ConnectWithOptions(ctx, opts, "myapp")
+func ConnectWithOptions(ctx context.Context, opts *options.ClientOptions, dbName string) (*mongo.Database, error) {
+	if opts == nil {
+		opts = options.Client()
+	}
+	if opts.Registry == nil {
+		opts.SetRegistry(CodecRegistry())
+	}
+	client, err := mongo.Connect(opts)
 	if err != nil {
 		return nil, fmt.Errorf("goodm: failed to connect: %w", err)
 	}
@@ -44,3 +63,11 @@ func DB() *mongo.Database {
 	defer dbMu.RUnlock()
 	return globalDB
 }
+
+// SetDB replaces the globally stored database reference, for applications
+// that create their own client. Pass nil to clear it.
+func SetDB(db *mongo.Database) {
+	dbMu.Lock()
+	globalDB = db
+	dbMu.Unlock()
+}
@@ -0,0 +1,213 @@
+package goodm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ArtifactFormat is the format version written to schema artifacts.
+const ArtifactFormat = 1
+
+// SchemaArtifact is the published contract of the collections a service
+// owns: its registered schemas, labelled with the service name and a version
+// of the service's choosing. Another service imports it with ImportSchemas to
+// check its filters and refs without importing the owner's Go packages.
+type SchemaArtifact struct {
+	Format  int             `json:"format"`
+	Service string          `json:"service"`
+	Version string          `json:"version"`
+	Models  []ModelSnapshot `json:"models"`
+}
+
+var (
+	importedMu sync.RWMutex
+	imported   = map[string]map[string]*Schema{} // service -> model name -> schema
+)
+
+// ExportSchemas returns the artifact of the given schemas, published by
+// service at version.
+//
+// Example:
+//
+//	art := goodm.ExportSchemas("billing", "2.3.0", goodm.GetAll())
+//	err := goodm.WriteArtifact(f, art)
+func ExportSchemas(service, version string, schemas map[string]*Schema) *SchemaArtifact {
+	return &SchemaArtifact{
+		Format:  ArtifactFormat,
+		Service: service,
+		Version: version,
+		Models:  Snapshot(schemas).Models,
+	}
+}
+
+// WriteArtifact writes a to w as indented JSON.
+func WriteArtifact(w io.Writer, a *SchemaArtifact) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("goodm: encode artifact: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("goodm: write artifact: %w", err)
+	}
+	return nil
+}
+
+// ReadArtifact reads an artifact written by WriteArtifact.
+func ReadArtifact(r io.Reader) (*SchemaArtifact, error) {
+	var a SchemaArtifact
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("goodm: decode artifact: %w", err)
+	}
+	if a.Format != ArtifactFormat {
+		return nil, fmt.Errorf("goodm: unsupported artifact format %d", a.Format)
+	}
+	if a.Service == "" {
+		return nil, fmt.Errorf("goodm: artifact names no service")
+	}
+	return &a, nil
+}
+
+// LoadArtifact reads the artifact file at path.
+func LoadArtifact(path string) (*SchemaArtifact, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("goodm: open artifact: %w", err)
+	}
+	defer f.Close()
+	return ReadArtifact(f)
+}
+
+// ImportSchemas makes the schemas of another service's artifact available
+// read-only, replacing any earlier import from the same service. Imported
+// schemas are not registered: they cannot be used for reads or writes and
+// are left out of GetAll, Enforce, and migrations. Look them up with
+// GetImported, check filters with CheckImportedFilter, and refs to their
+// collections resolve in CheckRefs.
+//
+// Example:
+//
+//	art, err := goodm.LoadArtifact("contracts/billing.schema.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = goodm.ImportSchemas(art)
+func ImportSchemas(a *SchemaArtifact) error {
+	if a.Service == "" {
+		return fmt.Errorf("goodm: artifact names no service")
+	}
+	schemas := make(map[string]*Schema, len(a.Models))
+	for _, m := range a.Models {
+		if _, dup := schemas[m.Name]; dup {
+			return fmt.Errorf("goodm: artifact of %s declares model %s twice", a.Service, m.Name)
+		}
+		s := &Schema{
+			ModelName:  m.Name,
+			Collection: m.Collection,
+			Fields:     importedFields(m.Fields),
+			Service:    a.Service,
+		}
+		for _, ix := range m.Indexes {
+			s.CompoundIndexes = append(s.CompoundIndexes, CompoundIndex{Fields: ix.Fields, Unique: ix.Unique})
+		}
+		schemas[m.Name] = s
+	}
+
+	importedMu.Lock()
+	defer importedMu.Unlock()
+	imported[a.Service] = schemas
+	return nil
+}
+
+// importedFields converts snapshot fields back into field schemas. Go field
+// names are not part of an artifact, so Name holds the bson name.
+func importedFields(fields []FieldSnapshot) []FieldSchema {
+	if len(fields) == 0 {
+		return nil
+	}
+	out := make([]FieldSchema, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, FieldSchema{
+			Name: f.Name, BSONName: f.Name, Type: f.Type, Required: f.Required, Unique: f.Unique, Index: f.Index,
+			Immutable: f.Immutable, Deprecated: f.Deprecated, Default: f.Default, Enum: f.Enum, Ref: f.Ref,
+			SubFields: importedFields(f.Fields),
+		})
+	}
+	return out
+}
+
+// GetImported returns the schema of model imported from service, or false
+// if none was imported.
+func GetImported(service, model string) (*Schema, bool) {
+	importedMu.RLock()
+	defer importedMu.RUnlock()
+	s, ok := imported[service][model]
+	return s, ok
+}
+
+// CheckImportedFilter reports the fields of filter that model, imported
+// from service, does not declare, as CheckFilter does for registered models.
+//
+// Example:
+//
+//	err := goodm.CheckImportedFilter("billing", "Invoice", bson.D{{Key: "status", Value: "open"}})
+func CheckImportedFilter(service, model string, filter interface{}) error {
+	schema, ok := GetImported(service, model)
+	if !ok {
+		return fmt.Errorf("goodm: no model %s imported from %s", model, service)
+	}
+	return checkFilter(schema, filter)
+}
+
+// CheckRefs verifies that every ref field of schemas names the collection of
+// a registered or imported model, and returns an error listing those that
+// do not.
+//
+// Example:
+//
+//	if err := goodm.CheckRefs(goodm.GetAll()); err != nil {
+//	    log.Fatal(err)
+//	}
+func CheckRefs(schemas map[string]*Schema) error {
+	known := make(map[string]bool)
+	for _, s := range GetAll() {
+		known[s.Collection] = true
+	}
+	importedMu.RLock()
+	for _, models := range imported {
+		for _, s := range models {
+			known[s.Collection] = true
+		}
+	}
+	importedMu.RUnlock()
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var unresolved []string
+	for _, name := range names {
+		collectUnresolvedRefs(name+".", schemas[name].Fields, known, &unresolved)
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("goodm: refs to unknown collections: %s", strings.Join(unresolved, ", "))
+	}
+	return nil
+}
+
+// collectUnresolvedRefs appends "path -> collection" for each ref field
+// below fields whose collection is not known.
+func collectUnresolvedRefs(prefix string, fields []FieldSchema, known map[string]bool, unresolved *[]string) {
+	for _, f := range fields {
+		if f.Ref != "" && !known[f.Ref] {
+			*unresolved = append(*unresolved, fmt.Sprintf("%s%s -> %s", prefix, f.BSONName, f.Ref))
+		}
+		collectUnresolvedRefs(prefix+f.BSONName+".", f.SubFields, known, unresolved)
+	}
+}
@@ -0,0 +1,98 @@
+package goodm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testContractLine struct {
+	SKU string `bson:"sku"`
+}
+
+type testContractInvoice struct {
+	Model    `bson:",inline"`
+	Status   string             `bson:"status" goodm:"enum=open|paid"`
+	Customer bson.ObjectID      `bson:"customer" goodm:"ref=customers"`
+	Lines    []testContractLine `bson:"lines"`
+}
+
+type testContractShipment struct {
+	Model   `bson:",inline"`
+	Invoice bson.ObjectID `bson:"invoice" goodm:"ref=invoices"`
+}
+
+func TestSchemaArtifact_ImportAndCheck(t *testing.T) {
+	// The owning service exports its schema.
+	if err := Register(&testContractInvoice{}, "invoices"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	schema, _ := Get("testContractInvoice")
+	registryMu.Lock()
+	delete(registry, "testContractInvoice")
+	registryMu.Unlock()
+
+	var buf bytes.Buffer
+	art := ExportSchemas("billing", "2.3.0", map[string]*Schema{"testContractInvoice": schema})
+	if err := WriteArtifact(&buf, art); err != nil {
+		t.Fatalf("WriteArtifact: %v", err)
+	}
+
+	// A consumer imports it and refers to its collection.
+	read, err := ReadArtifact(&buf)
+	if err != nil {
+		t.Fatalf("ReadArtifact: %v", err)
+	}
+	if read.Service != "billing" || read.Version != "2.3.0" || len(read.Models) != 1 {
+		t.Fatalf("unexpected artifact: %+v", read)
+	}
+	if err := Register(&testContractShipment{}, "shipments"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testContractShipment")
+		registryMu.Unlock()
+		importedMu.Lock()
+		delete(imported, "billing")
+		importedMu.Unlock()
+	})
+	shipments := map[string]*Schema{}
+	shipments["testContractShipment"], _ = Get("testContractShipment")
+
+	if err := CheckRefs(shipments); err == nil || !strings.Contains(err.Error(), "testContractShipment.invoice -> invoices") {
+		t.Errorf("expected an unresolved ref before importing, got %v", err)
+	}
+	if err := ImportSchemas(read); err != nil {
+		t.Fatalf("ImportSchemas: %v", err)
+	}
+	if err := CheckRefs(shipments); err != nil {
+		t.Errorf("expected refs to resolve after importing, got %v", err)
+	}
+
+	imp, ok := GetImported("billing", "testContractInvoice")
+	if !ok || imp.Service != "billing" || imp.Collection != "invoices" {
+		t.Fatalf("expected the imported schema, got %+v", imp)
+	}
+	if _, ok := Get("testContractInvoice"); ok {
+		t.Error("imported schemas must not be registered")
+	}
+	if err := CheckImportedFilter("billing", "testContractInvoice", bson.D{{Key: "status", Value: "open"}, {Key: "lines.0.sku", Value: "A"}}); err != nil {
+		t.Errorf("expected a valid filter, got %v", err)
+	}
+	var unknown *UnknownFilterFieldsError
+	err = CheckImportedFilter("billing", "testContractInvoice", bson.D{{Key: "stauts", Value: "open"}})
+	if !errors.As(err, &unknown) || len(unknown.Fields) != 1 || unknown.Fields[0] != "stauts" {
+		t.Errorf("expected stauts to be unknown, got %v", err)
+	}
+	if err := CheckImportedFilter("billing", "testRefund", bson.D{}); err == nil {
+		t.Error("expected an error for a model that was not imported")
+	}
+
+	if _, err := ReadArtifact(strings.NewReader(`{"format": 9, "service": "billing"}`)); err == nil {
+		t.Error("expected an unsupported format to be rejected")
+	}
+}
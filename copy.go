@@ -0,0 +1,299 @@
+package goodm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// CopyOptions configures CopyTo and CopyFrom.
+type CopyOptions struct {
+	Exclude []string // bson names of model fields not to copy, in addition to the defaults
+}
+
+// CopyTo copies the fields of a registered model into dto, a pointer to an
+// API payload struct, replacing field-by-field mapping in handlers. Fields
+// are matched by name: a dto field matches a model field when its json tag,
+// bson tag, or Go name equals the model field's bson name, json name, or Go
+// name. Unmatched fields are left alone.
+//
+// Values are converted where the intent is clear: between T and *T, between
+// types of the same kind (such as a named string type and string) or between
+// numeric types, from bson.ObjectID to its hex string and back, and
+// element-wise for slices, string-keyed maps, and nested structs, which are
+// matched by name too. Any other mismatch is an error.
+//
+// Hidden (select=false) fields are never copied to a DTO, so secrets such as
+// password hashes stay out of responses; opts.Exclude names more fields.
+//
+// Example:
+//
+//	var out UserResponse
+//	err := goodm.CopyTo(&user, &out, goodm.CopyOptions{Exclude: []string{"internal_notes"}})
+func CopyTo(model, dto interface{}, opts ...CopyOptions) error {
+	var opt CopyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	src := reflect.Indirect(reflect.ValueOf(model))
+	dst, err := copyTarget(dto)
+	if err != nil {
+		return err
+	}
+
+	skip, err := copyExclusions(schema, opt, func(f FieldSchema) bool { return f.Hidden })
+	if err != nil {
+		return err
+	}
+	return copyStruct(dst, src, skip, nil, "")
+}
+
+// CopyFrom copies the fields of dto, an API payload struct, into a pointer to
+// a registered model, matching and converting fields as CopyTo does.
+//
+// Fields the client must not set are never copied from a DTO: the managed
+// _id, created_at, updated_at, and __v fields, immutable fields, and hidden
+// (select=false) fields. opts.Exclude names more fields. Set excluded fields
+// on the model directly when they are legitimately part of the request.
+//
+// Example:
+//
+//	var in CreateUserRequest
+//	_ = json.NewDecoder(r.Body).Decode(&in)
+//	user := &User{}
+//	if err := goodm.CopyFrom(in, user); err != nil { ... }
+//	err = goodm.Create(ctx, user)
+func CopyFrom(dto, model interface{}, opts ...CopyOptions) error {
+	var opt CopyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	dst, err := copyTarget(model)
+	if err != nil {
+		return err
+	}
+	src := reflect.Indirect(reflect.ValueOf(dto))
+	if src.Kind() != reflect.Struct {
+		return fmt.Errorf("goodm: CopyFrom: dto must be a struct or a pointer to one, got %T", dto)
+	}
+
+	skip, err := copyExclusions(schema, opt, func(f FieldSchema) bool {
+		return schema.base().managed(f.BSONName) || f.Immutable || f.Hidden
+	})
+	if err != nil {
+		return err
+	}
+	return copyStruct(dst, src, nil, skip, "")
+}
+
+// copyTarget returns the struct a non-nil pointer points to.
+func copyTarget(ptr interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("goodm: copy target must be a non-nil pointer to a struct, got %T", ptr)
+	}
+	return v.Elem(), nil
+}
+
+// copyExclusions returns the Go names of the model fields not to copy: those
+// matching byDefault and those named in opt.Exclude.
+func copyExclusions(schema *Schema, opt CopyOptions, byDefault func(FieldSchema) bool) (map[string]bool, error) {
+	skip := map[string]bool{}
+	for _, f := range schema.Fields {
+		if byDefault(f) {
+			skip[f.Name] = true
+		}
+	}
+	for _, name := range opt.Exclude {
+		f := schema.GetField(name)
+		if f == nil {
+			return nil, fmt.Errorf("goodm: %s has no field %q to exclude", schema.ModelName, name)
+		}
+		skip[f.Name] = true
+	}
+	return skip, nil
+}
+
+// copyStruct copies the fields of src into the matching fields of dst.
+// skipSrc and skipDst hold Go names of fields to leave out on either side.
+func copyStruct(dst, src reflect.Value, skipSrc, skipDst map[string]bool, path string) error {
+	srcFields := copyFields(src.Type())
+	for _, df := range copyFields(dst.Type()) {
+		if skipDst[df.Name] {
+			continue
+		}
+		dstKeys := copyKeys(df)
+		if len(dstKeys) == 0 {
+			continue
+		}
+		for _, sf := range srcFields {
+			if skipSrc[sf.Name] || !keysOverlap(dstKeys, copyKeys(sf)) {
+				continue
+			}
+			fieldPath := df.Name
+			if path != "" {
+				fieldPath = path + "." + df.Name
+			}
+			if err := copyValue(dst.FieldByIndex(df.Index), src.FieldByIndex(sf.Index), fieldPath); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// copyFields returns the exported fields of t, including those promoted
+// from embedded structs, with their full index paths. Fields promoted
+// through embedded pointers are skipped.
+func copyFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+outer:
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous && derefType(f.Type).Kind() == reflect.Struct {
+			continue
+		}
+		for i := 1; i < len(f.Index); i++ {
+			if t.FieldByIndex(f.Index[:i]).Type.Kind() == reflect.Ptr {
+				continue outer
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// copyKeys returns the names a field can be matched by: its Go name and the
+// names in its bson and json tags. Fields excluded from both encodings with
+// "-" have no names.
+func copyKeys(f reflect.StructField) []string {
+	keys := []string{f.Name}
+	excluded := 0
+	for _, key := range []string{"bson", "json"} {
+		tag, ok := f.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		switch name {
+		case "-":
+			excluded++
+		case "":
+		default:
+			keys = append(keys, name)
+		}
+	}
+	if excluded == 2 {
+		return nil
+	}
+	return keys
+}
+
+// keysOverlap reports whether a and b share a name.
+func keysOverlap(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// copyValue assigns src to dst, converting between compatible types.
+func copyValue(dst, src reflect.Value, path string) error {
+	st, dt := src.Type(), dst.Type()
+	switch {
+	case st.AssignableTo(dt):
+		dst.Set(src)
+		return nil
+	case st.Kind() == reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dt))
+			return nil
+		}
+		return copyValue(dst, src.Elem(), path)
+	case dt.Kind() == reflect.Ptr:
+		elem := reflect.New(dt.Elem())
+		if err := copyValue(elem.Elem(), src, path); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case st == objectIDType && dt.Kind() == reflect.String:
+		hex := ""
+		if id := src.Interface().(bson.ObjectID); !id.IsZero() {
+			hex = id.Hex()
+		}
+		dst.SetString(hex)
+		return nil
+	case st.Kind() == reflect.String && dt == objectIDType:
+		var id bson.ObjectID
+		if s := src.String(); s != "" {
+			var err error
+			if id, err = bson.ObjectIDFromHex(s); err != nil {
+				return fmt.Errorf("goodm: copy %s: %q is not an ObjectID", path, s)
+			}
+		}
+		dst.Set(reflect.ValueOf(id))
+		return nil
+	case (st.Kind() == dt.Kind() || isNumericKind(st.Kind()) && isNumericKind(dt.Kind())) &&
+		st.Kind() != reflect.Struct && st.Kind() != reflect.Slice && st.Kind() != reflect.Map && st.ConvertibleTo(dt):
+		dst.Set(src.Convert(dt))
+		return nil
+	case st.Kind() == reflect.Struct && dt.Kind() == reflect.Struct && !isLeafType(st) && !isLeafType(dt):
+		return copyStruct(dst, src, nil, nil, path)
+	case st.Kind() == reflect.Slice && dt.Kind() == reflect.Slice:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dt))
+			return nil
+		}
+		out := reflect.MakeSlice(dt, src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := copyValue(out.Index(i), src.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+		return nil
+	case st.Kind() == reflect.Map && dt.Kind() == reflect.Map && st.Key().Kind() == reflect.String && dt.Key().Kind() == reflect.String:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dt))
+			return nil
+		}
+		out := reflect.MakeMapWithSize(dt, src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			v := reflect.New(dt.Elem()).Elem()
+			if err := copyValue(v, iter.Value(), path+"."+iter.Key().String()); err != nil {
+				return err
+			}
+			out.SetMapIndex(iter.Key().Convert(dt.Key()), v)
+		}
+		dst.Set(out)
+		return nil
+	}
+	return fmt.Errorf("goodm: copy %s: cannot convert %s to %s", path, st, dt)
+}
+
+// isNumericKind reports whether k is an integer or floating-point kind.
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testUserRole string
+
+type testUserDTO struct {
+	ID        string       `json:"id"`
+	Email     string       `json:"email"`
+	FullName  string       `json:"name"`
+	Age       *int64       `json:"age"`
+	Role      testUserRole `json:"role"`
+	Profile   string       `json:"profile"`
+	CreatedAt time.Time    `json:"created_at"`
+	Extra     string       `json:"extra"`
+}
+
+type testAddressDTO struct {
+	City string `json:"city"`
+}
+
+type testOrderItemDTO struct {
+	Name string `json:"name"`
+	Qty  int    `json:"quantity"`
+}
+
+type testOrderDTO struct {
+	Name    string             `json:"name"`
+	Address *testAddressDTO    `json:"address"`
+	Items   []testOrderItemDTO `json:"items"`
+}
+
+func TestCopyTo(t *testing.T) {
+	useTestStore(t)
+	id, profile := bson.NewObjectID(), bson.NewObjectID()
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	user := &testUser{Email: "a@b.c", Name: "Ann", Age: 30, Role: "admin", ProfileID: profile}
+	user.ID, user.CreatedAt = id, created
+
+	dto := testUserDTO{Extra: "kept"}
+	if err := CopyTo(user, &dto); err != nil {
+		t.Fatalf("CopyTo: %v", err)
+	}
+	if dto.ID != id.Hex() || dto.Profile != profile.Hex() {
+		t.Errorf("ObjectIDs not copied as hex: %+v", dto)
+	}
+	if dto.Email != "a@b.c" || dto.FullName != "Ann" || dto.Role != "admin" || !dto.CreatedAt.Equal(created) {
+		t.Errorf("unexpected dto: %+v", dto)
+	}
+	if dto.Age == nil || *dto.Age != 30 {
+		t.Errorf("Age = %v, want 30", dto.Age)
+	}
+	if dto.Extra != "kept" {
+		t.Errorf("unmatched field changed: %q", dto.Extra)
+	}
+}
+
+func TestCopyTo_Nested(t *testing.T) {
+	useTestStore(t)
+	order := &testOrder{
+		Name:    "o1",
+		Address: testAddress{Street: "Main", City: "Oslo"},
+		Items:   []testOrderItem{{Name: "pen", Quantity: 2}},
+	}
+
+	var dto testOrderDTO
+	if err := CopyTo(order, &dto); err != nil {
+		t.Fatalf("CopyTo: %v", err)
+	}
+	if dto.Address == nil || dto.Address.City != "Oslo" {
+		t.Errorf("Address = %+v", dto.Address)
+	}
+	if len(dto.Items) != 1 || dto.Items[0].Name != "pen" || dto.Items[0].Qty != 2 {
+		t.Errorf("Items = %+v", dto.Items)
+	}
+}
+
+func TestCopyTo_Exclusions(t *testing.T) {
+	useTestStore(t)
+	type accountDTO struct {
+		Username     string `json:"username"`
+		PasswordHash string `json:"password_hash"`
+	}
+	account := &testAccount{Username: "ann", PasswordHash: "secret"}
+
+	var dto accountDTO
+	if err := CopyTo(account, &dto); err != nil {
+		t.Fatalf("CopyTo: %v", err)
+	}
+	if dto.PasswordHash != "" {
+		t.Error("hidden field was copied to the DTO")
+	}
+
+	user := &testUser{Email: "a@b.c", Name: "Ann"}
+	var udto testUserDTO
+	if err := CopyTo(user, &udto, CopyOptions{Exclude: []string{"email"}}); err != nil {
+		t.Fatalf("CopyTo: %v", err)
+	}
+	if udto.Email != "" || udto.FullName != "Ann" {
+		t.Errorf("Exclude not applied: %+v", udto)
+	}
+
+	err := CopyTo(user, &udto, CopyOptions{Exclude: []string{"emial"}})
+	if err == nil || !strings.Contains(err.Error(), `"emial"`) {
+		t.Errorf("expected an unknown field error, got %v", err)
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	useTestStore(t)
+	age := int64(41)
+	profile := bson.NewObjectID()
+	dto := testUserDTO{
+		ID:        bson.NewObjectID().Hex(),
+		Email:     "b@c.d",
+		FullName:  "Bob",
+		Age:       &age,
+		Role:      "user",
+		Profile:   profile.Hex(),
+		CreatedAt: time.Now(),
+	}
+
+	user := &testUser{Name: "Ann"}
+	if err := CopyFrom(dto, user); err != nil {
+		t.Fatalf("CopyFrom: %v", err)
+	}
+	if user.Email != "b@c.d" || user.Age != 41 || user.Role != "user" || user.ProfileID != profile {
+		t.Errorf("unexpected model: %+v", user)
+	}
+	if !user.ID.IsZero() || !user.CreatedAt.IsZero() {
+		t.Error("managed fields were copied from the DTO")
+	}
+	if user.Name != "Ann" {
+		t.Errorf("immutable field copied: Name = %q", user.Name)
+	}
+}
+
+func TestCopyFrom_Errors(t *testing.T) {
+	useTestStore(t)
+	if err := CopyFrom(testUserDTO{Profile: "nope"}, &testUser{}); err == nil || !strings.Contains(err.Error(), "Profile") {
+		t.Errorf("expected an ObjectID error naming the field, got %v", err)
+	}
+
+	type badDTO struct {
+		Age []string `json:"age"`
+	}
+	if err := CopyFrom(badDTO{Age: []string{"x"}}, &testUser{}); err == nil {
+		t.Error("expected a conversion error")
+	}
+
+	if err := CopyFrom(testUserDTO{}, testUser{}); err == nil {
+		t.Error("expected an error for a non-pointer model")
+	}
+}
@@ -9,13 +9,28 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
-// getCollection returns a *mongo.Collection for the schema, applying any
+// getCollection returns the collection for the schema, applying any
 // per-schema read/write concern or read preference configured via the
-// Configurable interface.
-func getCollection(db *mongo.Database, schema *Schema) *mongo.Collection {
+// Configurable interface, or the in-memory collection when db holds the test
+// store.
+func getCollection(db dbHandle, schema *Schema) collection {
+	return writeCollection(db, schema, nil)
+}
+
+// writeCollection returns the schema's collection like getCollection, with
+// wc, when set, replacing the schema's write concern. The in-memory test
+// store ignores write concerns.
+func writeCollection(db dbHandle, schema *Schema, wc *writeconcern.WriteConcern) collection {
+	if db.mem != nil {
+		return testStoreCollection(db.mem, schema)
+	}
 	opts := schema.CollOptions
+	if wc != nil {
+		opts.WriteConcern = wc
+	}
 	if opts.ReadPreference == nil && opts.ReadConcern == nil && opts.WriteConcern == nil {
 		return db.Collection(schema.Collection)
 	}
@@ -35,14 +50,66 @@ func getCollection(db *mongo.Database, schema *Schema) *mongo.Collection {
 // CreateOptions configures the Create operation.
 type CreateOptions struct {
 	DB *mongo.Database
+
+	// WriteConcern overrides the write concern of the model's
+	// CollectionOptions for this call, e.g. writeconcern.Majority() for a
+	// write that must survive a failover. It is ignored inside a
+	// transaction, which commits with its own write concern.
+	WriteConcern *writeconcern.WriteConcern
+
+	// Unordered makes CreateMany insert every model it can instead of
+	// stopping at the first one the server rejects. Create ignores it.
+	Unordered bool
+
+	// upsertFilter makes Create insert only if no document matches it; see
+	// Upsert.
+	upsertFilter interface{}
+
+	// prepared makes Create skip the steps that prepare the model (ID,
+	// timestamps, defaults, sequences, BeforeCreate, normalizers, slugs,
+	// validation, tree path), for an Upsert retrying an insert it already
+	// prepared.
+	prepared bool
 }
 
 // FindOptions configures Find, FindOne, and FindCursor operations.
 type FindOptions struct {
-	DB    *mongo.Database
-	Limit int64
-	Skip  int64
-	Sort  bson.D
+	DB            *mongo.Database
+	Limit         int64
+	Skip          int64
+	Sort          bson.D
+	IncludeHidden bool               // also return fields tagged select=false
+	Collation     *options.Collation // string comparison rules for the filter and sort
+
+	// Strict makes FindOne and Find fail with *UnknownFieldsError when a
+	// document has top-level fields the model does not declare.
+	Strict bool
+
+	// Populate fills related documents into the results of FindOne and
+	// Find: the bson names of ref fields with a populate field, or the Go
+	// field names of has relations. Each costs one batched $in query per
+	// level. A dotted path such as "author.profile" populates the fetched
+	// documents in turn, and a trailing "*" ("manager*") repeats the last
+	// step on each new level of documents.
+	Populate []string
+
+	// MaxPopulateDepth caps the levels a Populate path descends
+	// (DefaultMaxPopulateDepth if zero).
+	MaxPopulateDepth int
+}
+
+// DefaultMaxPopulateDepth is the number of levels a FindOptions.Populate path
+// may descend when FindOptions.MaxPopulateDepth is zero.
+const DefaultMaxPopulateDepth = 5
+
+// WithHidden returns FindOptions that include fields tagged
+// `goodm:"select=false"`, which are otherwise excluded from reads.
+//
+// Example:
+//
+//	goodm.FindOne(ctx, bson.D{{Key: "email", Value: email}}, &user, goodm.WithHidden())
+func WithHidden() FindOptions {
+	return FindOptions{IncludeHidden: true}
 }
 
 // UpdateOptions configures the Update operation.
@@ -50,6 +117,54 @@ type UpdateOptions struct {
 	DB         *mongo.Database
 	Unset      []string // bson field names to remove from the document
 	MaxRetries int      // retry with 3-way merge on version conflict (0 = no retry)
+
+	// PreserveUnknown keeps stored top-level fields that are not part of the
+	// model. Update replaces the whole document, so without it any unmodeled
+	// field is removed on save.
+	PreserveUnknown bool
+
+	// CheckVersion makes UpdateOne, UpdateFields, and PartialUpdate
+	// version-aware: the model's Version must match the stored __v, which is
+	// incremented on success. A mismatch returns ErrVersionConflict.
+	CheckVersion bool
+
+	// ArrayFilters, Upsert, and Collation are passed to the driver by
+	// UpdateOne and UpdateMany. ArrayFilters select the array elements an
+	// $[<identifier>] operator updates; Upsert inserts a document when none
+	// matches the filter.
+	ArrayFilters []interface{}
+	Upsert       bool
+	Collation    *options.Collation
+}
+
+// updateOneOptions returns the driver options for UpdateOne.
+func (o UpdateOptions) updateOneOptions() *options.UpdateOneOptionsBuilder {
+	uo := options.UpdateOne()
+	if o.ArrayFilters != nil {
+		uo.SetArrayFilters(o.ArrayFilters)
+	}
+	if o.Upsert {
+		uo.SetUpsert(true)
+	}
+	if o.Collation != nil {
+		uo.SetCollation(o.Collation)
+	}
+	return uo
+}
+
+// updateManyOptions returns the driver options for UpdateMany.
+func (o UpdateOptions) updateManyOptions() *options.UpdateManyOptionsBuilder {
+	uo := options.UpdateMany()
+	if o.ArrayFilters != nil {
+		uo.SetArrayFilters(o.ArrayFilters)
+	}
+	if o.Upsert {
+		uo.SetUpsert(true)
+	}
+	if o.Collation != nil {
+		uo.SetCollation(o.Collation)
+	}
+	return uo
 }
 
 // UnsetFields returns UpdateOptions that will remove the specified fields from
@@ -66,7 +181,10 @@ func UnsetFields(fields ...string) UpdateOptions {
 // conflict using a 3-way field-level merge. On conflict, the document is re-read
 // from the database, and the caller's changed fields are merged onto the fresh
 // state — but only if no other writer modified the same fields. If both sides
-// changed the same field, a *MergeConflictError is returned.
+// changed the same field, a *MergeConflictError is returned. Changes are
+// measured against the model as FindOne or Find loaded it or as it was last
+// saved; a model goodm has not loaded or saved cannot be merged and returns
+// ErrVersionConflict.
 //
 // Example:
 //
@@ -75,9 +193,35 @@ func WithRetry(maxRetries int) UpdateOptions {
 	return UpdateOptions{MaxRetries: maxRetries}
 }
 
+// PreserveUnknownFields returns UpdateOptions that keep fields stored in the
+// document but absent from the model struct, such as fields written by another
+// service or removed from the struct during a migration.
+//
+// Example:
+//
+//	goodm.Update(ctx, &user, goodm.PreserveUnknownFields())
+func PreserveUnknownFields() UpdateOptions {
+	return UpdateOptions{PreserveUnknown: true}
+}
+
+// WithVersionCheck returns UpdateOptions that make UpdateOne, UpdateFields,
+// and PartialUpdate honor optimistic concurrency like Update does. The expected version is read
+// from the model, so pass the loaded document rather than an empty one.
+//
+// Example:
+//
+//	goodm.UpdateFields(ctx, &task, bson.M{"step": 5}, goodm.WithVersionCheck())
+func WithVersionCheck() UpdateOptions {
+	return UpdateOptions{CheckVersion: true}
+}
+
 // DeleteOptions configures the Delete operation.
 type DeleteOptions struct {
 	DB *mongo.Database
+
+	// WriteConcern overrides the write concern of the model's
+	// CollectionOptions for this call. It is ignored inside a transaction.
+	WriteConcern *writeconcern.WriteConcern
 }
 
 // Create inserts a new document. It generates an ID if zero, sets timestamps,
@@ -92,52 +236,43 @@ func Create(ctx context.Context, model interface{}, opts ...CreateOptions) error
 		Operation: OpCreate, Collection: schema.Collection,
 		ModelName: schema.ModelName, Model: model,
 	}, func(ctx context.Context) error {
-		var optDB *mongo.Database
+		var opt CreateOptions
 		if len(opts) > 0 {
-			optDB = opts[0].DB
+			opt = opts[0]
 		}
-		db, err := getDB(optDB)
+		db, err := getDB(opt.DB)
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
 
-		// Set ID if zero
-		id, err := getModelID(model)
-		if err != nil {
-			return err
-		}
-		if id.IsZero() {
-			setModelID(model, bson.NewObjectID())
+		coll := writeCollection(db, schema, opt.WriteConcern)
+		if !opt.prepared {
+			if err := prepareCreate(ctx, db, coll, model, schema); err != nil {
+				return err
+			}
 		}
 
-		// Set timestamps
-		setTimestamps(model, time.Now())
-
-		// Apply schema defaults to zero-valued fields
-		if err := applyDefaults(model, schema); err != nil {
+		// Insert
+		doc, err := insertDocument(model, schema)
+		if err != nil {
 			return err
 		}
-
-		// Initialize version to 0
-		setModelVersion(model, 0)
-
-		// BeforeCreate hook
-		if hook, ok := model.(BeforeCreate); ok {
-			if err := hook.BeforeCreate(ctx); err != nil {
+		if opt.upsertFilter != nil {
+			err = insertIfNone(ctx, coll, scopeFilter(ctx, schema, opt.upsertFilter), doc)
+		} else {
+			_, err = coll.InsertOne(ctx, doc, withComment(ctx, options.InsertOne()))
+		}
+		if err != nil {
+			if err == errUpsertMatched {
 				return err
 			}
-		}
-
-		// Validate
-		if errs := Validate(model, schema); len(errs) > 0 {
-			return ValidationErrors(errs)
-		}
-
-		// Insert
-		coll := getCollection(db, schema)
-		if _, err := coll.InsertOne(ctx, model); err != nil {
+			if verrs := uniqueWithErrors(schema, err); verrs != nil {
+				return verrs
+			}
 			return fmt.Errorf("goodm: insert failed: %w", err)
 		}
+		track(model)
 
 		// AfterCreate hook
 		if hook, ok := model.(AfterCreate); ok {
@@ -150,6 +285,69 @@ func Create(ctx context.Context, model interface{}, opts ...CreateOptions) error
 	})
 }
 
+// prepareCreate readies model for insertion: it sets the ID, timestamps,
+// defaults, sequences, and discriminator, runs BeforeCreate, normalizes,
+// generates slugs, validates, and derives the tree path.
+func prepareCreate(ctx context.Context, db dbHandle, coll collection, model interface{}, schema *Schema) error {
+	// Set ID if zero
+	id, err := getModelID(model)
+	if err != nil {
+		return err
+	}
+	if id.IsZero() {
+		setModelID(model, bson.NewObjectID())
+	}
+
+	// Set timestamps
+	setTimestamps(model, clockNow())
+	stampActor(ctx, model, true)
+
+	// Apply schema defaults to zero-valued fields
+	if err := applyDefaults(model, schema); err != nil {
+		return err
+	}
+
+	// Assign autoincrement values
+	if err := setSequences(ctx, db, model, schema, nil, 1); err != nil {
+		return err
+	}
+
+	// Initialize version to 0
+	setModelVersion(model, 0)
+
+	// Stamp the discriminator for polymorphic collections
+	setDiscriminator(model, schema)
+
+	// BeforeCreate hook
+	if hook, ok := model.(BeforeCreate); ok {
+		if err := hook.BeforeCreate(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Normalize
+	if err := applyNormalizers(model, schema); err != nil {
+		return err
+	}
+
+	// Generate slugs
+	if err := setSlugs(ctx, coll, model, schema, nil); err != nil {
+		return err
+	}
+
+	// Validate
+	if errs := Validate(model, schema); len(errs) > 0 {
+		return ValidationErrors(errs)
+	}
+
+	// Derive the materialized tree path from the parent
+	if _, err := setTreePath(ctx, coll, model, schema, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // FindOne finds a single document matching filter and decodes it into result.
 // Returns ErrNotFound if no document matches.
 func FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...FindOptions) error {
@@ -158,29 +356,65 @@ func FindOne(ctx context.Context, filter interface{}, result interface{}, opts .
 		return err
 	}
 
-	return runMiddleware(ctx, &OpInfo{
+	var opt FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+
+	err = runMiddleware(ctx, &OpInfo{
 		Operation: OpFind, Collection: schema.Collection,
 		ModelName: schema.ModelName, Model: result, Filter: filter,
+		Result: result, Options: opt,
+		filterable: true,
 	}, func(ctx context.Context) error {
-		var optDB *mongo.Database
-		if len(opts) > 0 {
-			optDB = opts[0].DB
-		}
-		db, err := getDB(optDB)
+		db, err := getDB(opt.DB)
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
+
+		findOneOpts := withComment(ctx, options.FindOne())
+		if opt.Collation != nil {
+			findOneOpts.SetCollation(opt.Collation)
+		}
+		if proj := hiddenProjection(schema, opt.IncludeHidden); proj != nil {
+			findOneOpts.SetProjection(proj)
+		}
 
 		coll := getCollection(db, schema)
-		if err := coll.FindOne(ctx, filter).Decode(result); err != nil {
+		res := coll.FindOne(ctx, scopeFilter(ctx, schema, filter), findOneOpts)
+		if check := unknownFieldsCheck(ctx, schema, opt.Strict); check != nil {
+			if raw, err := res.Raw(); err == nil {
+				if err := check(raw); err != nil {
+					return err
+				}
+			}
+		}
+		if err := res.Decode(result); err != nil {
 			if err == mongo.ErrNoDocuments {
 				return ErrNotFound
 			}
 			return fmt.Errorf("goodm: find one failed: %w", err)
 		}
+		if fallback := renameFallbackFunc(schema); fallback != nil {
+			if raw, err := res.Raw(); err == nil {
+				if err := fallback(raw, result); err != nil {
+					return err
+				}
+			}
+		}
 
-		return nil
+		return afterLoad(ctx, result)
 	})
+	if err != nil || len(opt.Populate) == 0 {
+		return err
+	}
+	return populateFound(ctx, schema, result, opt)
 }
 
 // Find finds all documents matching filter and decodes them into results.
@@ -199,20 +433,29 @@ func Find(ctx context.Context, filter interface{}, results interface{}, opts ...
 		return err
 	}
 
-	return runMiddleware(ctx, &OpInfo{
+	var opt FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+
+	err = runMiddleware(ctx, &OpInfo{
 		Operation: OpFind, Collection: schema.Collection,
 		ModelName: schema.ModelName, Filter: filter,
+		Result: results, Options: opt,
+		filterable: true,
 	}, func(ctx context.Context) error {
-		var opt FindOptions
-		if len(opts) > 0 {
-			opt = opts[0]
-		}
 		db, err := getDB(opt.DB)
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
 
-		findOpts := options.Find()
+		findOpts := withComment(ctx, options.Find())
 		if opt.Limit > 0 {
 			findOpts.SetLimit(opt.Limit)
 		}
@@ -222,20 +465,45 @@ func Find(ctx context.Context, filter interface{}, results interface{}, opts ...
 		if opt.Sort != nil {
 			findOpts.SetSort(opt.Sort)
 		}
+		if opt.Collation != nil {
+			findOpts.SetCollation(opt.Collation)
+		}
+		if proj := hiddenProjection(schema, opt.IncludeHidden); proj != nil {
+			findOpts.SetProjection(proj)
+		}
 
 		coll := getCollection(db, schema)
-		cursor, err := coll.Find(ctx, filter, findOpts)
+		cursor, err := coll.Find(ctx, scopeFilter(ctx, schema, filter), findOpts)
 		if err != nil {
 			return fmt.Errorf("goodm: find failed: %w", err)
 		}
 		defer func() { _ = cursor.Close(ctx) }()
 
-		if err := cursor.All(ctx, results); err != nil {
+		check := unknownFieldsCheck(ctx, schema, opt.Strict)
+		if fallback := renameFallbackFunc(schema); check != nil || fallback != nil {
+			if err := decodeAllChecked(ctx, cursor, results, check, fallback); err != nil {
+				return err
+			}
+		} else if err := cursor.All(ctx, results); err != nil {
 			return fmt.Errorf("goodm: cursor decode failed: %w", err)
 		}
 
-		return nil
+		return afterLoad(ctx, results)
 	})
+	if err != nil || len(opt.Populate) == 0 {
+		return err
+	}
+	return populateFound(ctx, schema, results, opt)
+}
+
+// populateFound runs FindOptions.Populate for FindOne and Find. It runs after
+// the middleware chain, so results served from a cache are populated too.
+func populateFound(ctx context.Context, schema *Schema, results interface{}, opt FindOptions) error {
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return err
+	}
+	return populateResults(ctx, db, schema, results, opt.Populate, opt.MaxPopulateDepth)
 }
 
 // FindCursor returns a raw *mongo.Cursor for streaming large result sets.
@@ -246,10 +514,16 @@ func FindCursor(ctx context.Context, filter interface{}, model interface{}, opts
 		return nil, err
 	}
 
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return nil, err
+	}
+
 	var cursor *mongo.Cursor
 	err = runMiddleware(ctx, &OpInfo{
 		Operation: OpFind, Collection: schema.Collection,
 		ModelName: schema.ModelName, Model: model, Filter: filter,
+		filterable: true,
 	}, func(ctx context.Context) error {
 		var opt FindOptions
 		if len(opts) > 0 {
@@ -259,8 +533,9 @@ func FindCursor(ctx context.Context, filter interface{}, model interface{}, opts
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
 
-		findOpts := options.Find()
+		findOpts := withComment(ctx, options.Find())
 		if opt.Limit > 0 {
 			findOpts.SetLimit(opt.Limit)
 		}
@@ -270,9 +545,15 @@ func FindCursor(ctx context.Context, filter interface{}, model interface{}, opts
 		if opt.Sort != nil {
 			findOpts.SetSort(opt.Sort)
 		}
+		if opt.Collation != nil {
+			findOpts.SetCollation(opt.Collation)
+		}
+		if proj := hiddenProjection(schema, opt.IncludeHidden); proj != nil {
+			findOpts.SetProjection(proj)
+		}
 
 		coll := getCollection(db, schema)
-		c, err := coll.Find(ctx, filter, findOpts)
+		c, err := coll.Find(ctx, scopeFilter(ctx, schema, filter), findOpts)
 		if err != nil {
 			return fmt.Errorf("goodm: find cursor failed: %w", err)
 		}
@@ -284,7 +565,9 @@ func FindCursor(ctx context.Context, filter interface{}, model interface{}, opts
 }
 
 // Update replaces an existing document. It fetches the current document to enforce
-// immutable fields, runs BeforeSave/AfterSave hooks, validates, and sets UpdatedAt.
+// immutable fields and enum transitions, runs BeforeSave/AfterSave hooks,
+// validates, and sets UpdatedAt. A disallowed transition returns a
+// *TransitionError matching ErrInvalidTransition.
 func Update(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
 	schema, err := getSchemaForModel(model)
 	if err != nil {
@@ -312,18 +595,23 @@ func Update(ctx context.Context, model interface{}, opts ...UpdateOptions) error
 	return runMiddleware(ctx, &OpInfo{
 		Operation: OpUpdate, Collection: schema.Collection,
 		ModelName: schema.ModelName, Model: model,
-		Filter: bson.D{{Key: "_id", Value: id}},
+		Filter: bson.D{{Key: "_id", Value: id}}, filterable: true,
 	}, func(ctx context.Context) error {
 		db, err := getDB(opt.DB)
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
 
 		coll := getCollection(db, schema)
 
+		if err := checkPolicyScope(ctx, coll, schema, id, OpUpdate); err != nil {
+			return err
+		}
 		if err := checkImmutableFields(ctx, coll, id, model, schema); err != nil {
 			return err
 		}
+		stampActor(ctx, model, false)
 
 		// BeforeSave hook
 		if hook, ok := model.(BeforeSave); ok {
@@ -332,15 +620,30 @@ func Update(ctx context.Context, model interface{}, opts ...UpdateOptions) error
 			}
 		}
 
+		// Normalize
+		if err := applyNormalizers(model, schema); err != nil {
+			return err
+		}
+
 		// Validate
 		if errs := Validate(model, schema); len(errs) > 0 {
 			return ValidationErrors(errs)
 		}
 
+		// Carry over stored fields the model does not hold (hidden or unknown).
+		carry, err := loadCarry(ctx, coll, id, model, schema, opt)
+		if err != nil {
+			return err
+		}
+
 		// Save with optional retry-with-merge on version conflict.
-		if err := saveWithRetry(ctx, coll, model, opt, id); err != nil {
+		if err := saveWithRetry(ctx, coll, model, schema, opt, carry, id); err != nil {
+			if verrs := uniqueWithErrors(schema, err); verrs != nil {
+				return verrs
+			}
 			return err
 		}
+		track(model)
 
 		// AfterSave hook
 		if hook, ok := model.(AfterSave); ok {
@@ -353,46 +656,120 @@ func Update(ctx context.Context, model interface{}, opts ...UpdateOptions) error
 	})
 }
 
-// checkImmutableFields verifies that immutable fields have not been modified.
-// Skips the check entirely if no fields are marked immutable.
-func checkImmutableFields(ctx context.Context, coll *mongo.Collection, id bson.ObjectID, model interface{}, schema *Schema) error {
-	if !hasImmutableFields(schema) {
+// Save creates model when its ID is zero and updates it otherwise, with the
+// hooks, validation, and versioning of Create or Update. opts apply to the
+// update; a create uses only their DB.
+//
+// Example:
+//
+//	user := &User{Email: "alice@example.com", Name: "Alice"}
+//	err := goodm.Save(ctx, user) // creates
+//	user.Age = 31
+//	err = goodm.Save(ctx, user) // updates
+func Save(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
+	id, err := getModelID(model)
+	if err != nil {
+		return err
+	}
+	if id.IsZero() {
+		var opt UpdateOptions
+		if len(opts) > 0 {
+			opt = opts[0]
+		}
+		return Create(ctx, model, CreateOptions{DB: opt.DB})
+	}
+	return Update(ctx, model, opts...)
+}
+
+// checkImmutableFields verifies that immutable and write-once fields have not been
+// modified and that enum fields only made allowed transitions. Skips the check
+// entirely if no fields carry these tags.
+func checkImmutableFields(ctx context.Context, coll collection, id bson.ObjectID, model interface{}, schema *Schema) error {
+	if !hasImmutableFields(schema) && !hasTransitions(schema) {
 		return nil
 	}
 	existing := reflect.New(reflect.TypeOf(model).Elem()).Interface()
-	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(existing); err != nil {
+	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}}, withComment(ctx, options.FindOne())).Decode(existing); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return ErrNotFound
 		}
 		return fmt.Errorf("goodm: failed to fetch existing document: %w", err)
 	}
+	// Hidden fields the model does not hold are carried over unchanged, so
+	// compare them as equal rather than as cleared.
+	ev := reflect.ValueOf(existing).Elem()
+	for _, f := range unloadedHidden(model, schema) {
+		fv := ev.FieldByName(f.Name)
+		fv.Set(reflect.Zero(fv.Type()))
+	}
 	if immutableErrs := validateImmutable(existing, model, schema); len(immutableErrs) > 0 {
 		return ValidationErrors(immutableErrs)
 	}
-	return nil
+	return validateTransitions(existing, model, schema)
 }
 
-// buildVersionFilter constructs a filter with optimistic concurrency version checking.
-// When oldVersion == 0, also matches documents without __v (legacy compat).
-func buildVersionFilter(id bson.ObjectID, oldVersion int) bson.D {
-	if oldVersion == 0 {
-		return bson.D{
-			{Key: "_id", Value: id},
-			{Key: "$or", Value: bson.A{
-				bson.D{{Key: "__v", Value: 0}},
-				bson.D{{Key: "__v", Value: bson.D{{Key: "$exists", Value: false}}}},
-			}},
-		}
+// buildVersionFilter constructs a filter with optimistic concurrency version checking
+// on the version key key. When oldVersion == 0, also matches documents without
+// the key (legacy compat).
+func buildVersionFilter(id bson.ObjectID, key string, oldVersion int) bson.D {
+	return append(bson.D{{Key: "_id", Value: id}}, versionClause(key, oldVersion)...)
+}
+
+// versionClause matches documents whose version key is at the given version.
+// Version 0 also matches documents written before versioning, which have no
+// version field.
+func versionClause(key string, version int) bson.D {
+	if version == 0 {
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: key, Value: 0}},
+			bson.D{{Key: key, Value: bson.D{{Key: "$exists", Value: false}}}},
+		}}}
 	}
-	return bson.D{
-		{Key: "_id", Value: id},
-		{Key: "__v", Value: oldVersion},
+	return bson.D{{Key: key, Value: version}}
+}
+
+// withVersionClause narrows filter to documents at the given version.
+func withVersionClause(filter interface{}, key string, version int) interface{} {
+	if filter == nil {
+		return versionClause(key, version)
 	}
+	return bson.D{{Key: "$and", Value: bson.A{filter, versionClause(key, version)}}}
+}
+
+// addVersionInc adds {$inc: {<key>: 1}} to an update document, merging with an
+// existing $inc. Pipeline updates are not supported.
+func addVersionInc(update interface{}, key string) (bson.D, error) {
+	raw, err := marshalBSON(update)
+	if err != nil {
+		return nil, fmt.Errorf("goodm: versioned update must be a document: %w", err)
+	}
+	var doc bson.D
+	if err := unmarshalBSON(raw, &doc); err != nil {
+		return nil, fmt.Errorf("goodm: versioned update must be a document: %w", err)
+	}
+
+	for i, e := range doc {
+		if e.Key != "$inc" {
+			continue
+		}
+		inc, ok := e.Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("goodm: $inc must be a document")
+		}
+		for _, ie := range inc {
+			if ie.Key == key {
+				return nil, fmt.Errorf("goodm: versioned update must not modify %s", key)
+			}
+		}
+		doc[i].Value = append(inc, bson.E{Key: key, Value: 1})
+		return doc, nil
+	}
+	return append(doc, bson.E{Key: "$inc", Value: bson.D{{Key: key, Value: 1}}}), nil
 }
 
 // checkUpdateConflict disambiguates between a missing document and a version conflict
 // when an update matched zero documents.
-func checkUpdateConflict(ctx context.Context, coll *mongo.Collection, id bson.ObjectID) error {
+func checkUpdateConflict(ctx context.Context, coll collection, id bson.ObjectID) error {
 	count, err := coll.CountDocuments(ctx, bson.D{{Key: "_id", Value: id}})
 	if err != nil {
 		return fmt.Errorf("goodm: update failed: %w", err)
@@ -405,7 +782,11 @@ func checkUpdateConflict(ctx context.Context, coll *mongo.Collection, id bson.Ob
 
 // UpdateFields performs a partial $set update on specific fields of a document,
 // identified by the model's ID. It runs middleware, sets UpdatedAt, and increments
-// the version — but does NOT enforce optimistic locking (last-write-wins).
+// the version — but does NOT enforce optimistic locking (last-write-wins) unless
+// WithVersionCheck is passed.
+//
+// As in Create and Update, nil values and zero values of omitempty fields are
+// removed from the document ($unset) rather than stored.
 //
 // Use this instead of Update when concurrent writers touch disjoint fields and
 // version conflicts are acceptable (e.g. progress tracking, heartbeats).
@@ -430,52 +811,95 @@ func UpdateFields(ctx context.Context, model interface{}, fields bson.M, opts ..
 	if err := validateUpdateFieldNames(schema, fields); err != nil {
 		return err
 	}
+	if err := normalizeUpdates(schema, fields); err != nil {
+		return err
+	}
 
 	return runMiddleware(ctx, &OpInfo{
 		Operation: OpUpdate, Collection: schema.Collection,
 		ModelName: schema.ModelName, Model: model,
-		Filter: bson.D{{Key: "_id", Value: id}},
+		Filter: bson.D{{Key: "_id", Value: id}}, filterable: true,
 	}, func(ctx context.Context) error {
-		var optDB *mongo.Database
+		var opt UpdateOptions
 		if len(opts) > 0 {
-			optDB = opts[0].DB
+			opt = opts[0]
 		}
-		db, err := getDB(optDB)
+		db, err := getDB(opt.DB)
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
+		if err := checkPolicyScope(ctx, getCollection(db, schema), schema, id, OpUpdate); err != nil {
+			return err
+		}
 
-		// Add updated_at and increment version
-		fields["updated_at"] = time.Now()
+		// Add updated_at and updated_by, and increment version
+		updatedAt := schema.base().updatedAt
+		fields[updatedAt] = clockNow()
+		if name, actor, ok := actorField(ctx, model); ok {
+			fields[name] = actor
+		}
 		oldVersion, _ := getModelVersion(model)
 		newVersion := oldVersion + 1
 
+		filter := bson.D{{Key: "_id", Value: id}}
+		if opt.CheckVersion {
+			filter = buildVersionFilter(id, schema.base().version, oldVersion)
+		}
+
 		coll := getCollection(db, schema)
-		result, err := coll.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{
-			{Key: "$set", Value: fields},
-			{Key: "$inc", Value: bson.D{{Key: "__v", Value: 1}}},
-		})
+		result, err := coll.UpdateOne(ctx, filter, fieldsUpdate(schema, fields), withComment(ctx, options.UpdateOne()))
 		if err != nil {
+			if verrs := uniqueWithErrors(schema, err); verrs != nil {
+				return verrs
+			}
 			return fmt.Errorf("goodm: update fields failed: %w", err)
 		}
 		if result.MatchedCount == 0 {
+			if opt.CheckVersion {
+				return checkUpdateConflict(ctx, coll, id)
+			}
 			return ErrNotFound
 		}
 
 		// Reflect the changes back onto the struct
-		setUpdatedAt(model, fields["updated_at"].(time.Time))
+		setUpdatedAt(model, fields[updatedAt].(time.Time))
+		stampActor(ctx, model, false)
 		setModelVersion(model, newVersion)
 		applyFieldsToModel(model, fields)
+		written := []string{schema.base().version}
+		for name := range fields {
+			written = append(written, name)
+		}
+		trackWritten(model, written)
 
 		return nil
 	})
 }
 
+// fieldsUpdate builds the UpdateFields update document: a version increment,
+// $set for the non-empty fields, and $unset for the empty ones. Operators
+// with no fields are left out, since servers before MongoDB 5.0 reject an
+// empty $set or $unset.
+func fieldsUpdate(schema *Schema, fields bson.M) bson.D {
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: schema.base().version, Value: 1}}}}
+	set, unset := splitEmptyFields(schema, fields)
+	unset = shadowUpdate(schema, set, unset)
+	unset = renameUpdate(schema, set, unset)
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	if len(unset) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unset})
+	}
+	return update
+}
+
 // validateUpdateFieldNames checks that all field names in the map are known schema
 // fields and not managed by the ODM.
 func validateUpdateFieldNames(schema *Schema, fields bson.M) error {
 	for name := range fields {
-		if managedFields[name] {
+		if schema.base().managed(name) {
 			return fmt.Errorf("goodm: cannot set managed field %q via UpdateFields", name)
 		}
 		if !schema.HasField(name) {
@@ -505,7 +929,9 @@ func applyFieldsToModel(model interface{}, fields bson.M) {
 			continue
 		}
 		fv := v.Field(i)
-		if fv.CanSet() {
+		if fv.CanSet() && val == nil {
+			fv.Set(reflect.Zero(fv.Type()))
+		} else if fv.CanSet() {
 			rv := reflect.ValueOf(val)
 			if rv.Type().AssignableTo(fv.Type()) {
 				fv.Set(rv)
@@ -514,6 +940,76 @@ func applyFieldsToModel(model interface{}, fields bson.M) {
 	}
 }
 
+// Touch sets UpdatedAt to now and increments the version of a stored document
+// without changing any other field, e.g. to mark it as recently active or to
+// move it to the end of an updated_at ordering. It runs middleware but no
+// hooks or validation. Like UpdateFields it is last-write-wins unless
+// WithVersionCheck is passed.
+//
+//	err := goodm.Touch(ctx, &session)
+func Touch(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+
+	id, err := getModelID(model)
+	if err != nil {
+		return err
+	}
+	if id.IsZero() {
+		return fmt.Errorf("goodm: cannot touch document with zero ID")
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpUpdate, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: model,
+		Filter: bson.D{{Key: "_id", Value: id}}, filterable: true,
+	}, func(ctx context.Context) error {
+		var opt UpdateOptions
+		if len(opts) > 0 {
+			opt = opts[0]
+		}
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		if err := checkPolicyScope(ctx, getCollection(db, schema), schema, id, OpUpdate); err != nil {
+			return err
+		}
+
+		now := clockNow()
+		oldVersion, _ := getModelVersion(model)
+
+		filter := bson.D{{Key: "_id", Value: id}}
+		if opt.CheckVersion {
+			filter = buildVersionFilter(id, schema.base().version, oldVersion)
+		}
+
+		coll := getCollection(db, schema)
+		result, err := coll.UpdateOne(ctx, filter, bson.D{
+			{Key: "$set", Value: bson.D{{Key: schema.base().updatedAt, Value: now}}},
+			{Key: "$inc", Value: bson.D{{Key: schema.base().version, Value: 1}}},
+		}, withComment(ctx, options.UpdateOne()))
+		if err != nil {
+			return fmt.Errorf("goodm: touch failed: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			if opt.CheckVersion {
+				return checkUpdateConflict(ctx, coll, id)
+			}
+			return ErrNotFound
+		}
+
+		setUpdatedAt(model, now)
+		setModelVersion(model, oldVersion+1)
+		trackWritten(model, []string{schema.base().updatedAt, schema.base().version})
+
+		return nil
+	})
+}
+
 // UpdateOne performs a partial update on a single document matching filter.
 // The model parameter is used only for schema/collection lookup (e.g. &User{}).
 // The update parameter should be a MongoDB update document (e.g. bson.D{{"$set", bson.D{...}}}).
@@ -522,41 +1018,98 @@ func applyFieldsToModel(model interface{}, fields bson.M) {
 // hooks, validation, and immutable field enforcement. Use Update for the full
 // ODM lifecycle, or use this when you need raw performance and accept responsibility
 // for data integrity.
+//
+// With WithVersionCheck, the update only applies while the document's __v
+// matches the model's Version, and __v is incremented. Pass the loaded model
+// rather than an empty one; its Version is advanced on success.
+//
+// UpdateOptions.ArrayFilters, Upsert, and Collation are passed to the driver.
+// With Upsert, no ErrNotFound is returned when a document is inserted.
 func UpdateOne(ctx context.Context, filter interface{}, update interface{}, model interface{}, opts ...UpdateOptions) error {
 	schema, err := getSchemaForModel(model)
 	if err != nil {
 		return err
 	}
 
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+
 	return runMiddleware(ctx, &OpInfo{
 		Operation: OpUpdate, Collection: schema.Collection,
 		ModelName: schema.ModelName, Model: model, Filter: filter,
+		filterable: true,
 	}, func(ctx context.Context) error {
-		var optDB *mongo.Database
+		var opt UpdateOptions
 		if len(opts) > 0 {
-			optDB = opts[0].DB
+			opt = opts[0]
 		}
-		db, err := getDB(optDB)
+		db, err := getDB(opt.DB)
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
 
 		coll := getCollection(db, schema)
-		result, err := coll.UpdateOne(ctx, filter, update)
+		scoped := scopeFilter(ctx, schema, filter)
+		if !opt.CheckVersion {
+			result, err := coll.UpdateOne(ctx, scoped, update, withComment(ctx, opt.updateOneOptions()))
+			if err != nil {
+				return fmt.Errorf("goodm: update one failed: %w", err)
+			}
+			if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+				return ErrNotFound
+			}
+			return nil
+		}
+		if opt.Upsert {
+			return fmt.Errorf("goodm: update one: Upsert cannot be combined with CheckVersion")
+		}
+
+		oldVersion, err := getModelVersion(model)
+		if err != nil {
+			return err
+		}
+		versioned, err := addVersionInc(update, schema.base().version)
+		if err != nil {
+			return err
+		}
+		result, err := coll.UpdateOne(ctx, withVersionClause(scoped, schema.base().version, oldVersion), versioned, withComment(ctx, opt.updateOneOptions()))
 		if err != nil {
 			return fmt.Errorf("goodm: update one failed: %w", err)
 		}
 		if result.MatchedCount == 0 {
-			return ErrNotFound
+			count, err := coll.CountDocuments(ctx, scoped)
+			if err != nil {
+				return fmt.Errorf("goodm: update one failed: %w", err)
+			}
+			if count == 0 {
+				return ErrNotFound
+			}
+			return ErrVersionConflict
 		}
+		setModelVersion(model, oldVersion+1)
 
 		return nil
 	})
 }
 
 // Delete removes a document by its ID.
-// Runs BeforeDelete/AfterDelete hooks.
+// Runs BeforeDelete/AfterDelete hooks and applies the ondelete rules of the
+// model's has_one/has_many relations. Related documents are removed or
+// updated without their hooks; wrap Delete in WithTransaction to make the
+// cascade atomic.
+//
+// For models embedding SoftDeleteModel, Delete instead sets DeletedAt, with
+// the same hooks and no ondelete rules, and returns ErrNotFound if the
+// document is already deleted. Use HardDelete to remove it.
 func Delete(ctx context.Context, model interface{}, opts ...DeleteOptions) error {
+	return deleteModel(ctx, model, false, opts)
+}
+
+// deleteModel implements Delete, or HardDelete if hard is set.
+func deleteModel(ctx context.Context, model interface{}, hard bool, opts []DeleteOptions) error {
 	schema, err := getSchemaForModel(model)
 	if err != nil {
 		return err
@@ -573,16 +1126,20 @@ func Delete(ctx context.Context, model interface{}, opts ...DeleteOptions) error
 	return runMiddleware(ctx, &OpInfo{
 		Operation: OpDelete, Collection: schema.Collection,
 		ModelName: schema.ModelName, Model: model,
-		Filter: bson.D{{Key: "_id", Value: id}},
+		Filter: bson.D{{Key: "_id", Value: id}}, filterable: true,
 	}, func(ctx context.Context) error {
-		var optDB *mongo.Database
+		var opt DeleteOptions
 		if len(opts) > 0 {
-			optDB = opts[0].DB
+			opt = opts[0]
 		}
-		db, err := getDB(optDB)
+		db, err := getDB(opt.DB)
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
+		if err := checkPolicyScope(ctx, getCollection(db, schema), schema, id, OpDelete); err != nil {
+			return err
+		}
 
 		// BeforeDelete hook
 		if hook, ok := model.(BeforeDelete); ok {
@@ -591,13 +1148,26 @@ func Delete(ctx context.Context, model interface{}, opts ...DeleteOptions) error
 			}
 		}
 
-		coll := getCollection(db, schema)
-		result, err := coll.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
-		if err != nil {
-			return fmt.Errorf("goodm: delete failed: %w", err)
-		}
-		if result.DeletedCount == 0 {
-			return ErrNotFound
+		coll := writeCollection(db, schema, opt.WriteConcern)
+		if schema.SoftDelete != "" && !hard {
+			if err := softDelete(ctx, coll, schema, model, id); err != nil {
+				return err
+			}
+		} else {
+			ids := []bson.ObjectID{id}
+			if err := checkDeleteRestrict(ctx, db, schema.Collection, ids, map[bson.ObjectID]bool{id: true}); err != nil {
+				return err
+			}
+			result, err := coll.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}}, withComment(ctx, options.DeleteOne()))
+			if err != nil {
+				return fmt.Errorf("goodm: delete failed: %w", err)
+			}
+			if result.DeletedCount == 0 {
+				return ErrNotFound
+			}
+			if err := applyDeleteRules(ctx, db, schema.Collection, ids); err != nil {
+				return err
+			}
 		}
 
 		// AfterDelete hook
@@ -617,27 +1187,45 @@ func Delete(ctx context.Context, model interface{}, opts ...DeleteOptions) error
 // Performance: This is a direct passthrough to MongoDB's DeleteOne. It bypasses
 // hooks entirely. Use Delete for the full ODM lifecycle with BeforeDelete/AfterDelete
 // hooks, or use this when you need raw performance and don't require hook execution.
+// For models embedding SoftDeleteModel, the document is marked deleted instead.
 func DeleteOne(ctx context.Context, filter interface{}, model interface{}, opts ...DeleteOptions) error {
 	schema, err := getSchemaForModel(model)
 	if err != nil {
 		return err
 	}
 
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+
 	return runMiddleware(ctx, &OpInfo{
 		Operation: OpDelete, Collection: schema.Collection,
 		ModelName: schema.ModelName, Model: model, Filter: filter,
+		filterable: true,
 	}, func(ctx context.Context) error {
-		var optDB *mongo.Database
+		var opt DeleteOptions
 		if len(opts) > 0 {
-			optDB = opts[0].DB
+			opt = opts[0]
 		}
-		db, err := getDB(optDB)
+		db, err := getDB(opt.DB)
 		if err != nil {
 			return err
 		}
+		bindOpDB(ctx, db)
 
-		coll := getCollection(db, schema)
-		result, err := coll.DeleteOne(ctx, filter)
+		coll := writeCollection(db, schema, opt.WriteConcern)
+		if schema.SoftDelete != "" {
+			result, err := coll.UpdateOne(ctx, scopeFilter(ctx, schema, filter), softDeleteUpdate(schema, clockNow()), withComment(ctx, options.UpdateOne()))
+			if err != nil {
+				return fmt.Errorf("goodm: delete one failed: %w", err)
+			}
+			if result.MatchedCount == 0 {
+				return ErrNotFound
+			}
+			return nil
+		}
+		result, err := coll.DeleteOne(ctx, scopeFilter(ctx, schema, filter), withComment(ctx, options.DeleteOne()))
 		if err != nil {
 			return fmt.Errorf("goodm: delete one failed: %w", err)
 		}
@@ -700,7 +1288,9 @@ func setModelID(model interface{}, id bson.ObjectID) {
 	}
 }
 
-// setTimestamps sets CreatedAt (if zero) and UpdatedAt on a model via reflection.
+// setTimestamps sets CreatedAt (if zero) and UpdatedAt on a model via
+// reflection. Like the other base field helpers, it finds the fields by Go
+// name whatever their bson keys; see baseKeysOf.
 func setTimestamps(model interface{}, now time.Time) {
 	v := reflect.ValueOf(model)
 	if v.Kind() == reflect.Ptr {
@@ -727,19 +1317,34 @@ func setUpdatedAt(model interface{}, now time.Time) {
 	}
 }
 
+// copyCreatedAt sets CreatedAt on dst to that of src via reflection.
+func copyCreatedAt(dst, src interface{}) {
+	d := reflect.Indirect(reflect.ValueOf(dst)).FieldByName("CreatedAt")
+	s := reflect.Indirect(reflect.ValueOf(src)).FieldByName("CreatedAt")
+	if d.IsValid() && d.CanSet() && s.IsValid() && s.Type() == d.Type() {
+		d.Set(s)
+	}
+}
+
 // getDB returns the provided database or falls back to the global DB().
-func getDB(optDB *mongo.Database) (*mongo.Database, error) {
+// While UseTestStore is active it returns a handle holding the in-memory store
+// and a nil database, which getCollection resolves to the store.
+func getDB(optDB *mongo.Database) (dbHandle, error) {
 	if optDB != nil {
-		return optDB, nil
+		return dbHandle{Database: optDB}, nil
+	}
+	if mem := activeTestStore(); mem != nil {
+		return dbHandle{mem: mem}, nil
 	}
 	db := DB()
 	if db == nil {
-		return nil, ErrNoDatabase
+		return dbHandle{}, ErrNoDatabase
 	}
-	return db, nil
+	return dbHandle{Database: db}, nil
 }
 
 // validateImmutable checks that immutable fields have not changed between old and new.
+// Write-once fields may change only while the stored value is still zero.
 func validateImmutable(old, new interface{}, schema *Schema) []ValidationError {
 	var errs []ValidationError
 
@@ -753,7 +1358,7 @@ func validateImmutable(old, new interface{}, schema *Schema) []ValidationError {
 	}
 
 	for _, field := range schema.Fields {
-		if !field.Immutable {
+		if !field.Immutable && !field.WriteOnce {
 			continue
 		}
 		oldField := oldV.FieldByName(field.Name)
@@ -761,21 +1366,30 @@ func validateImmutable(old, new interface{}, schema *Schema) []ValidationError {
 		if !oldField.IsValid() || !newField.IsValid() {
 			continue
 		}
-		if !reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+		if field.Immutable {
 			errs = append(errs, ValidationError{
 				Field:   field.BSONName,
 				Message: "field is immutable and cannot be changed",
 			})
+		} else if !oldField.IsZero() {
+			errs = append(errs, ValidationError{
+				Field:   field.BSONName,
+				Message: "field is write-once and has already been set",
+			})
 		}
 	}
 
 	return errs
 }
 
-// hasImmutableFields returns true if any field in the schema is marked immutable.
+// hasImmutableFields returns true if any field in the schema is marked immutable
+// or write-once.
 func hasImmutableFields(schema *Schema) bool {
 	for _, f := range schema.Fields {
-		if f.Immutable {
+		if f.Immutable || f.WriteOnce {
 			return true
 		}
 	}
@@ -806,16 +1420,11 @@ func setModelVersion(model interface{}, version int) {
 	}
 }
 
-// managedFields are internal fields that must not be unset.
-var managedFields = map[string]bool{
-	"_id": true, "created_at": true, "updated_at": true, "__v": true,
-}
-
 // validateUnsetFields checks that unset field names are valid schema fields,
 // not managed by the ODM, and not required.
 func validateUnsetFields(schema *Schema, fields []string) error {
 	for _, name := range fields {
-		if managedFields[name] {
+		if schema.base().managed(name) {
 			return fmt.Errorf("goodm: cannot unset managed field %q", name)
 		}
 		f := schema.GetField(name)
@@ -829,40 +1438,154 @@ func validateUnsetFields(schema *Schema, fields []string) error {
 	return nil
 }
 
-// replaceWithUnset builds the replacement document, strips any unset fields, and
-// performs the ReplaceOne. Returns the number of matched documents.
-func replaceWithUnset(ctx context.Context, coll *mongo.Collection, filter bson.D, model interface{}, unsetFields []string) (int64, error) {
-	replacement, err := buildReplacement(model, unsetFields)
+// replaceWithUnset builds the replacement document, strips any unset fields,
+// overlays any carried-over stored fields, and performs the ReplaceOne.
+// Returns the number of matched documents.
+func replaceWithUnset(ctx context.Context, coll collection, filter bson.D, model interface{}, unsetFields []string, carry bson.M) (int64, error) {
+	replacement, err := buildReplacement(model, unsetFields, carry)
 	if err != nil {
 		return 0, err
 	}
-	result, err := coll.ReplaceOne(ctx, filter, replacement)
+	result, err := coll.ReplaceOne(ctx, filter, replacement, withComment(ctx, options.Replace()))
 	if err != nil {
 		return 0, fmt.Errorf("goodm: update failed: %w", err)
 	}
 	return result.MatchedCount, nil
 }
 
-// buildReplacement marshals a model to bson.M and removes unset fields.
-// When there are no unset fields, returns the model as-is to avoid the
-// marshal/unmarshal overhead.
-func buildReplacement(model interface{}, unsetFields []string) (interface{}, error) {
-	if len(unsetFields) == 0 {
+// buildReplacement marshals a model to bson.M, removes empty and unset fields,
+// and sets carried-over fields (stored values the model does not hold, such
+// as select=false fields that were not loaded). When there is nothing to
+// remove or carry, returns the model as-is to avoid the marshal/unmarshal
+// overhead.
+func buildReplacement(model interface{}, unsetFields []string, carry bson.M) (interface{}, error) {
+	var empty []string
+	var shadows bson.M
+	schema, err := getSchemaForModel(model)
+	if err == nil {
+		empty = emptyFields(model, schema)
+		shadows = shadowValues(model, schema)
+	}
+	renamed := err == nil && hasRenamedFields(schema)
+	if len(unsetFields) == 0 && len(carry) == 0 && len(empty) == 0 && len(shadows) == 0 && !renamed {
 		return model, nil
 	}
 
-	raw, err := bson.Marshal(model)
+	raw, err := marshalBSON(model)
 	if err != nil {
 		return nil, fmt.Errorf("goodm: failed to marshal model for unset: %w", err)
 	}
 	var doc bson.M
-	if err := bson.Unmarshal(raw, &doc); err != nil {
+	if err := unmarshalBSON(raw, &doc); err != nil {
 		return nil, fmt.Errorf("goodm: failed to unmarshal model for unset: %w", err)
 	}
 
+	for _, field := range empty {
+		delete(doc, field)
+	}
+	for k, v := range shadows {
+		doc[k] = v
+	}
+	for k, v := range carry {
+		doc[k] = v
+	}
 	for _, field := range unsetFields {
 		delete(doc, field)
 	}
+	if renamed {
+		renameWrites(schema, doc)
+	}
 
 	return doc, nil
 }
+
+// emptyFields returns the bson names of the model's top-level fields that are
+// not stored: nil pointers, and zero values of omitempty fields. The driver
+// already omits most of these; it still writes nil pointers as null and
+// omitempty structs as zero subdocuments.
+func emptyFields(model interface{}, schema *Schema) []string {
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	var empty []string
+	for _, fs := range schema.Fields {
+		fv := v.FieldByName(fs.Name)
+		if !fv.IsValid() {
+			continue
+		}
+		if fv.Kind() == reflect.Ptr && fv.IsNil() || fs.OmitEmpty && fv.IsZero() {
+			empty = append(empty, fs.BSONName)
+		}
+	}
+	return empty
+}
+
+// insertDocument returns the document Create writes for model: the model
+// itself, or an ordered copy without its empty fields.
+func insertDocument(model interface{}, schema *Schema) (interface{}, error) {
+	empty := emptyFields(model, schema)
+	if len(empty) == 0 && !hasShadowFields(schema) && !hasRenamedFields(schema) {
+		return model, nil
+	}
+
+	raw, err := marshalBSON(model)
+	if err != nil {
+		return nil, fmt.Errorf("goodm: bson marshal failed: %w", err)
+	}
+	var doc bson.D
+	if err := unmarshalBSON(raw, &doc); err != nil {
+		return nil, fmt.Errorf("goodm: bson unmarshal failed: %w", err)
+	}
+	omit := make(map[string]bool, len(empty))
+	for _, name := range empty {
+		omit[name] = true
+	}
+	kept := doc[:0]
+	for _, e := range doc {
+		if !omit[e.Key] {
+			kept = append(kept, e)
+		}
+	}
+	shadows := shadowValues(model, schema)
+	for _, f := range schema.Fields {
+		if value, ok := shadows[shadowName(f)]; ok {
+			kept = append(kept, bson.E{Key: shadowName(f), Value: value})
+		}
+	}
+	for _, f := range schema.Fields {
+		if f.RenamedFrom == "" {
+			continue
+		}
+		for _, e := range kept {
+			if e.Key == f.BSONName {
+				kept = append(kept, bson.E{Key: f.RenamedFrom, Value: e.Value})
+				break
+			}
+		}
+	}
+	return kept, nil
+}
+
+// splitEmptyFields separates the values of a $set update that Create would
+// not store, nil values and zero values of omitempty fields, into an $unset
+// document.
+func splitEmptyFields(schema *Schema, fields bson.M) (set, unset bson.M) {
+	set = make(bson.M, len(fields))
+	for name, val := range fields {
+		rv := reflect.ValueOf(val)
+		empty := val == nil || (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil()
+		if f := schema.GetField(name); f != nil && f.OmitEmpty && !empty {
+			empty = rv.IsZero()
+		}
+		if empty {
+			if unset == nil {
+				unset = bson.M{}
+			}
+			unset[name] = ""
+			continue
+		}
+		set[name] = val
+	}
+	return set, unset
+}
@@ -3,9 +3,11 @@ package goodm
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
 // --- unit tests (no DB) ---
@@ -118,6 +120,42 @@ func TestValidateImmutable(t *testing.T) {
 	}
 }
 
+func TestValidateImmutable_WriteOnce(t *testing.T) {
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "Name", BSONName: "name", WriteOnce: true},
+		},
+	}
+
+	type model struct {
+		Name string
+	}
+
+	// Zero -> value is allowed (first write)
+	if errs := validateImmutable(&model{}, &model{Name: "ext-1"}, schema); len(errs) != 0 {
+		t.Fatalf("expected first write to pass, got %v", errs)
+	}
+
+	// Value -> different value is rejected
+	errs := validateImmutable(&model{Name: "ext-1"}, &model{Name: "ext-2"}, schema)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errs))
+	}
+	if errs[0].Field != "name" {
+		t.Fatalf("expected name field, got %s", errs[0].Field)
+	}
+
+	// Value -> zero is rejected too
+	if errs := validateImmutable(&model{Name: "ext-1"}, &model{}, schema); len(errs) != 1 {
+		t.Fatalf("expected clearing a write-once field to fail, got %v", errs)
+	}
+
+	// Unchanged value passes
+	if errs := validateImmutable(&model{Name: "ext-1"}, &model{Name: "ext-1"}, schema); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
 func TestGetDB_NilFallback(t *testing.T) {
 	dbMu.Lock()
 	saved := globalDB
@@ -186,7 +224,7 @@ func TestBuildReplacement_NoUnset(t *testing.T) {
 	u := &testUser{Name: "Alice", Email: "alice@test.com"}
 	u.ID = bson.NewObjectID()
 
-	result, err := buildReplacement(u, nil)
+	result, err := buildReplacement(u, nil, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -204,7 +242,7 @@ func TestBuildReplacement_WithUnset(t *testing.T) {
 	}
 	u.ID = bson.NewObjectID()
 
-	result, err := buildReplacement(u, []string{"profile"})
+	result, err := buildReplacement(u, []string{"profile"}, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -298,6 +336,152 @@ func TestApplyFieldsToModel(t *testing.T) {
 	}
 }
 
+type testOptionalAddr struct {
+	City string `bson:"city"`
+}
+
+type testOptional struct {
+	Model    `bson:",inline"`
+	Name     string           `bson:"name"`
+	Nickname *string          `bson:"nickname"`
+	Address  testOptionalAddr `bson:"address,omitempty"`
+	Score    int              `bson:"score,omitempty"`
+}
+
+func registerOptionalModel(t *testing.T) {
+	t.Helper()
+	if err := Register(&testOptional{}, "test_optionals"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testOptional")
+		registryMu.Unlock()
+	})
+}
+
+// storedKeys returns the top-level keys of the stored document with id.
+func storedKeys(t *testing.T, ctx context.Context, schema *Schema, id bson.ObjectID) map[string]bool {
+	t.Helper()
+	raw, err := getCollection(dbHandle{mem: activeTestStore()}, schema).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Raw()
+	if err != nil {
+		t.Fatalf("raw find: %v", err)
+	}
+	elems, _ := raw.Elements()
+	keys := make(map[string]bool, len(elems))
+	for _, e := range elems {
+		keys[e.Key()] = true
+	}
+	return keys
+}
+
+func TestEmptyFields(t *testing.T) {
+	registerOptionalModel(t)
+	schema, _ := Get("testOptional")
+	if f := schema.GetField("address"); f == nil || !f.OmitEmpty {
+		t.Fatalf("expected address to be recorded as omitempty: %+v", f)
+	}
+	if f := schema.GetField("name"); f == nil || f.OmitEmpty {
+		t.Fatalf("expected name not to be omitempty: %+v", f)
+	}
+
+	m := &testOptional{Name: "x"}
+	m.ID = bson.NewObjectID()
+	if got := emptyFields(m, schema); !reflect.DeepEqual(got, []string{"nickname", "address", "score"}) {
+		t.Fatalf("unexpected empty fields: %v", got)
+	}
+
+	nick := ""
+	m.Nickname, m.Address.City, m.Score = &nick, "Oslo", 1
+	if got := emptyFields(m, schema); len(got) != 0 {
+		t.Fatalf("expected no empty fields, got %v", got)
+	}
+}
+
+func TestSplitEmptyFields(t *testing.T) {
+	registerOptionalModel(t)
+	schema, _ := Get("testOptional")
+
+	var nilNick *string
+	set, unset := splitEmptyFields(schema, bson.M{
+		"name": "", "nickname": nilNick, "address": testOptionalAddr{}, "score": 0,
+	})
+	if !reflect.DeepEqual(set, bson.M{"name": ""}) {
+		t.Fatalf("expected only name to be set, got %v", set)
+	}
+	if len(unset) != 3 {
+		t.Fatalf("expected nickname, address, and score to be unset, got %v", unset)
+	}
+}
+
+func TestFieldsUpdate_OmitsEmptyOperators(t *testing.T) {
+	registerOptionalModel(t)
+	schema, _ := Get("testOptional")
+
+	var nilNick *string
+	update := fieldsUpdate(schema, bson.M{"nickname": nilNick})
+	if len(update) != 2 || update[0].Key != "$inc" || update[1].Key != "$unset" {
+		t.Fatalf("expected only $inc and $unset, got %v", update)
+	}
+	update = fieldsUpdate(schema, bson.M{"name": "x"})
+	if len(update) != 2 || update[1].Key != "$set" {
+		t.Fatalf("expected only $inc and $set, got %v", update)
+	}
+}
+
+func TestEmptyFields_NotStored(t *testing.T) {
+	ctx := useTestStore(t)
+	registerOptionalModel(t)
+	schema, _ := Get("testOptional")
+
+	m := &testOptional{Name: "opt"}
+	if err := Create(ctx, m); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	keys := storedKeys(t, ctx, schema, m.ID)
+	if keys["nickname"] || keys["address"] || keys["score"] || !keys["name"] {
+		t.Fatalf("expected empty fields to be omitted on create, stored %v", keys)
+	}
+
+	nick := "o"
+	m.Nickname, m.Address.City = &nick, "Oslo"
+	if err := Update(ctx, m); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if keys := storedKeys(t, ctx, schema, m.ID); !keys["nickname"] || !keys["address"] {
+		t.Fatalf("expected set fields to be stored, stored %v", keys)
+	}
+
+	m.Nickname = nil
+	if err := Update(ctx, m); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if keys := storedKeys(t, ctx, schema, m.ID); keys["nickname"] || !keys["address"] {
+		t.Fatalf("expected nil nickname to be removed on update, stored %v", keys)
+	}
+
+	if err := UpdateFields(ctx, m, bson.M{"address": testOptionalAddr{}, "nickname": nil}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	if keys := storedKeys(t, ctx, schema, m.ID); keys["address"] || keys["nickname"] {
+		t.Fatalf("expected empty values to be unset, stored %v", keys)
+	}
+	if m.Address.City != "" || m.Nickname != nil {
+		t.Fatalf("expected the model to reflect the unset fields: %+v", m)
+	}
+
+	if _, err := CreateMany(ctx, []testOptional{{Name: "a"}, {Name: "b", Score: 2}}); err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	var all []testOptional
+	if err := Find(ctx, bson.D{{Key: "name", Value: "b"}}, &all); err != nil || len(all) != 1 {
+		t.Fatalf("find: %v (%d results)", err, len(all))
+	}
+	if keys := storedKeys(t, ctx, schema, all[0].ID); keys["address"] || !keys["score"] {
+		t.Fatalf("expected empty fields to be omitted by CreateMany, stored %v", keys)
+	}
+}
+
 // --- integration tests (require MongoDB) ---
 
 func TestCreate_Integration(t *testing.T) {
@@ -343,6 +527,29 @@ func TestCreate_ValidationFailure(t *testing.T) {
 	}
 }
 
+func TestCreate_WriteConcernOverride(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	u := &testUser{Email: "wc@test.com", Name: "WC", Role: "user"}
+	if err := Create(ctx, u, CreateOptions{WriteConcern: writeconcern.Majority()}); err != nil {
+		t.Fatalf("create with majority: %v", err)
+	}
+
+	// No deployment has 50 members, so the override must reach the server.
+	unsatisfiable := &writeconcern.WriteConcern{W: 50}
+	if err := Create(ctx, &testUser{Email: "wc2@test.com", Name: "WC", Role: "user"},
+		CreateOptions{WriteConcern: unsatisfiable}); err == nil {
+		t.Fatal("expected create with an unsatisfiable write concern to fail")
+	}
+	if err := Delete(ctx, u, DeleteOptions{WriteConcern: unsatisfiable}); err == nil {
+		t.Fatal("expected delete with an unsatisfiable write concern to fail")
+	}
+	if err := Delete(ctx, u, DeleteOptions{WriteConcern: writeconcern.W1()}); err != nil && !errors.Is(err, ErrNotFound) {
+		t.Fatalf("delete with w:1: %v", err)
+	}
+}
+
 func TestFindOne_Integration(t *testing.T) {
 	ctx, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -517,6 +724,35 @@ func TestUpdateOne_Integration(t *testing.T) {
 	}
 }
 
+func TestUpdateOne_ArrayFilters(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	oldTag, newTag, keepTag := bson.NewObjectID(), bson.NewObjectID(), bson.NewObjectID()
+	p := &testPost{Title: "Filters", TagIDs: []bson.ObjectID{oldTag, keepTag}}
+	if err := Create(ctx, p); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	err := UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: p.ID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "tags.$[t]", Value: newTag}}}},
+		&testPost{},
+		UpdateOptions{ArrayFilters: []interface{}{bson.D{{Key: "t", Value: oldTag}}}},
+	)
+	if err != nil {
+		t.Fatalf("update one: %v", err)
+	}
+
+	found := &testPost{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: p.ID}}, found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(found.TagIDs) != 2 || found.TagIDs[0] != newTag || found.TagIDs[1] != keepTag {
+		t.Fatalf("expected [%s %s], got %v", newTag.Hex(), keepTag.Hex(), found.TagIDs)
+	}
+}
+
 func TestDeleteOne_Integration(t *testing.T) {
 	ctx, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -661,6 +897,74 @@ func TestParseFields_LeafTypesNoSubFields(t *testing.T) {
 	}
 }
 
+type testGeo struct {
+	Lat float64 `bson:"lat" goodm:"required"`
+	Lng float64 `bson:"lng" goodm:"required"`
+}
+
+type testStore struct {
+	Model    `bson:",inline"`
+	Location testGeo   `bson:"location"`
+	Branches []testGeo `bson:"branches"`
+}
+
+type testDepot struct {
+	Model    `bson:",inline"`
+	Location *testGeo `bson:"location"`
+}
+
+func TestRegisterEmbedded_SharedSubFields(t *testing.T) {
+	defer func() {
+		registryMu.Lock()
+		delete(embedded, reflect.TypeOf(testGeo{}))
+		delete(registry, "testStore")
+		delete(registry, "testDepot")
+		registryMu.Unlock()
+	}()
+
+	if err := RegisterEmbedded(&testGeo{}); err != nil {
+		t.Fatalf("register embedded: %v", err)
+	}
+	if err := RegisterEmbedded(testGeo{}); err == nil {
+		t.Fatal("expected error for duplicate embedded registration")
+	}
+	if err := RegisterEmbedded(42); err == nil {
+		t.Fatal("expected error for non-struct")
+	}
+
+	es, ok := GetEmbedded("testGeo")
+	if !ok || len(es.Fields) != 2 {
+		t.Fatalf("expected embedded testGeo with 2 fields, got %+v", es)
+	}
+
+	if err := Register(&testStore{}, "test_stores"); err != nil {
+		t.Fatalf("register store: %v", err)
+	}
+	if err := Register(&testDepot{}, "test_depots"); err != nil {
+		t.Fatalf("register depot: %v", err)
+	}
+
+	store, _ := Get("testStore")
+	depot, _ := Get("testDepot")
+	for _, f := range []*FieldSchema{store.GetField("location"), store.GetField("branches"), depot.GetField("location")} {
+		if f.Embedded != "testGeo" {
+			t.Fatalf("expected field %s to reference testGeo, got %q", f.BSONName, f.Embedded)
+		}
+		if len(f.SubFields) != 2 || &f.SubFields[0] != &es.Fields[0] {
+			t.Fatalf("expected field %s to share the embedded SubFields", f.BSONName)
+		}
+	}
+	if !store.GetField("branches").IsSlice {
+		t.Fatal("expected branches IsSlice=true")
+	}
+
+	// Validation still walks the shared fields.
+	errs := Validate(&testStore{Branches: []testGeo{{Lat: 1}}}, store)
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors (location.lat, location.lng, branches[0].lng), got %v", errs)
+	}
+}
+
 // --- collection options unit tests ---
 
 func TestRegister_ConfigurableInterface(t *testing.T) {
@@ -761,7 +1065,7 @@ func TestCreateMany_AppliesDefaults(t *testing.T) {
 		{Email: "def1@test.com", Name: "Def1", Age: 20},
 		{Email: "def2@test.com", Name: "Def2", Age: 21, Role: "admin"},
 	}
-	if err := CreateMany(ctx, users); err != nil {
+	if _, err := CreateMany(ctx, users); err != nil {
 		t.Fatalf("create many: %v", err)
 	}
 
@@ -1000,7 +1304,7 @@ func TestSubdoc_CreateManyWithDefaults_Integration(t *testing.T) {
 			},
 		},
 	}
-	if err := CreateMany(ctx, orders); err != nil {
+	if _, err := CreateMany(ctx, orders); err != nil {
 		t.Fatalf("create many: %v", err)
 	}
 
@@ -1037,3 +1341,131 @@ func TestMiddleware_WithCRUD_Integration(t *testing.T) {
 		}
 	}
 }
+
+func TestWithVersionCheck_Constructor(t *testing.T) {
+	if opts := WithVersionCheck(); !opts.CheckVersion {
+		t.Fatal("expected CheckVersion to be set")
+	}
+}
+
+func TestWithVersionClause(t *testing.T) {
+	got := withVersionClause(nil, "__v", 3)
+	if len(got.(bson.D)) != 1 || got.(bson.D)[0].Key != "__v" {
+		t.Fatalf("expected bare version clause, got %v", got)
+	}
+
+	got = withVersionClause(bson.D{{Key: "name", Value: "x"}}, "__v", 0)
+	and := got.(bson.D)
+	if len(and) != 1 || and[0].Key != "$and" || len(and[0].Value.(bson.A)) != 2 {
+		t.Fatalf("expected $and of filter and version clause, got %v", got)
+	}
+}
+
+func TestAddVersionInc(t *testing.T) {
+	doc, err := addVersionInc(bson.D{{Key: "$set", Value: bson.D{{Key: "age", Value: 1}}}}, "__v")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(doc) != 2 || doc[1].Key != "$inc" {
+		t.Fatalf("expected $inc to be appended, got %v", doc)
+	}
+
+	doc, err = addVersionInc(bson.M{"$inc": bson.M{"hits": 1}}, "__v")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inc := doc[0].Value.(bson.D)
+	if len(doc) != 1 || len(inc) != 2 || inc[1].Key != "__v" {
+		t.Fatalf("expected __v merged into existing $inc, got %v", doc)
+	}
+
+	if _, err := addVersionInc(bson.D{{Key: "$inc", Value: bson.D{{Key: "__v", Value: 5}}}}, "__v"); err == nil {
+		t.Fatal("expected error when update already modifies __v")
+	}
+	if _, err := addVersionInc(bson.A{bson.D{{Key: "$set", Value: bson.D{}}}}, "__v"); err == nil {
+		t.Fatal("expected error for pipeline update")
+	}
+}
+
+func TestUpdateFields_VersionCheck(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	u := &testUser{Email: "vfields@test.com", Name: "VFields", Role: "user"}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	stale := &testUser{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, stale); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	if err := UpdateFields(ctx, u, bson.M{"age": 30}, WithVersionCheck()); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	if u.Version != 1 {
+		t.Fatalf("expected version 1, got %d", u.Version)
+	}
+
+	err := UpdateFields(ctx, stale, bson.M{"age": 40}, WithVersionCheck())
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestUpdateOne_VersionCheck(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	u := &testUser{Email: "vone@test.com", Name: "VOne", Role: "user"}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	filter := bson.D{{Key: "_id", Value: u.ID}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "age", Value: 50}}}}
+
+	if err := UpdateOne(ctx, filter, update, u, WithVersionCheck()); err != nil {
+		t.Fatalf("update one: %v", err)
+	}
+	if u.Version != 1 {
+		t.Fatalf("expected model version 1, got %d", u.Version)
+	}
+
+	stale := &testUser{}
+	err := UpdateOne(ctx, filter, update, stale, WithVersionCheck())
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	err = UpdateOne(ctx, bson.D{{Key: "_id", Value: bson.NewObjectID()}}, update, u, WithVersionCheck())
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	ctx := useTestStore(t)
+
+	u := &testHookUser{Email: "save@test.com", Name: "Save"}
+	if err := Save(ctx, u); err != nil {
+		t.Fatalf("Save new: %v", err)
+	}
+	if u.ID.IsZero() || !reflect.DeepEqual(u.Events, []string{"before_create", "after_create"}) {
+		t.Fatalf("expected Save to create, got ID %v and events %v", u.ID, u.Events)
+	}
+
+	u.Events = nil
+	u.Name = "Saved"
+	if err := Save(ctx, u); err != nil {
+		t.Fatalf("Save existing: %v", err)
+	}
+	if u.Version != 1 || !reflect.DeepEqual(u.Events, []string{"before_save", "after_save"}) {
+		t.Fatalf("expected Save to update, got version %d and events %v", u.Version, u.Events)
+	}
+
+	stale := &testHookUser{Email: "save@test.com", Name: "Stale"}
+	stale.ID = u.ID
+	if err := Save(ctx, stale); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict for a stale model, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package goodm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// dateOnly returns midnight UTC of t's calendar date in t's own location, so
+// that a date picked in any time zone is stored as the same instant.
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// isDateOnly reports whether t is midnight UTC.
+func isDateOnly(t time.Time) bool {
+	u := t.UTC()
+	return u.Hour() == 0 && u.Minute() == 0 && u.Second() == 0 && u.Nanosecond() == 0
+}
+
+// checkDateOnly ensures dateonly tags are only used on time fields, in the
+// model and its subdocuments.
+func checkDateOnly(schema *Schema) error {
+	return checkDateOnlyFields(schema.ModelName, schema.Fields)
+}
+
+// checkDateOnlyFields is checkDateOnly for one level of fields.
+func checkDateOnlyFields(modelName string, fields []FieldSchema) error {
+	for _, f := range fields {
+		if f.DateOnly && strings.TrimLeft(f.Type, "[]*") != "time.Time" {
+			return fmt.Errorf("goodm: %s: dateonly field %q must be time.Time, *time.Time, or []time.Time, got %s", modelName, f.BSONName, f.Type)
+		}
+		if err := checkDateOnlyFields(modelName, f.SubFields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasDateOnly reports whether any of fields, or their subfields, is dateonly.
+func hasDateOnly(fields []FieldSchema) bool {
+	for _, f := range fields {
+		if f.DateOnly || hasDateOnly(f.SubFields) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDateOnly checks that a time.Time, *time.Time, or []time.Time value
+// holds dates only.
+func validateDateOnly(fv reflect.Value, fieldPath string) *ValidationError {
+	switch {
+	case fv.Type() == timeType:
+		if !isDateOnly(fv.Interface().(time.Time)) {
+			return &ValidationError{Field: fieldPath, Message: "must be a date without a time of day (midnight UTC)"}
+		}
+	case fv.Kind() == reflect.Ptr:
+		if !fv.IsNil() {
+			return validateDateOnly(fv.Elem(), fieldPath)
+		}
+	case fv.Kind() == reflect.Slice:
+		for i := 0; i < fv.Len(); i++ {
+			if err := validateDateOnly(fv.Index(i), fmt.Sprintf("%s[%d]", fieldPath, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkLoadedDateOnly validates the dateonly fields of models decoded by a
+// read, so that times of day written outside goodm surface as
+// ValidationErrors instead of shifting dates between time zones. target is
+// a pointer to a model or to a slice of models.
+func checkLoadedDateOnly(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil
+	}
+	models := []reflect.Value{rv}
+	if sv := rv.Elem(); sv.Kind() == reflect.Slice {
+		models = models[:0]
+		for i := 0; i < sv.Len(); i++ {
+			models = append(models, sv.Index(i))
+		}
+	}
+
+	var errs ValidationErrors
+	for _, m := range models {
+		for m.Kind() == reflect.Interface || m.Kind() == reflect.Ptr {
+			if m.IsNil() {
+				break
+			}
+			m = m.Elem()
+		}
+		if m.Kind() != reflect.Struct || !m.CanAddr() {
+			continue
+		}
+		schema, err := getSchemaForModel(m.Addr().Interface())
+		if err != nil || !hasDateOnly(schema.Fields) {
+			continue
+		}
+		errs = append(errs, dateOnlyErrors(m, schema.Fields, "")...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// dateOnlyErrors validates the dateonly fields of v and its subdocuments.
+func dateOnlyErrors(v reflect.Value, fields []FieldSchema, pathPrefix string) []ValidationError {
+	var errs []ValidationError
+	for _, fs := range fields {
+		fv := v.FieldByName(fs.Name)
+		if !fv.IsValid() {
+			continue
+		}
+		fieldPath := fs.BSONName
+		if pathPrefix != "" {
+			fieldPath = pathPrefix + "." + fs.BSONName
+		}
+		if fs.DateOnly {
+			if err := validateDateOnly(fv, fieldPath); err != nil {
+				errs = append(errs, *err)
+			}
+		}
+		if !hasDateOnly(fs.SubFields) {
+			continue
+		}
+		if fs.IsSlice {
+			for i := 0; i < fv.Len(); i++ {
+				elem := reflect.Indirect(fv.Index(i))
+				if elem.IsValid() {
+					errs = append(errs, dateOnlyErrors(elem, fs.SubFields, fmt.Sprintf("%s[%d]", fieldPath, i))...)
+				}
+			}
+		} else if inner := reflect.Indirect(fv); inner.IsValid() {
+			errs = append(errs, dateOnlyErrors(inner, fs.SubFields, fieldPath)...)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,117 @@
+package goodm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testBooking struct {
+	Model     `bson:",inline"`
+	Guest     string     `bson:"guest"`
+	CheckIn   time.Time  `bson:"check_in"  goodm:"dateonly"`
+	CheckOut  *time.Time `bson:"check_out" goodm:"dateonly"`
+	Confirmed time.Time  `bson:"confirmed" goodm:"normalize=utc"`
+}
+
+type testBadDateOnly struct {
+	Model `bson:",inline"`
+	Day   string `bson:"day" goodm:"dateonly"`
+}
+
+func registerBookingModel(t *testing.T) {
+	t.Helper()
+	if err := Register(&testBooking{}, "test_bookings"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testBooking")
+		registryMu.Unlock()
+	})
+}
+
+func TestParseGoodmTag_DateOnly(t *testing.T) {
+	if fs := ParseGoodmTag("required,dateonly"); !fs.DateOnly || !fs.Required {
+		t.Fatalf("expected dateonly and required, got %+v", fs)
+	}
+}
+
+func TestRegister_DateOnlyRequiresTime(t *testing.T) {
+	err := Register(&testBadDateOnly{}, "test_bad_dateonly")
+	if err == nil || !strings.Contains(err.Error(), "must be time.Time") {
+		t.Fatalf("expected dateonly type error, got %v", err)
+	}
+}
+
+func TestCreate_DateOnly(t *testing.T) {
+	ctx := useTestStore(t)
+	registerBookingModel(t)
+
+	tokyo := time.FixedZone("Tokyo", 9*3600)
+	checkIn := time.Date(2024, 7, 1, 8, 0, 0, 0, tokyo) // June 30 in UTC
+	checkOut := time.Date(2024, 7, 3, 23, 0, 0, 0, tokyo)
+	b := &testBooking{Guest: "Ann", CheckIn: checkIn, CheckOut: &checkOut, Confirmed: checkIn}
+	if err := Create(ctx, b); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	want := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	if !b.CheckIn.Equal(want) || !b.CheckOut.Equal(want.AddDate(0, 0, 2)) {
+		t.Fatalf("expected dates at midnight UTC, got %v, %v", b.CheckIn, b.CheckOut)
+	}
+	if b.Confirmed.Location() != time.UTC || !b.Confirmed.Equal(checkIn) {
+		t.Fatalf("expected confirmed in UTC, got %v", b.Confirmed)
+	}
+
+	found := &testBooking{}
+	if err := FindOne(ctx, bson.D{{Key: "check_in", Value: want}}, found); err != nil {
+		t.Fatalf("find by date: %v", err)
+	}
+}
+
+func TestFindOne_DateOnlyValidatedOnRead(t *testing.T) {
+	ctx := useTestStore(t)
+	registerBookingModel(t)
+
+	id := bson.NewObjectID()
+	_, err := activeTestStore().Collection("test_bookings").InsertOne(ctx, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "guest", Value: "Bob"},
+		{Key: "check_in", Value: time.Date(2024, 7, 1, 15, 0, 0, 0, time.UTC)},
+	})
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	err = FindOne(ctx, bson.D{{Key: "_id", Value: id}}, &testBooking{})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Field != "check_in" {
+		t.Fatalf("expected check_in validation error, got %v", err)
+	}
+}
+
+func TestUpdateFields_DateOnly(t *testing.T) {
+	ctx := useTestStore(t)
+	registerBookingModel(t)
+
+	b := &testBooking{Guest: "Cy", CheckIn: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)}
+	if err := Create(ctx, b); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	moved := time.Date(2024, 7, 9, 18, 45, 0, 0, time.UTC)
+	if err := UpdateFields(ctx, b, bson.M{"check_in": moved}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+
+	found := &testBooking{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: b.ID}}, found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if want := time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC); !found.CheckIn.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, found.CheckIn)
+	}
+}
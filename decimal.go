@@ -0,0 +1,228 @@
+package goodm
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var ratType = reflect.TypeOf(big.Rat{})
+
+// maxDecimalDigits is the number of significant digits Decimal128 holds.
+const maxDecimalDigits = 34
+
+// newCodecRegistry returns a registry with goodm's built-in codecs: big.Rat
+// fields are stored as Decimal128.
+func newCodecRegistry() *bson.Registry {
+	reg := bson.NewRegistry()
+	reg.RegisterTypeEncoder(ratType, ratCodec{})
+	reg.RegisterTypeDecoder(ratType, ratCodec{})
+	return reg
+}
+
+// ratCodec stores big.Rat values, typically money, as Decimal128 so that they
+// keep their exact value and sort and aggregate numerically in MongoDB.
+// Values without a terminating decimal expansion, such as 1/3, cannot be
+// encoded; round them first. Decoding also accepts int32, int64, double, and
+// numeric string values.
+type ratCodec struct{}
+
+func (ratCodec) EncodeValue(_ bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+	r := val.Interface().(big.Rat)
+	d, err := ratToDecimal128(&r)
+	if err != nil {
+		return err
+	}
+	return vw.WriteDecimal128(d)
+}
+
+func (ratCodec) DecodeValue(_ bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+	var s string
+	switch vr.Type() {
+	case bson.TypeDecimal128:
+		d, err := vr.ReadDecimal128()
+		if err != nil {
+			return err
+		}
+		s = d.String()
+	case bson.TypeInt32:
+		n, err := vr.ReadInt32()
+		if err != nil {
+			return err
+		}
+		s = fmt.Sprint(n)
+	case bson.TypeInt64:
+		n, err := vr.ReadInt64()
+		if err != nil {
+			return err
+		}
+		s = fmt.Sprint(n)
+	case bson.TypeDouble:
+		f, err := vr.ReadDouble()
+		if err != nil {
+			return err
+		}
+		r := new(big.Rat)
+		if r.SetFloat64(f) == nil {
+			return fmt.Errorf("goodm: cannot decode %v into big.Rat", f)
+		}
+		val.Set(reflect.ValueOf(*r))
+		return nil
+	case bson.TypeString:
+		str, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		s = str
+	case bson.TypeNull:
+		val.Set(reflect.Zero(ratType))
+		return vr.ReadNull()
+	default:
+		return fmt.Errorf("goodm: cannot decode %s into big.Rat", vr.Type())
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return fmt.Errorf("goodm: cannot decode %q into big.Rat", s)
+	}
+	val.Set(reflect.ValueOf(*r))
+	return nil
+}
+
+// ratToDecimal128 converts r to a Decimal128 without rounding.
+func ratToDecimal128(r *big.Rat) (bson.Decimal128, error) {
+	places, ok := decimalPlaces(r)
+	if !ok {
+		return bson.Decimal128{}, fmt.Errorf("goodm: %s has no exact decimal representation; round it before saving", r.RatString())
+	}
+	d, err := bson.ParseDecimal128(r.FloatString(places))
+	if err != nil {
+		return bson.Decimal128{}, fmt.Errorf("goodm: %s does not fit in Decimal128: %w", r.FloatString(places), err)
+	}
+	return d, nil
+}
+
+// decimalPlaces returns the number of digits after the decimal point needed
+// to write r exactly, or false if its decimal expansion does not terminate.
+func decimalPlaces(r *big.Rat) (int, bool) {
+	denom := new(big.Int).Set(r.Denom())
+	two, five := big.NewInt(2), big.NewInt(5)
+	var twos, fives int
+	mod := new(big.Int)
+	for {
+		if q, m := new(big.Int).QuoRem(denom, two, mod); m.Sign() == 0 {
+			denom, twos = q, twos+1
+			continue
+		}
+		if q, m := new(big.Int).QuoRem(denom, five, mod); m.Sign() == 0 {
+			denom, fives = q, fives+1
+			continue
+		}
+		break
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	if twos > fives {
+		return twos, true
+	}
+	return fives, true
+}
+
+// toRat returns the value of a big.Rat, *big.Rat, bson.Decimal128, or
+// *bson.Decimal128 field. NaN and infinite decimals are not numbers.
+func toRat(fv reflect.Value) (*big.Rat, bool) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil, false
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Type() {
+	case ratType:
+		r := fv.Interface().(big.Rat)
+		return &r, true
+	case decimal128Type:
+		return new(big.Rat).SetString(fv.Interface().(bson.Decimal128).String())
+	}
+	return nil, false
+}
+
+// isDecimalType reports whether a FieldSchema type string is a decimal type.
+func isDecimalType(typ string) bool {
+	typ = strings.TrimPrefix(typ, "*")
+	return typ == "big.Rat" || typ == "bson.Decimal128"
+}
+
+// checkDecimals validates the precision= and scale= tags of schema's fields
+// and subdocuments.
+func checkDecimals(schema *Schema) error {
+	return checkDecimalFields(schema.ModelName, schema.Fields)
+}
+
+// checkDecimalFields is checkDecimals for one level of fields.
+func checkDecimalFields(modelName string, fields []FieldSchema) error {
+	for _, f := range fields {
+		if f.Precision != nil || f.Scale != nil {
+			if !isDecimalType(f.Type) {
+				return fmt.Errorf("goodm: %s: precision and scale on field %q require big.Rat or bson.Decimal128, got %s", modelName, f.BSONName, f.Type)
+			}
+			if f.Precision != nil && (*f.Precision < 1 || *f.Precision > maxDecimalDigits) {
+				return fmt.Errorf("goodm: %s: precision of field %q must be between 1 and %d", modelName, f.BSONName, maxDecimalDigits)
+			}
+			if f.Scale != nil && (*f.Scale < 0 || f.Precision != nil && *f.Scale > *f.Precision) {
+				return fmt.Errorf("goodm: %s: scale of field %q must be between 0 and its precision", modelName, f.BSONName)
+			}
+		}
+		if err := checkDecimalFields(modelName, f.SubFields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDecimal checks a decimal value against the field's scale (maximum
+// digits after the decimal point) and precision (maximum total digits, of
+// which scale are reserved for the fraction).
+func validateDecimal(fv reflect.Value, fs FieldSchema, fieldPath string) *ValidationError {
+	r, ok := toRat(fv)
+	if !ok {
+		return nil
+	}
+	places, exact := decimalPlaces(r)
+	if fs.Scale != nil && (!exact || places > *fs.Scale) {
+		return &ValidationError{
+			Field:   fieldPath,
+			Message: fmt.Sprintf("value %s has more than %d decimal places", formatDecimal(r), *fs.Scale),
+		}
+	}
+	if fs.Precision != nil {
+		fraction := places
+		if fs.Scale != nil {
+			fraction = *fs.Scale
+		}
+		intDigits := 0
+		if ip := new(big.Int).Quo(new(big.Int).Abs(r.Num()), r.Denom()); ip.Sign() != 0 {
+			intDigits = len(ip.String())
+		}
+		if intDigits+fraction > *fs.Precision {
+			return &ValidationError{
+				Field:   fieldPath,
+				Message: fmt.Sprintf("value %s exceeds precision %d", formatDecimal(r), *fs.Precision),
+			}
+		}
+	}
+	return nil
+}
+
+// formatDecimal writes r in decimal notation, or as a fraction if its
+// decimal expansion does not terminate.
+func formatDecimal(r *big.Rat) string {
+	if places, ok := decimalPlaces(r); ok {
+		return r.FloatString(places)
+	}
+	return r.RatString()
+}
@@ -0,0 +1,151 @@
+package goodm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testBill struct {
+	Model    `bson:",inline"`
+	Total    big.Rat         `bson:"total"    goodm:"precision=10,scale=2,min=0"`
+	Discount *big.Rat        `bson:"discount" goodm:"scale=2,max=100"`
+	Tax      bson.Decimal128 `bson:"tax"      goodm:"scale=4"`
+}
+
+type testBadPrecision struct {
+	Model  `bson:",inline"`
+	Amount float64 `bson:"amount" goodm:"scale=2"`
+}
+
+func registerBillModel(t *testing.T) {
+	t.Helper()
+	if err := Register(&testBill{}, "test_bills"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testBill")
+		registryMu.Unlock()
+	})
+}
+
+func mustRat(t *testing.T, s string) *big.Rat {
+	t.Helper()
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		t.Fatalf("bad rat %q", s)
+	}
+	return r
+}
+
+func TestRatCodec_RoundTrip(t *testing.T) {
+	type doc struct {
+		A big.Rat  `bson:"a"`
+		B *big.Rat `bson:"b"`
+		C *big.Rat `bson:"c"`
+	}
+	in := doc{A: *mustRat(t, "1234.50"), B: mustRat(t, "-0.125")}
+	raw, err := marshalBSON(in)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if typ := bson.Raw(raw).Lookup("a").Type; typ != bson.TypeDecimal128 {
+		t.Fatalf("expected Decimal128, got %s", typ)
+	}
+
+	var out doc
+	if err := unmarshalBSON(raw, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.A.Cmp(&in.A) != 0 || out.B.Cmp(in.B) != 0 || out.C != nil {
+		t.Fatalf("round trip mismatch: %v, %v, %v", out.A.String(), out.B, out.C)
+	}
+}
+
+func TestRatCodec_DecodesOtherNumbers(t *testing.T) {
+	var out struct {
+		I big.Rat `bson:"i"`
+		F big.Rat `bson:"f"`
+		S big.Rat `bson:"s"`
+	}
+	raw, _ := bson.Marshal(bson.D{{Key: "i", Value: int64(7)}, {Key: "f", Value: 0.5}, {Key: "s", Value: "19.99"}})
+	if err := unmarshalBSON(raw, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.I.RatString() != "7" || out.F.RatString() != "1/2" || out.S.FloatString(2) != "19.99" {
+		t.Fatalf("unexpected values: %s, %s, %s", out.I.RatString(), out.F.RatString(), out.S.RatString())
+	}
+}
+
+func TestRatCodec_NonTerminating(t *testing.T) {
+	_, err := marshalBSON(struct {
+		A big.Rat `bson:"a"`
+	}{A: *big.NewRat(1, 3)})
+	if err == nil || !strings.Contains(err.Error(), "no exact decimal representation") {
+		t.Fatalf("expected non-terminating error, got %v", err)
+	}
+}
+
+func TestRegister_DecimalTagsRequireDecimalType(t *testing.T) {
+	err := Register(&testBadPrecision{}, "test_bad_precision")
+	if err == nil || !strings.Contains(err.Error(), "require big.Rat or bson.Decimal128") {
+		t.Fatalf("expected decimal type error, got %v", err)
+	}
+}
+
+func TestValidate_Decimals(t *testing.T) {
+	useTestStore(t)
+	registerBillModel(t)
+	schema, _ := getSchemaForModel(&testBill{})
+
+	tax, _ := bson.ParseDecimal128("0.12345")
+	bill := &testBill{Total: *mustRat(t, "123456789.5"), Discount: mustRat(t, "100.001"), Tax: tax}
+	errs := Validate(bill, schema)
+	got := make(map[string]string)
+	for _, e := range errs {
+		got[e.Field] = e.Message
+	}
+	if !strings.Contains(got["total"], "exceeds precision 10") {
+		t.Fatalf("expected precision error on total, got %v", errs)
+	}
+	if !strings.Contains(got["discount"], "more than 2 decimal places") {
+		t.Fatalf("expected scale error on discount, got %v", errs)
+	}
+	if !strings.Contains(got["tax"], "more than 4 decimal places") {
+		t.Fatalf("expected scale error on tax, got %v", errs)
+	}
+
+	bill = &testBill{Total: *mustRat(t, "-5"), Discount: mustRat(t, "150")}
+	errs = Validate(bill, schema)
+	if len(errs) != 2 || !strings.Contains(errs[0].Message, "less than minimum 0") || !strings.Contains(errs[1].Message, "exceeds maximum 100") {
+		t.Fatalf("expected min and max errors, got %v", errs)
+	}
+}
+
+func TestCreate_Decimal(t *testing.T) {
+	ctx := useTestStore(t)
+	registerBillModel(t)
+
+	bill := &testBill{Total: *mustRat(t, "19.99"), Discount: mustRat(t, "2.5")}
+	if err := Create(ctx, bill); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	found := &testBill{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: bill.ID}}, found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Total.FloatString(2) != "19.99" || found.Discount.FloatString(1) != "2.5" {
+		t.Fatalf("unexpected values: %s, %s", found.Total.RatString(), found.Discount.RatString())
+	}
+
+	bad := &testBill{Total: *mustRat(t, "0.001")}
+	var verrs ValidationErrors
+	if err := Create(ctx, bad); !errors.As(err, &verrs) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// DeprecatedUse reports an operation that filters on or writes a field
+// tagged deprecated.
+type DeprecatedUse struct {
+	ModelName string
+	Field     string // dotted bson path of the deprecated field
+	Note      string // the field's deprecation note, if any
+	Operation OpType
+	InFilter  bool // true if the field is in the filter, false if it is written
+}
+
+func (u DeprecatedUse) String() string {
+	verb := "writes"
+	if u.InFilter {
+		verb = "filters on"
+	}
+	s := fmt.Sprintf("goodm: %s on %s %s deprecated field %q", u.Operation, u.ModelName, verb, u.Field)
+	if u.Note != "" {
+		s += " (" + u.Note + ")"
+	}
+	return s
+}
+
+// WarnDeprecated returns a MiddlewareFunc that reports every operation
+// using a field tagged deprecated, so callers can be found and migrated
+// before the field is removed. Filters of finds, updates, and deletes are
+// checked, as are the non-zero fields of models passed to Create and
+// Update. Update documents of UpdateOne and UpdateMany are not inspected.
+// Each use is passed to fn, or logged with the standard logger if fn is nil.
+// Operations are never blocked.
+//
+// Example:
+//
+//	type Customer struct {
+//	    goodm.Model `bson:",inline"`
+//	    Phone       string `bson:"phone" goodm:"deprecated=use phone_e164"`
+//	    PhoneE164   string `bson:"phone_e164"`
+//	}
+//
+//	goodm.Use(goodm.WarnDeprecated(nil))
+//	// goodm: find on Customer filters on deprecated field "phone" (use phone_e164)
+func WarnDeprecated(fn func(DeprecatedUse)) MiddlewareFunc {
+	if fn == nil {
+		fn = func(u DeprecatedUse) { log.Print(u.String()) }
+	}
+	return func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		schema, ok := Get(op.ModelName)
+		if !ok || !hasDeprecatedFields(schema.Fields) {
+			return next(ctx)
+		}
+		report := func(f *FieldSchema, path string, inFilter bool) {
+			fn(DeprecatedUse{
+				ModelName: op.ModelName, Field: path, Note: f.DeprecatedNote,
+				Operation: op.Operation, InFilter: inFilter,
+			})
+		}
+
+		switch op.Operation {
+		case OpFind, OpUpdate, OpDelete, OpUpdateMany, OpDeleteMany:
+			if doc, err := filterDoc(op.Filter); err == nil {
+				collectDeprecatedFilterFields(filterFields(schema), doc, func(f *FieldSchema, path string) {
+					report(f, path, true)
+				})
+			}
+		}
+		switch op.Operation {
+		case OpCreate, OpUpdate:
+			if v := reflect.Indirect(reflect.ValueOf(op.Model)); v.Kind() == reflect.Struct {
+				seen := map[string]bool{}
+				collectDeprecatedWrites(v, schema.Fields, "", func(f *FieldSchema, path string) {
+					if !seen[path] {
+						seen[path] = true
+						report(f, path, false)
+					}
+				})
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// hasDeprecatedFields reports whether any of fields, or their subfields, is
+// deprecated.
+func hasDeprecatedFields(fields []FieldSchema) bool {
+	for _, f := range fields {
+		if f.Deprecated || hasDeprecatedFields(f.SubFields) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterDoc converts a filter to a bson.D. A nil filter is empty.
+func filterDoc(filter interface{}) (bson.D, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	raw, err := marshalBSON(filter)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	if err := unmarshalBSON(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// collectDeprecatedFilterFields calls found for every deprecated field a
+// path of doc names or passes through, following $and, $or, and $nor.
+func collectDeprecatedFilterFields(fields []FieldSchema, doc bson.D, found func(*FieldSchema, string)) {
+	for _, e := range doc {
+		switch {
+		case e.Key == "$and" || e.Key == "$or" || e.Key == "$nor":
+			if clauses, ok := e.Value.(bson.A); ok {
+				for _, c := range clauses {
+					if cd, ok := c.(bson.D); ok {
+						collectDeprecatedFilterFields(fields, cd, found)
+					}
+				}
+			}
+		case strings.HasPrefix(e.Key, "$"):
+		default:
+			segments := strings.Split(e.Key, ".")
+			for i := range segments {
+				path := strings.Join(segments[:i+1], ".")
+				if f, _ := filterField(fields, path); f != nil && f.Deprecated && !isArrayPosition(segments[i]) {
+					found(f, path)
+				}
+			}
+		}
+	}
+}
+
+// collectDeprecatedWrites calls found for every non-zero deprecated field of
+// the struct v, following subdocuments and their slices.
+func collectDeprecatedWrites(v reflect.Value, fields []FieldSchema, prefix string, found func(*FieldSchema, string)) {
+	for i := range fields {
+		f := &fields[i]
+		fv := v.FieldByName(f.Name)
+		if !fv.IsValid() || fv.IsZero() {
+			continue
+		}
+		path := prefix + f.BSONName
+		if f.Deprecated {
+			found(f, path)
+		}
+		if len(f.SubFields) == 0 {
+			continue
+		}
+		fv = reflect.Indirect(fv)
+		switch fv.Kind() {
+		case reflect.Struct:
+			collectDeprecatedWrites(fv, f.SubFields, path+".", found)
+		case reflect.Slice:
+			for j := 0; j < fv.Len(); j++ {
+				if elem := reflect.Indirect(fv.Index(j)); elem.Kind() == reflect.Struct {
+					collectDeprecatedWrites(elem, f.SubFields, path+".", found)
+				}
+			}
+		}
+	}
+}
+
+// DeprecatedFieldUse is a reference in Go source to a deprecated field, found
+// by FindDeprecatedUses.
+type DeprecatedFieldUse struct {
+	Struct string // struct declaring the field
+	Field  string // Go field name
+	Note   string // the field's deprecation note, if any
+	Pos    string // file:line of the reference
+}
+
+func (u DeprecatedFieldUse) String() string {
+	s := fmt.Sprintf("%s: %s.%s is deprecated", u.Pos, u.Struct, u.Field)
+	if u.Note != "" {
+		s += ": " + u.Note
+	}
+	return s
+}
+
+// deprecatedDecl is a deprecated field declared in scanned source.
+type deprecatedDecl struct {
+	structName, field, note string
+}
+
+// FindDeprecatedUses parses Go source files, given as file names mapped to
+// contents, and reports references to struct fields tagged deprecated in
+// any of them: selectors such as c.Phone, composite literal keys such as
+// Customer{Phone: ...}, and the field's bson name used as a key in
+// bson.M{"phone": ...} or bson.E{Key: "phone", ...}.
+//
+// References are matched by name, without type information, so a name is
+// only reported when no non-deprecated field in the scanned files shares it.
+// Pass every package that declares or uses the models in one call. Results
+// are in source order, with files sorted by name.
+func FindDeprecatedUses(files map[string][]byte) ([]DeprecatedFieldUse, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	parsed := make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		f, err := parser.ParseFile(fset, name, files[name], 0)
+		if err != nil {
+			return nil, fmt.Errorf("goodm: failed to parse %s: %w", name, err)
+		}
+		parsed = append(parsed, f)
+	}
+
+	// Collect deprecated fields by Go name and bson name, dropping names
+	// that non-deprecated fields share.
+	byGoName := map[string]*deprecatedDecl{}
+	byBSONName := map[string]*deprecatedDecl{}
+	shared := map[string]bool{}
+	sharedBSON := map[string]bool{}
+	for _, f := range parsed {
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			for _, field := range st.Fields.List {
+				var goodmTag, bsonName string
+				if field.Tag != nil {
+					if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+						goodmTag = reflect.StructTag(tag).Get("goodm")
+						bsonName, _ = ParseBSONTag(reflect.StructTag(tag).Get("bson"))
+					}
+				}
+				fs := ParseGoodmTag(goodmTag)
+				for _, ident := range field.Names {
+					if !fs.Deprecated {
+						shared[ident.Name] = true
+						if bsonName != "" {
+							sharedBSON[bsonName] = true
+						}
+						continue
+					}
+					d := &deprecatedDecl{structName: spec.Name.Name, field: ident.Name, note: fs.DeprecatedNote}
+					byGoName[ident.Name] = d
+					if bsonName != "" && bsonName != "-" {
+						byBSONName[bsonName] = d
+					}
+				}
+			}
+			return true
+		})
+	}
+	for name := range shared {
+		delete(byGoName, name)
+	}
+	for name := range sharedBSON {
+		delete(byBSONName, name)
+	}
+	if len(byGoName) == 0 && len(byBSONName) == 0 {
+		return nil, nil
+	}
+
+	var uses []DeprecatedFieldUse
+	add := func(d *deprecatedDecl, pos token.Pos) {
+		p := fset.Position(pos)
+		uses = append(uses, DeprecatedFieldUse{
+			Struct: d.structName, Field: d.field, Note: d.note,
+			Pos: fmt.Sprintf("%s:%d", p.Filename, p.Line),
+		})
+	}
+	bsonKey := func(e ast.Expr) (*deprecatedDecl, bool) {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return nil, false
+		}
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return nil, false
+		}
+		d := byBSONName[s]
+		return d, d != nil
+	}
+	for _, f := range parsed {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				if d := byGoName[n.Sel.Name]; d != nil {
+					add(d, n.Sel.Pos())
+				}
+			case *ast.KeyValueExpr:
+				if ident, ok := n.Key.(*ast.Ident); ok {
+					if d := byGoName[ident.Name]; d != nil {
+						add(d, ident.Pos())
+					}
+					if ident.Name == "Key" {
+						if d, ok := bsonKey(n.Value); ok {
+							add(d, n.Value.Pos())
+						}
+					}
+				}
+				if d, ok := bsonKey(n.Key); ok {
+					add(d, n.Key.Pos())
+				}
+			}
+			return true
+		})
+	}
+	return uses, nil
+}
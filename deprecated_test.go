@@ -0,0 +1,154 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testDeprecatedAddress struct {
+	City   string `bson:"city"`
+	Region string `bson:"region" goodm:"deprecated"`
+}
+
+type testDeprecatedCustomer struct {
+	Model     `bson:",inline"`
+	Name      string                  `bson:"name" json:"name"`
+	Phone     string                  `bson:"phone" json:"phone" goodm:"deprecated=use phone_e164"`
+	PhoneE164 string                  `bson:"phone_e164" json:"phone_e164"`
+	Addresses []testDeprecatedAddress `bson:"addresses" json:"addresses"`
+}
+
+func registerDeprecatedCustomer(t *testing.T) {
+	t.Helper()
+	if err := Register(&testDeprecatedCustomer{}, "test_deprecated_customers"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testDeprecatedCustomer")
+		registryMu.Unlock()
+	})
+}
+
+func TestParseGoodmTag_Deprecated(t *testing.T) {
+	fs := ParseGoodmTag("required,deprecated=use phone_e164")
+	if !fs.Deprecated || fs.DeprecatedNote != "use phone_e164" || !fs.Required {
+		t.Errorf("unexpected schema: %+v", fs)
+	}
+	if fs := ParseGoodmTag("deprecated"); !fs.Deprecated || fs.DeprecatedNote != "" {
+		t.Errorf("unexpected schema for bare flag: %+v", fs)
+	}
+}
+
+func TestWarnDeprecated(t *testing.T) {
+	ctx := useTestStore(t)
+	registerDeprecatedCustomer(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	var uses []string
+	Use(WarnDeprecated(func(u DeprecatedUse) { uses = append(uses, u.String()) }))
+
+	c := &testDeprecatedCustomer{
+		Name:      "Ann",
+		Phone:     "555",
+		Addresses: []testDeprecatedAddress{{City: "Oslo", Region: "East"}, {City: "Bergen", Region: "West"}},
+	}
+	if err := Create(ctx, c); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var found []testDeprecatedCustomer
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "phone", Value: "555"}},
+		bson.D{{Key: "addresses.0.region", Value: "East"}},
+	}}}
+	if err := Find(ctx, filter, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if err := Find(ctx, bson.D{{Key: "phone_e164", Value: "+1555"}}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	want := []string{
+		`goodm: create on testDeprecatedCustomer writes deprecated field "phone" (use phone_e164)`,
+		`goodm: create on testDeprecatedCustomer writes deprecated field "addresses.region"`,
+		`goodm: find on testDeprecatedCustomer filters on deprecated field "phone" (use phone_e164)`,
+		`goodm: find on testDeprecatedCustomer filters on deprecated field "addresses.0.region"`,
+	}
+	if strings.Join(uses, "\n") != strings.Join(want, "\n") {
+		t.Errorf("uses:\n%s\nwant:\n%s", strings.Join(uses, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDeprecated_Generators(t *testing.T) {
+	useTestStore(t)
+	registerDeprecatedCustomer(t)
+	schemas := map[string]*Schema{}
+	schemas["testDeprecatedCustomer"], _ = Get("testDeprecatedCustomer")
+
+	if ts := GenerateTypeScript(schemas); !strings.Contains(ts, "  /** @deprecated use phone_e164 */\n  phone: string;") {
+		t.Errorf("TypeScript lacks the deprecation:\n%s", ts)
+	}
+	if gql := GenerateGraphQL(schemas); !strings.Contains(gql, `phone: String! @deprecated(reason: "use phone_e164")`) ||
+		!strings.Contains(gql, "region: String! @deprecated\n") {
+		t.Errorf("GraphQL lacks the deprecations:\n%s", gql)
+	}
+
+	snap := Snapshot(schemas)
+	if f := snap.Models[0].Fields; !f[len(f)-3].Deprecated {
+		t.Errorf("snapshot field not deprecated: %+v", f[len(f)-3])
+	}
+}
+
+const deprecatedModelSource = `package models
+
+type Customer struct {
+	Name  string ` + "`bson:\"name\"`" + `
+	Phone string ` + "`bson:\"phone\" goodm:\"deprecated=use phone_e164\"`" + `
+	Fax   string ` + "`bson:\"fax\" goodm:\"deprecated\"`" + `
+}
+
+type Supplier struct {
+	Fax string ` + "`bson:\"fax\"`" + `
+}
+`
+
+const deprecatedUseSource = `package handlers
+
+func lookup(c *models.Customer, s *models.Supplier) {
+	_ = c.Name
+	_ = c.Phone
+	_ = s.Fax
+	_ = models.Customer{Phone: "1"}
+	_ = bson.M{"phone": "1", "name": "x"}
+	_ = bson.D{{Key: "phone", Value: "1"}}
+}
+`
+
+func TestFindDeprecatedUses(t *testing.T) {
+	uses, err := FindDeprecatedUses(map[string][]byte{
+		"models/customer.go":  []byte(deprecatedModelSource),
+		"handlers/handler.go": []byte(deprecatedUseSource),
+	})
+	if err != nil {
+		t.Fatalf("FindDeprecatedUses: %v", err)
+	}
+	var got []string
+	for _, u := range uses {
+		got = append(got, u.String())
+	}
+	want := []string{
+		"handlers/handler.go:5: Customer.Phone is deprecated: use phone_e164",
+		"handlers/handler.go:7: Customer.Phone is deprecated: use phone_e164",
+		"handlers/handler.go:8: Customer.Phone is deprecated: use phone_e164",
+		"handlers/handler.go:9: Customer.Phone is deprecated: use phone_e164",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("uses:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := FindDeprecatedUses(map[string][]byte{"bad.go": []byte("package x\ntype {")}); err == nil {
+		t.Error("expected a parse error")
+	}
+}
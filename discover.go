@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/dwoolworth/goodm/internal"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -24,6 +27,15 @@ type DiscoveredField struct {
 	IsRequired bool   // appears in every sampled doc
 	IsUnique   bool   // has a unique index
 	IsIndexed  bool   // has a non-unique index
+	Ref        string // collection this ObjectID field references, inferred by InferRelations
+}
+
+// DiscoveredRelation is a has_many relation inferred from another collection's
+// reference to this one.
+type DiscoveredRelation struct {
+	Name       string // Go field name for the relation
+	Collection string // collection holding the references
+	ForeignKey string // bson field in Collection referencing this collection
 }
 
 // DiscoveredIndex describes an index found on a collection.
@@ -35,10 +47,11 @@ type DiscoveredIndex struct {
 
 // DiscoveredCollection holds the discovery results for a single collection.
 type DiscoveredCollection struct {
-	Name     string
-	Fields   []DiscoveredField
-	Indexes  []DiscoveredIndex
-	DocCount int64
+	Name      string
+	Fields    []DiscoveredField
+	Indexes   []DiscoveredIndex
+	Relations []DiscoveredRelation
+	DocCount  int64
 }
 
 // Discover introspects a MongoDB database by sampling documents and reading indexes.
@@ -68,9 +81,65 @@ func Discover(ctx context.Context, db *mongo.Database, opts DiscoverOptions) ([]
 		results = append(results, dc)
 	}
 
+	InferRelations(results)
 	return results, nil
 }
 
+// InferRelations links ObjectID fields to the collections they reference by
+// name: a field called author, author_id, or authorId holding ObjectIDs (or
+// an array of them, e.g. tag_ids) references the collection whose singular
+// name is author. The field's Ref is set and the referenced collection gets
+// the inverse has_many relation. Fields without a matching collection among
+// colls are left alone.
+func InferRelations(colls []DiscoveredCollection) {
+	byStem := make(map[string]int)
+	for i, c := range colls {
+		byStem[internal.Singularize(c.Name)] = i
+	}
+
+	for i := range colls {
+		for j := range colls[i].Fields {
+			f := &colls[i].Fields[j]
+			if f.BSONName == "_id" || (f.GoType != "bson.ObjectID" && f.GoType != "[]bson.ObjectID") {
+				continue
+			}
+			stem := referenceStem(f.BSONName)
+			target, ok := byStem[stem]
+			if !ok {
+				target, ok = byStem[internal.Singularize(stem)]
+			}
+			if !ok {
+				continue
+			}
+			f.Ref = colls[target].Name
+			if f.GoType != "bson.ObjectID" {
+				continue
+			}
+
+			name := internal.ToExportedName(colls[i].Name)
+			for _, r := range colls[target].Relations {
+				if r.Name == name {
+					name += "By" + internal.ToExportedName(stem)
+					break
+				}
+			}
+			colls[target].Relations = append(colls[target].Relations, DiscoveredRelation{
+				Name: name, Collection: colls[i].Name, ForeignKey: f.BSONName,
+			})
+		}
+	}
+}
+
+// referenceStem strips a trailing ID suffix from a reference field name.
+func referenceStem(name string) string {
+	for _, suffix := range []string{"_ids", "_id", "Ids", "IDs", "Id", "ID"} {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.ToLower(name[:len(name)-len(suffix)])
+		}
+	}
+	return strings.ToLower(name)
+}
+
 func discoverCollection(ctx context.Context, coll *mongo.Collection, opts DiscoverOptions) (DiscoveredCollection, error) {
 	dc := DiscoveredCollection{
 		Name: coll.Name(),
@@ -119,24 +188,33 @@ type fieldTracker struct {
 	count int             // number of docs containing this field
 }
 
-func sampleDocuments(ctx context.Context, coll *mongo.Collection, sampleSize int) ([]DiscoveredField, error) {
+// forEachSample decodes up to sampleSize documents of coll and calls fn with
+// each one, returning the number of documents sampled. Documents that fail
+// to decode are skipped.
+func forEachSample(ctx context.Context, coll collection, sampleSize int, fn func(doc bson.D)) (int, error) {
 	cursor, err := coll.Find(ctx, bson.D{}, options.Find().SetLimit(int64(sampleSize)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to sample documents: %w", err)
+		return 0, fmt.Errorf("failed to sample documents: %w", err)
 	}
 	defer func() { _ = cursor.Close(ctx) }()
 
-	trackers := make(map[string]*fieldTracker) // bsonName → tracker
-	fieldOrder := []string{}                   // preserve insertion order
-	totalDocs := 0
-
+	n := 0
 	for cursor.Next(ctx) {
 		var doc bson.D
 		if err := cursor.Decode(&doc); err != nil {
 			continue
 		}
-		totalDocs++
+		n++
+		fn(doc)
+	}
+	return n, nil
+}
 
+func sampleDocuments(ctx context.Context, coll *mongo.Collection, sampleSize int) ([]DiscoveredField, error) {
+	trackers := make(map[string]*fieldTracker) // bsonName → tracker
+	fieldOrder := []string{}                   // preserve insertion order
+
+	totalDocs, err := forEachSample(ctx, coll, sampleSize, func(doc bson.D) {
 		for _, elem := range doc {
 			ft, exists := trackers[elem.Key]
 			if !exists {
@@ -148,6 +226,9 @@ func sampleDocuments(ctx context.Context, coll *mongo.Collection, sampleSize int
 			goType := inferGoType(elem.Value)
 			ft.types[goType] = true
 		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if totalDocs == 0 {
@@ -222,7 +303,7 @@ func inferGoType(v interface{}) string {
 		return "bool"
 	case bson.ObjectID:
 		return "bson.ObjectID"
-	case time.Time:
+	case time.Time, bson.DateTime:
 		return "time.Time"
 	case bson.D:
 		return "bson.M"
@@ -0,0 +1,161 @@
+package goodm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// DefaultDriftWatchInterval is the time between checks of a drift watcher
+// when DriftWatch.Interval is zero.
+const DefaultDriftWatchInterval = 10 * time.Minute
+
+// DriftWatch configures StartDriftWatcher.
+type DriftWatch struct {
+	Interval   time.Duration // time between checks (default DefaultDriftWatchInterval)
+	SampleSize int           // documents sampled per collection (default DefaultDriftSampleSize)
+
+	// OnDrift is called after each check that finds drift not reported
+	// before, with the new drift, once it was sent to the Webhook.
+	OnDrift func(ctx context.Context, drifts []DriftError)
+
+	// Webhook, if set, receives new drift as a JSON POST of
+	// {"drifts": [{"collection": ..., "field": ..., "message": ...}]}.
+	// Drift whose delivery fails is sent again, and passed to OnDrift
+	// again, after the next check.
+	Webhook string
+
+	// OnError is called when a webhook delivery fails. The watcher keeps
+	// running.
+	OnError func(err error)
+
+	DB *mongo.Database // database to watch (default: the global database)
+}
+
+// StartDriftWatcher starts a worker that samples the collections of all
+// registered models every w.Interval, as Enforce does with DriftWarn, and
+// reports fields that exist in the database but not in the schema. Each
+// drifted field is reported once per watcher, when it is first seen; the
+// first check runs immediately and reports the drift that already exists.
+//
+// Example:
+//
+//	w, err := goodm.StartDriftWatcher(ctx, goodm.DriftWatch{
+//	    Interval: 10 * time.Minute,
+//	    OnDrift: func(ctx context.Context, drifts []goodm.DriftError) {
+//	        for _, d := range drifts {
+//	            log.Printf("schema drift: %v", &d)
+//	        }
+//	    },
+//	})
+//	defer w.Stop()
+func StartDriftWatcher(ctx context.Context, w DriftWatch) (*Worker, error) {
+	if w.OnDrift == nil && w.Webhook == "" {
+		return nil, fmt.Errorf("goodm: drift watcher requires OnDrift or a Webhook")
+	}
+	if w.Interval <= 0 {
+		w.Interval = DefaultDriftWatchInterval
+	}
+	if w.SampleSize <= 0 {
+		w.SampleSize = DefaultDriftSampleSize
+	}
+	db, err := getDB(w.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return startWorker(ctx, func(ctx context.Context) error {
+		reported := make(map[DriftError]bool)
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		for {
+			checkDrift(ctx, db, w, reported)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}), nil
+}
+
+// checkDrift samples every registered collection once and reports the drift
+// not in reported, adding it there once delivered.
+func checkDrift(ctx context.Context, db dbHandle, w DriftWatch, reported map[DriftError]bool) {
+	schemas := GetAll()
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fresh []DriftError
+	for _, name := range names {
+		schema := schemas[name]
+		for _, d := range detectDrift(ctx, namedCollection(db, schema.Collection), schema, w.SampleSize) {
+			if !reported[d] {
+				reported[d] = true
+				fresh = append(fresh, d)
+			}
+		}
+	}
+	if len(fresh) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	if w.Webhook != "" {
+		if err := postDrift(ctx, w.Webhook, fresh); err != nil {
+			for _, d := range fresh {
+				delete(reported, d)
+			}
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+		}
+	}
+	if w.OnDrift != nil {
+		w.OnDrift(ctx, fresh)
+	}
+}
+
+// postDrift sends drifts to a drift watcher's webhook.
+func postDrift(ctx context.Context, url string, drifts []DriftError) error {
+	type driftJSON struct {
+		Collection string `json:"collection"`
+		Field      string `json:"field"`
+		Message    string `json:"message"`
+	}
+	payload := struct {
+		Drifts []driftJSON `json:"drifts"`
+	}{}
+	for _, d := range drifts {
+		payload.Drifts = append(payload.Drifts, driftJSON{d.Collection, d.Field, d.Message})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("goodm: drift webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("goodm: drift webhook: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("goodm: drift webhook: %s returned %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package goodm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestStartDriftWatcher(t *testing.T) {
+	ctx := useTestStore(t)
+	users := activeTestStore().Collection("test_users")
+	insert := func(extra string) {
+		t.Helper()
+		doc := bson.D{{Key: "_id", Value: bson.NewObjectID()}, {Key: "email", Value: extra + "@test.com"}, {Key: extra, Value: 1}}
+		if _, err := users.InsertOne(ctx, doc); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	insert("legacy")
+
+	var posts atomic.Int32
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Drifts []struct{ Collection, Field string } `json:"drifts"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if posts.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if len(body.Drifts) == 0 || body.Drifts[0].Collection != "test_users" {
+			t.Errorf("unexpected webhook payload: %+v", body)
+		}
+	}))
+	defer hook.Close()
+
+	found := make(chan []DriftError, 10)
+	errs := make(chan error, 10)
+	w, err := StartDriftWatcher(ctx, DriftWatch{
+		Interval: 10 * time.Millisecond,
+		OnDrift:  func(ctx context.Context, drifts []DriftError) { found <- drifts },
+		Webhook:  hook.URL,
+		OnError:  func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatalf("StartDriftWatcher: %v", err)
+	}
+	defer w.Stop()
+
+	next := func() []DriftError {
+		t.Helper()
+		select {
+		case d := <-found:
+			return d
+		case <-time.After(2 * time.Second):
+			t.Fatal("no drift reported")
+			return nil
+		}
+	}
+	if d := next(); len(d) != 1 || d[0].Field != "legacy" {
+		t.Fatalf("expected the existing drift first, got %+v", d)
+	}
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("expected a webhook error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the failed webhook delivery to be reported")
+	}
+	// The failed delivery is retried with the next check.
+	if d := next(); len(d) != 1 || d[0].Field != "legacy" {
+		t.Fatalf("expected the undelivered drift again, got %+v", d)
+	}
+
+	insert("nickname")
+	if d := next(); len(d) != 1 || d[0].Field != "nickname" {
+		t.Fatalf("expected only the new drift, got %+v", d)
+	}
+	if err := w.Stop(); err != nil {
+		t.Errorf("Stop: %v", err)
+	}
+	if posts.Load() < 3 {
+		t.Errorf("expected 3 webhook deliveries, got %d", posts.Load())
+	}
+
+	if _, err := StartDriftWatcher(ctx, DriftWatch{}); err == nil {
+		t.Error("expected an error without OnDrift or Webhook")
+	}
+}
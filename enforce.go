@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -29,69 +30,147 @@ type EnforceOptions struct {
 	OnDriftWarning func(d DriftError) // called for each drift when policy is DriftWarn
 }
 
+// EnforceReport summarizes the schema work done by Enforce, for startup logs.
+type EnforceReport struct {
+	Collections []EnforcedSchema // one entry per registered schema, in enforcement order
+	Duration    time.Duration    // time taken by the whole call
+}
+
+// EnforcedSchema reports the work Enforce did for one schema.
+type EnforcedSchema struct {
+	ModelName      string
+	Collection     string
+	View           bool          // the schema is a view, created or updated instead of indexed
+	IndexesCreated []string      // names of the indexes created, empty if all existed
+	Drifts         []DriftError  // drift found, nil when drift detection is skipped
+	Duration       time.Duration // time taken by the schema, drift detection included
+}
+
+// IndexesCreated returns the number of indexes created across all schemas.
+func (r *EnforceReport) IndexesCreated() int {
+	n := 0
+	for _, c := range r.Collections {
+		n += len(c.IndexesCreated)
+	}
+	return n
+}
+
+// String returns a line per schema, listing the indexes created, the drift
+// count, and the time taken.
+func (r *EnforceReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "enforced %d schemas in %s, new indexes: %d", len(r.Collections), r.Duration, r.IndexesCreated())
+	for _, c := range r.Collections {
+		fmt.Fprintf(&b, "\n  %s (%s): ", c.Collection, c.ModelName)
+		switch {
+		case c.View:
+			b.WriteString("view updated")
+		case len(c.IndexesCreated) == 0:
+			b.WriteString("no indexes created")
+		default:
+			fmt.Fprintf(&b, "created %s", strings.Join(c.IndexesCreated, ", "))
+		}
+		fmt.Fprintf(&b, ", %d drift, %s", len(c.Drifts), c.Duration)
+	}
+	return b.String()
+}
+
 // Enforce ensures that all registered schemas are reflected in the database.
 // It creates missing indexes and optionally detects schema drift based on the
-// provided options. If no options are provided, drift detection is skipped.
-func Enforce(ctx context.Context, db *mongo.Database, opts ...EnforceOptions) error {
-	var opt EnforceOptions
+// provided options. Once every schema passes, it records each one's hash in
+// MetaCollection for VerifySchemas. If no options are provided, drift is handled by the
+// DriftPolicy of CurrentConfig, which skips detection by default.
+//
+// The returned report lists, per schema, the indexes created, the drift
+// found, and the time taken. On error it covers the schemas enforced before
+// the failure.
+//
+// Example:
+//
+//	report, err := goodm.Enforce(ctx, db)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	log.Print(report)
+func Enforce(ctx context.Context, db *mongo.Database, opts ...EnforceOptions) (*EnforceReport, error) {
+	opt := EnforceOptions{DriftPolicy: CurrentConfig().DriftPolicy}
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
+	start := time.Now()
+	report := &EnforceReport{}
+	defer func() { report.Duration = time.Since(start) }()
+
 	schemas := GetAll()
 
 	for _, schema := range schemas {
-		if err := enforceSchema(ctx, db, schema); err != nil {
-			return err
+		schemaStart := time.Now()
+		enforced := EnforcedSchema{ModelName: schema.ModelName, Collection: schema.Collection, View: schema.ViewOn != ""}
+		var err error
+		if enforced.View {
+			err = enforceView(ctx, db, schema)
+		} else {
+			enforced.IndexesCreated, err = enforceSchema(ctx, db, schema)
 		}
-
-		if opt.DriftPolicy == DriftIgnore {
-			continue
+		if err != nil {
+			return report, err
 		}
 
-		sampleSize := opt.DriftSampleSize
-		if sampleSize <= 0 {
-			sampleSize = DefaultDriftSampleSize
+		if opt.DriftPolicy != DriftIgnore {
+			sampleSize := opt.DriftSampleSize
+			if sampleSize <= 0 {
+				sampleSize = DefaultDriftSampleSize
+			}
+			enforced.Drifts = DetectDrift(ctx, db, schema, sampleSize)
 		}
-		drifts := DetectDrift(ctx, db, schema, sampleSize)
-		if len(drifts) == 0 {
+		enforced.Duration = time.Since(schemaStart)
+		report.Collections = append(report.Collections, enforced)
+		if len(enforced.Drifts) == 0 {
 			continue
 		}
 
 		switch opt.DriftPolicy {
 		case DriftWarn:
-			for _, d := range drifts {
+			for _, d := range enforced.Drifts {
 				if opt.OnDriftWarning != nil {
 					opt.OnDriftWarning(d)
 				}
 			}
 		case DriftFatal:
-			msgs := make([]string, len(drifts))
-			for i, d := range drifts {
+			msgs := make([]string, len(enforced.Drifts))
+			for i, d := range enforced.Drifts {
 				msgs[i] = d.Error()
 			}
-			return &EnforcementError{
+			return report, &EnforcementError{
 				Collection: schema.Collection,
 				Message:    fmt.Sprintf("schema drift detected: %s", strings.Join(msgs, "; ")),
 			}
 		}
 	}
 
-	return nil
+	if err := stampSchemas(ctx, db.Collection(MetaCollection), schemas); err != nil {
+		return report, err
+	}
+	return report, nil
 }
 
-func enforceSchema(ctx context.Context, db *mongo.Database, schema *Schema) error {
+// enforceSchema creates the missing indexes of schema and returns the names
+// of those it created.
+func enforceSchema(ctx context.Context, db *mongo.Database, schema *Schema) ([]string, error) {
 	coll := db.Collection(schema.Collection)
 
 	// Get existing indexes
 	existing, err := ListExistingIndexes(ctx, coll)
 	if err != nil {
-		return &EnforcementError{
+		return nil, &EnforcementError{
 			Collection: schema.Collection,
 			Message:    fmt.Sprintf("failed to list indexes: %v", err),
 		}
 	}
 
+	var created []string
+
 	// Create single-field indexes from field tags
 	for _, field := range schema.Fields {
 		if field.Unique {
@@ -99,27 +178,32 @@ func enforceSchema(ctx context.Context, db *mongo.Database, schema *Schema) erro
 			if !existing[indexName] {
 				model := mongo.IndexModel{
 					Keys:    bson.D{{Key: field.BSONName, Value: 1}},
-					Options: options.Index().SetUnique(true),
+					Options: indexOptions(true, field.Collation),
 				}
-				if _, err := coll.Indexes().CreateOne(ctx, model); err != nil {
-					return &EnforcementError{
+				name, err := coll.Indexes().CreateOne(ctx, model)
+				if err != nil {
+					return created, &EnforcementError{
 						Collection: schema.Collection,
 						Message:    fmt.Sprintf("failed to create unique index on %s: %v", field.BSONName, err),
 					}
 				}
+				created = append(created, name)
 			}
 		} else if field.Index {
 			indexName := field.BSONName + "_1"
 			if !existing[indexName] {
 				model := mongo.IndexModel{
-					Keys: bson.D{{Key: field.BSONName, Value: 1}},
+					Keys:    bson.D{{Key: field.BSONName, Value: 1}},
+					Options: indexOptions(false, field.Collation),
 				}
-				if _, err := coll.Indexes().CreateOne(ctx, model); err != nil {
-					return &EnforcementError{
+				name, err := coll.Indexes().CreateOne(ctx, model)
+				if err != nil {
+					return created, &EnforcementError{
 						Collection: schema.Collection,
 						Message:    fmt.Sprintf("failed to create index on %s: %v", field.BSONName, err),
 					}
 				}
+				created = append(created, name)
 			}
 		}
 	}
@@ -132,29 +216,45 @@ func enforceSchema(ctx context.Context, db *mongo.Database, schema *Schema) erro
 			for _, f := range ci.Fields {
 				keys = append(keys, bson.E{Key: f, Value: 1})
 			}
-			model := mongo.IndexModel{Keys: keys}
-			if ci.Unique {
-				model.Options = options.Index().SetUnique(true)
-			}
-			if _, err := coll.Indexes().CreateOne(ctx, model); err != nil {
-				return &EnforcementError{
+			model := mongo.IndexModel{Keys: keys, Options: indexOptions(ci.Unique, ci.Collation)}
+			name, err := coll.Indexes().CreateOne(ctx, model)
+			if err != nil {
+				return created, &EnforcementError{
 					Collection: schema.Collection,
 					Message:    fmt.Sprintf("failed to create compound index %s: %v", indexName, err),
 				}
 			}
+			created = append(created, name)
 		}
 	}
 
-	return nil
+	return created, nil
+}
+
+// indexOptions returns the options for an index with the given uniqueness
+// and collation spec. The spec was validated by Register.
+func indexOptions(unique bool, collation string) *options.IndexOptionsBuilder {
+	opts := options.Index()
+	if unique {
+		opts.SetUnique(true)
+	}
+	if collation != "" {
+		if c, err := ParseCollation(collation); err == nil {
+			opts.SetCollation(c)
+		}
+	}
+	return opts
 }
 
 // DetectDrift samples documents from the collection and reports fields
 // that exist in the database but not in the schema. The sampleSize parameter
 // controls how many documents are sampled (use DefaultDriftSampleSize if unsure).
 func DetectDrift(ctx context.Context, db *mongo.Database, schema *Schema, sampleSize int) []DriftError {
-	var drifts []DriftError
-	coll := db.Collection(schema.Collection)
+	return detectDrift(ctx, db.Collection(schema.Collection), schema, sampleSize)
+}
 
+func detectDrift(ctx context.Context, coll collection, schema *Schema, sampleSize int) []DriftError {
+	var drifts []DriftError
 	if sampleSize <= 0 {
 		sampleSize = DefaultDriftSampleSize
 	}
@@ -168,6 +268,9 @@ func DetectDrift(ctx context.Context, db *mongo.Database, schema *Schema, sample
 	knownFields := make(map[string]bool)
 	for _, f := range schema.Fields {
 		knownFields[f.BSONName] = true
+		if name := shadowName(f); name != "" {
+			knownFields[name] = true
+		}
 	}
 
 	seen := make(map[string]bool)
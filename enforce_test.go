@@ -0,0 +1,75 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestEnforceReport_Integration(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.Collection("test_users").InsertOne(ctx, bson.D{{Key: "email", Value: "drift@test.com"}, {Key: "legacy", Value: 1}}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	report, err := Enforce(ctx, db, EnforceOptions{DriftPolicy: DriftWarn})
+	if err != nil {
+		t.Fatalf("enforce: %v", err)
+	}
+	if len(report.Collections) != len(GetAll()) || report.Duration <= 0 {
+		t.Fatalf("expected an entry per schema, got %+v", report)
+	}
+	var users *EnforcedSchema
+	for i := range report.Collections {
+		if report.Collections[i].ModelName == "testUser" {
+			users = &report.Collections[i]
+		}
+	}
+	if users == nil {
+		t.Fatalf("expected testUser in the report, got %+v", report.Collections)
+	}
+	if len(users.IndexesCreated) != 1 || users.IndexesCreated[0] != "email_1" {
+		t.Errorf("expected email_1 to be created, got %v", users.IndexesCreated)
+	}
+	if len(users.Drifts) != 1 || users.Drifts[0].Field != "legacy" {
+		t.Errorf("expected the legacy field to drift, got %v", users.Drifts)
+	}
+
+	report, err = Enforce(ctx, db)
+	if err != nil {
+		t.Fatalf("second enforce: %v", err)
+	}
+	if n := report.IndexesCreated(); n != 0 {
+		t.Errorf("expected no indexes on the second run, got %d", n)
+	}
+	for _, c := range report.Collections {
+		if c.Drifts != nil {
+			t.Errorf("expected drift detection to be skipped, got %v for %s", c.Drifts, c.ModelName)
+		}
+	}
+}
+
+func TestEnforceReport_String(t *testing.T) {
+	report := &EnforceReport{
+		Duration: 30 * time.Millisecond,
+		Collections: []EnforcedSchema{
+			{ModelName: "User", Collection: "users", IndexesCreated: []string{"email_1", "tenant_1_name_1"},
+				Drifts: []DriftError{{Collection: "users", Field: "legacy"}}, Duration: 20 * time.Millisecond},
+			{ModelName: "Post", Collection: "posts", Duration: 5 * time.Millisecond},
+			{ModelName: "ActiveUser", Collection: "active_users", View: true, Duration: 5 * time.Millisecond},
+		},
+	}
+	want := strings.Join([]string{
+		"enforced 3 schemas in 30ms, new indexes: 2",
+		"  users (User): created email_1, tenant_1_name_1, 1 drift, 20ms",
+		"  posts (Post): no indexes created, 0 drift, 5ms",
+		"  active_users (ActiveUser): view updated, 0 drift, 5ms",
+	}, "\n")
+	if got := report.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+package goodm
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dwoolworth/goodm/internal"
+)
+
+// EnumOptions controls enum code generation.
+type EnumOptions struct {
+	PackageName string // Go package name (default "models")
+}
+
+// enumType is one generated enum: a named type and its allowed values.
+type enumType struct {
+	Name       string // e.g. "UserRole"
+	Underlying string // "string" or a numeric Go type
+	Source     string // e.g. "User.Role"
+	Values     []string
+}
+
+// GenerateEnums generates Go source declaring a type per enum-tagged field of
+// the given schemas and their subdocument structs, named after the struct and
+// field (e.g. UserRole for User.Role), with a constant per allowed value
+// (UserRoleAdmin), an IsValid method, and a Values method returning every
+// value in tag order. Generating the constants from the tags keeps code that
+// compares or assigns enum values from drifting away from what validation
+// accepts.
+//
+// String fields produce string types; integer and float fields produce
+// numeric types whose values must parse as numbers. Two values mapping to the
+// same constant name are an error.
+//
+// Example:
+//
+//	src, err := goodm.GenerateEnums(goodm.GetAll(), goodm.EnumOptions{PackageName: "models"})
+func GenerateEnums(schemas map[string]*Schema, opts ...EnumOptions) ([]byte, error) {
+	var opt EnumOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.PackageName == "" {
+		opt.PackageName = "models"
+	}
+
+	enums, err := collectEnums(schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by goodm gen enums. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n", opt.PackageName)
+	for _, e := range enums {
+		if err := writeEnum(&b, e); err != nil {
+			return nil, err
+		}
+	}
+
+	formatted, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("goodm: gen enums: %w", err)
+	}
+	return formatted, nil
+}
+
+// collectEnums returns the enum fields of schemas and of the subdocument
+// structs they reference, sorted by type name.
+func collectEnums(schemas map[string]*Schema) ([]enumType, error) {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var enums []enumType
+	seen := map[reflect.Type]bool{}
+	byName := map[string]string{}
+	var walk func(structName string, t reflect.Type) error
+	walk = func(structName string, t reflect.Type) error {
+		if seen[t] {
+			return nil
+		}
+		seen[t] = true
+		for _, f := range internal.StructFields(t) {
+			base := derefType(f.Type)
+			tag := ParseGoodmTag(f.Tag.Get("goodm"))
+			if len(tag.Enum) == 0 {
+				if base.Kind() == reflect.Struct && base.Name() != "" && base != timeType && base != ratType {
+					if err := walk(base.Name(), base); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			e := enumType{
+				Name:   structName + f.Name,
+				Source: structName + "." + f.Name,
+				Values: tag.Enum,
+			}
+			switch base.Kind() {
+			case reflect.String:
+				e.Underlying = "string"
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Float32, reflect.Float64:
+				e.Underlying = base.Kind().String()
+			default:
+				return fmt.Errorf("goodm: gen enums: %s has enum values but type %s", e.Source, f.Type)
+			}
+			if other, ok := byName[e.Name]; ok {
+				return fmt.Errorf("goodm: gen enums: %s and %s both generate type %s", other, e.Source, e.Name)
+			}
+			byName[e.Name] = e.Source
+			enums = append(enums, e)
+		}
+		return nil
+	}
+	for _, name := range names {
+		s := schemas[name]
+		if err := walk(s.ModelName, s.modelType); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	return enums, nil
+}
+
+// writeEnum writes the type, constants, and methods of one enum.
+func writeEnum(b *bytes.Buffer, e enumType) error {
+	consts := make([]string, len(e.Values))
+	literals := make([]string, len(e.Values))
+	used := map[string]string{}
+	for i, v := range e.Values {
+		consts[i] = e.Name + enumConstSuffix(v)
+		if prev, ok := used[consts[i]]; ok {
+			return fmt.Errorf("goodm: gen enums: values %q and %q of %s both generate constant %s", prev, v, e.Source, consts[i])
+		}
+		used[consts[i]] = v
+
+		if e.Underlying == "string" {
+			literals[i] = strconv.Quote(v)
+		} else {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return fmt.Errorf("goodm: gen enums: value %q of %s is not a number", v, e.Source)
+			}
+			literals[i] = v
+		}
+	}
+
+	fmt.Fprintf(b, "\n// %s is the set of values allowed for %s.\n", e.Name, e.Source)
+	fmt.Fprintf(b, "type %s %s\n\nconst (\n", e.Name, e.Underlying)
+	for i := range consts {
+		fmt.Fprintf(b, "\t%s %s = %s\n", consts[i], e.Name, literals[i])
+	}
+	b.WriteString(")\n")
+
+	fmt.Fprintf(b, "\n// IsValid reports whether v is one of the allowed values.\n")
+	fmt.Fprintf(b, "func (v %s) IsValid() bool {\n\tswitch v {\n\tcase %s:\n\t\treturn true\n\t}\n\treturn false\n}\n",
+		e.Name, strings.Join(consts, ", "))
+
+	fmt.Fprintf(b, "\n// Values returns the allowed values, in tag order.\n")
+	fmt.Fprintf(b, "func (%s) Values() []%s {\n\treturn []%s{%s}\n}\n",
+		e.Name, e.Name, e.Name, strings.Join(consts, ", "))
+	return nil
+}
+
+// enumConstSuffix returns the exported identifier suffix for an enum value:
+// "pending_review" and "pending-review" become PendingReview, "1.5" becomes
+// 1_5, and the empty string becomes Empty.
+func enumConstSuffix(value string) string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range value {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	if len(words) == 0 {
+		return "Empty"
+	}
+
+	var b strings.Builder
+	for i, w := range words {
+		if i > 0 && unicode.IsDigit([]rune(w)[0]) && b.Len() > 0 {
+			last := []rune(b.String())
+			if unicode.IsDigit(last[len(last)-1]) {
+				b.WriteString("_")
+			}
+		}
+		b.WriteString(internal.ToExportedName(w))
+	}
+	return b.String()
+}
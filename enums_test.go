@@ -0,0 +1,124 @@
+package goodm
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testEnumAssignee struct {
+	Kind string `bson:"kind" goodm:"enum=person|team"`
+}
+
+type testEnumTicket struct {
+	Model    `bson:",inline"`
+	Status   string             `bson:"status" goodm:"enum=open|in_progress|closed"`
+	Priority int                `bson:"priority" goodm:"enum=1|2|3"`
+	Labels   []string           `bson:"labels" goodm:"enum=bug|feature-request"`
+	Assignee *testEnumAssignee  `bson:"assignee"`
+	Watchers []testEnumAssignee `bson:"watchers"`
+}
+
+func registerEnumTicket(t *testing.T) map[string]*Schema {
+	t.Helper()
+	if err := Register(&testEnumTicket{}, "test_enum_tickets"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testEnumTicket")
+		registryMu.Unlock()
+	})
+	schema, _ := Get("testEnumTicket")
+	return map[string]*Schema{"testEnumTicket": schema}
+}
+
+func TestGenerateEnums(t *testing.T) {
+	schemas := registerEnumTicket(t)
+
+	src, err := GenerateEnums(schemas, EnumOptions{PackageName: "tickets"})
+	if err != nil {
+		t.Fatalf("GenerateEnums: %v", err)
+	}
+	code := string(src)
+	if _, err := parser.ParseFile(token.NewFileSet(), "enums.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	for _, want := range []string{
+		"// Code generated by goodm gen enums. DO NOT EDIT.",
+		"package tickets",
+		"type testEnumTicketStatus string",
+		`testEnumTicketStatusOpen       testEnumTicketStatus = "open"`,
+		`testEnumTicketStatusInProgress testEnumTicketStatus = "in_progress"`,
+		"type testEnumTicketPriority int",
+		"testEnumTicketPriority1 testEnumTicketPriority = 1",
+		`testEnumTicketLabelsFeatureRequest testEnumTicketLabels = "feature-request"`,
+		"type testEnumAssigneeKind string",
+		"func (v testEnumTicketStatus) IsValid() bool {",
+		"case testEnumTicketStatusOpen, testEnumTicketStatusInProgress, testEnumTicketStatusClosed:",
+		"func (testEnumTicketStatus) Values() []testEnumTicketStatus {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in:\n%s", want, code)
+		}
+	}
+	if n := strings.Count(code, "type testEnumAssigneeKind "); n != 1 {
+		t.Errorf("subdocument enum declared %d times, want 1", n)
+	}
+	if strings.Index(code, "type testEnumAssigneeKind") > strings.Index(code, "type testEnumTicketLabels") {
+		t.Error("enums are not sorted by type name")
+	}
+}
+
+func TestGenerateEnums_DefaultPackage(t *testing.T) {
+	schemas := registerEnumTicket(t)
+
+	src, err := GenerateEnums(schemas)
+	if err != nil {
+		t.Fatalf("GenerateEnums: %v", err)
+	}
+	if !strings.Contains(string(src), "package models\n") {
+		t.Errorf("expected package models, got:\n%s", src)
+	}
+}
+
+func TestGenerateEnums_Errors(t *testing.T) {
+	type collide struct {
+		Model `bson:",inline"`
+		Mode  string `bson:"mode" goodm:"enum=read-only|read_only"`
+	}
+	type notNumber struct {
+		Model `bson:",inline"`
+		Level int `bson:"level" goodm:"enum=1|high"`
+	}
+	for name, model := range map[string]interface{}{
+		"collide":   &collide{},
+		"notNumber": &notNumber{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			schema := &Schema{ModelName: name, modelType: reflect.TypeOf(model).Elem()}
+			if _, err := GenerateEnums(map[string]*Schema{name: schema}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestEnumConstSuffix(t *testing.T) {
+	tests := map[string]string{
+		"admin":          "Admin",
+		"pending_review": "PendingReview",
+		"pending-review": "PendingReview",
+		"1.5":            "1_5",
+		"":               "Empty",
+		"id":             "ID",
+	}
+	for in, want := range tests {
+		if got := enumConstSuffix(in); got != want {
+			t.Errorf("enumConstSuffix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
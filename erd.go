@@ -0,0 +1,94 @@
+package goodm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GenerateERD renders the relations between the given schemas as a Mermaid
+// entity-relationship diagram. Each schema becomes an entity listing its
+// fields. Relations are drawn once per foreign key: has_one/has_many
+// relations are labeled with their field name, and belongs_to relations
+// without a matching has relation with the field holding the ID.
+//
+// Example:
+//
+//	fmt.Println(goodm.GenerateERD(goodm.GetAll()))
+func GenerateERD(schemas map[string]*Schema) string {
+	names := make([]string, 0, len(schemas))
+	byCollection := map[string]*Schema{}
+	for name, s := range schemas {
+		names = append(names, name)
+		if _, ok := byCollection[s.Collection]; !ok {
+			byCollection[s.Collection] = s
+		}
+	}
+	sort.Strings(names)
+
+	entity := func(collection string) string {
+		if s, ok := byCollection[collection]; ok {
+			return s.ModelName
+		}
+		return collection
+	}
+
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, name := range names {
+		s := schemas[name]
+		fmt.Fprintf(&b, "    %s {\n", s.ModelName)
+		for _, f := range s.Fields {
+			key := ""
+			switch {
+			case f.BSONName == "_id":
+				key = " PK"
+			case f.Ref != "":
+				key = " FK"
+			case f.Unique:
+				key = " UK"
+			}
+			fmt.Fprintf(&b, "        %s %s%s\n", erdType(f.Type), f.BSONName, key)
+		}
+		b.WriteString("    }\n")
+	}
+
+	// Has relations are drawn first so their names label the edges; the
+	// belongs_to relations mirroring them are skipped.
+	drawn := map[string]bool{}
+	for _, pass := range []bool{true, false} {
+		for _, name := range names {
+			s := schemas[name]
+			for _, rel := range s.Relations {
+				if (rel.Kind != RelationBelongsTo) != pass {
+					continue
+				}
+				var line, edge string
+				switch rel.Kind {
+				case RelationBelongsTo:
+					edge = rel.Collection + "<-" + s.Collection + "." + rel.LocalField
+					line = fmt.Sprintf("    %s ||--o{ %s : %q", entity(rel.Collection), s.ModelName, rel.LocalField)
+				case RelationHasOne:
+					edge = s.Collection + "<-" + rel.Collection + "." + rel.ForeignKey
+					line = fmt.Sprintf("    %s ||--o| %s : %q", s.ModelName, entity(rel.Collection), rel.Name)
+				case RelationHasMany:
+					edge = s.Collection + "<-" + rel.Collection + "." + rel.ForeignKey
+					line = fmt.Sprintf("    %s ||--o{ %s : %q", s.ModelName, entity(rel.Collection), rel.Name)
+				}
+				if drawn[edge] {
+					continue
+				}
+				drawn[edge] = true
+				b.WriteString(line + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// erdType converts a Go type name into a Mermaid attribute type, which may
+// not contain spaces or brackets.
+func erdType(goType string) string {
+	r := strings.NewReplacer("[]", "array_", "*", "", ".", "_", " ", "", "{", "", "}", "", "[", "", "]", "")
+	return r.Replace(goType)
+}
@@ -17,8 +17,54 @@ var (
 	// (optimistic concurrency control). This means another process modified the
 	// document between your read and write.
 	ErrVersionConflict = errors.New("goodm: version conflict (document was modified by another process)")
+
+	// ErrNestedTransaction is returned by WithTransaction with
+	// TransactionOptions.FailNested when the context is already inside a
+	// transaction.
+	ErrNestedTransaction = errors.New("goodm: transaction already in progress")
+
+	// ErrReadOnly is returned by writes while SetReadOnly is on. Errors
+	// returned by writes to views match it too.
+	ErrReadOnly = errors.New("goodm: read-only mode")
+
+	// ErrUpsertConflict is returned by Upsert when the document matching its
+	// filter kept appearing, changing, or disappearing between the lookup and
+	// the write, and the retries ran out.
+	ErrUpsertConflict = errors.New("goodm: upsert conflict (matching document kept changing)")
 )
 
+// ReadOnlyError is returned by writes to a model registered with
+// RegisterView.
+type ReadOnlyError struct {
+	ModelName string
+	View      string // the view's name
+	Operation OpType
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("goodm: %s is read-only: %s is a view, %s not allowed", e.ModelName, e.View, e.Operation)
+}
+
+// Is reports whether target is ErrReadOnly.
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnly
+}
+
+// AccessDeniedError is returned when the access policy set with SetPolicy
+// denies an operation.
+type AccessDeniedError struct {
+	ModelName string
+	Operation OpType
+	Reason    string
+}
+
+func (e *AccessDeniedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("goodm: %s on %s denied by policy", e.Operation, e.ModelName)
+	}
+	return fmt.Sprintf("goodm: %s on %s denied by policy: %s", e.Operation, e.ModelName, e.Reason)
+}
+
 // DriftError indicates a field exists in the database but not in the schema.
 type DriftError struct {
 	Collection string
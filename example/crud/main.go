@@ -73,10 +73,11 @@ func main() {
 	if err != nil {
 		log.Fatalf("connect: %v", err)
 	}
-	if err := goodm.Enforce(ctx, db); err != nil {
+	report, err := goodm.Enforce(ctx, db)
+	if err != nil {
 		log.Fatalf("enforce: %v", err)
 	}
-	fmt.Println("Connected and enforced schemas")
+	fmt.Printf("Connected and %s\n", report)
 
 	// Clean up collection for a fresh demo
 	_ = db.Collection("crud_example_users").Drop(ctx)
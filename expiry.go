@@ -0,0 +1,210 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// defaultExpiryBatchSize is the number of expired documents loaded per query.
+const defaultExpiryBatchSize = 100
+
+// ExpiryOptions configures DeleteExpired and StartExpiryWorker.
+type ExpiryOptions struct {
+	DB        *mongo.Database
+	Archive   string            // collection each expired document is copied to before it is deleted
+	BatchSize int64             // documents loaded per query (default 100)
+	OnSweep   func(ExpirySweep) // called after every sweep, e.g. to record metrics
+}
+
+// ExpirySweep reports the outcome of one pass over expired documents.
+type ExpirySweep struct {
+	Deleted  int           // documents deleted
+	Archived int           // documents copied to the archive collection
+	Failed   int           // documents whose archive or delete failed; retried on the next sweep
+	Err      error         // the first failure, if any
+	Duration time.Duration // time the sweep took
+}
+
+// DeleteExpired deletes the documents of model whose time field, given by its
+// bson name, is at or before now. Unlike a TTL index, each document is
+// loaded and deleted with Delete, so BeforeDelete and AfterDelete hooks,
+// middleware, and delete rules run. With opts.Archive set, each document is
+// first copied to the archive collection, as the model encodes it.
+//
+// A document that fails to archive or delete is counted in Failed and left
+// in place; the sweep continues with the others. The returned error is
+// non-nil only if the sweep could not run at all.
+//
+// Example:
+//
+//	sweep, err := goodm.DeleteExpired(ctx, &Session{}, "expires_at")
+func DeleteExpired(ctx context.Context, model interface{}, field string, opts ...ExpiryOptions) (*ExpirySweep, error) {
+	var opt ExpiryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	schema, err := expirySchema(model, field)
+	if err != nil {
+		return nil, err
+	}
+	sweep := sweepExpired(ctx, schema, field, opt)
+	if sweep.Deleted == 0 && sweep.Failed == 0 && sweep.Err != nil {
+		return nil, sweep.Err
+	}
+	return &sweep, nil
+}
+
+// StartExpiryWorker starts a worker that runs DeleteExpired immediately and
+// then every interval, for deployments that cannot use a TTL index, for
+// example because expiry must run delete hooks or archive documents. Sweep
+// failures do not stop the worker; observe them with opts.OnSweep.
+//
+// Example:
+//
+//	w, err := goodm.StartExpiryWorker(ctx, &Session{}, "expires_at", time.Minute,
+//	    goodm.ExpiryOptions{
+//	        Archive: "sessions_archive",
+//	        OnSweep: func(s goodm.ExpirySweep) { expiredTotal.Add(float64(s.Deleted)) },
+//	    })
+//	defer w.Stop()
+func StartExpiryWorker(ctx context.Context, model interface{}, field string, interval time.Duration, opts ...ExpiryOptions) (*Worker, error) {
+	var opt ExpiryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	schema, err := expirySchema(model, field)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("goodm: expiry interval must be positive, got %s", interval)
+	}
+
+	return startWorker(ctx, func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			sweepExpired(ctx, schema, field, opt)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}), nil
+}
+
+// expirySchema resolves model's schema and checks that field is a time field.
+func expirySchema(model interface{}, field string) (*Schema, error) {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return nil, err
+	}
+	f := schema.GetField(field)
+	if f == nil {
+		return nil, fmt.Errorf("goodm: %s has no field %q", schema.ModelName, field)
+	}
+	if strings.TrimPrefix(f.Type, "*") != "time.Time" {
+		return nil, fmt.Errorf("goodm: expiry field %q of %s must be time.Time or *time.Time, got %s", field, schema.ModelName, f.Type)
+	}
+	return schema, nil
+}
+
+// sweepExpired archives and deletes expired documents in batches until only
+// documents that failed in this sweep are left, then reports the sweep to
+// opt.OnSweep.
+func sweepExpired(ctx context.Context, schema *Schema, field string, opt ExpiryOptions) ExpirySweep {
+	start := time.Now()
+	var sweep ExpirySweep
+	defer func() {
+		sweep.Duration = time.Since(start)
+		if opt.OnSweep != nil {
+			opt.OnSweep(sweep)
+		}
+	}()
+
+	fail := func(err error) {
+		sweep.Failed++
+		if sweep.Err == nil {
+			sweep.Err = err
+		}
+	}
+
+	batch := opt.BatchSize
+	if batch <= 0 {
+		batch = defaultExpiryBatchSize
+	}
+	filter := bson.D{{Key: field, Value: bson.D{{Key: "$lte", Value: start}}}}
+	failed := map[bson.ObjectID]bool{}
+	for ctx.Err() == nil {
+		// Skip documents that already failed in this sweep, so they are not
+		// retried until the next one.
+		q := filter
+		if len(failed) > 0 {
+			ids := make(bson.A, 0, len(failed))
+			for id := range failed {
+				ids = append(ids, id)
+			}
+			q = append(bson.D{{Key: "_id", Value: bson.D{{Key: "$nin", Value: ids}}}}, filter...)
+		}
+
+		results := reflect.New(reflect.SliceOf(schema.modelType))
+		err := Find(ctx, q, results.Interface(), FindOptions{
+			DB: opt.DB, Limit: batch, Sort: bson.D{{Key: field, Value: 1}}, IncludeHidden: true,
+		})
+		if err != nil {
+			if sweep.Err == nil {
+				sweep.Err = err
+			}
+			return sweep
+		}
+
+		docs := results.Elem()
+		for i := 0; i < docs.Len(); i++ {
+			model := docs.Index(i).Addr().Interface()
+			id, _ := getModelID(model)
+			if err := expireOne(ctx, model, id, opt, &sweep); err != nil {
+				failed[id] = true
+				fail(err)
+			}
+		}
+		if int64(docs.Len()) < batch {
+			return sweep
+		}
+	}
+	return sweep
+}
+
+// expireOne archives model if configured, then deletes it.
+func expireOne(ctx context.Context, model interface{}, id bson.ObjectID, opt ExpiryOptions, sweep *ExpirySweep) error {
+	if opt.Archive != "" {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		_, err = namedCollection(db, opt.Archive).ReplaceOne(ctx, bson.D{{Key: "_id", Value: id}}, model,
+			withComment(ctx, options.Replace()).SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("goodm: failed to archive %s: %w", id.Hex(), err)
+		}
+		sweep.Archived++
+	}
+
+	err := Delete(ctx, model, DeleteOptions{DB: opt.DB})
+	if errors.Is(err, ErrNotFound) {
+		return nil // deleted concurrently
+	}
+	if err != nil {
+		return err
+	}
+	sweep.Deleted++
+	return nil
+}
@@ -0,0 +1,154 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var errKeepSession = errors.New("session is pinned")
+
+type testExpiring struct {
+	Model     `bson:",inline"`
+	Token     string    `bson:"token"`
+	Secret    string    `bson:"secret" goodm:"select=false"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+var expiredTokens []string
+
+func (s *testExpiring) BeforeDelete(ctx context.Context) error {
+	if s.Token == "pinned" {
+		return errKeepSession
+	}
+	return nil
+}
+
+func (s *testExpiring) AfterDelete(ctx context.Context) error {
+	expiredTokens = append(expiredTokens, s.Token)
+	return nil
+}
+
+func registerExpiring(t *testing.T) {
+	t.Helper()
+	useTestStore(t)
+	if err := Register(&testExpiring{}, "test_expiring"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	expiredTokens = nil
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testExpiring")
+		registryMu.Unlock()
+	})
+}
+
+func seedExpiring(t *testing.T, tokens ...string) {
+	t.Helper()
+	for _, tok := range tokens {
+		expires := time.Now().Add(-time.Hour)
+		if strings.HasPrefix(tok, "live") {
+			expires = time.Now().Add(time.Hour)
+		}
+		if err := Create(context.Background(), &testExpiring{Token: tok, Secret: "s-" + tok, ExpiresAt: expires}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	registerExpiring(t)
+	seedExpiring(t, "a", "b", "c", "d", "e", "live")
+	ctx := context.Background()
+
+	var reported *ExpirySweep
+	sweep, err := DeleteExpired(ctx, &testExpiring{}, "expires_at", ExpiryOptions{
+		BatchSize: 2,
+		Archive:   "test_expiring_archive",
+		OnSweep:   func(s ExpirySweep) { reported = &s },
+	})
+	if err != nil {
+		t.Fatalf("DeleteExpired: %v", err)
+	}
+	if sweep.Deleted != 5 || sweep.Archived != 5 || sweep.Failed != 0 {
+		t.Errorf("unexpected sweep: %+v", sweep)
+	}
+	if reported == nil || reported.Deleted != 5 {
+		t.Errorf("OnSweep not called with the sweep: %+v", reported)
+	}
+	if len(expiredTokens) != 5 {
+		t.Errorf("AfterDelete ran for %v, want 5 sessions", expiredTokens)
+	}
+
+	var left []testExpiring
+	if err := Find(ctx, bson.D{}, &left); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(left) != 1 || left[0].Token != "live" {
+		t.Errorf("expected only the live session to remain, got %+v", left)
+	}
+
+	var archived bson.M
+	if err := activeTestStore().Collection("test_expiring_archive").FindOne(ctx, bson.D{{Key: "token", Value: "a"}}).Decode(&archived); err != nil {
+		t.Fatalf("archived document not found: %v", err)
+	}
+	if archived["secret"] != "s-a" {
+		t.Errorf("archive lost the hidden field: %v", archived)
+	}
+}
+
+func TestDeleteExpired_HookFailure(t *testing.T) {
+	registerExpiring(t)
+	seedExpiring(t, "a", "pinned", "b")
+
+	sweep, err := DeleteExpired(context.Background(), &testExpiring{}, "expires_at", ExpiryOptions{BatchSize: 1})
+	if err != nil {
+		t.Fatalf("DeleteExpired: %v", err)
+	}
+	if sweep.Deleted != 2 || sweep.Failed != 1 || !errors.Is(sweep.Err, errKeepSession) {
+		t.Errorf("unexpected sweep: %+v", sweep)
+	}
+}
+
+func TestDeleteExpired_Validation(t *testing.T) {
+	registerExpiring(t)
+	ctx := context.Background()
+	if _, err := DeleteExpired(ctx, &testExpiring{}, "expires"); err == nil || !strings.Contains(err.Error(), `no field "expires"`) {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+	if _, err := DeleteExpired(ctx, &testExpiring{}, "token"); err == nil || !strings.Contains(err.Error(), "time.Time") {
+		t.Errorf("expected time type error, got %v", err)
+	}
+	if _, err := StartExpiryWorker(ctx, &testExpiring{}, "expires_at", 0); err == nil {
+		t.Error("expected an interval error")
+	}
+}
+
+func TestStartExpiryWorker(t *testing.T) {
+	registerExpiring(t)
+	seedExpiring(t, "a", "live")
+
+	sweeps := make(chan ExpirySweep, 10)
+	w, err := StartExpiryWorker(context.Background(), &testExpiring{}, "expires_at", time.Hour, ExpiryOptions{
+		OnSweep: func(s ExpirySweep) { sweeps <- s },
+	})
+	if err != nil {
+		t.Fatalf("StartExpiryWorker: %v", err)
+	}
+
+	select {
+	case s := <-sweeps:
+		if s.Deleted != 1 {
+			t.Errorf("first sweep deleted %d, want 1", s.Deleted)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not sweep on start")
+	}
+	if err := w.Stop(); err != nil {
+		t.Errorf("Stop: %v", err)
+	}
+}
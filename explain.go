@@ -0,0 +1,177 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dwoolworth/goodm/internal/memstore"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ExplainResult summarizes the execution of a find query as reported by the
+// server's explain command in "executionStats" mode.
+type ExplainResult struct {
+	Stages         []string      // winning plan stages, outermost first (e.g. FETCH, IXSCAN)
+	IndexName      string        // index used by the winning plan, if any
+	CollectionScan bool          // true if the winning plan contains a COLLSCAN stage
+	KeysExamined   int64         // index keys scanned
+	DocsExamined   int64         // documents scanned
+	Returned       int64         // documents returned
+	Duration       time.Duration // server-side execution time
+	Raw            bson.Raw      // full explain output
+
+	// RegexWarnings lists the filter's regexes that prevent efficient index
+	// use (see CheckRegexes). Such a query may scan every key of an index
+	// without showing a collection scan.
+	RegexWarnings []RegexWarning
+}
+
+// Explain runs the find query that Find would issue for filter and opts
+// through the explain command and returns its execution statistics. The model
+// parameter is used only for schema/collection lookup (e.g. &User{}).
+//
+// Example:
+//
+//	res, err := goodm.Explain(ctx, bson.D{{Key: "email", Value: email}}, &User{})
+//	if res.CollectionScan {
+//	    log.Printf("missing index: examined %d docs", res.DocsExamined)
+//	}
+func Explain(ctx context.Context, filter interface{}, model interface{}, opts ...FindOptions) (*ExplainResult, error) {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var opt FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	if db.mem != nil {
+		return nil, fmt.Errorf("goodm: explain failed: %w", memstore.ErrUnsupported)
+	}
+
+	if filter, err = coerceFilter(schema, filter); err != nil {
+		return nil, err
+	}
+	filter = scopeFilter(ctx, schema, filter)
+	if filter == nil {
+		filter = bson.D{}
+	}
+	find := bson.D{
+		{Key: "find", Value: schema.Collection},
+		{Key: "filter", Value: filter},
+	}
+	if opt.Sort != nil {
+		find = append(find, bson.E{Key: "sort", Value: opt.Sort})
+	}
+	if opt.Collation != nil {
+		find = append(find, bson.E{Key: "collation", Value: collationDoc(opt.Collation)})
+	}
+	if opt.Skip > 0 {
+		find = append(find, bson.E{Key: "skip", Value: opt.Skip})
+	}
+	if opt.Limit > 0 {
+		find = append(find, bson.E{Key: "limit", Value: opt.Limit})
+	}
+
+	raw, err := db.RunCommand(ctx, bson.D{
+		{Key: "explain", Value: find},
+		{Key: "verbosity", Value: "executionStats"},
+	}).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("goodm: explain failed: %w", err)
+	}
+	res := parseExplain(raw)
+	res.RegexWarnings = CheckRegexes(filter)
+	return res, nil
+}
+
+// parseExplain extracts the winning plan and execution statistics from the
+// output of the explain command.
+func parseExplain(raw bson.Raw) *ExplainResult {
+	res := &ExplainResult{Raw: raw}
+
+	plan, ok := raw.Lookup("queryPlanner", "winningPlan").DocumentOK()
+	if ok {
+		// Slot-based execution nests the classic plan under queryPlan.
+		if qp, ok := plan.Lookup("queryPlan").DocumentOK(); ok {
+			plan = qp
+		}
+		walkPlan(plan, res)
+	}
+
+	if stats, ok := raw.Lookup("executionStats").DocumentOK(); ok {
+		res.KeysExamined, _ = stats.Lookup("totalKeysExamined").AsInt64OK()
+		res.DocsExamined, _ = stats.Lookup("totalDocsExamined").AsInt64OK()
+		res.Returned, _ = stats.Lookup("nReturned").AsInt64OK()
+		ms, _ := stats.Lookup("executionTimeMillis").AsInt64OK()
+		res.Duration = time.Duration(ms) * time.Millisecond
+	}
+	return res
+}
+
+// walkPlan records the stages of a plan tree, depth first.
+func walkPlan(stage bson.Raw, res *ExplainResult) {
+	if name, ok := stage.Lookup("stage").StringValueOK(); ok {
+		res.Stages = append(res.Stages, name)
+		if name == "COLLSCAN" {
+			res.CollectionScan = true
+		}
+	}
+	if idx, ok := stage.Lookup("indexName").StringValueOK(); ok && res.IndexName == "" {
+		res.IndexName = idx
+	}
+	if input, ok := stage.Lookup("inputStage").DocumentOK(); ok {
+		walkPlan(input, res)
+	}
+	if inputs, ok := stage.Lookup("inputStages").ArrayOK(); ok {
+		values, _ := inputs.Values()
+		for _, v := range values {
+			if doc, ok := v.DocumentOK(); ok {
+				walkPlan(doc, res)
+			}
+		}
+	}
+}
+
+// ExplainTB is the subset of testing.TB used by ExplainAll.
+type ExplainTB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// ExplainAll explains each filter against the model's collection and reports a
+// test failure for any query whose winning plan is a collection scan, or whose
+// regexes prevent efficient index use. Use it in tests to guard hot queries
+// against missing indexes.
+//
+// Example:
+//
+//	func TestUserQueriesUseIndexes(t *testing.T) {
+//	    goodm.ExplainAll(t, ctx, &User{},
+//	        bson.D{{Key: "email", Value: "a@b.c"}},
+//	        bson.D{{Key: "role", Value: "admin"}},
+//	    )
+//	}
+func ExplainAll(t ExplainTB, ctx context.Context, model interface{}, filters ...interface{}) {
+	t.Helper()
+	for _, filter := range filters {
+		res, err := Explain(ctx, filter, model)
+		if err != nil {
+			t.Errorf("goodm: explain %v: %v", filter, err)
+			continue
+		}
+		if res.CollectionScan {
+			t.Errorf("goodm: query %v uses a collection scan (examined %d docs)", filter, res.DocsExamined)
+		}
+		for _, w := range res.RegexWarnings {
+			t.Errorf("goodm: query %v: %s", filter, w)
+		}
+	}
+}
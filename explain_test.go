@@ -0,0 +1,101 @@
+package goodm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestParseExplain(t *testing.T) {
+	raw, _ := bson.Marshal(bson.D{
+		{Key: "queryPlanner", Value: bson.D{
+			{Key: "winningPlan", Value: bson.D{
+				{Key: "stage", Value: "FETCH"},
+				{Key: "inputStage", Value: bson.D{
+					{Key: "stage", Value: "IXSCAN"},
+					{Key: "indexName", Value: "email_1"},
+				}},
+			}},
+		}},
+		{Key: "executionStats", Value: bson.D{
+			{Key: "nReturned", Value: int32(1)},
+			{Key: "executionTimeMillis", Value: int32(3)},
+			{Key: "totalKeysExamined", Value: int32(1)},
+			{Key: "totalDocsExamined", Value: int64(1)},
+		}},
+	})
+
+	res := parseExplain(raw)
+	if len(res.Stages) != 2 || res.Stages[0] != "FETCH" || res.Stages[1] != "IXSCAN" {
+		t.Fatalf("unexpected stages: %v", res.Stages)
+	}
+	if res.IndexName != "email_1" || res.CollectionScan {
+		t.Fatalf("expected index email_1 without collscan, got %+v", res)
+	}
+	if res.KeysExamined != 1 || res.DocsExamined != 1 || res.Returned != 1 {
+		t.Fatalf("unexpected counts: %+v", res)
+	}
+	if res.Duration != 3*time.Millisecond {
+		t.Fatalf("expected 3ms, got %v", res.Duration)
+	}
+}
+
+func TestParseExplain_SlotBasedCollScan(t *testing.T) {
+	raw, _ := bson.Marshal(bson.D{
+		{Key: "queryPlanner", Value: bson.D{
+			{Key: "winningPlan", Value: bson.D{
+				{Key: "queryPlan", Value: bson.D{{Key: "stage", Value: "COLLSCAN"}}},
+			}},
+		}},
+	})
+
+	res := parseExplain(raw)
+	if !res.CollectionScan || res.IndexName != "" {
+		t.Fatalf("expected collection scan, got %+v", res)
+	}
+}
+
+type recordingTB struct {
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestExplain_Integration(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := Enforce(ctx, db); err != nil {
+		t.Fatalf("enforce: %v", err)
+	}
+	if err := Create(ctx, &testUser{Email: "explain@test.com", Name: "Explain", Role: "user"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	res, err := Explain(ctx, bson.D{{Key: "email", Value: "explain@test.com"}}, &testUser{})
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if res.CollectionScan || res.IndexName == "" {
+		t.Fatalf("expected index scan on email, got %+v", res)
+	}
+	if res.Returned != 1 {
+		t.Fatalf("expected 1 returned, got %d", res.Returned)
+	}
+
+	rec := &recordingTB{}
+	ExplainAll(rec, ctx, &testUser{},
+		bson.D{{Key: "email", Value: "explain@test.com"}},
+		bson.D{{Key: "age", Value: 30}},
+	)
+	if len(rec.errors) != 1 {
+		t.Fatalf("expected 1 collection scan failure, got %v", rec.errors)
+	}
+}
@@ -0,0 +1,164 @@
+package goodm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+)
+
+// ExportOptions configures the Export operation.
+type ExportOptions struct {
+	DB *mongo.Database
+
+	// Transaction reads inside a transaction with snapshot read concern
+	// instead of a snapshot session, for servers older than MongoDB 5.0.
+	// Transactions are limited to the server's transactionLifetimeLimitSeconds
+	// (60 seconds by default).
+	Transaction bool
+}
+
+// ExportRecord is one line of Export's output.
+type ExportRecord struct {
+	Collection string   `bson:"collection"`
+	Document   bson.Raw `bson:"document"`
+}
+
+// Export writes every document of the collections of models to w, reading
+// them all at a single point in time, so an export of several collections
+// is mutually consistent: an order written together with its line items is
+// either exported with them or not at all. Reads use a snapshot session, or
+// a snapshot transaction with opts.Transaction. If ctx already carries a
+// session, Export reads in it instead.
+//
+// Each document is written as one line of canonical Extended JSON, of the
+// form {"collection": "orders", "document": {...}}, which ReadExport parses.
+// Collections are exported in the order their models are given, each sorted
+// by _id; models sharing a collection export it once.
+//
+// Snapshot reads are only as old as the server's snapshot history window
+// (minSnapshotHistoryWindowInSeconds, 5 minutes by default) allows, so very
+// large exports may need it raised.
+//
+// Example:
+//
+//	f, _ := os.Create("backup.jsonl")
+//	defer f.Close()
+//	err := goodm.Export(ctx, []interface{}{&Customer{}, &Order{}, &OrderItem{}}, f)
+func Export(ctx context.Context, models []interface{}, w io.Writer, opts ...ExportOptions) error {
+	var opt ExportOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var collections []string
+	seen := map[string]bool{}
+	for _, model := range models {
+		schema, err := getSchemaForModel(model)
+		if err != nil {
+			return err
+		}
+		if !seen[schema.Collection] {
+			seen[schema.Collection] = true
+			collections = append(collections, schema.Collection)
+		}
+	}
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return err
+	}
+
+	export := func(ctx context.Context) error {
+		for _, name := range collections {
+			if err := exportCollection(ctx, db, name, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if db.mem != nil || mongo.SessionFromContext(ctx) != nil {
+		return export(ctx)
+	}
+	client := db.Client()
+	if client == nil {
+		return ErrNoDatabase
+	}
+
+	if opt.Transaction {
+		session, err := client.StartSession()
+		if err != nil {
+			return fmt.Errorf("goodm: failed to start session: %w", err)
+		}
+		defer session.EndSession(ctx)
+		txOpts := options.Transaction().SetReadConcern(readconcern.Snapshot())
+		_, err = session.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+			return nil, export(ctx)
+		}, txOpts)
+		if err != nil {
+			return fmt.Errorf("goodm: export failed: %w", err)
+		}
+		return nil
+	}
+
+	session, err := client.StartSession(options.Session().SetSnapshot(true))
+	if err != nil {
+		return fmt.Errorf("goodm: failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+	return export(mongo.NewSessionContext(ctx, session))
+}
+
+// exportCollection writes the documents of one collection to w.
+func exportCollection(ctx context.Context, db dbHandle, name string, w io.Writer) error {
+	cur, err := namedCollection(db, name).Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("goodm: failed to export %s: %w", name, err)
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		line, err := bson.MarshalExtJSON(ExportRecord{Collection: name, Document: cur.Current}, true, false)
+		if err != nil {
+			return fmt.Errorf("goodm: failed to encode %s document: %w", name, err)
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("goodm: failed to write export: %w", err)
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("goodm: failed to export %s: %w", name, err)
+	}
+	return nil
+}
+
+// ReadExport parses the output of Export, calling fn with each record in
+// order. It stops at the first error fn returns.
+//
+// Example:
+//
+//	err := goodm.ReadExport(f, func(rec goodm.ExportRecord) error {
+//	    _, err := db.Collection(rec.Collection).InsertOne(ctx, rec.Document)
+//	    return err
+//	})
+func ReadExport(r io.Reader, fn func(ExportRecord) error) error {
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("goodm: failed to read export record %d: %w", line, err)
+		}
+		var rec ExportRecord
+		if err := bson.UnmarshalExtJSON(raw, true, &rec); err != nil {
+			return fmt.Errorf("goodm: failed to decode export record %d: %w", line, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
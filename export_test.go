@@ -0,0 +1,71 @@
+package goodm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	ctx := useTestStore(t)
+	users := []*testUser{
+		{Email: "b@test.com", Name: "B", Age: 2},
+		{Email: "a@test.com", Name: "A", Age: 1},
+	}
+	for _, u := range users {
+		if err := Create(ctx, u); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	order := &testOrder{Name: "o1", Address: testAddress{Street: "Main", City: "Oslo"}}
+	if err := Create(ctx, order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Export(ctx, []interface{}{&testUser{}, &testOrder{}, &testUser{}}, &buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 3 {
+		t.Fatalf("expected 3 lines, got %d:\n%s", n, buf.String())
+	}
+	if !strings.Contains(buf.String(), `"$oid"`) {
+		t.Errorf("output is not canonical Extended JSON:\n%s", buf.String())
+	}
+
+	var got []ExportRecord
+	err := ReadExport(&buf, func(rec ExportRecord) error {
+		got = append(got, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadExport: %v", err)
+	}
+	if got[0].Collection != "test_users" || got[1].Collection != "test_users" || got[2].Collection != "test_orders" {
+		t.Fatalf("unexpected collections: %s, %s, %s", got[0].Collection, got[1].Collection, got[2].Collection)
+	}
+	var first testUser
+	if err := unmarshalBSON(got[0].Document, &first); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if first.ID != users[0].ID || first.Email != "b@test.com" {
+		t.Errorf("documents not sorted by _id: %+v", first)
+	}
+	if got[2].Document.Lookup("address", "city").StringValue() != "Oslo" {
+		t.Errorf("nested field lost: %s", got[2].Document)
+	}
+}
+
+func TestExport_Errors(t *testing.T) {
+	ctx := useTestStore(t)
+	type unregistered struct{ Model }
+	var buf bytes.Buffer
+	if err := Export(ctx, []interface{}{&unregistered{}}, &buf); err == nil {
+		t.Error("expected an error for an unregistered model")
+	}
+
+	err := ReadExport(strings.NewReader(`{"collection": "x", "document": {"a": 1}}`+"\n{bad"), func(ExportRecord) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "record 2") {
+		t.Errorf("expected an error naming record 2, got %v", err)
+	}
+}
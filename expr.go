@@ -0,0 +1,51 @@
+package goodm
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// Expr returns a filter matching documents for which the aggregation
+// expression is true, for comparisons the query language cannot express,
+// such as those involving computed values. Field paths are written "$field".
+//
+// Example:
+//
+//	// Projects that spent more than 90% of their budget
+//	filter := goodm.Expr(bson.D{{Key: "$gt", Value: bson.A{
+//	    "$spent", bson.D{{Key: "$multiply", Value: bson.A{"$budget", 0.9}}},
+//	}}})
+func Expr(expression interface{}) bson.D {
+	return bson.D{{Key: "$expr", Value: expression}}
+}
+
+// FieldEq returns a filter matching documents whose field a equals their
+// field b. a and b are bson field names, dotted for subdocuments.
+func FieldEq(a, b string) bson.D { return compareFields("$eq", a, b) }
+
+// FieldNe returns a filter matching documents whose field a differs from
+// their field b.
+func FieldNe(a, b string) bson.D { return compareFields("$ne", a, b) }
+
+// FieldGt returns a filter matching documents whose field a is greater than
+// their field b.
+//
+// Example:
+//
+//	var over []Project
+//	err := goodm.Find(ctx, goodm.FieldGt("spent", "budget"), &over)
+func FieldGt(a, b string) bson.D { return compareFields("$gt", a, b) }
+
+// FieldGte returns a filter matching documents whose field a is greater than
+// or equal to their field b.
+func FieldGte(a, b string) bson.D { return compareFields("$gte", a, b) }
+
+// FieldLt returns a filter matching documents whose field a is less than
+// their field b.
+func FieldLt(a, b string) bson.D { return compareFields("$lt", a, b) }
+
+// FieldLte returns a filter matching documents whose field a is less than or
+// equal to their field b.
+func FieldLte(a, b string) bson.D { return compareFields("$lte", a, b) }
+
+// compareFields builds the $expr filter comparing fields a and b with op.
+func compareFields(op, a, b string) bson.D {
+	return Expr(bson.D{{Key: op, Value: bson.A{"$" + a, "$" + b}}})
+}
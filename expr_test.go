@@ -0,0 +1,74 @@
+package goodm
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testProject struct {
+	Model  `bson:",inline"`
+	Name   string `bson:"name"`
+	Spent  int    `bson:"spent"`
+	Budget int    `bson:"budget"`
+}
+
+func TestFieldComparisons(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testProject{}, "test_projects"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testProject")
+		registryMu.Unlock()
+	})
+
+	for _, p := range []*testProject{
+		{Name: "over", Spent: 120, Budget: 100},
+		{Name: "close", Spent: 95, Budget: 100},
+		{Name: "even", Spent: 50, Budget: 50},
+		{Name: "under", Spent: 10, Budget: 100},
+	} {
+		if err := Create(ctx, p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	names := func(filter bson.D) []string {
+		t.Helper()
+		var found []testProject
+		if err := Find(ctx, filter, &found); err != nil {
+			t.Fatalf("find %v: %v", filter, err)
+		}
+		var out []string
+		for _, p := range found {
+			out = append(out, p.Name)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	cases := []struct {
+		filter bson.D
+		want   []string
+	}{
+		{FieldGt("spent", "budget"), []string{"over"}},
+		{FieldGte("spent", "budget"), []string{"even", "over"}},
+		{FieldLt("spent", "budget"), []string{"close", "under"}},
+		{FieldLte("spent", "budget"), []string{"close", "even", "under"}},
+		{FieldEq("spent", "budget"), []string{"even"}},
+		{FieldNe("spent", "budget"), []string{"close", "over", "under"}},
+		{Expr(bson.D{{Key: "$gt", Value: bson.A{
+			"$spent", bson.D{{Key: "$multiply", Value: bson.A{"$budget", 0.9}}},
+		}}}), []string{"close", "even", "over"}},
+		{append(bson.D{{Key: "budget", Value: 100}}, FieldLt("spent", "budget")...), []string{"close", "under"}},
+	}
+	for _, tc := range cases {
+		if got := names(tc.filter); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: expected %v, got %v", tc.filter, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Creator persists a new document and returns its ID. *Factory[T] implements
+// it, so factories can be used as associations of other factories.
+type Creator interface {
+	CreateID(ctx context.Context) (bson.ObjectID, error)
+}
+
+// Factory builds valid models for tests. The defaults function fills in
+// attributes and receives a per-factory sequence number starting at 1, which
+// keeps unique fields unique. Overrides passed to Build or Create run after
+// the defaults.
+//
+// Example:
+//
+//	var users = goodm.NewFactory(func(u *User, n int) {
+//	    u.Email = fmt.Sprintf("user%d@example.com", n)
+//	    u.Name = "Test User"
+//	})
+//
+//	var posts = goodm.NewFactory(func(p *Post, n int) {
+//	    p.Title = fmt.Sprintf("Post %d", n)
+//	}).Associate("author", users)
+//
+//	admin, err := users.Create(ctx, func(u *User) { u.Role = "admin" })
+//	post, err := posts.Create(ctx) // also creates its author
+type Factory[T any] struct {
+	defaults     func(m *T, n int)
+	associations []factoryAssociation
+	seq          int64
+}
+
+var (
+	objectIDType      = reflect.TypeOf(bson.ObjectID{})
+	objectIDSliceType = reflect.TypeOf([]bson.ObjectID{})
+)
+
+type factoryAssociation struct {
+	field   string
+	creator Creator
+}
+
+// NewFactory creates a Factory for model type T. defaults may be nil.
+func NewFactory[T any](defaults func(m *T, n int)) *Factory[T] {
+	return &Factory[T]{defaults: defaults}
+}
+
+// Associate makes Create fill the ref field with the bson name field by
+// creating a document with c, unless the field is already set. The field must
+// be a bson.ObjectID or []bson.ObjectID; for slices one document is created.
+func (f *Factory[T]) Associate(field string, c Creator) *Factory[T] {
+	f.associations = append(f.associations, factoryAssociation{field: field, creator: c})
+	return f
+}
+
+// Build returns a new model with defaults and overrides applied, without
+// persisting it or its associations.
+func (f *Factory[T]) Build(overrides ...func(*T)) *T {
+	m := new(T)
+	if f.defaults != nil {
+		f.defaults(m, int(atomic.AddInt64(&f.seq, 1)))
+	}
+	for _, o := range overrides {
+		o(m)
+	}
+	return m
+}
+
+// Create builds a model, creates any unset associations, and inserts it with
+// goodm.Create, so defaults, hooks, and validation apply as usual.
+func (f *Factory[T]) Create(ctx context.Context, overrides ...func(*T)) (*T, error) {
+	m := f.Build(overrides...)
+	if err := f.createAssociations(ctx, m); err != nil {
+		return nil, err
+	}
+	if err := Create(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CreateMany creates n models, applying the same overrides to each.
+func (f *Factory[T]) CreateMany(ctx context.Context, n int, overrides ...func(*T)) ([]*T, error) {
+	result := make([]*T, 0, n)
+	for i := 0; i < n; i++ {
+		m, err := f.Create(ctx, overrides...)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// CreateID creates a model and returns its ID.
+func (f *Factory[T]) CreateID(ctx context.Context) (bson.ObjectID, error) {
+	m, err := f.Create(ctx)
+	if err != nil {
+		return bson.ObjectID{}, err
+	}
+	return getModelID(m)
+}
+
+// createAssociations fills zero-valued association fields on m.
+func (f *Factory[T]) createAssociations(ctx context.Context, m *T) error {
+	if len(f.associations) == 0 {
+		return nil
+	}
+	schema, err := getSchemaForModel(m)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(m).Elem()
+
+	for _, a := range f.associations {
+		fs := schema.GetField(a.field)
+		if fs == nil {
+			return fmt.Errorf("goodm: factory association: unknown field %q in %s", a.field, schema.ModelName)
+		}
+		fv := v.FieldByName(fs.Name)
+		if fv.Type() != objectIDType && fv.Type() != objectIDSliceType {
+			return fmt.Errorf("goodm: factory association %q must be bson.ObjectID or []bson.ObjectID, got %s", a.field, fv.Type())
+		}
+		if !fv.IsZero() {
+			continue
+		}
+
+		id, err := a.creator.CreateID(ctx)
+		if err != nil {
+			return fmt.Errorf("goodm: factory association %q: %w", a.field, err)
+		}
+		if fv.Type() == objectIDType {
+			fv.Set(reflect.ValueOf(id))
+		} else {
+			fv.Set(reflect.ValueOf([]bson.ObjectID{id}))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package goodm
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestUserFactory() *Factory[testUser] {
+	return NewFactory(func(u *testUser, n int) {
+		u.Email = fmt.Sprintf("user%d@test.com", n)
+		u.Name = "Factory User"
+		u.Age = 30
+	})
+}
+
+func TestFactory_BuildSequenceAndOverrides(t *testing.T) {
+	users := newTestUserFactory()
+
+	a := users.Build()
+	b := users.Build(func(u *testUser) { u.Age = 99 })
+
+	if a.Email != "user1@test.com" || b.Email != "user2@test.com" {
+		t.Fatalf("expected sequential emails, got %q and %q", a.Email, b.Email)
+	}
+	if a.Age != 30 || b.Age != 99 {
+		t.Fatalf("expected override to apply only to b, got %d and %d", a.Age, b.Age)
+	}
+	if !a.ID.IsZero() {
+		t.Fatal("expected Build not to persist the model")
+	}
+}
+
+func TestFactory_NilDefaults(t *testing.T) {
+	p := NewFactory[testProfile](nil).Build(func(p *testProfile) { p.Bio = "hi" })
+	if p.Bio != "hi" {
+		t.Fatalf("expected override to apply, got %q", p.Bio)
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestFactory_CreateWithAssociations(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	users := newTestUserFactory()
+	tags := NewFactory(func(tg *testTag, n int) { tg.Label = fmt.Sprintf("tag-%d", n) })
+	posts := NewFactory(func(p *testPost, n int) { p.Title = fmt.Sprintf("Post %d", n) }).
+		Associate("author", users).
+		Associate("tags", tags)
+
+	post, err := posts.Create(ctx)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if post.ID.IsZero() || post.AuthorID.IsZero() || len(post.TagIDs) != 1 {
+		t.Fatalf("expected persisted post with associations, got %+v", post)
+	}
+
+	author := &testUser{}
+	if err := FindOne(ctx, map[string]interface{}{"_id": post.AuthorID}, author); err != nil {
+		t.Fatalf("expected author to be created: %v", err)
+	}
+	if author.Role != "user" {
+		t.Fatalf("expected schema defaults to apply, got role %q", author.Role)
+	}
+
+	// An explicitly set association is kept.
+	existing := post.AuthorID
+	second, err := posts.Create(ctx, func(p *testPost) { p.AuthorID = existing })
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if second.AuthorID != existing {
+		t.Fatal("expected explicit author to be kept")
+	}
+
+	many, err := users.CreateMany(ctx, 3)
+	if err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	if len(many) != 3 || many[0].Email == many[2].Email {
+		t.Fatalf("expected 3 distinct users, got %+v", many)
+	}
+}
+
+func TestFactory_AssociationErrors(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	users := newTestUserFactory()
+	bad := NewFactory(func(p *testPost, n int) { p.Title = "x" }).Associate("title", users)
+	if _, err := bad.Create(ctx); err == nil {
+		t.Fatal("expected error for non-ObjectID association field")
+	}
+	unknown := NewFactory(func(p *testPost, n int) { p.Title = "x" }).Associate("nope", users)
+	if _, err := unknown.Create(ctx); err == nil {
+		t.Fatal("expected error for unknown association field")
+	}
+}
@@ -0,0 +1,132 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// DefaultFieldStatsSampleSize is the number of documents FieldStats samples
+// when no sample size is given.
+const DefaultFieldStatsSampleSize = 500
+
+// FieldStatsReport describes how the top-level fields of a collection are
+// used across a sample of its documents.
+type FieldStatsReport struct {
+	Collection string
+	Total      int64 // documents in the collection
+	Sampled    int   // documents sampled
+	Registered bool  // a registered model uses the collection
+	Fields     []FieldUsage
+}
+
+// FieldUsage is the usage of one field in a FieldStatsReport.
+type FieldUsage struct {
+	Field    string
+	Present  int            // sampled documents holding the field
+	Nulls    int            // sampled documents holding it as null
+	Types    map[string]int // inferred Go type of each non-null value, with counts
+	Declared bool           // a registered model of the collection declares it
+}
+
+// Presence returns the fraction of sampled documents holding the field.
+func (u FieldUsage) Presence(sampled int) float64 {
+	if sampled == 0 {
+		return 0
+	}
+	return float64(u.Present) / float64(sampled)
+}
+
+// NullRate returns the fraction of documents holding the field in which it
+// is null.
+func (u FieldUsage) NullRate() float64 {
+	if u.Present == 0 {
+		return 0
+	}
+	return float64(u.Nulls) / float64(u.Present)
+}
+
+// TypeNames returns the observed types, most frequent first.
+func (u FieldUsage) TypeNames() []string {
+	names := make([]string, 0, len(u.Types))
+	for t := range u.Types {
+		names = append(names, t)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if u.Types[names[i]] != u.Types[names[j]] {
+			return u.Types[names[i]] > u.Types[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// FieldStats samples up to sampleSize documents of the named collection
+// (DefaultFieldStatsSampleSize if zero) and reports, for each top-level
+// field, how often it is present and null and which types it holds. Fields
+// are marked Declared when a registered model of the collection declares
+// them, so rarely used drift fields stand out as candidates for pruning.
+// Documents are sampled the way Discover samples them. A nil db uses the
+// global database, or the in-memory store while UseTestStore is active.
+//
+// Example:
+//
+//	report, err := goodm.FieldStats(ctx, db, "users", 1000)
+//	for _, f := range report.Fields {
+//	    if !f.Declared {
+//	        fmt.Printf("%s: in %.1f%% of documents\n", f.Field, 100*f.Presence(report.Sampled))
+//	    }
+//	}
+func FieldStats(ctx context.Context, db *mongo.Database, collection string, sampleSize int) (*FieldStatsReport, error) {
+	if sampleSize <= 0 {
+		sampleSize = DefaultFieldStatsSampleSize
+	}
+	h, err := getDB(db)
+	if err != nil {
+		return nil, err
+	}
+	coll := namedCollection(h, collection)
+
+	report := &FieldStatsReport{Collection: collection}
+	if report.Total, err = coll.CountDocuments(ctx, bson.D{}); err != nil {
+		return nil, fmt.Errorf("goodm: field stats for %s: failed to count documents: %w", collection, err)
+	}
+
+	declared := make(map[string]bool)
+	for _, s := range schemasForCollection(collection) {
+		report.Registered = true
+		for _, f := range s.Fields {
+			declared[f.BSONName] = true
+		}
+	}
+
+	byName := make(map[string]*FieldUsage)
+	var order []string
+	report.Sampled, err = forEachSample(ctx, coll, sampleSize, func(doc bson.D) {
+		for _, e := range doc {
+			u, ok := byName[e.Key]
+			if !ok {
+				u = &FieldUsage{Field: e.Key, Types: make(map[string]int), Declared: declared[e.Key]}
+				byName[e.Key] = u
+				order = append(order, e.Key)
+			}
+			u.Present++
+			if e.Value == nil {
+				u.Nulls++
+				continue
+			}
+			u.Types[inferGoType(e.Value)]++
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("goodm: field stats for %s: %w", collection, err)
+	}
+
+	for _, name := range order {
+		report.Fields = append(report.Fields, *byName[name])
+	}
+	return report, nil
+}
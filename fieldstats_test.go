@@ -0,0 +1,58 @@
+package goodm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestFieldStats(t *testing.T) {
+	ctx := useTestStore(t)
+
+	coll := activeTestStore().Collection("test_users")
+	docs := []bson.D{
+		{{Key: "email", Value: "a@test.com"}, {Key: "age", Value: int32(30)}, {Key: "legacy", Value: "x"}},
+		{{Key: "email", Value: "b@test.com"}, {Key: "age", Value: int64(41)}, {Key: "legacy", Value: nil}},
+		{{Key: "email", Value: "c@test.com"}, {Key: "age", Value: "unknown"}},
+		{{Key: "email", Value: "d@test.com"}, {Key: "seen", Value: time.Now()}},
+	}
+	for _, d := range docs {
+		if _, err := coll.InsertOne(ctx, d); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+
+	report, err := FieldStats(ctx, nil, "test_users", 3)
+	if err != nil {
+		t.Fatalf("FieldStats: %v", err)
+	}
+	if report.Total != 4 || report.Sampled != 3 || !report.Registered {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	byName := make(map[string]FieldUsage)
+	var names []string
+	for _, f := range report.Fields {
+		byName[f.Field] = f
+		names = append(names, f.Field)
+	}
+	if !reflect.DeepEqual(names, []string{"_id", "email", "age", "legacy"}) {
+		t.Fatalf("expected fields in first-seen order, got %v", names)
+	}
+	age := byName["age"]
+	if age.Presence(report.Sampled) != 1 || !age.Declared ||
+		!reflect.DeepEqual(age.TypeNames(), []string{"int32", "int64", "string"}) {
+		t.Errorf("unexpected age usage: %+v", age)
+	}
+	legacy := byName["legacy"]
+	if legacy.Declared || legacy.Present != 2 || legacy.NullRate() != 0.5 {
+		t.Errorf("unexpected legacy usage: %+v", legacy)
+	}
+
+	report, err = FieldStats(ctx, nil, "unmodelled", 0)
+	if err != nil || report.Registered || report.Sampled != 0 || len(report.Fields) != 0 {
+		t.Errorf("expected an empty report for an unknown collection, got %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,174 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UnknownFilterFieldsError reports a query filter naming fields that are not
+// part of the model, which would otherwise silently match nothing.
+type UnknownFilterFieldsError struct {
+	ModelName string
+	Fields    []string // unknown field paths, in filter order
+}
+
+func (e *UnknownFilterFieldsError) Error() string {
+	return fmt.Sprintf("goodm: filter on %s names unknown fields: %s", e.ModelName, strings.Join(e.Fields, ", "))
+}
+
+// StrictFilters returns a MiddlewareFunc that rejects find, update, and
+// delete operations whose filter names a field the model does not declare,
+// returning an *UnknownFilterFieldsError before the query runs.
+//
+// Example:
+//
+//	goodm.Use(goodm.StrictFilters())
+//	err := goodm.Find(ctx, bson.D{{Key: "emial", Value: addr}}, &users)
+//	// goodm: filter on User names unknown fields: emial
+func StrictFilters() MiddlewareFunc {
+	return func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		switch op.Operation {
+		case OpFind, OpUpdate, OpDelete, OpUpdateMany, OpDeleteMany:
+			if schema, ok := Get(op.ModelName); ok {
+				if err := checkFilter(schema, op.Filter); err != nil {
+					return err
+				}
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// CheckFilter reports the fields of filter that model does not declare, as
+// StrictFilters does. It returns nil if every field is known.
+//
+// Dotted paths are followed into subdocuments, and array positions in them
+// are allowed. $and, $or, and $nor clauses, $elemMatch on subdocument arrays,
+// and $not are checked too. Other top-level operators such as $expr are
+// skipped, as are paths below fields without a declared structure, such as
+// maps. For a model sharing a polymorphic collection, fields of every model
+// in the collection are accepted.
+func CheckFilter(model interface{}, filter interface{}) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	return checkFilter(schema, filter)
+}
+
+// checkFilter is CheckFilter for a resolved schema.
+func checkFilter(schema *Schema, filter interface{}) error {
+	doc, err := filterDoc(filter)
+	if err != nil {
+		return fmt.Errorf("goodm: failed to encode filter: %w", err)
+	}
+
+	var unknown []string
+	collectUnknownFilterFields(filterFields(schema), doc, "", &unknown)
+	if len(unknown) > 0 {
+		return &UnknownFilterFieldsError{ModelName: schema.ModelName, Fields: unknown}
+	}
+	return nil
+}
+
+// filterFields returns the fields a filter on schema's collection may name:
+// schema's own, or those of every model in a polymorphic collection.
+func filterFields(schema *Schema) []FieldSchema {
+	if schema.Discriminator == "" {
+		return schema.Fields
+	}
+	var fields []FieldSchema
+	for _, s := range GetAll() {
+		if s.Collection == schema.Collection {
+			fields = append(fields, s.Fields...)
+		}
+	}
+	return fields
+}
+
+// collectUnknownFilterFields appends the paths of doc's fields that are not in
+// fields to unknown, prefixed with prefix.
+func collectUnknownFilterFields(fields []FieldSchema, doc bson.D, prefix string, unknown *[]string) {
+	for _, e := range doc {
+		switch {
+		case e.Key == "$and" || e.Key == "$or" || e.Key == "$nor":
+			if clauses, ok := e.Value.(bson.A); ok {
+				for _, c := range clauses {
+					if cd, ok := c.(bson.D); ok {
+						collectUnknownFilterFields(fields, cd, prefix, unknown)
+					}
+				}
+			}
+		case strings.HasPrefix(e.Key, "$"):
+		default:
+			f, ok := filterField(fields, e.Key)
+			if !ok {
+				*unknown = append(*unknown, prefix+e.Key)
+				continue
+			}
+			collectUnknownOperandFields(f, e.Value, prefix+e.Key+".", unknown)
+		}
+	}
+}
+
+// collectUnknownOperandFields checks the operand of a field match: the
+// subdocument queries of $elemMatch, including under $not.
+func collectUnknownOperandFields(f *FieldSchema, v interface{}, prefix string, unknown *[]string) {
+	ops, ok := v.(bson.D)
+	if !ok || len(ops) == 0 || !strings.HasPrefix(ops[0].Key, "$") {
+		return
+	}
+	for _, op := range ops {
+		switch op.Key {
+		case "$not":
+			collectUnknownOperandFields(f, op.Value, prefix, unknown)
+		case "$elemMatch":
+			query, ok := op.Value.(bson.D)
+			if !ok || f == nil || len(f.SubFields) == 0 {
+				continue
+			}
+			if len(query) > 0 && strings.HasPrefix(query[0].Key, "$") {
+				continue // operators on the elements themselves
+			}
+			collectUnknownFilterFields(f.SubFields, query, prefix, unknown)
+		}
+	}
+}
+
+// filterField resolves a dotted filter path against fields, skipping array
+// positions. It returns the field the path ends at, or nil when the path
+// continues below a field without declared subfields, and false when the
+// path names an unknown field.
+func filterField(fields []FieldSchema, path string) (*FieldSchema, bool) {
+	name, rest, nested := strings.Cut(path, ".")
+	for i := range fields {
+		f := &fields[i]
+		if f.BSONName != name && shadowName(*f) != name {
+			continue
+		}
+		if !nested {
+			return f, true
+		}
+		if len(f.SubFields) == 0 {
+			return nil, true
+		}
+		if pos, after, more := strings.Cut(rest, "."); isArrayPosition(pos) {
+			if !more {
+				return f, true
+			}
+			rest = after
+		}
+		return filterField(f.SubFields, rest)
+	}
+	return nil, false
+}
+
+// isArrayPosition reports whether a path segment is an array index.
+func isArrayPosition(segment string) bool {
+	_, err := strconv.Atoi(segment)
+	return err == nil
+}
@@ -0,0 +1,109 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestCheckFilter_KnownFields(t *testing.T) {
+	useTestStore(t)
+
+	for _, filter := range []interface{}{
+		nil,
+		bson.D{},
+		bson.D{{Key: "_id", Value: bson.NewObjectID()}, {Key: "name", Value: "a"}},
+		bson.M{"address.city": "Oslo"},
+		bson.D{{Key: "items.0.name", Value: "pen"}},
+		bson.D{{Key: "items.0", Value: bson.D{{Key: "name", Value: "pen"}}}},
+		bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "name", Value: "a"}},
+			bson.D{{Key: "created_at", Value: bson.D{{Key: "$gt", Value: 1}}}},
+		}}},
+		bson.D{{Key: "items", Value: bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "quantity", Value: bson.D{{Key: "$gt", Value: 1}}}}}}}},
+		bson.D{{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$whatever", 1}}}}},
+	} {
+		if err := CheckFilter(&testOrder{}, filter); err != nil {
+			t.Fatalf("unexpected error for %v: %v", filter, err)
+		}
+	}
+}
+
+func TestCheckFilter_UnknownFields(t *testing.T) {
+	useTestStore(t)
+
+	filter := bson.D{
+		{Key: "nmae", Value: "a"},
+		{Key: "address.cty", Value: "Oslo"},
+		{Key: "$and", Value: bson.A{bson.D{{Key: "items.0.nam", Value: "pen"}}}},
+		{Key: "items", Value: bson.D{{Key: "$not", Value: bson.D{
+			{Key: "$elemMatch", Value: bson.D{{Key: "qty", Value: 0}}},
+		}}}},
+	}
+	err := CheckFilter(&testOrder{}, filter)
+	var ufe *UnknownFilterFieldsError
+	if !errors.As(err, &ufe) {
+		t.Fatalf("expected *UnknownFilterFieldsError, got %v", err)
+	}
+	want := []string{"nmae", "address.cty", "items.0.nam", "items.qty"}
+	if ufe.ModelName != "testOrder" || !reflect.DeepEqual(ufe.Fields, want) {
+		t.Fatalf("unexpected error: %+v", ufe)
+	}
+}
+
+func TestStrictFilters_Middleware(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	defer ClearMiddleware()
+	Use(StrictFilters())
+
+	if err := Create(ctx, &testUser{Email: "strict@test.com", Name: "Strict"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var users []testUser
+	if err := Find(ctx, bson.D{{Key: "email", Value: "strict@test.com"}}, &users); err != nil || len(users) != 1 {
+		t.Fatalf("expected 1 user, got %d, %v", len(users), err)
+	}
+
+	var ufe *UnknownFilterFieldsError
+	if err := Find(ctx, bson.D{{Key: "emial", Value: "strict@test.com"}}, &users); !errors.As(err, &ufe) {
+		t.Fatalf("expected unknown filter field error from Find, got %v", err)
+	}
+	if _, err := DeleteMany(ctx, bson.D{{Key: "rol", Value: "user"}}, &testUser{}); !errors.As(err, &ufe) {
+		t.Fatalf("expected unknown filter field error from DeleteMany, got %v", err)
+	}
+	if err := Find(ctx, bson.D{}, &users); err != nil || len(users) != 1 {
+		t.Fatalf("expected rejected DeleteMany to leave 1 user, got %d, %v", len(users), err)
+	}
+}
+
+func TestStrictFilters_SkipsOtherOperations(t *testing.T) {
+	useTestStore(t)
+
+	mw := StrictFilters()
+	next := func(context.Context) error { return nil }
+	op := &OpInfo{Operation: OpFind, ModelName: "testUser", Filter: bson.D{{Key: "bio", Value: "x"}}}
+	if err := mw(context.Background(), op, next); err == nil {
+		t.Fatal("expected unknown field error for find")
+	}
+	op.Operation = OpAggregate
+	if err := mw(context.Background(), op, next); err != nil {
+		t.Fatalf("expected aggregate to pass through, got %v", err)
+	}
+}
+
+func TestCheckFilter_PolymorphicCollection(t *testing.T) {
+	useTestStore(t)
+	defer registerPaymentModels(t)()
+
+	if err := CheckFilter(&testCardPayment{}, bson.D{{Key: "kind", Value: "bank"}, {Key: "iban", Value: "NO1"}}); err != nil {
+		t.Fatalf("expected fields of other kinds in the collection to be known, got %v", err)
+	}
+	if err := CheckFilter(&testCardPayment{}, bson.D{{Key: "swift", Value: "x"}}); err == nil {
+		t.Fatal("expected unknown field error")
+	}
+}
@@ -0,0 +1,239 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// FindOneAndUpdate atomically applies update to the first document matching
+// filter and decodes the updated document into result, which also selects
+// the model (e.g. &Job{}). Unlike Update, which reads and then replaces the
+// whole document, the change is made in a single server-side operation, so
+// concurrent callers can safely claim queue entries or bump counters.
+//
+// __v is incremented and updated_at set as part of the update. AfterSave
+// runs on the updated document after it is decoded. BeforeSave does not run:
+// result holds no document before the update, and changes a hook made to it
+// would not reach the update. Like UpdateOne, the update bypasses validation
+// and immutable field enforcement. It returns ErrNotFound if no document
+// matches.
+//
+// With WithVersionCheck, the update only applies while the document's __v
+// matches result's Version, and ErrVersionConflict is returned otherwise.
+// UpdateOptions.ArrayFilters, Upsert, and Collation are passed to the driver.
+//
+// Example:
+//
+//	var job Job
+//	err := goodm.FindOneAndUpdate(ctx,
+//	    bson.D{{Key: "status", Value: "queued"}},
+//	    bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "running"}}}},
+//	    &job)
+func FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, result interface{}, opts ...UpdateOptions) error {
+	schema, err := getSchemaForModel(result)
+	if err != nil {
+		return err
+	}
+
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+
+	var opt UpdateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Upsert && opt.CheckVersion {
+		return fmt.Errorf("goodm: find one and update: Upsert cannot be combined with CheckVersion")
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpUpdate, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: result, Filter: filter,
+		Result: result, filterable: true,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+
+		keys := schema.base()
+		versioned, err := addVersionInc(update, keys.version)
+		if err != nil {
+			return err
+		}
+		versioned = addUpdatedAtSet(versioned, keys.updatedAt, clockNow())
+
+		scoped := scopeFilter(ctx, schema, filter)
+		if opt.CheckVersion {
+			version, err := getModelVersion(result)
+			if err != nil {
+				return err
+			}
+			scoped = withVersionClause(scoped, keys.version, version)
+		}
+
+		fo := withComment(ctx, options.FindOneAndUpdate()).SetReturnDocument(options.After)
+		if opt.ArrayFilters != nil {
+			fo.SetArrayFilters(opt.ArrayFilters)
+		}
+		if opt.Upsert {
+			fo.SetUpsert(true)
+		}
+		if opt.Collation != nil {
+			fo.SetCollation(opt.Collation)
+		}
+		if proj := hiddenProjection(schema, false); proj != nil {
+			fo.SetProjection(proj)
+		}
+
+		coll := getCollection(db, schema)
+		if err := coll.FindOneAndUpdate(ctx, scoped, versioned, fo).Decode(result); err != nil {
+			if err != mongo.ErrNoDocuments {
+				if verrs := uniqueWithErrors(schema, err); verrs != nil {
+					return verrs
+				}
+				return fmt.Errorf("goodm: find one and update failed: %w", err)
+			}
+			if !opt.CheckVersion {
+				return ErrNotFound
+			}
+			count, err := coll.CountDocuments(ctx, scopeFilter(ctx, schema, filter))
+			if err != nil {
+				return fmt.Errorf("goodm: find one and update failed: %w", err)
+			}
+			if count == 0 {
+				return ErrNotFound
+			}
+			return ErrVersionConflict
+		}
+		if err := afterLoad(ctx, result); err != nil {
+			return err
+		}
+
+		// AfterSave hook
+		if hook, ok := result.(AfterSave); ok {
+			if err := hook.AfterSave(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// addUpdatedAtSet adds key, the model's updated_at key, to the $set of an
+// update document, unless the update already sets it.
+func addUpdatedAtSet(doc bson.D, key string, now time.Time) bson.D {
+	for i, e := range doc {
+		if e.Key != "$set" {
+			continue
+		}
+		set, ok := e.Value.(bson.D)
+		if !ok {
+			return doc
+		}
+		for _, se := range set {
+			if se.Key == key {
+				return doc
+			}
+		}
+		doc[i].Value = append(set, bson.E{Key: key, Value: now})
+		return doc
+	}
+	return append(doc, bson.E{Key: "$set", Value: bson.D{{Key: key, Value: now}}})
+}
+
+// FindOneAndDelete atomically removes the first document matching filter and
+// decodes it into result, which also selects the model (e.g. &Job{}). Unlike
+// FindOne followed by Delete, no other caller can read and remove the same
+// document in between, so workers can claim and remove queue entries.
+//
+// BeforeDelete and AfterDelete run, in that order, on the decoded document
+// once it has been removed, so BeforeDelete cannot prevent the removal: an
+// error it returns is returned, and undoes the removal only inside
+// WithTransaction. The ondelete rules of the model's relations are not
+// applied. For models embedding SoftDeleteModel, the document is marked
+// deleted instead of removed. It returns ErrNotFound if no document matches.
+//
+// Example:
+//
+//	var job Job
+//	err := goodm.FindOneAndDelete(ctx, bson.D{{Key: "status", Value: "queued"}}, &job)
+func FindOneAndDelete(ctx context.Context, filter interface{}, result interface{}, opts ...DeleteOptions) error {
+	schema, err := getSchemaForModel(result)
+	if err != nil {
+		return err
+	}
+
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpDelete, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: result, Filter: filter,
+		Result: result, filterable: true,
+	}, func(ctx context.Context) error {
+		var opt DeleteOptions
+		if len(opts) > 0 {
+			opt = opts[0]
+		}
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+
+		proj := hiddenProjection(schema, false)
+		coll := writeCollection(db, schema, opt.WriteConcern)
+		scoped := scopeFilter(ctx, schema, filter)
+		var res *mongo.SingleResult
+		if schema.SoftDelete != "" {
+			fo := withComment(ctx, options.FindOneAndUpdate()).SetReturnDocument(options.After)
+			if proj != nil {
+				fo.SetProjection(proj)
+			}
+			res = coll.FindOneAndUpdate(ctx, scoped, softDeleteUpdate(schema, clockNow()), fo)
+		} else {
+			fo := withComment(ctx, options.FindOneAndDelete())
+			if proj != nil {
+				fo.SetProjection(proj)
+			}
+			res = coll.FindOneAndDelete(ctx, scoped, fo)
+		}
+		if err := res.Decode(result); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return ErrNotFound
+			}
+			return fmt.Errorf("goodm: find one and delete failed: %w", err)
+		}
+		if err := afterLoad(ctx, result); err != nil {
+			return err
+		}
+
+		// BeforeDelete hook
+		if hook, ok := result.(BeforeDelete); ok {
+			if err := hook.BeforeDelete(ctx); err != nil {
+				return err
+			}
+		}
+
+		// AfterDelete hook
+		if hook, ok := result.(AfterDelete); ok {
+			if err := hook.AfterDelete(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,183 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testJob struct {
+	Model  `bson:",inline"`
+	Status string   `bson:"status"`
+	Runs   int      `bson:"runs"`
+	Events []string `bson:"-"`
+}
+
+func (j *testJob) BeforeSave(ctx context.Context) error {
+	j.Events = append(j.Events, "before_save")
+	return nil
+}
+
+func (j *testJob) AfterSave(ctx context.Context) error {
+	j.Events = append(j.Events, "after_save:"+j.Status)
+	return nil
+}
+
+func (j *testJob) BeforeDelete(ctx context.Context) error {
+	j.Events = append(j.Events, "before_delete:"+j.Status)
+	return nil
+}
+
+func (j *testJob) AfterDelete(ctx context.Context) error {
+	j.Events = append(j.Events, "after_delete")
+	return nil
+}
+
+func registerTestJob(t *testing.T) {
+	t.Helper()
+	if err := Register(&testJob{}, "jobs"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testJob")
+		registryMu.Unlock()
+	})
+}
+
+func TestFindOneAndUpdate(t *testing.T) {
+	ctx := useTestStore(t)
+	registerTestJob(t)
+
+	queued := &testJob{Status: "queued"}
+	if err := Create(ctx, queued); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var job testJob
+	err := FindOneAndUpdate(ctx,
+		bson.D{{Key: "status", Value: "queued"}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "running"}}}},
+		&job)
+	if err != nil {
+		t.Fatalf("FindOneAndUpdate: %v", err)
+	}
+	if job.ID != queued.ID || job.Status != "running" || job.Version != 1 {
+		t.Errorf("expected the claimed job at version 1, got %+v", job)
+	}
+	if job.UpdatedAt.IsZero() || job.UpdatedAt.Before(queued.UpdatedAt.Truncate(time.Millisecond)) {
+		t.Errorf("expected updated_at to be set, got %v (created %v)", job.UpdatedAt, queued.UpdatedAt)
+	}
+	// BeforeSave would only see the empty result, so just AfterSave runs.
+	if want := []string{"after_save:running"}; !reflect.DeepEqual(job.Events, want) {
+		t.Errorf("expected hooks %v, got %v", want, job.Events)
+	}
+
+	// The job is claimed, so a second claim finds nothing.
+	err = FindOneAndUpdate(ctx,
+		bson.D{{Key: "status", Value: "queued"}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "running"}}}},
+		&testJob{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Concurrent increments are not lost.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: job.ID}},
+				bson.D{{Key: "$inc", Value: bson.D{{Key: "runs", Value: 1}}}}, &testJob{}); err != nil {
+				t.Errorf("increment: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	var final testJob
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: job.ID}}, &final); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if final.Runs != 10 || final.Version != 11 {
+		t.Errorf("expected 10 runs at version 11, got %d runs at version %d", final.Runs, final.Version)
+	}
+
+	// With a version check, a stale result is rejected.
+	err = FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: job.ID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "done"}}}}, &job, WithVersionCheck())
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+	if err := FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: job.ID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "done"}}}}, &final, WithVersionCheck()); err != nil {
+		t.Errorf("expected a current result to update, got %v", err)
+	}
+	if final.Status != "done" || final.Version != 12 {
+		t.Errorf("expected done at version 12, got %+v", final)
+	}
+
+	if err := FindOneAndUpdate(ctx, bson.D{}, bson.D{{Key: "$inc", Value: bson.D{{Key: "__v", Value: 1}}}}, &testJob{}); err == nil {
+		t.Error("expected an update modifying __v to be rejected")
+	}
+}
+
+func TestFindOneAndDelete(t *testing.T) {
+	ctx := useTestStore(t)
+	registerTestJob(t)
+
+	for i := 0; i < 5; i++ {
+		if err := Create(ctx, &testJob{Status: "queued"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	var job testJob
+	if err := FindOneAndDelete(ctx, bson.D{{Key: "status", Value: "queued"}}, &job); err != nil {
+		t.Fatalf("FindOneAndDelete: %v", err)
+	}
+	if job.ID.IsZero() || job.Status != "queued" {
+		t.Errorf("expected the removed job, got %+v", job)
+	}
+	if want := []string{"before_delete:queued", "after_delete"}; !reflect.DeepEqual(job.Events, want) {
+		t.Errorf("expected hooks %v, got %v", want, job.Events)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: job.ID}}, &testJob{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the job to be removed, got %v", err)
+	}
+
+	// Concurrent workers each claim a different job.
+	var mu sync.Mutex
+	claimed := make(map[bson.ObjectID]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var j testJob
+			err := FindOneAndDelete(ctx, bson.D{{Key: "status", Value: "queued"}}, &j)
+			if errors.Is(err, ErrNotFound) {
+				return
+			}
+			if err != nil {
+				t.Errorf("claim: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if claimed[j.ID] {
+				t.Errorf("job %s claimed twice", j.ID.Hex())
+			}
+			claimed[j.ID] = true
+		}()
+	}
+	wg.Wait()
+	if len(claimed) != 4 {
+		t.Errorf("expected the 4 remaining jobs to be claimed, got %d", len(claimed))
+	}
+}
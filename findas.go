@@ -0,0 +1,239 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/dwoolworth/goodm/internal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// subsetChecks caches the result of checking a view struct against a model,
+// keyed by subsetKey.
+var subsetChecks sync.Map
+
+type subsetKey struct {
+	model, view reflect.Type
+}
+
+// subsetCheck is the cached outcome of checkSubset: the bson keys the view reads,
+// or the reason it does not fit the model.
+type subsetCheck struct {
+	keys []string
+	err  error
+}
+
+// FindAs reads documents of model's collection into result, a struct that is
+// not registered itself but declares a subset of model's fields, such as a
+// summary for a list page. Only _id and the view's fields are fetched.
+//
+// result is a pointer to a view struct, which receives the first matching
+// document (ErrNotFound if none), or a pointer to a slice of view structs,
+// which receives every match. Every bson field of the view must exist on model
+// with the same type, a pointer to it, or, for subdocuments, a view struct of
+// its own; otherwise FindAs returns an error before querying. Hidden fields
+// are fetched only with WithHidden.
+//
+// opts apply as for Find, except Strict and Populate, which are ignored.
+//
+// Example:
+//
+//	type UserSummary struct {
+//	    ID    bson.ObjectID `bson:"_id"`
+//	    Name  string        `bson:"name"`
+//	    Email string        `bson:"email"`
+//	}
+//
+//	var summaries []UserSummary
+//	err := goodm.FindAs(ctx, &User{}, bson.D{{Key: "role", Value: "admin"}}, &summaries)
+func FindAs(ctx context.Context, model interface{}, filter interface{}, result interface{}, opts ...FindOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("goodm: FindAs result must be a pointer to a struct or slice, got %T", result)
+	}
+	many := rv.Elem().Kind() == reflect.Slice
+	viewType := rv.Elem().Type()
+	if many {
+		viewType = viewType.Elem()
+	}
+	if viewType.Kind() == reflect.Ptr {
+		viewType = viewType.Elem()
+	}
+	if viewType.Kind() != reflect.Struct {
+		return fmt.Errorf("goodm: FindAs result must be a pointer to a struct or slice, got %T", result)
+	}
+	keys, err := checkSubset(schema, viewType)
+	if err != nil {
+		return err
+	}
+
+	var opt FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpFind, Collection: schema.Collection,
+		ModelName: schema.ModelName, Filter: filter,
+		Result: result, Options: opt,
+		filterable: true,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+
+		proj := subsetProjection(schema, keys, opt.IncludeHidden)
+		coll := getCollection(db, schema)
+		filter := scopeFilter(ctx, schema, filter)
+
+		if !many {
+			findOneOpts := withComment(ctx, options.FindOne()).SetProjection(proj)
+			if opt.Sort != nil {
+				findOneOpts.SetSort(opt.Sort)
+			}
+			if opt.Skip > 0 {
+				findOneOpts.SetSkip(opt.Skip)
+			}
+			if opt.Collation != nil {
+				findOneOpts.SetCollation(opt.Collation)
+			}
+			if err := coll.FindOne(ctx, filter, findOneOpts).Decode(result); err != nil {
+				if err == mongo.ErrNoDocuments {
+					return ErrNotFound
+				}
+				return fmt.Errorf("goodm: find as failed: %w", err)
+			}
+			return afterLoad(ctx, result)
+		}
+
+		findOpts := withComment(ctx, options.Find()).SetProjection(proj)
+		if opt.Limit > 0 {
+			findOpts.SetLimit(opt.Limit)
+		}
+		if opt.Skip > 0 {
+			findOpts.SetSkip(opt.Skip)
+		}
+		if opt.Sort != nil {
+			findOpts.SetSort(opt.Sort)
+		}
+		if opt.Collation != nil {
+			findOpts.SetCollation(opt.Collation)
+		}
+		cursor, err := coll.Find(ctx, filter, findOpts)
+		if err != nil {
+			return fmt.Errorf("goodm: find as failed: %w", err)
+		}
+		defer func() { _ = cursor.Close(ctx) }()
+		if err := cursor.All(ctx, result); err != nil {
+			return fmt.Errorf("goodm: cursor decode failed: %w", err)
+		}
+		return afterLoad(ctx, result)
+	})
+}
+
+// checkSubset verifies that view fits schema's model and returns the top-level
+// bson keys it reads. Results are cached per model and view type.
+func checkSubset(schema *Schema, view reflect.Type) ([]string, error) {
+	key := subsetKey{model: schema.modelType, view: view}
+	if c, ok := subsetChecks.Load(key); ok {
+		c := c.(subsetCheck)
+		return c.keys, c.err
+	}
+
+	var c subsetCheck
+	if schema.modelType == nil {
+		c.err = fmt.Errorf("goodm: FindAs: model %s has no struct type", schema.ModelName)
+	} else {
+		c.err = checkSubsetFields(schema.ModelName, view.Name(), schema.modelType, view)
+	}
+	if c.err == nil {
+		for _, f := range internal.PromotedFields(view) {
+			if k := internal.BSONKey(f.StructField); k != "-" {
+				c.keys = append(c.keys, k)
+			}
+		}
+	}
+	subsetChecks.Store(key, c)
+	return c.keys, c.err
+}
+
+// checkSubsetFields checks that every bson field of view exists on model with a
+// compatible type. path names the view struct in errors.
+func checkSubsetFields(modelName, path string, model, view reflect.Type) error {
+	modelFields := make(map[string]reflect.Type)
+	for _, f := range internal.PromotedFields(model) {
+		modelFields[internal.BSONKey(f.StructField)] = f.Type
+	}
+	for _, f := range internal.PromotedFields(view) {
+		k := internal.BSONKey(f.StructField)
+		if k == "-" {
+			continue
+		}
+		mt, ok := modelFields[k]
+		if !ok {
+			return fmt.Errorf("goodm: FindAs: %s.%s: %s has no field %q", path, f.Name, modelName, k)
+		}
+		if err := checkSubsetType(modelName, path+"."+f.Name, mt, f.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSubsetType checks that a view field of type vt can hold the values of a
+// model field of type mt: the same type or a pointer to it, a basic type of
+// the same kind, or a view struct of a subdocument.
+func checkSubsetType(modelName, path string, mt, vt reflect.Type) error {
+	if mt == vt {
+		return nil
+	}
+	if mt.Kind() == reflect.Ptr {
+		mt = mt.Elem()
+	}
+	if vt.Kind() == reflect.Ptr {
+		vt = vt.Elem()
+	}
+	switch {
+	case mt == vt:
+		return nil
+	case mt.Kind() == reflect.Slice && vt.Kind() == reflect.Slice:
+		return checkSubsetType(modelName, path, mt.Elem(), vt.Elem())
+	case mt.Kind() == reflect.Struct && vt.Kind() == reflect.Struct && !isLeafType(mt) && !isLeafType(vt):
+		return checkSubsetFields(modelName, path, mt, vt)
+	case mt.Kind() == vt.Kind() && (mt.Kind() <= reflect.Complex128 || mt.Kind() == reflect.String):
+		return nil
+	}
+	return fmt.Errorf("goodm: FindAs: %s is %s, but %s stores %s", path, vt, modelName, mt)
+}
+
+// subsetProjection returns the inclusion projection for a view's keys, leaving
+// out hidden fields unless includeHidden is set.
+func subsetProjection(schema *Schema, keys []string, includeHidden bool) bson.D {
+	proj := bson.D{{Key: "_id", Value: 1}}
+	for _, k := range keys {
+		if k == "_id" {
+			continue
+		}
+		if f := schema.GetField(k); f != nil && f.Hidden && !includeHidden {
+			continue
+		}
+		proj = append(proj, bson.E{Key: k, Value: 1})
+	}
+	return proj
+}
@@ -0,0 +1,110 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testUserSummary struct {
+	ID    bson.ObjectID `bson:"_id"`
+	Name  string        `bson:"name"`
+	Email string        `bson:"email"`
+}
+
+type testAccountSummary struct {
+	ID           bson.ObjectID `bson:"_id"`
+	Username     string        `bson:"username"`
+	PasswordHash string        `bson:"password_hash"`
+}
+
+func TestFindAs(t *testing.T) {
+	ctx := useTestStore(t)
+
+	a := &testUser{Email: "a@test.com", Name: "A", Age: 30}
+	b := &testUser{Email: "b@test.com", Name: "B", Age: 40}
+	for _, u := range []*testUser{a, b} {
+		if err := Create(ctx, u); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	var one testUserSummary
+	if err := FindAs(ctx, &testUser{}, bson.D{{Key: "email", Value: "b@test.com"}}, &one); err != nil {
+		t.Fatalf("FindAs single: %v", err)
+	}
+	if one.ID != b.ID || one.Name != "B" || one.Email != "b@test.com" {
+		t.Errorf("unexpected summary: %+v", one)
+	}
+
+	var all []testUserSummary
+	err := FindAs(ctx, &testUser{}, bson.D{}, &all, FindOptions{Sort: bson.D{{Key: "name", Value: -1}}})
+	if err != nil {
+		t.Fatalf("FindAs slice: %v", err)
+	}
+	if len(all) != 2 || all[0].Name != "B" || all[1].Name != "A" {
+		t.Errorf("expected [B A], got %+v", all)
+	}
+
+	err = FindAs(ctx, &testUser{}, bson.D{{Key: "email", Value: "none@test.com"}}, &one)
+	if err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFindAs_ViewMismatch(t *testing.T) {
+	ctx := useTestStore(t)
+
+	var unknown []struct {
+		Nickname string `bson:"nickname"`
+	}
+	err := FindAs(ctx, &testUser{}, bson.D{}, &unknown)
+	if err == nil || !strings.Contains(err.Error(), `no field "nickname"`) {
+		t.Errorf("expected an unknown field error, got %v", err)
+	}
+
+	var mistyped []struct {
+		Age string `bson:"age"`
+	}
+	err = FindAs(ctx, &testUser{}, bson.D{}, &mistyped)
+	if err == nil || !strings.Contains(err.Error(), "Age is string") {
+		t.Errorf("expected a type mismatch error, got %v", err)
+	}
+
+	var widened []struct {
+		Age *int `bson:"age"`
+	}
+	if err := FindAs(ctx, &testUser{}, bson.D{}, &widened); err != nil {
+		t.Errorf("expected a pointer to the model's type to fit, got %v", err)
+	}
+
+	if err := FindAs(ctx, &testUser{}, bson.D{}, testUserSummary{}); err == nil {
+		t.Error("expected an error for a non-pointer result")
+	}
+}
+
+func TestFindAs_HiddenFields(t *testing.T) {
+	ctx := useTestStore(t)
+
+	acct := &testAccount{Username: "ada", PasswordHash: "secret"}
+	if err := Create(ctx, acct); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var s testAccountSummary
+	if err := FindAs(ctx, &testAccount{}, bson.D{}, &s); err != nil {
+		t.Fatalf("FindAs: %v", err)
+	}
+	if s.Username != "ada" || s.PasswordHash != "" {
+		t.Errorf("expected password_hash left out, got %+v", s)
+	}
+
+	s = testAccountSummary{}
+	if err := FindAs(ctx, &testAccount{}, bson.D{}, &s, FindOptions{IncludeHidden: true}); err != nil {
+		t.Fatalf("FindAs with hidden: %v", err)
+	}
+	if s.PasswordHash != "secret" {
+		t.Errorf("expected password_hash with IncludeHidden, got %+v", s)
+	}
+}
@@ -0,0 +1,81 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FindByIDs fetches the documents with the given IDs in one $in query and
+// decodes them into results, a pointer to a slice (e.g. *[]User), in the
+// order of ids. An ID listed twice yields its document twice. IDs with no
+// document are left out of results and returned as missing, in input order;
+// a missing ID is not an error.
+//
+// opts apply as for Find, except Limit, Skip, and Sort, which are ignored.
+// Results are matched to ids by _id, so a projection that drops _id (for
+// example a middleware rewriting the find) is an error rather than reporting
+// every ID as missing.
+//
+// Example:
+//
+//	var authors []User
+//	missing, err := goodm.FindByIDs(ctx, authorIDs, &authors)
+func FindByIDs(ctx context.Context, ids []bson.ObjectID, results interface{}, opts ...FindOptions) ([]bson.ObjectID, error) {
+	rv := reflect.ValueOf(results)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("goodm: results must be a pointer to a slice, got %T", results)
+	}
+	sliceType := rv.Elem().Type()
+
+	var opt FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.Limit, opt.Skip, opt.Sort = 0, 0, nil
+
+	unique := make([]bson.ObjectID, 0, len(ids))
+	seen := make(map[bson.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	found := reflect.New(sliceType)
+	if len(unique) > 0 {
+		filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: unique}}}}
+		if err := Find(ctx, filter, found.Interface(), opt); err != nil {
+			return nil, err
+		}
+	}
+
+	byID := make(map[bson.ObjectID]reflect.Value, found.Elem().Len())
+	for i := 0; i < found.Elem().Len(); i++ {
+		elem := found.Elem().Index(i)
+		id, err := getModelID(elem.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if id.IsZero() {
+			return nil, fmt.Errorf("goodm: FindByIDs result has no _id; the projection must include _id")
+		}
+		byID[id] = elem
+	}
+
+	out := reflect.MakeSlice(sliceType, 0, len(ids))
+	var missing []bson.ObjectID
+	for _, id := range ids {
+		elem, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		out = reflect.Append(out, elem)
+	}
+	rv.Elem().Set(out)
+	return missing, nil
+}
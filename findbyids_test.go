@@ -0,0 +1,73 @@
+package goodm
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestFindByIDs(t *testing.T) {
+	ctx := useTestStore(t)
+
+	a := &testUser{Email: "a@test.com", Name: "A"}
+	b := &testUser{Email: "b@test.com", Name: "B"}
+	c := &testUser{Email: "c@test.com", Name: "C"}
+	for _, u := range []*testUser{a, b, c} {
+		if err := Create(ctx, u); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	gone := bson.NewObjectID()
+
+	var users []testUser
+	missing, err := FindByIDs(ctx, []bson.ObjectID{c.ID, gone, a.ID, c.ID}, &users)
+	if err != nil {
+		t.Fatalf("FindByIDs: %v", err)
+	}
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	if len(names) != 3 || names[0] != "C" || names[1] != "A" || names[2] != "C" {
+		t.Errorf("expected [C A C] in input order, got %v", names)
+	}
+	if len(missing) != 1 || missing[0] != gone {
+		t.Errorf("expected the unknown ID to be missing, got %v", missing)
+	}
+
+	missing, err = FindByIDs(ctx, []bson.ObjectID{b.ID, a.ID}, &users, FindOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("FindByIDs with Limit: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "B" || users[1].Name != "A" || len(missing) != 0 {
+		t.Errorf("expected [B A] ignoring Limit, got %+v (missing %v)", users, missing)
+	}
+
+	users = []testUser{*a}
+	missing, err = FindByIDs(ctx, nil, &users)
+	if err != nil || len(users) != 0 || len(missing) != 0 {
+		t.Errorf("expected empty results for no IDs, got %+v, %v, %v", users, missing, err)
+	}
+
+	if _, err := FindByIDs(ctx, []bson.ObjectID{a.ID}, &testUser{}); err == nil {
+		t.Error("expected an error for a non-slice results argument")
+	}
+
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		if err := next(ctx); err != nil {
+			return err
+		}
+		if found, ok := op.Result.(*[]testUser); ok {
+			for i := range *found {
+				(*found)[i].ID = bson.ObjectID{}
+			}
+		}
+		return nil
+	})
+	if _, err := FindByIDs(ctx, []bson.ObjectID{a.ID}, &users); err == nil {
+		t.Error("expected an error when results come back without _id")
+	}
+}
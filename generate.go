@@ -116,6 +116,9 @@ func GenerateModel(coll DiscoveredCollection, opts GenerateOptions) ([]byte, err
 
 		goName := internal.ToExportedName(f.BSONName)
 		goodmTag := internal.FormatGoodmTag(f.IsUnique, f.IsIndexed, f.IsRequired)
+		if f.Ref != "" {
+			goodmTag = strings.TrimPrefix(goodmTag+",ref="+f.Ref, ",")
+		}
 
 		if strings.Contains(f.GoType, "time.Time") {
 			needsTime = true
@@ -132,6 +135,16 @@ func GenerateModel(coll DiscoveredCollection, opts GenerateOptions) ([]byte, err
 		})
 	}
 
+	// Inverse relations become non-persisted has_many fields
+	for _, r := range coll.Relations {
+		fields = append(fields, templateField{
+			GoName:   r.Name,
+			GoType:   "[]" + internal.SanitizeStructName(r.Collection),
+			BSONName: "-",
+			GoodmTag: "hasmany=" + r.Collection + ",fk=" + r.ForeignKey,
+		})
+	}
+
 	// Collect compound indexes (multi-key only; single-key are tags)
 	var compoundIndexes []templateCompoundIndex
 	for _, idx := range coll.Indexes {
@@ -0,0 +1,494 @@
+// Package goodmmock provides a goodm.Store with programmable expectations,
+// for unit testing services against deterministic results and error paths
+// such as goodm.ErrNotFound and goodm.ErrVersionConflict.
+package goodmmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/dwoolworth/goodm"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrUnexpectedCall is returned by a Store method that no expectation
+// matches. The test is also marked as failed.
+var ErrUnexpectedCall = errors.New("goodmmock: unexpected call")
+
+// methods lists the goodm.Store methods an expectation can name.
+var methods = map[string]bool{
+	"Create": true, "CreateMany": true,
+	"FindOne": true, "Find": true, "FindByIDs": true, "FindCursor": true,
+	"Update": true, "Save": true, "UpdateFields": true, "PartialUpdate": true, "Touch": true,
+	"UpdateOne": true, "UpdateMany": true, "FindOneAndUpdate": true, "Upsert": true,
+	"Delete": true, "DeleteOne": true, "DeleteMany": true, "FindOneAndDelete": true,
+	"HardDelete": true, "Restore": true, "FindWithDeleted": true,
+	"Populate": true, "Aggregate": true, "WithTransaction": true,
+}
+
+// resultMethods lists the methods whose result argument SetResult fills.
+var resultMethods = map[string]bool{
+	"FindOne": true, "Find": true, "FindByIDs": true, "FindWithDeleted": true,
+	"FindOneAndUpdate": true, "FindOneAndDelete": true, "Aggregate": true,
+}
+
+// Call records one call to a Store method. Fields the method does not take
+// are left zero.
+type Call struct {
+	Method string      // Store method name, e.g. "FindOne"
+	Model  interface{} // model, models, result, or results argument
+	Result interface{} // results argument of Aggregate, which also takes a model
+	Filter interface{} // filter, the ids of FindByIDs, or the stages of Aggregate
+	Update interface{} // update document of UpdateOne, UpdateMany, and FindOneAndUpdate
+	Fields bson.M      // fields of UpdateFields
+	Names  []string    // field names of PartialUpdate
+	Refs   goodm.Refs  // refs of Populate
+	Opts   interface{} // options slice as passed, e.g. []goodm.UpdateOptions
+}
+
+// Store is a goodm.Store whose methods return what the test programmed with
+// On. Every call is recorded, and a call no expectation matches fails the
+// test and returns ErrUnexpectedCall. Expectations not used as often as
+// required fail the test when it finishes.
+//
+// Example:
+//
+//	store := goodmmock.New(t)
+//	store.On("FindOne").SetResult(&User{Name: "Alice"})
+//	store.On("Update").Return(goodm.ErrVersionConflict)
+//
+//	svc := &UserService{store: store}
+//	err := svc.Rename(ctx, id, "Bob") // sees a version conflict
+type Store struct {
+	t testing.TB
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+var _ goodm.Store = (*Store)(nil)
+
+// New returns a Store for the test t, checking its expectations when the
+// test finishes.
+func New(t testing.TB) *Store {
+	s := &Store{t: t}
+	t.Cleanup(s.AssertExpectations)
+	return s
+}
+
+// Expectation programs the outcome of calls to one Store method. By default
+// it matches any call of the method, once.
+type Expectation struct {
+	method string
+	match  func(Call) bool
+	err    error
+	result interface{}
+	bulk   *goodm.BulkResult
+	run    func(ctx context.Context, c Call) error
+	times  int // required calls; -1 for any number
+	calls  int
+}
+
+// On adds an expectation for calls to the named Store method. Expectations
+// are tried in the order they were added, and each is used up after the
+// number of calls set by Times.
+func (s *Store) On(method string) *Expectation {
+	s.t.Helper()
+	if !methods[method] {
+		s.t.Fatalf("goodmmock: %q is not a goodm.Store method", method)
+	}
+	e := &Expectation{method: method, times: 1}
+	s.mu.Lock()
+	s.expectations = append(s.expectations, e)
+	s.mu.Unlock()
+	return e
+}
+
+// Return makes matching calls return err.
+func (e *Expectation) Return(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// SetResult makes matching FindOne, Find, FindByIDs, FindWithDeleted,
+// FindOneAndUpdate, FindOneAndDelete, and Aggregate calls store v into their
+// result argument, and matching FindCursor calls return a cursor over v,
+// which must then be a slice. v is a value or a pointer of the result's
+// element type.
+func (e *Expectation) SetResult(v interface{}) *Expectation {
+	e.result = v
+	return e
+}
+
+// SetBulkResult makes matching CreateMany, UpdateMany, and DeleteMany calls
+// return r.
+func (e *Expectation) SetBulkResult(r *goodm.BulkResult) *Expectation {
+	e.bulk = r
+	return e
+}
+
+// Match restricts the expectation to calls for which fn returns true.
+func (e *Expectation) Match(fn func(Call) bool) *Expectation {
+	e.match = fn
+	return e
+}
+
+// Run makes matching calls run fn, whose error is returned unless Return set
+// one. fn may modify the call's model, for example to set the ID Create
+// would assign.
+func (e *Expectation) Run(fn func(ctx context.Context, c Call) error) *Expectation {
+	e.run = fn
+	return e
+}
+
+// Times sets how many calls the expectation handles and requires. n < 0
+// handles any number of calls, including none.
+func (e *Expectation) Times(n int) *Expectation {
+	if n < 0 {
+		n = -1
+	}
+	e.times = n
+	return e
+}
+
+// Calls returns the calls made so far, in order.
+func (s *Store) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// AssertExpectations fails the test for every expectation called fewer times
+// than required. New arranges for it to run when the test finishes.
+func (s *Store) AssertExpectations() {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.expectations {
+		if e.times >= 0 && e.calls < e.times {
+			s.t.Errorf("goodmmock: expected %d call(s) to %s, got %d", e.times, e.method, e.calls)
+		}
+	}
+}
+
+// find records c and returns the expectation it uses, or nil.
+func (s *Store) find(c Call) *Expectation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, c)
+	for _, e := range s.expectations {
+		if e.method != c.Method || (e.times >= 0 && e.calls >= e.times) {
+			continue
+		}
+		if e.match != nil && !e.match(c) {
+			continue
+		}
+		e.calls++
+		return e
+	}
+	return nil
+}
+
+// handles reports whether any expectation names method.
+func (s *Store) handles(method string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.expectations {
+		if e.method == method {
+			return true
+		}
+	}
+	return false
+}
+
+// call runs the expectation matching c.
+func (s *Store) call(ctx context.Context, c Call) (*Expectation, error) {
+	e := s.find(c)
+	if e == nil {
+		s.t.Helper()
+		s.t.Errorf("goodmmock: unexpected call to %s (model %T, filter %v)", c.Method, c.Model, c.Filter)
+		return nil, ErrUnexpectedCall
+	}
+	if e.run != nil {
+		if err := e.run(ctx, c); err != nil && e.err == nil {
+			return e, err
+		}
+	}
+	if e.err != nil {
+		return e, e.err
+	}
+	if e.result != nil && resultMethods[c.Method] {
+		dst := c.Model
+		if c.Result != nil {
+			dst = c.Result
+		}
+		if err := setResult(dst, e.result); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+// setResult stores v, or the value v points to, into the value dst points to.
+func setResult(dst, v interface{}) error {
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Ptr || d.IsNil() {
+		return fmt.Errorf("goodmmock: result must be a non-nil pointer, got %T", dst)
+	}
+	d = d.Elem()
+	src := reflect.ValueOf(v)
+	if !src.Type().AssignableTo(d.Type()) && src.Kind() == reflect.Ptr {
+		src = src.Elem()
+	}
+	if !src.Type().AssignableTo(d.Type()) {
+		return fmt.Errorf("goodmmock: cannot store %T into %T", v, dst)
+	}
+	d.Set(src)
+	return nil
+}
+
+// Create returns the outcome programmed for "Create".
+func (s *Store) Create(ctx context.Context, model interface{}, opts ...goodm.CreateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Create", Model: model, Opts: opts})
+	return err
+}
+
+// CreateMany returns the outcome programmed for "CreateMany", with the
+// result set with SetBulkResult or an empty one.
+func (s *Store) CreateMany(ctx context.Context, models interface{}, opts ...goodm.CreateOptions) (*goodm.BulkResult, error) {
+	s.t.Helper()
+	e, err := s.call(ctx, Call{Method: "CreateMany", Model: models, Opts: opts})
+	return bulkResult(e), err
+}
+
+// FindOne returns the outcome programmed for "FindOne".
+func (s *Store) FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...goodm.FindOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "FindOne", Model: result, Filter: filter, Opts: opts})
+	return err
+}
+
+// Find returns the outcome programmed for "Find".
+func (s *Store) Find(ctx context.Context, filter interface{}, results interface{}, opts ...goodm.FindOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Find", Model: results, Filter: filter, Opts: opts})
+	return err
+}
+
+// FindByIDs returns the outcome programmed for "FindByIDs". The IDs are
+// recorded as the call's Filter, and those with no document in the results,
+// by ID, are returned as missing.
+func (s *Store) FindByIDs(ctx context.Context, ids []bson.ObjectID, results interface{}, opts ...goodm.FindOptions) ([]bson.ObjectID, error) {
+	s.t.Helper()
+	if _, err := s.call(ctx, Call{Method: "FindByIDs", Model: results, Filter: ids, Opts: opts}); err != nil {
+		return nil, err
+	}
+	return missingIDs(ids, results), nil
+}
+
+// FindCursor returns the outcome programmed for "FindCursor": a cursor over
+// the slice set with SetResult, or an empty cursor.
+func (s *Store) FindCursor(ctx context.Context, filter interface{}, model interface{}, opts ...goodm.FindOptions) (*mongo.Cursor, error) {
+	s.t.Helper()
+	e, err := s.call(ctx, Call{Method: "FindCursor", Model: model, Filter: filter, Opts: opts})
+	if err != nil {
+		return nil, err
+	}
+	var docs []interface{}
+	if e.result != nil {
+		v := reflect.ValueOf(e.result)
+		if v.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("goodmmock: FindCursor result must be a slice, got %T", e.result)
+		}
+		for i := 0; i < v.Len(); i++ {
+			docs = append(docs, v.Index(i).Interface())
+		}
+	}
+	return mongo.NewCursorFromDocuments(docs, nil, goodm.CodecRegistry())
+}
+
+// Update returns the outcome programmed for "Update".
+func (s *Store) Update(ctx context.Context, model interface{}, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Update", Model: model, Opts: opts})
+	return err
+}
+
+// Save returns the outcome programmed for "Save".
+func (s *Store) Save(ctx context.Context, model interface{}, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Save", Model: model, Opts: opts})
+	return err
+}
+
+// UpdateFields returns the outcome programmed for "UpdateFields".
+func (s *Store) UpdateFields(ctx context.Context, model interface{}, fields bson.M, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "UpdateFields", Model: model, Fields: fields, Opts: opts})
+	return err
+}
+
+// PartialUpdate returns the outcome programmed for "PartialUpdate".
+func (s *Store) PartialUpdate(ctx context.Context, model interface{}, fields []string, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "PartialUpdate", Model: model, Names: fields, Opts: opts})
+	return err
+}
+
+// Touch returns the outcome programmed for "Touch".
+func (s *Store) Touch(ctx context.Context, model interface{}, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Touch", Model: model, Opts: opts})
+	return err
+}
+
+// UpdateOne returns the outcome programmed for "UpdateOne".
+func (s *Store) UpdateOne(ctx context.Context, filter interface{}, update interface{}, model interface{}, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "UpdateOne", Model: model, Filter: filter, Update: update, Opts: opts})
+	return err
+}
+
+// UpdateMany returns the outcome programmed for "UpdateMany", with the
+// result set with SetBulkResult or an empty one.
+func (s *Store) UpdateMany(ctx context.Context, filter, update interface{}, model interface{}, opts ...goodm.UpdateOptions) (*goodm.BulkResult, error) {
+	s.t.Helper()
+	e, err := s.call(ctx, Call{Method: "UpdateMany", Model: model, Filter: filter, Update: update, Opts: opts})
+	return bulkResult(e), err
+}
+
+// FindOneAndUpdate returns the outcome programmed for "FindOneAndUpdate".
+func (s *Store) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, result interface{}, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "FindOneAndUpdate", Model: result, Filter: filter, Update: update, Opts: opts})
+	return err
+}
+
+// Upsert returns the outcome programmed for "Upsert".
+func (s *Store) Upsert(ctx context.Context, filter interface{}, model interface{}, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Upsert", Model: model, Filter: filter, Opts: opts})
+	return err
+}
+
+// Delete returns the outcome programmed for "Delete".
+func (s *Store) Delete(ctx context.Context, model interface{}, opts ...goodm.DeleteOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Delete", Model: model, Opts: opts})
+	return err
+}
+
+// DeleteOne returns the outcome programmed for "DeleteOne".
+func (s *Store) DeleteOne(ctx context.Context, filter interface{}, model interface{}, opts ...goodm.DeleteOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "DeleteOne", Model: model, Filter: filter, Opts: opts})
+	return err
+}
+
+// DeleteMany returns the outcome programmed for "DeleteMany", with the
+// result set with SetBulkResult or an empty one.
+func (s *Store) DeleteMany(ctx context.Context, filter interface{}, model interface{}, opts ...goodm.DeleteOptions) (*goodm.BulkResult, error) {
+	s.t.Helper()
+	e, err := s.call(ctx, Call{Method: "DeleteMany", Model: model, Filter: filter, Opts: opts})
+	return bulkResult(e), err
+}
+
+// FindOneAndDelete returns the outcome programmed for "FindOneAndDelete".
+func (s *Store) FindOneAndDelete(ctx context.Context, filter interface{}, result interface{}, opts ...goodm.DeleteOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "FindOneAndDelete", Model: result, Filter: filter, Opts: opts})
+	return err
+}
+
+// HardDelete returns the outcome programmed for "HardDelete".
+func (s *Store) HardDelete(ctx context.Context, model interface{}, opts ...goodm.DeleteOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "HardDelete", Model: model, Opts: opts})
+	return err
+}
+
+// Restore returns the outcome programmed for "Restore".
+func (s *Store) Restore(ctx context.Context, model interface{}, opts ...goodm.UpdateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Restore", Model: model, Opts: opts})
+	return err
+}
+
+// FindWithDeleted returns the outcome programmed for "FindWithDeleted".
+func (s *Store) FindWithDeleted(ctx context.Context, filter interface{}, results interface{}, opts ...goodm.FindOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "FindWithDeleted", Model: results, Filter: filter, Opts: opts})
+	return err
+}
+
+// Populate returns the outcome programmed for "Populate".
+func (s *Store) Populate(ctx context.Context, model interface{}, refs goodm.Refs, opts ...goodm.PopulateOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Populate", Model: model, Refs: refs, Opts: opts})
+	return err
+}
+
+// Aggregate returns the outcome programmed for "Aggregate". The stages are
+// recorded as the call's Filter.
+func (s *Store) Aggregate(ctx context.Context, model interface{}, stages []bson.D, results interface{}, opts ...goodm.PipelineOptions) error {
+	s.t.Helper()
+	_, err := s.call(ctx, Call{Method: "Aggregate", Model: model, Result: results, Filter: stages, Opts: opts})
+	return err
+}
+
+// WithTransaction runs fn with ctx, so the calls it makes reach the mock,
+// and returns its error. If the test programmed "WithTransaction", a
+// matching expectation with an error returns that error without running fn,
+// as when the transaction cannot start.
+func (s *Store) WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...goodm.TransactionOptions) error {
+	s.t.Helper()
+	if !s.handles("WithTransaction") {
+		s.find(Call{Method: "WithTransaction", Opts: opts})
+		return fn(ctx)
+	}
+	if _, err := s.call(ctx, Call{Method: "WithTransaction", Opts: opts}); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+// missingIDs returns the ids, in order, that no element of the slice results
+// points to has as its ID field.
+func missingIDs(ids []bson.ObjectID, results interface{}) []bson.ObjectID {
+	found := make(map[bson.ObjectID]bool)
+	if v := reflect.ValueOf(results); v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Slice {
+		for i := 0; i < v.Elem().Len(); i++ {
+			el := reflect.Indirect(v.Elem().Index(i))
+			if el.Kind() != reflect.Struct {
+				continue
+			}
+			if f := el.FieldByName("ID"); f.IsValid() && f.Type() == reflect.TypeOf(bson.ObjectID{}) {
+				found[f.Interface().(bson.ObjectID)] = true
+			}
+		}
+	}
+	var missing []bson.ObjectID
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+func bulkResult(e *Expectation) *goodm.BulkResult {
+	if e == nil {
+		return nil
+	}
+	if e.bulk != nil {
+		return e.bulk
+	}
+	return &goodm.BulkResult{}
+}
@@ -0,0 +1,265 @@
+package goodmmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dwoolworth/goodm"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type user struct {
+	goodm.Model `bson:",inline"`
+	Name        string `bson:"name"`
+}
+
+// recorder is a testing.TB that records failures instead of failing the
+// test, and runs cleanups on demand.
+type recorder struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(fn func()) { r.cleanups = append(r.cleanups, fn) }
+
+func (r *recorder) finish() {
+	for _, fn := range r.cleanups {
+		fn()
+	}
+}
+
+func TestStore_Results(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+
+	store.On("FindOne").SetResult(&user{Name: "Alice"})
+	store.On("Find").SetResult([]user{{Name: "Bob"}, {Name: "Carol"}})
+	store.On("DeleteMany").SetBulkResult(&goodm.BulkResult{DeletedCount: 3})
+
+	var u user
+	if err := store.FindOne(ctx, bson.D{{Key: "name", Value: "Alice"}}, &u); err != nil || u.Name != "Alice" {
+		t.Fatalf("FindOne: %+v, %v", u, err)
+	}
+	var users []user
+	if err := store.Find(ctx, bson.D{}, &users); err != nil || len(users) != 2 {
+		t.Fatalf("Find: %+v, %v", users, err)
+	}
+	res, err := store.DeleteMany(ctx, bson.D{}, &user{})
+	if err != nil || res.DeletedCount != 3 {
+		t.Fatalf("DeleteMany: %+v, %v", res, err)
+	}
+
+	calls := store.Calls()
+	if len(calls) != 3 || calls[0].Method != "FindOne" || calls[0].Model != &u {
+		t.Errorf("unexpected calls: %+v", calls)
+	}
+}
+
+func TestStore_Errors(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+
+	store.On("FindOne").
+		Match(func(c Call) bool {
+			f, ok := c.Filter.(bson.D)
+			return ok && len(f) == 1 && f[0].Value == "missing"
+		}).
+		Return(goodm.ErrNotFound)
+	store.On("Update").Return(goodm.ErrVersionConflict).Times(2)
+	store.On("Update")
+	store.On("Save").Return(goodm.ErrVersionConflict)
+
+	if err := store.FindOne(ctx, bson.D{{Key: "_id", Value: "missing"}}, &user{}); !errors.Is(err, goodm.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := store.Update(ctx, &user{}); !errors.Is(err, goodm.ErrVersionConflict) {
+			t.Errorf("attempt %d: expected ErrVersionConflict, got %v", i, err)
+		}
+	}
+	if err := store.Update(ctx, &user{}); err != nil {
+		t.Errorf("expected the third update to succeed, got %v", err)
+	}
+	if err := store.Save(ctx, &user{}); !errors.Is(err, goodm.ErrVersionConflict) {
+		t.Errorf("expected Save to return ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestStore_Run(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+
+	id := bson.NewObjectID()
+	store.On("Create").Run(func(ctx context.Context, c Call) error {
+		c.Model.(*user).ID = id
+		return nil
+	})
+
+	err := store.WithTransaction(ctx, func(ctx context.Context) error {
+		return store.Create(ctx, &user{Name: "Dana"})
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if calls := store.Calls(); len(calls) != 2 || calls[0].Method != "WithTransaction" || calls[1].Model.(*user).ID != id {
+		t.Errorf("unexpected calls: %+v", calls)
+	}
+
+	store.On("WithTransaction").Return(errors.New("no replica set"))
+	ran := false
+	err = store.WithTransaction(ctx, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	if err == nil || ran {
+		t.Errorf("expected the programmed error without running fn, got %v (ran %v)", err, ran)
+	}
+}
+
+func TestStore_Cursor(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+	store.On("FindCursor").SetResult([]user{{Name: "Eve"}})
+
+	cursor, err := store.FindCursor(ctx, bson.D{}, &user{})
+	if err != nil {
+		t.Fatalf("FindCursor: %v", err)
+	}
+	var users []user
+	if err := cursor.All(ctx, &users); err != nil || len(users) != 1 || users[0].Name != "Eve" {
+		t.Fatalf("cursor: %+v, %v", users, err)
+	}
+}
+
+func TestStore_Failures(t *testing.T) {
+	ctx := context.Background()
+	r := &recorder{TB: t}
+	store := New(r)
+	store.On("Delete")
+
+	if err := store.Create(ctx, &user{}); !errors.Is(err, ErrUnexpectedCall) {
+		t.Errorf("expected ErrUnexpectedCall, got %v", err)
+	}
+	r.finish()
+
+	if len(r.errors) != 2 {
+		t.Fatalf("expected an unexpected call and an unmet expectation, got %q", r.errors)
+	}
+}
+
+func TestStore_Aggregate(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+	store.On("Aggregate").SetResult([]bson.M{{"role": "admin", "n": 2}})
+
+	stages := []bson.D{{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$role"}}}}}
+	var out []bson.M
+	if err := store.Aggregate(ctx, &user{}, stages, &out); err != nil || len(out) != 1 || out[0]["n"] != 2 {
+		t.Fatalf("Aggregate: %+v, %v", out, err)
+	}
+	if c := store.Calls()[0]; c.Model == nil || c.Result != &out || len(c.Filter.([]bson.D)) != 1 {
+		t.Errorf("expected the model, results, and stages to be recorded, got %+v", c)
+	}
+}
+
+func TestStore_FindByIDs(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+	found, gone := bson.NewObjectID(), bson.NewObjectID()
+	store.On("FindByIDs").SetResult([]user{{Model: goodm.Model{ID: found}, Name: "Fay"}})
+
+	var users []user
+	missing, err := store.FindByIDs(ctx, []bson.ObjectID{gone, found}, &users)
+	if err != nil || len(users) != 1 || users[0].Name != "Fay" {
+		t.Fatalf("FindByIDs: %+v, %v", users, err)
+	}
+	if len(missing) != 1 || missing[0] != gone {
+		t.Errorf("expected %v to be missing, got %v", gone, missing)
+	}
+}
+
+func TestStore_Upsert(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+	store.On("Upsert").Return(goodm.ErrUpsertConflict)
+
+	filter := bson.D{{Key: "name", Value: "Gil"}}
+	if err := store.Upsert(ctx, filter, &user{Name: "Gil"}); !errors.Is(err, goodm.ErrUpsertConflict) {
+		t.Errorf("expected ErrUpsertConflict, got %v", err)
+	}
+	if c := store.Calls()[0]; c.Method != "Upsert" || c.Filter == nil || c.Model.(*user).Name != "Gil" {
+		t.Errorf("unexpected call: %+v", c)
+	}
+}
+
+func TestStore_FindOneAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+	store.On("FindOneAndUpdate").SetResult(&user{Name: "Hal"})
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Hal"}}}}
+	var u user
+	if err := store.FindOneAndUpdate(ctx, bson.D{}, update, &u); err != nil || u.Name != "Hal" {
+		t.Fatalf("FindOneAndUpdate: %+v, %v", u, err)
+	}
+	if c := store.Calls()[0]; c.Update == nil || c.Model != &u {
+		t.Errorf("expected the update and result to be recorded, got %+v", c)
+	}
+}
+
+func TestStore_FindOneAndDelete(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+	store.On("FindOneAndDelete").SetResult(user{Name: "Ivy"})
+	store.On("FindOneAndDelete").Return(goodm.ErrNotFound)
+
+	var u user
+	if err := store.FindOneAndDelete(ctx, bson.D{}, &u); err != nil || u.Name != "Ivy" {
+		t.Fatalf("FindOneAndDelete: %+v, %v", u, err)
+	}
+	if err := store.FindOneAndDelete(ctx, bson.D{}, &user{}); !errors.Is(err, goodm.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+	store.On("FindWithDeleted").SetResult([]user{{Name: "Jo"}})
+	store.On("Restore").Return(goodm.ErrNotFound)
+	store.On("HardDelete")
+
+	var users []user
+	if err := store.FindWithDeleted(ctx, bson.D{}, &users); err != nil || len(users) != 1 || users[0].Name != "Jo" {
+		t.Fatalf("FindWithDeleted: %+v, %v", users, err)
+	}
+	if err := store.Restore(ctx, &users[0]); !errors.Is(err, goodm.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := store.HardDelete(ctx, &users[0]); err != nil {
+		t.Errorf("HardDelete: %v", err)
+	}
+}
+
+func TestStore_PartialUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := New(t)
+	store.On("PartialUpdate").Return(goodm.ErrVersionConflict)
+
+	u := &user{Name: "Kit"}
+	if err := store.PartialUpdate(ctx, u, []string{"name"}, goodm.WithVersionCheck()); !errors.Is(err, goodm.ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+	if c := store.Calls()[0]; len(c.Names) != 1 || c.Names[0] != "name" || c.Model != u {
+		t.Errorf("expected the field names and model to be recorded, got %+v", c)
+	}
+}
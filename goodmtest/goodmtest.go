@@ -0,0 +1,27 @@
+// Package goodmtest provides helpers for testing code that uses goodm.
+package goodmtest
+
+import (
+	"testing"
+
+	"github.com/dwoolworth/goodm"
+)
+
+// UseStore switches goodm to a fresh in-memory store for the duration of the
+// test, so code under test can call goodm without a running MongoDB. The
+// store is discarded when the test finishes. Tests using it must not run in
+// parallel with other tests that use goodm's global state.
+//
+// Example:
+//
+//	func TestSignup(t *testing.T) {
+//	    goodmtest.UseStore(t)
+//	    if err := signup(ctx, "alice@example.com"); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+func UseStore(t testing.TB) {
+	t.Helper()
+	goodm.UseTestStore()
+	t.Cleanup(goodm.ClearTestStore)
+}
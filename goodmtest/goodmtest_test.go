@@ -0,0 +1,89 @@
+package goodmtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dwoolworth/goodm"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type widget struct {
+	goodm.Model `bson:",inline"`
+	Name        string `bson:"name" goodm:"unique,required"`
+}
+
+func init() {
+	if err := goodm.Register(&widget{}, "widgets"); err != nil {
+		panic(err)
+	}
+}
+
+func TestUseStore(t *testing.T) {
+	t.Run("store", func(t *testing.T) {
+		UseStore(t)
+		ctx := context.Background()
+		if err := goodm.Create(ctx, &widget{Name: "gear"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		found := &widget{}
+		if err := goodm.FindOne(ctx, bson.D{{Key: "name", Value: "gear"}}, found); err != nil {
+			t.Fatalf("find one: %v", err)
+		}
+	})
+
+	if err := goodm.Create(context.Background(), &widget{Name: "gear"}); !errors.Is(err, goodm.ErrNoDatabase) {
+		t.Fatalf("expected ErrNoDatabase after the store is discarded, got %v", err)
+	}
+}
+
+func TestStartMongo(t *testing.T) {
+	prev := goodm.DB()
+	t.Run("mongo", func(t *testing.T) {
+		db := StartMongo(t)
+		if goodm.DB() != db {
+			t.Fatal("expected StartMongo to set the global database")
+		}
+		ctx := context.Background()
+		if err := goodm.Create(ctx, &widget{Name: "gear"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		err := goodm.Create(ctx, &widget{Name: "gear"})
+		if err == nil {
+			t.Fatal("expected duplicate key error from the enforced unique index")
+		}
+	})
+	if goodm.DB() != prev {
+		t.Fatal("expected the previous global database to be restored")
+	}
+}
+
+func TestWithRollback(t *testing.T) {
+	StartMongo(t, MongoOptions{ReplicaSet: true})
+	ctx := context.Background()
+
+	WithRollback(t, func(ctx context.Context) {
+		if err := goodm.Create(ctx, &widget{Name: "temp"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		err := goodm.WithTransaction(ctx, func(ctx context.Context) error {
+			return goodm.Create(ctx, &widget{Name: "nested"})
+		})
+		if err != nil {
+			t.Fatalf("nested transaction: %v", err)
+		}
+		var found []widget
+		if err := goodm.Find(ctx, bson.D{}, &found); err != nil || len(found) != 2 {
+			t.Fatalf("expected 2 widgets inside the transaction, got %d, %v", len(found), err)
+		}
+	})
+
+	var found []widget
+	if err := goodm.Find(ctx, bson.D{}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(found) != 0 {
+		t.Fatalf("expected writes to be rolled back, found %d widgets", len(found))
+	}
+}
@@ -0,0 +1,173 @@
+package goodmtest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dwoolworth/goodm"
+	"github.com/testcontainers/testcontainers-go"
+	tcmongo "github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// DefaultImage is the MongoDB image StartMongo runs when MongoOptions.Image
+// is empty.
+const DefaultImage = "mongo:7"
+
+// MongoOptions configures StartMongo.
+type MongoOptions struct {
+	// Image is the MongoDB image to run. Defaults to DefaultImage.
+	Image string
+
+	// ReplicaSet starts the server as a single-node replica set, which
+	// transactions require.
+	ReplicaSet bool
+}
+
+// server is a MongoDB container shared by every test in the process that
+// asks for the same options.
+type server struct {
+	once sync.Once
+	uri  string
+	err  error
+}
+
+var (
+	serversMu sync.Mutex
+	servers   = map[MongoOptions]*server{}
+	dbSeq     int64
+)
+
+// StartMongo gives the test its own MongoDB database and connects goodm to
+// it. The first call in a test binary starts a disposable MongoDB container
+// with testcontainers; later calls with the same options reuse it, each
+// getting a fresh database. Indexes for all registered models are created
+// with goodm.Enforce. When the test finishes the database is dropped and the
+// previous global database is restored. The container is removed when the
+// test binary exits.
+//
+// If MONGODB_URI is set, that server is used instead of a container. If
+// neither is available because Docker is not running, the test is skipped.
+//
+// StartMongo sets goodm's global database, so tests that run in parallel
+// should pass the returned database through the DB field of each operation's
+// options.
+//
+// Example:
+//
+//	func TestOrders(t *testing.T) {
+//	    goodmtest.StartMongo(t, goodmtest.MongoOptions{ReplicaSet: true})
+//	    // goodm.Create, goodm.WithTransaction, ...
+//	}
+func StartMongo(t testing.TB, opts ...MongoOptions) *mongo.Database {
+	t.Helper()
+	var opt MongoOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Image == "" {
+		opt.Image = DefaultImage
+	}
+
+	ctx := context.Background()
+	uri, err := mongoURI(ctx, opt)
+	if err != nil {
+		if !dockerAvailable(ctx) {
+			t.Skipf("goodmtest: Docker not available and MONGODB_URI not set: %v", err)
+		}
+		t.Fatalf("goodmtest: failed to start MongoDB: %v", err)
+	}
+
+	prev := goodm.DB()
+	name := fmt.Sprintf("goodmtest_%d_%d", time.Now().UnixNano(), atomic.AddInt64(&dbSeq, 1))
+	db, err := goodm.Connect(ctx, uri, name)
+	if err != nil {
+		t.Fatalf("goodmtest: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Drop(ctx)
+		_ = db.Client().Disconnect(ctx)
+		goodm.SetDB(prev)
+	})
+
+	if _, err := goodm.Enforce(ctx, db); err != nil {
+		t.Fatalf("goodmtest: %v", err)
+	}
+	return db
+}
+
+// mongoURI returns the URI of the server for opt, starting its container on
+// first use.
+func mongoURI(ctx context.Context, opt MongoOptions) (string, error) {
+	if uri := os.Getenv("MONGODB_URI"); uri != "" {
+		return uri, nil
+	}
+
+	serversMu.Lock()
+	s, ok := servers[opt]
+	if !ok {
+		s = &server{}
+		servers[opt] = s
+	}
+	serversMu.Unlock()
+
+	s.once.Do(func() {
+		s.uri, s.err = startContainer(ctx, opt)
+	})
+	return s.uri, s.err
+}
+
+func startContainer(ctx context.Context, opt MongoOptions) (uri string, err error) {
+	defer func() {
+		// testcontainers panics when no Docker host can be found.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	var customizers []testcontainers.ContainerCustomizer
+	if opt.ReplicaSet {
+		customizers = append(customizers, tcmongo.WithReplicaSet("rs0"))
+	}
+	c, err := tcmongo.Run(ctx, opt.Image, customizers...)
+	if err != nil {
+		return "", err
+	}
+	uri, err = c.ConnectionString(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !opt.ReplicaSet {
+		return uri, nil
+	}
+
+	// The replica set member advertises the container's internal address,
+	// which the host may not be able to reach, so connect to it directly.
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = url.Values{"directConnection": {"true"}}.Encode()
+	return u.String(), nil
+}
+
+// dockerAvailable reports whether testcontainers can reach a Docker host.
+func dockerAvailable(ctx context.Context) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		return false
+	}
+	defer provider.Close()
+	return provider.Health(ctx) == nil
+}
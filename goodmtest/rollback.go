@@ -0,0 +1,64 @@
+package goodmtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dwoolworth/goodm"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// RollbackOptions configures WithRollback.
+type RollbackOptions struct {
+	// DB is the database whose client runs the transaction. Defaults to
+	// goodm's global database.
+	DB *mongo.Database
+}
+
+// WithRollback runs fn inside a transaction that is always aborted, so every
+// write fn makes through goodm with the ctx it is given is discarded when it
+// returns. Tests can share one database without dropping it between cases.
+// goodm.WithTransaction calls inside fn join the rollback transaction.
+//
+// Transactions require a replica set; use StartMongo with
+// MongoOptions{ReplicaSet: true}. Writes to collections that do not exist
+// yet create them inside the transaction, which needs MongoDB 4.4 or later.
+//
+// Example:
+//
+//	func TestRename(t *testing.T) {
+//	    goodmtest.WithRollback(t, func(ctx context.Context) {
+//	        user := &User{Name: "alice"}
+//	        if err := goodm.Create(ctx, user); err != nil {
+//	            t.Fatal(err)
+//	        }
+//	        // ...
+//	    })
+//	}
+func WithRollback(t testing.TB, fn func(ctx context.Context), opts ...RollbackOptions) {
+	t.Helper()
+	db := goodm.DB()
+	if len(opts) > 0 && opts[0].DB != nil {
+		db = opts[0].DB
+	}
+	if db == nil {
+		t.Fatalf("goodmtest: WithRollback: %v", goodm.ErrNoDatabase)
+	}
+
+	ctx := context.Background()
+	session, err := db.Client().StartSession()
+	if err != nil {
+		t.Fatalf("goodmtest: failed to start session: %v", err)
+	}
+	defer session.EndSession(ctx)
+
+	if err := session.StartTransaction(); err != nil {
+		t.Fatalf("goodmtest: failed to start transaction: %v", err)
+	}
+	// Deferred so the transaction is also aborted when fn calls t.FailNow.
+	defer func() {
+		_ = session.AbortTransaction(ctx)
+	}()
+
+	fn(mongo.NewSessionContext(ctx, session))
+}
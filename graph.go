@@ -0,0 +1,186 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dwoolworth/goodm/internal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// GraphOptions configures the SaveGraph operation.
+type GraphOptions struct {
+	DB          *mongo.Database
+	Transaction bool // save the whole graph in one transaction (requires a replica set)
+}
+
+// graphLink is a non-persisted field holding the populated document(s) for a
+// ref field, declared with `bson:"-" goodm:"populate=<ref field>"`.
+type graphLink struct {
+	field reflect.StructField
+	ref   *FieldSchema
+}
+
+// SaveGraph saves root together with the documents held in its populate
+// fields. A populate field is a non-persisted field that holds the loaded
+// document, or slice of documents, for one of the model's ref fields:
+//
+//	type Post struct {
+//	    goodm.Model `bson:",inline"`
+//	    AuthorID    bson.ObjectID `bson:"author" goodm:"ref=users"`
+//	    Author      *User         `bson:"-"      goodm:"populate=author"`
+//	}
+//
+// Children are saved before their parent, recursively: documents with a zero
+// ID are inserted with Create and the others are saved with Update. Their IDs
+// are then written into the parent's ref field, replacing its previous value,
+// and the parent is saved the same way. A nil populate field leaves the ref
+// field untouched.
+//
+// Without GraphOptions.Transaction, an error part-way through leaves the
+// documents saved so far in place.
+//
+// Example:
+//
+//	post := &Post{Title: "Hello", Author: &User{Email: "a@example.com", Name: "Alice"}}
+//	err := goodm.SaveGraph(ctx, post) // creates the user, then the post
+func SaveGraph(ctx context.Context, root interface{}, opts ...GraphOptions) error {
+	var opt GraphOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	save := func(ctx context.Context) error {
+		return saveGraphNode(ctx, root, opt.DB, map[interface{}]bool{})
+	}
+	if opt.Transaction {
+		return WithTransaction(ctx, save, TransactionOptions{DB: opt.DB})
+	}
+	return save(ctx)
+}
+
+// saveGraphNode saves model's populated children, links their IDs, and then
+// saves model. visiting holds the models currently being saved, to detect
+// cycles.
+func saveGraphNode(ctx context.Context, model interface{}, db *mongo.Database, visiting map[interface{}]bool) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	id, err := getModelID(model)
+	if err != nil {
+		return err
+	}
+	if visiting[model] {
+		if id.IsZero() {
+			return fmt.Errorf("goodm: save graph: cycle of new %s documents", schema.ModelName)
+		}
+		return nil // saved by the caller that is already visiting it
+	}
+	visiting[model] = true
+	defer delete(visiting, model)
+
+	v := reflect.ValueOf(model).Elem()
+	links, err := graphLinks(v.Type(), schema)
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		if err := saveGraphLink(ctx, v, l, db, visiting); err != nil {
+			return err
+		}
+	}
+
+	if id.IsZero() {
+		return Create(ctx, model, CreateOptions{DB: db})
+	}
+	return Update(ctx, model, UpdateOptions{DB: db})
+}
+
+// saveGraphLink saves the document(s) held in one populate field and writes
+// their IDs into the linked ref field.
+func saveGraphLink(ctx context.Context, parent reflect.Value, l graphLink, db *mongo.Database, visiting map[interface{}]bool) error {
+	fv := parent.FieldByName(l.field.Name)
+	refv := parent.FieldByName(l.ref.Name)
+
+	if fv.Kind() == reflect.Slice {
+		if fv.IsNil() {
+			return nil
+		}
+		if refv.Type() != objectIDSliceType {
+			return fmt.Errorf("goodm: save graph: populate field %s is a slice but ref field %q is %s", l.field.Name, l.ref.BSONName, refv.Type())
+		}
+		ids := make([]bson.ObjectID, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			id, err := saveGraphChild(ctx, fv.Index(i), db, visiting)
+			if err != nil {
+				return fmt.Errorf("goodm: save graph: %s[%d]: %w", l.field.Name, i, err)
+			}
+			ids[i] = id
+		}
+		refv.Set(reflect.ValueOf(ids))
+		return nil
+	}
+
+	if fv.IsZero() {
+		return nil
+	}
+	if refv.Type() != objectIDType {
+		return fmt.Errorf("goodm: save graph: populate field %s holds one document but ref field %q is %s", l.field.Name, l.ref.BSONName, refv.Type())
+	}
+	id, err := saveGraphChild(ctx, fv, db, visiting)
+	if err != nil {
+		return fmt.Errorf("goodm: save graph: %s: %w", l.field.Name, err)
+	}
+	refv.Set(reflect.ValueOf(id))
+	return nil
+}
+
+// saveGraphChild saves a child held as a struct or pointer and returns its ID.
+func saveGraphChild(ctx context.Context, cv reflect.Value, db *mongo.Database, visiting map[interface{}]bool) (bson.ObjectID, error) {
+	if cv.Kind() != reflect.Ptr {
+		cv = cv.Addr()
+	}
+	if cv.IsNil() {
+		return bson.ObjectID{}, fmt.Errorf("nil document")
+	}
+	child := cv.Interface()
+	if err := saveGraphNode(ctx, child, db, visiting); err != nil {
+		return bson.ObjectID{}, err
+	}
+	return getModelID(child)
+}
+
+// graphLinks returns the populate fields declared on model type t.
+func graphLinks(t reflect.Type, schema *Schema) ([]graphLink, error) {
+	var links []graphLink
+	for _, f := range internal.StructFields(t) {
+		ref := populateTarget(f.Tag.Get("goodm"))
+		if ref == "" {
+			continue
+		}
+		if name, _ := ParseBSONTag(f.Tag.Get("bson")); name != "-" {
+			return nil, fmt.Errorf("goodm: populate field %s.%s must be tagged bson:\"-\"", schema.ModelName, f.Name)
+		}
+		fs := schema.GetField(ref)
+		if fs == nil || fs.Ref == "" {
+			return nil, fmt.Errorf("goodm: populate field %s.%s names %q, which is not a ref field", schema.ModelName, f.Name, ref)
+		}
+		links = append(links, graphLink{field: f, ref: fs})
+	}
+	return links, nil
+}
+
+// populateTarget returns the ref field named by a populate= directive in a
+// goodm tag, or "".
+func populateTarget(tag string) string {
+	for _, part := range strings.Split(tag, ",") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), "="); ok && k == "populate" {
+			return v
+		}
+	}
+	return ""
+}
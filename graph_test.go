@@ -0,0 +1,125 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testGraphPost struct {
+	Model    `bson:",inline"`
+	Title    string          `bson:"title"  goodm:"required"`
+	AuthorID bson.ObjectID   `bson:"author" goodm:"ref=test_users"`
+	Author   *testUser       `bson:"-"      goodm:"populate=author"`
+	TagIDs   []bson.ObjectID `bson:"tags"   goodm:"ref=test_tags"`
+	Tags     []testTag       `bson:"-"      goodm:"populate=tags"`
+}
+
+type testBadGraphPost struct {
+	Model  `bson:",inline"`
+	Title  string    `bson:"title"`
+	Author *testUser `bson:"-" goodm:"populate=title"`
+}
+
+func registerGraphModels(t *testing.T) {
+	t.Helper()
+	for model, coll := range map[interface{}]string{
+		&testGraphPost{}:    "test_graph_posts",
+		&testBadGraphPost{}: "test_bad_graph_posts",
+	} {
+		if err := Register(model, coll); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testGraphPost")
+		delete(registry, "testBadGraphPost")
+		registryMu.Unlock()
+	})
+}
+
+func TestSaveGraph_CreatesChildrenFirst(t *testing.T) {
+	ctx := useTestStore(t)
+	registerGraphModels(t)
+
+	post := &testGraphPost{
+		Title:  "Graph",
+		Author: &testUser{Email: "graph@test.com", Name: "Graph"},
+		Tags:   []testTag{{Label: "go"}, {Label: "mongo"}},
+	}
+	if err := SaveGraph(ctx, post); err != nil {
+		t.Fatalf("save graph: %v", err)
+	}
+	if post.Author.ID.IsZero() || post.AuthorID != post.Author.ID {
+		t.Fatalf("expected author ID linked into the post, got %v / %v", post.AuthorID, post.Author.ID)
+	}
+	if len(post.TagIDs) != 2 || post.TagIDs[1] != post.Tags[1].ID {
+		t.Fatalf("expected tag IDs linked into the post, got %v", post.TagIDs)
+	}
+
+	stored := &testGraphPost{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: post.ID}}, stored); err != nil {
+		t.Fatalf("find post: %v", err)
+	}
+	if stored.AuthorID != post.Author.ID || stored.Author != nil {
+		t.Fatalf("expected stored ref without the populated document, got %+v", stored)
+	}
+
+	// Saving again updates existing documents instead of inserting them.
+	post.Author.Age = 41
+	post.Title = "Graph v2"
+	if err := SaveGraph(ctx, post); err != nil {
+		t.Fatalf("save graph again: %v", err)
+	}
+	author := &testUser{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: post.AuthorID}}, author); err != nil {
+		t.Fatalf("find author: %v", err)
+	}
+	if author.Age != 41 || post.Version != 1 {
+		t.Fatalf("expected author and post to be updated, got age %d, post version %d", author.Age, post.Version)
+	}
+	var tags []testTag
+	if err := Find(ctx, bson.D{}, &tags); err != nil || len(tags) != 2 {
+		t.Fatalf("expected 2 tags after second save, got %d, %v", len(tags), err)
+	}
+}
+
+func TestSaveGraph_NilPopulateFieldKeepsRef(t *testing.T) {
+	ctx := useTestStore(t)
+	registerGraphModels(t)
+
+	id := bson.NewObjectID()
+	post := &testGraphPost{Title: "Unpopulated", AuthorID: id}
+	if err := SaveGraph(ctx, post); err != nil {
+		t.Fatalf("save graph: %v", err)
+	}
+	if post.AuthorID != id {
+		t.Fatalf("expected ref to be left alone, got %v", post.AuthorID)
+	}
+}
+
+func TestSaveGraph_ChildFailureStopsParent(t *testing.T) {
+	ctx := useTestStore(t)
+	registerGraphModels(t)
+
+	post := &testGraphPost{Title: "Invalid author", Author: &testUser{Name: "No email"}}
+	err := SaveGraph(ctx, post)
+	if err == nil || !strings.Contains(err.Error(), "Author") {
+		t.Fatalf("expected error naming the Author field, got %v", err)
+	}
+	if !post.ID.IsZero() {
+		t.Fatal("expected parent not to be saved when a child fails")
+	}
+}
+
+func TestSaveGraph_PopulateMustNameRefField(t *testing.T) {
+	ctx := useTestStore(t)
+	registerGraphModels(t)
+
+	err := SaveGraph(ctx, &testBadGraphPost{Title: "x", Author: &testUser{}})
+	if err == nil || !strings.Contains(err.Error(), "not a ref field") {
+		t.Fatalf("expected error for populate on a non-ref field, got %v", err)
+	}
+}
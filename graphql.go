@@ -0,0 +1,331 @@
+package goodm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dwoolworth/goodm/internal"
+)
+
+// gqlStringEscaper escapes text for a GraphQL string literal.
+var gqlStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// GraphQLOptions configures GenerateGraphQL.
+type GraphQLOptions struct {
+	// BSONNames names fields after the bson tags instead of the json tag or
+	// the camelCased Go field name.
+	BSONNames bool
+
+	// Query adds a Query type with a lookup by ID and a paginated list for
+	// every model.
+	Query bool
+}
+
+// GenerateGraphQL renders the given schemas as GraphQL SDL: one object type
+// per model and subdocument struct, and an enum per enum field whose values
+// are valid GraphQL names. ObjectIDs map to ID, times to a DateTime scalar,
+// and maps and raw documents to a JSON scalar. Non-pointer fields that are
+// not omitempty or select=false are non-null.
+//
+// Relations become fields of the related type: a ref field AuthorID adds
+// author: User, and has_one/has_many fields become User or [User!]. Refs to
+// collections without a registered model are left as IDs.
+//
+// Example:
+//
+//	fmt.Print(goodm.GenerateGraphQL(goodm.GetAll(), goodm.GraphQLOptions{Query: true}))
+func GenerateGraphQL(schemas map[string]*Schema, opts ...GraphQLOptions) string {
+	var opt GraphQLOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	g := &gqlGenerator{
+		opt: opt, models: map[reflect.Type]string{}, byCollection: map[string]string{},
+		nested: map[reflect.Type]bool{}, scalars: map[string]bool{},
+	}
+	names := make([]string, 0, len(schemas))
+	for name, s := range schemas {
+		names = append(names, name)
+		g.models[s.modelType] = s.ModelName
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c := schemas[name].Collection; g.byCollection[c] == "" {
+			g.byCollection[c] = name
+		}
+	}
+
+	var body strings.Builder
+	for _, name := range names {
+		g.writeModel(&body, schemas[name])
+	}
+	written := map[reflect.Type]bool{}
+	for {
+		var pending []reflect.Type
+		for t := range g.nested {
+			if !written[t] {
+				pending = append(pending, t)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		sort.Slice(pending, func(i, j int) bool { return pending[i].Name() < pending[j].Name() })
+		for _, t := range pending {
+			written[t] = true
+			g.writeType(&body, t.Name(), t, nil)
+		}
+	}
+	body.WriteString(g.enums.String())
+
+	if opt.Query {
+		body.WriteString("\ntype Query {\n")
+		for _, name := range names {
+			s := schemas[name]
+			one, list := lowerCamel(s.ModelName), lowerCamel(internal.ToExportedName(s.Collection))
+			if list == one {
+				list += "List"
+			}
+			fmt.Fprintf(&body, "  %s(id: ID!): %s\n", one, s.ModelName)
+			fmt.Fprintf(&body, "  %s(limit: Int, skip: Int): [%s!]!\n", list, s.ModelName)
+		}
+		body.WriteString("}\n")
+	}
+
+	var b strings.Builder
+	b.WriteString("# Code generated by goodm gen graphql. DO NOT EDIT.\n")
+	scalars := make([]string, 0, len(g.scalars))
+	for s := range g.scalars {
+		scalars = append(scalars, s)
+	}
+	sort.Strings(scalars)
+	if len(scalars) > 0 {
+		b.WriteString("\n")
+	}
+	for _, s := range scalars {
+		fmt.Fprintf(&b, "scalar %s\n", s)
+	}
+	b.WriteString(body.String())
+	return b.String()
+}
+
+type gqlGenerator struct {
+	opt          GraphQLOptions
+	models       map[reflect.Type]string // registered model types and their names
+	byCollection map[string]string       // collection to model name
+	nested       map[reflect.Type]bool   // subdocument struct types referenced so far
+	scalars      map[string]bool         // custom scalars referenced so far
+	enums        strings.Builder
+}
+
+func (g *gqlGenerator) writeModel(b *strings.Builder, s *Schema) {
+	g.writeType(b, s.ModelName, s.modelType, s)
+}
+
+// writeType writes the object type for struct t. For models, schema supplies
+// the relations; it is nil for subdocuments.
+func (g *gqlGenerator) writeType(b *strings.Builder, name string, t reflect.Type, schema *Schema) {
+	fmt.Fprintf(b, "\ntype %s {\n", name)
+	taken := map[string]bool{}
+	relationFields := map[string]bool{}
+	if schema != nil {
+		for _, rel := range schema.Relations {
+			if rel.Kind != RelationBelongsTo {
+				relationFields[rel.Name] = true
+			}
+		}
+	}
+
+	for _, f := range internal.StructFields(t) {
+		if relationFields[f.Name] || populateTarget(f.Tag.Get("goodm")) != "" {
+			continue // written as relation fields below
+		}
+		prop, omitempty, ok := g.fieldName(f)
+		if !ok {
+			continue
+		}
+		tag := ParseGoodmTag(f.Tag.Get("goodm"))
+		typ := g.gqlType(f.Type)
+		if len(tag.Enum) > 0 && derefType(f.Type).Kind() == reflect.String {
+			if enum := g.enum(name+f.Name, tag.Enum); enum != "" {
+				typ = strings.Replace(typ, "String", enum, 1)
+			}
+		}
+		if omitempty || tag.Hidden {
+			typ = strings.TrimSuffix(typ, "!")
+		}
+		taken[prop] = true
+		directive := ""
+		if tag.Deprecated {
+			directive = " @deprecated"
+			if tag.DeprecatedNote != "" {
+				directive += fmt.Sprintf("(reason: \"%s\")", gqlStringEscaper.Replace(tag.DeprecatedNote))
+			}
+		}
+		fmt.Fprintf(b, "  %s: %s%s\n", prop, typ, directive)
+	}
+
+	if schema != nil {
+		for _, rel := range schema.Relations {
+			target := g.byCollection[rel.Collection]
+			if target == "" {
+				continue
+			}
+			var prop, typ string
+			switch rel.Kind {
+			case RelationBelongsTo:
+				prop = lowerCamel(trimIDSuffix(rel.Name))
+				typ = target
+				if f := schema.GetField(rel.LocalField); f != nil && strings.HasPrefix(f.Type, "[]") {
+					typ = "[" + target + "!]"
+				}
+			case RelationHasOne:
+				prop, typ = g.relationName(t, rel.Name), target
+			case RelationHasMany:
+				prop, typ = g.relationName(t, rel.Name), "["+target+"!]"
+			}
+			if taken[prop] {
+				continue
+			}
+			taken[prop] = true
+			fmt.Fprintf(b, "  %s: %s\n", prop, typ)
+		}
+	}
+	b.WriteString("}\n")
+}
+
+// fieldName returns the GraphQL field name for a struct field, whether it is
+// omitted when empty, and whether it is serialized at all.
+func (g *gqlGenerator) fieldName(f reflect.StructField) (string, bool, bool) {
+	if g.opt.BSONNames {
+		return wireName(f, true)
+	}
+	name, omitempty, ok := wireName(f, false)
+	if jsonName, _ := ParseBSONTag(f.Tag.Get("json")); jsonName == "" {
+		name = lowerCamel(f.Name)
+	}
+	return name, omitempty, ok
+}
+
+// relationName returns the field name for the has_one/has_many field goName.
+func (g *gqlGenerator) relationName(t reflect.Type, goName string) string {
+	if f, ok := t.FieldByName(goName); ok {
+		if jsonName, _ := ParseBSONTag(f.Tag.Get("json")); jsonName != "" && jsonName != "-" {
+			return jsonName
+		}
+	}
+	return lowerCamel(goName)
+}
+
+// gqlType returns the GraphQL type for a Go type.
+func (g *gqlGenerator) gqlType(t reflect.Type) string {
+	switch {
+	case t == objectIDType:
+		return "ID!"
+	case t == timeType:
+		g.scalars["DateTime"] = true
+		return "DateTime!"
+	case t == decimal128Type:
+		return "String!"
+	case bsonDocTypes[t]:
+		g.scalars["JSON"] = true
+		return "JSON"
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return strings.TrimSuffix(g.gqlType(t.Elem()), "!")
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "String" // base64
+		}
+		return "[" + g.gqlType(t.Elem()) + "]"
+	case reflect.Map, reflect.Interface:
+		g.scalars["JSON"] = true
+		return "JSON"
+	case reflect.Struct:
+		if name, ok := g.models[t]; ok {
+			return name + "!"
+		}
+		if t.Name() == "" {
+			g.scalars["JSON"] = true
+			return "JSON"
+		}
+		g.nested[t] = true
+		return t.Name() + "!"
+	case reflect.Bool:
+		return "Boolean!"
+	case reflect.String:
+		return "String!"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "Int!"
+	case reflect.Float32, reflect.Float64:
+		return "Float!"
+	}
+	g.scalars["JSON"] = true
+	return "JSON"
+}
+
+// enum writes an enum type named name and returns the name, or returns ""
+// when a value is not a valid GraphQL name.
+func (g *gqlGenerator) enum(name string, values []string) string {
+	for _, v := range values {
+		if !isGraphQLName(v) || v == "true" || v == "false" || v == "null" {
+			return ""
+		}
+	}
+	name = internal.ToExportedName(name)
+	fmt.Fprintf(&g.enums, "\nenum %s {\n", name)
+	for _, v := range values {
+		fmt.Fprintf(&g.enums, "  %s\n", v)
+	}
+	g.enums.WriteString("}\n")
+	return name
+}
+
+// isGraphQLName reports whether s matches /[_A-Za-z][_0-9A-Za-z]*/.
+func isGraphQLName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// lowerCamel lowercases the leading capital or initialism of a Go name:
+// "CreatedAt" → "createdAt", "ID" → "id", "URLPath" → "urlPath".
+func lowerCamel(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// trimIDSuffix strips a trailing ID or IDs from a Go field name.
+func trimIDSuffix(name string) string {
+	for _, suffix := range []string{"IDs", "Ids", "ID", "Id"} {
+		if trimmed := strings.TrimSuffix(name, suffix); trimmed != name && trimmed != "" {
+			if suffix == "IDs" || suffix == "Ids" {
+				return trimmed + "s"
+			}
+			return trimmed
+		}
+	}
+	return name
+}
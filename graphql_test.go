@@ -0,0 +1,58 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateGraphQL(t *testing.T) {
+	useTestStore(t)
+	registerRelationModels(t)
+
+	sdl := GenerateGraphQL(GetAll(), GraphQLOptions{Query: true})
+	for _, want := range []string{
+		"scalar DateTime",
+		"type testUser {",
+		"  id: ID!",
+		"  createdAt: DateTime!",
+		"  role: TestUserRole!",
+		"  profileID: ID!",
+		"  profile: testProfile",
+		"enum TestUserRole {\n  admin\n  user\n}",
+		"type testPost {",
+		"  tagIDs: [ID!]",
+		"  tags: [testTag!]",
+		"  author: testUser",
+		"type testRelAuthor {",
+		"  posts: [testRelPost!]",
+		"  settings: testRelSettings",
+		"type testAccount {",
+		"  passwordHash: String",
+		"  testRelSettingsList(limit: Int, skip: Int): [testRelSettings!]!",
+		"type Query {",
+		"  testUser(id: ID!): testUser",
+		"  testUsers(limit: Int, skip: Int): [testUser!]!",
+	} {
+		if !strings.Contains(sdl, want+"\n") {
+			t.Fatalf("expected %q in output:\n%s", want, sdl)
+		}
+	}
+
+	sdl = GenerateGraphQL(GetAll(), GraphQLOptions{BSONNames: true})
+	if !strings.Contains(sdl, "  _id: ID\n") || !strings.Contains(sdl, "  created_at: DateTime!\n") {
+		t.Fatalf("expected bson-named fields:\n%s", sdl)
+	}
+	if strings.Contains(sdl, "type Query") {
+		t.Fatal("expected no Query type without GraphQLOptions.Query")
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	for in, want := range map[string]string{
+		"CreatedAt": "createdAt", "ID": "id", "URLPath": "urlPath", "name": "name", "TagIDs": "tagIDs",
+	} {
+		if got := lowerCamel(in); got != want {
+			t.Fatalf("lowerCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
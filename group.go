@@ -0,0 +1,141 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// GroupSpec describes a group-by aggregation for GroupBy. Fields are bson
+// names of the model, or dotted paths into its subdocuments. Accumulator maps
+// go from output field to the field they aggregate.
+type GroupSpec struct {
+	By     []string          // fields to group by, stored in _id; empty puts all documents in one group
+	Filter interface{}       // optional $match applied before grouping
+	Count  string            // output field receiving each group's document count, if set
+	Sum    map[string]string // output field -> field summed
+	Avg    map[string]string // output field -> field averaged
+	Min    map[string]string // output field -> field minimum
+	Max    map[string]string // output field -> field maximum
+	Sort   bson.D            // optional $sort applied to the groups
+}
+
+// GroupCountResult is one group returned by GroupCount.
+type GroupCountResult struct {
+	Value interface{} `bson:"_id"`   // the field's value, nil for documents without it
+	Count int64       `bson:"count"` // documents with that value
+}
+
+// GroupBy groups the model's documents as described by spec and decodes one
+// result per group into results, a pointer to a slice. A single By field is
+// stored as _id itself; several are stored as an _id subdocument keyed by
+// field name. It runs through Aggregate, so middleware sees OpAggregate.
+//
+// Example:
+//
+//	var totals []struct {
+//	    Customer bson.ObjectID `bson:"_id"`
+//	    Orders   int64         `bson:"orders"`
+//	    Revenue  float64       `bson:"revenue"`
+//	}
+//	err := goodm.GroupBy(ctx, &Order{}, goodm.GroupSpec{
+//	    By:    []string{"customer"},
+//	    Count: "orders",
+//	    Sum:   map[string]string{"revenue": "total"},
+//	}, &totals)
+func GroupBy(ctx context.Context, model interface{}, spec GroupSpec, results interface{}, opts ...PipelineOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	stages, err := groupStages(schema, spec)
+	if err != nil {
+		return err
+	}
+	return Aggregate(ctx, model, stages, results, opts...)
+}
+
+// GroupCount counts the model's documents per distinct value of field, most
+// frequent first.
+//
+// Example:
+//
+//	counts, err := goodm.GroupCount(ctx, &User{}, "role")
+//	// [{Value: "user", Count: 120} {Value: "admin", Count: 3}]
+func GroupCount(ctx context.Context, model interface{}, field string, opts ...PipelineOptions) ([]GroupCountResult, error) {
+	var results []GroupCountResult
+	err := GroupBy(ctx, model, GroupSpec{
+		By:    []string{field},
+		Count: "count",
+		Sort:  bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}},
+	}, &results, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// groupStages returns the aggregation stages for spec, checking its fields
+// against the schema.
+func groupStages(schema *Schema, spec GroupSpec) ([]bson.D, error) {
+	checkField := func(name string) error {
+		if !hasFieldPath(schema.Fields, name) {
+			return fmt.Errorf("goodm: group by: unknown field %q in %s", name, schema.ModelName)
+		}
+		return nil
+	}
+
+	var id interface{}
+	switch len(spec.By) {
+	case 0:
+	case 1:
+		if err := checkField(spec.By[0]); err != nil {
+			return nil, err
+		}
+		id = "$" + spec.By[0]
+	default:
+		key := make(bson.D, 0, len(spec.By))
+		for _, name := range spec.By {
+			if err := checkField(name); err != nil {
+				return nil, err
+			}
+			key = append(key, bson.E{Key: name, Value: "$" + name})
+		}
+		id = key
+	}
+
+	group := bson.D{{Key: "_id", Value: id}}
+	if spec.Count != "" {
+		group = append(group, bson.E{Key: spec.Count, Value: bson.D{{Key: "$sum", Value: 1}}})
+	}
+	for _, acc := range []struct {
+		op     string
+		fields map[string]string
+	}{
+		{"$sum", spec.Sum}, {"$avg", spec.Avg}, {"$min", spec.Min}, {"$max", spec.Max},
+	} {
+		outputs := make([]string, 0, len(acc.fields))
+		for out := range acc.fields {
+			outputs = append(outputs, out)
+		}
+		sort.Strings(outputs)
+		for _, out := range outputs {
+			if err := checkField(acc.fields[out]); err != nil {
+				return nil, err
+			}
+			group = append(group, bson.E{Key: out, Value: bson.D{{Key: acc.op, Value: "$" + acc.fields[out]}}})
+		}
+	}
+
+	var stages []bson.D
+	if spec.Filter != nil {
+		stages = append(stages, bson.D{{Key: "$match", Value: spec.Filter}})
+	}
+	stages = append(stages, bson.D{{Key: "$group", Value: group}})
+	if len(spec.Sort) > 0 {
+		stages = append(stages, bson.D{{Key: "$sort", Value: spec.Sort}})
+	}
+	return stages, nil
+}
@@ -0,0 +1,127 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestGroupStages(t *testing.T) {
+	useTestStore(t)
+	schema, err := getSchemaForModel(&testOrder{})
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+
+	stages, err := groupStages(schema, GroupSpec{
+		By:     []string{"name", "address.city"},
+		Filter: bson.D{{Key: "name", Value: "x"}},
+		Count:  "orders",
+		Sum:    map[string]string{"qty": "items.quantity", "a": "__v"},
+		Max:    map[string]string{"latest": "created_at"},
+	})
+	if err != nil {
+		t.Fatalf("groupStages: %v", err)
+	}
+
+	want := []bson.D{
+		{{Key: "$match", Value: bson.D{{Key: "name", Value: "x"}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "name", Value: "$name"}, {Key: "address.city", Value: "$address.city"}}},
+			{Key: "orders", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "a", Value: bson.D{{Key: "$sum", Value: "$__v"}}},
+			{Key: "qty", Value: bson.D{{Key: "$sum", Value: "$items.quantity"}}},
+			{Key: "latest", Value: bson.D{{Key: "$max", Value: "$created_at"}}},
+		}}},
+	}
+	if !reflect.DeepEqual(stages, want) {
+		t.Fatalf("unexpected stages:\n got %v\nwant %v", stages, want)
+	}
+}
+
+func TestGroupStages_UnknownField(t *testing.T) {
+	useTestStore(t)
+	schema, err := getSchemaForModel(&testUser{})
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+
+	for _, spec := range []GroupSpec{
+		{By: []string{"nope"}},
+		{By: []string{"role", "nope"}},
+		{Avg: map[string]string{"avg": "nope"}},
+	} {
+		if _, err := groupStages(schema, spec); err == nil || !strings.Contains(err.Error(), `unknown field "nope"`) {
+			t.Fatalf("expected unknown field error for %+v, got %v", spec, err)
+		}
+	}
+}
+
+func TestGroupCount_Stages(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	defer ClearMiddleware()
+
+	var seen *OpInfo
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		seen = op
+		return errors.New("blocked")
+	})
+
+	if _, err := GroupCount(ctx, &testUser{}, "role"); err == nil || err.Error() != "blocked" {
+		t.Fatalf("expected middleware error, got %v", err)
+	}
+	want := []bson.D{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$role"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	if seen == nil || seen.Operation != OpAggregate || !reflect.DeepEqual(seen.Filter, want) {
+		t.Fatalf("unexpected op: %+v", seen)
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestGroupBy_Integration(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i, role := range []string{"admin", "user", "user"} {
+		user := &testUser{Email: fmt.Sprintf("group%d@test.com", i), Name: "Group", Age: 20 + i, Role: role}
+		if err := Create(ctx, user); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	counts, err := GroupCount(ctx, &testUser{}, "role")
+	if err != nil {
+		t.Fatalf("group count: %v", err)
+	}
+	if len(counts) != 2 || counts[0].Value != "user" || counts[0].Count != 2 || counts[1].Count != 1 {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+
+	var ages []struct {
+		Role  string `bson:"_id"`
+		Total int    `bson:"total"`
+	}
+	err = GroupBy(ctx, &testUser{}, GroupSpec{
+		By:   []string{"role"},
+		Sum:  map[string]string{"total": "age"},
+		Sort: bson.D{{Key: "_id", Value: 1}},
+	}, &ages)
+	if err != nil {
+		t.Fatalf("group by: %v", err)
+	}
+	if len(ages) != 2 || ages[0].Total != 20 || ages[1].Total != 43 {
+		t.Fatalf("unexpected sums: %+v", ages)
+	}
+}
@@ -0,0 +1,86 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// hiddenProjection returns an exclusion projection for fields tagged
+// `goodm:"select=false"`. Returns nil when the schema has no hidden fields or
+// the caller asked for them via WithHidden.
+func hiddenProjection(schema *Schema, includeHidden bool) bson.D {
+	if includeHidden {
+		return nil
+	}
+	var proj bson.D
+	for _, f := range schema.Fields {
+		if f.Hidden {
+			proj = append(proj, bson.E{Key: f.BSONName, Value: 0})
+		}
+	}
+	return proj
+}
+
+// unloadedHidden returns the select=false fields that model does not hold:
+// zero on the model and zero when goodm last loaded or saved it. A hidden
+// field that was loaded with WithHidden and then cleared is not included, so
+// Update writes the zero value instead of restoring the stored one.
+func unloadedHidden(model interface{}, schema *Schema) []FieldSchema {
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	var orig reflect.Value
+	if o := Original(model); o != nil {
+		orig = reflect.ValueOf(o).Elem()
+	}
+
+	var fields []FieldSchema
+	for _, f := range schema.Fields {
+		if !f.Hidden {
+			continue
+		}
+		fv := v.FieldByName(f.Name)
+		if !fv.IsValid() || !fv.IsZero() {
+			continue
+		}
+		if orig.IsValid() && !orig.FieldByName(f.Name).IsZero() {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// loadHiddenCarry reads the stored values of the hidden fields model does not
+// hold, so that an Update of a model loaded without WithHidden does not erase
+// them. Returns nil when there is nothing to carry over.
+func loadHiddenCarry(ctx context.Context, coll collection, id bson.ObjectID, model interface{}, schema *Schema) (bson.M, error) {
+	hidden := unloadedHidden(model, schema)
+	if len(hidden) == 0 {
+		return nil, nil
+	}
+	proj := bson.D{{Key: "_id", Value: 0}}
+	for _, f := range hidden {
+		proj = append(proj, bson.E{Key: f.BSONName, Value: 1})
+	}
+
+	var stored bson.M
+	err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}},
+		withComment(ctx, options.FindOne()).SetProjection(proj)).Decode(&stored)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil // the save itself will report ErrNotFound
+		}
+		return nil, fmt.Errorf("goodm: failed to read hidden fields: %w", err)
+	}
+	if len(stored) == 0 {
+		return nil, nil
+	}
+	return stored, nil
+}
@@ -0,0 +1,186 @@
+package goodm
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestParseGoodmTag_SelectFalse(t *testing.T) {
+	if fs := ParseGoodmTag("select=false"); !fs.Hidden {
+		t.Fatal("expected select=false to mark field hidden")
+	}
+	if fs := ParseGoodmTag("select=true"); fs.Hidden {
+		t.Fatal("expected select=true to leave field visible")
+	}
+}
+
+func TestHiddenProjection(t *testing.T) {
+	registerTestModels()
+	defer unregisterTestModels()
+
+	schema, _ := Get("testAccount")
+
+	proj := hiddenProjection(schema, false)
+	if len(proj) != 1 || proj[0].Key != "password_hash" || proj[0].Value != 0 {
+		t.Fatalf("unexpected projection: %v", proj)
+	}
+
+	if proj := hiddenProjection(schema, true); proj != nil {
+		t.Fatalf("expected nil projection with hidden fields included, got %v", proj)
+	}
+
+	userSchema, _ := Get("testUser")
+	if proj := hiddenProjection(userSchema, false); proj != nil {
+		t.Fatalf("expected nil projection for schema without hidden fields, got %v", proj)
+	}
+}
+
+func TestBuildReplacement_WithCarry(t *testing.T) {
+	a := &testAccount{Username: "alice"}
+	a.ID = bson.NewObjectID()
+
+	result, err := buildReplacement(a, nil, bson.M{"password_hash": "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc, ok := result.(bson.M)
+	if !ok {
+		t.Fatalf("expected bson.M, got %T", result)
+	}
+	if doc["password_hash"] != "secret" {
+		t.Fatalf("expected carried password_hash, got %v", doc["password_hash"])
+	}
+	if doc["username"] != "alice" {
+		t.Fatalf("expected username to remain, got %v", doc["username"])
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestFind_HiddenFieldsExcluded(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	a := &testAccount{Username: "alice", PasswordHash: "h4sh"}
+	if err := Create(ctx, a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	found := &testAccount{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: a.ID}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.PasswordHash != "" {
+		t.Fatalf("expected password_hash to be hidden, got %q", found.PasswordHash)
+	}
+
+	var all []testAccount
+	if err := Find(ctx, bson.D{}, &all); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(all) != 1 || all[0].PasswordHash != "" {
+		t.Fatalf("expected hidden field excluded from Find, got %+v", all)
+	}
+
+	withHidden := &testAccount{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: a.ID}}, withHidden, WithHidden()); err != nil {
+		t.Fatalf("find one with hidden: %v", err)
+	}
+	if withHidden.PasswordHash != "h4sh" {
+		t.Fatalf("expected password_hash with WithHidden, got %q", withHidden.PasswordHash)
+	}
+}
+
+func TestUpdate_PreservesUnloadedHiddenFields(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	a := &testAccount{Username: "alice", PasswordHash: "h4sh"}
+	if err := Create(ctx, a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	loaded := &testAccount{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: a.ID}}, loaded); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	loaded.Username = "alice2"
+	if err := Update(ctx, loaded); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	check := &testAccount{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: a.ID}}, check, WithHidden()); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if check.Username != "alice2" {
+		t.Fatalf("expected username alice2, got %q", check.Username)
+	}
+	if check.PasswordHash != "h4sh" {
+		t.Fatalf("expected password_hash preserved, got %q", check.PasswordHash)
+	}
+}
+
+type testAPIClient struct {
+	Model  `bson:",inline"`
+	Name   string `bson:"name"`
+	Secret string `bson:"secret" goodm:"select=false,immutable"`
+	Token  string `bson:"token"  goodm:"select=false"`
+}
+
+func TestUpdate_HiddenFieldsLoadedWithHidden(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testAPIClient{}, "test_api_clients"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testAPIClient")
+		registryMu.Unlock()
+	})
+
+	c := &testAPIClient{Name: "ci", Secret: "s3cret", Token: "t0ken"}
+	if err := Create(ctx, c); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Loaded without hidden fields: the immutable secret is not reported as
+	// changed, and both hidden fields are carried over.
+	loaded := &testAPIClient{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: c.ID}}, loaded); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	loaded.Name = "ci2"
+	if err := Update(ctx, loaded); err != nil {
+		t.Fatalf("update without hidden fields: %v", err)
+	}
+
+	// Loaded with hidden fields: clearing the token is saved.
+	full := &testAPIClient{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: c.ID}}, full, WithHidden()); err != nil {
+		t.Fatalf("find one with hidden: %v", err)
+	}
+	if full.Name != "ci2" || full.Secret != "s3cret" || full.Token != "t0ken" {
+		t.Fatalf("expected hidden fields preserved, got %+v", full)
+	}
+	full.Token = ""
+	if err := Update(ctx, full); err != nil {
+		t.Fatalf("update clearing token: %v", err)
+	}
+
+	check := &testAPIClient{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: c.ID}}, check, WithHidden()); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if check.Token != "" || check.Secret != "s3cret" {
+		t.Fatalf("expected token cleared and secret kept, got %+v", check)
+	}
+
+	check.Secret = ""
+	var verrs ValidationErrors
+	if err := Update(ctx, check); !errors.As(err, &verrs) {
+		t.Fatalf("expected clearing a loaded immutable field to fail, got %v", err)
+	}
+}
@@ -31,3 +31,10 @@ type BeforeDelete interface {
 type AfterDelete interface {
 	AfterDelete(ctx context.Context) error
 }
+
+// ComputeVirtuals is called after a document is decoded by Find, FindOne,
+// Populate, or BatchPopulate. Use it to fill non-persisted fields derived from
+// stored values (tag them `bson:"-"` so they are never written).
+type ComputeVirtuals interface {
+	ComputeVirtuals(ctx context.Context) error
+}
@@ -0,0 +1,180 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ImportConflict selects what Import does with a document that collides
+// with a stored one on _id or a unique field.
+type ImportConflict int
+
+const (
+	// ImportFail stops the import with an error. This is the default.
+	ImportFail ImportConflict = iota
+
+	// ImportSkip keeps the stored document and continues.
+	ImportSkip
+
+	// ImportOverwrite replaces the stored document with the same _id.
+	ImportOverwrite
+)
+
+// ImportOptions configures the Import operation.
+type ImportOptions struct {
+	DB         *mongo.Database
+	OnConflict ImportConflict // what to do with colliding documents (default ImportFail)
+	RunHooks   bool           // call each model's BeforeCreate and AfterCreate hooks
+}
+
+// ImportResult counts the documents Import wrote.
+type ImportResult struct {
+	Inserted    int
+	Overwritten int
+	Skipped     int
+}
+
+// Import reads documents in Export's format from r and writes each into its
+// collection, as the registered model of that collection. Every document is
+// decoded into its model and validated against the schema before it is
+// written, and its _id, timestamps, and version are kept as exported.
+// Documents of a polymorphic collection are matched to their model by
+// discriminator value. With opts.RunHooks, BeforeCreate runs before
+// validation and AfterCreate after the write, as they do in Create.
+//
+// Each document is written through the middleware chain as an OpCreate.
+// Import stops at the first record that fails, reporting its record number;
+// documents written before it are kept, so run Import inside WithTransaction
+// for an all-or-nothing import.
+//
+// Example:
+//
+//	f, _ := os.Open("backup.jsonl")
+//	defer f.Close()
+//	res, err := goodm.Import(ctx, f, goodm.ImportOptions{OnConflict: goodm.ImportSkip})
+func Import(ctx context.Context, r io.Reader, opts ...ImportOptions) (*ImportResult, error) {
+	var opt ImportOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	n := 0
+	err = ReadExport(r, func(rec ExportRecord) error {
+		n++
+		if err := importRecord(ctx, db, rec, opt, result); err != nil {
+			return fmt.Errorf("goodm: import record %d (%s): %w", n, rec.Collection, err)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// importRecord decodes, validates, and writes one exported document.
+func importRecord(ctx context.Context, db dbHandle, rec ExportRecord, opt ImportOptions, result *ImportResult) error {
+	schema, err := importSchema(rec)
+	if err != nil {
+		return err
+	}
+	model := reflect.New(schema.modelType).Interface()
+	if err := unmarshalBSON(rec.Document, model); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", schema.ModelName, err)
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpCreate, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: model,
+	}, func(ctx context.Context) error {
+		bindOpDB(ctx, db)
+		id, err := getModelID(model)
+		if err != nil {
+			return err
+		}
+		if id.IsZero() {
+			id = bson.NewObjectID()
+			setModelID(model, id)
+		}
+
+		if opt.RunHooks {
+			if hook, ok := model.(BeforeCreate); ok {
+				if err := hook.BeforeCreate(ctx); err != nil {
+					return err
+				}
+			}
+		}
+		if errs := Validate(model, schema); len(errs) > 0 {
+			return ValidationErrors(errs)
+		}
+
+		doc, err := insertDocument(model, schema)
+		if err != nil {
+			return err
+		}
+		coll := getCollection(db, schema)
+		_, err = coll.InsertOne(ctx, doc, withComment(ctx, options.InsertOne()))
+		switch {
+		case err == nil:
+			result.Inserted++
+		case !mongo.IsDuplicateKeyError(err) || opt.OnConflict == ImportFail:
+			return fmt.Errorf("insert %s failed: %w", id.Hex(), err)
+		case opt.OnConflict == ImportSkip:
+			result.Skipped++
+			return nil
+		default:
+			res, err := coll.ReplaceOne(ctx, bson.D{{Key: "_id", Value: id}}, doc,
+				withComment(ctx, options.Replace()).SetUpsert(true))
+			if err != nil {
+				return fmt.Errorf("overwrite %s failed: %w", id.Hex(), err)
+			}
+			if res.MatchedCount > 0 {
+				result.Overwritten++
+			} else {
+				result.Inserted++
+			}
+		}
+		track(model)
+
+		if opt.RunHooks {
+			if hook, ok := model.(AfterCreate); ok {
+				if err := hook.AfterCreate(ctx); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// importSchema returns the registered schema an exported document belongs
+// to, choosing among the kinds of a polymorphic collection by discriminator.
+func importSchema(rec ExportRecord) (*Schema, error) {
+	var match *Schema
+	for _, s := range GetAll() {
+		if s.Collection != rec.Collection {
+			continue
+		}
+		if s.Discriminator == "" {
+			return s, nil
+		}
+		match = s
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no registered model for collection %q", rec.Collection)
+	}
+
+	kind, ok := rec.Document.Lookup(match.Discriminator).StringValueOK()
+	if s := kindSchemas(rec.Collection)[kind]; ok && s != nil {
+		return s, nil
+	}
+	return nil, fmt.Errorf("no registered model of collection %q has %s %q", rec.Collection, match.Discriminator, kind)
+}
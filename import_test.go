@@ -0,0 +1,126 @@
+package goodm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// exportLine returns one Export record for doc in collection.
+func exportLine(t *testing.T, collection string, doc interface{}) string {
+	t.Helper()
+	raw, err := marshalBSON(doc)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	line, err := bson.MarshalExtJSON(ExportRecord{Collection: collection, Document: raw}, true, false)
+	if err != nil {
+		t.Fatalf("marshal ext json: %v", err)
+	}
+	return string(line) + "\n"
+}
+
+func TestImport_RoundTrip(t *testing.T) {
+	ctx := useTestStore(t)
+	u := &testUser{Email: "a@test.com", Name: "A", Age: 3}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	o := &testOrder{Name: "o1", Address: testAddress{Street: "Main", City: "Oslo"}}
+	if err := Create(ctx, o); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Export(ctx, []interface{}{&testUser{}, &testOrder{}}, &buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	activeTestStore().Reset()
+	res, err := Import(ctx, &buf)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if res.Inserted != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	var got testUser
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, &got); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got.Email != "a@test.com" || !got.CreatedAt.Equal(u.CreatedAt.Truncate(1e6)) {
+		t.Errorf("document not imported as exported: %+v", got)
+	}
+}
+
+func TestImport_Conflicts(t *testing.T) {
+	ctx := useTestStore(t)
+	u := &testUser{Email: "a@test.com", Name: "A", Age: 3}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	imported := *u
+	imported.Age = 40
+	other := &testUser{Email: "b@test.com", Name: "B"}
+	other.ID = bson.NewObjectID()
+	input := exportLine(t, "test_users", &imported) + exportLine(t, "test_users", other)
+
+	_, err := Import(ctx, strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "record 1") {
+		t.Fatalf("expected a conflict error on record 1, got %v", err)
+	}
+
+	res, err := Import(ctx, strings.NewReader(input), ImportOptions{OnConflict: ImportSkip})
+	if err != nil {
+		t.Fatalf("Import skip: %v", err)
+	}
+	if res.Skipped != 1 || res.Inserted != 1 {
+		t.Errorf("unexpected skip result: %+v", res)
+	}
+
+	res, err = Import(ctx, strings.NewReader(input), ImportOptions{OnConflict: ImportOverwrite})
+	if err != nil {
+		t.Fatalf("Import overwrite: %v", err)
+	}
+	if res.Overwritten != 2 {
+		t.Errorf("unexpected overwrite result: %+v", res)
+	}
+	var got testUser
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, &got); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got.Age != 40 {
+		t.Errorf("Age = %d, want 40", got.Age)
+	}
+}
+
+func TestImport_ValidationAndHooks(t *testing.T) {
+	ctx := useTestStore(t)
+	input := exportLine(t, "test_users", &testUser{Name: "No Email"})
+	_, err := Import(ctx, strings.NewReader(input))
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	hooked := &testHookUser{Email: "h@test.com", Name: "H"}
+	hooked.ID = bson.NewObjectID()
+	input = exportLine(t, "test_hook_users", hooked)
+	if _, err := Import(ctx, strings.NewReader(input), ImportOptions{RunHooks: true}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	var got testHookUser
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: hooked.ID}}, &got); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(got.Events) != 1 || got.Events[0] != "before_create" {
+		t.Errorf("Events = %v, want [before_create]", got.Events)
+	}
+
+	_, err = Import(ctx, strings.NewReader(exportLine(t, "nowhere", bson.D{})))
+	if err == nil || !strings.Contains(err.Error(), `"nowhere"`) {
+		t.Errorf("expected an unknown collection error, got %v", err)
+	}
+}
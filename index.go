@@ -2,8 +2,9 @@ package goodm
 
 // CompoundIndex represents a multi-field index on a MongoDB collection.
 type CompoundIndex struct {
-	Fields []string
-	Unique bool
+	Fields    []string `json:"fields"`
+	Unique    bool     `json:"unique,omitempty"`
+	Collation string   `json:"collation,omitempty"` // collation spec (see ParseCollation); "" uses the collection default
 }
 
 // NewCompoundIndex creates a non-unique compound index on the given fields.
@@ -15,3 +16,10 @@ func NewCompoundIndex(fields ...string) CompoundIndex {
 func NewUniqueCompoundIndex(fields ...string) CompoundIndex {
 	return CompoundIndex{Fields: fields, Unique: true}
 }
+
+// WithCollation returns a copy of the index using the given collation spec,
+// e.g. "en_ci" for case-insensitive uniqueness. See ParseCollation.
+func (ci CompoundIndex) WithCollation(spec string) CompoundIndex {
+	ci.Collation = spec
+	return ci
+}
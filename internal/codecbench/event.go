@@ -0,0 +1,42 @@
+// Package codecbench declares a model with a codec generated by goodm gen
+// codecs, to test generated codecs against the bson struct codec and
+// benchmark the two.
+package codecbench
+
+import (
+	"time"
+
+	"github.com/dwoolworth/goodm"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+//go:generate go test -run TestGeneratedCodec -update
+
+// Status is a named string type, written without reflection.
+type Status string
+
+// Address is a subdocument, written through goodm's codec registry.
+type Address struct {
+	City string `bson:"city"`
+	Zip  string `bson:"zip"`
+}
+
+// Event is a small document typical of a hot write path.
+type Event struct {
+	goodm.Model `bson:",inline"`
+	Name        string         `bson:"name" goodm:"required"`
+	Status      Status         `bson:"status"`
+	Count       int            `bson:"count"`
+	Total       int64          `bson:"total"`
+	Level       int16          `bson:"level"`
+	Score       float64        `bson:"score"`
+	Active      bool           `bson:"active"`
+	At          time.Time      `bson:"at"`
+	Owner       bson.ObjectID  `bson:"owner"`
+	Tags        []string       `bson:"tags"`
+	Note        *string        `bson:"note"`
+	Due         *time.Time     `bson:"due,omitempty"`
+	Address     *Address       `bson:"address,omitempty"`
+	Extra       map[string]int `bson:"extra,omitempty"`
+	Scratch     string         `bson:"-"`
+}
@@ -0,0 +1,266 @@
+// Code generated by goodm gen codecs. DO NOT EDIT.
+
+// This file uses go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore, an
+// experimental driver package without a compatibility promise. Regenerate it
+// after upgrading the driver.
+
+package codecbench
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dwoolworth/goodm"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore"
+)
+
+// MarshalBSON encodes m as the bson struct codec would, without reflection.
+func (m *Event) MarshalBSON() ([]byte, error) {
+	dst := make([]byte, 0, 314+len(m.Name)+len(m.Status)+16*len(m.Tags))
+	idx, dst := bsoncore.AppendDocumentStart(dst)
+	var err error
+	if !m.Model.ID.IsZero() {
+		dst = bsoncore.AppendObjectIDElement(dst, "_id", m.Model.ID)
+	}
+	dst = bsoncore.AppendDateTimeElement(dst, "created_at", m.Model.CreatedAt.UnixMilli())
+	dst = bsoncore.AppendDateTimeElement(dst, "updated_at", m.Model.UpdatedAt.UnixMilli())
+	dst = goodmCodecAppendInt(dst, "__v", int64(m.Model.Version))
+	dst = bsoncore.AppendStringElement(dst, "name", m.Name)
+	dst = bsoncore.AppendStringElement(dst, "status", string(m.Status))
+	dst = goodmCodecAppendInt(dst, "count", int64(m.Count))
+	dst = bsoncore.AppendInt64Element(dst, "total", m.Total)
+	dst = bsoncore.AppendInt32Element(dst, "level", int32(m.Level))
+	dst = bsoncore.AppendDoubleElement(dst, "score", m.Score)
+	dst = bsoncore.AppendBooleanElement(dst, "active", m.Active)
+	dst = bsoncore.AppendDateTimeElement(dst, "at", m.At.UnixMilli())
+	dst = bsoncore.AppendObjectIDElement(dst, "owner", m.Owner)
+	dst = goodmCodecAppendStrings(dst, "tags", m.Tags)
+	if m.Note == nil {
+		dst = bsoncore.AppendNullElement(dst, "note")
+	} else {
+		dst = bsoncore.AppendStringElement(dst, "note", *m.Note)
+	}
+	if m.Due != nil && !m.Due.IsZero() {
+		dst = bsoncore.AppendDateTimeElement(dst, "due", (*m.Due).UnixMilli())
+	}
+	if m.Address != nil {
+		if dst, err = goodmCodecAppend(dst, "address", &m.Address); err != nil {
+			return nil, fmt.Errorf("goodm: encoding Event.address: %w", err)
+		}
+	}
+	if len(m.Extra) != 0 {
+		if dst, err = goodmCodecAppend(dst, "extra", &m.Extra); err != nil {
+			return nil, fmt.Errorf("goodm: encoding Event.extra: %w", err)
+		}
+	}
+	return bsoncore.AppendDocumentEnd(dst, idx)
+}
+
+// UnmarshalBSON decodes data into m as the bson struct codec would, without
+// reflection.
+func (m *Event) UnmarshalBSON(data []byte) error {
+	if length, _, ok := bsoncore.ReadLength(data); !ok || int(length) != len(data) || length < 5 {
+		return fmt.Errorf("goodm: decoding Event: malformed document")
+	}
+	for rem := data[4 : len(data)-1]; len(rem) > 0; {
+		elem, rest, ok := bsoncore.ReadElement(rem)
+		if !ok {
+			return fmt.Errorf("goodm: decoding Event: malformed document")
+		}
+		rem = rest
+		v := elem.Value()
+		switch string(elem.KeyBytes()) {
+		case "_id":
+			if x, ok := v.ObjectIDOK(); ok {
+				m.Model.ID = bson.ObjectID(x)
+			} else if err := goodmCodecDecode(v, &m.Model.ID); err != nil {
+				return fmt.Errorf("goodm: decoding Event._id: %w", err)
+			}
+		case "created_at":
+			if x, ok := v.DateTimeOK(); ok {
+				m.Model.CreatedAt = time.UnixMilli(x).UTC()
+			} else if err := goodmCodecDecode(v, &m.Model.CreatedAt); err != nil {
+				return fmt.Errorf("goodm: decoding Event.created_at: %w", err)
+			}
+		case "updated_at":
+			if x, ok := v.DateTimeOK(); ok {
+				m.Model.UpdatedAt = time.UnixMilli(x).UTC()
+			} else if err := goodmCodecDecode(v, &m.Model.UpdatedAt); err != nil {
+				return fmt.Errorf("goodm: decoding Event.updated_at: %w", err)
+			}
+		case "__v":
+			if x, ok := goodmCodecInt(v, 64); ok {
+				m.Model.Version = int(x)
+			} else if err := goodmCodecDecode(v, &m.Model.Version); err != nil {
+				return fmt.Errorf("goodm: decoding Event.__v: %w", err)
+			}
+		case "name":
+			if x, ok := v.StringValueOK(); ok {
+				m.Name = x
+			} else if err := goodmCodecDecode(v, &m.Name); err != nil {
+				return fmt.Errorf("goodm: decoding Event.name: %w", err)
+			}
+		case "status":
+			if x, ok := v.StringValueOK(); ok {
+				m.Status = Status(x)
+			} else if err := goodmCodecDecode(v, &m.Status); err != nil {
+				return fmt.Errorf("goodm: decoding Event.status: %w", err)
+			}
+		case "count":
+			if x, ok := goodmCodecInt(v, 64); ok {
+				m.Count = int(x)
+			} else if err := goodmCodecDecode(v, &m.Count); err != nil {
+				return fmt.Errorf("goodm: decoding Event.count: %w", err)
+			}
+		case "total":
+			if x, ok := goodmCodecInt(v, 64); ok {
+				m.Total = x
+			} else if err := goodmCodecDecode(v, &m.Total); err != nil {
+				return fmt.Errorf("goodm: decoding Event.total: %w", err)
+			}
+		case "level":
+			if x, ok := goodmCodecInt(v, 16); ok {
+				m.Level = int16(x)
+			} else if err := goodmCodecDecode(v, &m.Level); err != nil {
+				return fmt.Errorf("goodm: decoding Event.level: %w", err)
+			}
+		case "score":
+			if x, ok := v.DoubleOK(); ok {
+				m.Score = x
+			} else if err := goodmCodecDecode(v, &m.Score); err != nil {
+				return fmt.Errorf("goodm: decoding Event.score: %w", err)
+			}
+		case "active":
+			if x, ok := v.BooleanOK(); ok {
+				m.Active = x
+			} else if err := goodmCodecDecode(v, &m.Active); err != nil {
+				return fmt.Errorf("goodm: decoding Event.active: %w", err)
+			}
+		case "at":
+			if x, ok := v.DateTimeOK(); ok {
+				m.At = time.UnixMilli(x).UTC()
+			} else if err := goodmCodecDecode(v, &m.At); err != nil {
+				return fmt.Errorf("goodm: decoding Event.at: %w", err)
+			}
+		case "owner":
+			if x, ok := v.ObjectIDOK(); ok {
+				m.Owner = bson.ObjectID(x)
+			} else if err := goodmCodecDecode(v, &m.Owner); err != nil {
+				return fmt.Errorf("goodm: decoding Event.owner: %w", err)
+			}
+		case "tags":
+			if x, ok := goodmCodecStrings(v); ok {
+				m.Tags = x
+			} else if err := goodmCodecDecode(v, &m.Tags); err != nil {
+				return fmt.Errorf("goodm: decoding Event.tags: %w", err)
+			}
+		case "note":
+			if v.Type == bsoncore.TypeNull {
+				m.Note = nil
+			} else if x, ok := v.StringValueOK(); ok {
+				y := x
+				m.Note = &y
+			} else if err := goodmCodecDecode(v, &m.Note); err != nil {
+				return fmt.Errorf("goodm: decoding Event.note: %w", err)
+			}
+		case "due":
+			if v.Type == bsoncore.TypeNull {
+				m.Due = nil
+			} else if x, ok := v.DateTimeOK(); ok {
+				y := time.UnixMilli(x).UTC()
+				m.Due = &y
+			} else if err := goodmCodecDecode(v, &m.Due); err != nil {
+				return fmt.Errorf("goodm: decoding Event.due: %w", err)
+			}
+		case "address":
+			if err := goodmCodecDecode(v, &m.Address); err != nil {
+				return fmt.Errorf("goodm: decoding Event.address: %w", err)
+			}
+		case "extra":
+			if err := goodmCodecDecode(v, &m.Extra); err != nil {
+				return fmt.Errorf("goodm: decoding Event.extra: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// goodmCodecAppendInt appends an int as the bson struct codec writes it: as
+// an int32 if it fits, otherwise as an int64.
+func goodmCodecAppendInt(dst []byte, key string, i int64) []byte {
+	if i >= -1<<31 && i < 1<<31 {
+		return bsoncore.AppendInt32Element(dst, key, int32(i))
+	}
+	return bsoncore.AppendInt64Element(dst, key, i)
+}
+
+// goodmCodecAppendStrings appends a []string, or null if it is nil.
+func goodmCodecAppendStrings(dst []byte, key string, s []string) []byte {
+	if s == nil {
+		return bsoncore.AppendNullElement(dst, key)
+	}
+	idx, dst := bsoncore.AppendArrayElementStart(dst, key)
+	for i, v := range s {
+		dst = bsoncore.AppendStringElement(dst, strconv.Itoa(i), v)
+	}
+	dst, _ = bsoncore.AppendArrayEnd(dst, idx)
+	return dst
+}
+
+// goodmCodecAppend appends the value v points to, encoded with goodm's
+// codec registry.
+func goodmCodecAppend(dst []byte, key string, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := bson.NewEncoder(bson.NewDocumentWriter(&buf))
+	enc.SetRegistry(goodm.CodecRegistry())
+	if err := enc.Encode(bson.D{{Key: key, Value: v}}); err != nil {
+		return dst, err
+	}
+	doc := buf.Bytes()
+	return append(dst, doc[4:len(doc)-1]...), nil
+}
+
+// goodmCodecDecode decodes v into the value dst points to with goodm's codec
+// registry.
+func goodmCodecDecode(v bsoncore.Value, dst interface{}) error {
+	return bson.RawValue{Type: bson.Type(v.Type), Value: v.Data}.UnmarshalWithRegistry(goodm.CodecRegistry(), dst)
+}
+
+// goodmCodecInt reads an int32 or int64 that fits in bits.
+func goodmCodecInt(v bsoncore.Value, bits int) (int64, bool) {
+	var i int64
+	switch v.Type {
+	case bsoncore.TypeInt32:
+		i = int64(v.Int32())
+	case bsoncore.TypeInt64:
+		i = v.Int64()
+	default:
+		return 0, false
+	}
+	if bits < 64 && (i < -1<<(bits-1) || i >= 1<<(bits-1)) {
+		return 0, false
+	}
+	return i, true
+}
+
+// goodmCodecStrings reads an array of strings.
+func goodmCodecStrings(v bsoncore.Value) ([]string, bool) {
+	arr, ok := v.ArrayOK()
+	if !ok {
+		return nil, false
+	}
+	values, err := arr.Values()
+	if err != nil {
+		return nil, false
+	}
+	s := make([]string, len(values))
+	for i, e := range values {
+		if s[i], ok = e.StringValueOK(); !ok {
+			return nil, false
+		}
+	}
+	return s, true
+}
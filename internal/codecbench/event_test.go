@@ -0,0 +1,199 @@
+package codecbench
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dwoolworth/goodm"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var update = flag.Bool("update", false, "rewrite event_codec.go")
+
+// plainEvent has Event's fields without its generated methods, so the bson
+// struct codec encodes it.
+type plainEvent Event
+
+func TestMain(m *testing.M) {
+	if err := goodm.Register(&Event{}, "events"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestGeneratedCodec(t *testing.T) {
+	schema, _ := goodm.Get("Event")
+	src, err := goodm.GenerateCodecs(map[string]*goodm.Schema{"Event": schema}, goodm.CodecOptions{PackageName: "codecbench"})
+	if err != nil {
+		t.Fatalf("GenerateCodecs: %v", err)
+	}
+	if *update {
+		if err := os.WriteFile("event_codec.go", src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	committed, err := os.ReadFile("event_codec.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, committed) {
+		t.Fatal("event_codec.go is stale; run go generate ./internal/codecbench")
+	}
+}
+
+func sampleEvent() Event {
+	note := "hello"
+	due := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	return Event{
+		Model:   goodm.Model{ID: bson.NewObjectID(), CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 6_000_000, time.UTC), Version: 3},
+		Name:    "signup",
+		Status:  "open",
+		Count:   1 << 40,
+		Total:   42,
+		Level:   -7,
+		Score:   0.5,
+		Active:  true,
+		At:      time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Owner:   bson.NewObjectID(),
+		Tags:    []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"},
+		Note:    &note,
+		Due:     &due,
+		Address: &Address{City: "Oslo", Zip: "0150"},
+		Extra:   map[string]int{"x": 1},
+	}
+}
+
+func TestGeneratedCodec_MatchesStructCodec(t *testing.T) {
+	for name, e := range map[string]Event{"full": sampleEvent(), "zero": {}} {
+		want, err := bson.Marshal(plainEvent(e))
+		if err != nil {
+			t.Fatalf("%s: struct codec: %v", name, err)
+		}
+		got, err := bson.Marshal(&e)
+		if err != nil {
+			t.Fatalf("%s: generated codec: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: documents differ:\n got %v\nwant %v", name, bson.Raw(got), bson.Raw(want))
+		}
+
+		var decoded Event
+		if err := bson.Unmarshal(want, &decoded); err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		var plain plainEvent
+		if err := bson.Unmarshal(want, &plain); err != nil {
+			t.Fatalf("%s: struct decode: %v", name, err)
+		}
+		if !reflect.DeepEqual(decoded, Event(plain)) {
+			t.Errorf("%s: decoded values differ:\n got %+v\nwant %+v", name, decoded, plain)
+		}
+	}
+}
+
+func TestGeneratedCodec_FallsBackOnOtherTypes(t *testing.T) {
+	doc := bson.D{
+		{Key: "count", Value: 3.0},
+		{Key: "level", Value: int64(5)},
+		{Key: "name", Value: nil},
+		{Key: "note", Value: nil},
+		{Key: "unknown", Value: "ignored"},
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := Event{Name: "before", Note: new(string)}
+	if err := bson.Unmarshal(raw, &e); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if e.Count != 3 || e.Level != 5 || e.Name != "" || e.Note != nil {
+		t.Errorf("unexpected event: %+v", e)
+	}
+
+	raw, _ = bson.Marshal(bson.D{{Key: "level", Value: int64(1 << 20)}})
+	if err := bson.Unmarshal(raw, &e); err == nil {
+		t.Error("expected an overflowing level to fail")
+	}
+}
+
+func TestGeneratedCodec_Store(t *testing.T) {
+	goodm.UseTestStore()
+	t.Cleanup(goodm.ClearTestStore)
+	ctx := context.Background()
+
+	e := sampleEvent()
+	e.ID = bson.ObjectID{}
+	if err := goodm.Create(ctx, &e); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var found Event
+	if err := goodm.FindOne(ctx, bson.D{{Key: "_id", Value: e.ID}}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Name != e.Name || found.Address.City != "Oslo" || !found.UpdatedAt.Equal(e.UpdatedAt.Truncate(time.Millisecond)) {
+		t.Errorf("unexpected event: %+v", found)
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	e := sampleEvent()
+	e.Due, e.Address, e.Extra = nil, nil, nil
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := e.MarshalBSON(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("generated-via-driver", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := bson.Marshal(&e); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reflection", func(b *testing.B) {
+		p := plainEvent(e)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := bson.Marshal(&p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	e := sampleEvent()
+	e.Due, e.Address, e.Extra = nil, nil, nil
+	raw, err := bson.Marshal(plainEvent(e))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out Event
+			if err := bson.Unmarshal(raw, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out plainEvent
+			if err := bson.Unmarshal(raw, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
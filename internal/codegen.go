@@ -30,7 +30,7 @@ func ToExportedName(name string) string {
 // SanitizeStructName converts a collection name to a singular exported Go struct name.
 // Example: "blog_posts" → "BlogPost", "users" → "User"
 func SanitizeStructName(collectionName string) string {
-	singular := singularize(collectionName)
+	singular := Singularize(collectionName)
 	return ToExportedName(singular)
 }
 
@@ -58,9 +58,9 @@ func isAcronym(s string) bool {
 	return false
 }
 
-// singularize performs a simple singularization by stripping trailing "s".
+// Singularize performs a simple singularization by stripping trailing "s".
 // Handles common cases: "posts" → "post", "statuses" → "status", "iries" → not touched
-func singularize(s string) string {
+func Singularize(s string) string {
 	if len(s) < 3 {
 		return s
 	}
@@ -0,0 +1,171 @@
+package memstore
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// matchExpr evaluates the aggregation expression of a $expr query against
+// doc and reports whether the result is truthy. Supported are field paths
+// ("$field"), literals, $literal, the comparisons $eq, $ne, $gt, $gte, $lt,
+// $lte, the logical $and, $or, $not, and the arithmetic $add, $subtract,
+// $multiply, $divide.
+func matchExpr(doc bson.D, expr interface{}) (bool, error) {
+	v, err := evalExpr(doc, expr)
+	if err != nil {
+		return false, err
+	}
+	return exprTruthy(v), nil
+}
+
+func evalExpr(doc bson.D, expr interface{}) (interface{}, error) {
+	switch e := expr.(type) {
+	case string:
+		if strings.HasPrefix(e, "$$") {
+			return nil, fmt.Errorf("memstore: $expr variables are not supported: %s", e)
+		}
+		if strings.HasPrefix(e, "$") {
+			values := lookup(doc, e[1:])
+			if len(values) == 0 {
+				return nil, nil
+			}
+			if len(values) > 1 {
+				return bson.A(values), nil
+			}
+			return values[0], nil
+		}
+		return e, nil
+	case bson.A:
+		out := make(bson.A, 0, len(e))
+		for _, el := range e {
+			v, err := evalExpr(doc, el)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case bson.D:
+		if len(e) != 1 || !strings.HasPrefix(e[0].Key, "$") {
+			return e, nil
+		}
+		return evalOperator(doc, e[0].Key, e[0].Value)
+	}
+	return expr, nil
+}
+
+func evalOperator(doc bson.D, op string, arg interface{}) (interface{}, error) {
+	if op == "$literal" {
+		return arg, nil
+	}
+	args, err := exprArgs(doc, arg)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("memstore: %s requires 2 arguments", op)
+		}
+		cmp := Compare(args[0], args[1])
+		switch op {
+		case "$eq":
+			return cmp == 0, nil
+		case "$ne":
+			return cmp != 0, nil
+		case "$gt":
+			return cmp > 0, nil
+		case "$gte":
+			return cmp >= 0, nil
+		case "$lt":
+			return cmp < 0, nil
+		}
+		return cmp <= 0, nil
+	case "$and", "$or":
+		for _, a := range args {
+			if exprTruthy(a) == (op == "$or") {
+				return op == "$or", nil
+			}
+		}
+		return op == "$and", nil
+	case "$not":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("memstore: $not requires 1 argument")
+		}
+		return !exprTruthy(args[0]), nil
+	case "$add", "$subtract", "$multiply", "$divide":
+		return evalArithmetic(op, args)
+	}
+	return nil, fmt.Errorf("memstore: unsupported $expr operator %s", op)
+}
+
+// exprArgs evaluates an operator's arguments, which are an array or, for a
+// single argument, the value itself.
+func exprArgs(doc bson.D, arg interface{}) ([]interface{}, error) {
+	list, ok := arg.(bson.A)
+	if !ok {
+		list = bson.A{arg}
+	}
+	args := make([]interface{}, 0, len(list))
+	for _, a := range list {
+		v, err := evalExpr(doc, a)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	return args, nil
+}
+
+// evalArithmetic applies a numeric operator. A null or missing operand makes
+// the result null, as in MongoDB.
+func evalArithmetic(op string, args []interface{}) (interface{}, error) {
+	if len(args) == 0 || ((op == "$subtract" || op == "$divide") && len(args) != 2) {
+		return nil, fmt.Errorf("memstore: wrong number of arguments to %s", op)
+	}
+	nums := make([]float64, 0, len(args))
+	for _, a := range args {
+		if a == nil {
+			return nil, nil
+		}
+		n, ok := toFloat(a)
+		if !ok {
+			return nil, fmt.Errorf("memstore: %s only supports numeric arguments, got %T", op, a)
+		}
+		nums = append(nums, n)
+	}
+	result := nums[0]
+	for _, n := range nums[1:] {
+		switch op {
+		case "$add":
+			result += n
+		case "$subtract":
+			result -= n
+		case "$multiply":
+			result *= n
+		case "$divide":
+			if n == 0 {
+				return nil, fmt.Errorf("memstore: $divide by zero")
+			}
+			result /= n
+		}
+	}
+	return result, nil
+}
+
+// exprTruthy reports whether an expression result counts as true: anything
+// but false, null, missing, and zero.
+func exprTruthy(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	if n, ok := toFloat(v); ok {
+		return n != 0
+	}
+	return true
+}
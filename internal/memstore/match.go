@@ -0,0 +1,419 @@
+package memstore
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Match reports whether doc satisfies filter, following MongoDB query
+// semantics for the supported operators.
+func Match(doc bson.D, filter bson.D) (bool, error) {
+	for _, e := range filter {
+		ok, err := matchElem(doc, e)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchElem(doc bson.D, e bson.E) (bool, error) {
+	switch e.Key {
+	case "$and", "$or", "$nor":
+		clauses, ok := e.Value.(bson.A)
+		if !ok || len(clauses) == 0 {
+			return false, fmt.Errorf("memstore: %s requires a non-empty array", e.Key)
+		}
+		for _, c := range clauses {
+			cd, ok := c.(bson.D)
+			if !ok {
+				return false, fmt.Errorf("memstore: %s clauses must be documents", e.Key)
+			}
+			m, err := Match(doc, cd)
+			if err != nil {
+				return false, err
+			}
+			switch {
+			case e.Key == "$and" && !m:
+				return false, nil
+			case e.Key == "$or" && m:
+				return true, nil
+			case e.Key == "$nor" && m:
+				return false, nil
+			}
+		}
+		return e.Key != "$or", nil
+	}
+	if e.Key == "$expr" {
+		return matchExpr(doc, e.Value)
+	}
+	if strings.HasPrefix(e.Key, "$") {
+		return false, fmt.Errorf("memstore: unsupported query operator %s", e.Key)
+	}
+
+	values := lookup(doc, e.Key)
+	if ops, ok := operatorDoc(e.Value); ok {
+		return matchOperators(values, ops)
+	}
+	return matchEq(values, e.Value), nil
+}
+
+// operatorDoc returns v as an operator document if its keys start with "$".
+func operatorDoc(v interface{}) (bson.D, bool) {
+	d, ok := v.(bson.D)
+	if !ok || len(d) == 0 || !strings.HasPrefix(d[0].Key, "$") {
+		return nil, false
+	}
+	return d, true
+}
+
+func matchOperators(values []interface{}, ops bson.D) (bool, error) {
+	for _, op := range ops {
+		if op.Key == "$regex" {
+			op.Value = withRegexOptions(op.Value, ops)
+		}
+		ok, err := matchOperator(values, op)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchOperator(values []interface{}, op bson.E) (bool, error) {
+	switch op.Key {
+	case "$eq":
+		return matchEq(values, op.Value), nil
+	case "$ne":
+		return !matchEq(values, op.Value), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		for _, c := range candidates(values) {
+			cmp, ok := compareSameType(c, op.Value)
+			if !ok {
+				continue
+			}
+			if (op.Key == "$gt" && cmp > 0) || (op.Key == "$gte" && cmp >= 0) ||
+				(op.Key == "$lt" && cmp < 0) || (op.Key == "$lte" && cmp <= 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$in", "$nin":
+		list, ok := op.Value.(bson.A)
+		if !ok {
+			return false, fmt.Errorf("memstore: %s requires an array", op.Key)
+		}
+		found := false
+		for _, want := range list {
+			if matchEq(values, want) {
+				found = true
+				break
+			}
+		}
+		return found == (op.Key == "$in"), nil
+	case "$exists":
+		want, _ := op.Value.(bool)
+		return (len(values) > 0) == want, nil
+	case "$not":
+		inner, ok := operatorDoc(op.Value)
+		if !ok {
+			return false, fmt.Errorf("memstore: $not requires an operator document")
+		}
+		m, err := matchOperators(values, inner)
+		return !m, err
+	case "$size":
+		n, ok := toFloat(op.Value)
+		if !ok {
+			return false, fmt.Errorf("memstore: $size requires a number")
+		}
+		for _, v := range values {
+			if a, ok := v.(bson.A); ok && float64(len(a)) == n {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$all":
+		list, ok := op.Value.(bson.A)
+		if !ok {
+			return false, fmt.Errorf("memstore: $all requires an array")
+		}
+		for _, want := range list {
+			if !matchEq(values, want) {
+				return false, nil
+			}
+		}
+		return len(list) > 0, nil
+	case "$elemMatch":
+		cond, ok := op.Value.(bson.D)
+		if !ok {
+			return false, fmt.Errorf("memstore: $elemMatch requires a document")
+		}
+		for _, v := range values {
+			arr, ok := v.(bson.A)
+			if !ok {
+				continue
+			}
+			for _, el := range arr {
+				var m bool
+				var err error
+				if ops, isOps := operatorDoc(cond); isOps {
+					m, err = matchOperators([]interface{}{el}, ops)
+				} else if ed, isDoc := el.(bson.D); isDoc {
+					m, err = Match(ed, cond)
+				}
+				if err != nil {
+					return false, err
+				}
+				if m {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	case "$regex":
+		return matchRegex(values, op.Value)
+	case "$options":
+		return true, nil // consumed by $regex
+	default:
+		return false, fmt.Errorf("memstore: unsupported query operator %s", op.Key)
+	}
+}
+
+// withRegexOptions folds a sibling $options into a string $regex pattern.
+func withRegexOptions(pattern interface{}, ops bson.D) interface{} {
+	p, ok := pattern.(string)
+	if !ok {
+		return pattern
+	}
+	for _, op := range ops {
+		if op.Key == "$options" {
+			if o, ok := op.Value.(string); ok {
+				return bson.Regex{Pattern: p, Options: o}
+			}
+		}
+	}
+	return pattern
+}
+
+func matchRegex(values []interface{}, pattern interface{}) (bool, error) {
+	var expr string
+	switch p := pattern.(type) {
+	case string:
+		expr = p
+	case bson.Regex:
+		expr = p.Pattern
+		if p.Options != "" {
+			expr = "(?" + p.Options + ")" + expr
+		}
+	default:
+		return false, fmt.Errorf("memstore: $regex requires a string")
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false, fmt.Errorf("memstore: invalid $regex: %w", err)
+	}
+	for _, c := range candidates(values) {
+		if s, ok := c.(string); ok && re.MatchString(s) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchEq implements MongoDB equality: a field matches if its value, or any
+// element of an array value, equals want. A null want also matches a missing
+// field.
+func matchEq(values []interface{}, want interface{}) bool {
+	if want == nil && len(values) == 0 {
+		return true
+	}
+	if re, ok := want.(bson.Regex); ok {
+		m, _ := matchRegex(values, re)
+		return m
+	}
+	for _, c := range candidates(values) {
+		if Equal(c, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates expands array values into their elements, keeping the arrays.
+func candidates(values []interface{}) []interface{} {
+	var result []interface{}
+	for _, v := range values {
+		result = append(result, v)
+		if a, ok := v.(bson.A); ok {
+			result = append(result, a...)
+		}
+	}
+	return result
+}
+
+// lookup returns the values at a dotted path. Arrays along the path are
+// traversed element-wise, so several values may be returned.
+func lookup(doc bson.D, path string) []interface{} {
+	return lookupParts(doc, strings.Split(path, "."))
+}
+
+func lookupParts(v interface{}, parts []string) []interface{} {
+	if len(parts) == 0 {
+		return []interface{}{v}
+	}
+	switch t := v.(type) {
+	case bson.D:
+		for _, e := range t {
+			if e.Key == parts[0] {
+				return lookupParts(e.Value, parts[1:])
+			}
+		}
+	case bson.A:
+		if i, err := strconv.Atoi(parts[0]); err == nil {
+			if i >= 0 && i < len(t) {
+				return lookupParts(t[i], parts[1:])
+			}
+			return nil
+		}
+		var result []interface{}
+		for _, el := range t {
+			if _, ok := el.(bson.D); ok {
+				result = append(result, lookupParts(el, parts)...)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// Equal compares two BSON values, treating all numeric types as numbers.
+func Equal(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Compare orders two BSON values using MongoDB's cross-type sort order.
+func Compare(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+	if cmp, ok := compareSameType(a, b); ok {
+		return cmp
+	}
+	return 0
+}
+
+// compareSameType compares values of the same type class. Returns false if
+// the values are not comparable.
+func compareSameType(a, b interface{}) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(fa, fb), true
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	case bson.DateTime:
+		y, ok := b.(bson.DateTime)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(int64(x), int64(y)), true
+	case bson.ObjectID:
+		y, ok := b.(bson.ObjectID)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(x[:], y[:]), true
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(boolInt(x), boolInt(y)), true
+	case nil:
+		return 0, b == nil
+	}
+	return 0, false
+}
+
+func compareOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// typeRank returns MongoDB's sort rank for a value's BSON type.
+func typeRank(v interface{}) int {
+	if _, ok := toFloat(v); ok {
+		return 2
+	}
+	switch v.(type) {
+	case nil:
+		return 1
+	case string:
+		return 3
+	case bson.D:
+		return 4
+	case bson.A:
+		return 5
+	case bson.Binary:
+		return 6
+	case bson.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case bson.DateTime:
+		return 9
+	case bson.Timestamp:
+		return 10
+	case bson.Regex:
+		return 11
+	}
+	return 12
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
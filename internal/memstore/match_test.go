@@ -0,0 +1,105 @@
+package memstore
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMatch(t *testing.T) {
+	doc := bson.D{
+		{Key: "name", Value: "alice"},
+		{Key: "age", Value: int32(30)},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+		{Key: "address", Value: bson.D{{Key: "city", Value: "Paris"}}},
+		{Key: "items", Value: bson.A{
+			bson.D{{Key: "sku", Value: "x"}, {Key: "qty", Value: int32(2)}},
+			bson.D{{Key: "sku", Value: "y"}, {Key: "qty", Value: int32(5)}},
+		}},
+	}
+	cases := []struct {
+		name   string
+		filter bson.D
+		want   bool
+	}{
+		{"equality", bson.D{{Key: "name", Value: "alice"}}, true},
+		{"numeric types", bson.D{{Key: "age", Value: int64(30)}}, true},
+		{"array contains", bson.D{{Key: "tags", Value: "b"}}, true},
+		{"dotted path", bson.D{{Key: "address.city", Value: "Paris"}}, true},
+		{"array of documents", bson.D{{Key: "items.sku", Value: "y"}}, true},
+		{"range", bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: 18}, {Key: "$lt", Value: 30}}}}, false},
+		{"in", bson.D{{Key: "name", Value: bson.D{{Key: "$in", Value: bson.A{"bob", "alice"}}}}}, true},
+		{"missing equals null", bson.D{{Key: "deleted", Value: nil}}, true},
+		{"exists", bson.D{{Key: "deleted", Value: bson.D{{Key: "$exists", Value: true}}}}, false},
+		{"or", bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "name", Value: "bob"}},
+			bson.D{{Key: "age", Value: 30}},
+		}}}, true},
+		{"elemMatch", bson.D{{Key: "items", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+			{Key: "sku", Value: "x"}, {Key: "qty", Value: bson.D{{Key: "$gte", Value: 3}}},
+		}}}}}, false},
+		{"regex", bson.D{{Key: "name", Value: bson.D{{Key: "$regex", Value: "^AL"}, {Key: "$options", Value: "i"}}}}, true},
+		{"regex value", bson.D{{Key: "name", Value: bson.Regex{Pattern: "^AL", Options: "i"}}}, true},
+		{"expr field comparison", bson.D{{Key: "$expr", Value: bson.D{{Key: "$gt", Value: bson.A{"$age", "$address.zip"}}}}}, true},
+		{"expr computed", bson.D{{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{
+			bson.D{{Key: "$multiply", Value: bson.A{"$age", 2}}}, 50,
+		}}}}}, false},
+		{"expr logical", bson.D{{Key: "$expr", Value: bson.D{{Key: "$and", Value: bson.A{
+			bson.D{{Key: "$eq", Value: bson.A{"$name", "alice"}}},
+			bson.D{{Key: "$not", Value: bson.A{bson.D{{Key: "$gte", Value: bson.A{"$age", 40}}}}}},
+		}}}}}, true},
+	}
+	for _, tc := range cases {
+		got, err := Match(doc, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	if _, err := Match(doc, bson.D{{Key: "$expr", Value: bson.D{{Key: "$concat", Value: bson.A{"$name"}}}}}); err == nil {
+		t.Fatal("expected error for unsupported $expr operator")
+	}
+	if _, err := Match(doc, bson.D{{Key: "$where", Value: "true"}}); err == nil {
+		t.Fatal("expected error for unsupported operator")
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	doc := bson.D{
+		{Key: "_id", Value: int32(1)},
+		{Key: "count", Value: int32(1)},
+		{Key: "tags", Value: bson.A{"a"}},
+	}
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{{Key: "count", Value: int32(2)}}},
+		{Key: "$set", Value: bson.D{{Key: "profile.bio", Value: "hi"}}},
+		{Key: "$addToSet", Value: bson.D{{Key: "tags", Value: bson.D{{Key: "$each", Value: bson.A{"a", "b"}}}}}},
+		{Key: "$setOnInsert", Value: bson.D{{Key: "created", Value: true}}},
+	}
+	got, err := ApplyUpdate(doc, update, false)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	want := bson.D{
+		{Key: "_id", Value: int32(1)},
+		{Key: "count", Value: int32(3)},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+		{Key: "profile", Value: bson.D{{Key: "bio", Value: "hi"}}},
+	}
+	if !Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if len(doc) != 3 || !Equal(doc[1].Value, int32(1)) {
+		t.Fatalf("expected input document to be left unchanged, got %v", doc)
+	}
+
+	if _, err := ApplyUpdate(doc, bson.D{{Key: "$set", Value: bson.D{{Key: "_id", Value: int32(2)}}}}, false); err == nil {
+		t.Fatal("expected error when changing _id")
+	}
+	if _, err := ApplyUpdate(doc, bson.D{{Key: "count", Value: 1}}, false); err == nil {
+		t.Fatal("expected error for replacement-style update")
+	}
+}
@@ -0,0 +1,774 @@
+// Package memstore is an in-memory stand-in for a MongoDB database, used by
+// goodm's test store. It implements the subset of collection operations goodm
+// issues, with MongoDB filter and update semantics for common operators.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ErrUnsupported is returned for operations the in-memory store cannot run.
+var ErrUnsupported = errors.New("memstore: operation not supported by the in-memory test store")
+
+// Store holds in-memory collections. It is safe for concurrent use.
+type Store struct {
+	mu    sync.Mutex
+	reg   *bson.Registry
+	colls map[string]*collData
+}
+
+type collData struct {
+	docs   []bson.D
+	unique [][]string
+}
+
+// New creates an empty Store that encodes and decodes values with reg.
+func New(reg *bson.Registry) *Store {
+	return &Store{reg: reg, colls: make(map[string]*collData)}
+}
+
+// Collection returns a handle to the named collection, creating it lazily.
+func (s *Store) Collection(name string) *Collection {
+	return &Collection{store: s, name: name}
+}
+
+// Reset removes all collections and documents.
+func (s *Store) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.colls = make(map[string]*collData)
+}
+
+// data returns the collection's storage. Must be called with s.mu held.
+func (s *Store) data(name string) *collData {
+	cd, ok := s.colls[name]
+	if !ok {
+		cd = &collData{}
+		s.colls[name] = cd
+	}
+	return cd
+}
+
+// Collection is a handle to one in-memory collection. Its methods mirror those
+// of *mongo.Collection.
+type Collection struct {
+	store *Store
+	name  string
+}
+
+// Name returns the collection name.
+func (c *Collection) Name() string { return c.name }
+
+// SetUnique declares unique keys, each a list of field paths, enforced on
+// every insert, update, and replace.
+func (c *Collection) SetUnique(keys [][]string) {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	c.store.data(c.name).unique = keys
+}
+
+// Drop removes the collection and its documents.
+func (c *Collection) Drop(ctx context.Context) error {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	delete(c.store.colls, c.name)
+	return nil
+}
+
+// Rename moves the collection's documents to the collection named to. It
+// fails if to already holds documents, unless dropTarget is set.
+func (c *Collection) Rename(to string, dropTarget bool) error {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	if target, ok := c.store.colls[to]; ok && len(target.docs) > 0 && !dropTarget {
+		return fmt.Errorf("memstore: target collection %q exists", to)
+	}
+	c.store.colls[to] = c.store.data(c.name)
+	delete(c.store.colls, c.name)
+	return nil
+}
+
+// InsertOne inserts a document, generating an _id if it has none.
+func (c *Collection) InsertOne(ctx context.Context, document interface{}, opts ...options.Lister[options.InsertOneOptions]) (*mongo.InsertOneResult, error) {
+	doc, err := c.toDoc(document)
+	if err != nil {
+		return nil, err
+	}
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	doc, id := ensureID(doc)
+	if err := c.insert(doc); err != nil {
+		return nil, err
+	}
+	return &mongo.InsertOneResult{InsertedID: id, Acknowledged: true}, nil
+}
+
+// InsertMany inserts a slice of documents in order, stopping at the first
+// duplicate key unless the insert is unordered.
+func (c *Collection) InsertMany(ctx context.Context, documents interface{}, opts ...options.Lister[options.InsertManyOptions]) (*mongo.InsertManyResult, error) {
+	args, err := collect(opts)
+	if err != nil {
+		return nil, err
+	}
+	ordered := args.Ordered == nil || *args.Ordered
+
+	v := reflect.ValueOf(documents)
+	if v.Kind() != reflect.Slice {
+		return nil, mongo.ErrNotSlice
+	}
+	if v.Len() == 0 {
+		return nil, mongo.ErrEmptySlice
+	}
+
+	docs := make([]bson.D, v.Len())
+	for i := range docs {
+		doc, err := c.toDoc(v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = doc
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	res := &mongo.InsertManyResult{Acknowledged: true}
+	var failed mongo.WriteException
+	for i, doc := range docs {
+		doc, id := ensureID(doc)
+		if err := c.insert(doc); err != nil {
+			var we mongo.WriteException
+			if !errors.As(err, &we) {
+				return res, err
+			}
+			for _, e := range we.WriteErrors {
+				e.Index = i
+				failed.WriteErrors = append(failed.WriteErrors, e)
+			}
+			if ordered {
+				break
+			}
+			continue
+		}
+		res.InsertedIDs = append(res.InsertedIDs, id)
+	}
+	if len(failed.WriteErrors) > 0 {
+		return res, failed
+	}
+	return res, nil
+}
+
+// FindOne returns the first document matching filter.
+func (c *Collection) FindOne(ctx context.Context, filter interface{}, opts ...options.Lister[options.FindOneOptions]) *mongo.SingleResult {
+	args, err := collect(opts)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, c.store.reg)
+	}
+	skip := int64(0)
+	if args.Skip != nil {
+		skip = *args.Skip
+	}
+	if args.Collation != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, ErrUnsupported, c.store.reg)
+	}
+	docs, err := c.query(filter, args.Sort, skip, 1, args.Projection)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, c.store.reg)
+	}
+	if len(docs) == 0 {
+		return mongo.NewSingleResultFromDocument(bson.D{}, mongo.ErrNoDocuments, c.store.reg)
+	}
+	return mongo.NewSingleResultFromDocument(docs[0], nil, c.store.reg)
+}
+
+// Find returns a cursor over all documents matching filter.
+func (c *Collection) Find(ctx context.Context, filter interface{}, opts ...options.Lister[options.FindOptions]) (*mongo.Cursor, error) {
+	args, err := collect(opts)
+	if err != nil {
+		return nil, err
+	}
+	var skip, limit int64
+	if args.Skip != nil {
+		skip = *args.Skip
+	}
+	if args.Limit != nil {
+		limit = *args.Limit
+	}
+	if args.Collation != nil {
+		return nil, ErrUnsupported
+	}
+	docs, err := c.query(filter, args.Sort, skip, limit, args.Projection)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]interface{}, len(docs))
+	for i, d := range docs {
+		items[i] = d
+	}
+	return mongo.NewCursorFromDocuments(items, nil, c.store.reg)
+}
+
+// CountDocuments counts documents matching filter.
+func (c *Collection) CountDocuments(ctx context.Context, filter interface{}, opts ...options.Lister[options.CountOptions]) (int64, error) {
+	args, err := collect(opts)
+	if err != nil {
+		return 0, err
+	}
+	var skip, limit int64
+	if args.Skip != nil {
+		skip = *args.Skip
+	}
+	if args.Limit != nil {
+		limit = *args.Limit
+	}
+	docs, err := c.query(filter, nil, skip, limit, nil)
+	return int64(len(docs)), err
+}
+
+// UpdateOne applies update to the first document matching filter.
+func (c *Collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...options.Lister[options.UpdateOneOptions]) (*mongo.UpdateResult, error) {
+	args, err := collect(opts)
+	if err != nil {
+		return nil, err
+	}
+	if args.ArrayFilters != nil || args.Collation != nil {
+		return nil, ErrUnsupported
+	}
+	return c.update(filter, update, false, args.Upsert != nil && *args.Upsert)
+}
+
+// UpdateMany applies update to every document matching filter.
+func (c *Collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...options.Lister[options.UpdateManyOptions]) (*mongo.UpdateResult, error) {
+	args, err := collect(opts)
+	if err != nil {
+		return nil, err
+	}
+	if args.ArrayFilters != nil || args.Collation != nil {
+		return nil, ErrUnsupported
+	}
+	return c.update(filter, update, true, args.Upsert != nil && *args.Upsert)
+}
+
+// FindOneAndUpdate atomically applies update to the first document matching
+// filter and returns the document from before or, with ReturnDocument After,
+// after the update. Sort, array filters, and collations are not supported.
+func (c *Collection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...options.Lister[options.FindOneAndUpdateOptions]) *mongo.SingleResult {
+	fail := func(err error) *mongo.SingleResult {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, c.store.reg)
+	}
+	args, err := collect(opts)
+	if err != nil {
+		return fail(err)
+	}
+	if args.ArrayFilters != nil || args.Collation != nil || args.Sort != nil {
+		return fail(ErrUnsupported)
+	}
+	if _, ok := update.(bson.A); ok {
+		return fail(ErrUnsupported) // pipeline updates
+	}
+	f, err := c.toDoc(filter)
+	if err != nil {
+		return fail(err)
+	}
+	u, err := c.toDoc(update)
+	if err != nil {
+		return fail(err)
+	}
+	after := args.ReturnDocument != nil && *args.ReturnDocument == options.After
+	result := func(doc bson.D) *mongo.SingleResult {
+		return mongo.NewSingleResultFromDocument(doc, nil, c.store.reg)
+	}
+	if args.Projection != nil {
+		proj, err := c.toDoc(args.Projection)
+		if err != nil {
+			return fail(err)
+		}
+		result = func(doc bson.D) *mongo.SingleResult {
+			return mongo.NewSingleResultFromDocument(project(doc, proj), nil, c.store.reg)
+		}
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	cd := c.store.data(c.name)
+	for i, doc := range cd.docs {
+		ok, err := Match(doc, f)
+		if err != nil {
+			return fail(err)
+		}
+		if !ok {
+			continue
+		}
+		next, err := ApplyUpdate(doc, u, false)
+		if err != nil {
+			return fail(err)
+		}
+		if err := c.checkUnique(next, i); err != nil {
+			return fail(err)
+		}
+		cd.docs[i] = next
+		if after {
+			return result(next)
+		}
+		return result(doc)
+	}
+
+	if args.Upsert == nil || !*args.Upsert {
+		return fail(mongo.ErrNoDocuments)
+	}
+	next, err := ApplyUpdate(equalityFields(f), u, true)
+	if err != nil {
+		return fail(err)
+	}
+	next, _ = ensureID(next)
+	if err := c.insert(next); err != nil {
+		return fail(err)
+	}
+	if !after {
+		return fail(mongo.ErrNoDocuments)
+	}
+	return result(next)
+}
+
+// ReplaceOne replaces the first document matching filter, keeping its _id.
+func (c *Collection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...options.Lister[options.ReplaceOptions]) (*mongo.UpdateResult, error) {
+	args, err := collect(opts)
+	if err != nil {
+		return nil, err
+	}
+	f, err := c.toDoc(filter)
+	if err != nil {
+		return nil, err
+	}
+	repl, err := c.toDoc(replacement)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range repl {
+		if strings.HasPrefix(e.Key, "$") {
+			return nil, fmt.Errorf("memstore: replacement document must not contain operators")
+		}
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	cd := c.store.data(c.name)
+	for i, doc := range cd.docs {
+		ok, err := Match(doc, f)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		id := getValue(doc, "_id")
+		if rid := getValue(repl, "_id"); rid != nil && !Equal(rid, id) {
+			return nil, fmt.Errorf("memstore: _id is immutable")
+		}
+		next := append(bson.D{{Key: "_id", Value: id}}, withoutKey(repl, "_id")...)
+		if err := c.checkUnique(next, i); err != nil {
+			return nil, err
+		}
+		cd.docs[i] = next
+		return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1, Acknowledged: true}, nil
+	}
+
+	if args.Upsert != nil && *args.Upsert {
+		doc, id := ensureID(repl)
+		if err := c.insert(doc); err != nil {
+			return nil, err
+		}
+		return &mongo.UpdateResult{UpsertedCount: 1, UpsertedID: id, Acknowledged: true}, nil
+	}
+	return &mongo.UpdateResult{Acknowledged: true}, nil
+}
+
+// DeleteOne removes the first document matching filter.
+func (c *Collection) DeleteOne(ctx context.Context, filter interface{}, opts ...options.Lister[options.DeleteOneOptions]) (*mongo.DeleteResult, error) {
+	return c.delete(filter, false)
+}
+
+// FindOneAndDelete atomically removes the first document matching filter and
+// returns it. Sort and collations are not supported.
+func (c *Collection) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...options.Lister[options.FindOneAndDeleteOptions]) *mongo.SingleResult {
+	fail := func(err error) *mongo.SingleResult {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, c.store.reg)
+	}
+	args, err := collect(opts)
+	if err != nil {
+		return fail(err)
+	}
+	if args.Collation != nil || args.Sort != nil {
+		return fail(ErrUnsupported)
+	}
+	f, err := c.toDoc(filter)
+	if err != nil {
+		return fail(err)
+	}
+	var proj bson.D
+	if args.Projection != nil {
+		if proj, err = c.toDoc(args.Projection); err != nil {
+			return fail(err)
+		}
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	cd := c.store.data(c.name)
+	for i, doc := range cd.docs {
+		ok, err := Match(doc, f)
+		if err != nil {
+			return fail(err)
+		}
+		if !ok {
+			continue
+		}
+		cd.docs = append(cd.docs[:i:i], cd.docs[i+1:]...)
+		if proj != nil {
+			doc = project(doc, proj)
+		}
+		return mongo.NewSingleResultFromDocument(doc, nil, c.store.reg)
+	}
+	return fail(mongo.ErrNoDocuments)
+}
+
+// DeleteMany removes every document matching filter.
+func (c *Collection) DeleteMany(ctx context.Context, filter interface{}, opts ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error) {
+	return c.delete(filter, true)
+}
+
+// Aggregate is not supported by the in-memory store.
+func (c *Collection) Aggregate(ctx context.Context, pipeline interface{}, opts ...options.Lister[options.AggregateOptions]) (*mongo.Cursor, error) {
+	return nil, ErrUnsupported
+}
+
+// query returns copies of the documents matching filter, sorted, paged, and
+// projected.
+func (c *Collection) query(filter interface{}, sortSpec interface{}, skip, limit int64, projection interface{}) ([]bson.D, error) {
+	f, err := c.toDoc(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store.mu.Lock()
+	var docs []bson.D
+	for _, doc := range c.store.data(c.name).docs {
+		ok, err := Match(doc, f)
+		if err != nil {
+			c.store.mu.Unlock()
+			return nil, err
+		}
+		if ok {
+			docs = append(docs, doc)
+		}
+	}
+	c.store.mu.Unlock()
+
+	if sortSpec != nil {
+		spec, err := c.toDoc(sortSpec)
+		if err != nil {
+			return nil, err
+		}
+		sortDocs(docs, spec)
+	}
+	if skip > 0 {
+		if skip >= int64(len(docs)) {
+			docs = nil
+		} else {
+			docs = docs[skip:]
+		}
+	}
+	if limit > 0 && limit < int64(len(docs)) {
+		docs = docs[:limit]
+	}
+	if projection != nil {
+		proj, err := c.toDoc(projection)
+		if err != nil {
+			return nil, err
+		}
+		for i, d := range docs {
+			docs[i] = project(d, proj)
+		}
+	}
+	return docs, nil
+}
+
+func (c *Collection) update(filter, update interface{}, many, upsert bool) (*mongo.UpdateResult, error) {
+	if _, ok := update.(bson.A); ok {
+		return nil, ErrUnsupported // pipeline updates
+	}
+	f, err := c.toDoc(filter)
+	if err != nil {
+		return nil, err
+	}
+	u, err := c.toDoc(update)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	cd := c.store.data(c.name)
+	res := &mongo.UpdateResult{Acknowledged: true}
+	for i, doc := range cd.docs {
+		ok, err := Match(doc, f)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		next, err := ApplyUpdate(doc, u, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.checkUnique(next, i); err != nil {
+			return nil, err
+		}
+		res.MatchedCount++
+		if !reflect.DeepEqual(doc, next) {
+			res.ModifiedCount++
+		}
+		cd.docs[i] = next
+		if !many {
+			break
+		}
+	}
+
+	if res.MatchedCount == 0 && upsert {
+		next, err := ApplyUpdate(equalityFields(f), u, true)
+		if err != nil {
+			return nil, err
+		}
+		next, id := ensureID(next)
+		if err := c.insert(next); err != nil {
+			return nil, err
+		}
+		res.UpsertedCount = 1
+		res.UpsertedID = id
+	}
+	return res, nil
+}
+
+func (c *Collection) delete(filter interface{}, many bool) (*mongo.DeleteResult, error) {
+	f, err := c.toDoc(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	cd := c.store.data(c.name)
+	kept := cd.docs[:0:0]
+	var deleted int64
+	for _, doc := range cd.docs {
+		if many || deleted == 0 {
+			ok, err := Match(doc, f)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				deleted++
+				continue
+			}
+		}
+		kept = append(kept, doc)
+	}
+	cd.docs = kept
+	return &mongo.DeleteResult{DeletedCount: deleted, Acknowledged: true}, nil
+}
+
+// insert appends doc after checking _id and unique keys. Must be called with
+// the store lock held.
+func (c *Collection) insert(doc bson.D) error {
+	if err := c.checkUnique(doc, -1); err != nil {
+		return err
+	}
+	cd := c.store.data(c.name)
+	cd.docs = append(cd.docs, doc)
+	return nil
+}
+
+// checkUnique reports a duplicate key error if doc collides with another
+// document (other than the one at index self) on _id or a unique key.
+func (c *Collection) checkUnique(doc bson.D, self int) error {
+	cd := c.store.data(c.name)
+	keys := append([][]string{{"_id"}}, cd.unique...)
+	for _, key := range keys {
+		mine := keyValues(doc, key)
+		for i, other := range cd.docs {
+			if i == self {
+				continue
+			}
+			if reflect.DeepEqual(mine, keyValues(other, key)) {
+				return duplicateKeyError(c.name, key)
+			}
+		}
+	}
+	return nil
+}
+
+func keyValues(doc bson.D, key []string) []interface{} {
+	vals := make([]interface{}, len(key))
+	for i, k := range key {
+		vals[i] = normalizeNumber(getValue(doc, k))
+	}
+	return vals
+}
+
+// normalizeNumber maps numbers to float64 so unique checks compare by value.
+func normalizeNumber(v interface{}) interface{} {
+	if f, ok := toFloat(v); ok {
+		return f
+	}
+	return v
+}
+
+func duplicateKeyError(coll string, key []string) error {
+	return mongo.WriteException{WriteErrors: []mongo.WriteError{{
+		Code:    11000,
+		Message: fmt.Sprintf("E11000 duplicate key error collection: %s index: %s", coll, strings.Join(key, "_1_")+"_1"),
+	}}}
+}
+
+// toDoc converts any document-like value to bson.D via the store's registry.
+// nil becomes an empty document.
+func (c *Collection) toDoc(v interface{}) (bson.D, error) {
+	if v == nil {
+		return bson.D{}, nil
+	}
+	var buf bytes.Buffer
+	enc := bson.NewEncoder(bson.NewDocumentWriter(&buf))
+	enc.SetRegistry(c.store.reg)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("memstore: %w", err)
+	}
+	dec := bson.NewDecoder(bson.NewDocumentReader(bytes.NewReader(buf.Bytes())))
+	dec.SetRegistry(c.store.reg)
+	var doc bson.D
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("memstore: %w", err)
+	}
+	return doc, nil
+}
+
+// ensureID returns doc with an _id first, generating an ObjectID if missing.
+func ensureID(doc bson.D) (bson.D, interface{}) {
+	if id := getValue(doc, "_id"); id != nil {
+		return doc, id
+	}
+	id := bson.NewObjectID()
+	return append(bson.D{{Key: "_id", Value: id}}, doc...), id
+}
+
+func withoutKey(doc bson.D, key string) bson.D {
+	out := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		if e.Key != key {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// equalityFields returns the plain equality conditions of a filter, used to
+// seed an upserted document.
+func equalityFields(filter bson.D) bson.D {
+	var out bson.D
+	for _, e := range filter {
+		if strings.HasPrefix(e.Key, "$") {
+			continue
+		}
+		if _, isOps := operatorDoc(e.Value); isOps {
+			continue
+		}
+		out, _ = setPath(out, strings.Split(e.Key, "."), e.Value)
+	}
+	return out
+}
+
+// sortDocs sorts docs in place by a sort specification of {field: 1|-1}.
+func sortDocs(docs []bson.D, spec bson.D) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, s := range spec {
+			dir, _ := toFloat(s.Value)
+			cmp := Compare(getValue(docs[i], s.Key), getValue(docs[j], s.Key))
+			if cmp == 0 {
+				continue
+			}
+			if dir < 0 {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// project applies a top-level inclusion or exclusion projection.
+func project(doc bson.D, proj bson.D) bson.D {
+	include := false
+	keepID := true
+	fields := make(map[string]bool, len(proj))
+	for _, p := range proj {
+		on := truthy(p.Value)
+		if p.Key == "_id" {
+			keepID = on
+			continue
+		}
+		fields[p.Key] = true
+		include = include || on
+	}
+
+	out := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		if e.Key == "_id" {
+			if keepID {
+				out = append(out, e)
+			}
+			continue
+		}
+		if fields[e.Key] == include {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func truthy(v interface{}) bool {
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	f, _ := toFloat(v)
+	return f != 0
+}
+
+// collect applies option setters to a zero options struct.
+func collect[T any](opts []options.Lister[T]) (*T, error) {
+	args := new(T)
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		for _, set := range o.List() {
+			if set == nil {
+				continue
+			}
+			if err := set(args); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,298 @@
+package memstore
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ApplyUpdate applies an update document of operators to doc and returns the
+// result. insert enables $setOnInsert.
+func ApplyUpdate(doc bson.D, update bson.D, insert bool) (bson.D, error) {
+	if len(update) == 0 {
+		return nil, fmt.Errorf("memstore: update document must not be empty")
+	}
+	for _, op := range update {
+		if !strings.HasPrefix(op.Key, "$") {
+			return nil, fmt.Errorf("memstore: update document must contain only operators, got %q", op.Key)
+		}
+		fields, ok := op.Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("memstore: %s requires a document", op.Key)
+		}
+		for _, f := range fields {
+			if f.Key == "_id" && op.Key != "$setOnInsert" {
+				if op.Key != "$set" || !Equal(getValue(doc, "_id"), f.Value) {
+					return nil, fmt.Errorf("memstore: _id is immutable")
+				}
+			}
+			var err error
+			doc, err = applyOperator(doc, op.Key, f, insert)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return doc, nil
+}
+
+func applyOperator(doc bson.D, op string, f bson.E, insert bool) (bson.D, error) {
+	path := strings.Split(f.Key, ".")
+	switch op {
+	case "$set":
+		return setPath(doc, path, f.Value)
+	case "$setOnInsert":
+		if !insert {
+			return doc, nil
+		}
+		return setPath(doc, path, f.Value)
+	case "$unset":
+		return unsetPath(doc, path), nil
+	case "$inc", "$mul":
+		cur := getValue(doc, f.Key)
+		if cur == nil {
+			cur = int32(0)
+			if op == "$mul" {
+				return setPath(doc, path, zeroLike(f.Value))
+			}
+		}
+		n, err := arith(cur, f.Value, op == "$mul")
+		if err != nil {
+			return nil, fmt.Errorf("memstore: %s on %q: %w", op, f.Key, err)
+		}
+		return setPath(doc, path, n)
+	case "$min", "$max":
+		cur := getValue(doc, f.Key)
+		cmp := Compare(f.Value, cur)
+		if cur == nil || (op == "$min" && cmp < 0) || (op == "$max" && cmp > 0) {
+			return setPath(doc, path, f.Value)
+		}
+		return doc, nil
+	case "$currentDate":
+		return setPath(doc, path, bson.NewDateTimeFromTime(time.Now()))
+	case "$rename":
+		to, ok := f.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("memstore: $rename target must be a string")
+		}
+		vals := lookup(doc, f.Key)
+		if len(vals) == 0 {
+			return doc, nil
+		}
+		doc = unsetPath(doc, path)
+		return setPath(doc, strings.Split(to, "."), vals[0])
+	case "$push", "$addToSet":
+		arr, err := arrayAt(doc, f.Key)
+		if err != nil {
+			return nil, err
+		}
+		items := bson.A{f.Value}
+		if d, ok := f.Value.(bson.D); ok && len(d) > 0 && d[0].Key == "$each" {
+			each, ok := d[0].Value.(bson.A)
+			if !ok {
+				return nil, fmt.Errorf("memstore: $each requires an array")
+			}
+			items = each
+		}
+		for _, item := range items {
+			if op == "$addToSet" && containsEqual(arr, item) {
+				continue
+			}
+			arr = append(arr, item)
+		}
+		return setPath(doc, path, arr)
+	case "$pull":
+		arr, err := arrayAt(doc, f.Key)
+		if err != nil {
+			return nil, err
+		}
+		kept := bson.A{}
+		for _, el := range arr {
+			remove, err := pullMatches(el, f.Value)
+			if err != nil {
+				return nil, err
+			}
+			if !remove {
+				kept = append(kept, el)
+			}
+		}
+		return setPath(doc, path, kept)
+	case "$pop":
+		arr, err := arrayAt(doc, f.Key)
+		if err != nil || len(arr) == 0 {
+			return doc, err
+		}
+		if n, _ := toFloat(f.Value); n < 0 {
+			arr = arr[1:]
+		} else {
+			arr = arr[:len(arr)-1]
+		}
+		return setPath(doc, path, arr)
+	}
+	return nil, fmt.Errorf("memstore: unsupported update operator %s", op)
+}
+
+// pullMatches reports whether an array element matches a $pull condition.
+func pullMatches(el, cond interface{}) (bool, error) {
+	if ops, ok := operatorDoc(cond); ok {
+		return matchOperators([]interface{}{el}, ops)
+	}
+	if cd, ok := cond.(bson.D); ok {
+		if ed, ok := el.(bson.D); ok {
+			return Match(ed, cd)
+		}
+		return false, nil
+	}
+	return Equal(el, cond), nil
+}
+
+func containsEqual(arr bson.A, v interface{}) bool {
+	for _, el := range arr {
+		if Equal(el, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// arrayAt returns a copy of the array at path, or an empty array if unset.
+func arrayAt(doc bson.D, path string) (bson.A, error) {
+	cur := getValue(doc, path)
+	if cur == nil {
+		return bson.A{}, nil
+	}
+	arr, ok := cur.(bson.A)
+	if !ok {
+		return nil, fmt.Errorf("memstore: field %q is not an array", path)
+	}
+	return append(bson.A{}, arr...), nil
+}
+
+// getValue returns the single value at a dotted path, or nil.
+func getValue(doc bson.D, path string) interface{} {
+	vals := lookup(doc, path)
+	if len(vals) == 0 {
+		return nil
+	}
+	return vals[0]
+}
+
+// setPath sets the value at path, creating intermediate documents.
+func setPath(doc bson.D, path []string, val interface{}) (bson.D, error) {
+	out := append(bson.D{}, doc...)
+	for i, e := range out {
+		if e.Key != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			out[i].Value = val
+			return out, nil
+		}
+		nv, err := setIn(e.Value, path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		out[i].Value = nv
+		return out, nil
+	}
+	if len(path) == 1 {
+		return append(out, bson.E{Key: path[0], Value: val}), nil
+	}
+	nv, err := setPath(bson.D{}, path[1:], val)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, bson.E{Key: path[0], Value: nv}), nil
+}
+
+func setIn(container interface{}, path []string, val interface{}) (interface{}, error) {
+	switch c := container.(type) {
+	case bson.D:
+		return setPath(c, path, val)
+	case bson.A:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("memstore: cannot index array with %q", path[0])
+		}
+		out := append(bson.A{}, c...)
+		for len(out) <= i {
+			out = append(out, nil)
+		}
+		if len(path) == 1 {
+			out[i] = val
+			return out, nil
+		}
+		nv, err := setIn(out[i], path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = nv
+		return out, nil
+	case nil:
+		return setPath(bson.D{}, path, val)
+	}
+	return nil, fmt.Errorf("memstore: cannot set %q on a non-document value", strings.Join(path, "."))
+}
+
+// unsetPath removes the value at path, if present.
+func unsetPath(doc bson.D, path []string) bson.D {
+	out := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		if e.Key != path[0] {
+			out = append(out, e)
+			continue
+		}
+		if len(path) == 1 {
+			continue
+		}
+		if sub, ok := e.Value.(bson.D); ok {
+			e.Value = unsetPath(sub, path[1:])
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// arith adds (or multiplies) two numbers, keeping integer types where
+// possible and widening int32 to int64 on overflow.
+func arith(a, b interface{}, mul bool) (interface{}, error) {
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if !okA || !okB {
+		return nil, fmt.Errorf("cannot apply arithmetic to non-numeric value")
+	}
+	_, floatA := a.(float64)
+	_, floatB := b.(float64)
+	if floatA || floatB {
+		if mul {
+			return fa * fb, nil
+		}
+		return fa + fb, nil
+	}
+
+	ia, ib := int64(fa), int64(fb)
+	r := ia + ib
+	if mul {
+		r = ia * ib
+	}
+	_, wideA := a.(int64)
+	_, wideB := b.(int64)
+	if !wideA && !wideB && r >= math.MinInt32 && r <= math.MaxInt32 {
+		return int32(r), nil
+	}
+	return r, nil
+}
+
+func zeroLike(v interface{}) interface{} {
+	switch v.(type) {
+	case float64:
+		return float64(0)
+	case int64:
+		return int64(0)
+	}
+	return int32(0)
+}
@@ -8,6 +8,52 @@ import (
 // StructFields returns all exported fields of a struct, flattening embedded structs.
 // It accepts a reflect.Type that must be a struct type.
 func StructFields(t reflect.Type) []reflect.StructField {
+	promoted := PromotedFields(t)
+	fields := make([]reflect.StructField, len(promoted))
+	for i, f := range promoted {
+		fields[i] = f.StructField
+	}
+	return fields
+}
+
+// PromotedField is an exported struct field together with the embedded
+// structs it is promoted through, outermost first. Via is empty for fields
+// declared on the struct itself.
+type PromotedField struct {
+	reflect.StructField
+	Via []reflect.StructField
+}
+
+// PromotedFields returns the exported fields of a struct, flattening embedded
+// structs. As in the bson encoder, a field hides deeper fields with the same
+// bson key; fields colliding at the same depth, and fields tagged bson:"-",
+// are all returned.
+func PromotedFields(t reflect.Type) []PromotedField {
+	all := FlattenFields(t)
+	depth := make(map[string]int, len(all))
+	for _, f := range all {
+		key := BSONKey(f.StructField)
+		if d, ok := depth[key]; !ok || len(f.Via) < d {
+			depth[key] = len(f.Via)
+		}
+	}
+	fields := make([]PromotedField, 0, len(all))
+	for _, f := range all {
+		if key := BSONKey(f.StructField); key == "-" || len(f.Via) == depth[key] {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// FlattenFields returns every exported field of a struct and of the structs
+// it embeds, including fields hidden by shallower ones. Embedded structs
+// tagged bson:"-" are skipped.
+func FlattenFields(t reflect.Type) []PromotedField {
+	return flattenFields(t, nil)
+}
+
+func flattenFields(t reflect.Type, via []reflect.StructField) []PromotedField {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
@@ -15,7 +61,7 @@ func StructFields(t reflect.Type) []reflect.StructField {
 		return nil
 	}
 
-	var fields []reflect.StructField
+	var fields []PromotedField
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
 		if !f.IsExported() {
@@ -23,15 +69,28 @@ func StructFields(t reflect.Type) []reflect.StructField {
 		}
 		// Flatten embedded structs
 		if f.Anonymous {
-			embedded := StructFields(f.Type)
-			fields = append(fields, embedded...)
+			if BSONKey(f) == "-" {
+				continue
+			}
+			path := append(via[:len(via):len(via)], f)
+			fields = append(fields, flattenFields(f.Type, path)...)
 			continue
 		}
-		fields = append(fields, f)
+		fields = append(fields, PromotedField{StructField: f, Via: via})
 	}
 	return fields
 }
 
+// BSONKey returns the document key of a struct field: its bson tag name, or
+// its lowercased Go name.
+func BSONKey(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("bson"), ",")[0]
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
 // TypeName returns a human-readable type name for a reflect.Type.
 func TypeName(t reflect.Type) string {
 	if t.Kind() == reflect.Ptr {
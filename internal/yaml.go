@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlEntry is one key of a JSON object, in document order.
+type yamlEntry struct {
+	key   string
+	value interface{}
+}
+
+// JSONToYAML converts a JSON document to block-style YAML, keeping object
+// keys in document order. Strings are quoted only when they would otherwise
+// read as another type or break the syntax.
+func JSONToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+	var buf bytes.Buffer
+	switch v := v.(type) {
+	case []yamlEntry:
+		writeYAMLMap(&buf, v, 0)
+	case []interface{}:
+		writeYAMLList(&buf, v, 0)
+	default:
+		buf.WriteString(yamlScalar(v) + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeOrdered reads the next JSON value from dec, as []yamlEntry for
+// objects, []interface{} for arrays, and the decoder's token for scalars.
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		entries := []yamlEntry{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, yamlEntry{key: key.(string), value: v})
+		}
+		_, err := dec.Token()
+		return entries, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		_, err := dec.Token()
+		return items, err
+	}
+	return tok, nil
+}
+
+func writeYAMLMap(buf *bytes.Buffer, entries []yamlEntry, indent int) {
+	if len(entries) == 0 {
+		buf.WriteString(strings.Repeat(" ", indent) + "{}\n")
+		return
+	}
+	for i, e := range entries {
+		// The first key of a map inside a list item follows its "- ".
+		if i > 0 || buf.Len() == 0 || buf.Bytes()[buf.Len()-1] == '\n' {
+			buf.WriteString(strings.Repeat(" ", indent))
+		}
+		buf.WriteString(yamlScalar(e.key) + ":")
+		writeYAMLValue(buf, e.value, indent+2)
+	}
+}
+
+func writeYAMLList(buf *bytes.Buffer, items []interface{}, indent int) {
+	if len(items) == 0 {
+		buf.WriteString(strings.Repeat(" ", indent) + "[]\n")
+		return
+	}
+	for _, item := range items {
+		buf.WriteString(strings.Repeat(" ", indent) + "-")
+		if m, ok := item.([]yamlEntry); ok && len(m) > 0 {
+			buf.WriteString(" ")
+			writeYAMLMap(buf, m, indent+2)
+			continue
+		}
+		writeYAMLValue(buf, item, indent+2)
+	}
+}
+
+// writeYAMLValue writes v after a "key:" or "-": scalars and empty
+// collections on the same line, others on the following lines.
+func writeYAMLValue(buf *bytes.Buffer, v interface{}, indent int) {
+	switch v := v.(type) {
+	case []yamlEntry:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAMLMap(buf, v, indent)
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAMLList(buf, v, indent)
+	default:
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+var (
+	yamlPlain    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./()|-]*( [A-Za-z0-9_./()|-]+)*$`)
+	yamlReserved = map[string]bool{
+		"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+		"null": true, "y": true, "n": true,
+	}
+)
+
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if yamlPlain.MatchString(v) && !yamlReserved[strings.ToLower(v)] {
+			return v
+		}
+		return strconv.Quote(v)
+	}
+	return fmt.Sprint(v)
+}
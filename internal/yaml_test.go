@@ -0,0 +1,42 @@
+package internal
+
+import "testing"
+
+func TestJSONToYAML(t *testing.T) {
+	in := `{"model_name":"User","fields":[{"name":"Email","enum":["a","b c"],"min":0,"required":true},{"name":"Note","default":"","tags":[],"opts":{}}],"hooks":["BeforeSave"],"meta":{"w":"majority","when":"2:30","flag":"yes","nested":[[1,2]]},"nil":null}`
+	want := `model_name: User
+fields:
+  - name: Email
+    enum:
+      - a
+      - b c
+    min: 0
+    required: true
+  - name: Note
+    default: ""
+    tags: []
+    opts: {}
+hooks:
+  - BeforeSave
+meta:
+  w: majority
+  when: "2:30"
+  flag: "yes"
+  nested:
+    -
+      - 1
+      - 2
+nil: null
+`
+	got, err := JSONToYAML([]byte(in))
+	if err != nil {
+		t.Fatalf("JSONToYAML: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("unexpected YAML:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := JSONToYAML([]byte(`{"a":`)); err == nil {
+		t.Error("expected an error for truncated JSON")
+	}
+}
@@ -0,0 +1,302 @@
+package goodm
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dwoolworth/goodm/internal"
+)
+
+// JSONTagIssue describes a struct field whose json tag is missing or names
+// the field differently than its bson tag, so that the API layer and the
+// database disagree on the field's name.
+type JSONTagIssue struct {
+	Struct   string // struct type name
+	Field    string // Go field name
+	BSONName string
+	JSONName string // name encoding/json uses; "" when the json tag is missing
+	Pos      string // file:line, set by FixJSONTags
+}
+
+func (i JSONTagIssue) String() string {
+	prefix := i.Struct + "." + i.Field
+	if i.Pos != "" {
+		prefix = i.Pos + ": " + prefix
+	}
+	if i.JSONName == "" {
+		return fmt.Sprintf("%s: missing json tag (bson name %q)", prefix, i.BSONName)
+	}
+	return fmt.Sprintf("%s: json name %q does not match bson name %q", prefix, i.JSONName, i.BSONName)
+}
+
+// CheckJSONTags reports the fields of the given models and their subdocument
+// structs whose json tags are missing or disagree with their bson names.
+// Fields excluded with json:"-" are skipped, as are the fields of the
+// embedded goodm.Model. Use it in a test to keep API and storage names in
+// step.
+//
+// Example:
+//
+//	for _, issue := range goodm.CheckJSONTags(goodm.GetAll()) {
+//	    t.Error(issue)
+//	}
+func CheckJSONTags(schemas map[string]*Schema) []JSONTagIssue {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []JSONTagIssue
+	seen := map[reflect.Type]bool{}
+	var walk func(structName string, t reflect.Type)
+	walk = func(structName string, t reflect.Type) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		var nested []reflect.Type
+		for _, f := range internal.StructFields(t) {
+			if isModelBaseField(f) {
+				continue
+			}
+			if issue, _, ok := checkJSONTag(structName, f.Name, f.Tag); ok {
+				issues = append(issues, issue)
+			}
+			if f.Tag.Get("bson") == "-" {
+				continue
+			}
+			if base := derefType(f.Type); base.Kind() == reflect.Struct && !isLeafType(base) && base.Name() != "" {
+				nested = append(nested, base)
+			}
+		}
+		for _, n := range nested {
+			walk(n.Name(), n)
+		}
+	}
+	for _, name := range names {
+		s := schemas[name]
+		walk(s.ModelName, s.modelType)
+	}
+	return issues
+}
+
+// isModelBaseField reports whether f was promoted from the embedded
+// goodm.Model.
+func isModelBaseField(f reflect.StructField) bool {
+	base, ok := modelBaseType.FieldByName(f.Name)
+	return ok && base.Type == f.Type && base.Tag == f.Tag
+}
+
+// checkJSONTag compares a field's json tag with its bson tag. It returns the
+// issue and the json tag value that would fix it, or false when the field is
+// consistent, excluded from either encoding, or inlined by bson.
+func checkJSONTag(structName, fieldName string, tag reflect.StructTag) (JSONTagIssue, string, bool) {
+	bsonTag := tag.Get("bson")
+	bsonName, omitempty := ParseBSONTag(bsonTag)
+	if bsonName == "-" || bsonName == "" && strings.Contains(bsonTag, "inline") {
+		return JSONTagIssue{}, "", false
+	}
+	if bsonName == "" {
+		bsonName = strings.ToLower(fieldName)
+	}
+	issue := JSONTagIssue{Struct: structName, Field: fieldName, BSONName: bsonName}
+
+	jsonTag, ok := tag.Lookup("json")
+	if !ok {
+		want := bsonName
+		if omitempty {
+			want += ",omitempty"
+		}
+		return issue, want, true
+	}
+	jsonName, opts, _ := strings.Cut(jsonTag, ",")
+	if jsonName == "-" && opts == "" {
+		return JSONTagIssue{}, "", false
+	}
+	if jsonName == "" {
+		jsonName = fieldName
+	}
+	if jsonName == bsonName {
+		return JSONTagIssue{}, "", false
+	}
+	issue.JSONName = jsonName
+	want := bsonName
+	if opts != "" {
+		want += "," + opts
+	}
+	return issue, want, true
+}
+
+// JSONTagOptions controls FixJSONTags.
+type JSONTagOptions struct {
+	// Rename also rewrites json names that differ from the bson name.
+	// Renaming changes the API's wire format, so by default mismatches are
+	// only reported.
+	Rename bool
+}
+
+// FixJSONTags adds a json tag matching the bson tag, including omitempty, to
+// every field of the Go source src that lacks one, and with opts.Rename
+// rewrites json names that differ from the bson name. It returns the
+// rewritten source and every issue found, fixed or not. Only structs with at
+// least one bson tag are considered; embedded fields, bson inline fields,
+// and json:"-" fields are left alone.
+//
+// Example:
+//
+//	out, issues, err := goodm.FixJSONTags("user.go", src)
+func FixJSONTags(filename string, src []byte, opts ...JSONTagOptions) ([]byte, []JSONTagIssue, error) {
+	var opt JSONTagOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("goodm: %w", err)
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	var issues []JSONTagIssue
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok || !hasBSONTags(st) {
+			return true
+		}
+		for _, field := range st.Fields.List {
+			if len(field.Names) != 1 || !field.Names[0].IsExported() {
+				continue
+			}
+			var raw string
+			if field.Tag != nil {
+				unquoted, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					continue
+				}
+				raw = unquoted
+			}
+			issue, want, ok := checkJSONTag(spec.Name.Name, field.Names[0].Name, reflect.StructTag(raw))
+			if !ok {
+				continue
+			}
+			issue.Pos = fmt.Sprintf("%s:%d", filename, fset.Position(field.Pos()).Line)
+			issues = append(issues, issue)
+			if issue.JSONName != "" && !opt.Rename {
+				continue
+			}
+
+			newTag := quoteTag(setTagValue(raw, "json", want))
+			if field.Tag == nil {
+				pos := fset.Position(field.Type.End()).Offset
+				edits = append(edits, edit{pos, pos, " " + newTag})
+			} else {
+				edits = append(edits, edit{fset.Position(field.Tag.Pos()).Offset, fset.Position(field.Tag.End()).Offset, newTag})
+			}
+		}
+		return true
+	})
+	if len(edits) == 0 {
+		return src, issues, nil
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, nil, fmt.Errorf("goodm: %s: %w", filename, err)
+	}
+	return formatted, issues, nil
+}
+
+// hasBSONTags reports whether any field of st has a bson tag.
+func hasBSONTags(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		raw, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		if _, ok := reflect.StructTag(raw).Lookup("bson"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// setTagValue sets key to value in the struct tag raw, replacing an existing
+// entry in place or adding one after the bson entry, or at the end.
+func setTagValue(raw, key, value string) string {
+	entry := key + ":" + strconv.Quote(value)
+	type span struct {
+		key        string
+		start, end int
+	}
+	var spans []span
+	for i := 0; i < len(raw); {
+		for i < len(raw) && raw[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(raw) && raw[i] > ' ' && raw[i] != ':' && raw[i] != '"' {
+			i++
+		}
+		if i+1 >= len(raw) || raw[i] != ':' || raw[i+1] != '"' {
+			break
+		}
+		name := raw[start:i]
+		i += 2
+		for i < len(raw) && raw[i] != '"' {
+			if raw[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		i++
+		spans = append(spans, span{name, start, i})
+	}
+
+	for _, s := range spans {
+		if s.key == key {
+			return raw[:s.start] + entry + raw[s.end:]
+		}
+	}
+	for _, s := range spans {
+		if s.key == "bson" {
+			return raw[:s.end] + " " + entry + raw[s.end:]
+		}
+	}
+	if raw == "" {
+		return entry
+	}
+	return raw + " " + entry
+}
+
+// quoteTag quotes a struct tag as a raw string literal when possible.
+func quoteTag(raw string) string {
+	if strings.Contains(raw, "`") {
+		return strconv.Quote(raw)
+	}
+	return "`" + raw + "`"
+}
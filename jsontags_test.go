@@ -0,0 +1,122 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+)
+
+type testJSONAddress struct {
+	City string `bson:"city" json:"city"`
+	Zip  string `bson:"zip,omitempty"`
+}
+
+type testJSONCustomer struct {
+	Model   `bson:",inline"`
+	Name    string             `bson:"name" json:"name"`
+	Email   string             `bson:"email"`
+	Phone   string             `bson:"phone" json:"phoneNumber"`
+	Secret  string             `bson:"secret" json:"-"`
+	Cache   string             `bson:"-"`
+	Address *testJSONAddress   `bson:"address" json:"address"`
+	Extra   []testJSONAddress  `bson:"extra" json:"extra"`
+	Labels  map[string]string  `bson:"labels" json:"labels"`
+	Notes   string             `json:"Notes"`
+	Parent  *testJSONCustomer  `bson:"parent,omitempty" json:"parent,omitempty"`
+	ByCity  map[string]float64 `bson:"by_city" json:"by_city,omitempty"`
+}
+
+func TestCheckJSONTags(t *testing.T) {
+	useTestStore(t)
+	if err := Register(&testJSONCustomer{}, "test_json_customers"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testJSONCustomer")
+		registryMu.Unlock()
+	})
+	schema, _ := Get("testJSONCustomer")
+
+	issues := CheckJSONTags(map[string]*Schema{"testJSONCustomer": schema})
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`testJSONCustomer.Email: missing json tag (bson name "email")`,
+		`testJSONCustomer.Phone: json name "phoneNumber" does not match bson name "phone"`,
+		`testJSONCustomer.Notes: json name "Notes" does not match bson name "notes"`,
+		`testJSONAddress.Zip: missing json tag (bson name "zip")`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+const jsonTagSource = `package models
+
+import "github.com/dwoolworth/goodm"
+
+type Customer struct {
+	goodm.Model ` + "`bson:\",inline\"`" + `
+	Name   string ` + "`bson:\"name\" goodm:\"required\"`" + `
+	Email  string ` + "`bson:\"email,omitempty\" json:\"mail,omitempty\"`" + `
+	Notes  string
+	secret string ` + "`bson:\"secret\"`" + `
+	Hidden string ` + "`bson:\"hidden\" json:\"-\"`" + `
+}
+
+type Options struct {
+	Verbose bool
+}
+`
+
+func TestFixJSONTags(t *testing.T) {
+	out, issues, err := FixJSONTags("customer.go", []byte(jsonTagSource))
+	if err != nil {
+		t.Fatalf("FixJSONTags: %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %v", issues)
+	}
+	if issues[0].Pos != "customer.go:7" {
+		t.Errorf("Pos = %q, want customer.go:7", issues[0].Pos)
+	}
+
+	code := string(out)
+	for _, want := range []string{
+		"`bson:\"name\" json:\"name\" goodm:\"required\"`",
+		"`bson:\"email,omitempty\" json:\"mail,omitempty\"`", // mismatch reported, not renamed
+		"Notes       string `json:\"notes\"`",
+		"secret      string `bson:\"secret\"`",
+		"Verbose bool\n",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in:\n%s", want, code)
+		}
+	}
+}
+
+func TestFixJSONTags_Rename(t *testing.T) {
+	out, _, err := FixJSONTags("customer.go", []byte(jsonTagSource), JSONTagOptions{Rename: true})
+	if err != nil {
+		t.Fatalf("FixJSONTags: %v", err)
+	}
+	if !strings.Contains(string(out), "`bson:\"email,omitempty\" json:\"email,omitempty\"`") {
+		t.Errorf("json name not renamed:\n%s", out)
+	}
+
+	again, issues, err := FixJSONTags("customer.go", out, JSONTagOptions{Rename: true})
+	if err != nil {
+		t.Fatalf("FixJSONTags: %v", err)
+	}
+	if len(issues) != 0 || string(again) != string(out) {
+		t.Errorf("second pass not clean: %v", issues)
+	}
+}
+
+func TestFixJSONTags_ParseError(t *testing.T) {
+	if _, _, err := FixJSONTags("bad.go", []byte("package models\ntype {")); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -4,28 +4,42 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
-// saveWithRetry attempts a versioned save, optionally retrying with a 3-way
-// field-level merge when a version conflict occurs. Without retries it still
-// refreshes the model's version on conflict to prevent cascading failures.
-func saveWithRetry(ctx context.Context, coll *mongo.Collection, model interface{}, opt UpdateOptions, id bson.ObjectID) error {
+// saveWithRetry attempts a versioned save and handles version conflicts with
+// the schema's ConflictStrategy. WithRetry on a model without a strategy selects
+// a 3-way field-level merge. Without retries it still refreshes the model's
+// version on conflict to prevent cascading failures.
+func saveWithRetry(ctx context.Context, coll collection, model interface{}, schema *Schema, opt UpdateOptions, carry bson.M, id bson.ObjectID) error {
+	strategy := schema.Conflict
+	if strategy == ConflictFail && opt.MaxRetries > 0 {
+		strategy = ConflictMerge
+	}
+	if strategy == ConflictLastWriteWins {
+		return saveLastWriteWins(ctx, coll, model, opt, carry, id)
+	}
+
+	retries := opt.MaxRetries
+	if retries == 0 && strategy != ConflictFail {
+		retries = defaultConflictRetries
+	}
+
+	// The merge base is the model as it was loaded, so that the caller's
+	// edits show up as changes. Without a tracked original there is no base
+	// to merge against, and the conflict is returned instead.
 	var base bson.M
-	if opt.MaxRetries > 0 {
-		var err error
-		base, err = snapshotModel(model)
-		if err != nil {
-			return err
+	if strategy == ConflictMerge {
+		if base = originalDoc(model); base == nil {
+			strategy = ConflictFail
 		}
 	}
 
 	for attempt := 0; ; attempt++ {
-		err := attemptSave(ctx, coll, model, opt.Unset, id)
+		err := attemptSave(ctx, coll, model, opt.Unset, carry, id)
 		if err == nil {
 			return nil
 		}
@@ -33,15 +47,26 @@ func saveWithRetry(ctx context.Context, coll *mongo.Collection, model interface{
 			return err
 		}
 
-		// Version conflict — can we retry with merge?
-		if base == nil || attempt >= opt.MaxRetries {
+		// Version conflict — can we retry?
+		if strategy == ConflictFail || attempt >= retries {
 			// No retry: refresh version so next caller Update() can succeed.
 			refreshModelVersion(ctx, coll, model, id)
 			return ErrVersionConflict
 		}
 
-		// 3-way merge: re-read DB state, detect conflicts, apply disjoint changes.
-		if err := mergeFromDB(ctx, coll, model, base, id); err != nil {
+		if strategy == ConflictResolve {
+			err = resolveFromDB(ctx, coll, model, schema, id)
+		} else {
+			// 3-way merge: re-read DB state, detect conflicts, apply disjoint changes.
+			err = mergeFromDB(ctx, coll, model, base, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		// The model now reflects the fresh stored state; recompute what
+		// still needs carrying over at the new version.
+		if carry, err = loadCarry(ctx, coll, id, model, schema, opt); err != nil {
 			return err
 		}
 	}
@@ -49,13 +74,19 @@ func saveWithRetry(ctx context.Context, coll *mongo.Collection, model interface{
 
 // attemptSave performs a single versioned replace. Returns ErrVersionConflict
 // if the version filter did not match, or ErrNotFound if the document is gone.
-func attemptSave(ctx context.Context, coll *mongo.Collection, model interface{}, unsetFields []string, id bson.ObjectID) error {
+func attemptSave(ctx context.Context, coll collection, model interface{}, unsetFields []string, carry bson.M, id bson.ObjectID) error {
+	oldVersion, _ := getModelVersion(model)
+	return saveWithFilter(ctx, coll, model, unsetFields, carry, id, buildVersionFilter(id, baseKeysOf(model).version, oldVersion))
+}
+
+// saveWithFilter bumps the model's version and UpdatedAt and replaces the
+// document matched by filter. The version is rolled back if nothing matched.
+func saveWithFilter(ctx context.Context, coll collection, model interface{}, unsetFields []string, carry bson.M, id bson.ObjectID, filter bson.D) error {
 	oldVersion, _ := getModelVersion(model)
 	setModelVersion(model, oldVersion+1)
-	setUpdatedAt(model, time.Now())
+	setUpdatedAt(model, clockNow())
 
-	filter := buildVersionFilter(id, oldVersion)
-	matched, err := replaceWithUnset(ctx, coll, filter, model, unsetFields)
+	matched, err := replaceWithUnset(ctx, coll, filter, model, unsetFields, carry)
 	if err != nil {
 		setModelVersion(model, oldVersion)
 		return fmt.Errorf("goodm: update failed: %w", err)
@@ -71,10 +102,10 @@ func attemptSave(ctx context.Context, coll *mongo.Collection, model interface{},
 // mergeFromDB re-reads the document, computes a 3-way diff (base vs ours vs theirs),
 // and applies non-conflicting changes from the caller onto the fresh DB state.
 // Returns a *MergeConflictError if both sides modified the same fields.
-func mergeFromDB(ctx context.Context, coll *mongo.Collection, model interface{}, base bson.M, id bson.ObjectID) error {
+func mergeFromDB(ctx context.Context, coll collection, model interface{}, base bson.M, id bson.ObjectID) error {
 	// Re-read the current document from the database.
 	fresh := reflect.New(reflect.TypeOf(model).Elem()).Interface()
-	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(fresh); err != nil {
+	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}}, withComment(ctx, options.FindOne())).Decode(fresh); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return ErrNotFound
 		}
@@ -90,10 +121,18 @@ func mergeFromDB(ctx context.Context, coll *mongo.Collection, model interface{},
 		return err
 	}
 
-	ourChanges := diffFields(base, ours)
-	theirChanges := diffFields(base, theirs)
+	keys := baseKeysOf(model)
+	ourChanges := diffFields(keys, base, ours)
+	theirChanges := diffFields(keys, base, theirs)
 
-	conflicts := fieldIntersection(ourChanges, theirChanges)
+	// Fields both sides set to the same value, such as their changes merged
+	// in by an earlier attempt, do not conflict.
+	var conflicts []string
+	for _, field := range fieldIntersection(ourChanges, theirChanges) {
+		if !reflect.DeepEqual(ours[field], theirs[field]) {
+			conflicts = append(conflicts, field)
+		}
+	}
 	if len(conflicts) > 0 {
 		return &MergeConflictError{Fields: conflicts}
 	}
@@ -102,11 +141,11 @@ func mergeFromDB(ctx context.Context, coll *mongo.Collection, model interface{},
 	merged := buildMergedDoc(theirs, ours, ourChanges)
 
 	// Write the merged state back into the caller's model struct.
-	raw, err := bson.Marshal(merged)
+	raw, err := marshalBSON(merged)
 	if err != nil {
 		return fmt.Errorf("goodm: failed to marshal merged document: %w", err)
 	}
-	if err := bson.Unmarshal(raw, model); err != nil {
+	if err := unmarshalBSON(raw, model); err != nil {
 		return fmt.Errorf("goodm: failed to apply merged document: %w", err)
 	}
 
@@ -132,15 +171,20 @@ func buildMergedDoc(theirs, ours bson.M, ourChanges []string) bson.M {
 
 // refreshModelVersion does a best-effort read of the document's current version
 // and updates the model struct so the next Update() call won't cascade-fail.
-func refreshModelVersion(ctx context.Context, coll *mongo.Collection, model interface{}, id bson.ObjectID) {
-	var doc struct {
-		Version int `bson:"__v"`
+func refreshModelVersion(ctx context.Context, coll collection, model interface{}, id bson.ObjectID) {
+	key := baseKeysOf(model).version
+	raw, err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}},
+		withComment(ctx, options.FindOne()).SetProjection(bson.D{{Key: key, Value: 1}})).Raw()
+	if err != nil {
+		return
 	}
-	err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}},
-		options.FindOne().SetProjection(bson.D{{Key: "__v", Value: 1}})).Decode(&doc)
-	if err == nil {
-		setModelVersion(model, doc.Version)
+	var version int
+	if v, err := raw.LookupErr(key); err == nil {
+		if n, ok := v.AsInt64OK(); ok {
+			version = int(n)
+		}
 	}
+	setModelVersion(model, version)
 }
 
 // snapshotModel marshals a model to bson.M, capturing the "base" state before
@@ -151,12 +195,12 @@ func snapshotModel(model interface{}) (bson.M, error) {
 
 // toBsonMap marshals any value to a bson.M via round-trip through raw BSON.
 func toBsonMap(v interface{}) (bson.M, error) {
-	raw, err := bson.Marshal(v)
+	raw, err := marshalBSON(v)
 	if err != nil {
 		return nil, fmt.Errorf("goodm: bson marshal failed: %w", err)
 	}
 	var m bson.M
-	if err := bson.Unmarshal(raw, &m); err != nil {
+	if err := unmarshalBSON(raw, &m); err != nil {
 		return nil, fmt.Errorf("goodm: bson unmarshal failed: %w", err)
 	}
 	return m, nil
@@ -164,10 +208,10 @@ func toBsonMap(v interface{}) (bson.M, error) {
 
 // diffFields returns the bson field names that differ between base and modified,
 // excluding managed fields (_id, __v, timestamps) which are expected to change.
-func diffFields(base, modified bson.M) []string {
+func diffFields(keys baseKeys, base, modified bson.M) []string {
 	var changed []string
 	for key, modVal := range modified {
-		if managedFields[key] {
+		if keys.managed(key) {
 			continue
 		}
 		baseVal, exists := base[key]
@@ -177,7 +221,7 @@ func diffFields(base, modified bson.M) []string {
 	}
 	// Fields present in base but absent in modified (removed/unset).
 	for key := range base {
-		if managedFields[key] {
+		if keys.managed(key) {
 			continue
 		}
 		if _, exists := modified[key]; !exists {
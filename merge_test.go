@@ -42,7 +42,7 @@ func TestDiffFields_NoChanges(t *testing.T) {
 	base := bson.M{"name": "Alice", "age": int32(25), "role": "user"}
 	modified := bson.M{"name": "Alice", "age": int32(25), "role": "user"}
 
-	changes := diffFields(base, modified)
+	changes := diffFields(defaultBaseKeys, base, modified)
 	if len(changes) != 0 {
 		t.Fatalf("expected no changes, got %v", changes)
 	}
@@ -52,7 +52,7 @@ func TestDiffFields_ValueChanged(t *testing.T) {
 	base := bson.M{"name": "Alice", "age": int32(25), "role": "user"}
 	modified := bson.M{"name": "Alice", "age": int32(30), "role": "user"}
 
-	changes := diffFields(base, modified)
+	changes := diffFields(defaultBaseKeys, base, modified)
 	if len(changes) != 1 || changes[0] != "age" {
 		t.Fatalf("expected [age], got %v", changes)
 	}
@@ -62,7 +62,7 @@ func TestDiffFields_MultipleChanges(t *testing.T) {
 	base := bson.M{"name": "Alice", "age": int32(25), "role": "user"}
 	modified := bson.M{"name": "Bob", "age": int32(30), "role": "user"}
 
-	changes := diffFields(base, modified)
+	changes := diffFields(defaultBaseKeys, base, modified)
 	if len(changes) != 2 {
 		t.Fatalf("expected 2 changes, got %v", changes)
 	}
@@ -79,7 +79,7 @@ func TestDiffFields_FieldAdded(t *testing.T) {
 	base := bson.M{"name": "Alice"}
 	modified := bson.M{"name": "Alice", "age": int32(25)}
 
-	changes := diffFields(base, modified)
+	changes := diffFields(defaultBaseKeys, base, modified)
 	if len(changes) != 1 || changes[0] != "age" {
 		t.Fatalf("expected [age], got %v", changes)
 	}
@@ -89,7 +89,7 @@ func TestDiffFields_FieldRemoved(t *testing.T) {
 	base := bson.M{"name": "Alice", "age": int32(25)}
 	modified := bson.M{"name": "Alice"}
 
-	changes := diffFields(base, modified)
+	changes := diffFields(defaultBaseKeys, base, modified)
 	if len(changes) != 1 || changes[0] != "age" {
 		t.Fatalf("expected [age], got %v", changes)
 	}
@@ -99,7 +99,7 @@ func TestDiffFields_SkipsManagedFields(t *testing.T) {
 	base := bson.M{"name": "Alice", "__v": int32(1), "updated_at": "old"}
 	modified := bson.M{"name": "Alice", "__v": int32(2), "updated_at": "new"}
 
-	changes := diffFields(base, modified)
+	changes := diffFields(defaultBaseKeys, base, modified)
 	if len(changes) != 0 {
 		t.Fatalf("expected no changes (managed fields skipped), got %v", changes)
 	}
@@ -243,8 +243,8 @@ func TestEndToEndMergeScenario(t *testing.T) {
 		"status":         "running",
 	}
 
-	ourChanges := diffFields(base, ours)
-	theirChanges := diffFields(base, theirs)
+	ourChanges := diffFields(defaultBaseKeys, base, ours)
+	theirChanges := diffFields(defaultBaseKeys, base, theirs)
 
 	// Our changes should be step and tokens_used.
 	ourSet := map[string]bool{}
@@ -286,8 +286,8 @@ func TestConflictingMergeScenario(t *testing.T) {
 	ours := bson.M{"status": "completed", "step": int32(5)}  // we changed both
 	theirs := bson.M{"status": "failed", "step": int32(4)}   // they changed status
 
-	ourChanges := diffFields(base, ours)
-	theirChanges := diffFields(base, theirs)
+	ourChanges := diffFields(defaultBaseKeys, base, ours)
+	theirChanges := diffFields(defaultBaseKeys, base, theirs)
 
 	conflicts := fieldIntersection(ourChanges, theirChanges)
 	if len(conflicts) != 1 || conflicts[0] != "status" {
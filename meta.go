@@ -0,0 +1,132 @@
+package goodm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MetaCollection is the collection in which Enforce records the schemas it
+// enforced, one SchemaStamp per model.
+const MetaCollection = "goodm_meta"
+
+// SchemaStamp is the MetaCollection document Enforce writes for a model.
+type SchemaStamp struct {
+	Model      string    `bson:"_id"`
+	Collection string    `bson:"collection"`
+	Hash       string    `bson:"hash"`
+	Version    string    `bson:"goodm_version"` // goodm version of the binary that ran Enforce
+	EnforcedAt time.Time `bson:"enforced_at"`
+}
+
+// SchemaMismatch is a model whose registered schema and stamp in
+// MetaCollection disagree.
+type SchemaMismatch struct {
+	Model string
+	Hash  string       // hash of the registered schema, or "" if the model is not registered
+	Stamp *SchemaStamp // the stored stamp, or nil if the model was never enforced
+}
+
+func (m SchemaMismatch) String() string {
+	switch {
+	case m.Stamp == nil:
+		return fmt.Sprintf("%s: never enforced on this database", m.Model)
+	case m.Hash == "":
+		return fmt.Sprintf("%s: enforced by goodm %s at %s, but not registered in this binary",
+			m.Model, m.Stamp.Version, m.Stamp.EnforcedAt.Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("%s: schema %s differs from %s, enforced by goodm %s at %s",
+			m.Model, m.Hash, m.Stamp.Hash, m.Stamp.Version, m.Stamp.EnforcedAt.Format(time.RFC3339))
+	}
+}
+
+// SchemaHash returns a short hash of schema's snapshot: its collection,
+// fields, and compound indexes. Equal hashes mean equal snapshots.
+func SchemaHash(schema *Schema) string {
+	data, _ := json.Marshal(modelSnapshot(schema))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// VerifySchemas compares schemas against the stamps Enforce left in db's
+// MetaCollection, and returns the models that differ, sorted by name: models
+// never enforced, models whose schema changed since, and stamped models
+// missing from schemas. Run it at deploy time to detect a forgotten Enforce
+// or migration.
+//
+// Example:
+//
+//	mismatches, err := goodm.VerifySchemas(ctx, db, goodm.GetAll())
+func VerifySchemas(ctx context.Context, db *mongo.Database, schemas map[string]*Schema) ([]SchemaMismatch, error) {
+	return verifySchemaStamps(ctx, db.Collection(MetaCollection), schemas)
+}
+
+// stampSchemas records schemas in the meta collection coll.
+func stampSchemas(ctx context.Context, coll collection, schemas map[string]*Schema) error {
+	now := time.Now().UTC()
+	version := libraryVersion()
+	for _, s := range schemas {
+		stamp := SchemaStamp{Model: s.ModelName, Collection: s.Collection, Hash: SchemaHash(s), Version: version, EnforcedAt: now}
+		_, err := coll.ReplaceOne(ctx, bson.D{{Key: "_id", Value: stamp.Model}}, stamp, options.Replace().SetUpsert(true))
+		if err != nil {
+			return &EnforcementError{Collection: MetaCollection, Message: fmt.Sprintf("failed to record schema of %s: %v", s.ModelName, err)}
+		}
+	}
+	return nil
+}
+
+func verifySchemaStamps(ctx context.Context, coll collection, schemas map[string]*Schema) ([]SchemaMismatch, error) {
+	cursor, err := coll.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("goodm: read %s: %w", MetaCollection, err)
+	}
+	var stamps []SchemaStamp
+	if err := cursor.All(ctx, &stamps); err != nil {
+		return nil, fmt.Errorf("goodm: read %s: %w", MetaCollection, err)
+	}
+	stored := make(map[string]*SchemaStamp, len(stamps))
+	for i := range stamps {
+		stored[stamps[i].Model] = &stamps[i]
+	}
+
+	var mismatches []SchemaMismatch
+	for name, s := range schemas {
+		hash := SchemaHash(s)
+		if st := stored[name]; st == nil || st.Hash != hash {
+			mismatches = append(mismatches, SchemaMismatch{Model: name, Hash: hash, Stamp: st})
+		}
+		delete(stored, name)
+	}
+	for name, st := range stored {
+		mismatches = append(mismatches, SchemaMismatch{Model: name, Stamp: st})
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Model < mismatches[j].Model })
+	return mismatches, nil
+}
+
+// libraryVersion returns the version of the goodm module built into the
+// running binary, or "devel" if it is unknown.
+func libraryVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	path := reflect.TypeOf(Model{}).PkgPath()
+	mods := append([]*debug.Module{&info.Main}, info.Deps...)
+	for _, m := range mods {
+		if m.Path == path && m.Version != "" && m.Version != "(devel)" {
+			return m.Version
+		}
+	}
+	return "devel"
+}
@@ -0,0 +1,52 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemaStamps_Verify(t *testing.T) {
+	ctx := useTestStore(t)
+	coll := activeTestStore().Collection(MetaCollection)
+
+	user, _ := Get("testUser")
+	profile, _ := Get("testProfile")
+	tag, _ := Get("testTag")
+	if err := stampSchemas(ctx, coll, map[string]*Schema{"testUser": user, "testProfile": profile}); err != nil {
+		t.Fatalf("stamp: %v", err)
+	}
+
+	mismatches, err := verifySchemaStamps(ctx, coll, map[string]*Schema{"testUser": user, "testProfile": profile})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("expected no mismatches right after stamping, got %v", mismatches)
+	}
+
+	changed := *user
+	changed.Fields = append(append([]FieldSchema{}, user.Fields...), FieldSchema{Name: "Nickname", BSONName: "nickname", Type: "string"})
+	if SchemaHash(&changed) == SchemaHash(user) {
+		t.Fatal("expected a new field to change the schema hash")
+	}
+
+	mismatches, err = verifySchemaStamps(ctx, coll, map[string]*Schema{"testUser": &changed, "testTag": tag})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if len(mismatches) != 3 {
+		t.Fatalf("expected 3 mismatches, got %v", mismatches)
+	}
+	for i, want := range []string{
+		"testProfile: enforced by goodm ",
+		"testTag: never enforced",
+		"testUser: schema " + SchemaHash(&changed) + " differs from " + SchemaHash(user),
+	} {
+		if got := mismatches[i].String(); !strings.HasPrefix(got, want) {
+			t.Errorf("mismatch %d: expected prefix %q, got %q", i, want, got)
+		}
+	}
+	if st := mismatches[2].Stamp; st == nil || st.Collection != "test_users" || st.Version == "" || st.EnforcedAt.IsZero() {
+		t.Errorf("expected the stored stamp to be returned, got %+v", st)
+	}
+}
@@ -16,15 +16,30 @@ const (
 	OpCreateMany OpType = "create_many"
 	OpUpdateMany OpType = "update_many"
 	OpDeleteMany OpType = "delete_many"
+	OpAggregate  OpType = "aggregate"
+
+	OpCreateView       OpType = "create_view"
+	OpRenameCollection OpType = "rename_collection"
+	OpDropCollection   OpType = "drop_collection"
+	OpCompact          OpType = "compact"
+	OpReindex          OpType = "reindex"
+	OpRunCommand       OpType = "run_command"
 )
 
 // OpInfo provides context about the current operation to middleware.
 type OpInfo struct {
+	ID         string // unique ID of this operation, see OpIDFromContext
+	ParentID   string // ID of the operation whose hooks or middleware started this one, or ""
 	Operation  OpType
 	Collection string
 	ModelName  string
-	Model      interface{} // the model being operated on, or nil
-	Filter     interface{} // the query filter, if applicable
+	Model      interface{}            // the model being operated on, or nil
+	Filter     interface{}            // the query filter, or the pipeline stages for OpAggregate
+	Result     interface{}            // where FindOne/Find decode their results, or nil
+	Options    interface{}            // the operation's options (e.g. FindOptions), or nil
+	Meta       map[string]interface{} // metadata set on the context with WithOpMeta, or nil
+
+	filterable bool // the operation applies access policy filters
 }
 
 // MiddlewareFunc is a function that wraps a CRUD operation.
@@ -66,8 +81,28 @@ func ClearMiddleware() {
 }
 
 // runMiddleware builds and executes the middleware chain for an operation.
-// If no middleware is registered, fn is called directly.
-func runMiddleware(ctx context.Context, info *OpInfo, fn func(context.Context) error) error {
+// If no middleware is registered, fn is called directly. Writes to views or
+// in read-only mode, and operations the model's access policy denies, are
+// rejected before the chain runs. Operations that run are counted in Stats,
+// and bounded by Config.OperationTimeout.
+func runMiddleware(ctx context.Context, info *OpInfo, fn func(context.Context) error) (err error) {
+	if err := checkWritable(info); err != nil {
+		return err
+	}
+	ctx, err = applyPolicy(ctx, info)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withOperationTimeout(ctx, CurrentConfig())
+	defer cancel()
+	done := trackOp(info)
+	defer func() { done(err) }()
+	info.ID = newOpID()
+	info.ParentID = OpIDFromContext(ctx)
+	ctx = withHookContext(ctx, info)
+	ctx = withQueryComment(ctx, info.ModelName)
+	info.Meta = OpMetaFromContext(ctx)
+
 	mwMu.RLock()
 	chain := make([]MiddlewareFunc, 0, len(globalMW))
 	chain = append(chain, globalMW...)
@@ -3,6 +3,8 @@ package goodm
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -13,7 +15,15 @@ import (
 // MigrateOptions controls migration behavior.
 type MigrateOptions struct {
 	DryRun     bool
-	DropExtras bool // drop indexes not in schema
+	DropExtras bool                // drop indexes not in schema
+	Prune      map[string][]string // retired bson fields to unset, keyed by collection
+	PruneBatch int                 // documents unset per batch when pruning (default 1000)
+	OnPrune    func(PruneResult)   // called after every pruned batch
+
+	// DropCollections lists obsolete collections to drop, in addition to
+	// those retired with RetireCollection. Migrate refuses to drop the
+	// collection of a registered model.
+	DropCollections []string
 }
 
 // ActionType describes the kind of migration action.
@@ -23,6 +33,8 @@ const (
 	ActionCreateIndex ActionType = iota
 	ActionDropIndex
 	ActionFieldDrift // field in DB not in schema
+	ActionPruneFields
+	ActionDropCollection
 )
 
 // MigrationAction describes a single change to apply.
@@ -31,6 +43,7 @@ type MigrationAction struct {
 	Collection  string
 	Description string
 	IndexName   string
+	Fields      []string // fields to unset, for ActionPruneFields
 }
 
 // MigrationPlan holds all planned actions.
@@ -38,6 +51,74 @@ type MigrationPlan struct {
 	Actions []MigrationAction
 }
 
+// Matches reports whether target selects the action: target is a collection
+// name, which selects all of its actions, or "collection:name", which selects
+// the index action on the index called name, or the prune action unsetting
+// the field called name.
+func (a MigrationAction) Matches(target string) bool {
+	coll, name, ok := strings.Cut(target, ":")
+	if coll != a.Collection {
+		return false
+	}
+	if !ok {
+		return true
+	}
+	if a.IndexName != "" {
+		return a.IndexName == name
+	}
+	for _, f := range a.Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the plan with only the actions keep returns true for.
+//
+// Example:
+//
+//	plan = plan.Filter(func(a goodm.MigrationAction) bool { return a.Type != goodm.ActionDropIndex })
+func (p MigrationPlan) Filter(keep func(MigrationAction) bool) MigrationPlan {
+	var out MigrationPlan
+	for _, a := range p.Actions {
+		if keep(a) {
+			out.Actions = append(out.Actions, a)
+		}
+	}
+	return out
+}
+
+// Only returns the plan with only the actions selected by one of targets
+// (see MigrationAction.Matches). A target that selects no action is an
+// error, so a mistyped index name is not silently ignored.
+//
+// Example:
+//
+//	plan, err = plan.Only("users:email_1", "orders")
+func (p MigrationPlan) Only(targets ...string) (MigrationPlan, error) {
+	for _, t := range targets {
+		found := false
+		for _, a := range p.Actions {
+			if a.Matches(t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return MigrationPlan{}, fmt.Errorf("migration: %q matches no planned action", t)
+		}
+	}
+	return p.Filter(func(a MigrationAction) bool {
+		for _, t := range targets {
+			if a.Matches(t) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
 // MigrationResult reports what happened during execution.
 type MigrationResult struct {
 	Executed int
@@ -101,6 +182,15 @@ func PlanMigration(ctx context.Context, db *mongo.Database, schemas map[string]*
 		}
 	}
 
+	// Retired collections that still exist are dropped
+	if retired := RetiredCollections(); len(retired) > 0 {
+		names, err := db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: bson.D{{Key: "$in", Value: retired}}}})
+		if err != nil {
+			return plan, fmt.Errorf("migration: failed to list collections: %w", err)
+		}
+		plan.Actions = append(plan.Actions, PlanDropCollections(names)...)
+	}
+
 	return plan, nil
 }
 
@@ -109,12 +199,10 @@ func ExecuteMigration(ctx context.Context, db *mongo.Database, plan MigrationPla
 	var result MigrationResult
 
 	for _, action := range plan.Actions {
-		coll := db.Collection(action.Collection)
-
 		switch action.Type {
 		case ActionCreateIndex:
 			model := buildIndexModel(action.IndexName)
-			if _, err := coll.Indexes().CreateOne(ctx, model); err != nil {
+			if _, err := db.Collection(action.Collection).Indexes().CreateOne(ctx, model); err != nil {
 				result.Errors = append(result.Errors, fmt.Errorf("%s: %w", action.Description, err))
 			} else {
 				result.Executed++
@@ -126,7 +214,7 @@ func ExecuteMigration(ctx context.Context, db *mongo.Database, plan MigrationPla
 				result.Warnings = append(result.Warnings, fmt.Sprintf("Skipped drop: %s on %s (use --drop-extras to drop)", action.IndexName, action.Collection))
 				continue
 			}
-			if err := coll.Indexes().DropOne(ctx, action.IndexName); err != nil {
+			if err := db.Collection(action.Collection).Indexes().DropOne(ctx, action.IndexName); err != nil {
 				result.Errors = append(result.Errors, fmt.Errorf("%s: %w", action.Description, err))
 			} else {
 				result.Executed++
@@ -134,6 +222,38 @@ func ExecuteMigration(ctx context.Context, db *mongo.Database, plan MigrationPla
 
 		case ActionFieldDrift:
 			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s", action.Collection, action.Description))
+
+		case ActionDropCollection:
+			if schemas := schemasForCollection(action.Collection); len(schemas) > 0 {
+				result.Errors = append(result.Errors, fmt.Errorf("%s: collection belongs to registered model %s", action.Description, schemas[0].ModelName))
+				continue
+			}
+			if err := dropCollection(ctx, db, action.Collection); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("%s: %w", action.Description, err))
+			} else {
+				result.Executed++
+			}
+
+		case ActionPruneFields:
+			schemas := schemasForCollection(action.Collection)
+			if len(schemas) == 0 {
+				result.Errors = append(result.Errors, fmt.Errorf("%s: no registered model for collection %s", action.Description, action.Collection))
+				continue
+			}
+			var err error
+			for _, schema := range schemas {
+				_, err = PruneFields(ctx, reflect.New(schema.modelType).Interface(), action.Fields, PruneOptions{
+					DB: db, BatchSize: opts.PruneBatch, OnProgress: opts.OnPrune,
+				})
+				if err != nil {
+					break
+				}
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("%s: %w", action.Description, err))
+			} else {
+				result.Executed++
+			}
 		}
 	}
 
@@ -148,6 +268,8 @@ func Migrate(ctx context.Context, db *mongo.Database, opts MigrateOptions) (Migr
 	if err != nil {
 		return MigrationResult{}, err
 	}
+	plan.Actions = append(plan.Actions, PlanPrune(opts.Prune)...)
+	plan.Actions = append(plan.Actions, PlanDropCollections(opts.DropCollections)...)
 
 	if opts.DryRun {
 		return MigrationResult{
@@ -159,6 +281,223 @@ func Migrate(ctx context.Context, db *mongo.Database, opts MigrateOptions) (Migr
 	return ExecuteMigration(ctx, db, plan, opts)
 }
 
+// PlanPrune returns the actions that unset retired fields, one per
+// collection in prune, which maps collection names to bson field names.
+// Append them to a plan from PlanMigration to prune as part of a migration.
+func PlanPrune(prune map[string][]string) []MigrationAction {
+	collections := make([]string, 0, len(prune))
+	for coll := range prune {
+		collections = append(collections, coll)
+	}
+	sort.Strings(collections)
+
+	var actions []MigrationAction
+	for _, coll := range collections {
+		fields := prune[coll]
+		if len(fields) == 0 {
+			continue
+		}
+		actions = append(actions, MigrationAction{
+			Type:        ActionPruneFields,
+			Collection:  coll,
+			Description: fmt.Sprintf("Unset retired fields: %s", strings.Join(fields, ", ")),
+			Fields:      fields,
+		})
+	}
+	return actions
+}
+
+// RetireCollection marks collections as obsolete, typically those of models
+// that were removed. PlanMigration proposes dropping each one that still
+// exists. Dropping deletes every document, so only retire a collection once
+// nothing reads it.
+//
+// Example:
+//
+//	func init() {
+//	    goodm.RetireCollection("legacy_sessions")
+//	}
+func RetireCollection(names ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, name := range names {
+		retired[name] = true
+	}
+}
+
+// RetiredCollections returns the collections marked with RetireCollection,
+// sorted by name.
+func RetiredCollections() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(retired))
+	for name := range retired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PlanDropCollections returns the actions that drop the given obsolete
+// collections, skipping duplicates. Append them to a plan from PlanMigration
+// to drop collections not retired with RetireCollection.
+func PlanDropCollections(names []string) []MigrationAction {
+	var actions []MigrationAction
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		actions = append(actions, MigrationAction{
+			Type:        ActionDropCollection,
+			Collection:  name,
+			Description: fmt.Sprintf("Drop collection: %s (obsolete)", name),
+		})
+	}
+	return actions
+}
+
+// dropCollection drops the named collection from db, or from the in-memory
+// store when db is nil and UseTestStore is active.
+func dropCollection(ctx context.Context, db *mongo.Database, name string) error {
+	h, err := getDB(db)
+	if err != nil {
+		return err
+	}
+	if h.mem != nil {
+		return h.mem.Collection(name).Drop(ctx)
+	}
+	return h.Collection(name).Drop(ctx)
+}
+
+// defaultPruneBatch is the number of documents PruneFields updates at once.
+const defaultPruneBatch = 1000
+
+// PruneOptions configures PruneFields.
+type PruneOptions struct {
+	DB         *mongo.Database
+	BatchSize  int               // documents unset per batch (default 1000)
+	OnProgress func(PruneResult) // called after every batch
+}
+
+// PruneResult reports the progress of PruneFields.
+type PruneResult struct {
+	Collection string
+	Fields     []string
+	Total      int64 // documents holding any of the fields when pruning started
+	Pruned     int64 // documents unset so far
+}
+
+// PruneFields unsets retired fields, given by bson name, from every
+// document of model's collection that has them, completing a field's
+// retirement once no code reads or writes it. Documents are updated in
+// batches of opts.BatchSize, so large collections are not locked by one
+// long update, and opts.OnProgress is called after each batch. Hooks and
+// middleware do not run.
+//
+// Only fields the model no longer declares, or declares with the
+// deprecated tag, can be pruned; others are rejected, since the next save
+// would write them back.
+//
+// Example:
+//
+//	res, err := goodm.PruneFields(ctx, &Customer{}, []string{"phone", "fax"}, goodm.PruneOptions{
+//	    OnProgress: func(p goodm.PruneResult) { log.Printf("%d/%d", p.Pruned, p.Total) },
+//	})
+func PruneFields(ctx context.Context, model interface{}, fields []string, opts ...PruneOptions) (*PruneResult, error) {
+	var opt PruneOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("goodm: PruneFields requires at least one field")
+	}
+	for _, name := range fields {
+		if schema.base().managed(name) {
+			return nil, fmt.Errorf("goodm: cannot prune managed field %q", name)
+		}
+		if f := schema.GetField(name); f != nil && !f.Deprecated {
+			return nil, fmt.Errorf("goodm: cannot prune %q: %s still declares it; remove it or tag it deprecated", name, schema.ModelName)
+		}
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = defaultPruneBatch
+	}
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	coll := getCollection(db, schema)
+	var present bson.A
+	unset := bson.D{}
+	for _, name := range fields {
+		present = append(present, bson.D{{Key: name, Value: bson.D{{Key: "$exists", Value: true}}}})
+		unset = append(unset, bson.E{Key: name, Value: ""})
+	}
+	filter := scopeFilter(withDeleted(ctx), schema, bson.D{{Key: "$or", Value: present}})
+
+	result := &PruneResult{Collection: schema.Collection, Fields: fields}
+	result.Total, err = coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("goodm: prune %s: failed to count documents: %w", schema.Collection, err)
+	}
+
+	findOpts := withComment(ctx, options.Find()).
+		SetProjection(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(opt.BatchSize))
+	for {
+		cur, err := coll.Find(ctx, filter, findOpts)
+		if err != nil {
+			return result, fmt.Errorf("goodm: prune %s failed: %w", schema.Collection, err)
+		}
+		var batch []struct {
+			ID interface{} `bson:"_id"`
+		}
+		if err := cur.All(ctx, &batch); err != nil {
+			return result, fmt.Errorf("goodm: prune %s failed: %w", schema.Collection, err)
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+		ids := make(bson.A, len(batch))
+		for i, doc := range batch {
+			ids[i] = doc.ID
+		}
+
+		res, err := coll.UpdateMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}},
+			bson.D{{Key: "$unset", Value: unset}}, withComment(ctx, options.UpdateMany()))
+		if err != nil {
+			return result, fmt.Errorf("goodm: prune %s failed: %w", schema.Collection, err)
+		}
+		if res.ModifiedCount == 0 {
+			return result, nil // nothing left that this filter can change
+		}
+		result.Pruned += res.ModifiedCount
+		if opt.OnProgress != nil {
+			opt.OnProgress(*result)
+		}
+	}
+}
+
+// schemasForCollection returns the registered schemas of a collection, one
+// per kind for polymorphic collections, sorted by model name.
+func schemasForCollection(collection string) []*Schema {
+	var schemas []*Schema
+	for _, s := range GetAll() {
+		if s.Collection == collection {
+			schemas = append(schemas, s)
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].ModelName < schemas[j].ModelName })
+	return schemas
+}
+
 // buildExpectedIndexes constructs the set of index names a schema expects to exist.
 func buildExpectedIndexes(schema *Schema) map[string]bool {
 	expected := make(map[string]bool)
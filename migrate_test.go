@@ -0,0 +1,164 @@
+package goodm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// insertPruneDocs inserts n customers with the deprecated phone field and an
+// undeclared fax field, and one without either.
+func insertPruneDocs(t *testing.T, ctx context.Context, n int) {
+	t.Helper()
+	coll := activeTestStore().Collection("test_deprecated_customers")
+	for i := 0; i < n; i++ {
+		doc := bson.D{{Key: "_id", Value: bson.NewObjectID()}, {Key: "name", Value: "c"}, {Key: "phone", Value: "555"}}
+		if i%2 == 0 {
+			doc = append(doc, bson.E{Key: "fax", Value: "556"})
+		}
+		if _, err := coll.InsertOne(ctx, doc); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if _, err := coll.InsertOne(ctx, bson.D{{Key: "_id", Value: bson.NewObjectID()}, {Key: "name", Value: "clean"}}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+}
+
+func TestPruneFields(t *testing.T) {
+	ctx := useTestStore(t)
+	registerDeprecatedCustomer(t)
+	insertPruneDocs(t, ctx, 5)
+
+	var progress []int64
+	res, err := PruneFields(ctx, &testDeprecatedCustomer{}, []string{"phone", "fax"}, PruneOptions{
+		BatchSize:  2,
+		OnProgress: func(p PruneResult) { progress = append(progress, p.Pruned) },
+	})
+	if err != nil {
+		t.Fatalf("PruneFields: %v", err)
+	}
+	if res.Total != 5 || res.Pruned != 5 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if len(progress) != 3 || progress[0] != 2 || progress[2] != 5 {
+		t.Errorf("progress = %v, want batches of 2", progress)
+	}
+
+	left, err := activeTestStore().Collection("test_deprecated_customers").CountDocuments(ctx, bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "phone", Value: bson.D{{Key: "$exists", Value: true}}}},
+		bson.D{{Key: "fax", Value: bson.D{{Key: "$exists", Value: true}}}},
+	}}})
+	if err != nil || left != 0 {
+		t.Errorf("%d documents still hold pruned fields (%v)", left, err)
+	}
+}
+
+func TestPruneFields_Rejected(t *testing.T) {
+	ctx := useTestStore(t)
+	registerDeprecatedCustomer(t)
+	for _, fields := range [][]string{{"name"}, {"created_at"}, nil} {
+		if _, err := PruneFields(ctx, &testDeprecatedCustomer{}, fields); err == nil {
+			t.Errorf("expected pruning %v to be rejected", fields)
+		}
+	}
+}
+
+func TestExecuteMigration_Prune(t *testing.T) {
+	ctx := useTestStore(t)
+	registerDeprecatedCustomer(t)
+	insertPruneDocs(t, ctx, 3)
+
+	plan := MigrationPlan{Actions: PlanPrune(map[string][]string{
+		"test_deprecated_customers": {"fax"},
+		"nowhere":                   {"x"},
+	})}
+	if len(plan.Actions) != 2 || plan.Actions[1].Description != "Unset retired fields: fax" {
+		t.Fatalf("unexpected plan: %+v", plan.Actions)
+	}
+
+	var last PruneResult
+	result, err := ExecuteMigration(ctx, nil, plan, MigrateOptions{OnPrune: func(p PruneResult) { last = p }})
+	if err != nil {
+		t.Fatalf("ExecuteMigration: %v", err)
+	}
+	if result.Executed != 1 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "nowhere") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if last.Pruned != 2 || last.Fields[0] != "fax" {
+		t.Errorf("unexpected progress: %+v", last)
+	}
+}
+
+func TestMigrationPlan_Only(t *testing.T) {
+	plan := MigrationPlan{Actions: []MigrationAction{
+		{Type: ActionCreateIndex, Collection: "users", IndexName: "email_1"},
+		{Type: ActionDropIndex, Collection: "users", IndexName: "legacy_1"},
+		{Type: ActionFieldDrift, Collection: "users", Description: "Extra field: fax"},
+		{Type: ActionCreateIndex, Collection: "orders", IndexName: "status_1"},
+		{Type: ActionPruneFields, Collection: "customers", Fields: []string{"phone", "fax"}},
+	}}
+
+	only, err := plan.Only("users:email_1", "orders", "customers:fax")
+	if err != nil {
+		t.Fatalf("Only: %v", err)
+	}
+	var got []string
+	for _, a := range only.Actions {
+		got = append(got, a.Collection+":"+a.IndexName)
+	}
+	if strings.Join(got, " ") != "users:email_1 orders:status_1 customers:" {
+		t.Errorf("unexpected selection: %v", got)
+	}
+
+	if _, err := plan.Only("users:emial_1"); err == nil || !strings.Contains(err.Error(), "matches no planned action") {
+		t.Errorf("expected an error for a target that matches nothing, got %v", err)
+	}
+
+	kept := plan.Filter(func(a MigrationAction) bool { return a.Type != ActionDropIndex })
+	if len(kept.Actions) != 4 {
+		t.Errorf("expected the drop to be filtered out, got %+v", kept.Actions)
+	}
+}
+
+func TestExecuteMigration_DropCollections(t *testing.T) {
+	ctx := useTestStore(t)
+	RetireCollection("old_sessions", "old_sessions")
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(retired, "old_sessions")
+		registryMu.Unlock()
+	})
+	if got := RetiredCollections(); len(got) != 1 || got[0] != "old_sessions" {
+		t.Fatalf("expected [old_sessions] retired, got %v", got)
+	}
+
+	old := activeTestStore().Collection("old_sessions")
+	if _, err := old.InsertOne(ctx, bson.D{{Key: "token", Value: "x"}}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if err := Create(ctx, &testUser{Email: "keep@test.com", Name: "Keep"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	plan := MigrationPlan{Actions: PlanDropCollections([]string{"old_sessions", "test_users", "old_sessions"})}
+	if len(plan.Actions) != 2 || plan.Actions[0].Type != ActionDropCollection || !plan.Actions[0].Matches("old_sessions") {
+		t.Fatalf("unexpected plan: %+v", plan.Actions)
+	}
+
+	result, err := ExecuteMigration(ctx, nil, plan, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteMigration: %v", err)
+	}
+	if result.Executed != 1 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "testUser") {
+		t.Errorf("expected the registered collection to be refused, got %+v", result)
+	}
+	if n, _ := old.CountDocuments(ctx, bson.D{}); n != 0 {
+		t.Errorf("expected old_sessions to be dropped, %d documents left", n)
+	}
+	if n, _ := activeTestStore().Collection("test_users").CountDocuments(ctx, bson.D{}); n != 1 {
+		t.Errorf("expected test_users to be kept, got %d documents", n)
+	}
+}
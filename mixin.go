@@ -0,0 +1,131 @@
+package goodm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dwoolworth/goodm/internal"
+)
+
+// checkMixins validates the embedded structs a model is composed from, such
+// as Model, Auditable, or application mixins like a TenantScoped struct:
+//
+//   - every embedded struct must be tagged bson:",inline", since the bson
+//     encoder stores an untagged one as a subdocument while the schema
+//     flattens it
+//   - two mixins at the same depth may not declare the same bson key or the
+//     same Go field name, which the bson encoder and Go both reject as
+//     ambiguous
+//
+// A field declared on the model itself, or on a shallower mixin, hides the
+// deeper field with the same bson key, tags included. Subdocument structs
+// are checked the same way.
+func checkMixins(t reflect.Type, modelName string) error {
+	return checkMixinsIn(t, modelName, make(map[reflect.Type]bool))
+}
+
+func checkMixinsIn(t reflect.Type, path string, seen map[reflect.Type]bool) error {
+	seen[t] = true
+	defer delete(seen, t)
+
+	all := internal.FlattenFields(t)
+	byKey := make(map[string][]internal.PromotedField)
+	byName := make(map[string][]internal.PromotedField)
+	var keys, names []string
+	for _, f := range all {
+		for i, e := range f.Via {
+			if !strings.Contains(e.Tag.Get("bson"), ",inline") {
+				return fmt.Errorf("goodm: %s: embedded %s must be tagged bson:\",inline\"", path, mixinName(f.Via[:i+1]))
+			}
+		}
+		// Fields tagged bson:"-" are not stored, so never collide.
+		if key := internal.BSONKey(f.StructField); key != "-" {
+			if byKey[key] == nil {
+				keys = append(keys, key)
+			}
+			byKey[key] = append(byKey[key], f)
+		}
+		if byName[f.Name] == nil {
+			names = append(names, f.Name)
+		}
+		byName[f.Name] = append(byName[f.Name], f)
+	}
+
+	for _, key := range keys {
+		if a, b, ok := mixinCollision(byKey[key]); ok {
+			if len(a.Via) == 0 {
+				return fmt.Errorf("goodm: %s: bson key %q is declared by both %s and %s", path, key, a.Name, b.Name)
+			}
+			return fmt.Errorf("goodm: %s: bson key %q is declared by both %s and %s; declare %s on the model to choose one",
+				path, key, mixinField(a), mixinField(b), key)
+		}
+	}
+	for _, name := range names {
+		if a, b, ok := mixinCollision(byName[name]); ok {
+			return fmt.Errorf("goodm: %s: field %s is declared by both %s and %s; declare %s on the model to choose one",
+				path, name, mixinName(a.Via), mixinName(b.Via), name)
+		}
+	}
+
+	// Subdocuments may be composed from mixins too.
+	for _, f := range internal.PromotedFields(t) {
+		ft := f.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || isLeafType(ft) || seen[ft] {
+			continue
+		}
+		if err := checkMixinsIn(ft, path+"."+f.Name, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mixinCollision returns two of fields that are declared at the shallowest
+// depth among them, if there are two.
+func mixinCollision(fields []internal.PromotedField) (a, b internal.PromotedField, ok bool) {
+	if len(fields) < 2 {
+		return a, b, false
+	}
+	depth := len(fields[0].Via)
+	for _, f := range fields[1:] {
+		if len(f.Via) < depth {
+			depth = len(f.Via)
+		}
+	}
+	var top []internal.PromotedField
+	for _, f := range fields {
+		if len(f.Via) == depth {
+			top = append(top, f)
+		}
+	}
+	if len(top) < 2 {
+		return a, b, false
+	}
+	return top[0], top[1], true
+}
+
+// mixinField names a field with the mixin it is promoted through.
+func mixinField(f internal.PromotedField) string {
+	if len(f.Via) == 0 {
+		return f.Name
+	}
+	return mixinName(f.Via) + "." + f.Name
+}
+
+// mixinName names the embedded struct a field is promoted through, as a
+// dotted path for nested mixins. It is empty for fields declared on the
+// struct itself.
+func mixinName(via []reflect.StructField) string {
+	names := make([]string, len(via))
+	for i, e := range via {
+		names[i] = e.Name
+	}
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,138 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type TestTenantScoped struct {
+	TenantID string `bson:"tenant_id" goodm:"required,index"`
+}
+
+type TestTimestamps struct {
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+type testMixedDoc struct {
+	Model            `bson:",inline"`
+	Auditable        `bson:",inline"`
+	TestTenantScoped `bson:",inline"`
+	Title            string `bson:"title"`
+}
+
+type testOverrideDoc struct {
+	Model            `bson:",inline"`
+	TestTenantScoped `bson:",inline"`
+	TenantID         string `bson:"tenant_id" goodm:"unique"`
+}
+
+type testCollidingDoc struct {
+	Model          `bson:",inline"`
+	TestTimestamps `bson:",inline"`
+}
+
+type testUntaggedMixinDoc struct {
+	Model `bson:",inline"`
+	TestTenantScoped
+}
+
+func registerMixedDocs(t *testing.T) {
+	t.Helper()
+	if err := Register(&testMixedDoc{}, "test_mixed_docs"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testMixedDoc")
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister_Mixins(t *testing.T) {
+	registerMixedDocs(t)
+	schema, _ := Get("testMixedDoc")
+
+	want := map[string]string{
+		"_id":        "Model",
+		"created_by": "Auditable",
+		"tenant_id":  "TestTenantScoped",
+		"title":      "",
+	}
+	for key, mixin := range want {
+		f := schema.GetField(key)
+		if f == nil {
+			t.Errorf("missing field %s", key)
+			continue
+		}
+		if f.Mixin != mixin {
+			t.Errorf("%s: expected mixin %q, got %q", key, mixin, f.Mixin)
+		}
+	}
+	if f := schema.GetField("tenant_id"); f == nil || !f.Required || !f.Index {
+		t.Errorf("expected the mixin's tags on tenant_id, got %+v", f)
+	}
+}
+
+func TestRegister_MixinOverride(t *testing.T) {
+	if err := Register(&testOverrideDoc{}, "test_override_docs"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testOverrideDoc")
+		registryMu.Unlock()
+	})
+
+	schema, _ := Get("testOverrideDoc")
+	n := 0
+	for _, f := range schema.Fields {
+		if f.BSONName == "tenant_id" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("expected one tenant_id field, got %d", n)
+	}
+	f := schema.GetField("tenant_id")
+	if f.Mixin != "" || !f.Unique || f.Required || f.Index {
+		t.Errorf("expected the model's declaration to replace the mixin's, got %+v", f)
+	}
+}
+
+func TestRegister_MixinCollisions(t *testing.T) {
+	err := Register(&testCollidingDoc{}, "test_colliding_docs")
+	if err == nil || !strings.Contains(err.Error(), `"created_at"`) || !strings.Contains(err.Error(), "TestTimestamps.CreatedAt") {
+		t.Errorf("expected a created_at collision, got %v", err)
+	}
+
+	err = Register(&testUntaggedMixinDoc{}, "test_untagged_docs")
+	if err == nil || !strings.Contains(err.Error(), "embedded TestTenantScoped") {
+		t.Errorf("expected an untagged mixin to be rejected, got %v", err)
+	}
+}
+
+func TestMixins_CRUD(t *testing.T) {
+	ctx := useTestStore(t)
+	registerMixedDocs(t)
+
+	if err := Create(ctx, &testMixedDoc{Title: "no tenant"}); err == nil {
+		t.Error("expected the mixin's required tag to be validated")
+	}
+
+	actor := bson.NewObjectID()
+	doc := &testMixedDoc{Title: "Q3", TestTenantScoped: TestTenantScoped{TenantID: "acme"}}
+	if err := Create(WithActor(ctx, actor), doc); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var found testMixedDoc
+	if err := FindOne(ctx, bson.D{{Key: "tenant_id", Value: "acme"}}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Title != "Q3" || found.CreatedBy != actor || found.CreatedAt.IsZero() {
+		t.Errorf("unexpected document: %+v", found)
+	}
+}
@@ -1,6 +1,9 @@
 package goodm
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -13,4 +16,92 @@ type Model struct {
 	CreatedAt time.Time     `bson:"created_at"`
 	UpdatedAt time.Time     `bson:"updated_at"`
 	Version   int           `bson:"__v"`
+
+	state *modelState // stored form as of the last load or save; see ChangedFields
+}
+
+// baseKeys are the bson keys of the base fields goodm maintains on every
+// model: the timestamps and the version counter.
+type baseKeys struct {
+	createdAt, updatedAt, version string
+}
+
+// defaultBaseKeys are the keys of the base fields of Model.
+var defaultBaseKeys = baseKeys{createdAt: "created_at", updatedAt: "updated_at", version: "__v"}
+
+// baseKeysOf returns the base keys of model, a struct or a pointer to one,
+// as resolved when its type was registered.
+//
+// Models embedding Model use the default keys. A model may instead embed its
+// own base struct declaring ID, CreatedAt, UpdatedAt, and Version fields
+// under other bson keys, e.g. for existing collections using createdAt and
+// _version; goodm then reads and writes those keys. Only the keys may
+// differ: the fields are found by these Go names, here and by the helpers
+// that read and set them (getModelID, setTimestamps, getModelVersion, and
+// the like), so a base field named otherwise is not maintained.
+func baseKeysOf(model interface{}) baseKeys {
+	if schema, err := getSchemaForModel(model); err == nil {
+		return schema.base()
+	}
+	return baseKeysFor(reflect.TypeOf(model))
+}
+
+// baseKeysFor returns the base keys of struct type t, or of the struct t
+// points to. Base fields t does not declare keep their default keys.
+func baseKeysFor(t reflect.Type) baseKeys {
+	keys := defaultBaseKeys
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return keys
+	}
+	for _, b := range []struct {
+		field string
+		key   *string
+	}{{"CreatedAt", &keys.createdAt}, {"UpdatedAt", &keys.updatedAt}, {"Version", &keys.version}} {
+		f, ok := t.FieldByName(b.field)
+		if !ok {
+			continue
+		}
+		name, _ := ParseBSONTag(f.Tag.Get("bson"))
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if name != "-" {
+			*b.key = name
+		}
+	}
+	return keys
+}
+
+// managed reports whether name is _id or the key of a base field.
+func (k baseKeys) managed(name string) bool {
+	return name == "_id" || name == k.createdAt || name == k.updatedAt || name == k.version
+}
+
+// checkBaseFields verifies that the base fields of t have the types goodm
+// maintains them as, since a custom base struct may declare them freely.
+func checkBaseFields(t reflect.Type) error {
+	for _, b := range []struct {
+		field string
+		ok    func(reflect.Type) bool
+		want  string
+	}{
+		{"ID", func(ft reflect.Type) bool { return ft == reflect.TypeOf(bson.ObjectID{}) }, "bson.ObjectID"},
+		{"CreatedAt", func(ft reflect.Type) bool { return ft == timeType }, "time.Time"},
+		{"UpdatedAt", func(ft reflect.Type) bool { return ft == timeType }, "time.Time"},
+		{"Version", func(ft reflect.Type) bool {
+			switch ft.Kind() {
+			case reflect.Int, reflect.Int32, reflect.Int64:
+				return true
+			}
+			return false
+		}, "an int"},
+	} {
+		if f, ok := t.FieldByName(b.field); ok && !b.ok(f.Type) {
+			return fmt.Errorf("goodm: %s.%s must be %s, got %s", t.Name(), b.field, b.want, f.Type)
+		}
+	}
+	return nil
 }
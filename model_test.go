@@ -0,0 +1,116 @@
+package goodm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// LegacyBase is a custom base struct matching an existing collection's
+// naming. Like any inlined struct, it must be exported to be encoded.
+type LegacyBase struct {
+	Tracked   `bson:"-"`
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	CreatedAt time.Time     `bson:"createdAt"`
+	UpdatedAt time.Time     `bson:"updatedAt"`
+	Version   int           `bson:"_version"`
+}
+
+type testLegacyAccount struct {
+	LegacyBase `bson:",inline"`
+	Name       string `bson:"name"`
+	Logins     int    `bson:"logins"`
+}
+
+func TestCustomBaseKeys(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testLegacyAccount{}, "legacy_accounts"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testLegacyAccount")
+		registryMu.Unlock()
+	})
+	schema, _ := Get("testLegacyAccount")
+	if want := (baseKeys{createdAt: "createdAt", updatedAt: "updatedAt", version: "_version"}); schema.baseKeys != want {
+		t.Errorf("expected Register to resolve the base keys %+v, got %+v", want, schema.baseKeys)
+	}
+	if keys := (&Schema{}).base(); keys != defaultBaseKeys {
+		t.Errorf("expected an unregistered schema to use the default keys, got %+v", keys)
+	}
+
+	a := &testLegacyAccount{Name: "ada"}
+	if err := Create(ctx, a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	a.Name = "Ada"
+	if err := Update(ctx, a); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := UpdateFields(ctx, a, bson.M{"logins": 1}, WithVersionCheck()); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	if err := Touch(ctx, a); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	var claimed testLegacyAccount
+	if err := FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: a.ID}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "logins", Value: 1}}}}, &claimed); err != nil {
+		t.Fatalf("find one and update: %v", err)
+	}
+
+	var raw bson.M
+	if err := activeTestStore().Collection("legacy_accounts").FindOne(ctx, bson.D{{Key: "_id", Value: a.ID}}).Decode(&raw); err != nil {
+		t.Fatalf("raw find: %v", err)
+	}
+	for _, key := range []string{"created_at", "updated_at", "__v"} {
+		if _, ok := raw[key]; ok {
+			t.Errorf("expected no default key %s, got %v", key, raw)
+		}
+	}
+	if raw["_version"] != int32(4) && raw["_version"] != int64(4) {
+		t.Errorf("expected _version 4, got %v", raw["_version"])
+	}
+	if _, ok := raw["createdAt"].(bson.DateTime); !ok {
+		t.Errorf("expected createdAt to be set, got %v", raw)
+	}
+	if _, ok := raw["updatedAt"].(bson.DateTime); !ok {
+		t.Errorf("expected updatedAt to be set, got %v", raw)
+	}
+
+	if IsDirty(a) {
+		t.Errorf("expected the custom base model to be tracked, changed %v", ChangedFields(a))
+	}
+	a.Name = "Ada L."
+	if got := ChangedFields(a); len(got) != 1 || got[0] != "name" {
+		t.Errorf("expected name to be changed, got %v", got)
+	}
+	if orig, ok := Original(a).(*testLegacyAccount); !ok || orig.Name != "Ada" || orig.Version != a.Version {
+		t.Errorf("expected the saved state as original, got %+v", Original(a))
+	}
+
+	stale := *a
+	stale.Version = 1
+	stale.Name = "stale"
+	if err := UpdateFields(ctx, &stale, bson.M{"name": "stale"}, WithVersionCheck()); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+	if err := UpdateFields(ctx, a, bson.M{"_version": 9}); err == nil {
+		t.Error("expected the custom version key to be managed")
+	}
+}
+
+func TestCheckBaseFields(t *testing.T) {
+	type badBase struct {
+		Version string `bson:"_version"`
+	}
+	type badModel struct {
+		badBase `bson:",inline"`
+	}
+	if err := Register(&badModel{}, "bad_models"); err == nil {
+		t.Fatal("expected a non-int Version to be rejected")
+	}
+}
@@ -0,0 +1,224 @@
+package goodm
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Store is the set of goodm operations a service depends on. Accept a Store
+// instead of calling the package-level functions to inject a specific
+// database or substitute a mock in tests.
+//
+// Example:
+//
+//	type UserService struct {
+//	    store goodm.Store
+//	}
+//
+//	svc := &UserService{store: goodm.NewStore(db)}
+type Store interface {
+	Create(ctx context.Context, model interface{}, opts ...CreateOptions) error
+	CreateMany(ctx context.Context, models interface{}, opts ...CreateOptions) (*BulkResult, error)
+	FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...FindOptions) error
+	Find(ctx context.Context, filter interface{}, results interface{}, opts ...FindOptions) error
+	FindByIDs(ctx context.Context, ids []bson.ObjectID, results interface{}, opts ...FindOptions) ([]bson.ObjectID, error)
+	FindCursor(ctx context.Context, filter interface{}, model interface{}, opts ...FindOptions) (*mongo.Cursor, error)
+	Update(ctx context.Context, model interface{}, opts ...UpdateOptions) error
+	Save(ctx context.Context, model interface{}, opts ...UpdateOptions) error
+	UpdateFields(ctx context.Context, model interface{}, fields bson.M, opts ...UpdateOptions) error
+	PartialUpdate(ctx context.Context, model interface{}, fields []string, opts ...UpdateOptions) error
+	Touch(ctx context.Context, model interface{}, opts ...UpdateOptions) error
+	UpdateOne(ctx context.Context, filter interface{}, update interface{}, model interface{}, opts ...UpdateOptions) error
+	UpdateMany(ctx context.Context, filter, update interface{}, model interface{}, opts ...UpdateOptions) (*BulkResult, error)
+	FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, result interface{}, opts ...UpdateOptions) error
+	Upsert(ctx context.Context, filter interface{}, model interface{}, opts ...UpdateOptions) error
+	Delete(ctx context.Context, model interface{}, opts ...DeleteOptions) error
+	DeleteOne(ctx context.Context, filter interface{}, model interface{}, opts ...DeleteOptions) error
+	DeleteMany(ctx context.Context, filter interface{}, model interface{}, opts ...DeleteOptions) (*BulkResult, error)
+	FindOneAndDelete(ctx context.Context, filter interface{}, result interface{}, opts ...DeleteOptions) error
+	HardDelete(ctx context.Context, model interface{}, opts ...DeleteOptions) error
+	Restore(ctx context.Context, model interface{}, opts ...UpdateOptions) error
+	FindWithDeleted(ctx context.Context, filter interface{}, results interface{}, opts ...FindOptions) error
+	Populate(ctx context.Context, model interface{}, refs Refs, opts ...PopulateOptions) error
+	Aggregate(ctx context.Context, model interface{}, stages []bson.D, results interface{}, opts ...PipelineOptions) error
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TransactionOptions) error
+}
+
+// MongoStore implements Store on top of the package-level operations, bound
+// to one database.
+type MongoStore struct {
+	db *mongo.Database
+}
+
+var _ Store = (*MongoStore)(nil)
+
+// NewStore returns a Store that runs every operation against db, unless the
+// operation's options name a different one. A nil db selects the global
+// database, so NewStore(nil) behaves exactly like the package-level functions.
+func NewStore(db *mongo.Database) *MongoStore {
+	return &MongoStore{db: db}
+}
+
+// DB returns the database the store is bound to, or nil for the global one.
+func (s *MongoStore) DB() *mongo.Database {
+	return s.db
+}
+
+// bindDB returns opts with the store's database filled in when the caller
+// did not set one. dbField returns the address of an option's DB field.
+func bindDB[O any](db *mongo.Database, opts []O, dbField func(*O) **mongo.Database) []O {
+	if db == nil {
+		return opts
+	}
+	var opt O
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if f := dbField(&opt); *f == nil {
+		*f = db
+	}
+	return []O{opt}
+}
+
+func (s *MongoStore) createOpts(opts []CreateOptions) []CreateOptions {
+	return bindDB(s.db, opts, func(o *CreateOptions) **mongo.Database { return &o.DB })
+}
+
+func (s *MongoStore) findOpts(opts []FindOptions) []FindOptions {
+	return bindDB(s.db, opts, func(o *FindOptions) **mongo.Database { return &o.DB })
+}
+
+func (s *MongoStore) updateOpts(opts []UpdateOptions) []UpdateOptions {
+	return bindDB(s.db, opts, func(o *UpdateOptions) **mongo.Database { return &o.DB })
+}
+
+func (s *MongoStore) deleteOpts(opts []DeleteOptions) []DeleteOptions {
+	return bindDB(s.db, opts, func(o *DeleteOptions) **mongo.Database { return &o.DB })
+}
+
+// Create calls Create against the store's database.
+func (s *MongoStore) Create(ctx context.Context, model interface{}, opts ...CreateOptions) error {
+	return Create(ctx, model, s.createOpts(opts)...)
+}
+
+// CreateMany calls CreateMany against the store's database.
+func (s *MongoStore) CreateMany(ctx context.Context, models interface{}, opts ...CreateOptions) (*BulkResult, error) {
+	return CreateMany(ctx, models, s.createOpts(opts)...)
+}
+
+// FindOne calls FindOne against the store's database.
+func (s *MongoStore) FindOne(ctx context.Context, filter interface{}, result interface{}, opts ...FindOptions) error {
+	return FindOne(ctx, filter, result, s.findOpts(opts)...)
+}
+
+// Find calls Find against the store's database.
+func (s *MongoStore) Find(ctx context.Context, filter interface{}, results interface{}, opts ...FindOptions) error {
+	return Find(ctx, filter, results, s.findOpts(opts)...)
+}
+
+// FindByIDs calls FindByIDs against the store's database.
+func (s *MongoStore) FindByIDs(ctx context.Context, ids []bson.ObjectID, results interface{}, opts ...FindOptions) ([]bson.ObjectID, error) {
+	return FindByIDs(ctx, ids, results, s.findOpts(opts)...)
+}
+
+// FindCursor calls FindCursor against the store's database.
+func (s *MongoStore) FindCursor(ctx context.Context, filter interface{}, model interface{}, opts ...FindOptions) (*mongo.Cursor, error) {
+	return FindCursor(ctx, filter, model, s.findOpts(opts)...)
+}
+
+// Update calls Update against the store's database.
+func (s *MongoStore) Update(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
+	return Update(ctx, model, s.updateOpts(opts)...)
+}
+
+// Save calls Save against the store's database.
+func (s *MongoStore) Save(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
+	return Save(ctx, model, s.updateOpts(opts)...)
+}
+
+// UpdateFields calls UpdateFields against the store's database.
+func (s *MongoStore) UpdateFields(ctx context.Context, model interface{}, fields bson.M, opts ...UpdateOptions) error {
+	return UpdateFields(ctx, model, fields, s.updateOpts(opts)...)
+}
+
+// PartialUpdate calls PartialUpdate against the store's database.
+func (s *MongoStore) PartialUpdate(ctx context.Context, model interface{}, fields []string, opts ...UpdateOptions) error {
+	return PartialUpdate(ctx, model, fields, s.updateOpts(opts)...)
+}
+
+// Touch calls Touch against the store's database.
+func (s *MongoStore) Touch(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
+	return Touch(ctx, model, s.updateOpts(opts)...)
+}
+
+// UpdateOne calls UpdateOne against the store's database.
+func (s *MongoStore) UpdateOne(ctx context.Context, filter interface{}, update interface{}, model interface{}, opts ...UpdateOptions) error {
+	return UpdateOne(ctx, filter, update, model, s.updateOpts(opts)...)
+}
+
+// UpdateMany calls UpdateMany against the store's database.
+func (s *MongoStore) UpdateMany(ctx context.Context, filter, update interface{}, model interface{}, opts ...UpdateOptions) (*BulkResult, error) {
+	return UpdateMany(ctx, filter, update, model, s.updateOpts(opts)...)
+}
+
+// FindOneAndUpdate calls FindOneAndUpdate against the store's database.
+func (s *MongoStore) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, result interface{}, opts ...UpdateOptions) error {
+	return FindOneAndUpdate(ctx, filter, update, result, s.updateOpts(opts)...)
+}
+
+// Upsert calls Upsert against the store's database.
+func (s *MongoStore) Upsert(ctx context.Context, filter interface{}, model interface{}, opts ...UpdateOptions) error {
+	return Upsert(ctx, filter, model, s.updateOpts(opts)...)
+}
+
+// Delete calls Delete against the store's database.
+func (s *MongoStore) Delete(ctx context.Context, model interface{}, opts ...DeleteOptions) error {
+	return Delete(ctx, model, s.deleteOpts(opts)...)
+}
+
+// DeleteOne calls DeleteOne against the store's database.
+func (s *MongoStore) DeleteOne(ctx context.Context, filter interface{}, model interface{}, opts ...DeleteOptions) error {
+	return DeleteOne(ctx, filter, model, s.deleteOpts(opts)...)
+}
+
+// DeleteMany calls DeleteMany against the store's database.
+func (s *MongoStore) DeleteMany(ctx context.Context, filter interface{}, model interface{}, opts ...DeleteOptions) (*BulkResult, error) {
+	return DeleteMany(ctx, filter, model, s.deleteOpts(opts)...)
+}
+
+// FindOneAndDelete calls FindOneAndDelete against the store's database.
+func (s *MongoStore) FindOneAndDelete(ctx context.Context, filter interface{}, result interface{}, opts ...DeleteOptions) error {
+	return FindOneAndDelete(ctx, filter, result, s.deleteOpts(opts)...)
+}
+
+// HardDelete calls HardDelete against the store's database.
+func (s *MongoStore) HardDelete(ctx context.Context, model interface{}, opts ...DeleteOptions) error {
+	return HardDelete(ctx, model, s.deleteOpts(opts)...)
+}
+
+// Restore calls Restore against the store's database.
+func (s *MongoStore) Restore(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
+	return Restore(ctx, model, s.updateOpts(opts)...)
+}
+
+// FindWithDeleted calls FindWithDeleted against the store's database.
+func (s *MongoStore) FindWithDeleted(ctx context.Context, filter interface{}, results interface{}, opts ...FindOptions) error {
+	return FindWithDeleted(ctx, filter, results, s.findOpts(opts)...)
+}
+
+// Populate calls Populate against the store's database.
+func (s *MongoStore) Populate(ctx context.Context, model interface{}, refs Refs, opts ...PopulateOptions) error {
+	return Populate(ctx, model, refs, bindDB(s.db, opts, func(o *PopulateOptions) **mongo.Database { return &o.DB })...)
+}
+
+// Aggregate calls Aggregate against the store's database.
+func (s *MongoStore) Aggregate(ctx context.Context, model interface{}, stages []bson.D, results interface{}, opts ...PipelineOptions) error {
+	return Aggregate(ctx, model, stages, results, bindDB(s.db, opts, func(o *PipelineOptions) **mongo.Database { return &o.DB })...)
+}
+
+// WithTransaction calls WithTransaction against the store's database.
+func (s *MongoStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TransactionOptions) error {
+	return WithTransaction(ctx, fn, bindDB(s.db, opts, func(o *TransactionOptions) **mongo.Database { return &o.DB })...)
+}
@@ -0,0 +1,89 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestBindDB(t *testing.T) {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer func() { _ = client.Disconnect(context.Background()) }()
+	bound, other := client.Database("bound"), client.Database("other")
+	s := NewStore(bound)
+
+	opts := s.findOpts([]FindOptions{{Limit: 5}})
+	if len(opts) != 1 || opts[0].DB != bound || opts[0].Limit != 5 {
+		t.Fatalf("expected store DB with caller's options kept, got %+v", opts)
+	}
+	if opts := s.updateOpts(nil); len(opts) != 1 || opts[0].DB != bound {
+		t.Fatalf("expected store DB without caller options, got %+v", opts)
+	}
+	if opts := s.createOpts([]CreateOptions{{DB: other}}); opts[0].DB != other {
+		t.Fatal("expected caller's DB to take precedence over the store's")
+	}
+	if opts := NewStore(nil).deleteOpts(nil); len(opts) != 0 {
+		t.Fatalf("expected no options for the default store, got %+v", opts)
+	}
+}
+
+// userService stands in for application code that accepts a Store.
+type userService struct {
+	store Store
+}
+
+func (s *userService) register(ctx context.Context, email string) (*testUser, error) {
+	u := &testUser{Email: email, Name: "New"}
+	return u, s.store.Create(ctx, u)
+}
+
+func TestStore_DefaultInstance(t *testing.T) {
+	ctx := useTestStore(t)
+	svc := &userService{store: NewStore(nil)}
+
+	u, err := svc.register(ctx, "svc@test.com")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	found := &testUser{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, found); err != nil {
+		t.Fatalf("expected store writes to be visible to package-level functions: %v", err)
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestStore_BoundDatabase(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	other := db.Client().Database(db.Name() + "_store")
+	defer func() { _ = other.Drop(ctx) }()
+	s := NewStore(other)
+
+	u := &testUser{Email: "bound@test.com", Name: "Bound"}
+	if err := s.Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, &testUser{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected document to be absent from the global database, got %v", err)
+	}
+	found := &testUser{}
+	if err := s.FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	found.Age = 40
+	if err := s.Update(ctx, found); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := s.Delete(ctx, found); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
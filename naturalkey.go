@@ -0,0 +1,121 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// checkKeys validates the natural key a model declares with Keys and gives
+// it a unique compound index, unless the fields are already unique.
+func checkKeys(schema *Schema) error {
+	if schema.Keys == nil {
+		return nil
+	}
+	if len(schema.Keys) == 0 {
+		return fmt.Errorf("goodm: %s: Keys returned no fields", schema.ModelName)
+	}
+	for _, k := range schema.Keys {
+		if !schema.HasField(k) {
+			return fmt.Errorf("goodm: %s: key field %q is not a field of the model", schema.ModelName, k)
+		}
+	}
+	if len(schema.Keys) == 1 && schema.GetField(schema.Keys[0]).Unique {
+		return nil
+	}
+	for _, ci := range schema.CompoundIndexes {
+		if ci.Unique && strings.Join(ci.Fields, ",") == strings.Join(schema.Keys, ",") {
+			return nil
+		}
+	}
+	schema.CompoundIndexes = append(schema.CompoundIndexes, NewUniqueCompoundIndex(schema.Keys...))
+	return nil
+}
+
+// keyFilter returns the filter matching model's natural key.
+func keyFilter(model interface{}, schema *Schema) (bson.D, error) {
+	if len(schema.Keys) == 0 {
+		return nil, fmt.Errorf("goodm: model %q declares no natural key (implement Keys() []string)", schema.ModelName)
+	}
+	v := reflect.Indirect(reflect.ValueOf(model))
+	filter := make(bson.D, 0, len(schema.Keys))
+	empty := true
+	for _, k := range schema.Keys {
+		fv := v.FieldByName(schema.GetField(k).Name)
+		if !fv.IsZero() {
+			empty = false
+		}
+		filter = append(filter, bson.E{Key: k, Value: fv.Interface()})
+	}
+	if empty {
+		return nil, fmt.Errorf("goodm: natural key of %q is empty", schema.ModelName)
+	}
+	return filter, nil
+}
+
+// FindByKey loads the document whose natural key matches model's key fields
+// into model. Returns ErrNotFound if no document matches.
+//
+// Example:
+//
+//	func (p *Product) Keys() []string { return []string{"vendor", "sku"} }
+//
+//	p := &Product{Vendor: "acme", SKU: "A-100"}
+//	err := goodm.FindByKey(ctx, p)
+func FindByKey(ctx context.Context, model interface{}, opts ...FindOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	filter, err := keyFilter(model, schema)
+	if err != nil {
+		return err
+	}
+	return FindOne(ctx, filter, model, opts...)
+}
+
+// UpsertByKey saves model by its natural key: it creates the document when no
+// document has the same key, and otherwise updates that document, taking over
+// its ID, CreatedAt, and Version. Both paths run the full Create or Update
+// lifecycle, including hooks and validation.
+//
+// Two concurrent upserts of a new key may both try to create it; the unique
+// index on the key rejects the second one.
+//
+// Example:
+//
+//	err := goodm.UpsertByKey(ctx, &Product{Vendor: "acme", SKU: "A-100", Price: 995})
+func UpsertByKey(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	filter, err := keyFilter(model, schema)
+	if err != nil {
+		return err
+	}
+	var opt UpdateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	existing := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+	err = FindOne(ctx, filter, existing, FindOptions{DB: opt.DB})
+	if errors.Is(err, ErrNotFound) {
+		return Create(ctx, model, CreateOptions{DB: opt.DB})
+	}
+	if err != nil {
+		return err
+	}
+
+	id, _ := getModelID(existing)
+	version, _ := getModelVersion(existing)
+	setModelID(model, id)
+	setModelVersion(model, version)
+	copyCreatedAt(model, existing)
+	return Update(ctx, model, opt)
+}
@@ -0,0 +1,82 @@
+package goodm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testProduct struct {
+	Model  `bson:",inline"`
+	Vendor string `bson:"vendor"`
+	SKU    string `bson:"sku"`
+	Price  int    `bson:"price"`
+}
+
+func (p *testProduct) Keys() []string { return []string{"vendor", "sku"} }
+
+func registerProductModel(t *testing.T) {
+	t.Helper()
+	if err := Register(&testProduct{}, "test_products"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testProduct")
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister_Keys(t *testing.T) {
+	registerProductModel(t)
+
+	schema, _ := Get("testProduct")
+	if len(schema.CompoundIndexes) != 1 || !schema.CompoundIndexes[0].Unique ||
+		compoundIndexName(schema.CompoundIndexes[0]) != "vendor_1_sku_1" {
+		t.Fatalf("expected a unique vendor_1_sku_1 index, got %+v", schema.CompoundIndexes)
+	}
+}
+
+func TestFindByKey_UpsertByKey(t *testing.T) {
+	ctx := useTestStore(t)
+	registerProductModel(t)
+
+	if err := FindByKey(ctx, &testProduct{Vendor: "acme", SKU: "A-1"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := FindByKey(ctx, &testProduct{}); err == nil {
+		t.Fatal("expected an empty key to be rejected")
+	}
+
+	created := &testProduct{Vendor: "acme", SKU: "A-1", Price: 100}
+	if err := UpsertByKey(ctx, created); err != nil {
+		t.Fatalf("upsert (create): %v", err)
+	}
+	if created.ID.IsZero() || created.Version != 0 {
+		t.Fatalf("expected a created document, got %+v", created)
+	}
+
+	updated := &testProduct{Vendor: "acme", SKU: "A-1", Price: 120}
+	if err := UpsertByKey(ctx, updated); err != nil {
+		t.Fatalf("upsert (update): %v", err)
+	}
+	if updated.ID != created.ID || updated.Version != 1 || !updated.CreatedAt.Equal(created.CreatedAt.Truncate(time.Millisecond)) {
+		t.Fatalf("expected the existing document to be updated, got %+v", updated)
+	}
+
+	found := &testProduct{Vendor: "acme", SKU: "A-1"}
+	if err := FindByKey(ctx, found); err != nil {
+		t.Fatalf("find by key: %v", err)
+	}
+	if found.Price != 120 {
+		t.Fatalf("expected price 120, got %d", found.Price)
+	}
+	var all []testProduct
+	if err := Find(ctx, map[string]interface{}{}, &all); err != nil || len(all) != 1 {
+		t.Fatalf("expected 1 product, got %d (%v)", len(all), err)
+	}
+
+	if err := FindByKey(ctx, &testUser{Email: "x@test.com"}); err == nil {
+		t.Fatal("expected a model without Keys to be rejected")
+	}
+}
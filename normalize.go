@@ -0,0 +1,313 @@
+package goodm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// NormalizerFunc transforms a string value before it is validated and written.
+type NormalizerFunc func(string) string
+
+var (
+	normalizerMu sync.RWMutex
+	normalizers  = map[string]NormalizerFunc{
+		"trim":  strings.TrimSpace,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": titleCase,
+	}
+)
+
+// TimeNormalizerFunc transforms a time value before it is validated and
+// written. Time normalizers are referenced from the same normalize= tag as
+// string normalizers and apply to time.Time fields.
+type TimeNormalizerFunc func(time.Time) time.Time
+
+var timeNormalizers = map[string]TimeNormalizerFunc{
+	"utc": time.Time.UTC,
+	"day": dateOnly,
+}
+
+// RegisterNormalizer adds a named normalizer that can be referenced from
+// `goodm:"normalize=..."` tags. Registering an existing name replaces it,
+// including the built-in trim, lower, upper, and title normalizers. Register
+// normalizers before the models that use them: Register rejects unknown names.
+//
+// Example:
+//
+//	goodm.RegisterNormalizer("digits", func(s string) string {
+//	    return strings.Map(func(r rune) rune {
+//	        if unicode.IsDigit(r) {
+//	            return r
+//	        }
+//	        return -1
+//	    }, s)
+//	})
+func RegisterNormalizer(name string, fn NormalizerFunc) {
+	normalizerMu.Lock()
+	defer normalizerMu.Unlock()
+	normalizers[name] = fn
+}
+
+// RegisterTimeNormalizer adds a named normalizer for time.Time fields that
+// can be referenced from `goodm:"normalize=..."` tags. Registering an existing
+// name replaces it, including the built-in utc and day normalizers. Like
+// RegisterNormalizer, it must be called before the models that use it.
+//
+// Example:
+//
+//	goodm.RegisterTimeNormalizer("minute", func(t time.Time) time.Time {
+//	    return t.UTC().Truncate(time.Minute)
+//	})
+func RegisterTimeNormalizer(name string, fn TimeNormalizerFunc) {
+	normalizerMu.Lock()
+	defer normalizerMu.Unlock()
+	timeNormalizers[name] = fn
+}
+
+// getTimeNormalizer looks up a registered time normalizer by name.
+func getTimeNormalizer(name string) (TimeNormalizerFunc, bool) {
+	normalizerMu.RLock()
+	defer normalizerMu.RUnlock()
+	fn, ok := timeNormalizers[name]
+	return fn, ok
+}
+
+// getNormalizer looks up a registered normalizer by name.
+func getNormalizer(name string) (NormalizerFunc, bool) {
+	normalizerMu.RLock()
+	defer normalizerMu.RUnlock()
+	fn, ok := normalizers[name]
+	return fn, ok
+}
+
+// checkNormalizers verifies at Register time that every name in a normalize=
+// tag refers to a registered normalizer of the field's kind: a time
+// normalizer for time fields, a string normalizer otherwise. Normalizers must
+// therefore be registered before the models that use them.
+func checkNormalizers(schema *Schema) error {
+	return checkNormalizerFields(schema.ModelName, schema.Fields)
+}
+
+// checkNormalizerFields is checkNormalizers for one level of fields.
+func checkNormalizerFields(modelName string, fields []FieldSchema) error {
+	for _, f := range fields {
+		isTime := strings.TrimLeft(f.Type, "[]*") == "time.Time"
+		for _, name := range f.Normalize {
+			if isTime {
+				if _, ok := getTimeNormalizer(name); !ok {
+					return fmt.Errorf("goodm: %s: field %q uses unknown time normalizer %q", modelName, f.BSONName, name)
+				}
+			} else if _, ok := getNormalizer(name); !ok {
+				return fmt.Errorf("goodm: %s: field %q uses unknown normalizer %q", modelName, f.BSONName, name)
+			}
+		}
+		if err := checkNormalizerFields(modelName, f.SubFields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyNormalizers runs each field's normalizers over its string or time
+// value(s), and reduces dateonly fields to their date.
+// Called on the write path after hooks and before validation.
+func applyNormalizers(model interface{}, schema *Schema) error {
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	return normalizeFields(v, schema.Fields)
+}
+
+// normalizeFields recursively normalizes string fields, including fields inside
+// subdocuments and slice elements.
+func normalizeFields(v reflect.Value, fields []FieldSchema) error {
+	for _, field := range fields {
+		fv := v.FieldByName(field.Name)
+		if !fv.IsValid() || !fv.CanSet() {
+			continue
+		}
+
+		if names := fieldNormalizers(field); len(names) > 0 {
+			if err := normalizeValue(fv, names); err != nil {
+				return fmt.Errorf("goodm: cannot normalize field %s: %w", field.Name, err)
+			}
+		}
+
+		if len(field.SubFields) > 0 {
+			if err := normalizeSubFields(fv, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeUpdates runs the normalizers of the fields set by an UpdateFields
+// call over their values, as applyNormalizers does for a whole model: string
+// normalizers over string values and time normalizers over time.Time values,
+// or pointers or slices of either. Values of another type than the field's are
+// left for validation to reject. Normalized slices and pointers are copies, so
+// the caller's values are not modified.
+func normalizeUpdates(schema *Schema, fields bson.M) error {
+	for name, v := range fields {
+		f := schema.GetField(name)
+		if f == nil || v == nil {
+			continue
+		}
+		names := fieldNormalizers(*f)
+		if len(names) == 0 {
+			continue
+		}
+		fv := reflect.New(reflect.TypeOf(v)).Elem()
+		fv.Set(reflect.ValueOf(v))
+		base := fv.Type()
+		switch {
+		case fv.Kind() == reflect.Ptr && isNormalizable(base.Elem()):
+			if fv.IsNil() {
+				continue
+			}
+			base = base.Elem()
+			elem := reflect.New(base)
+			elem.Elem().Set(fv.Elem())
+			fv.Set(elem)
+		case fv.Kind() == reflect.Slice && isNormalizable(base.Elem()):
+			base = base.Elem()
+			fv.Set(reflect.AppendSlice(reflect.MakeSlice(fv.Type(), 0, fv.Len()), fv))
+		}
+		isTime := strings.TrimLeft(f.Type, "[]*") == "time.Time"
+		if isTime != (base == timeType) || (!isTime && base.Kind() != reflect.String) {
+			continue
+		}
+		if err := normalizeValue(fv, names); err != nil {
+			return fmt.Errorf("goodm: cannot normalize field %s: %w", f.Name, err)
+		}
+		fields[name] = fv.Interface()
+	}
+	return nil
+}
+
+// fieldNormalizers returns the normalizers applied to field: those of its
+// normalize tag, followed by day for dateonly fields.
+func fieldNormalizers(field FieldSchema) []string {
+	if !field.DateOnly {
+		return field.Normalize
+	}
+	return append(append([]string{}, field.Normalize...), "day")
+}
+
+// normalizeSubFields applies normalizers to nested struct or slice-of-struct fields.
+func normalizeSubFields(fv reflect.Value, field FieldSchema) error {
+	if field.IsSlice {
+		for i := 0; i < fv.Len(); i++ {
+			elemVal := fv.Index(i)
+			if elemVal.Kind() == reflect.Ptr {
+				if elemVal.IsNil() {
+					continue
+				}
+				elemVal = elemVal.Elem()
+			}
+			if err := normalizeFields(elemVal, field.SubFields); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	innerVal := fv
+	if innerVal.Kind() == reflect.Ptr {
+		if innerVal.IsNil() {
+			return nil
+		}
+		innerVal = innerVal.Elem()
+	}
+	return normalizeFields(innerVal, field.SubFields)
+}
+
+// normalizeValue applies the named normalizers in order to a string or
+// time.Time value, or a pointer or slice of either. Other kinds are left
+// untouched.
+func normalizeValue(fv reflect.Value, names []string) error {
+	switch {
+	case fv.Type() == timeType:
+		t, err := runTimeNormalizers(fv.Interface().(time.Time), names)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+	case fv.Kind() == reflect.String:
+		s, err := runNormalizers(fv.String(), names)
+		if err != nil {
+			return err
+		}
+		fv.SetString(s)
+	case fv.Kind() == reflect.Ptr && isNormalizable(fv.Type().Elem()):
+		if fv.IsNil() {
+			return nil
+		}
+		return normalizeValue(fv.Elem(), names)
+	case fv.Kind() == reflect.Slice && isNormalizable(fv.Type().Elem()):
+		for i := 0; i < fv.Len(); i++ {
+			if err := normalizeValue(fv.Index(i), names); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isNormalizable reports whether values of t are normalized directly.
+func isNormalizable(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t == timeType
+}
+
+// runNormalizers pipes s through each named normalizer.
+func runNormalizers(s string, names []string) (string, error) {
+	for _, name := range names {
+		fn, ok := getNormalizer(name)
+		if !ok {
+			return "", fmt.Errorf("unknown normalizer %q", name)
+		}
+		s = fn(s)
+	}
+	return s, nil
+}
+
+// runTimeNormalizers pipes t through each named time normalizer.
+func runTimeNormalizers(t time.Time, names []string) (time.Time, error) {
+	for _, name := range names {
+		fn, ok := getTimeNormalizer(name)
+		if !ok {
+			return t, fmt.Errorf("unknown time normalizer %q", name)
+		}
+		t = fn(t)
+	}
+	return t, nil
+}
+
+// titleCase upper-cases the first letter of each word and lower-cases the rest.
+func titleCase(s string) string {
+	runes := []rune(s)
+	start := true
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			start = true
+			continue
+		}
+		if start {
+			runes[i] = unicode.ToUpper(r)
+			start = false
+		} else {
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+	return string(runes)
+}
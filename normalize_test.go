@@ -0,0 +1,292 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestApplyNormalizers_BuiltIns(t *testing.T) {
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "Email", BSONName: "email", Normalize: []string{"trim", "lower"}},
+			{Name: "Code", BSONName: "code", Normalize: []string{"upper"}},
+			{Name: "Name", BSONName: "name", Normalize: []string{"title"}},
+		},
+	}
+
+	type model struct {
+		Email string
+		Code  string
+		Name  string
+	}
+
+	m := &model{Email: "  Alice@Example.COM ", Code: "ab-12", Name: "aLICE van DYKE"}
+	if err := applyNormalizers(m, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Email != "alice@example.com" {
+		t.Fatalf("expected normalized email, got %q", m.Email)
+	}
+	if m.Code != "AB-12" {
+		t.Fatalf("expected AB-12, got %q", m.Code)
+	}
+	if m.Name != "Alice Van Dyke" {
+		t.Fatalf("expected title-cased name, got %q", m.Name)
+	}
+}
+
+func TestApplyNormalizers_PointerAndSlice(t *testing.T) {
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "Nick", BSONName: "nick", Normalize: []string{"trim"}},
+			{Name: "Tags", BSONName: "tags", Normalize: []string{"lower"}},
+		},
+	}
+
+	type model struct {
+		Nick *string
+		Tags []string
+	}
+
+	nick := "  bob "
+	m := &model{Nick: &nick, Tags: []string{"Go", "MONGO"}}
+	if err := applyNormalizers(m, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *m.Nick != "bob" {
+		t.Fatalf("expected bob, got %q", *m.Nick)
+	}
+	if m.Tags[0] != "go" || m.Tags[1] != "mongo" {
+		t.Fatalf("expected lowercased tags, got %v", m.Tags)
+	}
+
+	// Nil pointer is left alone
+	m = &model{}
+	if err := applyNormalizers(m, schema); err != nil {
+		t.Fatalf("unexpected error for nil pointer: %v", err)
+	}
+}
+
+func TestApplyNormalizers_Subdocument(t *testing.T) {
+	type inner struct {
+		City string
+	}
+	type model struct {
+		Address inner
+	}
+
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "Address", BSONName: "address", SubFields: []FieldSchema{
+				{Name: "City", BSONName: "city", Normalize: []string{"trim", "title"}},
+			}},
+		},
+	}
+
+	m := &model{Address: inner{City: " new york "}}
+	if err := applyNormalizers(m, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Address.City != "New York" {
+		t.Fatalf("expected New York, got %q", m.Address.City)
+	}
+}
+
+func TestApplyNormalizers_Custom(t *testing.T) {
+	RegisterNormalizer("test_dashless", func(s string) string {
+		return strings.ReplaceAll(s, "-", "")
+	})
+	defer func() {
+		normalizerMu.Lock()
+		delete(normalizers, "test_dashless")
+		normalizerMu.Unlock()
+	}()
+
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "Phone", BSONName: "phone", Normalize: []string{"test_dashless"}},
+		},
+	}
+
+	type model struct {
+		Phone string
+	}
+
+	m := &model{Phone: "555-123-4567"}
+	if err := applyNormalizers(m, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Phone != "5551234567" {
+		t.Fatalf("expected 5551234567, got %q", m.Phone)
+	}
+}
+
+func TestApplyNormalizers_Unknown(t *testing.T) {
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "Name", BSONName: "name", Normalize: []string{"nope"}},
+		},
+	}
+
+	type model struct {
+		Name string
+	}
+
+	if err := applyNormalizers(&model{Name: "x"}, schema); err == nil {
+		t.Fatal("expected error for unknown normalizer")
+	}
+}
+
+func TestParseGoodmTag_Normalize(t *testing.T) {
+	fs := ParseGoodmTag("required,normalize=trim|lower")
+	if !fs.Required {
+		t.Fatal("expected required")
+	}
+	if len(fs.Normalize) != 2 || fs.Normalize[0] != "trim" || fs.Normalize[1] != "lower" {
+		t.Fatalf("unexpected normalizers: %v", fs.Normalize)
+	}
+}
+
+func TestApplyNormalizers_Time(t *testing.T) {
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "At", BSONName: "at", Normalize: []string{"utc"}},
+			{Name: "Due", BSONName: "due", Normalize: []string{"day"}},
+			{Name: "Dates", BSONName: "dates", DateOnly: true},
+		},
+	}
+
+	type model struct {
+		At    time.Time
+		Due   *time.Time
+		Dates []time.Time
+	}
+
+	oslo := time.FixedZone("Oslo", 2*3600)
+	late := time.Date(2024, 3, 5, 23, 30, 0, 0, oslo)
+	due := late
+	m := &model{At: late, Due: &due, Dates: []time.Time{late}}
+	if err := applyNormalizers(m, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.At.Location() != time.UTC || !m.At.Equal(late) {
+		t.Fatalf("expected the same instant in UTC, got %v", m.At)
+	}
+	want := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	if !m.Due.Equal(want) || !m.Dates[0].Equal(want) {
+		t.Fatalf("expected the local calendar date at midnight UTC, got %v, %v", m.Due, m.Dates[0])
+	}
+}
+
+func TestApplyNormalizers_UnknownTimeNormalizer(t *testing.T) {
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "At", BSONName: "at", Normalize: []string{"lower"}},
+		},
+	}
+
+	type model struct {
+		At time.Time
+	}
+
+	if err := applyNormalizers(&model{At: time.Now()}, schema); err == nil || !strings.Contains(err.Error(), `unknown time normalizer "lower"`) {
+		t.Fatalf("expected unknown time normalizer error, got %v", err)
+	}
+}
+
+type testBadNormalizer struct {
+	Model `bson:",inline"`
+	Name  string    `bson:"name" goodm:"normalize=trim|lowr"`
+	At    time.Time `bson:"at"   goodm:"normalize=utc"`
+}
+
+type testBadTimeNormalizer struct {
+	Model `bson:",inline"`
+	At    time.Time `bson:"at" goodm:"normalize=trim"`
+}
+
+func TestRegister_UnknownNormalizer(t *testing.T) {
+	err := Register(&testBadNormalizer{}, "test_bad_normalizer")
+	if err == nil || !strings.Contains(err.Error(), `unknown normalizer "lowr"`) {
+		t.Fatalf("expected unknown normalizer error, got %v", err)
+	}
+	err = Register(&testBadTimeNormalizer{}, "test_bad_time_normalizer")
+	if err == nil || !strings.Contains(err.Error(), `unknown time normalizer "trim"`) {
+		t.Fatalf("expected unknown time normalizer error, got %v", err)
+	}
+}
+
+func TestNormalizeUpdates(t *testing.T) {
+	schema := &Schema{
+		Fields: []FieldSchema{
+			{Name: "Email", BSONName: "email", Type: "string", Normalize: []string{"trim", "lower"}},
+			{Name: "Nick", BSONName: "nick", Type: "*string", Normalize: []string{"trim"}},
+			{Name: "Tags", BSONName: "tags", Type: "[]string", Normalize: []string{"lower"}},
+			{Name: "At", BSONName: "at", Type: "time.Time", Normalize: []string{"utc"}},
+		},
+	}
+
+	nick := "  bob "
+	tags := []string{"Go", "MONGO"}
+	at := time.Date(2024, 7, 1, 9, 0, 0, 0, time.FixedZone("X", 3600))
+	fields := bson.M{"email": "  Alice@Example.COM ", "nick": &nick, "tags": tags, "at": at, "other": " kept "}
+	if err := normalizeUpdates(schema, fields); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fields["email"] != "alice@example.com" || *fields["nick"].(*string) != "bob" {
+		t.Errorf("expected normalized strings, got %v and %q", fields["email"], *fields["nick"].(*string))
+	}
+	if got := fields["tags"].([]string); got[0] != "go" || got[1] != "mongo" {
+		t.Errorf("expected lowercased tags, got %v", got)
+	}
+	if got := fields["at"].(time.Time); got.Location() != time.UTC || !got.Equal(at) {
+		t.Errorf("expected the time in UTC, got %v", got)
+	}
+	if fields["other"] != " kept " {
+		t.Errorf("expected an untagged field to be kept, got %q", fields["other"])
+	}
+	if nick != "  bob " || tags[0] != "Go" {
+		t.Errorf("expected the caller's values to be left alone, got %q and %v", nick, tags)
+	}
+
+	// A value of the wrong type is left for validation.
+	fields = bson.M{"email": 42, "at": "2024-07-01"}
+	if err := normalizeUpdates(schema, fields); err != nil || fields["email"] != 42 || fields["at"] != "2024-07-01" {
+		t.Errorf("expected mismatched values to be left alone, got %v, %v", fields, err)
+	}
+}
+
+type testContact struct {
+	Model `bson:",inline"`
+	Email string `bson:"email" goodm:"normalize=trim|lower"`
+}
+
+func TestUpdateFields_Normalize(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testContact{}, "test_contacts"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testContact")
+		registryMu.Unlock()
+	})
+
+	c := &testContact{Email: "a@test.com"}
+	if err := Create(ctx, c); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := UpdateFields(ctx, c, bson.M{"email": "  B@Test.COM "}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	found := &testContact{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: c.ID}}, found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Email != "b@test.com" {
+		t.Errorf("expected the normalized email, got %q", found.Email)
+	}
+}
@@ -0,0 +1,132 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+)
+
+// DefaultNPlusOneThreshold is the number of FindOne calls with the same
+// filter shape in one request scope after which DetectNPlusOne reports them.
+const DefaultNPlusOneThreshold = 5
+
+// NPlusOne reports FindOne calls repeated with the same filter shape inside
+// one request scope, the signature of an N+1 query pattern.
+type NPlusOne struct {
+	ModelName string
+	Shape     FilterShape
+	Count     int    // FindOne calls with this shape so far in the scope
+	Caller    string // file:line of the application code making the call that crossed the threshold
+}
+
+func (n NPlusOne) String() string {
+	s := fmt.Sprintf("goodm: possible N+1 query: %d FindOne calls on %s by %v in one request", n.Count, n.ModelName, n.Shape.Equality)
+	if n.Caller != "" {
+		s += " (" + n.Caller + ")"
+	}
+	return s + "; load them with one Find using $in, or BatchPopulate"
+}
+
+// NPlusOneOptions configures DetectNPlusOne.
+type NPlusOneOptions struct {
+	// Threshold is the number of FindOne calls with the same shape in one
+	// request scope that triggers a report. Defaults to
+	// DefaultNPlusOneThreshold.
+	Threshold int
+
+	// OnDetect receives each report. Defaults to logging it with the
+	// standard logger.
+	OnDetect func(NPlusOne)
+}
+
+// requestScope counts FindOne calls by model and filter shape for one
+// request.
+type requestScope struct {
+	mu       sync.Mutex
+	counts   map[string]int
+	reported map[string]bool
+}
+
+type requestScopeKey struct{}
+
+// WithRequestScope returns a context marking the boundary of one request for
+// DetectNPlusOne. FindOne calls are only counted under a scope; wrap the
+// context of each incoming request, typically in HTTP middleware.
+func WithRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestScopeKey{}, &requestScope{
+		counts:   make(map[string]int),
+		reported: make(map[string]bool),
+	})
+}
+
+// DetectNPlusOne returns a MiddlewareFunc for development that watches for
+// FindOne calls repeated with the same filter shape inside one request scope
+// (see WithRequestScope), such as loading the author of each post in a loop,
+// and reports each shape once per scope when its count reaches the
+// threshold. Only the shape of a filter is compared, not its values, so
+// FindOne by _id for ten different posts counts as ten calls. Operations are
+// never blocked.
+//
+// Example:
+//
+//	goodm.Use(goodm.DetectNPlusOne())
+//
+//	http.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	    ctx := goodm.WithRequestScope(r.Context())
+//	    // ...
+//	}))
+//	// goodm: possible N+1 query: 5 FindOne calls on User by [_id] in one request (handlers/posts.go:42); ...
+func DetectNPlusOne(opts ...NPlusOneOptions) MiddlewareFunc {
+	var opt NPlusOneOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Threshold <= 0 {
+		opt.Threshold = DefaultNPlusOneThreshold
+	}
+	if opt.OnDetect == nil {
+		opt.OnDetect = func(n NPlusOne) { log.Print(n.String()) }
+	}
+
+	return func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		scope, ok := ctx.Value(requestScopeKey{}).(*requestScope)
+		if !ok || !isFindOne(op) {
+			return next(ctx)
+		}
+		shape, ok := filterShape(op.Filter)
+		if !ok {
+			return next(ctx)
+		}
+		key := op.ModelName + "#" + shape.key()
+
+		scope.mu.Lock()
+		scope.counts[key]++
+		count := scope.counts[key]
+		report := count >= opt.Threshold && !scope.reported[key]
+		if report {
+			scope.reported[key] = true
+		}
+		scope.mu.Unlock()
+
+		if report {
+			_, caller := callSite()
+			opt.OnDetect(NPlusOne{ModelName: op.ModelName, Shape: shape, Count: count, Caller: caller})
+		}
+		return next(ctx)
+	}
+}
+
+// isFindOne reports whether op is a FindOne call: a find decoding into a
+// single document rather than a slice.
+func isFindOne(op *OpInfo) bool {
+	if op.Operation != OpFind || op.Result == nil {
+		return false
+	}
+	t := reflect.TypeOf(op.Result)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() != reflect.Slice
+}
@@ -0,0 +1,66 @@
+package goodm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestDetectNPlusOne(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+
+	var reports []NPlusOne
+	Use(DetectNPlusOne(NPlusOneOptions{Threshold: 3, OnDetect: func(n NPlusOne) {
+		reports = append(reports, n)
+	}}))
+
+	findEach := func(ctx context.Context, n int) {
+		for i := 0; i < n; i++ {
+			var u testUser
+			_ = FindOne(ctx, bson.D{{Key: "email", Value: i}}, &u)
+		}
+	}
+
+	findEach(ctx, 5)
+	if len(reports) != 0 {
+		t.Fatalf("expected no reports outside a request scope, got %+v", reports)
+	}
+
+	scoped := WithRequestScope(ctx)
+	for i := 0; i < 5; i++ {
+		var users []testUser
+		_ = Find(scoped, bson.D{{Key: "email", Value: i}}, &users)
+	}
+	findEach(scoped, 2)
+	if len(reports) != 0 {
+		t.Fatalf("expected no report below the threshold, got %+v", reports)
+	}
+	findEach(scoped, 4)
+	if len(reports) != 1 {
+		t.Fatalf("expected one report per shape, got %+v", reports)
+	}
+	r := reports[0]
+	if r.ModelName != "testUser" || r.Count != 3 || len(r.Shape.Equality) != 1 || r.Shape.Equality[0] != "email" {
+		t.Errorf("unexpected report: %+v", r)
+	}
+	if !strings.Contains(r.Caller, "nplusone_test.go:") {
+		t.Errorf("expected the caller to be the test, got %q", r.Caller)
+	}
+
+	findEach(WithRequestScope(ctx), 3)
+	if len(reports) != 2 {
+		t.Errorf("expected a new scope to report again, got %d reports", len(reports))
+	}
+}
+
+func TestNPlusOne_String(t *testing.T) {
+	n := NPlusOne{ModelName: "User", Shape: FilterShape{Equality: []string{"_id"}}, Count: 5, Caller: "handlers/posts.go:42"}
+	want := "goodm: possible N+1 query: 5 FindOne calls on User by [_id] in one request (handlers/posts.go:42); load them with one Find using $in, or BatchPopulate"
+	if n.String() != want {
+		t.Errorf("got %q", n.String())
+	}
+}
@@ -0,0 +1,65 @@
+package goodm
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// HookContext describes the operation a hook or middleware runs in.
+type HookContext struct {
+	Op *OpInfo // the operation, as middleware sees it
+
+	// DB is the database the operation runs against: its DB option, or the
+	// global database. It is nil on the in-memory test store, and in
+	// middleware until it calls next.
+	DB *mongo.Database
+
+	// InTransaction reports whether the operation runs inside
+	// WithTransaction. Writes made with the hook's ctx then join the
+	// transaction, and commit or abort with the operation.
+	InTransaction bool
+}
+
+type hookContextKey struct{}
+
+// txKey marks a context whose session runs a transaction started by
+// WithTransaction.
+type txKey struct{}
+
+// OpFromContext returns the operation that ctx, as passed to a hook or to
+// middleware, belongs to. ok is false outside a goodm operation.
+//
+// Hooks run inside the middleware chain and inside the operation's session,
+// so a hook can make related writes with its ctx and have them share the
+// operation's transaction and database.
+//
+// Example:
+//
+//	func (o *Order) AfterCreate(ctx context.Context) error {
+//	    hc, _ := goodm.OpFromContext(ctx)
+//	    if !hc.InTransaction {
+//	        return errors.New("orders must be created inside a transaction")
+//	    }
+//	    return goodm.Create(ctx, &OrderEvent{OrderID: o.ID}, goodm.CreateOptions{DB: hc.DB})
+//	}
+func OpFromContext(ctx context.Context) (HookContext, bool) {
+	hc, ok := ctx.Value(hookContextKey{}).(*HookContext)
+	if !ok {
+		return HookContext{}, false
+	}
+	return *hc, true
+}
+
+// withHookContext returns ctx carrying a HookContext for info.
+func withHookContext(ctx context.Context, info *OpInfo) context.Context {
+	return context.WithValue(ctx, hookContextKey{}, &HookContext{Op: info, InTransaction: InTransaction(ctx)})
+}
+
+// bindOpDB records the database the operation of ctx resolved, for
+// OpFromContext.
+func bindOpDB(ctx context.Context, db dbHandle) {
+	if hc, ok := ctx.Value(hookContextKey{}).(*HookContext); ok {
+		hc.DB = db.Database
+	}
+}
@@ -0,0 +1,116 @@
+package goodm
+
+import (
+	"context"
+	"testing"
+)
+
+type testHookedOrder struct {
+	Model  `bson:",inline"`
+	Number string `bson:"number"`
+
+	seen []HookContext
+}
+
+func (o *testHookedOrder) BeforeCreate(ctx context.Context) error {
+	hc, _ := OpFromContext(ctx)
+	o.seen = append(o.seen, hc)
+	return nil
+}
+
+func (o *testHookedOrder) AfterCreate(ctx context.Context) error {
+	hc, ok := OpFromContext(ctx)
+	if ok {
+		o.seen = append(o.seen, hc)
+	}
+	// A related write from the hook runs as its own operation.
+	return Create(ctx, &testHookedEvent{Order: o.Number})
+}
+
+type testHookedEvent struct {
+	Model `bson:",inline"`
+	Order string `bson:"order"`
+}
+
+func registerHookedOrders(t *testing.T) {
+	t.Helper()
+	if err := Register(&testHookedOrder{}, "test_hooked_orders"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := Register(&testHookedEvent{}, "test_hooked_events"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testHookedOrder")
+		delete(registry, "testHookedEvent")
+		registryMu.Unlock()
+	})
+}
+
+func TestOpFromContext_Hooks(t *testing.T) {
+	ctx := useTestStore(t)
+	registerHookedOrders(t)
+
+	if _, ok := OpFromContext(ctx); ok {
+		t.Fatal("OpFromContext reported an operation outside one")
+	}
+
+	o := &testHookedOrder{Number: "A-1"}
+	if err := Create(ctx, o); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(o.seen) != 2 {
+		t.Fatalf("expected both hooks to see the operation, got %+v", o.seen)
+	}
+	for _, hc := range o.seen {
+		if hc.Op == nil || hc.Op.Operation != OpCreate || hc.Op.ModelName != "testHookedOrder" || hc.Op.Model != o {
+			t.Errorf("unexpected operation: %+v", hc.Op)
+		}
+		if hc.InTransaction {
+			t.Error("InTransaction set outside a transaction")
+		}
+	}
+
+	var events []testHookedEvent
+	if err := Find(ctx, nil, &events); err != nil || len(events) != 1 {
+		t.Fatalf("expected the hook's event to be written, got %d (%v)", len(events), err)
+	}
+}
+
+func TestOpFromContext_Transaction(t *testing.T) {
+	ctx := useTestStore(t)
+	registerHookedOrders(t)
+
+	o := &testHookedOrder{Number: "A-2"}
+	err := WithTransaction(ctx, func(ctx context.Context) error {
+		return Create(ctx, o)
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if len(o.seen) != 2 || !o.seen[0].InTransaction || !o.seen[1].InTransaction {
+		t.Fatalf("expected hooks to report the transaction, got %+v", o.seen)
+	}
+}
+
+func TestOpFromContext_Middleware(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+
+	var before *OpInfo
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		hc, ok := OpFromContext(ctx)
+		if ok {
+			before = hc.Op
+		}
+		return next(ctx)
+	})
+	if err := Create(ctx, &testUser{Email: "hc@test.com", Name: "HC"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if before == nil || before.ModelName != "testUser" {
+		t.Fatalf("middleware did not see the operation: %+v", before)
+	}
+}
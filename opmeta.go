@@ -0,0 +1,53 @@
+package goodm
+
+import "context"
+
+// Standard operation metadata keys, so that middleware from different
+// sources agrees on where to find common request attributes.
+const (
+	MetaRequestID = "request_id" // ID of the request the operation serves
+	MetaUserID    = "user_id"    // user on whose behalf the operation runs
+	MetaTenantID  = "tenant_id"  // tenant the operation is scoped to
+)
+
+type opMetaKey struct{}
+
+// WithOpMeta returns a context carrying val under key as operation metadata.
+// Every goodm operation run with the context exposes its metadata to
+// middleware as OpInfo.Meta, so audit, logging, and tracing middleware share
+// one place to read request attributes instead of each defining its own
+// context keys. Setting a key again overrides it for the derived context
+// only.
+//
+// Example:
+//
+//	ctx = goodm.WithOpMeta(ctx, goodm.MetaRequestID, r.Header.Get("X-Request-ID"))
+//	ctx = goodm.WithOpMeta(ctx, "flag.new_pricing", true)
+//
+//	goodm.Use(func(ctx context.Context, op *goodm.OpInfo, next func(context.Context) error) error {
+//	    log.Printf("%s %s request=%v", op.Operation, op.ModelName, op.Meta[goodm.MetaRequestID])
+//	    return next(ctx)
+//	})
+func WithOpMeta(ctx context.Context, key string, val interface{}) context.Context {
+	parent, _ := ctx.Value(opMetaKey{}).(map[string]interface{})
+	meta := make(map[string]interface{}, len(parent)+1)
+	for k, v := range parent {
+		meta[k] = v
+	}
+	meta[key] = val
+	return context.WithValue(ctx, opMetaKey{}, meta)
+}
+
+// OpMetaFromContext returns a copy of the operation metadata set on ctx with
+// WithOpMeta, or nil if there is none.
+func OpMetaFromContext(ctx context.Context) map[string]interface{} {
+	meta, _ := ctx.Value(opMetaKey{}).(map[string]interface{})
+	if meta == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(meta))
+	for k, v := range meta {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package goodm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithOpMeta(t *testing.T) {
+	ctx := WithOpMeta(context.Background(), MetaRequestID, "req-1")
+	child := WithOpMeta(ctx, MetaUserID, "u-1")
+	child = WithOpMeta(child, MetaRequestID, "req-2")
+
+	if got := OpMetaFromContext(ctx); len(got) != 1 || got[MetaRequestID] != "req-1" {
+		t.Errorf("parent meta changed: %v", got)
+	}
+	got := OpMetaFromContext(child)
+	if got[MetaRequestID] != "req-2" || got[MetaUserID] != "u-1" {
+		t.Errorf("unexpected child meta: %v", got)
+	}
+
+	got["extra"] = true
+	if _, ok := OpMetaFromContext(child)["extra"]; ok {
+		t.Error("OpMetaFromContext returned the context's own map")
+	}
+	if OpMetaFromContext(context.Background()) != nil {
+		t.Error("expected nil meta for a bare context")
+	}
+}
+
+func TestRunMiddleware_OpMeta(t *testing.T) {
+	ClearMiddleware()
+	defer ClearMiddleware()
+
+	var seen map[string]interface{}
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		seen = op.Meta
+		return next(ctx)
+	})
+
+	ctx := WithOpMeta(context.Background(), MetaRequestID, "req-1")
+	err := runMiddleware(ctx, &OpInfo{Operation: OpFind, ModelName: "Foo"}, func(ctx context.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen[MetaRequestID] != "req-1" {
+		t.Errorf("Meta = %v, want request_id req-1", seen)
+	}
+}
+
+func TestOpMeta_Create(t *testing.T) {
+	useTestStore(t)
+	ClearMiddleware()
+	defer ClearMiddleware()
+
+	var seen map[string]interface{}
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		seen = op.Meta
+		return next(ctx)
+	})
+
+	ctx := WithOpMeta(context.Background(), MetaUserID, "u-7")
+	if err := Create(ctx, &testUser{Email: "meta@test.com", Name: "Meta"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if seen[MetaUserID] != "u-7" {
+		t.Errorf("Meta = %v, want user_id u-7", seen)
+	}
+}
@@ -0,0 +1,79 @@
+package goodm
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// newOpID returns a new operation ID: the hex form of a fresh ObjectID, which
+// is unique across processes and sorts by start time.
+func newOpID() string {
+	return bson.NewObjectID().Hex()
+}
+
+// OpIDFromContext returns the ID of the goodm operation ctx belongs to, as
+// passed to a hook or to middleware, or "" outside an operation. It equals
+// OpInfo.ID, so hook logs can be correlated with the operation's middleware
+// and query logs.
+//
+// Example:
+//
+//	func (u *User) BeforeSave(ctx context.Context) error {
+//	    log.Printf("op=%s normalizing %s", goodm.OpIDFromContext(ctx), u.Email)
+//	    return nil
+//	}
+func OpIDFromContext(ctx context.Context) string {
+	hc, ok := ctx.Value(hookContextKey{}).(*HookContext)
+	if !ok {
+		return ""
+	}
+	return hc.Op.ID
+}
+
+// LoggingMiddleware returns middleware that logs every operation once it
+// completes, through logf (e.g. log.Printf or t.Logf), as key=value pairs:
+//
+//	op=65f1c0e2a4b9d3e1f0a1b2c3 parent=65f1c0e2a4b9d3e1f0a1b2c2 model=User operation=update collection=users duration=1.3ms error="goodm: document not found"
+//
+// parent is set for operations started from another operation's hooks or
+// middleware, slow=true for operations at or above Config.SlowQueryThreshold,
+// and error for operations that fail. Config.LogLevel selects the operations
+// logged, and is read on every operation, so Configure changes it at runtime.
+//
+// Example:
+//
+//	goodm.Use(goodm.LoggingMiddleware(log.Printf))
+func LoggingMiddleware(logf func(format string, args ...interface{})) MiddlewareFunc {
+	return func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+		d := time.Since(start)
+
+		cfg := CurrentConfig()
+		slow := cfg.SlowQueryThreshold > 0 && d >= cfg.SlowQueryThreshold
+		switch {
+		case cfg.LogLevel >= LogOff,
+			cfg.LogLevel == LogErrors && err == nil,
+			cfg.LogLevel == LogSlow && err == nil && !slow:
+			return err
+		}
+
+		line := "op=" + op.ID
+		if op.ParentID != "" {
+			line += " parent=" + op.ParentID
+		}
+		line += " model=" + op.ModelName + " operation=" + string(op.Operation) +
+			" collection=" + op.Collection + " duration=" + d.String()
+		if slow {
+			line += " slow=true"
+		}
+		if err != nil {
+			line += " error=" + strconv.Quote(err.Error())
+		}
+		logf("%s", line)
+		return err
+	}
+}
@@ -0,0 +1,87 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testTracedOrder struct {
+	Model  `bson:",inline"`
+	Item   string `bson:"item"`
+	hookOp string
+}
+
+func (o *testTracedOrder) AfterCreate(ctx context.Context) error {
+	o.hookOp = OpIDFromContext(ctx)
+	return Create(ctx, &testProfile{Bio: "order " + o.Item})
+}
+
+func TestOpIDs_CorrelateHooksAndNestedOperations(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testTracedOrder{}, "test_traced_orders"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testTracedOrder")
+		registryMu.Unlock()
+	})
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+
+	var ops []*OpInfo
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		if OpIDFromContext(ctx) != op.ID {
+			t.Errorf("context op ID %q differs from OpInfo.ID %q", OpIDFromContext(ctx), op.ID)
+		}
+		ops = append(ops, op)
+		return next(ctx)
+	})
+	var lines []string
+	Use(LoggingMiddleware(func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}))
+
+	order := &testTracedOrder{Item: "book"}
+	if err := Create(ctx, order); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("expected the order and the nested profile create, got %d operations", len(ops))
+	}
+	outer, inner := ops[0], ops[1]
+	if outer.ID == "" || inner.ID == "" || outer.ID == inner.ID {
+		t.Fatalf("expected distinct operation IDs, got %q and %q", outer.ID, inner.ID)
+	}
+	if order.hookOp != outer.ID {
+		t.Errorf("expected the hook to see op %q, got %q", outer.ID, order.hookOp)
+	}
+	if outer.ParentID != "" || inner.ParentID != outer.ID {
+		t.Errorf("expected the nested create to have parent %q, got parents %q and %q", outer.ID, outer.ParentID, inner.ParentID)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %q", lines)
+	}
+	if want := "op=" + inner.ID + " parent=" + outer.ID + " model=testProfile operation=create"; !strings.HasPrefix(lines[0], want) {
+		t.Errorf("expected the nested line to start with %q, got %q", want, lines[0])
+	}
+	if want := "op=" + outer.ID + " model=testTracedOrder"; !strings.HasPrefix(lines[1], want) {
+		t.Errorf("expected the outer line to start with %q, got %q", want, lines[1])
+	}
+
+	lines = nil
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: bson.NewObjectID()}}, &testTracedOrder{}); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(lines) != 1 || !strings.Contains(lines[0], `error="goodm: document not found"`) {
+		t.Errorf("expected the failed find to be logged with its error, got %q", lines)
+	}
+	if OpIDFromContext(ctx) != "" {
+		t.Error("expected no op ID outside an operation")
+	}
+}
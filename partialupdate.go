@@ -0,0 +1,179 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// PartialUpdate writes only the named fields of model, by bson name, with a
+// single $set, leaving the rest of the stored document untouched. Unlike
+// Update, which replaces the whole document, it does not clobber concurrent
+// changes to other fields.
+//
+// BeforeSave and AfterSave run as in Update. Normalizers, validation, and
+// the immutable, write-once, and transition checks apply to the named
+// fields only. UpdatedAt is set and Version incremented, but the stored
+// version is not checked unless WithVersionCheck is passed: the named fields
+// are last-write-wins, as in UpdateFields. Nil values and zero values of
+// omitempty fields are removed from the document ($unset). Of the
+// UpdateOptions, only DB and CheckVersion apply.
+//
+// After the write, the named fields count as saved for ChangedFields; the
+// model's other changes are still reported.
+//
+// Example:
+//
+//	user.Age = 31
+//	user.Role = "admin"
+//	err := goodm.PartialUpdate(ctx, user, []string{"age", "role"})
+func PartialUpdate(ctx context.Context, model interface{}, fields []string, opts ...UpdateOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+
+	id, err := getModelID(model)
+	if err != nil {
+		return err
+	}
+	if id.IsZero() {
+		return fmt.Errorf("goodm: cannot update document with zero ID")
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("goodm: PartialUpdate requires at least one field")
+	}
+	partial, err := partialSchema(schema, fields)
+	if err != nil {
+		return err
+	}
+	var opt UpdateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpUpdate, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: model,
+		Filter: bson.D{{Key: "_id", Value: id}}, filterable: true,
+		Options: opt,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		coll := getCollection(db, schema)
+
+		if err := checkPolicyScope(ctx, coll, schema, id, OpUpdate); err != nil {
+			return err
+		}
+		if err := checkImmutableFields(ctx, coll, id, model, partial); err != nil {
+			return err
+		}
+		stampActor(ctx, model, false)
+
+		// BeforeSave hook
+		if hook, ok := model.(BeforeSave); ok {
+			if err := hook.BeforeSave(ctx); err != nil {
+				return err
+			}
+		}
+
+		// Normalize and validate the named fields
+		if err := applyNormalizers(model, partial); err != nil {
+			return err
+		}
+		if errs := Validate(model, partial); len(errs) > 0 {
+			return ValidationErrors(errs)
+		}
+
+		set, err := partialFields(model, partial)
+		if err != nil {
+			return err
+		}
+		updatedAt := schema.base().updatedAt
+		set[updatedAt] = clockNow()
+		if name, actor, ok := actorField(ctx, model); ok {
+			set[name] = actor
+		}
+
+		version, _ := getModelVersion(model)
+		filter := bson.D{{Key: "_id", Value: id}}
+		if opt.CheckVersion {
+			filter = buildVersionFilter(id, schema.base().version, version)
+		}
+		result, err := coll.UpdateOne(ctx, filter, fieldsUpdate(schema, set), withComment(ctx, options.UpdateOne()))
+		if err != nil {
+			if verrs := uniqueWithErrors(schema, err); verrs != nil {
+				return verrs
+			}
+			return fmt.Errorf("goodm: partial update failed: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			if opt.CheckVersion {
+				return checkUpdateConflict(ctx, coll, id)
+			}
+			return ErrNotFound
+		}
+
+		setUpdatedAt(model, set[updatedAt].(time.Time))
+		setModelVersion(model, version+1)
+		written := []string{schema.base().version}
+		for name := range set {
+			written = append(written, name)
+		}
+		trackWritten(model, written)
+
+		// AfterSave hook
+		if hook, ok := model.(AfterSave); ok {
+			if err := hook.AfterSave(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// partialSchema returns a copy of schema holding only the named fields, so
+// that normalizers, validation, and the immutable checks see nothing else.
+// Unknown and managed field names are rejected.
+func partialSchema(schema *Schema, names []string) (*Schema, error) {
+	partial := *schema
+	partial.Fields = nil
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if schema.base().managed(name) {
+			return nil, fmt.Errorf("goodm: cannot set managed field %q via PartialUpdate", name)
+		}
+		f := schema.GetField(name)
+		if f == nil {
+			return nil, fmt.Errorf("goodm: unknown field %q in %s", name, schema.ModelName)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		partial.Fields = append(partial.Fields, *f)
+	}
+	return &partial, nil
+}
+
+// partialFields returns the values of the fields of partial held by model,
+// keyed by bson name. Fields the encoder leaves out, zero values of
+// omitempty fields, are returned as nil so that they are unset.
+func partialFields(model interface{}, partial *Schema) (bson.M, error) {
+	doc, err := toBsonMap(model)
+	if err != nil {
+		return nil, err
+	}
+	set := make(bson.M, len(partial.Fields)+2)
+	for _, f := range partial.Fields {
+		set[f.BSONName] = doc[f.BSONName]
+	}
+	return set, nil
+}
@@ -0,0 +1,135 @@
+package goodm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestPartialUpdate(t *testing.T) {
+	ctx := useTestStore(t)
+
+	u := &testUser{Email: "partial@test.com", Name: "Partial", Age: 30}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// A concurrent writer changes a field the partial update does not name.
+	if err := UpdateFields(ctx, &testUser{Model: Model{ID: u.ID}}, bson.M{"email": "moved@test.com"}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+
+	u.Age = 31
+	u.Role = "admin"
+	u.Email = "stale@test.com"
+	if err := PartialUpdate(ctx, u, []string{"age", "role"}); err != nil {
+		t.Fatalf("partial update: %v", err)
+	}
+	if u.Version != 1 {
+		t.Errorf("expected version 1 on the model, got %d", u.Version)
+	}
+	var found testUser
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Age != 31 || found.Role != "admin" {
+		t.Errorf("expected the named fields to be written, got %+v", found)
+	}
+	if found.Email != "moved@test.com" {
+		t.Errorf("expected the concurrent change to survive, got %q", found.Email)
+	}
+	if found.Version != 2 || !found.UpdatedAt.Equal(u.UpdatedAt.Truncate(time.Millisecond)) {
+		t.Errorf("expected version 2 and the model's UpdatedAt, got %d and %v", found.Version, found.UpdatedAt)
+	}
+
+	// Only the named fields are validated.
+	u.Age = -1
+	var verrs ValidationErrors
+	if err := PartialUpdate(ctx, u, []string{"age"}); !errors.As(err, &verrs) || verrs[0].Field != "age" {
+		t.Errorf("expected a validation error on age, got %v", err)
+	}
+	u.Age = 32
+	u.Email = ""
+	if err := PartialUpdate(ctx, u, []string{"age"}); err != nil {
+		t.Errorf("expected an unnamed invalid field to be ignored, got %v", err)
+	}
+
+	u.Name = "Renamed"
+	if err := PartialUpdate(ctx, u, []string{"name"}); !errors.As(err, &verrs) {
+		t.Errorf("expected the immutable name to be rejected, got %v", err)
+	}
+	if err := PartialUpdate(ctx, u, []string{"__v"}); err == nil {
+		t.Error("expected a managed field to be rejected")
+	}
+	if err := PartialUpdate(ctx, u, []string{"nickname"}); err == nil {
+		t.Error("expected an unknown field to be rejected")
+	}
+	if err := PartialUpdate(ctx, u, nil); err == nil {
+		t.Error("expected PartialUpdate without fields to fail")
+	}
+}
+
+func TestPartialUpdateHooks(t *testing.T) {
+	ctx := useTestStore(t)
+	registerTestJob(t)
+
+	j := &testJob{Status: "queued"}
+	if err := Create(ctx, j); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	j.Events = nil
+	j.Status = "done"
+	if err := PartialUpdate(ctx, j, []string{"status"}); err != nil {
+		t.Fatalf("partial update: %v", err)
+	}
+	if len(j.Events) != 2 || j.Events[0] != "before_save" || j.Events[1] != "after_save:done" {
+		t.Errorf("expected save hooks, got %v", j.Events)
+	}
+
+	if err := PartialUpdate(ctx, &testJob{Model: Model{ID: bson.NewObjectID()}}, []string{"status"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestPartialUpdateOptions(t *testing.T) {
+	ctx := useTestStore(t)
+
+	u := &testUser{Email: "checked@test.com", Name: "Checked", Age: 30}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	stale := *u
+	u.Age = 31
+	if err := PartialUpdate(ctx, u, []string{"age"}, WithVersionCheck()); err != nil {
+		t.Fatalf("partial update: %v", err)
+	}
+	stale.Age = 40
+	if err := PartialUpdate(ctx, &stale, []string{"age"}, WithVersionCheck()); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected a stale version to conflict, got %v", err)
+	}
+	if err := PartialUpdate(ctx, &stale, []string{"age"}); err != nil {
+		t.Errorf("expected the unchecked write to win, got %v", err)
+	}
+	if err := PartialUpdate(ctx, u, []string{"age"}, UpdateOptions{CheckVersion: true}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected the overwritten version to conflict, got %v", err)
+	}
+
+	// The written fields count as saved; other changes are still reported.
+	var found testUser
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	found.Age = 50
+	found.Role = "admin"
+	if err := PartialUpdate(ctx, &found, []string{"age"}); err != nil {
+		t.Fatalf("partial update: %v", err)
+	}
+	if changed := ChangedFields(&found); len(changed) != 1 || changed[0] != "role" {
+		t.Errorf("expected only role to be unsaved, got %v", changed)
+	}
+	if prev, ok := Original(&found).(*testUser); !ok || prev.Age != 50 || prev.Version != found.Version {
+		t.Errorf("expected the original to hold the written age and version, got %+v", prev)
+	}
+}
@@ -3,9 +3,12 @@ package goodm
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // PipelineOptions configures a Pipeline.
@@ -29,6 +32,7 @@ type Pipeline struct {
 	model  interface{}
 	stages []bson.D
 	db     *mongo.Database
+	err    error // first error from a schema-derived stage, returned by Execute and Cursor
 }
 
 // NewPipeline creates a new aggregation pipeline builder bound to the given model.
@@ -47,6 +51,33 @@ func (p *Pipeline) Match(filter interface{}) *Pipeline {
 	return p
 }
 
+// MatchFields adds a $match stage requiring each field to equal its value.
+// Keys are bson names of the model's fields, or dotted paths into its
+// subdocuments; an unknown field fails Execute and Cursor. Values may be
+// operator documents such as bson.D{{Key: "$gte", Value: 21}}.
+func (p *Pipeline) MatchFields(fields map[string]interface{}) *Pipeline {
+	schema := p.schema()
+	if schema == nil {
+		return p
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if !hasFieldPath(schema.Fields, name) {
+			p.fail(fmt.Errorf("goodm: pipeline: unknown field %q in %s", name, schema.ModelName))
+			return p
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	filter := make(bson.D, 0, len(names))
+	for _, name := range names {
+		filter = append(filter, bson.E{Key: name, Value: fields[name]})
+	}
+	return p.Match(filter)
+}
+
 // Group adds a $group stage for aggregation.
 func (p *Pipeline) Group(group interface{}) *Pipeline {
 	p.stages = append(p.stages, bson.D{{Key: "$group", Value: group}})
@@ -65,6 +96,24 @@ func (p *Pipeline) Project(projection interface{}) *Pipeline {
 	return p
 }
 
+// ProjectModel adds a $project stage keeping the model's fields, so that
+// documents reshaped by earlier stages decode cleanly into the model. Fields
+// tagged select=false are left out, as in Find.
+func (p *Pipeline) ProjectModel() *Pipeline {
+	schema := p.schema()
+	if schema == nil {
+		return p
+	}
+
+	projection := make(bson.D, 0, len(schema.Fields))
+	for _, f := range schema.Fields {
+		if !f.Hidden {
+			projection = append(projection, bson.E{Key: f.BSONName, Value: 1})
+		}
+	}
+	return p.Project(projection)
+}
+
 // Limit adds a $limit stage.
 func (p *Pipeline) Limit(n int64) *Pipeline {
 	p.stages = append(p.stages, bson.D{{Key: "$limit", Value: n}})
@@ -120,52 +169,153 @@ func (p *Pipeline) Stages() []bson.D {
 	return p.stages
 }
 
-// Execute runs the aggregation pipeline and decodes all results into the
-// provided slice pointer.
-func (p *Pipeline) Execute(ctx context.Context, results interface{}) error {
+// Err returns the first error recorded by a schema-derived stage such as
+// MatchFields, or nil.
+func (p *Pipeline) Err() error {
+	return p.err
+}
+
+// schema returns the schema of the pipeline's model, recording the error if
+// the model is not registered.
+func (p *Pipeline) schema() *Schema {
 	schema, err := getSchemaForModel(p.model)
 	if err != nil {
-		return err
+		p.fail(err)
+		return nil
 	}
+	return schema
+}
 
-	db, err := getDB(p.db)
-	if err != nil {
-		return err
+// fail records err unless an earlier error was recorded.
+func (p *Pipeline) fail(err error) {
+	if p.err == nil {
+		p.err = err
 	}
+}
 
-	coll := getCollection(db, schema)
-	cursor, err := coll.Aggregate(ctx, p.stages)
-	if err != nil {
-		return fmt.Errorf("goodm: aggregate failed: %w", err)
+// hasFieldPath reports whether path names one of fields, or a dotted path
+// through their subdocument fields.
+func hasFieldPath(fields []FieldSchema, path string) bool {
+	name, rest, nested := strings.Cut(path, ".")
+	for _, f := range fields {
+		if f.BSONName != name {
+			continue
+		}
+		if !nested {
+			return true
+		}
+		return hasFieldPath(f.SubFields, rest)
 	}
-	defer func() { _ = cursor.Close(ctx) }()
+	return false
+}
 
-	if err := cursor.All(ctx, results); err != nil {
-		return fmt.Errorf("goodm: aggregate decode failed: %w", err)
+// Execute runs the aggregation pipeline and decodes all results into the
+// provided slice pointer. Like Aggregate, it runs through middleware as
+// OpAggregate and only sees the documents Find would.
+func (p *Pipeline) Execute(ctx context.Context, results interface{}) error {
+	if p.err != nil {
+		return p.err
 	}
-
-	return nil
+	return Aggregate(ctx, p.model, p.stages, results, PipelineOptions{DB: p.db})
 }
 
 // Cursor runs the aggregation pipeline and returns a raw *mongo.Cursor
 // for streaming large result sets. The caller is responsible for closing
-// the cursor.
+// the cursor. Like Execute, it runs through middleware as OpAggregate and
+// only sees the documents Find would.
 func (p *Pipeline) Cursor(ctx context.Context) (*mongo.Cursor, error) {
-	schema, err := getSchemaForModel(p.model)
-	if err != nil {
-		return nil, err
+	if p.err != nil {
+		return nil, p.err
 	}
 
-	db, err := getDB(p.db)
+	var cursor *mongo.Cursor
+	err := aggregate(ctx, p.model, p.stages, nil, PipelineOptions{DB: p.db}, func(ctx context.Context, c *mongo.Cursor) error {
+		cursor = c
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return cursor, nil
+}
+
+// Aggregate runs raw aggregation stages against the model's collection and
+// decodes all results into the provided slice pointer. It is the builder-free
+// counterpart of Pipeline.Execute for callers that already have their stages.
+// It runs through middleware as OpAggregate: the model's access policy is
+// consulted, and a pipeline ending in $out or $merge is rejected in
+// read-only mode. A leading $match stage limits the pipeline to the
+// documents Find would see: those matching the policy filter, not soft
+// deleted, and of the model's kind in a polymorphic collection.
+//
+// Example:
+//
+//	var results []bson.M
+//	err := goodm.Aggregate(ctx, &User{}, []bson.D{
+//	    {{Key: "$match", Value: bson.D{{Key: "role", Value: "admin"}}}},
+//	    {{Key: "$count", Value: "admins"}},
+//	}, &results)
+func Aggregate(ctx context.Context, model interface{}, stages []bson.D, results interface{}, opts ...PipelineOptions) error {
+	var opt PipelineOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return aggregate(ctx, model, stages, results, opt, func(ctx context.Context, cursor *mongo.Cursor) error {
+		defer func() { _ = cursor.Close(ctx) }()
+		if err := cursor.All(ctx, results); err != nil {
+			return fmt.Errorf("goodm: aggregate decode failed: %w", err)
+		}
+		return nil
+	})
+}
 
-	coll := getCollection(db, schema)
-	cursor, err := coll.Aggregate(ctx, p.stages)
+// aggregate runs stages against the collection of model through the
+// middleware chain as an OpAggregate, scoped as Aggregate describes, and
+// hands the cursor to fn.
+func aggregate(ctx context.Context, model interface{}, stages []bson.D, results interface{}, opt PipelineOptions, fn func(context.Context, *mongo.Cursor) error) error {
+	schema, err := getSchemaForModel(model)
 	if err != nil {
-		return nil, fmt.Errorf("goodm: aggregate cursor failed: %w", err)
+		return err
 	}
 
-	return cursor, nil
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpAggregate, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: model, Filter: stages,
+		Result: results, Options: opt,
+		filterable: true,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+
+		coll := getCollection(db, schema)
+		cursor, err := coll.Aggregate(ctx, scopeStages(ctx, schema, stages), withComment(ctx, options.Aggregate()))
+		if err != nil {
+			return fmt.Errorf("goodm: aggregate failed: %w", err)
+		}
+		return fn(ctx, cursor)
+	})
+}
+
+// scopeStages returns stages behind a $match stage limiting them to the
+// documents a Find of schema would see in ctx.
+func scopeStages(ctx context.Context, schema *Schema, stages []bson.D) []bson.D {
+	f := scopeFilter(ctx, schema, nil)
+	if f == nil {
+		return stages
+	}
+	return append([]bson.D{{{Key: "$match", Value: f}}}, stages...)
+}
+
+// writesOutput reports whether the pipeline stages of an OpAggregate end in
+// a $out or $merge stage, which writes its results to a collection.
+func writesOutput(filter interface{}) bool {
+	stages, ok := filter.([]bson.D)
+	if !ok || len(stages) == 0 {
+		return false
+	}
+	last := stages[len(stages)-1]
+	return len(last) > 0 && (last[0].Key == "$out" || last[0].Key == "$merge")
 }
@@ -1,6 +1,11 @@
 package goodm
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -142,3 +147,226 @@ func TestPipeline_Empty(t *testing.T) {
 		t.Fatalf("expected nil stages for empty pipeline, got %v", stages)
 	}
 }
+
+func TestAggregate_Middleware(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	defer ClearMiddleware()
+
+	var seen *OpInfo
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		seen = op
+		return errors.New("blocked")
+	})
+
+	stages := []bson.D{{{Key: "$match", Value: bson.D{{Key: "role", Value: "admin"}}}}}
+	var results []bson.M
+	err := Aggregate(ctx, &testUser{}, stages, &results)
+	if err == nil || err.Error() != "blocked" {
+		t.Fatalf("expected middleware error, got %v", err)
+	}
+	if seen == nil || seen.Operation != OpAggregate || seen.Collection != "test_users" || seen.ModelName != "testUser" {
+		t.Fatalf("unexpected op info: %+v", seen)
+	}
+	if got, ok := seen.Filter.([]bson.D); !ok || len(got) != 1 {
+		t.Fatalf("expected stages as filter, got %#v", seen.Filter)
+	}
+}
+
+func TestAggregate_UnregisteredModel(t *testing.T) {
+	type unregistered struct{ Model }
+	var results []bson.M
+	if err := Aggregate(context.Background(), &unregistered{}, nil, &results); err == nil {
+		t.Fatal("expected error for unregistered model")
+	}
+}
+
+func TestPipeline_MatchFields(t *testing.T) {
+	useTestStore(t)
+	p := NewPipeline(&testOrder{}).MatchFields(map[string]interface{}{
+		"name":         "first",
+		"address.city": "Oslo",
+		"items.name":   "pen",
+	})
+	if err := p.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := bson.D{
+		{Key: "address.city", Value: "Oslo"},
+		{Key: "items.name", Value: "pen"},
+		{Key: "name", Value: "first"},
+	}
+	stage := p.Stages()[0]
+	if stage[0].Key != "$match" || !reflect.DeepEqual(stage[0].Value, want) {
+		t.Fatalf("unexpected stage: %v", stage)
+	}
+}
+
+func TestPipeline_MatchFieldsUnknownField(t *testing.T) {
+	ctx := useTestStore(t)
+	p := NewPipeline(&testOrder{}).
+		MatchFields(map[string]interface{}{"address.country": "NO"}).
+		Limit(5)
+	if len(p.Stages()) != 1 {
+		t.Fatalf("expected only the limit stage, got %v", p.Stages())
+	}
+
+	var results []bson.M
+	err := p.Execute(ctx, &results)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "address.country"`) {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+	if _, err := p.Cursor(ctx); err == nil {
+		t.Fatal("expected Cursor to return the recorded error")
+	}
+}
+
+func TestPipeline_ProjectModel(t *testing.T) {
+	useTestStore(t)
+	p := NewPipeline(&testAccount{}).ProjectModel()
+	if err := p.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := bson.D{
+		{Key: "_id", Value: 1},
+		{Key: "created_at", Value: 1},
+		{Key: "updated_at", Value: 1},
+		{Key: "__v", Value: 1},
+		{Key: "username", Value: 1},
+	}
+	stage := p.Stages()[0]
+	if stage[0].Key != "$project" || !reflect.DeepEqual(stage[0].Value, want) {
+		t.Fatalf("unexpected stage: %v", stage)
+	}
+}
+
+func TestPipeline_ProjectModelUnregistered(t *testing.T) {
+	type unregistered struct{ Model }
+	p := NewPipeline(&unregistered{}).ProjectModel()
+	if p.Err() == nil || len(p.Stages()) != 0 {
+		t.Fatalf("expected error and no stages, got %v, %v", p.Err(), p.Stages())
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestAggregate_Integration(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i, role := range []string{"admin", "user", "admin"} {
+		user := &testUser{Email: fmt.Sprintf("agg%d@test.com", i), Name: "Agg", Age: 20 + i, Role: role}
+		if err := Create(ctx, user); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	var results []bson.M
+	err := Aggregate(ctx, &testUser{}, []bson.D{
+		{{Key: "$match", Value: bson.D{{Key: "role", Value: "admin"}}}},
+		{{Key: "$count", Value: "admins"}},
+	}, &results)
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	if len(results) != 1 || results[0]["admins"] != int32(2) {
+		t.Fatalf("expected 2 admins, got %v", results)
+	}
+}
+
+func TestPipeline_Middleware(t *testing.T) {
+	ctx := setupOwnedDocs(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+
+	var seen []*OpInfo
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		seen = append(seen, op)
+		return errors.New("blocked")
+	})
+
+	var results []bson.M
+	p := NewPipeline(&testOwnedDoc{}).Match(bson.D{{Key: "title", Value: "x"}})
+	if err := p.Execute(WithActor(ctx, "alice"), &results); err == nil || err.Error() != "blocked" {
+		t.Fatalf("expected Execute to run middleware, got %v", err)
+	}
+	if _, err := p.Cursor(WithActor(ctx, "alice")); err == nil || err.Error() != "blocked" {
+		t.Fatalf("expected Cursor to run middleware, got %v", err)
+	}
+	for _, op := range seen {
+		if op.Operation != OpAggregate || op.ModelName != "testOwnedDoc" {
+			t.Fatalf("unexpected op info: %+v", op)
+		}
+		if stages, ok := op.Filter.([]bson.D); !ok || len(stages) != 2 || stages[0][0].Key != "$match" {
+			t.Errorf("expected the policy $match ahead of the stages, got %v", op.Filter)
+		}
+	}
+	if len(seen) != 2 {
+		t.Fatalf("expected two operations, got %d", len(seen))
+	}
+
+	var denied *AccessDeniedError
+	if err := p.Execute(ctx, &results); !errors.As(err, &denied) {
+		t.Errorf("expected Execute without an actor to be denied, got %v", err)
+	}
+}
+
+func TestScopeStages(t *testing.T) {
+	useTestStore(t)
+	if err := Register(&testComment{}, "comments"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testComment")
+		registryMu.Unlock()
+	})
+	comments, _ := Get("testComment")
+	users, _ := Get("testUser")
+	stages := []bson.D{{{Key: "$count", Value: "n"}}}
+
+	got := scopeStages(context.Background(), comments, stages)
+	want := []bson.D{{{Key: "$match", Value: bson.D{{Key: "deleted_at", Value: nil}}}}, stages[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected soft-deleted documents to be left out, got %v", got)
+	}
+	if got := scopeStages(withDeleted(context.Background()), comments, stages); !reflect.DeepEqual(got, stages) {
+		t.Errorf("expected withDeleted to keep the stages, got %v", got)
+	}
+	if got := scopeStages(context.Background(), users, stages); !reflect.DeepEqual(got, stages) {
+		t.Errorf("expected an unscoped model to keep the stages, got %v", got)
+	}
+
+	cleanup := registerPaymentModels(t)
+	defer cleanup()
+	cards, _ := Get("testCardPayment")
+	got = scopeStages(context.Background(), cards, stages)
+	if len(got) != 2 || got[0][0].Key != "$match" || !reflect.DeepEqual(got[0][0].Value, bson.D{{Key: cards.Discriminator, Value: cards.DiscriminatorValue}}) {
+		t.Errorf("expected a polymorphic model to match its kind, got %v", got)
+	}
+}
+
+func TestAggregate_OutputReadOnly(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := SetReadOnly(true); err != nil {
+		t.Fatalf("set read-only: %v", err)
+	}
+	t.Cleanup(func() { _ = SetReadOnly(false) })
+
+	var results []bson.M
+	for _, stage := range []bson.D{
+		{{Key: "$out", Value: "admins"}},
+		{{Key: "$merge", Value: bson.D{{Key: "into", Value: "admins"}}}},
+	} {
+		err := NewPipeline(&testUser{}).Match(bson.D{{Key: "role", Value: "admin"}}).Stage(stage).Execute(ctx, &results)
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("expected %s to be rejected in read-only mode, got %v", stage[0].Key, err)
+		}
+	}
+	err := NewPipeline(&testUser{}).Match(bson.D{{Key: "role", Value: "admin"}}).Execute(ctx, &results)
+	if errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected a pipeline without output to pass the read-only check, got %v", err)
+	}
+}
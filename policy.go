@@ -0,0 +1,179 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Decision is the outcome of a PolicyFunc: allow the operation, deny it, or
+// allow it on the documents matching a filter only.
+type Decision struct {
+	Deny   bool
+	Reason string      // why the operation was denied, for AccessDeniedError
+	Filter interface{} // if set, restricts the operation to matching documents
+}
+
+// Allow returns a Decision letting the operation run unchanged.
+func Allow() Decision {
+	return Decision{}
+}
+
+// Deny returns a Decision rejecting the operation with an
+// *AccessDeniedError carrying reason.
+func Deny(reason string) Decision {
+	return Decision{Deny: true, Reason: reason}
+}
+
+// AllowWhere returns a Decision letting the operation run on the documents
+// matching filter only.
+func AllowWhere(filter interface{}) Decision {
+	return Decision{Filter: filter}
+}
+
+// PolicyFunc decides whether an operation on a model may run. actor is the
+// value set with WithActor, or nil.
+type PolicyFunc func(ctx context.Context, op *OpInfo, actor interface{}) Decision
+
+var (
+	policyMu sync.RWMutex
+	policies map[string]PolicyFunc
+)
+
+// SetPolicy installs the access policy of a registered model, replacing any
+// previous one; a nil policy removes it. The policy is consulted before every
+// operation on the model, ahead of middleware:
+//
+//   - a denying Decision fails the operation with an *AccessDeniedError
+//   - a Decision with a Filter restricts FindOne, Find, FindCursor,
+//     Aggregate, UpdateOne, UpdateMany, DeleteOne, and DeleteMany to the
+//     documents matching it, and fails Update, UpdateFields, Touch, and
+//     Delete with an *AccessDeniedError when the stored document does not
+//     match it. Other operations, such as Create, cannot apply a filter and
+//     are denied. OpInfo.Filter shows middleware the restricted filter, or
+//     for Aggregate the pipeline with a leading $match stage.
+//
+// Update, UpdateFields, Touch, and Delete check the stored document before
+// writing it, so a policy filter on a field those writes may change, rather
+// than an immutable owner field, does not guard concurrent writes.
+//
+// Example:
+//
+//	goodm.SetPolicy(&Post{}, func(ctx context.Context, op *goodm.OpInfo, actor interface{}) goodm.Decision {
+//	    switch op.Operation {
+//	    case goodm.OpFind, goodm.OpAggregate:
+//	        return goodm.Allow()
+//	    case goodm.OpCreate:
+//	        if actor == nil {
+//	            return goodm.Deny("sign in to post")
+//	        }
+//	        return goodm.Allow()
+//	    }
+//	    return goodm.AllowWhere(bson.D{{Key: "author", Value: actor}})
+//	})
+func SetPolicy(model interface{}, policy PolicyFunc) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	if policy == nil {
+		delete(policies, schema.ModelName)
+		return nil
+	}
+	if policies == nil {
+		policies = make(map[string]PolicyFunc)
+	}
+	policies[schema.ModelName] = policy
+	return nil
+}
+
+// ClearPolicies removes all access policies. Useful for testing.
+func ClearPolicies() {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policies = nil
+}
+
+// policyScope is the filter the access policy of a model restricted the
+// current operation to.
+type policyScope struct {
+	model  string
+	filter interface{}
+}
+
+type policyKey struct{}
+
+// applyPolicy consults the access policy of the operation's model. It
+// returns ctx carrying the policy's filter, with info.Filter narrowed to it.
+func applyPolicy(ctx context.Context, info *OpInfo) (context.Context, error) {
+	policyMu.RLock()
+	policy := policies[info.ModelName]
+	policyMu.RUnlock()
+	if policy == nil {
+		return ctx, nil
+	}
+
+	actor, _ := ActorFromContext(ctx)
+	d := policy(ctx, info, actor)
+	if d.Deny {
+		return ctx, &AccessDeniedError{ModelName: info.ModelName, Operation: info.Operation, Reason: d.Reason}
+	}
+	// Operations nested in hooks consult the policy again, and must not
+	// inherit an enclosing operation's filter.
+	scope := &policyScope{model: info.ModelName, filter: d.Filter}
+	if d.Filter == nil {
+		return context.WithValue(ctx, policyKey{}, scope), nil
+	}
+	if !info.filterable {
+		return ctx, &AccessDeniedError{
+			ModelName: info.ModelName, Operation: info.Operation,
+			Reason: "the policy filter cannot be applied to this operation",
+		}
+	}
+	if stages, ok := info.Filter.([]bson.D); ok && info.Operation == OpAggregate {
+		info.Filter = append([]bson.D{{{Key: "$match", Value: d.Filter}}}, stages...)
+	} else {
+		info.Filter = andFilter(info.Filter, d.Filter)
+	}
+	return context.WithValue(ctx, policyKey{}, scope), nil
+}
+
+// policyFilter returns the filter the access policy of schema restricted the
+// operation of ctx to, or nil.
+func policyFilter(ctx context.Context, schema *Schema) interface{} {
+	if ps, ok := ctx.Value(policyKey{}).(*policyScope); ok && ps.model == schema.ModelName {
+		return ps.filter
+	}
+	return nil
+}
+
+// checkPolicyScope returns an *AccessDeniedError if the access policy of
+// schema restricted the operation of ctx to a filter the stored document id
+// does not match.
+func checkPolicyScope(ctx context.Context, coll collection, schema *Schema, id bson.ObjectID, op OpType) error {
+	f := policyFilter(ctx, schema)
+	if f == nil {
+		return nil
+	}
+	n, err := coll.CountDocuments(ctx, andFilter(bson.D{{Key: "_id", Value: id}}, f))
+	if err != nil {
+		return fmt.Errorf("goodm: policy check failed: %w", err)
+	}
+	if n == 0 {
+		return &AccessDeniedError{ModelName: schema.ModelName, Operation: op, Reason: "the document does not match the policy filter"}
+	}
+	return nil
+}
+
+// andFilter returns a filter matching both a and b. A nil a matches all
+// documents.
+func andFilter(a, b interface{}) interface{} {
+	if a == nil {
+		return b
+	}
+	return bson.D{{Key: "$and", Value: bson.A{a, b}}}
+}
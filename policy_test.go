@@ -0,0 +1,156 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testOwnedDoc struct {
+	Model `bson:",inline"`
+	Owner string `bson:"owner"`
+	Title string `bson:"title"`
+}
+
+// ownerPolicy lets anyone signed in create documents, and restricts
+// everything else to the actor's own documents.
+func ownerPolicy(ctx context.Context, op *OpInfo, actor interface{}) Decision {
+	if actor == nil {
+		return Deny("not signed in")
+	}
+	if op.Operation == OpCreate {
+		return Allow()
+	}
+	return AllowWhere(bson.D{{Key: "owner", Value: actor}})
+}
+
+func setupOwnedDocs(t *testing.T) context.Context {
+	t.Helper()
+	ctx := useTestStore(t)
+	if err := Register(&testOwnedDoc{}, "test_owned_docs"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		ClearPolicies()
+		registryMu.Lock()
+		delete(registry, "testOwnedDoc")
+		registryMu.Unlock()
+	})
+	if err := SetPolicy(&testOwnedDoc{}, ownerPolicy); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+	return ctx
+}
+
+func TestPolicy_Deny(t *testing.T) {
+	ctx := setupOwnedDocs(t)
+
+	err := Create(ctx, &testOwnedDoc{Owner: "alice", Title: "anon"})
+	var denied *AccessDeniedError
+	if !errors.As(err, &denied) || denied.Operation != OpCreate || denied.Reason != "not signed in" {
+		t.Fatalf("expected an AccessDeniedError, got %v", err)
+	}
+
+	if err := SetPolicy(&testOwnedDoc{}, nil); err != nil {
+		t.Fatalf("remove policy: %v", err)
+	}
+	if err := Create(ctx, &testOwnedDoc{Owner: "alice", Title: "anon"}); err != nil {
+		t.Fatalf("create without a policy: %v", err)
+	}
+}
+
+func TestPolicy_Filter(t *testing.T) {
+	ctx := setupOwnedDocs(t)
+	alice := WithActor(ctx, "alice")
+	bob := WithActor(ctx, "bob")
+
+	mine := &testOwnedDoc{Owner: "alice", Title: "mine"}
+	theirs := &testOwnedDoc{Owner: "bob", Title: "theirs"}
+	if err := Create(alice, mine); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := Create(bob, theirs); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var docs []testOwnedDoc
+	if err := Find(alice, bson.D{}, &docs); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(docs) != 1 || docs[0].Title != "mine" {
+		t.Fatalf("expected only alice's document, got %+v", docs)
+	}
+	var doc testOwnedDoc
+	if err := FindOne(alice, bson.D{{Key: "title", Value: "theirs"}}, &doc); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected bob's document to be out of reach, got %v", err)
+	}
+
+	theirs.Title = "taken"
+	var denied *AccessDeniedError
+	if err := Update(alice, theirs); !errors.As(err, &denied) {
+		t.Errorf("expected updating bob's document to be denied, got %v", err)
+	}
+	if err := Delete(alice, theirs); !errors.As(err, &denied) {
+		t.Errorf("expected deleting bob's document to be denied, got %v", err)
+	}
+	mine.Title = "edited"
+	if err := Update(alice, mine); err != nil {
+		t.Errorf("update own document: %v", err)
+	}
+
+	res, err := DeleteMany(alice, bson.D{}, &testOwnedDoc{})
+	if err != nil || res.DeletedCount != 1 {
+		t.Fatalf("expected DeleteMany to delete alice's document only, got %+v (%v)", res, err)
+	}
+	docs = nil
+	if err := Find(bob, bson.D{}, &docs); err != nil || len(docs) != 1 || docs[0].Title != "theirs" {
+		t.Fatalf("expected bob's document to survive, got %+v (%v)", docs, err)
+	}
+}
+
+func TestPolicy_FilterNotApplicable(t *testing.T) {
+	ctx := setupOwnedDocs(t)
+	err := SetPolicy(&testOwnedDoc{}, func(ctx context.Context, op *OpInfo, actor interface{}) Decision {
+		return AllowWhere(bson.D{{Key: "owner", Value: actor}})
+	})
+	if err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+
+	var denied *AccessDeniedError
+	if err := Create(WithActor(ctx, "alice"), &testOwnedDoc{Owner: "alice"}); !errors.As(err, &denied) {
+		t.Fatalf("expected a filter on Create to be denied, got %v", err)
+	}
+}
+
+func TestPolicy_MiddlewareSeesFilter(t *testing.T) {
+	ctx := setupOwnedDocs(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+
+	var filters []interface{}
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		filters = append(filters, op.Filter)
+		return next(ctx)
+	})
+
+	alice := WithActor(ctx, "alice")
+	var docs []testOwnedDoc
+	if err := Find(alice, bson.D{{Key: "title", Value: "x"}}, &docs); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	var out []bson.M
+	_ = Aggregate(alice, &testOwnedDoc{}, []bson.D{{{Key: "$count", Value: "n"}}}, &out)
+
+	if len(filters) != 2 {
+		t.Fatalf("expected two operations, got %d", len(filters))
+	}
+	if f, ok := filters[0].(bson.D); !ok || f[0].Key != "$and" {
+		t.Errorf("expected the find filter to be narrowed, got %v", filters[0])
+	}
+	if stages, ok := filters[1].([]bson.D); !ok || len(stages) != 2 || stages[0][0].Key != "$match" {
+		t.Errorf("expected a leading $match stage, got %v", filters[1])
+	}
+}
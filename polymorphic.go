@@ -0,0 +1,224 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// checkDiscriminator ensures no other model in the same collection already uses
+// the schema's discriminator value. Must be called with registryMu held.
+func checkDiscriminator(schema *Schema) error {
+	if schema.Discriminator == "" {
+		return nil
+	}
+	for _, other := range registry {
+		if other.Collection != schema.Collection || other.Discriminator == "" {
+			continue
+		}
+		if other.Discriminator != schema.Discriminator {
+			return fmt.Errorf("goodm: model %q uses discriminator field %q but %q in collection %q uses %q",
+				schema.ModelName, schema.Discriminator, other.ModelName, schema.Collection, other.Discriminator)
+		}
+		if other.DiscriminatorValue == schema.DiscriminatorValue {
+			return fmt.Errorf("goodm: kind %q in collection %q is already registered by %q",
+				schema.DiscriminatorValue, schema.Collection, other.ModelName)
+		}
+	}
+	return nil
+}
+
+// setDiscriminator stamps the schema's kind value onto the model's
+// discriminator field. No-op for non-polymorphic schemas.
+func setDiscriminator(model interface{}, schema *Schema) {
+	if schema.Discriminator == "" {
+		return
+	}
+	f := schema.GetField(schema.Discriminator)
+	if f == nil {
+		return
+	}
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if fv := v.FieldByName(f.Name); fv.IsValid() && fv.CanSet() && fv.Kind() == reflect.String {
+		fv.SetString(schema.DiscriminatorValue)
+	}
+}
+
+// scopeFilter narrows a filter to documents of the schema's kind when the
+// model lives in a polymorphic collection, to the filter its access policy
+// returned for the operation of ctx, and to documents that are not soft
+// deleted. Other schemas get filter unchanged.
+func scopeFilter(ctx context.Context, schema *Schema, filter interface{}) interface{} {
+	if f := policyFilter(ctx, schema); f != nil {
+		filter = andFilter(filter, f)
+	}
+	if f := notDeletedFilter(ctx, schema); f != nil {
+		filter = andFilter(filter, f)
+	}
+	if schema.Discriminator == "" {
+		return filter
+	}
+	kind := bson.D{{Key: schema.Discriminator, Value: schema.DiscriminatorValue}}
+	if filter == nil {
+		return kind
+	}
+	return bson.D{{Key: "$and", Value: bson.A{filter, kind}}}
+}
+
+// kindSchemas returns the registered polymorphic schemas for a collection,
+// keyed by discriminator value.
+func kindSchemas(collection string) map[string]*Schema {
+	result := make(map[string]*Schema)
+	for _, s := range GetAll() {
+		if s.Collection == collection && s.Discriminator != "" {
+			result[s.DiscriminatorValue] = s
+		}
+	}
+	return result
+}
+
+// FindPolymorphic finds documents across every kind stored in a polymorphic
+// collection and decodes each into the registered model matching its
+// discriminator value. model is any registered model of the collection and is
+// used only for collection lookup. results must be a pointer to a slice whose
+// element type is an interface the kinds implement (or interface{}).
+//
+// Example:
+//
+//	type CardPayment struct {
+//	    goodm.Model `bson:",inline"`
+//	    Kind        string `bson:"kind" goodm:"kind=card"`
+//	    Last4       string `bson:"last4"`
+//	}
+//
+//	var payments []interface{}
+//	err := goodm.FindPolymorphic(ctx, bson.D{}, &CardPayment{}, &payments)
+//	for _, p := range payments {
+//	    switch p := p.(type) {
+//	    case *CardPayment:
+//	        ...
+//	    }
+//	}
+func FindPolymorphic(ctx context.Context, filter interface{}, model interface{}, results interface{}, opts ...FindOptions) error {
+	rv := reflect.ValueOf(results)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice || rv.Elem().Type().Elem().Kind() != reflect.Interface {
+		return fmt.Errorf("goodm: results must be a pointer to a slice of an interface type, got %T", results)
+	}
+
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	if schema.Discriminator == "" {
+		return fmt.Errorf("goodm: model %q has no kind discriminator", schema.ModelName)
+	}
+
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpFind, Collection: schema.Collection,
+		ModelName: schema.ModelName, Filter: filter,
+	}, func(ctx context.Context) error {
+		var opt FindOptions
+		if len(opts) > 0 {
+			opt = opts[0]
+		}
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+
+		findOpts := withComment(ctx, options.Find())
+		if opt.Limit > 0 {
+			findOpts.SetLimit(opt.Limit)
+		}
+		if opt.Skip > 0 {
+			findOpts.SetSkip(opt.Skip)
+		}
+		if opt.Sort != nil {
+			findOpts.SetSort(opt.Sort)
+		}
+		if opt.Collation != nil {
+			findOpts.SetCollation(opt.Collation)
+		}
+		if !opt.IncludeHidden {
+			var proj bson.D
+			for _, ks := range kindSchemas(schema.Collection) {
+				proj = append(proj, hiddenProjection(ks, false)...)
+			}
+			if proj != nil {
+				findOpts.SetProjection(dedupeProjection(proj))
+			}
+		}
+		if filter == nil {
+			filter = bson.D{}
+		}
+
+		coll := getCollection(db, schema)
+		cursor, err := coll.Find(ctx, filter, findOpts)
+		if err != nil {
+			return fmt.Errorf("goodm: find failed: %w", err)
+		}
+		defer func() { _ = cursor.Close(ctx) }()
+
+		return decodePolymorphic(ctx, cursor, schema, rv.Elem())
+	})
+}
+
+// decodePolymorphic decodes every document in cursor into the registered kind
+// model and appends it to out.
+func decodePolymorphic(ctx context.Context, cursor *mongo.Cursor, schema *Schema, out reflect.Value) error {
+	kinds := kindSchemas(schema.Collection)
+	elemType := out.Type().Elem()
+	decoded := reflect.MakeSlice(out.Type(), 0, 0)
+
+	for cursor.Next(ctx) {
+		kind, _ := cursor.Current.Lookup(schema.Discriminator).StringValueOK()
+		ks, ok := kinds[kind]
+		if !ok {
+			return fmt.Errorf("goodm: no model registered for %s=%q in %s", schema.Discriminator, kind, schema.Collection)
+		}
+
+		item := reflect.New(ks.modelType)
+		if err := unmarshalBSON(cursor.Current, item.Interface()); err != nil {
+			return fmt.Errorf("goodm: cursor decode failed: %w", err)
+		}
+		if !item.Type().AssignableTo(elemType) {
+			return fmt.Errorf("goodm: %s does not implement %s", item.Type(), elemType)
+		}
+		if err := afterLoad(ctx, item.Interface()); err != nil {
+			return err
+		}
+		decoded = reflect.Append(decoded, item)
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("goodm: cursor decode failed: %w", err)
+	}
+
+	out.Set(decoded)
+	return nil
+}
+
+// dedupeProjection removes repeated keys from a projection document.
+func dedupeProjection(proj bson.D) bson.D {
+	seen := make(map[string]bool, len(proj))
+	result := make(bson.D, 0, len(proj))
+	for _, e := range proj {
+		if !seen[e.Key] {
+			seen[e.Key] = true
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package goodm
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testCardPayment struct {
+	Model  `bson:",inline"`
+	Kind   string `bson:"kind"   goodm:"kind=card"`
+	Amount int    `bson:"amount" goodm:"required"`
+	Last4  string `bson:"last4"`
+}
+
+type testBankPayment struct {
+	Model  `bson:",inline"`
+	Kind   string `bson:"kind"   goodm:"kind=bank"`
+	Amount int    `bson:"amount" goodm:"required"`
+	IBAN   string `bson:"iban"`
+}
+
+type testDupCardPayment struct {
+	Model `bson:",inline"`
+	Kind  string `bson:"kind" goodm:"kind=card"`
+}
+
+func registerPaymentModels(t *testing.T) func() {
+	t.Helper()
+	if err := Register(&testCardPayment{}, "test_payments"); err != nil {
+		t.Fatalf("register card: %v", err)
+	}
+	if err := Register(&testBankPayment{}, "test_payments"); err != nil {
+		t.Fatalf("register bank: %v", err)
+	}
+	return func() {
+		registryMu.Lock()
+		delete(registry, "testCardPayment")
+		delete(registry, "testBankPayment")
+		delete(registry, "testDupCardPayment")
+		registryMu.Unlock()
+	}
+}
+
+func TestRegister_Discriminator(t *testing.T) {
+	defer registerPaymentModels(t)()
+
+	schema, _ := Get("testCardPayment")
+	if schema.Discriminator != "kind" || schema.DiscriminatorValue != "card" {
+		t.Fatalf("unexpected discriminator: %q=%q", schema.Discriminator, schema.DiscriminatorValue)
+	}
+
+	if err := Register(&testDupCardPayment{}, "test_payments"); err == nil {
+		t.Fatal("expected error for duplicate kind in the same collection")
+	}
+}
+
+func TestSetDiscriminator(t *testing.T) {
+	defer registerPaymentModels(t)()
+
+	schema, _ := Get("testBankPayment")
+	p := &testBankPayment{Kind: "wrong"}
+	setDiscriminator(p, schema)
+	if p.Kind != "bank" {
+		t.Fatalf("expected kind bank, got %q", p.Kind)
+	}
+}
+
+func TestScopeFilter(t *testing.T) {
+	defer registerPaymentModels(t)()
+
+	ctx := context.Background()
+	schema, _ := Get("testCardPayment")
+	scoped, ok := scopeFilter(ctx, schema, bson.D{{Key: "amount", Value: 5}}).(bson.D)
+	if !ok || len(scoped) != 1 || scoped[0].Key != "$and" {
+		t.Fatalf("expected $and filter, got %v", scoped)
+	}
+
+	scoped, ok = scopeFilter(ctx, schema, nil).(bson.D)
+	if !ok || len(scoped) != 1 || scoped[0].Key != "kind" || scoped[0].Value != "card" {
+		t.Fatalf("expected kind-only filter, got %v", scoped)
+	}
+
+	plain := &Schema{}
+	filter := bson.D{{Key: "x", Value: 1}}
+	if got := scopeFilter(ctx, plain, filter); len(got.(bson.D)) != 1 || got.(bson.D)[0].Key != "x" {
+		t.Fatalf("expected filter unchanged for non-polymorphic schema, got %v", got)
+	}
+}
+
+func TestFindPolymorphic_InvalidResults(t *testing.T) {
+	defer registerPaymentModels(t)()
+
+	var cards []testCardPayment
+	if err := FindPolymorphic(context.Background(), bson.D{}, &testCardPayment{}, &cards); err == nil {
+		t.Fatal("expected error for non-interface slice")
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestPolymorphic_Integration(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+	defer registerPaymentModels(t)()
+
+	if err := Create(ctx, &testCardPayment{Amount: 10, Last4: "4242"}); err != nil {
+		t.Fatalf("create card: %v", err)
+	}
+	if err := Create(ctx, &testBankPayment{Amount: 20, IBAN: "DE00"}); err != nil {
+		t.Fatalf("create bank: %v", err)
+	}
+
+	// Typed Find is scoped to the model's kind.
+	var cards []testCardPayment
+	if err := Find(ctx, bson.D{}, &cards); err != nil {
+		t.Fatalf("find cards: %v", err)
+	}
+	if len(cards) != 1 || cards[0].Kind != "card" || cards[0].Last4 != "4242" {
+		t.Fatalf("expected one card payment, got %+v", cards)
+	}
+
+	// Polymorphic Find decodes each kind into its own type.
+	var all []interface{}
+	if err := FindPolymorphic(ctx, bson.D{}, &testCardPayment{}, &all, FindOptions{Sort: bson.D{{Key: "amount", Value: 1}}}); err != nil {
+		t.Fatalf("find polymorphic: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 payments, got %d", len(all))
+	}
+	if _, ok := all[0].(*testCardPayment); !ok {
+		t.Fatalf("expected *testCardPayment, got %T", all[0])
+	}
+	if bank, ok := all[1].(*testBankPayment); !ok || bank.IBAN != "DE00" {
+		t.Fatalf("expected *testBankPayment with IBAN, got %#v", all[1])
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // Refs maps bson field names to destination pointers for population.
@@ -53,6 +55,17 @@ func Populate(ctx context.Context, model interface{}, refs Refs, opts ...Populat
 	}
 
 	for bsonName, target := range refs {
+		if rel := schema.GetRelation(bsonName); rel != nil && rel.Kind != RelationBelongsTo {
+			id, err := getModelID(model)
+			if err != nil {
+				return err
+			}
+			if err := populateRelation(ctx, db, rel, id, target); err != nil {
+				return err
+			}
+			continue
+		}
+
 		field := schema.GetField(bsonName)
 		if field == nil {
 			return fmt.Errorf("goodm: field %q not found in schema for %s", bsonName, schema.ModelName)
@@ -66,7 +79,7 @@ func Populate(ctx context.Context, model interface{}, refs Refs, opts ...Populat
 			return fmt.Errorf("goodm: field %q not found in model struct", field.Name)
 		}
 
-		coll := db.Collection(field.Ref)
+		coll := namedCollection(db, field.Ref)
 
 		// Array ref: []bson.ObjectID → fetch all via $in
 		if refIDs, ok := fv.Interface().([]bson.ObjectID); ok {
@@ -91,12 +104,16 @@ func Populate(ctx context.Context, model interface{}, refs Refs, opts ...Populat
 }
 
 // populateArrayRef fetches all documents whose IDs are in refIDs using a single $in query.
-func populateArrayRef(ctx context.Context, coll *mongo.Collection, refIDs []bson.ObjectID, bsonName string, target interface{}) error {
+func populateArrayRef(ctx context.Context, coll collection, refIDs []bson.ObjectID, bsonName string, target interface{}) error {
 	ids := filterNonZeroIDs(refIDs)
 	if len(ids) == 0 {
 		return nil
 	}
-	cursor, err := coll.Find(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	filter, proj, err := refScope(ctx, target, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		return err
+	}
+	cursor, err := coll.Find(ctx, filter, withComment(ctx, options.Find().SetProjection(proj)))
 	if err != nil {
 		return fmt.Errorf("goodm: populate %q failed: %w", bsonName, err)
 	}
@@ -105,21 +122,25 @@ func populateArrayRef(ctx context.Context, coll *mongo.Collection, refIDs []bson
 		return fmt.Errorf("goodm: populate %q decode failed: %w", bsonName, err)
 	}
 	_ = cursor.Close(ctx)
-	return nil
+	return afterLoad(ctx, target)
 }
 
 // populateSingleRef fetches a single document by its ObjectID.
-func populateSingleRef(ctx context.Context, coll *mongo.Collection, refID bson.ObjectID, bsonName string, target interface{}) error {
+func populateSingleRef(ctx context.Context, coll collection, refID bson.ObjectID, bsonName string, target interface{}) error {
 	if refID.IsZero() {
 		return nil // skip unset refs
 	}
-	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: refID}}).Decode(target); err != nil {
+	filter, proj, err := refScope(ctx, target, bson.D{{Key: "_id", Value: refID}})
+	if err != nil {
+		return err
+	}
+	if err := coll.FindOne(ctx, filter, withComment(ctx, options.FindOne().SetProjection(proj))).Decode(target); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil // referenced document not found, leave target as zero
 		}
 		return fmt.Errorf("goodm: populate %q failed: %w", bsonName, err)
 	}
-	return nil
+	return afterLoad(ctx, target)
 }
 
 // filterNonZeroIDs returns a new slice with zero ObjectIDs removed.
@@ -202,8 +223,12 @@ func BatchPopulate(ctx context.Context, models interface{}, field string, result
 		return err
 	}
 
-	coll := db.Collection(fs.Ref)
-	cursor, err := coll.Find(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	filter, proj, err := refScope(ctx, results, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		return err
+	}
+	coll := namedCollection(db, fs.Ref)
+	cursor, err := coll.Find(ctx, filter, withComment(ctx, options.Find().SetProjection(proj)))
 	if err != nil {
 		return fmt.Errorf("goodm: batch populate %q failed: %w", field, err)
 	}
@@ -213,7 +238,7 @@ func BatchPopulate(ctx context.Context, models interface{}, field string, result
 		return fmt.Errorf("goodm: batch populate decode failed: %w", err)
 	}
 
-	return nil
+	return afterLoad(ctx, results)
 }
 
 // collectRefIDs gathers unique non-zero ObjectIDs from a ref field across a slice of models.
@@ -252,3 +277,317 @@ func collectRefIDs(mv reflect.Value, fs *FieldSchema) []bson.ObjectID {
 
 	return ids
 }
+
+// populateResults fills the populate fields and has relations named in names
+// across the models decoded by FindOne or Find: results is a pointer to a
+// model or to a slice of models. Each name is a Populate path, see
+// populatePath.
+func populateResults(ctx context.Context, db dbHandle, schema *Schema, results interface{}, names []string, maxDepth int) error {
+	rv := reflect.ValueOf(results).Elem()
+	var models []reflect.Value
+	if rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			el := rv.Index(i)
+			if el.Kind() == reflect.Ptr {
+				if el.IsNil() {
+					continue
+				}
+				el = el.Elem()
+			}
+			models = append(models, el)
+		}
+	} else {
+		models = []reflect.Value{rv}
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxPopulateDepth
+	}
+
+	for _, name := range names {
+		if err := populatePath(ctx, db, schema, models, name, maxDepth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// populatePath populates one Populate path across models. Each dot-separated
+// step is either the bson name of a ref field with a populate field, or the
+// Go field name of a has relation, and is resolved on the documents fetched
+// by the step before it. A trailing "*" repeats the last step until a level
+// fetches no new documents or maxDepth levels are filled.
+//
+// A fetched document whose ID was already populated at an earlier level is
+// filled in but not descended into, so reference cycles end.
+func populatePath(ctx context.Context, db dbHandle, schema *Schema, models []reflect.Value, path string, maxDepth int) error {
+	steps := strings.Split(path, ".")
+	last := len(steps) - 1
+	repeat := strings.HasSuffix(steps[last], "*")
+	steps[last] = strings.TrimSuffix(steps[last], "*")
+	for _, step := range steps {
+		if step == "" || strings.Contains(step, "*") {
+			return fmt.Errorf("goodm: invalid populate path %q", path)
+		}
+	}
+	if len(steps) > maxDepth {
+		return fmt.Errorf("goodm: populate path %q is %d levels deep, more than the limit of %d", path, len(steps), maxDepth)
+	}
+
+	seen := make(map[bson.ObjectID]bool)
+	for depth := 0; depth < maxDepth && len(models) > 0; depth++ {
+		if depth > last && !repeat {
+			return nil
+		}
+		step := steps[last]
+		if depth < last {
+			step = steps[depth]
+		}
+		field, err := populateStep(ctx, db, schema, models, step)
+		if err != nil {
+			return err
+		}
+		if depth == last && !repeat {
+			return nil
+		}
+
+		for _, m := range models {
+			if id, err := getModelID(m.Addr().Interface()); err == nil {
+				seen[id] = true
+			}
+		}
+		models = populatedDocs(models, field, seen)
+		if len(models) == 0 {
+			return nil
+		}
+		if schema, err = getSchemaForModel(models[0].Addr().Interface()); err != nil {
+			return fmt.Errorf("goodm: cannot populate %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// populateStep fills the populate field or has relation called name across
+// models and returns the Go name of the field it filled.
+func populateStep(ctx context.Context, db dbHandle, schema *Schema, models []reflect.Value, name string) (string, error) {
+	if rel := schema.GetRelation(name); rel != nil && rel.Kind != RelationBelongsTo {
+		return rel.Name, populateHasRelation(ctx, db, rel, models)
+	}
+	links, err := graphLinks(models[0].Type(), schema)
+	if err != nil {
+		return "", err
+	}
+	for _, l := range links {
+		if l.ref.BSONName == name {
+			return l.field.Name, populateLink(ctx, db, l, models)
+		}
+	}
+	return "", fmt.Errorf("goodm: cannot populate %q: %s has no populate field for it and no has relation of that name", name, schema.ModelName)
+}
+
+// populatedDocs returns the documents held in field across models, skipping
+// those whose ID is in seen.
+func populatedDocs(models []reflect.Value, field string, seen map[bson.ObjectID]bool) []reflect.Value {
+	var docs []reflect.Value
+	add := func(v reflect.Value) {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+		if id, err := getModelID(v.Addr().Interface()); err != nil || seen[id] {
+			return
+		}
+		docs = append(docs, v)
+	}
+	for _, m := range models {
+		fv := m.FieldByName(field)
+		if fv.Kind() == reflect.Slice {
+			for i := 0; i < fv.Len(); i++ {
+				add(fv.Index(i))
+			}
+			continue
+		}
+		add(fv)
+	}
+	return docs
+}
+
+// populateLink fetches the documents referenced by a ref field across models
+// and stores them in the linked populate field, in ref order. Dangling refs
+// are skipped.
+func populateLink(ctx context.Context, db dbHandle, l graphLink, models []reflect.Value) error {
+	ptrs := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(models[0].Type())), 0, len(models))
+	for _, m := range models {
+		ptrs = reflect.Append(ptrs, m.Addr())
+	}
+	ids := collectRefIDs(ptrs, l.ref)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	docType := l.field.Type
+	if docType.Kind() == reflect.Slice {
+		docType = docType.Elem()
+	}
+	if docType.Kind() == reflect.Ptr {
+		docType = docType.Elem()
+	}
+	docs, err := fetchByField(ctx, namedCollection(db, l.ref.Ref), "_id", ids, docType)
+	if err != nil {
+		return fmt.Errorf("goodm: populate %q failed: %w", l.ref.BSONName, err)
+	}
+	byID := make(map[bson.ObjectID]reflect.Value, docs.Len())
+	for i := 0; i < docs.Len(); i++ {
+		if id, err := getModelID(docs.Index(i).Addr().Interface()); err == nil {
+			byID[id] = docs.Index(i)
+		}
+	}
+
+	for _, m := range models {
+		fv := m.FieldByName(l.field.Name)
+		switch ref := m.FieldByName(l.ref.Name).Interface().(type) {
+		case bson.ObjectID:
+			if doc, ok := byID[ref]; ok {
+				fv.Set(asFieldValue(doc, fv.Type()))
+			}
+		case []bson.ObjectID:
+			out := reflect.MakeSlice(fv.Type(), 0, len(ref))
+			for _, id := range ref {
+				if doc, ok := byID[id]; ok {
+					out = reflect.Append(out, asFieldValue(doc, fv.Type().Elem()))
+				}
+			}
+			fv.Set(out)
+		}
+	}
+	return nil
+}
+
+// populateHasRelation fetches the documents of a has_one or has_many relation
+// for all models in one query and stores each model's documents in the
+// relation field.
+func populateHasRelation(ctx context.Context, db dbHandle, rel *Relation, models []reflect.Value) error {
+	ids := make([]bson.ObjectID, 0, len(models))
+	for _, m := range models {
+		if id, err := getModelID(m.Addr().Interface()); err == nil && !id.IsZero() {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	sf, _ := models[0].Type().FieldByName(rel.Name)
+	docType := sf.Type
+	if docType.Kind() == reflect.Slice {
+		docType = docType.Elem()
+	}
+	if docType.Kind() == reflect.Ptr {
+		docType = docType.Elem()
+	}
+	docs, err := fetchByField(ctx, namedCollection(db, rel.Collection), rel.ForeignKey, ids, docType)
+	if err != nil {
+		return fmt.Errorf("goodm: populate %q failed: %w", rel.Name, err)
+	}
+	raws, err := fetchForeignKeys(docs, rel.ForeignKey)
+	if err != nil {
+		return fmt.Errorf("goodm: populate %q failed: %w", rel.Name, err)
+	}
+	byOwner := make(map[bson.ObjectID][]reflect.Value)
+	for i := 0; i < docs.Len(); i++ {
+		byOwner[raws[i]] = append(byOwner[raws[i]], docs.Index(i))
+	}
+
+	for _, m := range models {
+		id, _ := getModelID(m.Addr().Interface())
+		fv := m.FieldByName(rel.Name)
+		owned := byOwner[id]
+		if fv.Kind() == reflect.Slice {
+			out := reflect.MakeSlice(fv.Type(), 0, len(owned))
+			for _, doc := range owned {
+				out = reflect.Append(out, asFieldValue(doc, fv.Type().Elem()))
+			}
+			fv.Set(out)
+		} else if len(owned) > 0 {
+			fv.Set(asFieldValue(owned[0], fv.Type()))
+		}
+	}
+	return nil
+}
+
+// fetchByField loads the documents of coll whose field is one of ids into a
+// new slice of docType, and finishes them with afterLoad.
+func fetchByField(ctx context.Context, coll collection, field string, ids []bson.ObjectID, docType reflect.Type) (reflect.Value, error) {
+	docs := reflect.New(reflect.SliceOf(docType))
+	filter, proj, err := refScope(ctx, docs.Interface(), bson.D{{Key: field, Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	cursor, err := coll.Find(ctx, filter, withComment(ctx, options.Find().SetProjection(proj)))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	if err := cursor.All(ctx, docs.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if err := afterLoad(ctx, docs.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return docs.Elem(), nil
+}
+
+// refScope returns filter narrowed like a Find of the model target decodes,
+// a pointer to a model or to a slice of models, and the projection leaving
+// out its select=false fields. The model's access policy is consulted for an
+// OpFind and may deny the fetch; soft-deleted documents and documents of
+// other kinds are left out. A target that is not a registered model is
+// fetched with filter as is.
+func refScope(ctx context.Context, target interface{}, filter bson.D) (interface{}, bson.D, error) {
+	schema, err := getSchemaForModel(target)
+	if err != nil {
+		return filter, bson.D{}, nil
+	}
+	ctx, err = applyPolicy(ctx, &OpInfo{
+		Operation: OpFind, Collection: schema.Collection, ModelName: schema.ModelName,
+		Filter: filter, filterable: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	proj := hiddenProjection(schema, false)
+	if proj == nil {
+		proj = bson.D{}
+	}
+	return scopeFilter(ctx, schema, filter), proj, nil
+}
+
+// fetchForeignKeys returns the value of the foreign key field of each
+// document in docs.
+func fetchForeignKeys(docs reflect.Value, fk string) ([]bson.ObjectID, error) {
+	keys := make([]bson.ObjectID, docs.Len())
+	for i := range keys {
+		raw, err := marshalBSON(docs.Index(i).Addr().Interface())
+		if err != nil {
+			return nil, err
+		}
+		if id, ok := bson.Raw(raw).Lookup(fk).ObjectIDOK(); ok {
+			keys[i] = id
+		}
+	}
+	return keys, nil
+}
+
+// asFieldValue converts a fetched document to a field of type t: the
+// document itself, or a pointer to it.
+func asFieldValue(doc reflect.Value, t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Ptr {
+		return doc.Addr()
+	}
+	return doc
+}
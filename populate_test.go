@@ -2,6 +2,7 @@ package goodm
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -332,3 +333,277 @@ func TestPopulate_DanglingRef(t *testing.T) {
 		t.Fatal("profile should not be populated for dangling ref")
 	}
 }
+
+func TestFindOne_PopulateOption(t *testing.T) {
+	ctx := useTestStore(t)
+	registerGraphModels(t)
+
+	author := &testUser{Email: "find-pop@test.com", Name: "Ann", Age: 30}
+	if err := Create(ctx, author); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	tags := []*testTag{{Label: "go"}, {Label: "db"}}
+	for _, tag := range tags {
+		if err := Create(ctx, tag); err != nil {
+			t.Fatalf("create tag: %v", err)
+		}
+	}
+	post := &testGraphPost{Title: "hi", AuthorID: author.ID, TagIDs: []bson.ObjectID{tags[1].ID, tags[0].ID}}
+	if err := Create(ctx, post); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+
+	found := &testGraphPost{}
+	err := FindOne(ctx, bson.D{{Key: "_id", Value: post.ID}}, found, FindOptions{Populate: []string{"author", "tags"}})
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Author == nil || found.Author.Name != "Ann" {
+		t.Fatalf("expected populated author, got %+v", found.Author)
+	}
+	if len(found.Tags) != 2 || found.Tags[0].Label != "db" || found.Tags[1].Label != "go" {
+		t.Fatalf("expected tags in ref order, got %+v", found.Tags)
+	}
+}
+
+func TestFind_PopulateOption(t *testing.T) {
+	ctx := useTestStore(t)
+	registerRelationModels(t)
+
+	authors := []*testRelAuthor{{Name: "Ann"}, {Name: "Bob"}, {Name: "Cy"}}
+	for _, a := range authors {
+		if err := Create(ctx, a); err != nil {
+			t.Fatalf("create author: %v", err)
+		}
+	}
+	for _, p := range []*testRelPost{
+		{Title: "a1", AuthorID: authors[0].ID},
+		{Title: "a2", AuthorID: authors[0].ID},
+		{Title: "b1", AuthorID: authors[1].ID},
+	} {
+		if err := Create(ctx, p); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+	if err := Create(ctx, &testRelSettings{Theme: "dark", OwnerID: authors[1].ID}); err != nil {
+		t.Fatalf("create settings: %v", err)
+	}
+
+	var found []testRelAuthor
+	err := Find(ctx, bson.D{}, &found, FindOptions{
+		Sort:     bson.D{{Key: "name", Value: 1}},
+		Populate: []string{"Posts", "Settings"},
+	})
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(found) != 3 {
+		t.Fatalf("expected 3 authors, got %d", len(found))
+	}
+	if len(found[0].Posts) != 2 || len(found[1].Posts) != 1 || len(found[2].Posts) != 0 {
+		t.Fatalf("unexpected posts per author: %d, %d, %d", len(found[0].Posts), len(found[1].Posts), len(found[2].Posts))
+	}
+	if found[0].Settings != nil || found[1].Settings == nil || found[1].Settings.Theme != "dark" {
+		t.Fatalf("expected settings only on Bob, got %+v, %+v", found[0].Settings, found[1].Settings)
+	}
+}
+
+func TestFind_PopulateOptionUnknownName(t *testing.T) {
+	ctx := useTestStore(t)
+	registerGraphModels(t)
+
+	if err := Create(ctx, &testGraphPost{Title: "hi"}); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	var found []testGraphPost
+	err := Find(ctx, bson.D{}, &found, FindOptions{Populate: []string{"title"}})
+	if err == nil || !strings.Contains(err.Error(), "cannot populate") {
+		t.Fatalf("expected populate error, got %v", err)
+	}
+}
+
+type testEmployee struct {
+	Model     `bson:",inline"`
+	Name      string        `bson:"name"`
+	ManagerID bson.ObjectID `bson:"manager" goodm:"ref=test_employees"`
+	Manager   *testEmployee `bson:"-"       goodm:"populate=manager"`
+}
+
+func registerEmployees(t *testing.T) {
+	t.Helper()
+	if err := Register(&testEmployee{}, "test_employees"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testEmployee")
+		registryMu.Unlock()
+	})
+}
+
+// createEmployees creates one employee per name, each managed by the next.
+func createEmployees(ctx context.Context, t *testing.T, names ...string) []*testEmployee {
+	t.Helper()
+	emps := make([]*testEmployee, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		emps[i] = &testEmployee{Name: names[i]}
+		if i+1 < len(names) {
+			emps[i].ManagerID = emps[i+1].ID
+		}
+		if err := Create(ctx, emps[i]); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	return emps
+}
+
+// managerChain returns the names along e's populated managers.
+func managerChain(e *testEmployee) []string {
+	var names []string
+	for m := e.Manager; m != nil; m = m.Manager {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+func TestFind_PopulateNestedPath(t *testing.T) {
+	ctx := useTestStore(t)
+	registerEmployees(t)
+	emps := createEmployees(ctx, t, "Cy", "Bob", "Ann", "Dee")
+	byID := bson.D{{Key: "_id", Value: emps[0].ID}}
+
+	found := &testEmployee{}
+	if err := FindOne(ctx, byID, found, FindOptions{Populate: []string{"manager.manager"}}); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got := managerChain(found); len(got) != 2 || got[0] != "Bob" || got[1] != "Ann" {
+		t.Fatalf("expected [Bob Ann], got %v", got)
+	}
+
+	found = &testEmployee{}
+	if err := FindOne(ctx, byID, found, FindOptions{Populate: []string{"manager*"}}); err != nil {
+		t.Fatalf("find recursive: %v", err)
+	}
+	if got := managerChain(found); len(got) != 3 || got[2] != "Dee" {
+		t.Fatalf("expected [Bob Ann Dee], got %v", got)
+	}
+
+	found = &testEmployee{}
+	if err := FindOne(ctx, byID, found, FindOptions{Populate: []string{"manager*"}, MaxPopulateDepth: 2}); err != nil {
+		t.Fatalf("find recursive with depth: %v", err)
+	}
+	if got := managerChain(found); len(got) != 2 {
+		t.Fatalf("expected the recursion to stop after 2 levels, got %v", got)
+	}
+
+	err := FindOne(ctx, byID, &testEmployee{}, FindOptions{Populate: []string{"manager.manager.manager"}, MaxPopulateDepth: 2})
+	if err == nil || !strings.Contains(err.Error(), "more than the limit of 2") {
+		t.Fatalf("expected a depth limit error, got %v", err)
+	}
+	err = FindOne(ctx, byID, &testEmployee{}, FindOptions{Populate: []string{"manager*.manager"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid populate path") {
+		t.Fatalf("expected an invalid path error, got %v", err)
+	}
+}
+
+func TestFind_PopulateCycle(t *testing.T) {
+	ctx := useTestStore(t)
+	registerEmployees(t)
+	emps := createEmployees(ctx, t, "Ann", "Bob")
+	emps[1].ManagerID = emps[0].ID
+	if err := Update(ctx, emps[1]); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	found := &testEmployee{}
+	err := FindOne(ctx, bson.D{{Key: "_id", Value: emps[0].ID}}, found, FindOptions{Populate: []string{"manager*"}, MaxPopulateDepth: 100})
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got := managerChain(found); len(got) != 2 || got[0] != "Bob" || got[1] != "Ann" {
+		t.Fatalf("expected the cycle to end after [Bob Ann], got %v", got)
+	}
+}
+
+type testSharedDoc struct {
+	Model   `bson:",inline"`
+	Title   string          `bson:"title"`
+	OwnerID bson.ObjectID   `bson:"owner" goodm:"ref=test_accounts"`
+	Owner   *testAccount    `bson:"-"     goodm:"populate=owner"`
+	NoteIDs []bson.ObjectID `bson:"notes" goodm:"ref=comments"`
+	Notes   []*testComment  `bson:"-"     goodm:"populate=notes"`
+}
+
+func TestPopulate_ScopedLikeFind(t *testing.T) {
+	ctx := useTestStore(t)
+	for model, coll := range map[interface{}]string{&testSharedDoc{}: "test_shared_docs", &testComment{}: "comments"} {
+		if err := Register(model, coll); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testSharedDoc")
+		delete(registry, "testComment")
+		registryMu.Unlock()
+		ClearPolicies()
+	})
+
+	owner := &testAccount{Username: "ann", PasswordHash: "secret"}
+	if err := Create(ctx, owner); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	kept, gone := &testComment{Body: "kept"}, &testComment{Body: "gone"}
+	for _, c := range []*testComment{kept, gone} {
+		if err := Create(ctx, c); err != nil {
+			t.Fatalf("create comment: %v", err)
+		}
+	}
+	if err := Delete(ctx, gone); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	doc := &testSharedDoc{Title: "plan", OwnerID: owner.ID, NoteIDs: []bson.ObjectID{kept.ID, gone.ID}}
+	if err := Create(ctx, doc); err != nil {
+		t.Fatalf("create doc: %v", err)
+	}
+
+	found := &testSharedDoc{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: doc.ID}}, found, FindOptions{Populate: []string{"owner", "notes"}}); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if found.Owner == nil || found.Owner.Username != "ann" || found.Owner.PasswordHash != "" {
+		t.Errorf("expected the owner without its hidden field, got %+v", found.Owner)
+	}
+	if len(found.Notes) != 1 || found.Notes[0].Body != "kept" {
+		t.Errorf("expected the soft-deleted note to be left out, got %+v", found.Notes)
+	}
+
+	var acct testAccount
+	var notes []testComment
+	if err := Populate(ctx, doc, Refs{"owner": &acct, "notes": &notes}); err != nil {
+		t.Fatalf("populate: %v", err)
+	}
+	if acct.Username != "ann" || acct.PasswordHash != "" || len(notes) != 1 {
+		t.Errorf("expected Populate to be scoped, got %+v and %d notes", acct, len(notes))
+	}
+	var accts []testAccount
+	if err := BatchPopulate(ctx, []*testSharedDoc{doc}, "owner", &accts); err != nil {
+		t.Fatalf("batch populate: %v", err)
+	}
+	if len(accts) != 1 || accts[0].PasswordHash != "" {
+		t.Errorf("expected BatchPopulate to leave out the hidden field, got %+v", accts)
+	}
+
+	if err := SetPolicy(&testAccount{}, func(ctx context.Context, op *OpInfo, actor interface{}) Decision {
+		return AllowWhere(bson.D{{Key: "username", Value: actor}})
+	}); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+	found = &testSharedDoc{}
+	if err := FindOne(WithActor(ctx, "bob"), bson.D{{Key: "_id", Value: doc.ID}}, found, FindOptions{Populate: []string{"owner"}}); err != nil {
+		t.Fatalf("find as bob: %v", err)
+	}
+	if found.Owner != nil {
+		t.Errorf("expected the owner's policy to hide it from bob, got %+v", found.Owner)
+	}
+}
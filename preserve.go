@@ -0,0 +1,77 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// unknownFieldsProjection returns an exclusion projection for every top-level
+// field the schema knows about, leaving only unmodeled fields in the result.
+func unknownFieldsProjection(schema *Schema) bson.D {
+	proj := bson.D{{Key: "_id", Value: 0}}
+	for _, f := range schema.Fields {
+		if f.BSONName != "_id" {
+			proj = append(proj, bson.E{Key: f.BSONName, Value: 0})
+		}
+		if f.RenamedFrom != "" {
+			proj = append(proj, bson.E{Key: f.RenamedFrom, Value: 0})
+		}
+	}
+	return proj
+}
+
+// loadUnknownCarry reads the stored top-level fields that have no counterpart
+// in the schema, so that a replacing Update does not destroy data written by
+// other applications or older versions of the model. Returns nil when there
+// is nothing to carry over.
+func loadUnknownCarry(ctx context.Context, coll collection, id bson.ObjectID, schema *Schema) (bson.M, error) {
+	var stored bson.M
+	err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}},
+		withComment(ctx, options.FindOne()).SetProjection(unknownFieldsProjection(schema))).Decode(&stored)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil // the save itself will report ErrNotFound
+		}
+		return nil, fmt.Errorf("goodm: failed to read unknown fields: %w", err)
+	}
+	if len(stored) == 0 {
+		return nil, nil
+	}
+	return stored, nil
+}
+
+// loadCarry collects the stored fields an Update must write back alongside the
+// model: select=false fields the caller did not load and, with
+// PreserveUnknown, fields the model does not declare.
+func loadCarry(ctx context.Context, coll collection, id bson.ObjectID, model interface{}, schema *Schema, opt UpdateOptions) (bson.M, error) {
+	carry, err := loadHiddenCarry(ctx, coll, id, model, schema)
+	if err != nil {
+		return nil, err
+	}
+	if opt.PreserveUnknown {
+		unknown, err := loadUnknownCarry(ctx, coll, id, schema)
+		if err != nil {
+			return nil, err
+		}
+		carry = mergeCarry(carry, unknown)
+	}
+	return carry, nil
+}
+
+// mergeCarry combines carried-over field sets. Later sets win on overlap.
+func mergeCarry(sets ...bson.M) bson.M {
+	var result bson.M
+	for _, set := range sets {
+		for k, v := range set {
+			if result == nil {
+				result = make(bson.M)
+			}
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package goodm
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestUnknownFieldsProjection(t *testing.T) {
+	registerTestModels()
+	defer unregisterTestModels()
+
+	schema, _ := Get("testProfile")
+	proj := unknownFieldsProjection(schema)
+
+	excluded := make(map[string]bool)
+	for _, e := range proj {
+		if e.Value != 0 {
+			t.Fatalf("expected exclusion projection, got %v", proj)
+		}
+		excluded[e.Key] = true
+	}
+	for _, key := range []string{"_id", "created_at", "updated_at", "__v", "bio"} {
+		if !excluded[key] {
+			t.Fatalf("expected %q to be excluded, got %v", key, proj)
+		}
+	}
+	if len(proj) != 5 {
+		t.Fatalf("expected 5 excluded fields, got %v", proj)
+	}
+}
+
+func TestMergeCarry(t *testing.T) {
+	if got := mergeCarry(nil, nil); got != nil {
+		t.Fatalf("expected nil, got %v", got)
+	}
+	got := mergeCarry(bson.M{"a": 1, "b": 1}, nil, bson.M{"b": 2})
+	if got["a"] != 1 || got["b"] != 2 || len(got) != 2 {
+		t.Fatalf("unexpected merge result: %v", got)
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestUpdate_PreserveUnknownFields(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	p := &testProfile{Bio: "original"}
+	if err := Create(ctx, p); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	coll := db.Collection("test_profiles")
+	if _, err := coll.UpdateOne(ctx, bson.D{{Key: "_id", Value: p.ID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "legacy_flag", Value: true}}}}); err != nil {
+		t.Fatalf("seed unknown field: %v", err)
+	}
+
+	p.Bio = "updated"
+	if err := Update(ctx, p, PreserveUnknownFields()); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	var raw bson.M
+	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: p.ID}}).Decode(&raw); err != nil {
+		t.Fatalf("read raw: %v", err)
+	}
+	if raw["legacy_flag"] != true {
+		t.Fatalf("expected legacy_flag to survive, got %v", raw["legacy_flag"])
+	}
+	if raw["bio"] != "updated" {
+		t.Fatalf("expected bio to be updated, got %v", raw["bio"])
+	}
+
+	// Without the option, Update replaces the document and drops the field.
+	p.Bio = "again"
+	if err := Update(ctx, p); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	raw = nil
+	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: p.ID}}).Decode(&raw); err != nil {
+		t.Fatalf("read raw: %v", err)
+	}
+	if _, ok := raw["legacy_flag"]; ok {
+		t.Fatal("expected legacy_flag to be removed without PreserveUnknown")
+	}
+}
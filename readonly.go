@@ -0,0 +1,188 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// RegisterView registers a read-only model backed by a MongoDB view named
+// view, defined by an aggregation pipeline over source: a registered model
+// or a collection name. Enforce creates the view, or updates its definition
+// when the pipeline changes. Find, FindOne, FindCursor, and Aggregate work
+// as for any model; Create, Update, Delete, and the other writes return a
+// *ReadOnlyError before middleware runs.
+//
+// Views have no indexes of their own, so index tags and Indexes() are
+// rejected. The in-memory test store does not compute views: there, a view
+// is an empty collection.
+//
+// Example:
+//
+//	goodm.RegisterView(&ActiveUser{}, "active_users", &User{}, bson.A{
+//	    bson.D{{Key: "$match", Value: bson.D{{Key: "active", Value: true}}}},
+//	    bson.D{{Key: "$project", Value: bson.D{{Key: "password_hash", Value: 0}}}},
+//	})
+func RegisterView(model interface{}, view string, source interface{}, pipeline interface{}) error {
+	on, ok := source.(string)
+	if !ok {
+		schema, err := getSchemaForModel(source)
+		if err != nil {
+			return fmt.Errorf("goodm: view %s: %w", view, err)
+		}
+		on = schema.Collection
+	}
+	if on == "" {
+		return fmt.Errorf("goodm: view %s requires a source collection", view)
+	}
+	if pipeline == nil {
+		pipeline = bson.A{}
+	}
+	return register(model, view, func(s *Schema) {
+		s.ViewOn = on
+		s.ViewPipeline = pipeline
+	})
+}
+
+// checkView validates a schema registered with RegisterView.
+func checkView(schema *Schema) error {
+	if schema.ViewOn == "" {
+		return nil
+	}
+	if schema.ViewOn == schema.Collection {
+		return fmt.Errorf("goodm: view %s cannot be defined on itself", schema.Collection)
+	}
+	for _, f := range schema.Fields {
+		if f.Unique || f.Index || len(f.UniqueWith) > 0 {
+			return fmt.Errorf("goodm: %s.%s: views cannot have indexes", schema.ModelName, f.Name)
+		}
+	}
+	if len(schema.CompoundIndexes) > 0 {
+		return fmt.Errorf("goodm: %s: views cannot have indexes", schema.ModelName)
+	}
+	return nil
+}
+
+// ReadOnlyOptions configures SetReadOnly.
+type ReadOnlyOptions struct {
+	// Exempt lists registered models whose writes are still allowed, such
+	// as an audit log recording the maintenance itself.
+	Exempt []interface{}
+}
+
+var (
+	readOnlyMu     sync.RWMutex
+	readOnly       bool
+	readOnlyExempt map[string]bool
+)
+
+// SetReadOnly turns read-only mode on or off, for maintenance windows and
+// disaster-recovery drills. While it is on, Create, CreateMany, Update,
+// UpdateFields, Touch, UpdateOne, UpdateMany, Delete, DeleteOne, DeleteMany,
+// CreateView, RenameCollection, DropCollection, Compact, Reindex, and
+// aggregations ending in $out or $merge fail with ErrReadOnly before
+// middleware runs, except on exempt models. Other reads,
+// RunCommand, Enforce, and migrations are not affected. Each call replaces
+// the exemptions of the previous one.
+//
+// Example:
+//
+//	err := goodm.SetReadOnly(true, goodm.ReadOnlyOptions{Exempt: []interface{}{&AuditEvent{}}})
+//	defer goodm.SetReadOnly(false)
+func SetReadOnly(on bool, opts ...ReadOnlyOptions) error {
+	var opt ReadOnlyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	exempt := make(map[string]bool, len(opt.Exempt))
+	for _, m := range opt.Exempt {
+		schema, err := getSchemaForModel(m)
+		if err != nil {
+			return err
+		}
+		exempt[schema.ModelName] = true
+	}
+
+	readOnlyMu.Lock()
+	defer readOnlyMu.Unlock()
+	readOnly = on
+	readOnlyExempt = exempt
+	return nil
+}
+
+// IsReadOnly reports whether read-only mode is on.
+func IsReadOnly() bool {
+	readOnlyMu.RLock()
+	defer readOnlyMu.RUnlock()
+	return readOnly
+}
+
+// checkWritable returns a *ReadOnlyError for write operations on a model
+// registered with RegisterView, and ErrReadOnly for writes, including
+// aggregations ending in $out or $merge, while read-only mode is on.
+func checkWritable(info *OpInfo) error {
+	switch info.Operation {
+	case OpCreate, OpUpdate, OpDelete, OpCreateMany, OpUpdateMany, OpDeleteMany:
+		if schema, ok := Get(info.ModelName); ok && schema.ViewOn != "" {
+			return &ReadOnlyError{ModelName: schema.ModelName, View: schema.Collection, Operation: info.Operation}
+		}
+	case OpCreateView, OpRenameCollection, OpDropCollection, OpCompact, OpReindex:
+	case OpAggregate:
+		if !writesOutput(info.Filter) {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	readOnlyMu.RLock()
+	defer readOnlyMu.RUnlock()
+	if readOnly && !readOnlyExempt[info.ModelName] {
+		return fmt.Errorf("goodm: %s on %s rejected: %w", info.Operation, info.ModelName, ErrReadOnly)
+	}
+	return nil
+}
+
+// enforceView creates the view of schema, or updates its definition if it
+// exists.
+func enforceView(ctx context.Context, db *mongo.Database, schema *Schema) error {
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: schema.Collection}})
+	if err != nil {
+		return &EnforcementError{
+			Collection: schema.Collection,
+			Message:    fmt.Sprintf("failed to list collections: %v", err),
+		}
+	}
+
+	if len(specs) == 0 {
+		if err := db.CreateView(ctx, schema.Collection, schema.ViewOn, schema.ViewPipeline); err != nil {
+			return &EnforcementError{
+				Collection: schema.Collection,
+				Message:    fmt.Sprintf("failed to create view: %v", err),
+			}
+		}
+		return nil
+	}
+	if specs[0].Type != "view" {
+		return &EnforcementError{
+			Collection: schema.Collection,
+			Message:    fmt.Sprintf("%s exists and is not a view", schema.Collection),
+		}
+	}
+
+	cmd := bson.D{
+		{Key: "collMod", Value: schema.Collection},
+		{Key: "viewOn", Value: schema.ViewOn},
+		{Key: "pipeline", Value: schema.ViewPipeline},
+	}
+	if err := db.RunCommand(ctx, cmd).Err(); err != nil {
+		return &EnforcementError{
+			Collection: schema.Collection,
+			Message:    fmt.Sprintf("failed to update view: %v", err),
+		}
+	}
+	return nil
+}
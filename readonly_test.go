@@ -0,0 +1,193 @@
+package goodm
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testActiveUser struct {
+	Model `bson:",inline"`
+	Email string `bson:"email"`
+	Name  string `bson:"name"`
+	Age   int    `bson:"age"`
+}
+
+type testIndexedView struct {
+	Model `bson:",inline"`
+	Email string `bson:"email" goodm:"unique"`
+}
+
+func registerActiveUsers(t *testing.T, pipeline interface{}) {
+	t.Helper()
+	if err := RegisterView(&testActiveUser{}, "test_active_users", &testUser{}, pipeline); err != nil {
+		t.Fatalf("register view: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testActiveUser")
+		registryMu.Unlock()
+	})
+}
+
+func TestRegisterView_Schema(t *testing.T) {
+	useTestStore(t)
+	registerActiveUsers(t, nil)
+
+	schema, ok := Get("testActiveUser")
+	if !ok {
+		t.Fatal("view not registered")
+	}
+	if schema.Collection != "test_active_users" || schema.ViewOn != "test_users" {
+		t.Errorf("unexpected view schema: collection %q on %q", schema.Collection, schema.ViewOn)
+	}
+	if p, ok := schema.ViewPipeline.(bson.A); !ok || len(p) != 0 {
+		t.Errorf("expected an empty pipeline, got %#v", schema.ViewPipeline)
+	}
+}
+
+func TestRegisterView_Invalid(t *testing.T) {
+	if err := RegisterView(&testIndexedView{}, "test_indexed_view", "test_users", nil); err == nil {
+		t.Error("expected a view with a unique field to be rejected")
+	}
+	if err := RegisterView(&testActiveUser{}, "test_active_users", &testActiveUser{}, nil); err == nil {
+		t.Error("expected an unregistered source model to be rejected")
+	}
+	if err := RegisterView(&testActiveUser{}, "test_active_users", "test_active_users", nil); err == nil {
+		t.Error("expected a view on itself to be rejected")
+	}
+	if _, ok := Get("testActiveUser"); ok {
+		t.Error("invalid view was registered")
+	}
+}
+
+func TestRegisterView_ReadOnly(t *testing.T) {
+	ctx := useTestStore(t)
+	registerActiveUsers(t, nil)
+
+	u := &testActiveUser{Email: "ro@test.com", Name: "RO"}
+	u.ID = bson.NewObjectID()
+	checks := map[OpType]error{
+		OpCreate: Create(ctx, u),
+		OpUpdate: Update(ctx, u),
+		OpDelete: Delete(ctx, u),
+	}
+	_, err := DeleteMany(ctx, bson.D{}, &testActiveUser{})
+	checks[OpDeleteMany] = err
+
+	for op, err := range checks {
+		var ro *ReadOnlyError
+		if !errors.As(err, &ro) {
+			t.Errorf("%s: expected *ReadOnlyError, got %v", op, err)
+			continue
+		}
+		if ro.ModelName != "testActiveUser" || ro.View != "test_active_users" || ro.Operation != op {
+			t.Errorf("%s: unexpected error fields: %+v", op, ro)
+		}
+	}
+
+	var users []testActiveUser
+	if err := Find(ctx, bson.D{}, &users); err != nil {
+		t.Errorf("find on a view: %v", err)
+	}
+}
+
+func TestRegisterView_Integration(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	registerActiveUsers(t, bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "age", Value: bson.D{{Key: "$gte", Value: 30}}}}}},
+	})
+
+	if err := Create(ctx, &testUser{Email: "old@test.com", Name: "Old", Age: 40}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := Create(ctx, &testUser{Email: "young@test.com", Name: "Young", Age: 20}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := Enforce(ctx, db); err != nil {
+		t.Fatalf("enforce: %v", err)
+	}
+
+	var users []testActiveUser
+	if err := Find(ctx, bson.D{}, &users); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(users) != 1 || users[0].Email != "old@test.com" {
+		t.Fatalf("expected the view to hold one user, got %+v", users)
+	}
+
+	// Changing the pipeline updates the view on the next Enforce.
+	schema, _ := Get("testActiveUser")
+	schema.ViewPipeline = bson.A{}
+	if _, err := Enforce(ctx, db); err != nil {
+		t.Fatalf("enforce after change: %v", err)
+	}
+	users = nil
+	if err := Find(ctx, bson.D{}, &users); err != nil || len(users) != 2 {
+		t.Fatalf("expected the updated view to hold two users, got %d (%v)", len(users), err)
+	}
+}
+
+func TestSetReadOnly(t *testing.T) {
+	ctx := useTestStore(t)
+	registerActiveUsers(t, nil)
+	if err := Register(&testOwnedDoc{}, "test_owned_docs"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = SetReadOnly(false)
+		registryMu.Lock()
+		delete(registry, "testOwnedDoc")
+		registryMu.Unlock()
+	})
+
+	u := &testUser{Email: "maint@test.com", Name: "Maint"}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if err := SetReadOnly(true, ReadOnlyOptions{Exempt: []interface{}{&testOwnedDoc{}}}); err != nil {
+		t.Fatalf("set read-only: %v", err)
+	}
+	if !IsReadOnly() {
+		t.Fatal("IsReadOnly reported false")
+	}
+
+	u.Age = 50
+	if err := Update(ctx, u); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("update: expected ErrReadOnly, got %v", err)
+	}
+	if err := Create(ctx, &testUser{Email: "new@test.com", Name: "New"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("create: expected ErrReadOnly, got %v", err)
+	}
+	if _, err := DeleteMany(ctx, bson.D{}, &testUser{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("delete many: expected ErrReadOnly, got %v", err)
+	}
+	if err := DropCollection(ctx, &testUser{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("drop: expected ErrReadOnly, got %v", err)
+	}
+	var found testUser
+	if err := FindOne(ctx, bson.D{{Key: "email", Value: "maint@test.com"}}, &found); err != nil || found.Name != "Maint" {
+		t.Errorf("expected reads to work, got %+v (%v)", found, err)
+	}
+	if err := Create(ctx, &testOwnedDoc{Owner: "ops", Title: "maintenance started"}); err != nil {
+		t.Errorf("expected exempt model writes to work, got %v", err)
+	}
+
+	// Views keep their own error, which also matches ErrReadOnly.
+	err := Create(ctx, &testActiveUser{Email: "v@test.com"})
+	var ro *ReadOnlyError
+	if !errors.As(err, &ro) || !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected a view ReadOnlyError, got %v", err)
+	}
+
+	if err := SetReadOnly(false); err != nil {
+		t.Fatalf("clear read-only: %v", err)
+	}
+	if err := Update(ctx, u); err != nil {
+		t.Errorf("update after read-only mode: %v", err)
+	}
+}
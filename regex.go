@@ -0,0 +1,270 @@
+package goodm
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Prefix returns a filter matching documents whose field starts with prefix.
+// prefix is matched literally, and the regex is anchored and case-sensitive,
+// so an index on field serves it as a range scan.
+//
+// Example:
+//
+//	err := goodm.Find(ctx, goodm.Prefix("email", "ali"), &users)
+func Prefix(field, prefix string) bson.D {
+	return bson.D{{Key: field, Value: bson.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}}}
+}
+
+// Regex returns a filter matching documents whose field matches the regular
+// expression pattern, with options such as "i" for case-insensitive matching.
+// Only a case-sensitive pattern anchored with ^ can use an index efficiently;
+// CheckRegexes and Explain report the others.
+//
+// Example:
+//
+//	err := goodm.Find(ctx, goodm.Regex("name", "^al(i|ex)", ""), &users)
+func Regex(field, pattern, options string) bson.D {
+	return bson.D{{Key: field, Value: bson.Regex{Pattern: pattern, Options: options}}}
+}
+
+// RegexWarning reports a regex in a filter that prevents efficient index use.
+type RegexWarning struct {
+	Field   string // dotted field path, or "" in source scanned by FindRegexIssues
+	Pattern string
+	Options string
+	Reason  string
+	Pos     string // file:line, for issues found by FindRegexIssues
+}
+
+func (w RegexWarning) String() string {
+	target := fmt.Sprintf("/%s/%s", w.Pattern, w.Options)
+	if w.Field != "" {
+		target = w.Field + " " + target
+	}
+	if w.Pos != "" {
+		target = w.Pos + ": " + target
+	}
+	return target + ": " + w.Reason
+}
+
+// regexIndexProblem returns why a regex cannot use an index efficiently, or
+// "" if it can: it must be anchored with ^ (or \A), must not start with a
+// wildcard, and must be case-sensitive.
+func regexIndexProblem(pattern, options string) string {
+	switch {
+	case strings.Contains(options, "i"):
+		return "case-insensitive regexes scan every index key; use a case-insensitive index (goodm:\"ci\") with an equality match"
+	case !strings.HasPrefix(pattern, "^") && !strings.HasPrefix(pattern, `\A`):
+		return "regex is not anchored with ^, so it scans every index key"
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(pattern, "^"), `\A`)
+	if strings.HasPrefix(rest, ".*") || strings.HasPrefix(rest, ".+") {
+		return "regex starts with a wildcard, so it scans every index key"
+	}
+	return ""
+}
+
+// CheckRegexes reports the regexes in filter that prevent efficient index
+// use: unanchored, wildcard-prefixed, or case-insensitive patterns. Regexes
+// given as bson.Regex values or $regex operators are found, including inside
+// $and, $or, $nor, $not, $in, and $elemMatch. It returns nil if filter holds
+// no such regex or cannot be encoded.
+//
+// Example:
+//
+//	for _, w := range goodm.CheckRegexes(goodm.Regex("email", "@example.com$", "")) {
+//	    log.Print(w) // email /@example.com$/: regex is not anchored with ^, ...
+//	}
+func CheckRegexes(filter interface{}) []RegexWarning {
+	doc, err := filterDoc(filter)
+	if err != nil {
+		return nil
+	}
+	var warnings []RegexWarning
+	collectRegexWarnings(doc, "", &warnings)
+	return warnings
+}
+
+// collectRegexWarnings appends the warnings for the field matches of doc,
+// with field paths prefixed with prefix.
+func collectRegexWarnings(doc bson.D, prefix string, warnings *[]RegexWarning) {
+	for _, e := range doc {
+		switch {
+		case e.Key == "$and" || e.Key == "$or" || e.Key == "$nor":
+			if clauses, ok := e.Value.(bson.A); ok {
+				for _, c := range clauses {
+					if cd, ok := c.(bson.D); ok {
+						collectRegexWarnings(cd, prefix, warnings)
+					}
+				}
+			}
+		case strings.HasPrefix(e.Key, "$"):
+		default:
+			collectOperandRegexWarnings(prefix+e.Key, e.Value, warnings)
+		}
+	}
+}
+
+// collectOperandRegexWarnings checks the value a field is matched against:
+// a regex, or an operator document holding regexes.
+func collectOperandRegexWarnings(field string, v interface{}, warnings *[]RegexWarning) {
+	add := func(pattern, options string) {
+		if reason := regexIndexProblem(pattern, options); reason != "" {
+			*warnings = append(*warnings, RegexWarning{Field: field, Pattern: pattern, Options: options, Reason: reason})
+		}
+	}
+	switch val := v.(type) {
+	case bson.Regex:
+		add(val.Pattern, val.Options)
+	case bson.D:
+		var options string
+		for _, op := range val {
+			if op.Key == "$options" {
+				options, _ = op.Value.(string)
+			}
+		}
+		for _, op := range val {
+			switch op.Key {
+			case "$regex":
+				switch p := op.Value.(type) {
+				case string:
+					add(p, options)
+				case bson.Regex:
+					add(p.Pattern, p.Options+options)
+				}
+			case "$not":
+				collectOperandRegexWarnings(field, op.Value, warnings)
+			case "$in", "$nin":
+				if list, ok := op.Value.(bson.A); ok {
+					for _, el := range list {
+						if re, ok := el.(bson.Regex); ok {
+							add(re.Pattern, re.Options)
+						}
+					}
+				}
+			case "$elemMatch":
+				if query, ok := op.Value.(bson.D); ok {
+					collectRegexWarnings(query, field+".", warnings)
+				}
+			}
+		}
+	}
+}
+
+// FindRegexIssues parses Go source files, given as file names mapped to
+// contents, and reports regexes written as string literals that prevent
+// efficient index use: patterns passed to goodm.Regex, bson.Regex literals,
+// and values of "$regex" keys. Patterns built at runtime are not checked.
+// Results are in source order, with files sorted by name.
+func FindRegexIssues(files map[string][]byte) ([]RegexWarning, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []RegexWarning
+	for _, name := range names {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, name, files[name], 0)
+		if err != nil {
+			return nil, fmt.Errorf("goodm: failed to parse %s: %w", name, err)
+		}
+		add := func(pos token.Pos, pattern, options string) {
+			if reason := regexIndexProblem(pattern, options); reason != "" {
+				p := fset.Position(pos)
+				issues = append(issues, RegexWarning{
+					Pattern: pattern, Options: options, Reason: reason,
+					Pos: fmt.Sprintf("%s:%d", p.Filename, p.Line),
+				})
+			}
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				// goodm.Regex(field, pattern, options)
+				if isSelector(n.Fun, "goodm", "Regex") && len(n.Args) == 3 {
+					if pattern, ok := stringLit(n.Args[1]); ok {
+						options, _ := stringLit(n.Args[2])
+						add(n.Pos(), pattern, options)
+					}
+				}
+			case *ast.CompositeLit:
+				switch {
+				case isSelector(n.Type, "bson", "Regex"):
+					// bson.Regex{Pattern: ..., Options: ...}
+					fields := keyedStrings(n)
+					if pattern, ok := fields["Pattern"]; ok {
+						add(n.Pos(), pattern, fields["Options"])
+					}
+				case n.Type == nil || isSelector(n.Type, "bson", "E"):
+					// bson.E{Key: "$regex", Value: ...}, also as an element of bson.D
+					fields := keyedStrings(n)
+					if fields["Key"] == "$regex" {
+						if pattern, ok := fields["Value"]; ok {
+							add(n.Pos(), pattern, "")
+						}
+					}
+				}
+			case *ast.KeyValueExpr:
+				// bson.M{"$regex": ...}
+				if key, ok := stringLit(n.Key); ok && key == "$regex" {
+					if pattern, ok := stringLit(n.Value); ok {
+						add(n.Pos(), pattern, "")
+					}
+				}
+			}
+			return true
+		})
+	}
+	return issues, nil
+}
+
+// isSelector reports whether e is the selector pkg.name.
+func isSelector(e ast.Expr, pkg, name string) bool {
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == pkg
+}
+
+// stringLit returns the value of a string literal expression.
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// keyedStrings returns the string literal values of a keyed composite
+// literal's fields, by field name.
+func keyedStrings(lit *ast.CompositeLit) map[string]string {
+	out := make(map[string]string)
+	for _, el := range lit.Elts {
+		kv, ok := el.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if s, ok := stringLit(kv.Value); ok {
+			out[key.Name] = s
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package goodm
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestPrefixAndRegex(t *testing.T) {
+	ctx := useTestStore(t)
+
+	for _, email := range []string{"alice@test.com", "ali.baba@test.com", "bob@test.com", "Alina@test.com"} {
+		if err := Create(ctx, &testUser{Email: email, Name: email}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	emails := func(filter bson.D) []string {
+		t.Helper()
+		var users []testUser
+		if err := Find(ctx, filter, &users); err != nil {
+			t.Fatalf("find %v: %v", filter, err)
+		}
+		var out []string
+		for _, u := range users {
+			out = append(out, u.Email)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	if got := emails(Prefix("email", "ali.")); len(got) != 1 || got[0] != "ali.baba@test.com" {
+		t.Errorf("expected the prefix to be matched literally, got %v", got)
+	}
+	if got := emails(Prefix("email", "ali")); len(got) != 2 {
+		t.Errorf("expected two case-sensitive prefix matches, got %v", got)
+	}
+	if got := emails(Regex("email", "^ali", "i")); len(got) != 3 {
+		t.Errorf("expected three case-insensitive matches, got %v", got)
+	}
+	if len(CheckRegexes(Prefix("email", "a.b*"))) != 0 {
+		t.Error("expected Prefix to be index friendly")
+	}
+}
+
+func TestCheckRegexes(t *testing.T) {
+	filter := bson.D{
+		{Key: "name", Value: bson.Regex{Pattern: "^al"}},
+		{Key: "email", Value: bson.D{{Key: "$regex", Value: "@test.com$"}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "role", Value: bson.D{{Key: "$regex", Value: "^ad"}, {Key: "$options", Value: "i"}}}},
+			bson.D{{Key: "tags", Value: bson.D{{Key: "$in", Value: bson.A{"x", bson.Regex{Pattern: "^.*y"}}}}}},
+		}},
+		{Key: "items", Value: bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "sku", Value: bson.Regex{Pattern: "^A-"}}}}}},
+	}
+	warnings := CheckRegexes(filter)
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Field)
+	}
+	if strings.Join(got, ",") != "email,role,tags" {
+		t.Fatalf("expected warnings for email, role, and tags, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0].Reason, "not anchored") || !strings.Contains(warnings[1].Reason, "case-insensitive") ||
+		!strings.Contains(warnings[2].Reason, "wildcard") {
+		t.Errorf("unexpected reasons: %v", warnings)
+	}
+	if s := warnings[0].String(); s != "email /@test.com$/: regex is not anchored with ^, so it scans every index key" {
+		t.Errorf("unexpected String(): %s", s)
+	}
+}
+
+func TestFindRegexIssues(t *testing.T) {
+	src := []byte(`package svc
+
+import (
+	"github.com/dwoolworth/goodm"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	a = goodm.Regex("email", "@example.com$", "")
+	b = goodm.Regex("email", "^ali", "")
+	c = bson.Regex{Pattern: "^x", Options: "i"}
+	d = bson.D{{Key: "name", Value: bson.D{{Key: "$regex", Value: "smith"}}}}
+	e = bson.M{"name": bson.M{"$regex": "^smith"}}
+	f = bson.M{"name": bson.M{"$regex": ".*smith"}}
+	g = goodm.Regex("email", pattern, "")
+)
+`)
+	issues, err := FindRegexIssues(map[string][]byte{"svc.go": src})
+	if err != nil {
+		t.Fatalf("FindRegexIssues: %v", err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Pos+" "+issue.Pattern)
+	}
+	want := "svc.go:9 @example.com$,svc.go:11 ^x,svc.go:12 smith,svc.go:14 .*smith"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %v", want, got)
+	}
+
+	if _, err := FindRegexIssues(map[string][]byte{"bad.go": []byte("package")}); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -12,12 +12,27 @@ import (
 var (
 	registryMu sync.RWMutex
 	registry   = map[string]*Schema{}
+	embedded   = map[reflect.Type]*EmbeddedSchema{}
+	retired    = map[string]bool{}
 )
 
+// EmbeddedSchema is the parsed representation of a shared subdocument struct
+// registered with RegisterEmbedded.
+type EmbeddedSchema struct {
+	Name   string        // Go struct name
+	Fields []FieldSchema // parsed fields, shared by every parent that embeds it
+}
+
 // Register parses a model struct and registers its schema.
 // The model should be a pointer to a struct that embeds goodm.Model.
 // The collection parameter is the MongoDB collection name.
 func Register(model interface{}, collection string) error {
+	return register(model, collection, nil)
+}
+
+// register parses and registers a model, calling configure, if set, on the
+// parsed schema before it is checked and stored.
+func register(model interface{}, collection string, configure func(*Schema)) error {
 	t := reflect.TypeOf(model)
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -26,24 +41,116 @@ func Register(model interface{}, collection string) error {
 		return fmt.Errorf("goodm: Register expects a struct, got %s", t.Kind())
 	}
 
+	if err := checkMixins(t, t.Name()); err != nil {
+		return err
+	}
+	if err := checkBaseFields(t); err != nil {
+		return err
+	}
+
 	schema := &Schema{
 		ModelName:  t.Name(),
 		Collection: collection,
+		SoftDelete: softDeleteField(t),
+		modelType:  t,
+		baseKeys:   baseKeysFor(t),
 	}
 
 	// Parse struct fields (recursively handles subdocuments)
 	schema.Fields = parseFields(t, nil)
+	if configure != nil {
+		configure(schema)
+	}
+
+	// Polymorphic collections: a kind=value tag marks the discriminator field
+	for _, f := range schema.Fields {
+		if f.Kind != "" {
+			schema.Discriminator = f.BSONName
+			schema.DiscriminatorValue = f.Kind
+			break
+		}
+	}
+
+	// Relations: ref/belongsto fields plus hasone/hasmany declarations
+	relations, err := parseRelations(t, schema.ModelName, schema.Fields)
+	if err != nil {
+		return err
+	}
+	schema.Relations = relations
 
 	// Check for Indexable interface (compound indexes)
 	if indexable, ok := model.(Indexable); ok {
 		schema.CompoundIndexes = indexable.Indexes()
 	}
 
+	// Check for Keyed interface (natural key)
+	if keyed, ok := model.(Keyed); ok {
+		schema.Keys = keyed.Keys()
+	}
+	if err := checkKeys(schema); err != nil {
+		return err
+	}
+
+	if err := checkSequences(schema); err != nil {
+		return err
+	}
+
+	if err := checkSlugs(schema); err != nil {
+		return err
+	}
+
+	if err := checkCaseInsensitive(schema); err != nil {
+		return err
+	}
+
+	if err := checkRenames(schema); err != nil {
+		return err
+	}
+
+	if err := checkUniqueWith(schema); err != nil {
+		return err
+	}
+
+	if err := checkCollations(schema); err != nil {
+		return err
+	}
+
+	if err := checkDecimals(schema); err != nil {
+		return err
+	}
+
+	if err := checkDateOnly(schema); err != nil {
+		return err
+	}
+
+	if err := checkNormalizers(schema); err != nil {
+		return err
+	}
+
+	if err := checkTree(schema); err != nil {
+		return err
+	}
+
+	if err := checkTransitions(schema); err != nil {
+		return err
+	}
+
+	if err := checkView(schema); err != nil {
+		return err
+	}
+
 	// Check for Configurable interface (per-schema collection options)
 	if configurable, ok := model.(Configurable); ok {
 		schema.CollOptions = configurable.CollectionOptions()
 	}
 
+	// Check for ConflictHandler / ConflictResolver (version conflict handling)
+	strategy, err := detectConflictStrategy(model, schema.ModelName)
+	if err != nil {
+		return err
+	}
+	schema.Conflict = strategy
+
 	// Detect hook implementations
 	schema.Hooks = detectHooks(model)
 
@@ -52,12 +159,70 @@ func Register(model interface{}, collection string) error {
 		registryMu.Unlock()
 		return fmt.Errorf("goodm: model %q is already registered", schema.ModelName)
 	}
+	if err := checkDiscriminator(schema); err != nil {
+		registryMu.Unlock()
+		return err
+	}
 	registry[schema.ModelName] = schema
 	registryMu.Unlock()
 
 	return nil
 }
 
+// RegisterEmbedded parses a shared subdocument struct once and registers it.
+// Parent models registered afterwards reuse the parsed fields (defaults,
+// validation, normalizers) for any field of that type instead of re-parsing it.
+// Register embedded types before the models that use them.
+//
+// Example:
+//
+//	goodm.RegisterEmbedded(&Address{})
+//	goodm.Register(&User{}, "users")    // User.Address uses the shared schema
+//	goodm.Register(&Company{}, "companies")
+func RegisterEmbedded(model interface{}) error {
+	t := reflect.TypeOf(model)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("goodm: RegisterEmbedded expects a struct, got %s", t.Kind())
+	}
+
+	es := &EmbeddedSchema{
+		Name:   t.Name(),
+		Fields: parseFields(t, map[reflect.Type]bool{t: true}),
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := embedded[t]; exists {
+		return fmt.Errorf("goodm: embedded type %q is already registered", es.Name)
+	}
+	embedded[t] = es
+	return nil
+}
+
+// GetEmbedded returns the registered embedded schema for a struct name, or
+// false if not found.
+func GetEmbedded(name string) (*EmbeddedSchema, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, es := range embedded {
+		if es.Name == name {
+			return es, true
+		}
+	}
+	return nil, false
+}
+
+// lookupEmbedded returns the registered embedded schema for a type, if any.
+func lookupEmbedded(t reflect.Type) (*EmbeddedSchema, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	es, ok := embedded[t]
+	return es, ok
+}
+
 // GetAll returns all registered schemas.
 func GetAll() map[string]*Schema {
 	registryMu.RLock()
@@ -85,12 +250,12 @@ func parseFields(t reflect.Type, seen map[reflect.Type]bool) []FieldSchema {
 		seen = make(map[reflect.Type]bool)
 	}
 
-	fields := internal.StructFields(t)
+	fields := internal.PromotedFields(t)
 	var result []FieldSchema
 
 	for _, f := range fields {
 		bsonTag := f.Tag.Get("bson")
-		bsonName, _ := ParseBSONTag(bsonTag)
+		bsonName, omitempty := ParseBSONTag(bsonTag)
 		if bsonName == "" {
 			bsonName = strings.ToLower(f.Name)
 		}
@@ -101,7 +266,9 @@ func parseFields(t reflect.Type, seen map[reflect.Type]bool) []FieldSchema {
 		goodmTag := f.Tag.Get("goodm")
 		fs := ParseGoodmTag(goodmTag)
 		fs.Name = f.Name
+		fs.Mixin = mixinName(f.Via)
 		fs.BSONName = bsonName
+		fs.OmitEmpty = omitempty
 		fs.Type = internal.TypeName(f.Type)
 
 		// Determine underlying type (deref pointers, unwrap slices)
@@ -115,10 +282,14 @@ func parseFields(t reflect.Type, seen map[reflect.Type]bool) []FieldSchema {
 			fieldType = fieldType.Elem()
 		}
 
-		// Recurse into struct types that aren't leaf types
+		// Recurse into struct types that aren't leaf types, reusing the
+		// shared parse of registered embedded types.
 		if fieldType.Kind() == reflect.Struct && !isLeafType(fieldType) {
 			fs.IsSlice = isSlice
-			if !seen[fieldType] {
+			if es, ok := lookupEmbedded(fieldType); ok {
+				fs.Embedded = es.Name
+				fs.SubFields = es.Fields
+			} else if !seen[fieldType] {
 				seen[fieldType] = true
 				fs.SubFields = parseFields(fieldType, seen)
 				delete(seen, fieldType)
@@ -152,5 +323,8 @@ func detectHooks(model interface{}) []string {
 	if _, ok := model.(AfterDelete); ok {
 		hooks = append(hooks, "AfterDelete")
 	}
+	if _, ok := model.(ComputeVirtuals); ok {
+		hooks = append(hooks, "ComputeVirtuals")
+	}
 	return hooks
 }
@@ -0,0 +1,196 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// reindexSuffix is appended to the name of an index to name the stand-in
+// index Reindex builds while the index is rebuilt.
+const reindexSuffix = "_goodm_reindex"
+
+// ReindexReport describes an index rebuilt by Reindex.
+type ReindexReport struct {
+	Collection string
+	Index      string        // name of the rebuilt index
+	Keys       bson.D        // its key pattern
+	StandIn    string        // name of the stand-in index, dropped once the index was verified
+	Duration   time.Duration // time taken by the whole swap
+}
+
+// Reindex rebuilds the named index of collection without leaving its
+// queries unindexed. It builds a stand-in index on the same keys followed by
+// _id, drops the index, recreates it from its stored specification, checks
+// the new index matches that specification, and drops the stand-in. It runs
+// through the middleware chain as an OpReindex, with {name: index} as
+// OpInfo.Filter, so audit middleware sees it.
+//
+// The stand-in does not enforce uniqueness or expire documents, so a unique
+// index is not enforced while it is rebuilt. If any step after the drop
+// fails, the stand-in is kept in place and the error names it. Only indexes
+// with ascending and descending keys can be rebuilt. Reindex is not
+// supported by the in-memory test store.
+//
+// Example:
+//
+//	report, err := goodm.Reindex(ctx, "users", "email_1")
+func Reindex(ctx context.Context, collection, index string, opts ...AdminOptions) (*ReindexReport, error) {
+	var opt AdminOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	var report *ReindexReport
+	err := runMiddleware(ctx, &OpInfo{
+		Operation: OpReindex, Collection: collection,
+		Filter: bson.D{{Key: "name", Value: index}}, Options: opt,
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		if db.mem != nil {
+			return fmt.Errorf("goodm: reindex: indexes are not supported by the in-memory test store")
+		}
+		report, err = reindex(ctx, db.Database, collection, index)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// reindex performs the swap of Reindex on a database.
+func reindex(ctx context.Context, db *mongo.Database, collection, index string) (*ReindexReport, error) {
+	start := time.Now()
+	if index == "_id_" {
+		return nil, fmt.Errorf("goodm: reindex %s: the _id index cannot be rebuilt", collection)
+	}
+	spec, err := indexSpec(ctx, db, collection, index)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("goodm: reindex %s: index %q not found", collection, index)
+	}
+	keys, ok := specValue(spec, "key").(bson.D)
+	if !ok {
+		return nil, fmt.Errorf("goodm: reindex %s.%s: index has no key pattern", collection, index)
+	}
+	for _, k := range keys {
+		switch k.Value.(type) {
+		case int32, int64, float64:
+		default:
+			return nil, fmt.Errorf("goodm: reindex %s.%s: %s index keys cannot be rebuilt", collection, index, k.Value)
+		}
+	}
+
+	report := &ReindexReport{Collection: collection, Index: index, Keys: keys, StandIn: index + reindexSuffix}
+	if err := createIndexSpec(ctx, db, collection, standInSpec(spec, report.StandIn)); err != nil {
+		return nil, fmt.Errorf("goodm: reindex %s.%s: failed to build stand-in index: %w", collection, index, err)
+	}
+	if standIn, err := indexSpec(ctx, db, collection, report.StandIn); err != nil || standIn == nil {
+		return nil, fmt.Errorf("goodm: reindex %s.%s: stand-in index %s was not built (%v)", collection, index, report.StandIn, err)
+	}
+
+	if err := db.Collection(collection).Indexes().DropOne(ctx, index); err != nil {
+		return nil, fmt.Errorf("goodm: reindex %s.%s: failed to drop index, stand-in %s kept: %w", collection, index, report.StandIn, err)
+	}
+	if err := createIndexSpec(ctx, db, collection, spec); err != nil {
+		return nil, fmt.Errorf("goodm: reindex %s.%s: failed to rebuild index, stand-in %s kept: %w", collection, index, report.StandIn, err)
+	}
+	rebuilt, err := indexSpec(ctx, db, collection, index)
+	if err != nil {
+		return nil, fmt.Errorf("goodm: reindex %s.%s: failed to verify index, stand-in %s kept: %w", collection, index, report.StandIn, err)
+	}
+	if !reflect.DeepEqual(comparableSpec(rebuilt), comparableSpec(spec)) {
+		return nil, fmt.Errorf("goodm: reindex %s.%s: rebuilt index %v does not match %v, stand-in %s kept",
+			collection, index, rebuilt, spec, report.StandIn)
+	}
+
+	if err := db.Collection(collection).Indexes().DropOne(ctx, report.StandIn); err != nil {
+		return nil, fmt.Errorf("goodm: reindex %s.%s: failed to drop stand-in index %s: %w", collection, index, report.StandIn, err)
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// indexSpec returns the stored specification of the named index of
+// collection, or nil if there is none.
+func indexSpec(ctx context.Context, db *mongo.Database, collection, name string) (bson.D, error) {
+	cursor, err := db.Collection(collection).Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	for cursor.Next(ctx) {
+		var spec bson.D
+		if err := cursor.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("failed to decode index: %w", err)
+		}
+		if specValue(spec, "name") == name {
+			return spec, nil
+		}
+	}
+	return nil, cursor.Err()
+}
+
+// specValue returns the value of key in an index specification.
+func specValue(spec bson.D, key string) interface{} {
+	for _, e := range spec {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+// standInSpec returns the specification of the stand-in for the index spec:
+// its keys followed by _id, so that the key patterns differ, and its
+// options except uniqueness and expiry.
+func standInSpec(spec bson.D, name string) bson.D {
+	out := bson.D{{Key: "name", Value: name}}
+	for _, e := range spec {
+		switch e.Key {
+		case "name", "ns", "v", "unique", "expireAfterSeconds":
+		case "key":
+			keys := append(bson.D{}, e.Value.(bson.D)...)
+			if specValue(keys, "_id") == nil {
+				keys = append(keys, bson.E{Key: "_id", Value: int32(1)})
+			}
+			out = append(out, bson.E{Key: "key", Value: keys})
+		default:
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// comparableSpec returns spec without the fields the server may set
+// differently on a rebuilt index.
+func comparableSpec(spec bson.D) bson.D {
+	var out bson.D
+	for _, e := range spec {
+		if e.Key != "ns" && e.Key != "v" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// createIndexSpec creates an index of collection from a stored
+// specification, keeping every option it carries.
+func createIndexSpec(ctx context.Context, db *mongo.Database, collection string, spec bson.D) error {
+	return db.RunCommand(ctx, bson.D{
+		{Key: "createIndexes", Value: collection},
+		{Key: "indexes", Value: bson.A{comparableSpec(spec)}},
+	}).Err()
+}
@@ -0,0 +1,102 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestReindex(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	coll := db.Collection("reindexed")
+	if _, err := coll.InsertOne(ctx, bson.D{{Key: "email", Value: "a@test.com"}}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetName("email_1").SetUnique(true).SetSparse(true),
+	}); err != nil {
+		t.Fatalf("create index: %v", err)
+	}
+	before, err := indexSpec(ctx, db, "reindexed", "email_1")
+	if err != nil || before == nil {
+		t.Fatalf("index spec: %v, %v", before, err)
+	}
+
+	report, err := Reindex(ctx, "reindexed", "email_1", AdminOptions{DB: db})
+	if err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+	if report.Index != "email_1" || report.StandIn != "email_1_goodm_reindex" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	after, err := indexSpec(ctx, db, "reindexed", "email_1")
+	if err != nil || !reflect.DeepEqual(comparableSpec(after), comparableSpec(before)) {
+		t.Errorf("expected the rebuilt index %v to match %v (%v)", after, before, err)
+	}
+	if standIn, _ := indexSpec(ctx, db, "reindexed", report.StandIn); standIn != nil {
+		t.Errorf("expected the stand-in to be dropped, got %v", standIn)
+	}
+
+	if _, err := Reindex(ctx, "reindexed", "missing_1", AdminOptions{DB: db}); err == nil {
+		t.Error("expected a missing index to fail")
+	}
+	if _, err := Reindex(ctx, "reindexed", "_id_", AdminOptions{DB: db}); err == nil {
+		t.Error("expected the _id index to be refused")
+	}
+}
+
+func TestReindex_TestStore(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+
+	var seen *OpInfo
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		seen = op
+		return next(ctx)
+	})
+	if _, err := Reindex(ctx, "users", "email_1"); err == nil {
+		t.Error("expected Reindex to fail on the test store")
+	}
+	if seen == nil || seen.Operation != OpReindex || seen.Collection != "users" {
+		t.Fatalf("expected middleware to see the reindex, got %+v", seen)
+	}
+	if !reflect.DeepEqual(seen.Filter, bson.D{{Key: "name", Value: "email_1"}}) {
+		t.Errorf("expected the index name in Filter, got %v", seen.Filter)
+	}
+
+	if err := SetReadOnly(true); err != nil {
+		t.Fatalf("set read-only: %v", err)
+	}
+	t.Cleanup(func() { _ = SetReadOnly(false) })
+	if _, err := Reindex(ctx, "users", "email_1"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected Reindex to be rejected in read-only mode, got %v", err)
+	}
+}
+
+func TestStandInSpec(t *testing.T) {
+	spec := bson.D{
+		{Key: "v", Value: int32(2)},
+		{Key: "key", Value: bson.D{{Key: "email", Value: int32(1)}}},
+		{Key: "name", Value: "email_1"},
+		{Key: "unique", Value: true},
+		{Key: "expireAfterSeconds", Value: int32(60)},
+		{Key: "partialFilterExpression", Value: bson.D{{Key: "active", Value: true}}},
+	}
+	want := bson.D{
+		{Key: "name", Value: "email_1_goodm_reindex"},
+		{Key: "key", Value: bson.D{{Key: "email", Value: int32(1)}, {Key: "_id", Value: int32(1)}}},
+		{Key: "partialFilterExpression", Value: bson.D{{Key: "active", Value: true}}},
+	}
+	if got := standInSpec(spec, "email_1_goodm_reindex"); !reflect.DeepEqual(got, want) {
+		t.Errorf("standInSpec = %v, want %v", got, want)
+	}
+}
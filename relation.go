@@ -0,0 +1,274 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dwoolworth/goodm/internal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RelationKind identifies the direction of a relation between two models.
+type RelationKind string
+
+const (
+	// RelationBelongsTo means this model holds the related document's ID in
+	// one of its fields (goodm:"ref=..." or goodm:"belongsto=...").
+	RelationBelongsTo RelationKind = "belongs_to"
+
+	// RelationHasOne means one related document holds this model's ID in its
+	// foreign key field (goodm:"hasone=...,fk=...").
+	RelationHasOne RelationKind = "has_one"
+
+	// RelationHasMany means any number of related documents hold this model's
+	// ID in their foreign key field (goodm:"hasmany=...,fk=...").
+	RelationHasMany RelationKind = "has_many"
+)
+
+// OnDelete is what Delete does with the documents of a has_one or has_many
+// relation when the owning document is deleted.
+type OnDelete string
+
+const (
+	// OnDeleteNone leaves related documents untouched. This is the default.
+	OnDeleteNone OnDelete = ""
+
+	// OnDeleteCascade deletes the related documents and applies their own
+	// ondelete rules in turn.
+	OnDeleteCascade OnDelete = "cascade"
+
+	// OnDeleteNullify removes the foreign key field from related documents.
+	OnDeleteNullify OnDelete = "nullify"
+
+	// OnDeleteRestrict refuses to delete a document that still has related
+	// documents, returning ErrDeleteRestricted.
+	OnDeleteRestrict OnDelete = "restrict"
+)
+
+// ErrDeleteRestricted is returned by Delete when an ondelete=restrict
+// relation still has related documents.
+var ErrDeleteRestricted = errors.New("goodm: delete restricted by related documents")
+
+// Relation describes a relation declared on a model.
+type Relation struct {
+	Name       string       `json:"name"`                  // Go field declaring the relation
+	Kind       RelationKind `json:"kind"`                  // direction of the relation
+	Collection string       `json:"collection"`            // related collection
+	LocalField string       `json:"local_field,omitempty"` // bson field on this model holding the related ID (belongs_to)
+	ForeignKey string       `json:"foreign_key,omitempty"` // bson field on the related model holding this model's ID (has_one/has_many)
+	OnDelete   OnDelete     `json:"on_delete,omitempty"`   // what Delete does with related documents (has_one/has_many)
+}
+
+// GetRelation returns the relation declared by the Go field name, or nil.
+func (s *Schema) GetRelation(name string) *Relation {
+	for i := range s.Relations {
+		if s.Relations[i].Name == name {
+			return &s.Relations[i]
+		}
+	}
+	return nil
+}
+
+// parseRelations collects the relations declared on model type t. belongs_to
+// relations come from the ref fields in fields; has_one and has_many are
+// declared on non-persisted fields:
+//
+//	Posts []Post `bson:"-" goodm:"hasmany=posts,fk=author,ondelete=cascade"`
+func parseRelations(t reflect.Type, modelName string, fields []FieldSchema) ([]Relation, error) {
+	var relations []Relation
+	for _, f := range fields {
+		if f.Ref != "" {
+			relations = append(relations, Relation{
+				Name: f.Name, Kind: RelationBelongsTo, Collection: f.Ref, LocalField: f.BSONName,
+			})
+		}
+	}
+
+	for _, sf := range internal.StructFields(t) {
+		tag := relationTag(sf.Tag.Get("goodm"))
+		var rel Relation
+		switch {
+		case tag["hasmany"] != "":
+			rel = Relation{Kind: RelationHasMany, Collection: tag["hasmany"]}
+		case tag["hasone"] != "":
+			rel = Relation{Kind: RelationHasOne, Collection: tag["hasone"]}
+		default:
+			continue
+		}
+		rel.Name = sf.Name
+		rel.ForeignKey = tag["fk"]
+		rel.OnDelete = OnDelete(tag["ondelete"])
+
+		if rel.ForeignKey == "" {
+			return nil, fmt.Errorf("goodm: %s.%s: %s relation requires fk=<field>", modelName, sf.Name, rel.Kind)
+		}
+		switch rel.OnDelete {
+		case OnDeleteNone, OnDeleteCascade, OnDeleteNullify, OnDeleteRestrict:
+		default:
+			return nil, fmt.Errorf("goodm: %s.%s: unknown ondelete rule %q", modelName, sf.Name, rel.OnDelete)
+		}
+		relations = append(relations, rel)
+	}
+	return relations, nil
+}
+
+// relationTag returns the relation directives in a goodm tag.
+func relationTag(tag string) map[string]string {
+	result := map[string]string{}
+	for _, part := range strings.Split(tag, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "hasmany", "hasone", "fk", "ondelete":
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// populateRelation fetches the documents of a has_one or has_many relation,
+// those whose foreign key holds id.
+func populateRelation(ctx context.Context, db dbHandle, rel *Relation, id bson.ObjectID, target interface{}) error {
+	coll := namedCollection(db, rel.Collection)
+	filter, proj, err := refScope(ctx, target, bson.D{{Key: rel.ForeignKey, Value: id}})
+	if err != nil {
+		return err
+	}
+
+	if rel.Kind == RelationHasOne {
+		if err := coll.FindOne(ctx, filter, withComment(ctx, options.FindOne().SetProjection(proj))).Decode(target); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil
+			}
+			return fmt.Errorf("goodm: populate %q failed: %w", rel.Name, err)
+		}
+		return afterLoad(ctx, target)
+	}
+
+	cursor, err := coll.Find(ctx, filter, withComment(ctx, options.Find().SetProjection(proj)))
+	if err != nil {
+		return fmt.Errorf("goodm: populate %q failed: %w", rel.Name, err)
+	}
+	if err := cursor.All(ctx, target); err != nil {
+		_ = cursor.Close(ctx)
+		return fmt.Errorf("goodm: populate %q decode failed: %w", rel.Name, err)
+	}
+	_ = cursor.Close(ctx)
+	return afterLoad(ctx, target)
+}
+
+// deleteRules returns the has_one/has_many relations with an ondelete rule
+// declared by the models stored in collection.
+func deleteRules(collection string) []Relation {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	var rules []Relation
+	for _, s := range registry {
+		if s.Collection != collection {
+			continue
+		}
+		for _, rel := range s.Relations {
+			if rel.Kind != RelationBelongsTo && rel.OnDelete != OnDeleteNone {
+				rules = append(rules, rel)
+			}
+		}
+	}
+	return rules
+}
+
+// checkDeleteRestrict returns ErrDeleteRestricted if deleting the documents
+// with ids from collection would orphan documents of a restrict relation,
+// following cascade relations down the graph. seen holds the documents
+// already visited, so cyclic data terminates.
+func checkDeleteRestrict(ctx context.Context, db dbHandle, collection string, ids []bson.ObjectID, seen map[bson.ObjectID]bool) error {
+	for _, rel := range deleteRules(collection) {
+		filter := bson.D{{Key: rel.ForeignKey, Value: bson.D{{Key: "$in", Value: ids}}}}
+		coll := namedCollection(db, rel.Collection)
+		switch rel.OnDelete {
+		case OnDeleteRestrict:
+			n, err := coll.CountDocuments(ctx, filter, withComment(ctx, options.Count().SetLimit(1)))
+			if err != nil {
+				return fmt.Errorf("goodm: delete restrict check on %s failed: %w", rel.Collection, err)
+			}
+			if n > 0 {
+				return fmt.Errorf("%w: %s.%s", ErrDeleteRestricted, rel.Collection, rel.ForeignKey)
+			}
+		case OnDeleteCascade:
+			childIDs, err := relatedIDs(ctx, coll, filter)
+			if err != nil {
+				return err
+			}
+			var unseen []bson.ObjectID
+			for _, id := range childIDs {
+				if !seen[id] {
+					seen[id] = true
+					unseen = append(unseen, id)
+				}
+			}
+			if len(unseen) > 0 {
+				if err := checkDeleteRestrict(ctx, db, rel.Collection, unseen, seen); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyDeleteRules cascades or nullifies the documents related to the
+// deleted documents with ids from collection.
+func applyDeleteRules(ctx context.Context, db dbHandle, collection string, ids []bson.ObjectID) error {
+	for _, rel := range deleteRules(collection) {
+		filter := bson.D{{Key: rel.ForeignKey, Value: bson.D{{Key: "$in", Value: ids}}}}
+		coll := namedCollection(db, rel.Collection)
+		switch rel.OnDelete {
+		case OnDeleteNullify:
+			update := bson.D{{Key: "$unset", Value: bson.D{{Key: rel.ForeignKey, Value: ""}}}}
+			if _, err := coll.UpdateMany(ctx, filter, update, withComment(ctx, options.UpdateMany())); err != nil {
+				return fmt.Errorf("goodm: nullify %s.%s failed: %w", rel.Collection, rel.ForeignKey, err)
+			}
+		case OnDeleteCascade:
+			childIDs, err := relatedIDs(ctx, coll, filter)
+			if err != nil {
+				return err
+			}
+			if len(childIDs) == 0 {
+				continue
+			}
+			if _, err := coll.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: childIDs}}}},
+				withComment(ctx, options.DeleteMany())); err != nil {
+				return fmt.Errorf("goodm: cascade delete on %s failed: %w", rel.Collection, err)
+			}
+			if err := applyDeleteRules(ctx, db, rel.Collection, childIDs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// relatedIDs returns the IDs of the documents in coll matching filter.
+func relatedIDs(ctx context.Context, coll collection, filter bson.D) ([]bson.ObjectID, error) {
+	cursor, err := coll.Find(ctx, filter, withComment(ctx, options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}})))
+	if err != nil {
+		return nil, fmt.Errorf("goodm: related documents lookup failed: %w", err)
+	}
+	var docs []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("goodm: related documents lookup failed: %w", err)
+	}
+	ids := make([]bson.ObjectID, len(docs))
+	for i, d := range docs {
+		ids[i] = d.ID
+	}
+	return ids, nil
+}
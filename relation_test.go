@@ -0,0 +1,265 @@
+package goodm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testRelAuthor struct {
+	Model    `bson:",inline"`
+	Name     string           `bson:"name"`
+	Posts    []testRelPost    `bson:"-" goodm:"hasmany=test_rel_posts,fk=author,ondelete=cascade"`
+	Settings *testRelSettings `bson:"-" goodm:"hasone=test_rel_settings,fk=owner,ondelete=nullify"`
+	Invoices []testRelInvoice `bson:"-" goodm:"hasmany=test_rel_invoices,fk=author,ondelete=restrict"`
+}
+
+type testRelPost struct {
+	Model    `bson:",inline"`
+	Title    string           `bson:"title"`
+	AuthorID bson.ObjectID    `bson:"author" goodm:"belongsto=test_rel_authors"`
+	Comments []testRelComment `bson:"-" goodm:"hasmany=test_rel_comments,fk=post,ondelete=cascade"`
+}
+
+type testRelComment struct {
+	Model  `bson:",inline"`
+	Body   string        `bson:"body"`
+	PostID bson.ObjectID `bson:"post" goodm:"ref=test_rel_posts"`
+}
+
+type testRelSettings struct {
+	Model   `bson:",inline"`
+	Theme   string        `bson:"theme"`
+	OwnerID bson.ObjectID `bson:"owner" goodm:"ref=test_rel_authors"`
+}
+
+type testRelInvoice struct {
+	Model    `bson:",inline"`
+	AuthorID bson.ObjectID `bson:"author" goodm:"ref=test_rel_authors"`
+}
+
+type testRelMissingFK struct {
+	Model `bson:",inline"`
+	Posts []testRelPost `bson:"-" goodm:"hasmany=test_rel_posts"`
+}
+
+func registerRelationModels(t *testing.T) {
+	t.Helper()
+	models := []struct {
+		model interface{}
+		coll  string
+	}{
+		{&testRelAuthor{}, "test_rel_authors"},
+		{&testRelPost{}, "test_rel_posts"},
+		{&testRelComment{}, "test_rel_comments"},
+		{&testRelSettings{}, "test_rel_settings"},
+		{&testRelInvoice{}, "test_rel_invoices"},
+	}
+	for _, m := range models {
+		if err := Register(m.model, m.coll); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		for _, name := range []string{"testRelAuthor", "testRelPost", "testRelComment", "testRelSettings", "testRelInvoice"} {
+			delete(registry, name)
+		}
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister_Relations(t *testing.T) {
+	useTestStore(t)
+	registerRelationModels(t)
+
+	author, _ := Get("testRelAuthor")
+	posts := author.GetRelation("Posts")
+	if posts == nil || posts.Kind != RelationHasMany || posts.Collection != "test_rel_posts" ||
+		posts.ForeignKey != "author" || posts.OnDelete != OnDeleteCascade {
+		t.Fatalf("unexpected Posts relation: %+v", posts)
+	}
+	if s := author.GetRelation("Settings"); s == nil || s.Kind != RelationHasOne || s.OnDelete != OnDeleteNullify {
+		t.Fatalf("unexpected Settings relation: %+v", s)
+	}
+
+	post, _ := Get("testRelPost")
+	belongs := post.GetRelation("AuthorID")
+	if belongs == nil || belongs.Kind != RelationBelongsTo || belongs.Collection != "test_rel_authors" || belongs.LocalField != "author" {
+		t.Fatalf("unexpected belongs_to relation: %+v", belongs)
+	}
+	if f := post.GetField("author"); f == nil || f.Ref != "test_rel_authors" {
+		t.Fatal("expected belongsto to set the field's Ref")
+	}
+	if post.HasField("Comments") || post.HasField("comments") {
+		t.Fatal("expected has_many field not to be persisted")
+	}
+
+	err := Register(&testRelMissingFK{}, "test_rel_missing")
+	if err == nil || !strings.Contains(err.Error(), "fk=") {
+		t.Fatalf("expected error for has_many without fk, got %v", err)
+	}
+}
+
+func TestPopulate_HasRelations(t *testing.T) {
+	ctx := useTestStore(t)
+	registerRelationModels(t)
+
+	author := &testRelAuthor{Name: "Ann"}
+	if err := Create(ctx, author); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for _, title := range []string{"one", "two"} {
+		if err := Create(ctx, &testRelPost{Title: title, AuthorID: author.ID}); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+	if err := Create(ctx, &testRelSettings{Theme: "dark", OwnerID: author.ID}); err != nil {
+		t.Fatalf("create settings: %v", err)
+	}
+
+	settings := &testRelSettings{}
+	if err := Populate(ctx, author, Refs{"Posts": &author.Posts, "Settings": settings}); err != nil {
+		t.Fatalf("populate: %v", err)
+	}
+	if len(author.Posts) != 2 || settings.Theme != "dark" {
+		t.Fatalf("expected 2 posts and settings, got %d posts, %+v", len(author.Posts), settings)
+	}
+}
+
+func TestDelete_RelationRules(t *testing.T) {
+	ctx := useTestStore(t)
+	registerRelationModels(t)
+
+	author := &testRelAuthor{Name: "Ann"}
+	other := &testRelAuthor{Name: "Bob"}
+	for _, a := range []*testRelAuthor{author, other} {
+		if err := Create(ctx, a); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	post := &testRelPost{Title: "one", AuthorID: author.ID}
+	if err := Create(ctx, post); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	if err := Create(ctx, &testRelPost{Title: "kept", AuthorID: other.ID}); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	if err := Create(ctx, &testRelComment{Body: "hi", PostID: post.ID}); err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	settings := &testRelSettings{Theme: "dark", OwnerID: author.ID}
+	if err := Create(ctx, settings); err != nil {
+		t.Fatalf("create settings: %v", err)
+	}
+	invoice := &testRelInvoice{AuthorID: author.ID}
+	if err := Create(ctx, invoice); err != nil {
+		t.Fatalf("create invoice: %v", err)
+	}
+
+	if err := Delete(ctx, author); !errors.Is(err, ErrDeleteRestricted) {
+		t.Fatalf("expected ErrDeleteRestricted while an invoice exists, got %v", err)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: author.ID}}, &testRelAuthor{}); err != nil {
+		t.Fatalf("expected restricted author to remain: %v", err)
+	}
+
+	if err := Delete(ctx, invoice); err != nil {
+		t.Fatalf("delete invoice: %v", err)
+	}
+	if err := Delete(ctx, author); err != nil {
+		t.Fatalf("delete author: %v", err)
+	}
+
+	var posts []testRelPost
+	if err := Find(ctx, bson.D{}, &posts); err != nil {
+		t.Fatalf("find posts: %v", err)
+	}
+	if len(posts) != 1 || posts[0].Title != "kept" {
+		t.Fatalf("expected only the other author's post to remain, got %+v", posts)
+	}
+	var comments []testRelComment
+	if err := Find(ctx, bson.D{}, &comments); err != nil || len(comments) != 0 {
+		t.Fatalf("expected comments to be cascaded, got %d, %v", len(comments), err)
+	}
+	found := &testRelSettings{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: settings.ID}}, found); err != nil {
+		t.Fatalf("expected settings to be kept: %v", err)
+	}
+	if !found.OwnerID.IsZero() {
+		t.Fatalf("expected settings owner to be nullified, got %v", found.OwnerID)
+	}
+}
+
+func TestGenerateERD(t *testing.T) {
+	useTestStore(t)
+	registerRelationModels(t)
+
+	schemas := map[string]*Schema{}
+	for _, name := range []string{"testRelAuthor", "testRelPost", "testRelComment"} {
+		s, _ := Get(name)
+		schemas[name] = s
+	}
+	erd := GenerateERD(schemas)
+
+	for _, want := range []string{
+		"erDiagram",
+		"    testRelPost {\n",
+		"        bson_ObjectID author FK\n",
+		`    testRelAuthor ||--o{ testRelPost : "Posts"`,
+		`    testRelPost ||--o{ testRelComment : "Comments"`,
+	} {
+		if !strings.Contains(erd, want) {
+			t.Fatalf("expected ERD to contain %q, got:\n%s", want, erd)
+		}
+	}
+	if strings.Count(erd, "testRelPost : ") != 1 {
+		t.Fatalf("expected the author/post relation to be drawn once, got:\n%s", erd)
+	}
+}
+
+func TestInferRelations(t *testing.T) {
+	colls := []DiscoveredCollection{
+		{Name: "users", Fields: []DiscoveredField{{BSONName: "_id", GoType: "bson.ObjectID"}}},
+		{Name: "tags"},
+		{Name: "blog_posts", Fields: []DiscoveredField{
+			{BSONName: "author_id", GoType: "bson.ObjectID"},
+			{BSONName: "tag_ids", GoType: "[]bson.ObjectID"},
+			{BSONName: "reviewer", GoType: "string"},
+		}},
+	}
+	InferRelations(colls)
+
+	fields := colls[2].Fields
+	if fields[0].Ref != "" || fields[1].Ref != "tags" || fields[2].Ref != "" {
+		t.Fatalf("unexpected refs: %+v", fields)
+	}
+
+	colls[2].Fields[0].BSONName = "user_id"
+	colls[0].Relations, colls[1].Relations = nil, nil
+	InferRelations(colls)
+	if colls[2].Fields[0].Ref != "users" {
+		t.Fatalf("expected user_id to reference users, got %q", colls[2].Fields[0].Ref)
+	}
+	want := DiscoveredRelation{Name: "BlogPosts", Collection: "blog_posts", ForeignKey: "user_id"}
+	if len(colls[0].Relations) != 1 || colls[0].Relations[0] != want {
+		t.Fatalf("expected inverse has_many on users, got %+v", colls[0].Relations)
+	}
+
+	src, err := GenerateModel(colls[0], GenerateOptions{EmbedModel: true})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if !strings.Contains(strings.Join(strings.Fields(string(src)), " "), "BlogPosts []BlogPost `bson:\"-\" goodm:\"hasmany=blog_posts,fk=user_id\"`") {
+		t.Fatalf("expected has_many field in generated model, got:\n%s", src)
+	}
+	src, err = GenerateModel(colls[2], GenerateOptions{EmbedModel: true})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if !strings.Contains(string(src), `goodm:"ref=users"`) {
+		t.Fatalf("expected ref tag in generated model, got:\n%s", src)
+	}
+}
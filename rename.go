@@ -0,0 +1,219 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// defaultBackfillBatch is the number of documents BackfillRenames reads per
+// batch when no BatchSize is set.
+const defaultBackfillBatch = 1000
+
+// checkRenames validates the renamed_from tags of schema's fields. Renames
+// are supported on top-level fields only, and the old name must not be
+// another field of the model.
+func checkRenames(schema *Schema) error {
+	for _, f := range schema.Fields {
+		if hasNestedRename(f.SubFields) {
+			return fmt.Errorf("goodm: %s.%s: renamed_from is only supported on top-level fields", schema.ModelName, f.Name)
+		}
+		if f.RenamedFrom == "" {
+			continue
+		}
+		if f.RenamedFrom == f.BSONName {
+			return fmt.Errorf("goodm: %s.%s: renamed_from names the field itself", schema.ModelName, f.Name)
+		}
+		if schema.base().managed(f.RenamedFrom) || schema.HasField(f.RenamedFrom) {
+			return fmt.Errorf("goodm: %s.%s: renamed_from field %q collides with a model field", schema.ModelName, f.Name, f.RenamedFrom)
+		}
+		for _, other := range schema.Fields {
+			if other.Name != f.Name && (other.RenamedFrom == f.RenamedFrom || shadowName(other) == f.RenamedFrom) {
+				return fmt.Errorf("goodm: %s.%s: renamed_from field %q is also used by %s", schema.ModelName, f.Name, f.RenamedFrom, other.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// hasNestedRename reports whether any of fields, or their subfields, has a
+// renamed_from tag.
+func hasNestedRename(fields []FieldSchema) bool {
+	for _, f := range fields {
+		if f.RenamedFrom != "" || hasNestedRename(f.SubFields) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRenamedFields returns true if any field in the schema uses renamed_from.
+func hasRenamedFields(schema *Schema) bool {
+	for _, f := range schema.Fields {
+		if f.RenamedFrom != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// renameWrites copies the value of each renamed field in doc, a marshaled
+// model, to the field's old name, so code that still reads the old name
+// sees the write.
+func renameWrites(schema *Schema, doc bson.M) {
+	for _, f := range schema.Fields {
+		if f.RenamedFrom == "" {
+			continue
+		}
+		if v, ok := doc[f.BSONName]; ok {
+			doc[f.RenamedFrom] = v
+		}
+	}
+}
+
+// renameUpdate adds the old names of the renamed fields an UpdateFields call
+// sets or unsets, and returns the $unset document.
+func renameUpdate(schema *Schema, set, unset bson.M) bson.M {
+	for _, f := range schema.Fields {
+		if f.RenamedFrom == "" {
+			continue
+		}
+		if v, ok := set[f.BSONName]; ok {
+			set[f.RenamedFrom] = v
+		} else if _, ok := unset[f.BSONName]; ok {
+			unset[f.RenamedFrom] = ""
+		}
+	}
+	return unset
+}
+
+// renameFallback fills the renamed fields of target, a pointer to a decoded
+// model, from their old names when raw, the stored document, only has the
+// old name: documents written before the rename, or by code that does not
+// know it yet.
+func renameFallback(schema *Schema, raw bson.Raw, target interface{}) error {
+	var fill bson.D
+	for _, f := range schema.Fields {
+		if f.RenamedFrom == "" {
+			continue
+		}
+		if _, err := raw.LookupErr(f.BSONName); err == nil {
+			continue
+		}
+		if old, err := raw.LookupErr(f.RenamedFrom); err == nil {
+			fill = append(fill, bson.E{Key: f.BSONName, Value: old})
+		}
+	}
+	if len(fill) == 0 {
+		return nil
+	}
+	data, err := marshalBSON(fill)
+	if err != nil {
+		return fmt.Errorf("goodm: failed to read renamed fields: %w", err)
+	}
+	if err := unmarshalBSON(data, target); err != nil {
+		return fmt.Errorf("goodm: failed to read renamed fields: %w", err)
+	}
+	return nil
+}
+
+// renameFallbackFunc returns renameFallback bound to schema, or nil when the
+// schema has no renamed fields.
+func renameFallbackFunc(schema *Schema) func(raw bson.Raw, target interface{}) error {
+	if !hasRenamedFields(schema) {
+		return nil
+	}
+	return func(raw bson.Raw, target interface{}) error {
+		return renameFallback(schema, raw, target)
+	}
+}
+
+// BackfillOptions configures the BackfillRenames operation.
+type BackfillOptions struct {
+	DB        *mongo.Database
+	BatchSize int // documents read per batch (default 1000)
+}
+
+// BackfillRenames copies the value of every field tagged renamed_from from
+// its old name to its new one, in each document of model's collection that
+// only has the old name, and returns the number of documents updated. Run it
+// once every instance writes both names; afterwards, queries can filter on
+// the new name. Hooks and middleware do not run.
+//
+// Example:
+//
+//	n, err := goodm.BackfillRenames(ctx, &Customer{})
+func BackfillRenames(ctx context.Context, model interface{}, opts ...BackfillOptions) (int64, error) {
+	var opt BackfillOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return 0, err
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = defaultBackfillBatch
+	}
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return 0, err
+	}
+
+	coll := getCollection(db, schema)
+	var updated int64
+	for _, f := range schema.Fields {
+		if f.RenamedFrom == "" {
+			continue
+		}
+		missing := bson.D{
+			{Key: f.BSONName, Value: bson.D{{Key: "$exists", Value: false}}},
+			{Key: f.RenamedFrom, Value: bson.D{{Key: "$exists", Value: true}}},
+		}
+		findOpts := withComment(ctx, options.Find()).
+			SetProjection(bson.D{{Key: f.RenamedFrom, Value: 1}}).
+			SetLimit(int64(opt.BatchSize))
+		for {
+			cur, err := coll.Find(ctx, scopeFilter(withDeleted(ctx), schema, missing), findOpts)
+			if err != nil {
+				return updated, fmt.Errorf("goodm: backfill %s.%s failed: %w", schema.Collection, f.BSONName, err)
+			}
+			var batch []bson.Raw
+			if err := cur.All(ctx, &batch); err != nil {
+				return updated, fmt.Errorf("goodm: backfill %s.%s failed: %w", schema.Collection, f.BSONName, err)
+			}
+			if len(batch) == 0 {
+				break
+			}
+			var modified int64
+			for _, doc := range batch {
+				var id, value interface{}
+				if err := doc.Lookup("_id").Unmarshal(&id); err != nil {
+					return updated, fmt.Errorf("goodm: backfill %s.%s failed: %w", schema.Collection, f.BSONName, err)
+				}
+				if err := doc.Lookup(f.RenamedFrom).Unmarshal(&value); err != nil {
+					return updated, fmt.Errorf("goodm: backfill %s.%s failed: %w", schema.Collection, f.BSONName, err)
+				}
+				filter := bson.D{
+					{Key: "_id", Value: id},
+					{Key: f.BSONName, Value: bson.D{{Key: "$exists", Value: false}}},
+				}
+				res, err := coll.UpdateOne(ctx, filter,
+					bson.D{{Key: "$set", Value: bson.D{{Key: f.BSONName, Value: value}}}},
+					withComment(ctx, options.UpdateOne()))
+				if err != nil {
+					return updated, fmt.Errorf("goodm: backfill %s.%s failed: %w", schema.Collection, f.BSONName, err)
+				}
+				modified += res.ModifiedCount
+			}
+			if modified == 0 {
+				break // nothing left that this filter can change
+			}
+			updated += modified
+		}
+	}
+	return updated, nil
+}
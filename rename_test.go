@@ -0,0 +1,162 @@
+package goodm
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testRenamedCustomer struct {
+	Model     `bson:",inline"`
+	Name      string `bson:"name"`
+	PhoneE164 string `bson:"phone_e164,omitempty" goodm:"renamed_from=phone"`
+}
+
+func registerRenamedCustomer(t *testing.T) {
+	t.Helper()
+	if err := Register(&testRenamedCustomer{}, "test_renamed_customers"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testRenamedCustomer")
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister_RenamedFromCollision(t *testing.T) {
+	type collides struct {
+		Model `bson:",inline"`
+		Phone string `bson:"phone"`
+		E164  string `bson:"phone_e164" goodm:"renamed_from=phone"`
+	}
+	if err := Register(&collides{}, "test_collides"); err == nil {
+		t.Fatal("expected renamed_from naming a model field to be rejected")
+	}
+
+	type nested struct {
+		Model   `bson:",inline"`
+		Address struct {
+			Zip string `bson:"zip" goodm:"renamed_from=postcode"`
+		} `bson:"address"`
+	}
+	if err := Register(&nested{}, "test_nested_renames"); err == nil {
+		t.Fatal("expected renamed_from on a subfield to be rejected")
+	}
+}
+
+func TestRename_DualWrite(t *testing.T) {
+	ctx := useTestStore(t)
+	registerRenamedCustomer(t)
+	coll := activeTestStore().Collection("test_renamed_customers")
+
+	c := &testRenamedCustomer{Name: "Ada", PhoneE164: "+15550100"}
+	if err := Create(ctx, c); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	stored := func() bson.M {
+		t.Helper()
+		var doc bson.M
+		if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: c.ID}}).Decode(&doc); err != nil {
+			t.Fatalf("find stored: %v", err)
+		}
+		return doc
+	}
+	if doc := stored(); doc["phone"] != "+15550100" || doc["phone_e164"] != "+15550100" {
+		t.Fatalf("create did not write both names: %v", doc)
+	}
+
+	c.PhoneE164 = "+15550101"
+	if err := Update(ctx, c); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if doc := stored(); doc["phone"] != "+15550101" {
+		t.Fatalf("update did not write the old name: %v", doc)
+	}
+
+	if err := UpdateFields(ctx, c, bson.M{"phone_e164": "+15550102"}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	if doc := stored(); doc["phone"] != "+15550102" || doc["phone_e164"] != "+15550102" {
+		t.Fatalf("update fields did not write both names: %v", doc)
+	}
+
+	if err := UpdateFields(ctx, c, bson.M{"phone_e164": ""}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	doc := stored()
+	if _, ok := doc["phone"]; ok {
+		t.Fatalf("clearing the field kept the old name: %v", doc)
+	}
+}
+
+func TestRename_ReadFallback(t *testing.T) {
+	ctx := useTestStore(t)
+	registerRenamedCustomer(t)
+	coll := activeTestStore().Collection("test_renamed_customers")
+
+	oldID, newID := bson.NewObjectID(), bson.NewObjectID()
+	if _, err := coll.InsertOne(ctx, bson.D{{Key: "_id", Value: oldID}, {Key: "name", Value: "Old"}, {Key: "phone", Value: "+15550100"}}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err := coll.InsertOne(ctx, bson.D{{Key: "_id", Value: newID}, {Key: "name", Value: "New"},
+		{Key: "phone", Value: "+15550199"}, {Key: "phone_e164", Value: "+15550200"}}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	var one testRenamedCustomer
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: oldID}}, &one); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if one.PhoneE164 != "+15550100" {
+		t.Errorf("FindOne PhoneE164 = %q, want the old field's value", one.PhoneE164)
+	}
+
+	var all []testRenamedCustomer
+	if err := Find(ctx, bson.D{}, &all, FindOptions{Sort: bson.D{{Key: "name", Value: -1}}}); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(all) != 2 || all[0].PhoneE164 != "+15550100" || all[1].PhoneE164 != "+15550200" {
+		t.Errorf("unexpected Find results: %+v", all)
+	}
+
+	var strict []testRenamedCustomer
+	if err := Find(ctx, bson.D{}, &strict, FindOptions{Strict: true}); err != nil {
+		t.Errorf("strict find rejected the old name: %v", err)
+	}
+}
+
+func TestBackfillRenames(t *testing.T) {
+	ctx := useTestStore(t)
+	registerRenamedCustomer(t)
+	coll := activeTestStore().Collection("test_renamed_customers")
+
+	for i := 0; i < 5; i++ {
+		if _, err := coll.InsertOne(ctx, bson.D{{Key: "name", Value: "Old"}, {Key: "phone", Value: "+1555010" + string(rune('0'+i))}}); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if err := Create(ctx, &testRenamedCustomer{Name: "New", PhoneE164: "+15550200"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	n, err := BackfillRenames(ctx, &testRenamedCustomer{}, BackfillOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("backfilled %d documents, want 5", n)
+	}
+	missing, err := coll.CountDocuments(ctx, bson.D{{Key: "phone_e164", Value: bson.D{{Key: "$exists", Value: false}}}})
+	if err != nil || missing != 0 {
+		t.Errorf("%d documents still lack the new name (err %v)", missing, err)
+	}
+	var found testRenamedCustomer
+	if err := FindOne(ctx, bson.D{{Key: "phone_e164", Value: "+15550103"}}, &found); err != nil {
+		t.Errorf("filter on the new name after backfill: %v", err)
+	}
+
+	if n, err := BackfillRenames(ctx, &testRenamedCustomer{}); err != nil || n != 0 {
+		t.Errorf("second backfill = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,289 @@
+package goodm
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// modelBaseType is the type of the embedded goodm.Model.
+var modelBaseType = reflect.TypeOf(Model{})
+
+// RESTOptions controls REST handler generation.
+type RESTOptions struct {
+	PackageName string // Go package name (default "handlers")
+	Router      string // "stdlib" (default, Go 1.22 ServeMux patterns) or "chi"
+	BasePath    string // route prefix (default "/" + collection)
+}
+
+// restTemplateData is the data passed to the REST handler template.
+type restTemplateData struct {
+	Package     string
+	ModelImport string
+	ImportAlias string
+	ModelPkg    string
+	Model       string
+	Handler     string
+	BasePath    string
+	Chi         bool
+}
+
+var restTmpl = template.Must(template.New("rest").Parse(`// Code generated by goodm gen rest. You may edit this file.
+
+package {{ .Package }}
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dwoolworth/goodm"
+{{- if .Chi }}
+	"github.com/go-chi/chi/v5"
+{{- end }}
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	{{ if .ImportAlias }}{{ .ImportAlias }} {{ end }}"{{ .ModelImport }}"
+)
+
+const (
+	{{ .Handler }}DefaultLimit = 20
+	{{ .Handler }}MaxLimit     = 100
+)
+
+// {{ .Handler }} serves CRUD endpoints for {{ .ModelPkg }}.{{ .Model }} under {{ .BasePath }}.
+type {{ .Handler }} struct {
+	Store goodm.Store
+}
+
+// New{{ .Handler }} returns a handler backed by store.
+func New{{ .Handler }}(store goodm.Store) *{{ .Handler }} {
+	return &{{ .Handler }}{Store: store}
+}
+
+{{ if .Chi -}}
+// Routes mounts the endpoints on r.
+func (h *{{ .Handler }}) Routes(r chi.Router) {
+	r.Get("{{ .BasePath }}", h.List)
+	r.Post("{{ .BasePath }}", h.Create)
+	r.Get("{{ .BasePath }}/{id}", h.Get)
+	r.Put("{{ .BasePath }}/{id}", h.Update)
+	r.Delete("{{ .BasePath }}/{id}", h.Delete)
+}
+{{- else -}}
+// Routes mounts the endpoints on mux.
+func (h *{{ .Handler }}) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET {{ .BasePath }}", h.List)
+	mux.HandleFunc("POST {{ .BasePath }}", h.Create)
+	mux.HandleFunc("GET {{ .BasePath }}/{id}", h.Get)
+	mux.HandleFunc("PUT {{ .BasePath }}/{id}", h.Update)
+	mux.HandleFunc("DELETE {{ .BasePath }}/{id}", h.Delete)
+}
+{{- end }}
+
+// List returns a page of documents. Query parameters: limit (default 20, at
+// most 100) and skip.
+func (h *{{ .Handler }}) List(w http.ResponseWriter, r *http.Request) {
+	limit, err := h.intParam(r, "limit", {{ .Handler }}DefaultLimit)
+	if err != nil || limit < 1 || limit > {{ .Handler }}MaxLimit {
+		h.writeMessage(w, http.StatusBadRequest, "invalid limit")
+		return
+	}
+	skip, err := h.intParam(r, "skip", 0)
+	if err != nil || skip < 0 {
+		h.writeMessage(w, http.StatusBadRequest, "invalid skip")
+		return
+	}
+
+	items := []{{ .ModelPkg }}.{{ .Model }}{}
+	opts := goodm.FindOptions{Limit: limit, Skip: skip, Sort: bson.D{{ "{{" }}Key: "_id", Value: 1{{ "}}" }}}
+	if err := h.Store.Find(r.Context(), bson.D{}, &items, opts); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"items": items, "limit": limit, "skip": skip})
+}
+
+// Get returns one document by ID.
+func (h *{{ .Handler }}) Get(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	h.writeJSON(w, http.StatusOK, m)
+}
+
+// Create inserts the document in the request body.
+func (h *{{ .Handler }}) Create(w http.ResponseWriter, r *http.Request) {
+	m := &{{ .ModelPkg }}.{{ .Model }}{}
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		h.writeMessage(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	m.ID = bson.ObjectID{}
+	if err := h.Store.Create(r.Context(), m); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, m)
+}
+
+// Update applies the fields in the request body to a stored document.
+func (h *{{ .Handler }}) Update(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	id := m.ID
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		h.writeMessage(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	m.ID = id
+	if err := h.Store.Update(r.Context(), m); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, m)
+}
+
+// Delete removes a document by ID.
+func (h *{{ .Handler }}) Delete(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Store.Delete(r.Context(), m); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load fetches the document named by the id path parameter, writing an error
+// response if it cannot.
+func (h *{{ .Handler }}) load(w http.ResponseWriter, r *http.Request) (*{{ .ModelPkg }}.{{ .Model }}, bool) {
+{{- if .Chi }}
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+{{- else }}
+	id, err := bson.ObjectIDFromHex(r.PathValue("id"))
+{{- end }}
+	if err != nil {
+		h.writeMessage(w, http.StatusBadRequest, "invalid id")
+		return nil, false
+	}
+	m := &{{ .ModelPkg }}.{{ .Model }}{}
+	if err := h.Store.FindOne(r.Context(), bson.D{{ "{{" }}Key: "_id", Value: id{{ "}}" }}, m); err != nil {
+		h.writeError(w, err)
+		return nil, false
+	}
+	return m, true
+}
+
+func (h *{{ .Handler }}) intParam(r *http.Request, name string, def int64) (int64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// writeError maps goodm errors to HTTP responses: validation errors to 422
+// with the failing fields, missing documents to 404, and version conflicts
+// and duplicate keys to 409.
+func (h *{{ .Handler }}) writeError(w http.ResponseWriter, err error) {
+	var verrs goodm.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		fields := make([]map[string]string, len(verrs))
+		for i, e := range verrs {
+			fields[i] = map[string]string{"field": e.Field, "message": e.Message}
+		}
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{"error": "validation failed", "fields": fields})
+	case errors.Is(err, goodm.ErrNotFound):
+		h.writeMessage(w, http.StatusNotFound, "not found")
+	case errors.Is(err, goodm.ErrVersionConflict):
+		h.writeMessage(w, http.StatusConflict, "version conflict")
+	case mongo.IsDuplicateKeyError(err):
+		h.writeMessage(w, http.StatusConflict, "duplicate key")
+	default:
+		h.writeMessage(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func (h *{{ .Handler }}) writeMessage(w http.ResponseWriter, status int, msg string) {
+	h.writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func (h *{{ .Handler }}) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+`))
+
+// GenerateREST generates Go source for HTTP handlers serving list (with
+// limit/skip pagination), get, create, update, and delete endpoints for a
+// registered model, built on a goodm.Store. Validation errors map to 422,
+// ErrNotFound to 404, and version conflicts and duplicate keys to 409.
+//
+// The model must embed goodm.Model and be declared in an importable package;
+// the generated file imports it by its package path.
+//
+// Example:
+//
+//	schema, _ := goodm.Get("User")
+//	src, err := goodm.GenerateREST(schema, goodm.RESTOptions{Router: "chi"})
+func GenerateREST(schema *Schema, opts RESTOptions) ([]byte, error) {
+	if opts.PackageName == "" {
+		opts.PackageName = "handlers"
+	}
+	if opts.BasePath == "" {
+		opts.BasePath = "/" + schema.Collection
+	}
+	opts.BasePath = "/" + strings.Trim(opts.BasePath, "/")
+
+	switch opts.Router {
+	case "", "stdlib", "chi":
+	default:
+		return nil, fmt.Errorf("goodm: gen rest: unknown router %q (want stdlib or chi)", opts.Router)
+	}
+
+	t := schema.modelType
+	if f, ok := t.FieldByName("Model"); !ok || !f.Anonymous || f.Type != modelBaseType {
+		return nil, fmt.Errorf("goodm: gen rest: %s must embed goodm.Model", schema.ModelName)
+	}
+	if t.PkgPath() == "" || t.PkgPath() == "main" {
+		return nil, fmt.Errorf("goodm: gen rest: %s is not declared in an importable package", schema.ModelName)
+	}
+	pkg := t.String()[:strings.LastIndex(t.String(), ".")]
+
+	data := restTemplateData{
+		Package:     opts.PackageName,
+		ModelImport: t.PkgPath(),
+		ModelPkg:    pkg,
+		Model:       schema.ModelName,
+		Handler:     schema.ModelName + "Handler",
+		BasePath:    opts.BasePath,
+		Chi:         opts.Router == "chi",
+	}
+	if path.Base(data.ModelImport) != pkg {
+		data.ImportAlias = pkg
+	}
+
+	var buf bytes.Buffer
+	if err := restTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("goodm: gen rest: generated code does not parse: %w\n%s", err, buf.Bytes())
+	}
+	return formatted, nil
+}
@@ -0,0 +1,74 @@
+package goodm
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testNoBase struct {
+	ID   bson.ObjectID `bson:"_id"`
+	Name string        `bson:"name"`
+}
+
+func TestGenerateREST(t *testing.T) {
+	useTestStore(t)
+	schema, _ := Get("testUser")
+
+	src, err := GenerateREST(schema, RESTOptions{})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "handler.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, src)
+	}
+	code := string(src)
+	for _, want := range []string{
+		"package handlers",
+		`"github.com/dwoolworth/goodm"`,
+		"type testUserHandler struct",
+		`mux.HandleFunc("GET /test_users/{id}", h.Get)`,
+		`r.PathValue("id")`,
+		"items := []goodm.testUser{}",
+		"http.StatusUnprocessableEntity",
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected %q in generated code:\n%s", want, code)
+		}
+	}
+
+	src, err = GenerateREST(schema, RESTOptions{PackageName: "api", Router: "chi", BasePath: "v1/users/"})
+	if err != nil {
+		t.Fatalf("generate chi: %v", err)
+	}
+	code = string(src)
+	for _, want := range []string{"package api", `"github.com/go-chi/chi/v5"`, `r.Get("/v1/users/{id}", h.Get)`, `chi.URLParam(r, "id")`} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected %q in generated chi code:\n%s", want, code)
+		}
+	}
+
+	if _, err := GenerateREST(schema, RESTOptions{Router: "gin"}); err == nil {
+		t.Fatal("expected unknown router to be rejected")
+	}
+
+	if src, err := GenerateREST(schema, RESTOptions{PackageName: "my handlers"}); err == nil || src != nil || !strings.Contains(err.Error(), "package my handlers") {
+		t.Fatalf("expected a parse error carrying the generated source, got %v", err)
+	}
+
+	if err := Register(&testNoBase{}, "test_no_base"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testNoBase")
+		registryMu.Unlock()
+	})
+	noBase, _ := Get("testNoBase")
+	if _, err := GenerateREST(noBase, RESTOptions{}); err == nil {
+		t.Fatal("expected a model without goodm.Model to be rejected")
+	}
+}
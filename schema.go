@@ -1,6 +1,7 @@
 package goodm
 
 import (
+	"encoding/json"
 	"reflect"
 	"time"
 
@@ -18,22 +19,65 @@ type CollectionOptions struct {
 	WriteConcern   *writeconcern.WriteConcern
 }
 
+// MarshalJSON encodes the options that are set: the read preference mode,
+// the read concern level, and the write concern's w and journal settings.
+func (o CollectionOptions) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{})
+	if o.ReadPreference != nil {
+		out["read_preference"] = o.ReadPreference.Mode().String()
+	}
+	if o.ReadConcern != nil {
+		out["read_concern"] = o.ReadConcern.Level
+	}
+	if o.WriteConcern != nil {
+		wc := make(map[string]interface{})
+		if o.WriteConcern.W != nil {
+			wc["w"] = o.WriteConcern.W
+		}
+		if o.WriteConcern.Journal != nil {
+			wc["journal"] = *o.WriteConcern.Journal
+		}
+		out["write_concern"] = wc
+	}
+	return json.Marshal(out)
+}
+
 // FieldSchema describes a single field parsed from struct tags.
 type FieldSchema struct {
-	Name      string        // Go field name
-	BSONName  string        // bson tag name
-	Type      string        // Go type as string
-	Required  bool          // field must be non-zero
-	Unique    bool          // unique index on this field
-	Index     bool          // single-field index
-	Default   string        // raw default value
-	Enum      []string      // allowed values
-	Min       *int          // minimum value/length
-	Max       *int          // maximum value/length
-	Ref       string        // referenced collection
-	Immutable bool          // cannot be changed after creation
-	SubFields []FieldSchema // inner fields for struct/[]struct subdocuments
-	IsSlice   bool          // true if field is []struct or []*struct
+	Name            string              `json:"name"`                       // Go field name
+	BSONName        string              `json:"bson_name"`                  // bson tag name
+	OmitEmpty       bool                `json:"omit_empty,omitempty"`       // bson omitempty: not stored when zero
+	Type            string              `json:"type"`                       // Go type as string
+	Required        bool                `json:"required,omitempty"`         // field must be non-zero
+	Unique          bool                `json:"unique,omitempty"`           // unique index on this field
+	UniqueWith      []string            `json:"unique_with,omitempty"`      // scope fields of a unique compound index (uniquewith=a|b)
+	CaseInsensitive string              `json:"case_insensitive,omitempty"` // case-insensitive index strategy: "collation" (ci) or "shadow" (ci=shadow)
+	Index           bool                `json:"index,omitempty"`            // single-field index
+	Default         string              `json:"default,omitempty"`          // raw default value
+	Enum            []string            `json:"enum,omitempty"`             // allowed values
+	Min             *int                `json:"min,omitempty"`              // minimum value/length
+	Max             *int                `json:"max,omitempty"`              // maximum value/length
+	Precision       *int                `json:"precision,omitempty"`        // maximum total digits of a decimal value
+	Scale           *int                `json:"scale,omitempty"`            // maximum digits after the decimal point of a decimal value
+	Ref             string              `json:"ref,omitempty"`              // referenced collection
+	Immutable       bool                `json:"immutable,omitempty"`        // cannot be changed after creation
+	WriteOnce       bool                `json:"write_once,omitempty"`       // may be set once from its zero value, then immutable
+	Normalize       []string            `json:"normalize,omitempty"`        // normalizer names applied to string and time values on write
+	DateOnly        bool                `json:"date_only,omitempty"`        // time stored as midnight UTC of its calendar date (dateonly)
+	Hidden          bool                `json:"hidden,omitempty"`           // excluded from reads unless requested (select=false)
+	Kind            string              `json:"kind,omitempty"`             // discriminator value this model stamps (kind=value)
+	Collation       string              `json:"collation,omitempty"`        // collation spec for the field's index (collation=en_ci)
+	AutoIncrement   bool                `json:"auto_increment,omitempty"`   // assigned the next counter value on create when zero
+	Slug            string              `json:"slug,omitempty"`             // bson name of the field a slug is generated from (slug=title)
+	Tree            string              `json:"tree,omitempty"`             // tree role: "parent" (parent ref) or "path" (materialized path)
+	Transitions     map[string][]string `json:"transitions,omitempty"`      // allowed enum transitions, from each value to its next values
+	Deprecated      bool                `json:"deprecated,omitempty"`       // field is being retired (deprecated or deprecated=note)
+	DeprecatedNote  string              `json:"deprecated_note,omitempty"`  // what to use instead, from deprecated=note
+	RenamedFrom     string              `json:"renamed_from,omitempty"`     // old bson name written alongside and read as a fallback (renamed_from=old)
+	Mixin           string              `json:"mixin,omitempty"`            // embedded struct the field is promoted from, e.g. "Model"; empty if declared on the model
+	SubFields       []FieldSchema       `json:"sub_fields,omitempty"`       // inner fields for struct/[]struct subdocuments
+	Embedded        string              `json:"embedded,omitempty"`         // registered embedded type supplying SubFields, if any
+	IsSlice         bool                `json:"is_slice,omitempty"`         // true if field is []struct or []*struct
 }
 
 // isLeafType returns true for struct types that serialize as atomic BSON values
@@ -41,17 +85,46 @@ type FieldSchema struct {
 func isLeafType(t reflect.Type) bool {
 	return t == reflect.TypeOf(time.Time{}) ||
 		t == reflect.TypeOf(bson.ObjectID{}) ||
-		t == reflect.TypeOf(bson.Decimal128{})
+		t == reflect.TypeOf(bson.Decimal128{}) ||
+		t == ratType
 }
 
 // Schema is the parsed representation of a model struct.
 type Schema struct {
-	ModelName       string            // Go struct name
-	Collection      string            // MongoDB collection name
-	Fields          []FieldSchema     // parsed fields
-	CompoundIndexes []CompoundIndex   // compound indexes from Indexes() method
-	Hooks           []string          // hook interface names the model implements
-	CollOptions     CollectionOptions // per-schema read/write concern and read preference
+	ModelName       string            `json:"model_name"`                   // Go struct name
+	Collection      string            `json:"collection"`                   // MongoDB collection name
+	Fields          []FieldSchema     `json:"fields"`                       // parsed fields
+	CompoundIndexes []CompoundIndex   `json:"compound_indexes,omitempty"`   // compound indexes from Indexes() method
+	Hooks           []string          `json:"hooks,omitempty"`              // hook interface names the model implements
+	CollOptions     CollectionOptions `json:"collection_options,omitempty"` // per-schema read/write concern and read preference
+	Conflict        ConflictStrategy  `json:"conflict,omitempty"`           // how Update handles version conflicts
+	Relations       []Relation        `json:"relations,omitempty"`          // belongs_to, has_one, and has_many relations
+	Keys            []string          `json:"keys,omitempty"`               // natural key fields from Keys() method
+
+	Discriminator      string `json:"discriminator,omitempty"`       // bson field holding the kind, for polymorphic collections
+	DiscriminatorValue string `json:"discriminator_value,omitempty"` // kind value identifying this model in its collection
+
+	TreeParent string `json:"tree_parent,omitempty"` // bson field holding the parent ID, for tree models (tree=parent)
+	TreePath   string `json:"tree_path,omitempty"`   // bson field holding the materialized path, if any (tree=path)
+
+	SoftDelete string `json:"soft_delete,omitempty"` // bson field holding the deletion time, for models embedding SoftDeleteModel
+
+	ViewOn       string      `json:"view_on,omitempty"` // source collection, for models registered with RegisterView
+	ViewPipeline interface{} `json:"-"`                 // aggregation pipeline defining the view
+
+	Service string `json:"service,omitempty"` // owning service, for schemas imported with ImportSchemas
+
+	modelType reflect.Type // registered struct type, used to instantiate models
+	baseKeys  baseKeys     // bson keys of the base fields, resolved by Register
+}
+
+// base returns the bson keys of the schema's base fields. Imported schemas
+// use the default keys.
+func (s *Schema) base() baseKeys {
+	if s.baseKeys == (baseKeys{}) {
+		return defaultBaseKeys
+	}
+	return s.baseKeys
 }
 
 // HasField returns true if the schema contains a field with the given BSON name.
@@ -79,6 +152,17 @@ type Indexable interface {
 	Indexes() []CompoundIndex
 }
 
+// Keyed is implemented by models with a natural (business) key: the bson
+// names of the fields that identify a document, as used by FindByKey and
+// UpsertByKey.
+//
+// Example:
+//
+//	func (p *Product) Keys() []string { return []string{"vendor", "sku"} }
+type Keyed interface {
+	Keys() []string
+}
+
 // Configurable is implemented by models that define per-schema collection options
 // such as read preference, read concern, and write concern.
 //
@@ -0,0 +1,47 @@
+package goodm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	registerTestModels()
+	defer unregisterTestModels()
+
+	user, _ := Get("testUser")
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, want := range []string{
+		`"model_name":"testUser","collection":"test_users"`,
+		`{"name":"Email","bson_name":"email","type":"string","required":true,"unique":true}`,
+		`"enum":["admin","user"]`,
+		`"ref":"test_profiles"`,
+		`"min":0,"max":200`,
+		`"collection_options":{}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in %s", want, data)
+		}
+	}
+	if strings.Contains(string(data), `"conflict"`) {
+		t.Errorf("expected the default conflict strategy to be left out, got %s", data)
+	}
+
+	configured, _ := Get("testConfiguredModel")
+	data, err = json.Marshal(configured)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if want := `"collection_options":{"read_preference":"secondaryPreferred","write_concern":{"w":"majority"}}`; !strings.Contains(string(data), want) {
+		t.Errorf("expected %s in %s", want, data)
+	}
+
+	data, _ = json.Marshal(Schema{Conflict: ConflictLastWriteWins})
+	if !strings.Contains(string(data), `"conflict":"last_write_wins"`) {
+		t.Errorf("expected the conflict strategy by name, got %s", data)
+	}
+}
@@ -0,0 +1,99 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CountersCollection holds the counters behind autoincrement fields, one
+// document per field: {_id: "<collection>.<field>", seq: <last value>}.
+const CountersCollection = "goodm_counters"
+
+// checkSequences validates the autoincrement fields of schema and gives each
+// a unique index.
+func checkSequences(schema *Schema) error {
+	for i := range schema.Fields {
+		f := &schema.Fields[i]
+		if !f.AutoIncrement {
+			continue
+		}
+		switch f.Type {
+		case "int", "int32", "int64":
+		default:
+			return fmt.Errorf("goodm: %s.%s: autoincrement requires an int, int32, or int64 field", schema.ModelName, f.Name)
+		}
+		f.Unique = true
+	}
+	return nil
+}
+
+// reserveSequence atomically advances the counter named key by n and returns
+// the first of the n reserved values. Counters start at 1.
+func reserveSequence(ctx context.Context, db dbHandle, key string, n int64) (int64, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := namedCollection(db, CountersCollection).FindOneAndUpdate(ctx,
+		bson.D{{Key: "_id", Value: key}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "seq", Value: n}}}},
+		withComment(ctx, options.FindOneAndUpdate()).SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("goodm: failed to advance counter %s: %w", key, err)
+	}
+	return counter.Seq - n + 1, nil
+}
+
+// sequenceBlock hands out values reserved from counters in blocks, so
+// CreateMany advances each counter once instead of once per model.
+type sequenceBlock struct {
+	next map[string]int64 // next value to hand out, per counter
+	end  map[string]int64 // end of the reserved block (exclusive), per counter
+}
+
+func newSequenceBlock() *sequenceBlock {
+	return &sequenceBlock{next: make(map[string]int64), end: make(map[string]int64)}
+}
+
+// setSequences assigns the next counter value to each zero autoincrement
+// field of model. block is nil for a single Create; CreateMany passes the
+// block shared by the batch, with remaining as the number of models left.
+func setSequences(ctx context.Context, db dbHandle, model interface{}, schema *Schema, block *sequenceBlock, remaining int) error {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	for _, f := range schema.Fields {
+		if !f.AutoIncrement {
+			continue
+		}
+		fv := v.FieldByName(f.Name)
+		if !fv.IsValid() || !fv.IsZero() {
+			continue
+		}
+		key := schema.Collection + "." + f.BSONName
+
+		var seq int64
+		switch {
+		case block == nil:
+			first, err := reserveSequence(ctx, db, key, 1)
+			if err != nil {
+				return err
+			}
+			seq = first
+		case block.next[key] < block.end[key]:
+			seq = block.next[key]
+			block.next[key]++
+		default:
+			first, err := reserveSequence(ctx, db, key, int64(remaining))
+			if err != nil {
+				return err
+			}
+			seq = first
+			block.next[key], block.end[key] = first+1, first+int64(remaining)
+		}
+		fv.SetInt(seq)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package goodm
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testTicket struct {
+	Model  `bson:",inline"`
+	Number int64  `bson:"number" goodm:"autoincrement"`
+	Title  string `bson:"title"`
+}
+
+func registerTicketModel(t *testing.T) {
+	t.Helper()
+	if err := Register(&testTicket{}, "test_tickets"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testTicket")
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister_AutoIncrement(t *testing.T) {
+	registerTicketModel(t)
+
+	schema, _ := Get("testTicket")
+	if !schema.GetField("number").Unique {
+		t.Fatal("expected an autoincrement field to be unique")
+	}
+
+	type badType struct {
+		Model  `bson:",inline"`
+		Number string `bson:"number" goodm:"autoincrement"`
+	}
+	if err := Register(&badType{}, "test_bad_sequences"); err == nil {
+		t.Fatal("expected a string autoincrement field to be rejected")
+	}
+}
+
+func TestAutoIncrement(t *testing.T) {
+	ctx := useTestStore(t)
+	registerTicketModel(t)
+
+	first := &testTicket{Title: "a"}
+	if err := Create(ctx, first); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if first.Number != 1 {
+		t.Fatalf("expected 1, got %d", first.Number)
+	}
+
+	batch := []testTicket{{Title: "b"}, {Title: "c", Number: 100}, {Title: "d"}}
+	if _, err := CreateMany(ctx, batch); err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	if batch[0].Number != 2 || batch[1].Number != 100 || batch[2].Number != 3 {
+		t.Fatalf("unexpected numbers: %d %d %d", batch[0].Number, batch[1].Number, batch[2].Number)
+	}
+
+	// Values reserved but not used by the batch are skipped.
+	next := &testTicket{Title: "e"}
+	if err := Create(ctx, next); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if next.Number != 5 {
+		t.Fatalf("expected 5, got %d", next.Number)
+	}
+
+	var counter bson.M
+	if err := activeTestStore().Collection(CountersCollection).FindOne(ctx, bson.D{{Key: "_id", Value: "test_tickets.number"}}).Decode(&counter); err != nil {
+		t.Fatalf("find counter: %v", err)
+	}
+	if counter["seq"] != int64(5) {
+		t.Fatalf("expected the counter at 5, got %v", counter["seq"])
+	}
+}
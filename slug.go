@@ -0,0 +1,133 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// slugFold maps common accented Latin letters to their ASCII base letters.
+var slugFold = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+// Slugify returns a URL-safe slug for s: lowercase ASCII letters and digits,
+// with every other run of characters replaced by a single hyphen. Common
+// accented letters are folded to their base letters.
+//
+// Example:
+//
+//	goodm.Slugify("Crème Brûlée: A How-To") // "creme-brulee-a-how-to"
+func Slugify(s string) string {
+	s = slugFold.Replace(strings.ToLower(s))
+	var b strings.Builder
+	dash := false
+	for _, r := range s {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// checkSlugs validates the slug=source tags of schema's fields and makes each
+// slug unique, or unique within its uniquewith scope.
+func checkSlugs(schema *Schema) error {
+	for i := range schema.Fields {
+		f := &schema.Fields[i]
+		if f.Slug == "" {
+			continue
+		}
+		if f.Type != "string" {
+			return fmt.Errorf("goodm: %s.%s: slug requires a string field", schema.ModelName, f.Name)
+		}
+		if src := schema.GetField(f.Slug); src == nil || src.Type != "string" || src.BSONName == f.BSONName {
+			return fmt.Errorf("goodm: %s.%s: slug source %q is not a string field of the model", schema.ModelName, f.Name, f.Slug)
+		}
+		if len(f.UniqueWith) == 0 {
+			f.Unique = true
+		}
+	}
+	return nil
+}
+
+// slugScope returns the filter restricting a slug's uniqueness to the
+// model's uniquewith scope, and a key identifying that scope.
+func slugScope(v reflect.Value, schema *Schema, f FieldSchema) (bson.D, string) {
+	var filter bson.D
+	var key strings.Builder
+	for _, scope := range f.UniqueWith {
+		sf := schema.GetField(scope)
+		value := v.FieldByName(sf.Name).Interface()
+		filter = append(filter, bson.E{Key: scope, Value: value})
+		fmt.Fprintf(&key, "%v\x00", value)
+	}
+	return filter, key.String()
+}
+
+// setSlugs fills the model's empty slug fields from their sources. A slug
+// already stored, or generated earlier in the same CreateMany (batch), gets
+// the lowest free numeric suffix: "title", "title-2", "title-3".
+func setSlugs(ctx context.Context, coll collection, model interface{}, schema *Schema, batch map[string]bool) error {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	for _, f := range schema.Fields {
+		if f.Slug == "" {
+			continue
+		}
+		fv := v.FieldByName(f.Name)
+		if fv.String() != "" {
+			continue
+		}
+		base := Slugify(v.FieldByName(schema.GetField(f.Slug).Name).String())
+		if base == "" {
+			continue
+		}
+
+		scope, scopeKey := slugScope(v, schema, f)
+		filter := append(scope, bson.E{Key: f.BSONName, Value: bson.D{
+			{Key: "$regex", Value: "^" + regexp.QuoteMeta(base) + "(-[0-9]+)?$"},
+		}})
+		cursor, err := coll.Find(ctx, filter,
+			withComment(ctx, options.Find()).SetProjection(bson.D{{Key: f.BSONName, Value: 1}}))
+		if err != nil {
+			return fmt.Errorf("goodm: slug lookup failed: %w", err)
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			return fmt.Errorf("goodm: slug lookup failed: %w", err)
+		}
+		taken := make(map[string]bool, len(docs))
+		for _, d := range docs {
+			if s, ok := d[f.BSONName].(string); ok {
+				taken[s] = true
+			}
+		}
+
+		slug := base
+		for n := 2; taken[slug] || batch[f.BSONName+"\x00"+scopeKey+slug]; n++ {
+			slug = base + "-" + strconv.Itoa(n)
+		}
+		fv.SetString(slug)
+		if batch != nil {
+			batch[f.BSONName+"\x00"+scopeKey+slug] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package goodm
+
+import (
+	"testing"
+)
+
+type testPage struct {
+	Model  `bson:",inline"`
+	Title  string `bson:"title" goodm:"required"`
+	Slug   string `bson:"slug" goodm:"slug=title,required"`
+	SiteID string `bson:"site_id"`
+}
+
+type testSitePage struct {
+	Model  `bson:",inline"`
+	SiteID string `bson:"site_id"`
+	Title  string `bson:"title"`
+	Slug   string `bson:"slug" goodm:"slug=title,uniquewith=site_id"`
+}
+
+func registerSlugModels(t *testing.T) {
+	t.Helper()
+	if err := Register(&testPage{}, "test_pages"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := Register(&testSitePage{}, "test_site_pages"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testPage")
+		delete(registry, "testSitePage")
+		registryMu.Unlock()
+	})
+}
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Hello, World!":           "hello-world",
+		"  Crème Brûlée: How-To ": "creme-brulee-how-to",
+		"Go 1.19 -- released":     "go-1-19-released",
+		"日本語":                     "",
+	}
+	for in, want := range cases {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRegister_Slug(t *testing.T) {
+	registerSlugModels(t)
+
+	pages, _ := Get("testPage")
+	if !pages.GetField("slug").Unique {
+		t.Fatal("expected the slug to be unique")
+	}
+	sitePages, _ := Get("testSitePage")
+	if sitePages.GetField("slug").Unique || len(sitePages.CompoundIndexes) != 1 {
+		t.Fatal("expected a scoped slug to use a compound index")
+	}
+
+	type badSource struct {
+		Model `bson:",inline"`
+		Slug  string `bson:"slug" goodm:"slug=name"`
+	}
+	if err := Register(&badSource{}, "test_bad_slugs"); err == nil {
+		t.Fatal("expected an unknown slug source to be rejected")
+	}
+}
+
+func TestSlug_Generation(t *testing.T) {
+	ctx := useTestStore(t)
+	registerSlugModels(t)
+
+	first := &testPage{Title: "Hello World"}
+	if err := Create(ctx, first); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if first.Slug != "hello-world" {
+		t.Fatalf("expected hello-world, got %q", first.Slug)
+	}
+
+	pages := []testPage{{Title: "Hello world!"}, {Title: "hello WORLD"}, {Title: "Other", Slug: "custom"}}
+	if _, err := CreateMany(ctx, pages); err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	if pages[0].Slug != "hello-world-2" || pages[1].Slug != "hello-world-3" || pages[2].Slug != "custom" {
+		t.Fatalf("unexpected slugs: %q %q %q", pages[0].Slug, pages[1].Slug, pages[2].Slug)
+	}
+
+	// A similar but different base is not a collision.
+	longer := &testPage{Title: "Hello World Again"}
+	if err := Create(ctx, longer); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if longer.Slug != "hello-world-again" {
+		t.Fatalf("expected hello-world-again, got %q", longer.Slug)
+	}
+
+	// Scoped slugs only collide within their scope.
+	a := &testSitePage{SiteID: "a", Title: "About"}
+	b := &testSitePage{SiteID: "b", Title: "About"}
+	a2 := &testSitePage{SiteID: "a", Title: "About"}
+	for _, p := range []*testSitePage{a, b, a2} {
+		if err := Create(ctx, p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if a.Slug != "about" || b.Slug != "about" || a2.Slug != "about-2" {
+		t.Fatalf("unexpected scoped slugs: %q %q %q", a.Slug, b.Slug, a2.Slug)
+	}
+}
@@ -0,0 +1,306 @@
+package goodm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SnapshotVersion is the format version written to schema snapshots.
+const SnapshotVersion = 1
+
+// SchemaSnapshot is a canonical, serializable manifest of registered schemas.
+// Commit one to the repository with `goodm schema snapshot` and compare the
+// current registrations against it in CI with `goodm schema check`.
+type SchemaSnapshot struct {
+	Version int             `json:"version"`
+	Models  []ModelSnapshot `json:"models"`
+}
+
+// ModelSnapshot is one model in a SchemaSnapshot.
+type ModelSnapshot struct {
+	Name       string          `json:"name"`
+	Collection string          `json:"collection"`
+	Fields     []FieldSnapshot `json:"fields"`
+	Indexes    []IndexSnapshot `json:"indexes,omitempty"`
+}
+
+// FieldSnapshot is one field in a ModelSnapshot. Subdocument fields list
+// their inner fields.
+type FieldSnapshot struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Required   bool            `json:"required,omitempty"`
+	Unique     bool            `json:"unique,omitempty"`
+	Index      bool            `json:"index,omitempty"`
+	Immutable  bool            `json:"immutable,omitempty"`
+	Deprecated bool            `json:"deprecated,omitempty"`
+	Default    string          `json:"default,omitempty"`
+	Enum       []string        `json:"enum,omitempty"`
+	Ref        string          `json:"ref,omitempty"`
+	Fields     []FieldSnapshot `json:"fields,omitempty"`
+}
+
+// IndexSnapshot is one compound index in a ModelSnapshot.
+type IndexSnapshot struct {
+	Fields []string `json:"fields"`
+	Unique bool     `json:"unique,omitempty"`
+}
+
+// Snapshot returns the canonical snapshot of the given schemas, with models
+// sorted by name and fields in declaration order.
+//
+// Example:
+//
+//	snap := goodm.Snapshot(goodm.GetAll())
+func Snapshot(schemas map[string]*Schema) *SchemaSnapshot {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	snap := &SchemaSnapshot{Version: SnapshotVersion, Models: []ModelSnapshot{}}
+	for _, name := range names {
+		snap.Models = append(snap.Models, modelSnapshot(schemas[name]))
+	}
+	return snap
+}
+
+func modelSnapshot(s *Schema) ModelSnapshot {
+	m := ModelSnapshot{Name: s.ModelName, Collection: s.Collection, Fields: snapshotFields(s.Fields)}
+	for _, ci := range s.CompoundIndexes {
+		m.Indexes = append(m.Indexes, IndexSnapshot{Fields: ci.Fields, Unique: ci.Unique})
+	}
+	return m
+}
+
+func snapshotFields(fields []FieldSchema) []FieldSnapshot {
+	out := make([]FieldSnapshot, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, FieldSnapshot{
+			Name: f.BSONName, Type: f.Type, Required: f.Required, Unique: f.Unique, Index: f.Index,
+			Immutable: f.Immutable, Deprecated: f.Deprecated, Default: f.Default, Enum: f.Enum, Ref: f.Ref,
+			Fields: snapshotSubFields(f.SubFields),
+		})
+	}
+	return out
+}
+
+func snapshotSubFields(fields []FieldSchema) []FieldSnapshot {
+	if len(fields) == 0 {
+		return nil
+	}
+	return snapshotFields(fields)
+}
+
+// WriteSnapshot writes snap to w as indented JSON.
+func WriteSnapshot(w io.Writer, snap *SchemaSnapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("goodm: encode snapshot: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("goodm: write snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot.
+func ReadSnapshot(r io.Reader) (*SchemaSnapshot, error) {
+	var snap SchemaSnapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("goodm: decode snapshot: %w", err)
+	}
+	if snap.Version != SnapshotVersion {
+		return nil, fmt.Errorf("goodm: unsupported snapshot version %d", snap.Version)
+	}
+	return &snap, nil
+}
+
+// LoadSnapshot reads the snapshot file at path.
+func LoadSnapshot(path string) (*SchemaSnapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("goodm: open snapshot: %w", err)
+	}
+	defer f.Close()
+	return ReadSnapshot(f)
+}
+
+// SchemaChange is one difference between two snapshots.
+type SchemaChange struct {
+	Model    string // model name
+	Field    string // dotted bson path, or "" for model-level changes
+	Breaking bool   // existing documents or clients may no longer work
+	Message  string
+}
+
+func (c SchemaChange) String() string {
+	target := c.Model
+	if c.Field != "" {
+		target += "." + c.Field
+	}
+	return fmt.Sprintf("%s: %s", target, c.Message)
+}
+
+// DiffSnapshots compares the current snapshot against a previous one. These
+// changes are breaking:
+//   - a model, or a field, was removed
+//   - a model moved to another collection
+//   - a field changed type
+//   - a required field without a default was added, or an existing field
+//     became required without a default
+//
+// Added models and fields and other attribute changes are reported as
+// non-breaking.
+func DiffSnapshots(previous, current *SchemaSnapshot) []SchemaChange {
+	var changes []SchemaChange
+	cur := make(map[string]ModelSnapshot, len(current.Models))
+	for _, m := range current.Models {
+		cur[m.Name] = m
+	}
+	prev := make(map[string]bool, len(previous.Models))
+
+	for _, pm := range previous.Models {
+		prev[pm.Name] = true
+		cm, ok := cur[pm.Name]
+		if !ok {
+			changes = append(changes, SchemaChange{Model: pm.Name, Breaking: true, Message: "model removed"})
+			continue
+		}
+		if cm.Collection != pm.Collection {
+			changes = append(changes, SchemaChange{Model: pm.Name, Breaking: true,
+				Message: fmt.Sprintf("collection changed from %q to %q", pm.Collection, cm.Collection)})
+		}
+		changes = append(changes, diffSnapshotFields(pm.Name, "", pm.Fields, cm.Fields)...)
+		changes = append(changes, diffSnapshotIndexes(pm.Name, pm.Indexes, cm.Indexes)...)
+	}
+	for _, cm := range current.Models {
+		if !prev[cm.Name] {
+			changes = append(changes, SchemaChange{Model: cm.Name, Message: "model added"})
+		}
+	}
+	return changes
+}
+
+func diffSnapshotFields(model, prefix string, previous, current []FieldSnapshot) []SchemaChange {
+	var changes []SchemaChange
+	cur := make(map[string]FieldSnapshot, len(current))
+	for _, f := range current {
+		cur[f.Name] = f
+	}
+	prev := make(map[string]bool, len(previous))
+
+	for _, pf := range previous {
+		prev[pf.Name] = true
+		path := prefix + pf.Name
+		cf, ok := cur[pf.Name]
+		if !ok {
+			changes = append(changes, SchemaChange{Model: model, Field: path, Breaking: true, Message: "field removed"})
+			continue
+		}
+		if cf.Type != pf.Type {
+			changes = append(changes, SchemaChange{Model: model, Field: path, Breaking: true,
+				Message: fmt.Sprintf("type changed from %s to %s", pf.Type, cf.Type)})
+		}
+		if cf.Required && !pf.Required {
+			changes = append(changes, SchemaChange{Model: model, Field: path, Breaking: cf.Default == "",
+				Message: "field became required" + noDefault(cf)})
+		}
+		if attrs := attrChanges(pf, cf); attrs != "" {
+			changes = append(changes, SchemaChange{Model: model, Field: path, Message: attrs})
+		}
+		if len(pf.Fields) > 0 || len(cf.Fields) > 0 {
+			changes = append(changes, diffSnapshotFields(model, path+".", pf.Fields, cf.Fields)...)
+		}
+	}
+	for _, cf := range current {
+		if prev[cf.Name] {
+			continue
+		}
+		changes = append(changes, SchemaChange{Model: model, Field: prefix + cf.Name,
+			Breaking: cf.Required && cf.Default == "", Message: "field added" + requiredNote(cf)})
+	}
+	return changes
+}
+
+func noDefault(f FieldSnapshot) string {
+	if f.Default == "" {
+		return " without a default"
+	}
+	return ""
+}
+
+func requiredNote(f FieldSnapshot) string {
+	if !f.Required {
+		return ""
+	}
+	return " as required" + noDefault(f)
+}
+
+// attrChanges describes the non-breaking attribute changes between two
+// versions of a field, or returns "".
+func attrChanges(prev, cur FieldSnapshot) string {
+	var parts []string
+	flag := func(name string, was, is bool) {
+		if was != is {
+			if is {
+				parts = append(parts, "now "+name)
+			} else {
+				parts = append(parts, "no longer "+name)
+			}
+		}
+	}
+	if prev.Required && !cur.Required {
+		parts = append(parts, "no longer required")
+	}
+	flag("unique", prev.Unique, cur.Unique)
+	flag("indexed", prev.Index, cur.Index)
+	flag("immutable", prev.Immutable, cur.Immutable)
+	flag("deprecated", prev.Deprecated, cur.Deprecated)
+	if prev.Default != cur.Default {
+		parts = append(parts, fmt.Sprintf("default changed from %q to %q", prev.Default, cur.Default))
+	}
+	if strings.Join(prev.Enum, "|") != strings.Join(cur.Enum, "|") {
+		parts = append(parts, fmt.Sprintf("enum changed from [%s] to [%s]", strings.Join(prev.Enum, "|"), strings.Join(cur.Enum, "|")))
+	}
+	if prev.Ref != cur.Ref {
+		parts = append(parts, fmt.Sprintf("ref changed from %q to %q", prev.Ref, cur.Ref))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func diffSnapshotIndexes(model string, previous, current []IndexSnapshot) []SchemaChange {
+	key := func(ix IndexSnapshot) string {
+		k := strings.Join(ix.Fields, ",")
+		if ix.Unique {
+			k += " (unique)"
+		}
+		return k
+	}
+	prev := map[string]bool{}
+	for _, ix := range previous {
+		prev[key(ix)] = true
+	}
+	cur := map[string]bool{}
+	for _, ix := range current {
+		cur[key(ix)] = true
+	}
+
+	var changes []SchemaChange
+	for _, ix := range previous {
+		if k := key(ix); !cur[k] {
+			changes = append(changes, SchemaChange{Model: model, Message: "index removed: " + k})
+		}
+	}
+	for _, ix := range current {
+		if k := key(ix); !prev[k] {
+			changes = append(changes, SchemaChange{Model: model, Message: "index added: " + k})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,89 @@
+package goodm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	useTestStore(t)
+
+	snap := Snapshot(GetAll())
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, snap); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	read, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if changes := DiffSnapshots(read, Snapshot(GetAll())); len(changes) != 0 {
+		t.Fatalf("expected no changes after a round trip, got %v", changes)
+	}
+
+	var user *ModelSnapshot
+	for i := range read.Models {
+		if read.Models[i].Name == "testUser" {
+			user = &read.Models[i]
+		}
+	}
+	if user == nil || user.Collection != "test_users" {
+		t.Fatalf("expected testUser in snapshot, got %+v", read.Models)
+	}
+	for _, f := range user.Fields {
+		if f.Name == "email" && (!f.Unique || !f.Required || f.Type != "string") {
+			t.Fatalf("unexpected email field: %+v", f)
+		}
+	}
+
+	if _, err := ReadSnapshot(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Fatal("expected unknown snapshot version to be rejected")
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	previous := &SchemaSnapshot{Version: SnapshotVersion, Models: []ModelSnapshot{
+		{Name: "User", Collection: "users", Fields: []FieldSnapshot{
+			{Name: "email", Type: "string", Unique: true},
+			{Name: "age", Type: "int"},
+			{Name: "nickname", Type: "string"},
+			{Name: "address", Type: "Address", Fields: []FieldSnapshot{{Name: "city", Type: "string"}}},
+		}},
+		{Name: "Legacy", Collection: "legacy"},
+	}}
+	current := &SchemaSnapshot{Version: SnapshotVersion, Models: []ModelSnapshot{
+		{Name: "User", Collection: "users", Fields: []FieldSnapshot{
+			{Name: "email", Type: "string"},
+			{Name: "age", Type: "int64"},
+			{Name: "address", Type: "Address", Fields: []FieldSnapshot{{Name: "city", Type: "string"}, {Name: "zip", Type: "string", Required: true}}},
+			{Name: "role", Type: "string", Required: true, Default: "user"},
+			{Name: "tenant", Type: "string", Required: true},
+		}},
+		{Name: "Order", Collection: "orders"},
+	}}
+
+	got := map[string]bool{}
+	for _, c := range DiffSnapshots(previous, current) {
+		got[c.String()] = c.Breaking
+	}
+	want := map[string]bool{
+		"User.email: no longer unique":                                false,
+		"User.age: type changed from int to int64":                    true,
+		"User.nickname: field removed":                                true,
+		"User.address.zip: field added as required without a default": true,
+		"User.role: field added as required":                          false,
+		"User.tenant: field added as required without a default":      true,
+		"Legacy: model removed":                                       true,
+		"Order: model added":                                          false,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d changes, got %v", len(want), got)
+	}
+	for msg, breaking := range want {
+		b, ok := got[msg]
+		if !ok || b != breaking {
+			t.Fatalf("expected %q (breaking=%v), got %v", msg, breaking, got)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SoftDeleteModel is a base struct for models whose documents are marked
+// deleted instead of removed. Embed it in place of Model:
+//
+//	type Comment struct {
+//	    goodm.SoftDeleteModel `bson:",inline"`
+//	    Body string `bson:"body"`
+//	}
+//
+// Delete, DeleteOne, DeleteMany, and FindOneAndDelete set DeletedAt instead
+// of removing documents, and every read, update, and delete by filter skips
+// documents with DeletedAt set. Use FindWithDeleted to include them, Restore
+// to undelete one, and HardDelete to remove one for good.
+type SoftDeleteModel struct {
+	Model     `bson:",inline"`
+	DeletedAt *time.Time `bson:"deleted_at,omitempty"`
+}
+
+// IsDeleted reports whether the document has been soft deleted.
+func (m *SoftDeleteModel) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+var softDeleteModelType = reflect.TypeOf(SoftDeleteModel{})
+
+// softDeleteField returns the bson name of the deletion time of models of
+// type t, or "" if t does not embed SoftDeleteModel.
+func softDeleteField(t reflect.Type) string {
+	if f, ok := t.FieldByName("SoftDeleteModel"); ok && f.Anonymous && f.Type == softDeleteModelType {
+		return "deleted_at"
+	}
+	return ""
+}
+
+type withDeletedKey struct{}
+
+// withDeleted returns a context whose operations also see soft-deleted
+// documents.
+func withDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withDeletedKey{}, true)
+}
+
+// includesDeleted reports whether ctx was returned by withDeleted.
+func includesDeleted(ctx context.Context) bool {
+	on, _ := ctx.Value(withDeletedKey{}).(bool)
+	return on
+}
+
+// notDeletedFilter returns the filter matching the documents of schema that
+// are not soft deleted, or nil if schema is not soft-deletable or ctx
+// includes deleted documents.
+func notDeletedFilter(ctx context.Context, schema *Schema) interface{} {
+	if schema.SoftDelete == "" || includesDeleted(ctx) {
+		return nil
+	}
+	return bson.D{{Key: schema.SoftDelete, Value: nil}}
+}
+
+// softDeleteUpdate returns the update marking documents of schema deleted at
+// now.
+func softDeleteUpdate(schema *Schema, now time.Time) bson.D {
+	keys := schema.base()
+	return bson.D{
+		{Key: "$set", Value: bson.D{{Key: schema.SoftDelete, Value: now}, {Key: keys.updatedAt, Value: now}}},
+		{Key: "$inc", Value: bson.D{{Key: keys.version, Value: 1}}},
+	}
+}
+
+// softDelete marks the document id deleted and reflects the change onto
+// model. It returns ErrNotFound if the document is missing or already
+// deleted.
+func softDelete(ctx context.Context, coll collection, schema *Schema, model interface{}, id bson.ObjectID) error {
+	now := clockNow()
+	filter := bson.D{{Key: "_id", Value: id}, {Key: schema.SoftDelete, Value: nil}}
+	result, err := coll.UpdateOne(ctx, filter, softDeleteUpdate(schema, now), withComment(ctx, options.UpdateOne()))
+	if err != nil {
+		return fmt.Errorf("goodm: delete failed: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	setDeletedAt(model, &now)
+	setUpdatedAt(model, now)
+	version, _ := getModelVersion(model)
+	setModelVersion(model, version+1)
+	trackWritten(model, []string{schema.SoftDelete, schema.base().updatedAt, schema.base().version})
+	return nil
+}
+
+// setDeletedAt sets DeletedAt on a model via reflection.
+func setDeletedAt(model interface{}, at *time.Time) {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	if f := v.FieldByName("DeletedAt"); f.IsValid() && f.CanSet() {
+		f.Set(reflect.ValueOf(at))
+	}
+}
+
+// FindWithDeleted finds all documents matching filter, as Find does, but
+// includes soft-deleted documents of models embedding SoftDeleteModel.
+//
+// Example:
+//
+//	var comments []Comment
+//	err := goodm.FindWithDeleted(ctx, bson.D{{Key: "post", Value: postID}}, &comments)
+//	for _, c := range comments {
+//	    if c.IsDeleted() { ... }
+//	}
+func FindWithDeleted(ctx context.Context, filter interface{}, results interface{}, opts ...FindOptions) error {
+	return Find(withDeleted(ctx), filter, results, opts...)
+}
+
+// Restore undeletes a soft-deleted document: it clears DeletedAt, sets
+// UpdatedAt, and increments Version, in the database and on model. No hooks
+// run. It returns ErrNotFound if the document is missing or not deleted, and
+// an error for models not embedding SoftDeleteModel.
+//
+// Example:
+//
+//	err := goodm.Restore(ctx, &comment)
+func Restore(ctx context.Context, model interface{}, opts ...UpdateOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	if schema.SoftDelete == "" {
+		return fmt.Errorf("goodm: %s does not embed SoftDeleteModel", schema.ModelName)
+	}
+
+	id, err := getModelID(model)
+	if err != nil {
+		return err
+	}
+	if id.IsZero() {
+		return fmt.Errorf("goodm: cannot restore document with zero ID")
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpUpdate, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: model,
+		Filter: bson.D{{Key: "_id", Value: id}}, filterable: true,
+	}, func(ctx context.Context) error {
+		var opt UpdateOptions
+		if len(opts) > 0 {
+			opt = opts[0]
+		}
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		coll := getCollection(db, schema)
+		if err := checkPolicyScope(ctx, coll, schema, id, OpUpdate); err != nil {
+			return err
+		}
+
+		now := clockNow()
+		keys := schema.base()
+		result, err := coll.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: id}, {Key: schema.SoftDelete, Value: bson.D{{Key: "$ne", Value: nil}}}},
+			bson.D{
+				{Key: "$set", Value: bson.D{{Key: keys.updatedAt, Value: now}}},
+				{Key: "$unset", Value: bson.D{{Key: schema.SoftDelete, Value: ""}}},
+				{Key: "$inc", Value: bson.D{{Key: keys.version, Value: 1}}},
+			}, withComment(ctx, options.UpdateOne()))
+		if err != nil {
+			return fmt.Errorf("goodm: restore failed: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			return ErrNotFound
+		}
+
+		setDeletedAt(model, nil)
+		setUpdatedAt(model, now)
+		version, _ := getModelVersion(model)
+		setModelVersion(model, version+1)
+		trackWritten(model, []string{schema.SoftDelete, keys.updatedAt, keys.version})
+		return nil
+	})
+}
+
+// HardDelete permanently removes a document by its ID, whether or not it is
+// soft deleted, with the hooks and ondelete rules of Delete. For models not
+// embedding SoftDeleteModel it is the same as Delete.
+//
+// Example:
+//
+//	err := goodm.HardDelete(ctx, &comment)
+func HardDelete(ctx context.Context, model interface{}, opts ...DeleteOptions) error {
+	return deleteModel(ctx, model, true, opts)
+}
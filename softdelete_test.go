@@ -0,0 +1,136 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testComment struct {
+	SoftDeleteModel `bson:",inline"`
+	Post            string   `bson:"post"`
+	Body            string   `bson:"body"`
+	Events          []string `bson:"-"`
+}
+
+func (c *testComment) BeforeDelete(ctx context.Context) error {
+	c.Events = append(c.Events, "before_delete")
+	return nil
+}
+
+func (c *testComment) AfterDelete(ctx context.Context) error {
+	c.Events = append(c.Events, "after_delete")
+	return nil
+}
+
+func TestSoftDelete(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testComment{}, "comments"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testComment")
+		registryMu.Unlock()
+		SetClock(nil)
+	})
+	if schema, _ := Get("testComment"); schema.SoftDelete != "deleted_at" {
+		t.Fatalf("expected a soft-delete schema, got %q", schema.SoftDelete)
+	}
+	deletedAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return deletedAt }))
+
+	var comments []*testComment
+	for _, body := range []string{"first", "second", "third", "fourth"} {
+		c := &testComment{Post: "p1", Body: body}
+		if err := Create(ctx, c); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		comments = append(comments, c)
+	}
+	postFilter := bson.D{{Key: "post", Value: "p1"}}
+	count := func(find func(context.Context, interface{}, interface{}, ...FindOptions) error) int {
+		t.Helper()
+		var found []testComment
+		if err := find(ctx, postFilter, &found); err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		return len(found)
+	}
+
+	c := comments[0]
+	if err := Delete(ctx, c); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !c.IsDeleted() || !c.DeletedAt.Equal(deletedAt) || c.Version != 1 {
+		t.Errorf("expected the model to be marked deleted, got %+v", c.SoftDeleteModel)
+	}
+	if len(c.Events) != 2 {
+		t.Errorf("expected delete hooks, got %v", c.Events)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: c.ID}}, &testComment{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected FindOne to skip the deleted comment, got %v", err)
+	}
+	if n := count(Find); n != 3 {
+		t.Errorf("expected Find to return 3 comments, got %d", n)
+	}
+	if n := count(FindWithDeleted); n != 4 {
+		t.Errorf("expected FindWithDeleted to return 4 comments, got %d", n)
+	}
+	if err := Delete(ctx, c); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected deleting twice to return ErrNotFound, got %v", err)
+	}
+
+	if err := Restore(ctx, c); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if c.IsDeleted() || c.Version != 2 {
+		t.Errorf("expected the model to be restored, got %+v", c.SoftDeleteModel)
+	}
+	if err := Restore(ctx, c); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected restoring a live comment to return ErrNotFound, got %v", err)
+	}
+	if n := count(Find); n != 4 {
+		t.Errorf("expected the restored comment to be found, got %d", n)
+	}
+
+	// Deletes by filter mark documents deleted too.
+	if err := DeleteOne(ctx, bson.D{{Key: "body", Value: "second"}}, &testComment{}); err != nil {
+		t.Fatalf("delete one: %v", err)
+	}
+	res, err := DeleteMany(ctx, bson.D{{Key: "body", Value: bson.D{{Key: "$in", Value: bson.A{"second", "third"}}}}}, &testComment{})
+	if err != nil {
+		t.Fatalf("delete many: %v", err)
+	}
+	if res.DeletedCount != 1 {
+		t.Errorf("expected DeleteMany to skip the deleted comment, got %d", res.DeletedCount)
+	}
+	var removed testComment
+	if err := FindOneAndDelete(ctx, bson.D{{Key: "body", Value: "fourth"}}, &removed); err != nil {
+		t.Fatalf("find one and delete: %v", err)
+	}
+	if !removed.IsDeleted() {
+		t.Errorf("expected FindOneAndDelete to return the deleted comment, got %+v", removed)
+	}
+	if n := count(Find); n != 1 {
+		t.Errorf("expected 1 live comment, got %d", n)
+	}
+	if n, err := activeTestStore().Collection("comments").CountDocuments(ctx, bson.D{}); err != nil || n != 4 {
+		t.Errorf("expected all 4 documents to be kept, got %d (%v)", n, err)
+	}
+
+	// HardDelete removes a document, deleted or not.
+	if err := HardDelete(ctx, comments[1]); err != nil {
+		t.Fatalf("hard delete: %v", err)
+	}
+	if n := count(FindWithDeleted); n != 3 {
+		t.Errorf("expected 3 documents after the hard delete, got %d", n)
+	}
+
+	if err := Restore(ctx, &testUser{Model: Model{ID: bson.NewObjectID()}}); err == nil {
+		t.Error("expected Restore to reject a model without soft delete")
+	}
+}
@@ -0,0 +1,205 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// statsBuckets are the upper bounds of the duration histogram kept for every
+// operation.
+var statsBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram is a snapshot of operation durations.
+type Histogram struct {
+	Bounds []time.Duration // upper bound of each bucket
+	Counts []int64         // operations per bucket; the last, extra entry counts those above every bound
+	Count  int64           // operations observed
+	Sum    time.Duration   // total duration of the operations observed
+}
+
+// Mean returns the average duration, or 0 if nothing was observed.
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile returns the upper bound of the bucket holding the q-th quantile
+// (0 < q <= 1), or the largest bound if it falls above every bound.
+func (h Histogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 || len(h.Bounds) == 0 {
+		return 0
+	}
+	rank := int64(q * float64(h.Count))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, n := range h.Counts[:len(h.Bounds)] {
+		seen += n
+		if seen >= rank {
+			return h.Bounds[i]
+		}
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
+
+// OperationStats holds the counters of one operation type on one
+// collection.
+type OperationStats struct {
+	Collection string // empty for RunCommand
+	Operation  OpType
+	InFlight   int64 // operations running now
+	Errors     int64 // completed operations that returned an error
+	Timeouts   int64 // errors caused by an exceeded deadline or driver timeout
+	Duration   Histogram
+}
+
+// StatsSnapshot is a point-in-time copy of goodm's operation counters.
+type StatsSnapshot struct {
+	Operations []OperationStats // sorted by collection and operation
+}
+
+// InFlight returns the number of operations running on collection, or on
+// every collection if it is empty.
+func (s StatsSnapshot) InFlight(collection string) int64 {
+	var n int64
+	for _, o := range s.Operations {
+		if collection == "" || o.Collection == collection {
+			n += o.InFlight
+		}
+	}
+	return n
+}
+
+type statsKey struct {
+	collection string
+	op         OpType
+}
+
+type opCounters struct {
+	inFlight int64
+	errors   int64
+	timeouts int64
+	counts   []int64 // len(statsBuckets)+1
+	count    int64
+	sum      time.Duration
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = make(map[statsKey]*opCounters)
+)
+
+// Stats returns the operation counters goodm keeps for every operation run
+// through the middleware chain: operations in flight, errors, and a
+// duration histogram per collection and operation type. It needs no
+// metrics library, so a health endpoint can embed it directly.
+//
+// Example:
+//
+//	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+//	    s := goodm.Stats()
+//	    json.NewEncoder(w).Encode(map[string]interface{}{"db_in_flight": s.InFlight("")})
+//	})
+func Stats() StatsSnapshot {
+	statsMu.Lock()
+	out := make([]OperationStats, 0, len(stats))
+	for key, c := range stats {
+		out = append(out, OperationStats{
+			Collection: key.collection,
+			Operation:  key.op,
+			InFlight:   c.inFlight,
+			Errors:     c.errors,
+			Timeouts:   c.timeouts,
+			Duration: Histogram{
+				Bounds: append([]time.Duration(nil), statsBuckets...),
+				Counts: append([]int64(nil), c.counts...),
+				Count:  c.count,
+				Sum:    c.sum,
+			},
+		})
+	}
+	statsMu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Collection != out[j].Collection {
+			return out[i].Collection < out[j].Collection
+		}
+		return out[i].Operation < out[j].Operation
+	})
+	return StatsSnapshot{Operations: out}
+}
+
+// ResetStats discards all completed-operation counters. Operations in
+// flight stay counted.
+func ResetStats() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	for key, c := range stats {
+		if c.inFlight == 0 {
+			delete(stats, key)
+			continue
+		}
+		stats[key] = &opCounters{inFlight: c.inFlight, counts: make([]int64, len(statsBuckets)+1)}
+	}
+}
+
+// trackOp counts the operation of info as in flight. The returned function
+// records its completion.
+func trackOp(info *OpInfo) func(err error) {
+	key := statsKey{info.Collection, info.Operation}
+	start := time.Now()
+
+	statsMu.Lock()
+	counters(key).inFlight++
+	statsMu.Unlock()
+
+	return func(err error) {
+		d := time.Since(start)
+		i := sort.Search(len(statsBuckets), func(i int) bool { return d <= statsBuckets[i] })
+
+		statsMu.Lock()
+		defer statsMu.Unlock()
+		c := counters(key)
+		c.inFlight--
+		c.counts[i]++
+		c.count++
+		c.sum += d
+		if err != nil {
+			c.errors++
+			if errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err) {
+				c.timeouts++
+			}
+		}
+	}
+}
+
+// counters returns the counters of key, creating them. statsMu must be held.
+func counters(key statsKey) *opCounters {
+	c := stats[key]
+	if c == nil {
+		c = &opCounters{counts: make([]int64, len(statsBuckets)+1)}
+		stats[key] = c
+	}
+	return c
+}
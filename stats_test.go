@@ -0,0 +1,111 @@
+package goodm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func findStats(s StatsSnapshot, collection string, op OpType) OperationStats {
+	for _, o := range s.Operations {
+		if o.Collection == collection && o.Operation == op {
+			return o
+		}
+	}
+	return OperationStats{}
+}
+
+func TestStats(t *testing.T) {
+	ctx := useTestStore(t)
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	ResetStats()
+	t.Cleanup(ResetStats)
+
+	var inFlight int64
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		inFlight = Stats().InFlight("test_users")
+		return next(ctx)
+	})
+
+	if err := Create(ctx, &testUser{Email: "stats@test.com", Name: "Stats"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if inFlight != 1 {
+		t.Errorf("expected the create to be in flight, got %d", inFlight)
+	}
+	var u testUser
+	if err := FindOne(ctx, bson.D{{Key: "email", Value: "missing@test.com"}}, &u); err == nil {
+		t.Fatal("expected ErrNotFound")
+	}
+	if err := FindOne(ctx, bson.D{{Key: "email", Value: "stats@test.com"}}, &u); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	s := Stats()
+	if s.InFlight("") != 0 {
+		t.Errorf("expected nothing in flight, got %d", s.InFlight(""))
+	}
+	create := findStats(s, "test_users", OpCreate)
+	if create.Duration.Count != 1 || create.Errors != 0 {
+		t.Errorf("unexpected create stats: %+v", create)
+	}
+	find := findStats(s, "test_users", OpFind)
+	if find.Duration.Count != 2 || find.Errors != 1 {
+		t.Errorf("unexpected find stats: %+v", find)
+	}
+	var sum int64
+	for _, n := range find.Duration.Counts {
+		sum += n
+	}
+	if sum != 2 || len(find.Duration.Counts) != len(find.Duration.Bounds)+1 {
+		t.Errorf("unexpected histogram: %+v", find.Duration)
+	}
+
+	ResetStats()
+	if n := len(Stats().Operations); n != 0 {
+		t.Errorf("expected no stats after reset, got %d", n)
+	}
+}
+
+func TestStats_Timeouts(t *testing.T) {
+	ResetStats()
+	t.Cleanup(ResetStats)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_ = runMiddleware(ctx, &OpInfo{Operation: OpRunCommand}, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+
+	if o := findStats(Stats(), "", OpRunCommand); o.Errors != 1 || o.Timeouts != 1 {
+		t.Errorf("expected one timeout, got %+v", o)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := Histogram{
+		Bounds: []time.Duration{time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond},
+		Counts: []int64{5, 3, 1, 1},
+		Count:  10,
+		Sum:    500 * time.Millisecond,
+	}
+	if h.Mean() != 50*time.Millisecond {
+		t.Errorf("mean: got %v", h.Mean())
+	}
+	if q := h.Quantile(0.5); q != time.Millisecond {
+		t.Errorf("p50: got %v", q)
+	}
+	if q := h.Quantile(0.8); q != 10*time.Millisecond {
+		t.Errorf("p80: got %v", q)
+	}
+	if q := h.Quantile(1); q != 100*time.Millisecond {
+		t.Errorf("p100: got %v", q)
+	}
+	if (Histogram{}).Quantile(0.5) != 0 {
+		t.Error("expected 0 for an empty histogram")
+	}
+}
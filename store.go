@@ -0,0 +1,111 @@
+package goodm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dwoolworth/goodm/internal/memstore"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// collection is the subset of *mongo.Collection that goodm operations use.
+// It is satisfied by *mongo.Collection and by the in-memory test store.
+type collection interface {
+	InsertOne(ctx context.Context, document interface{}, opts ...options.Lister[options.InsertOneOptions]) (*mongo.InsertOneResult, error)
+	InsertMany(ctx context.Context, documents interface{}, opts ...options.Lister[options.InsertManyOptions]) (*mongo.InsertManyResult, error)
+	FindOne(ctx context.Context, filter interface{}, opts ...options.Lister[options.FindOneOptions]) *mongo.SingleResult
+	Find(ctx context.Context, filter interface{}, opts ...options.Lister[options.FindOptions]) (*mongo.Cursor, error)
+	CountDocuments(ctx context.Context, filter interface{}, opts ...options.Lister[options.CountOptions]) (int64, error)
+	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...options.Lister[options.UpdateOneOptions]) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...options.Lister[options.UpdateManyOptions]) (*mongo.UpdateResult, error)
+	FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...options.Lister[options.FindOneAndUpdateOptions]) *mongo.SingleResult
+	FindOneAndDelete(ctx context.Context, filter interface{}, opts ...options.Lister[options.FindOneAndDeleteOptions]) *mongo.SingleResult
+	ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...options.Lister[options.ReplaceOptions]) (*mongo.UpdateResult, error)
+	DeleteOne(ctx context.Context, filter interface{}, opts ...options.Lister[options.DeleteOneOptions]) (*mongo.DeleteResult, error)
+	DeleteMany(ctx context.Context, filter interface{}, opts ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error)
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...options.Lister[options.AggregateOptions]) (*mongo.Cursor, error)
+}
+
+var (
+	storeMu  sync.RWMutex
+	memStore *memstore.Store
+)
+
+// UseTestStore switches goodm to a fresh in-memory store, so code that calls
+// goodm can be unit tested without a running MongoDB. While it is active,
+// operations that don't pass an explicit DB option read and write the
+// in-memory store instead of the global database. Call ClearTestStore to
+// switch back.
+//
+// The store supports the common query operators ($eq, $ne, $gt, $gte, $lt,
+// $lte, $in, $nin, $exists, $and, $or, $nor, $not, $regex, $size, $all,
+// $elemMatch, and $expr with comparisons, logical, and arithmetic operators),
+// update operators ($set, $unset, $inc, $mul, $min, $max,
+// $push, $addToSet, $pull, $pop, $rename, $currentDate, $setOnInsert),
+// sorting, paging, top-level projections, and unique fields declared on
+// registered models. Aggregation pipelines and Explain are not supported, and
+// WithTransaction runs its function without isolation.
+func UseTestStore() {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	memStore = memstore.New(CodecRegistry())
+}
+
+// ClearTestStore discards the in-memory store installed by UseTestStore and
+// restores the global database.
+func ClearTestStore() {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	memStore = nil
+}
+
+// activeTestStore returns the in-memory store, or nil when none is installed.
+func activeTestStore() *memstore.Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return memStore
+}
+
+// dbHandle is the backend an operation resolved with getDB: a MongoDB
+// database, or the in-memory store that was active at that moment. The store
+// is kept rather than looked up again, so a ClearTestStore while the
+// operation runs cannot leave it without a backend.
+type dbHandle struct {
+	*mongo.Database
+	mem *memstore.Store
+}
+
+// namedCollection returns the collection called name in db's database or
+// in-memory store.
+func namedCollection(db dbHandle, name string) collection {
+	if db.mem != nil {
+		return db.mem.Collection(name)
+	}
+	return db.Collection(name)
+}
+
+// testStoreCollection returns the schema's in-memory collection with the
+// schema's unique fields and unique compound indexes enforced.
+func testStoreCollection(store *memstore.Store, schema *Schema) collection {
+	c := store.Collection(schema.Collection)
+	var unique [][]string
+	for _, f := range schema.Fields {
+		if f.Unique {
+			unique = append(unique, []string{f.BSONName})
+		}
+	}
+	for _, ci := range schema.CompoundIndexes {
+		if ci.Unique {
+			unique = append(unique, ci.Fields)
+		}
+	}
+	c.SetUnique(unique)
+	return c
+}
+
+// compile-time checks that both backends satisfy collection.
+var (
+	_ collection = (*mongo.Collection)(nil)
+	_ collection = (*memstore.Collection)(nil)
+)
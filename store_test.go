@@ -0,0 +1,260 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func useTestStore(t *testing.T) context.Context {
+	t.Helper()
+	UseTestStore()
+	registerTestModels()
+	t.Cleanup(func() {
+		ClearTestStore()
+		unregisterTestModels()
+	})
+	return context.Background()
+}
+
+func TestTestStore_CRUD(t *testing.T) {
+	ctx := useTestStore(t)
+
+	u := &testUser{Email: "mem@test.com", Name: "Mem", Age: 30}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if u.ID.IsZero() || u.Role != "user" {
+		t.Fatalf("expected ID and default role, got %+v", u)
+	}
+
+	found := &testUser{}
+	if err := FindOne(ctx, bson.D{{Key: "email", Value: "mem@test.com"}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.ID != u.ID || found.Age != 30 {
+		t.Fatalf("unexpected document: %+v", found)
+	}
+
+	found.Age = 31
+	if err := Update(ctx, found); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	u.Age = 32
+	if err := Update(ctx, u); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict for stale model, got %v", err)
+	}
+
+	if err := UpdateOne(ctx, bson.D{{Key: "_id", Value: u.ID}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "age", Value: 4}}}}, &testUser{}); err != nil {
+		t.Fatalf("update one: %v", err)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.Age != 35 || found.Version != 1 {
+		t.Fatalf("expected age 35 at version 1, got %d at %d", found.Age, found.Version)
+	}
+
+	if err := Delete(ctx, found); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, &testUser{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestTestStore_FindOptionsAndBulk(t *testing.T) {
+	ctx := useTestStore(t)
+
+	users := []testUser{
+		{Email: "a@test.com", Name: "A", Age: 40},
+		{Email: "b@test.com", Name: "B", Age: 20},
+		{Email: "c@test.com", Name: "C", Age: 30, Role: "admin"},
+	}
+	if _, err := CreateMany(ctx, users); err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+
+	var results []testUser
+	err := Find(ctx, bson.D{{Key: "age", Value: bson.D{{Key: "$gte", Value: 25}}}}, &results, FindOptions{
+		Sort: bson.D{{Key: "age", Value: 1}},
+	})
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "C" || results[1].Name != "A" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	results = nil
+	if err := Find(ctx, bson.D{}, &results, FindOptions{Sort: bson.D{{Key: "age", Value: -1}}, Skip: 1, Limit: 1}); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "C" {
+		t.Fatalf("expected C after skip/limit, got %+v", results)
+	}
+
+	n, err := UpdateMany(ctx, bson.D{{Key: "role", Value: "user"}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "role", Value: "admin"}}}}, &testUser{})
+	if err != nil || n.MatchedCount != 2 {
+		t.Fatalf("expected 2 updated, got %+v, %v", n, err)
+	}
+
+	deleted, err := DeleteMany(ctx, bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "name", Value: "A"}},
+		bson.D{{Key: "name", Value: "B"}},
+	}}}, &testUser{})
+	if err != nil || deleted.DeletedCount != 2 {
+		t.Fatalf("expected 2 deleted, got %+v, %v", deleted, err)
+	}
+}
+
+func TestTestStore_UniqueFields(t *testing.T) {
+	ctx := useTestStore(t)
+
+	if err := Create(ctx, &testUser{Email: "dup@test.com", Name: "One"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	err := Create(ctx, &testUser{Email: "dup@test.com", Name: "Two"})
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+}
+
+func TestTestStore_HiddenFieldsAndPopulate(t *testing.T) {
+	ctx := useTestStore(t)
+
+	a := &testAccount{Username: "alice", PasswordHash: "h4sh"}
+	if err := Create(ctx, a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	found := &testAccount{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: a.ID}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.PasswordHash != "" {
+		t.Fatalf("expected hidden field to be excluded, got %q", found.PasswordHash)
+	}
+
+	author := &testUser{Email: "author@test.com", Name: "Author"}
+	if err := Create(ctx, author); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	post := &testPost{Title: "Hello", AuthorID: author.ID}
+	if err := Create(ctx, post); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	populated := &testUser{}
+	if err := Populate(ctx, post, Refs{"author": populated}); err != nil {
+		t.Fatalf("populate: %v", err)
+	}
+	if populated.Email != "author@test.com" {
+		t.Fatalf("expected populated author, got %+v", populated)
+	}
+}
+
+func TestTestStore_Unsupported(t *testing.T) {
+	ctx := useTestStore(t)
+
+	if _, err := Explain(ctx, bson.D{}, &testUser{}); err == nil {
+		t.Fatal("expected Explain to be unsupported")
+	}
+	var out []bson.M
+	if err := NewPipeline(&testUser{}).Execute(ctx, &out); err == nil {
+		t.Fatal("expected aggregation to be unsupported")
+	}
+	called := false
+	if err := WithTransaction(ctx, func(ctx context.Context) error { called = true; return nil }); err != nil || !called {
+		t.Fatalf("expected WithTransaction to run fn, got called=%v err=%v", called, err)
+	}
+}
+
+func TestTestStore_Upsert(t *testing.T) {
+	ctx := useTestStore(t)
+
+	filter := bson.D{{Key: "email", Value: "new@test.com"}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "New"}, {Key: "age", Value: 5}}}}
+	if err := UpdateOne(ctx, filter, update, &testUser{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound without upsert, got %v", err)
+	}
+	if err := UpdateOne(ctx, filter, update, &testUser{}, UpdateOptions{Upsert: true}); err != nil {
+		t.Fatalf("upsert one: %v", err)
+	}
+	found := &testUser{}
+	if err := FindOne(ctx, filter, found); err != nil {
+		t.Fatalf("find upserted: %v", err)
+	}
+	if found.Name != "New" || found.Age != 5 {
+		t.Fatalf("unexpected upserted document: %+v", found)
+	}
+
+	res, err := UpdateMany(ctx, bson.D{{Key: "email", Value: "other@test.com"}}, update, &testUser{}, UpdateOptions{Upsert: true})
+	if err != nil {
+		t.Fatalf("upsert many: %v", err)
+	}
+	if res.UpsertedCount != 1 || res.MatchedCount != 0 {
+		t.Fatalf("expected 1 upserted and 0 matched, got %+v", res)
+	}
+
+	if _, err := UpdateMany(ctx, bson.D{}, update, &testUser{}, UpdateOptions{ArrayFilters: []interface{}{bson.D{}}}); err == nil {
+		t.Fatal("expected array filters to be unsupported")
+	}
+}
+
+func TestTestStore_Touch(t *testing.T) {
+	ctx := useTestStore(t)
+
+	u := &testUser{Email: "touch@test.com", Name: "Touch", Age: 20}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	before := u.UpdatedAt
+	stale := *u
+	u.Age = 99 // not written by Touch
+
+	time.Sleep(2 * time.Millisecond)
+	if err := Touch(ctx, u); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	if u.Version != 1 || !u.UpdatedAt.After(before) {
+		t.Fatalf("expected version 1 and a later UpdatedAt, got %d at %v", u.Version, u.UpdatedAt)
+	}
+
+	found := &testUser{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.Version != 1 || found.Age != 20 || !found.UpdatedAt.After(before) {
+		t.Fatalf("expected only version and updated_at to change, got %+v", found)
+	}
+
+	if err := Touch(ctx, &stale, WithVersionCheck()); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict for stale model, got %v", err)
+	}
+	if err := Touch(ctx, &testUser{}); err == nil {
+		t.Fatal("expected an error for a zero ID")
+	}
+}
+
+func TestTestStore_ClearedDuringOperation(t *testing.T) {
+	ctx := useTestStore(t)
+
+	db, err := getDB(nil)
+	if err != nil {
+		t.Fatalf("getDB: %v", err)
+	}
+	ClearTestStore()
+
+	schema, _ := Get("testUser")
+	if _, err := getCollection(db, schema).InsertOne(ctx, bson.D{{Key: "email", Value: "late@test.com"}}); err != nil {
+		t.Fatalf("expected the resolved store to serve the operation, got %v", err)
+	}
+	if n, _ := db.mem.Collection("test_users").CountDocuments(ctx, bson.D{}); n != 1 {
+		t.Fatalf("expected the insert in the resolved store, got %d documents", n)
+	}
+}
@@ -0,0 +1,132 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// UnknownFieldsError reports a stored document with top-level fields that are
+// not part of its model. Strict reads return it; the handler installed with
+// SetUnknownFieldsHandler receives it for every such document read.
+type UnknownFieldsError struct {
+	Collection string
+	ID         interface{} // _id of the document
+	Fields     []string    // unknown bson field names, in document order
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("goodm: document %v in %s has unknown fields: %s", e.ID, e.Collection, strings.Join(e.Fields, ", "))
+}
+
+var (
+	unknownFieldsMu      sync.RWMutex
+	unknownFieldsHandler func(ctx context.Context, e *UnknownFieldsError)
+)
+
+// SetUnknownFieldsHandler installs fn to be called for every document read by
+// FindOne or Find that has top-level fields the model does not declare. It
+// complements DetectDrift, which samples documents at startup, with the drift
+// production reads actually encounter. Pass nil to remove the handler.
+//
+// Checking decodes the top-level keys of each document a second time.
+//
+// Example:
+//
+//	goodm.SetUnknownFieldsHandler(func(ctx context.Context, e *goodm.UnknownFieldsError) {
+//	    log.Printf("schema drift: %v", e)
+//	})
+func SetUnknownFieldsHandler(fn func(ctx context.Context, e *UnknownFieldsError)) {
+	unknownFieldsMu.Lock()
+	defer unknownFieldsMu.Unlock()
+	unknownFieldsHandler = fn
+}
+
+// unknownFieldsCheck returns a function that reports unknown fields in a raw
+// document read for schema, or nil when neither strict mode nor a handler
+// asks for the check. In strict mode the function returns an
+// *UnknownFieldsError for the first such document.
+func unknownFieldsCheck(ctx context.Context, schema *Schema, strict bool) func(raw bson.Raw) error {
+	unknownFieldsMu.RLock()
+	handler := unknownFieldsHandler
+	unknownFieldsMu.RUnlock()
+	if !strict && handler == nil {
+		return nil
+	}
+
+	known := make(map[string]bool, len(schema.Fields))
+	for _, f := range schema.Fields {
+		known[f.BSONName] = true
+		if name := shadowName(f); name != "" {
+			known[name] = true
+		}
+		if f.RenamedFrom != "" {
+			known[f.RenamedFrom] = true
+		}
+	}
+	return func(raw bson.Raw) error {
+		elems, err := raw.Elements()
+		if err != nil {
+			return fmt.Errorf("goodm: strict decode failed: %w", err)
+		}
+		var unknown []string
+		for _, e := range elems {
+			if key := e.Key(); !known[key] {
+				unknown = append(unknown, key)
+			}
+		}
+		if len(unknown) == 0 {
+			return nil
+		}
+
+		uerr := &UnknownFieldsError{Collection: schema.Collection, Fields: unknown}
+		if id, err := raw.LookupErr("_id"); err == nil {
+			var v interface{}
+			if err := id.Unmarshal(&v); err == nil {
+				uerr.ID = v
+			}
+		}
+		if handler != nil {
+			handler(ctx, uerr)
+		}
+		if strict {
+			return uerr
+		}
+		return nil
+	}
+}
+
+// decodeAllChecked decodes every document from cursor into results, a
+// pointer to a slice, running check, if set, on each raw document first and
+// fallback, if set, on each decoded element.
+func decodeAllChecked(ctx context.Context, cursor *mongo.Cursor, results interface{}, check func(raw bson.Raw) error, fallback func(raw bson.Raw, target interface{}) error) error {
+	sv := reflect.ValueOf(results).Elem()
+	out := reflect.MakeSlice(sv.Type(), 0, 0)
+	for cursor.Next(ctx) {
+		if check != nil {
+			if err := check(cursor.Current); err != nil {
+				return err
+			}
+		}
+		elem := reflect.New(sv.Type().Elem())
+		if err := cursor.Decode(elem.Interface()); err != nil {
+			return fmt.Errorf("goodm: cursor decode failed: %w", err)
+		}
+		if fallback != nil {
+			if err := fallback(cursor.Current, elem.Interface()); err != nil {
+				return err
+			}
+		}
+		out = reflect.Append(out, elem.Elem())
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("goodm: cursor decode failed: %w", err)
+	}
+	sv.Set(out)
+	return nil
+}
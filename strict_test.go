@@ -0,0 +1,82 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func insertDriftedUsers(t *testing.T, ctx context.Context) bson.ObjectID {
+	t.Helper()
+	id := bson.NewObjectID()
+	coll := activeTestStore().Collection("test_users")
+	docs := []interface{}{
+		bson.D{{Key: "_id", Value: id}, {Key: "email", Value: "drift@test.com"}, {Key: "name", Value: "Drift"},
+			{Key: "nickname", Value: "d"}, {Key: "legacy", Value: true}},
+		bson.D{{Key: "_id", Value: bson.NewObjectID()}, {Key: "email", Value: "clean@test.com"}, {Key: "name", Value: "Clean"}},
+	}
+	if _, err := coll.InsertMany(ctx, docs); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	return id
+}
+
+func TestStrictRead(t *testing.T) {
+	ctx := useTestStore(t)
+	id := insertDriftedUsers(t, ctx)
+	filter := bson.D{{Key: "email", Value: "drift@test.com"}}
+
+	if err := FindOne(ctx, filter, &testUser{}); err != nil {
+		t.Fatalf("expected lenient read to succeed, got %v", err)
+	}
+
+	err := FindOne(ctx, filter, &testUser{}, FindOptions{Strict: true})
+	var uerr *UnknownFieldsError
+	if !errors.As(err, &uerr) {
+		t.Fatalf("expected UnknownFieldsError, got %v", err)
+	}
+	if uerr.Collection != "test_users" || uerr.ID != id || len(uerr.Fields) != 2 ||
+		uerr.Fields[0] != "nickname" || uerr.Fields[1] != "legacy" {
+		t.Fatalf("unexpected error: %+v", uerr)
+	}
+
+	if err := FindOne(ctx, bson.D{{Key: "email", Value: "clean@test.com"}}, &testUser{}, FindOptions{Strict: true}); err != nil {
+		t.Fatalf("expected clean document to pass, got %v", err)
+	}
+
+	var users []testUser
+	if err := Find(ctx, bson.D{}, &users, FindOptions{Strict: true}); !errors.As(err, &uerr) {
+		t.Fatalf("expected Find to fail with UnknownFieldsError, got %v", err)
+	}
+}
+
+func TestUnknownFieldsHandler(t *testing.T) {
+	ctx := useTestStore(t)
+	insertDriftedUsers(t, ctx)
+
+	var reported []*UnknownFieldsError
+	SetUnknownFieldsHandler(func(ctx context.Context, e *UnknownFieldsError) {
+		reported = append(reported, e)
+	})
+	t.Cleanup(func() { SetUnknownFieldsHandler(nil) })
+
+	var users []testUser
+	if err := Find(ctx, bson.D{}, &users, FindOptions{Sort: bson.D{{Key: "email", Value: 1}}}); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(users) != 2 || users[0].Email != "clean@test.com" || users[1].Email != "drift@test.com" {
+		t.Fatalf("unexpected results: %+v", users)
+	}
+	if len(reported) != 1 || len(reported[0].Fields) != 2 {
+		t.Fatalf("expected one report with two fields, got %+v", reported)
+	}
+
+	if err := FindOne(ctx, bson.D{{Key: "email", Value: "drift@test.com"}}, &testUser{}); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if len(reported) != 2 {
+		t.Fatalf("expected FindOne to report, got %d reports", len(reported))
+	}
+}
@@ -6,8 +6,12 @@ import (
 )
 
 // ParseGoodmTag parses a `goodm:"..."` struct tag value into FieldSchema attributes.
-// Supported tags: unique, index, required, immutable, default=val, enum=a|b|c,
-// min=N, max=N, ref=collection
+// Supported tags: unique, index, required, immutable, writeonce, default=val,
+// enum=a|b|c, min=N, max=N, precision=N, scale=N, ref=collection (or
+// belongsto=collection), normalize=a|b, select=false, kind=value, collation=locale[_ci|_ai],
+// tree=parent|path, transitions=a>b,b>c|d, uniquewith=a|b,
+// ci (or ci=shadow), slug=source, autoincrement, dateonly, deprecated
+// (or deprecated=note), renamed_from=old
 func ParseGoodmTag(tag string) FieldSchema {
 	var fs FieldSchema
 	if tag == "" {
@@ -15,15 +19,24 @@ func ParseGoodmTag(tag string) FieldSchema {
 	}
 
 	parts := strings.Split(tag, ",")
+	var key string
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
 		if part == "" {
 			continue
 		}
 
+		// transitions=a>b,b>c lists its pairs across several parts.
+		if key == "transitions" && !strings.Contains(part, "=") && strings.Contains(part, ">") {
+			parseTagKeyValue(&fs, key, part)
+			continue
+		}
+
 		if k, v, ok := strings.Cut(part, "="); ok {
+			key = k
 			parseTagKeyValue(&fs, k, v)
 		} else {
+			key = ""
 			parseTagFlag(&fs, part)
 		}
 	}
@@ -46,8 +59,47 @@ func parseTagKeyValue(fs *FieldSchema, key, value string) {
 		if n, err := strconv.Atoi(value); err == nil {
 			fs.Max = &n
 		}
-	case "ref":
+	case "precision":
+		if n, err := strconv.Atoi(value); err == nil {
+			fs.Precision = &n
+		}
+	case "scale":
+		if n, err := strconv.Atoi(value); err == nil {
+			fs.Scale = &n
+		}
+	case "ref", "belongsto":
 		fs.Ref = value
+	case "normalize":
+		fs.Normalize = strings.Split(value, "|")
+	case "kind":
+		fs.Kind = value
+	case "collation":
+		fs.Collation = value
+	case "tree":
+		fs.Tree = value
+	case "ci":
+		fs.CaseInsensitive = value
+	case "slug":
+		fs.Slug = value
+	case "uniquewith":
+		fs.UniqueWith = strings.Split(value, "|")
+	case "transitions":
+		// from>to, or from>a|b for several targets
+		if from, to, ok := strings.Cut(value, ">"); ok {
+			if fs.Transitions == nil {
+				fs.Transitions = make(map[string][]string)
+			}
+			fs.Transitions[from] = append(fs.Transitions[from], strings.Split(to, "|")...)
+		}
+	case "deprecated":
+		fs.Deprecated = true
+		fs.DeprecatedNote = value
+	case "renamed_from":
+		fs.RenamedFrom = value
+	case "select":
+		if b, err := strconv.ParseBool(value); err == nil {
+			fs.Hidden = !b
+		}
 	}
 }
 
@@ -58,10 +110,20 @@ func parseTagFlag(fs *FieldSchema, flag string) {
 		fs.Unique = true
 	case "index":
 		fs.Index = true
+	case "ci":
+		fs.CaseInsensitive = CaseInsensitiveCollation
+	case "autoincrement":
+		fs.AutoIncrement = true
+	case "dateonly":
+		fs.DateOnly = true
 	case "required":
 		fs.Required = true
 	case "immutable":
 		fs.Immutable = true
+	case "writeonce":
+		fs.WriteOnce = true
+	case "deprecated":
+		fs.Deprecated = true
 	}
 }
 
@@ -106,6 +106,12 @@ type testOrder struct {
 	Items   []testOrderItem `bson:"items"`
 }
 
+type testAccount struct {
+	Model        `bson:",inline"`
+	Username     string `bson:"username"      goodm:"required"`
+	PasswordHash string `bson:"password_hash" goodm:"select=false"`
+}
+
 // --- test DB setup ---
 
 func setupTestDB(t *testing.T) (context.Context, *mongo.Database, func()) {
@@ -116,7 +122,7 @@ func setupTestDB(t *testing.T) (context.Context, *mongo.Database, func()) {
 	}
 
 	ctx := context.Background()
-	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(options.Client().ApplyURI(uri).SetRegistry(CodecRegistry()))
 	if err != nil {
 		t.Skipf("MongoDB not available: %v", err)
 	}
@@ -164,6 +170,7 @@ func registerTestModels() {
 	_ = Register(&testHookUser{}, "test_hook_users")
 	_ = Register(&testConfiguredModel{}, "test_configured")
 	_ = Register(&testOrder{}, "test_orders")
+	_ = Register(&testAccount{}, "test_accounts")
 }
 
 func unregisterTestModels() {
@@ -175,5 +182,6 @@ func unregisterTestModels() {
 	delete(registry, "testHookUser")
 	delete(registry, "testConfiguredModel")
 	delete(registry, "testOrder")
+	delete(registry, "testAccount")
 	registryMu.Unlock()
 }
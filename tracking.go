@@ -0,0 +1,217 @@
+package goodm
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// modelState records the stored form of a model as of its last load or save.
+type modelState struct {
+	original bson.Raw
+}
+
+// Tracked holds the change-tracking state of a model. Models embedding Model
+// are tracked through it; a custom base struct embeds Tracked, tagged
+// bson:"-", to track its models too:
+//
+//	type Base struct {
+//	    goodm.Tracked `bson:"-"`
+//	    ID            bson.ObjectID `bson:"_id,omitempty"`
+//	    ...
+//	}
+type Tracked struct {
+	state *modelState
+}
+
+var trackedType = reflect.TypeOf(Tracked{})
+
+// trackedState returns where model keeps its tracking state: in its
+// embedded Model or Tracked. It returns nil if model is not a pointer to a
+// struct embedding either.
+func trackedState(model interface{}) **modelState {
+	switch m := model.(type) {
+	case *Model:
+		return &m.state
+	case *Tracked:
+		return &m.state
+	}
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if f := v.FieldByName("Model"); f.IsValid() && f.Type() == modelBaseType && f.CanAddr() {
+		return &f.Addr().Interface().(*Model).state
+	}
+	if f := v.FieldByName("Tracked"); f.IsValid() && f.Type() == trackedType && f.CanAddr() {
+		return &f.Addr().Interface().(*Tracked).state
+	}
+	return nil
+}
+
+// track records model's current state as its persisted original.
+func track(model interface{}) {
+	st := trackedState(model)
+	if st == nil {
+		return
+	}
+	raw, err := marshalBSON(model)
+	if err != nil {
+		*st = nil
+		return
+	}
+	*st = &modelState{original: raw}
+}
+
+// trackWritten records the fields of model named in written, by bson name,
+// as persisted by a write of those fields only, and keeps the rest of its
+// tracked original. A model goodm has not loaded or saved is tracked whole.
+func trackWritten(model interface{}, written []string) {
+	base := originalDoc(model)
+	if base == nil {
+		track(model)
+		return
+	}
+	st := trackedState(model)
+	current, err := toBsonMap(model)
+	if err != nil {
+		*st = nil
+		return
+	}
+	for _, name := range written {
+		if v, ok := current[name]; ok {
+			base[name] = v
+		} else {
+			delete(base, name)
+		}
+	}
+	raw, err := marshalBSON(base)
+	if err != nil {
+		*st = nil
+		return
+	}
+	*st = &modelState{original: raw}
+}
+
+// afterLoad finishes a decoded result: it records the loaded state for
+// ChangedFields, checks dateonly fields, and computes virtual fields.
+func afterLoad(ctx context.Context, target interface{}) error {
+	trackLoaded(target)
+	if err := checkLoadedDateOnly(target); err != nil {
+		return err
+	}
+	return computeVirtuals(ctx, target)
+}
+
+// trackLoaded tracks a decoded result: a pointer to a model or a pointer to a
+// slice of models or model pointers.
+func trackLoaded(target interface{}) {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		track(target)
+		return
+	}
+	sv := rv.Elem()
+	for i := 0; i < sv.Len(); i++ {
+		elem := sv.Index(i)
+		if elem.Kind() == reflect.Interface {
+			elem = elem.Elem()
+		}
+		if !elem.IsValid() || elem.Kind() == reflect.Ptr && elem.IsNil() {
+			continue
+		}
+		if elem.Kind() != reflect.Ptr && !elem.CanAddr() {
+			continue
+		}
+		track(elemModel(elem))
+	}
+}
+
+// IsNew reports whether model has not been saved: it was neither loaded by
+// FindOne or Find nor saved by Create or Update, and has no ID.
+func IsNew(model interface{}) bool {
+	st := trackedState(model)
+	if st == nil || *st != nil {
+		return false
+	}
+	id, err := getModelID(model)
+	return err == nil && id.IsZero()
+}
+
+// IsDirty reports whether model has changes that are not stored: it is new,
+// or ChangedFields is not empty.
+func IsDirty(model interface{}) bool {
+	return IsNew(model) || len(ChangedFields(model)) > 0
+}
+
+// ChangedFields returns the bson names of the top-level fields that differ
+// from the values last loaded or saved, sorted. Managed fields (_id,
+// timestamps, __v) are not reported. It returns nil for models goodm has not
+// loaded or saved, and for models embedding neither Model nor Tracked.
+//
+// Example:
+//
+//	func (o *Order) BeforeSave(ctx context.Context) error {
+//	    for _, f := range goodm.ChangedFields(o) {
+//	        if f == "status" {
+//	            o.StatusChangedAt = time.Now()
+//	        }
+//	    }
+//	    return nil
+//	}
+func ChangedFields(model interface{}) []string {
+	base := originalDoc(model)
+	if base == nil {
+		return nil
+	}
+	current, err := toBsonMap(model)
+	if err != nil {
+		return nil
+	}
+	changed := diffFields(baseKeysOf(model), base, current)
+	sort.Strings(changed)
+	return changed
+}
+
+// originalDoc returns the stored form of model as it was last loaded or
+// saved, or nil for models goodm has not loaded or saved.
+func originalDoc(model interface{}) bson.M {
+	st := trackedState(model)
+	if st == nil || *st == nil {
+		return nil
+	}
+	var doc bson.M
+	if err := unmarshalBSON((*st).original, &doc); err != nil {
+		return nil
+	}
+	return doc
+}
+
+// Original returns a copy of model as it was last loaded or saved, of the
+// same type as model, or nil for models goodm has not loaded or saved.
+//
+// Example:
+//
+//	if prev, ok := goodm.Original(user).(*User); ok && prev.Email != user.Email {
+//	    sendVerification(user)
+//	}
+func Original(model interface{}) interface{} {
+	st := trackedState(model)
+	if st == nil || *st == nil {
+		return nil
+	}
+	orig := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+	if err := unmarshalBSON((*st).original, orig); err != nil {
+		return nil
+	}
+	if ost := trackedState(orig); ost != nil {
+		*ost = *st
+	}
+	return orig
+}
@@ -0,0 +1,139 @@
+package goodm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestTracking_Lifecycle(t *testing.T) {
+	ctx := useTestStore(t)
+
+	u := &testUser{Email: "track@test.com", Name: "Track", Age: 30}
+	if !IsNew(u) || !IsDirty(u) {
+		t.Fatal("expected an unsaved model to be new and dirty")
+	}
+	if ChangedFields(u) != nil || Original(u) != nil {
+		t.Fatal("expected no tracking data for an unsaved model")
+	}
+
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if IsNew(u) || IsDirty(u) {
+		t.Fatal("expected a created model to be clean")
+	}
+
+	loaded := &testUser{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: u.ID}}, loaded); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if IsNew(loaded) || IsDirty(loaded) {
+		t.Fatal("expected a loaded model to be clean")
+	}
+
+	loaded.Age = 31
+	loaded.Role = "admin"
+	loaded.UpdatedAt = loaded.UpdatedAt.Add(1)
+	if got := ChangedFields(loaded); !reflect.DeepEqual(got, []string{"age", "role"}) {
+		t.Fatalf("expected [age role] changed, got %v", got)
+	}
+	orig, ok := Original(loaded).(*testUser)
+	if !ok || orig.Age != 30 || orig.Role != "user" {
+		t.Fatalf("expected the loaded values, got %+v", orig)
+	}
+
+	if err := Update(ctx, loaded); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if IsDirty(loaded) {
+		t.Fatalf("expected a saved model to be clean, changed %v", ChangedFields(loaded))
+	}
+	if orig := Original(loaded).(*testUser); orig.Age != 31 {
+		t.Fatalf("expected the saved values as original, got age %d", orig.Age)
+	}
+}
+
+func TestTracking_FindAndCreateMany(t *testing.T) {
+	ctx := useTestStore(t)
+
+	users := []testUser{
+		{Email: "a@test.com", Name: "A"},
+		{Email: "b@test.com", Name: "B"},
+	}
+	if _, err := CreateMany(ctx, users); err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	for i := range users {
+		if IsNew(&users[i]) || IsDirty(&users[i]) {
+			t.Fatalf("expected created model %d to be clean", i)
+		}
+	}
+
+	var found []testUser
+	if err := Find(ctx, bson.D{}, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(found))
+	}
+	found[1].Age = 40
+	if IsDirty(&found[0]) {
+		t.Fatal("expected the untouched result to be clean")
+	}
+	if got := ChangedFields(&found[1]); !reflect.DeepEqual(got, []string{"age"}) {
+		t.Fatalf("expected [age] changed, got %v", got)
+	}
+}
+
+func TestTracking_PartialWrites(t *testing.T) {
+	ctx := useTestStore(t)
+
+	u := &testUser{Email: "partial-track@test.com", Name: "Track", Age: 30}
+	if err := Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	u.Role = "admin"
+	if err := UpdateFields(ctx, u, bson.M{"age": 31}); err != nil {
+		t.Fatalf("update fields: %v", err)
+	}
+	if got := ChangedFields(u); !reflect.DeepEqual(got, []string{"role"}) {
+		t.Errorf("expected only the unsaved role after UpdateFields, got %v", got)
+	}
+	if orig := Original(u).(*testUser); orig.Age != 31 || orig.Role != "user" || orig.Version != u.Version {
+		t.Errorf("expected the written age and version in the original, got %+v", orig)
+	}
+	if err := Touch(ctx, u); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	if orig := Original(u).(*testUser); orig.Version != u.Version || !orig.UpdatedAt.Equal(u.UpdatedAt.Truncate(time.Millisecond)) {
+		t.Errorf("expected the touched version and timestamp in the original, got %+v", orig)
+	}
+
+	if err := Register(&testComment{}, "comments"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testComment")
+		registryMu.Unlock()
+	})
+	c := &testComment{Body: "hi"}
+	if err := Create(ctx, c); err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	if err := Delete(ctx, c); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if orig := Original(c).(*testComment); !orig.IsDeleted() || IsDirty(c) {
+		t.Errorf("expected the deletion to be tracked, got %+v (changed %v)", orig, ChangedFields(c))
+	}
+	if err := Restore(ctx, c); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if orig := Original(c).(*testComment); orig.IsDeleted() || IsDirty(c) {
+		t.Errorf("expected the restore to be tracked, got %+v (changed %v)", orig, ChangedFields(c))
+	}
+}
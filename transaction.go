@@ -2,14 +2,22 @@ package goodm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	driversession "go.mongodb.org/mongo-driver/v2/x/mongo/driver/session"
 )
 
 // TransactionOptions configures the WithTransaction operation.
 type TransactionOptions struct {
 	DB *mongo.Database
+
+	// FailNested makes WithTransaction return ErrNestedTransaction when ctx
+	// is already inside a transaction, instead of joining it. Use it for
+	// units of work that must commit on their own.
+	FailNested bool
 }
 
 // WithTransaction executes fn within a MongoDB transaction. All goodm CRUD
@@ -20,6 +28,13 @@ type TransactionOptions struct {
 // transaction is committed. Transient transaction errors are retried
 // automatically by the driver.
 //
+// If ctx is already inside a transaction, as it is inside another
+// WithTransaction callback, fn joins that transaction instead of starting a
+// new one, and the enclosing call decides whether it commits; with
+// opts.FailNested, ErrNestedTransaction is returned instead. If ctx carries
+// a session without a transaction, such as a CausallyConsistent session, the
+// transaction runs in that session.
+//
 // Example:
 //
 //	err := goodm.WithTransaction(ctx, func(ctx context.Context) error {
@@ -32,6 +47,93 @@ type TransactionOptions struct {
 //	    return nil
 //	})
 func WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TransactionOptions) error {
+	var opt TransactionOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	db, err := getDB(opt.DB)
+	if err != nil {
+		return err
+	}
+
+	join := func() error {
+		if opt.FailNested {
+			return ErrNestedTransaction
+		}
+		return fn(ctx)
+	}
+	if InTransaction(ctx) {
+		return join()
+	}
+	if db.mem != nil {
+		// in-memory test store: no transaction support
+		return fn(context.WithValue(ctx, txKey{}, true))
+	}
+
+	session := mongo.SessionFromContext(ctx)
+	if session == nil {
+		client := db.Client()
+		if client == nil {
+			return ErrNoDatabase
+		}
+		session, err = client.StartSession()
+		if err != nil {
+			return fmt.Errorf("goodm: failed to start session: %w", err)
+		}
+		defer session.EndSession(ctx)
+	}
+
+	started := false
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		started = true
+		return nil, fn(context.WithValue(ctx, txKey{}, true))
+	})
+	if !started && errors.Is(err, driversession.ErrTransactInProgress) {
+		// The session runs a transaction started outside goodm.
+		return join()
+	}
+	if err != nil {
+		return fmt.Errorf("goodm: transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// InTransaction reports whether ctx is inside a transaction started by
+// WithTransaction. Transactions started on a session directly through the
+// driver are not detected.
+func InTransaction(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+// CausalOptions configures the CausallyConsistent operation.
+type CausalOptions struct {
+	DB *mongo.Database
+}
+
+// CausallyConsistent executes fn within a causally consistent session. All
+// goodm operations called within fn use the session, so reads observe the
+// writes made earlier in fn even when they are served by a secondary, as
+// with a ReadPreference of SecondaryPreferred in the model's
+// CollectionOptions. Unlike WithTransaction, writes are not atomic and take
+// effect as they are made.
+//
+// The guarantees hold across elections and failovers only when the model's
+// collections use "majority" read and write concerns.
+//
+// If ctx already carries a session, as it does inside a WithTransaction
+// callback, fn runs in that session.
+//
+// Example:
+//
+//	err := goodm.CausallyConsistent(ctx, func(ctx context.Context) error {
+//	    if err := goodm.Create(ctx, order); err != nil {
+//	        return err
+//	    }
+//	    // Sees the new order even if read from a lagging secondary.
+//	    return goodm.Find(ctx, bson.D{{Key: "customer", Value: order.Customer}}, &orders)
+//	})
+func CausallyConsistent(ctx context.Context, fn func(ctx context.Context) error, opts ...CausalOptions) error {
 	var optDB *mongo.Database
 	if len(opts) > 0 {
 		optDB = opts[0].DB
@@ -41,23 +143,23 @@ func WithTransaction(ctx context.Context, fn func(ctx context.Context) error, op
 		return err
 	}
 
+	if db.mem != nil {
+		return fn(ctx) // in-memory test store: reads always see writes
+	}
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
 	client := db.Client()
 	if client == nil {
 		return ErrNoDatabase
 	}
 
-	session, err := client.StartSession()
+	session, err := client.StartSession(options.Session().SetCausalConsistency(true))
 	if err != nil {
 		return fmt.Errorf("goodm: failed to start session: %w", err)
 	}
 	defer session.EndSession(ctx)
 
-	_, err = session.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
-		return nil, fn(ctx)
-	})
-	if err != nil {
-		return fmt.Errorf("goodm: transaction failed: %w", err)
-	}
-
-	return nil
+	return fn(mongo.NewSessionContext(ctx, session))
 }
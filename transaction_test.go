@@ -2,10 +2,12 @@ package goodm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 func TestWithTransaction_Integration(t *testing.T) {
@@ -84,3 +86,156 @@ func TestWithTransaction_NoDatabase(t *testing.T) {
 		t.Fatalf("expected ErrNoDatabase, got %v", err)
 	}
 }
+
+func TestWithTransaction_Nested(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	errAbort := errors.New("abort outer")
+	err := WithTransaction(ctx, func(ctx context.Context) error {
+		if err := WithTransaction(ctx, func(ctx context.Context) error {
+			return Create(ctx, &testUser{Email: "nested@test.com", Name: "Nested", Age: 25})
+		}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Skipf("Transactions not supported (likely standalone): %v", err)
+	}
+
+	var users []testUser
+	if err := Find(ctx, bson.D{{Key: "email", Value: "nested@test.com"}}, &users); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("expected nested write to be rolled back with the outer transaction, found %d", len(users))
+	}
+}
+
+func TestWithTransaction_NestedTestStore(t *testing.T) {
+	ctx := useTestStore(t)
+
+	if InTransaction(ctx) {
+		t.Fatal("InTransaction outside a transaction")
+	}
+	joined := false
+	err := WithTransaction(ctx, func(ctx context.Context) error {
+		if !InTransaction(ctx) {
+			t.Error("InTransaction false inside WithTransaction")
+		}
+		if err := WithTransaction(ctx, func(ctx context.Context) error {
+			joined = InTransaction(ctx)
+			return nil
+		}); err != nil {
+			return err
+		}
+		return WithTransaction(ctx, func(ctx context.Context) error {
+			t.Error("FailNested ran fn inside a transaction")
+			return nil
+		}, TransactionOptions{FailNested: true})
+	})
+	if !errors.Is(err, ErrNestedTransaction) {
+		t.Fatalf("expected ErrNestedTransaction, got %v", err)
+	}
+	if !joined {
+		t.Error("nested WithTransaction did not join the transaction")
+	}
+}
+
+func TestWithTransaction_InCausalSession(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	errAbort := errors.New("abort")
+	err := CausallyConsistent(ctx, func(ctx context.Context) error {
+		err := WithTransaction(ctx, func(ctx context.Context) error {
+			if !InTransaction(ctx) {
+				t.Error("InTransaction false inside WithTransaction")
+			}
+			if err := Create(ctx, &testUser{Email: "causaltx@test.com", Name: "CausalTx", Age: 25}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Skipf("Transactions not supported (likely standalone): %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CausallyConsistent: %v", err)
+	}
+
+	var users []testUser
+	if err := Find(ctx, bson.D{{Key: "email", Value: "causaltx@test.com"}}, &users); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatal("expected the write to be rolled back: the causal session must not be joined without a transaction")
+	}
+}
+
+func TestCausallyConsistent_TestStore(t *testing.T) {
+	useTestStore(t)
+
+	errStop := errors.New("stop")
+	err := CausallyConsistent(context.Background(), func(ctx context.Context) error {
+		if err := Create(ctx, &testUser{Email: "causal@test.com", Name: "Causal"}); err != nil {
+			return err
+		}
+		var u testUser
+		if err := FindOne(ctx, bson.D{{Key: "email", Value: "causal@test.com"}}, &u); err != nil {
+			return err
+		}
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected fn's error, got %v", err)
+	}
+}
+
+func TestCausallyConsistent_NoDatabase(t *testing.T) {
+	dbMu.Lock()
+	saved := globalDB
+	globalDB = nil
+	dbMu.Unlock()
+	defer func() {
+		dbMu.Lock()
+		globalDB = saved
+		dbMu.Unlock()
+	}()
+
+	err := CausallyConsistent(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	if err != ErrNoDatabase {
+		t.Fatalf("expected ErrNoDatabase, got %v", err)
+	}
+}
+
+func TestCausallyConsistent_Integration(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	err := CausallyConsistent(ctx, func(ctx context.Context) error {
+		session := mongo.SessionFromContext(ctx)
+		if session == nil {
+			t.Fatal("expected a session in the callback context")
+		}
+		if err := Create(ctx, &testUser{Email: "causal@test.com", Name: "Causal", Age: 30}); err != nil {
+			return err
+		}
+		var u testUser
+		if err := FindOne(ctx, bson.D{{Key: "email", Value: "causal@test.com"}}, &u); err != nil {
+			return err
+		}
+		if session.OperationTime() == nil {
+			t.Error("expected the session to track an operation time")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CausallyConsistent: %v", err)
+	}
+}
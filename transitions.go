@@ -0,0 +1,91 @@
+package goodm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrInvalidTransition is returned (wrapped in a *TransitionError) when
+// Update moves an enum field to a value its transitions tag does not allow.
+var ErrInvalidTransition = errors.New("goodm: invalid state transition")
+
+// TransitionError names the field and values of a rejected state transition.
+// It matches ErrInvalidTransition with errors.Is.
+type TransitionError struct {
+	Field string // bson field name
+	From  string // stored value
+	To    string // value being saved
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("goodm: invalid transition of %s from %q to %q", e.Field, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// checkTransitions validates the transitions declared on schema's field tags:
+// they require an enum, and both ends of each pair must be enum values.
+func checkTransitions(schema *Schema) error {
+	for _, f := range schema.Fields {
+		if f.Transitions == nil {
+			continue
+		}
+		if len(f.Enum) == 0 {
+			return fmt.Errorf("goodm: %s.%s: transitions require enum", schema.ModelName, f.Name)
+		}
+		for from, targets := range f.Transitions {
+			for _, v := range append([]string{from}, targets...) {
+				if !inEnum(v, f.Enum) {
+					return fmt.Errorf("goodm: %s.%s: transition value %q is not in enum %v", schema.ModelName, f.Name, v, f.Enum)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func inEnum(v string, enum []string) bool {
+	for _, e := range enum {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTransitions returns true if any field in the schema declares transitions.
+func hasTransitions(schema *Schema) bool {
+	for _, f := range schema.Fields {
+		if f.Transitions != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTransitions checks that every field with transitions either kept
+// its stored value, had no stored value yet, or moved to an allowed value.
+func validateTransitions(old, new interface{}, schema *Schema) error {
+	oldV := reflect.Indirect(reflect.ValueOf(old))
+	newV := reflect.Indirect(reflect.ValueOf(new))
+
+	for _, field := range schema.Fields {
+		if field.Transitions == nil {
+			continue
+		}
+		oldField := oldV.FieldByName(field.Name)
+		newField := newV.FieldByName(field.Name)
+		if !oldField.IsValid() || !newField.IsValid() || oldField.IsZero() {
+			continue
+		}
+		from, to := stringValue(oldField), stringValue(newField)
+		if from == to || inEnum(to, field.Transitions[from]) {
+			continue
+		}
+		return &TransitionError{Field: field.BSONName, From: from, To: to}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package goodm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testArticle struct {
+	Model  `bson:",inline"`
+	Title  string `bson:"title"`
+	Status string `bson:"status" goodm:"enum=draft|published|archived,default=draft,transitions=draft>published|archived,published>archived"`
+}
+
+func TestParseGoodmTag_Transitions(t *testing.T) {
+	fs := ParseGoodmTag("enum=a|b|c,transitions=a>b|c,b>c,required")
+	want := map[string][]string{"a": {"b", "c"}, "b": {"c"}}
+	if !reflect.DeepEqual(fs.Transitions, want) {
+		t.Fatalf("expected %v, got %v", want, fs.Transitions)
+	}
+	if !fs.Required || len(fs.Enum) != 3 {
+		t.Fatalf("expected the surrounding tags to parse, got %+v", fs)
+	}
+}
+
+func TestRegister_Transitions(t *testing.T) {
+	type noEnum struct {
+		Model  `bson:",inline"`
+		Status string `bson:"status" goodm:"transitions=a>b"`
+	}
+	type unknownValue struct {
+		Model  `bson:",inline"`
+		Status string `bson:"status" goodm:"enum=a|b,transitions=a>c"`
+	}
+	for _, m := range []interface{}{&noEnum{}, &unknownValue{}} {
+		if err := Register(m, "test_bad_transitions"); err == nil {
+			t.Fatalf("expected %T to be rejected", m)
+		}
+	}
+}
+
+func TestUpdate_Transitions(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testArticle{}, "test_articles"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testArticle")
+		registryMu.Unlock()
+	})
+
+	a := &testArticle{Title: "Hello"}
+	if err := Create(ctx, a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	a.Title = "Hello, world"
+	if err := Update(ctx, a); err != nil {
+		t.Fatalf("expected an unchanged status to be allowed: %v", err)
+	}
+	a.Status = "published"
+	if err := Update(ctx, a); err != nil {
+		t.Fatalf("expected draft>published to be allowed: %v", err)
+	}
+
+	a.Status = "draft"
+	err := Update(ctx, a)
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	var te *TransitionError
+	if !errors.As(err, &te) || te.Field != "status" || te.From != "published" || te.To != "draft" {
+		t.Fatalf("expected a published to draft TransitionError, got %+v", te)
+	}
+
+	a.Status = "archived"
+	if err := Update(ctx, a); err != nil {
+		t.Fatalf("expected published>archived to be allowed: %v", err)
+	}
+}
@@ -0,0 +1,463 @@
+package goodm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// treeRoot is the materialized path of a root node. A node's path lists the
+// hex IDs of its ancestors, root first: "/<root>/<parent>/".
+const treeRoot = "/"
+
+// checkTree validates the tree=parent and tree=path fields of a schema,
+// records them on the schema, and indexes them.
+func checkTree(schema *Schema) error {
+	for i := range schema.Fields {
+		f := &schema.Fields[i]
+		switch f.Tree {
+		case "":
+			continue
+		case "parent":
+			if schema.TreeParent != "" {
+				return fmt.Errorf("goodm: %s.%s: model already has a tree=parent field", schema.ModelName, f.Name)
+			}
+			if f.Type != "bson.ObjectID" && f.Type != "*bson.ObjectID" {
+				return fmt.Errorf("goodm: %s.%s: tree=parent requires a bson.ObjectID field", schema.ModelName, f.Name)
+			}
+			schema.TreeParent = f.BSONName
+		case "path":
+			if schema.TreePath != "" {
+				return fmt.Errorf("goodm: %s.%s: model already has a tree=path field", schema.ModelName, f.Name)
+			}
+			if f.Type != "string" {
+				return fmt.Errorf("goodm: %s.%s: tree=path requires a string field", schema.ModelName, f.Name)
+			}
+			schema.TreePath = f.BSONName
+		default:
+			return fmt.Errorf("goodm: %s.%s: unknown tree role %q (want parent or path)", schema.ModelName, f.Name, f.Tree)
+		}
+		f.Index = true
+	}
+	if schema.TreePath != "" && schema.TreeParent == "" {
+		return fmt.Errorf("goodm: %s: tree=path requires a tree=parent field", schema.ModelName)
+	}
+	return nil
+}
+
+// treeNode resolves the schema and ID of a tree node.
+func treeNode(node interface{}) (*Schema, bson.ObjectID, error) {
+	schema, err := getSchemaForModel(node)
+	if err != nil {
+		return nil, bson.ObjectID{}, err
+	}
+	if schema.TreeParent == "" {
+		return nil, bson.ObjectID{}, fmt.Errorf("goodm: model %q has no tree=parent field", schema.ModelName)
+	}
+	id, err := getModelID(node)
+	if err != nil {
+		return nil, bson.ObjectID{}, err
+	}
+	if id.IsZero() {
+		return nil, bson.ObjectID{}, fmt.Errorf("goodm: tree node has a zero ID")
+	}
+	return schema, id, nil
+}
+
+// treeField returns the struct field of model stored under bsonName.
+func treeField(model interface{}, schema *Schema, bsonName string) reflect.Value {
+	f := schema.GetField(bsonName)
+	if f == nil {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(model).Elem().FieldByName(f.Name)
+}
+
+// treeParentID returns the model's parent ID, zero for a root.
+func treeParentID(model interface{}, schema *Schema) bson.ObjectID {
+	f := treeField(model, schema, schema.TreeParent)
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return bson.ObjectID{}
+		}
+		f = f.Elem()
+	}
+	id, _ := f.Interface().(bson.ObjectID)
+	return id
+}
+
+// setTreeParentID sets the model's parent ID; zero clears it.
+func setTreeParentID(model interface{}, schema *Schema, parent bson.ObjectID) {
+	f := treeField(model, schema, schema.TreeParent)
+	if f.Kind() == reflect.Ptr {
+		if parent.IsZero() {
+			f.Set(reflect.Zero(f.Type()))
+		} else {
+			f.Set(reflect.ValueOf(&parent))
+		}
+		return
+	}
+	f.Set(reflect.ValueOf(parent))
+}
+
+// treePathIDs parses the ancestor IDs out of a materialized path.
+func treePathIDs(path string) ([]bson.ObjectID, error) {
+	var ids []bson.ObjectID
+	for _, part := range strings.Split(strings.Trim(path, treeRoot), treeRoot) {
+		if part == "" {
+			continue
+		}
+		id, err := bson.ObjectIDFromHex(part)
+		if err != nil {
+			return nil, fmt.Errorf("goodm: invalid tree path %q", path)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// childPath returns the materialized path of a child of the node at path.
+func childPath(path string, id bson.ObjectID) string {
+	return path + id.Hex() + treeRoot
+}
+
+// storedTreePath reads the materialized path of the document with id.
+func storedTreePath(ctx context.Context, coll collection, schema *Schema, id bson.ObjectID) (string, error) {
+	var doc bson.M
+	err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}},
+		withComment(ctx, options.FindOne()).SetProjection(bson.D{{Key: schema.TreePath, Value: 1}})).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return "", fmt.Errorf("goodm: tree parent %s not found: %w", id.Hex(), ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("goodm: failed to read tree path: %w", err)
+	}
+	path, _ := doc[schema.TreePath].(string)
+	if path == "" {
+		path = treeRoot
+	}
+	return path, nil
+}
+
+// setTreePath fills the materialized path of a model being created from its
+// parent's stored path. batch holds the paths of models created earlier in
+// the same CreateMany, which are not stored yet.
+func setTreePath(ctx context.Context, coll collection, model interface{}, schema *Schema, batch map[bson.ObjectID]string) (string, error) {
+	if schema.TreePath == "" {
+		return "", nil
+	}
+	path := treeRoot
+	if parent := treeParentID(model, schema); !parent.IsZero() {
+		parentPath, ok := batch[parent]
+		if !ok {
+			var err error
+			if parentPath, err = storedTreePath(ctx, coll, schema, parent); err != nil {
+				return "", err
+			}
+		}
+		path = childPath(parentPath, parent)
+	}
+	treeField(model, schema, schema.TreePath).SetString(path)
+	return path, nil
+}
+
+// Ancestors loads the ancestors of node into results, a pointer to a slice
+// of the node's model type, starting at the root and ending with the node's
+// parent. Models with a tree=path field read them by ID from the stored
+// path; otherwise goodm follows the parent refs with $graphLookup.
+//
+// Example:
+//
+//	var crumbs []Category
+//	err := goodm.Ancestors(ctx, &category, &crumbs)
+func Ancestors(ctx context.Context, node interface{}, results interface{}, opts ...FindOptions) error {
+	return treeQuery(ctx, node, results, true, opts)
+}
+
+// Descendants loads every node below node into results, a pointer to a slice
+// of the node's model type. With a tree=path field they are ordered by path,
+// so each node follows its parent; otherwise they are ordered by depth.
+//
+// Example:
+//
+//	var subtree []Category
+//	err := goodm.Descendants(ctx, &category, &subtree)
+func Descendants(ctx context.Context, node interface{}, results interface{}, opts ...FindOptions) error {
+	return treeQuery(ctx, node, results, false, opts)
+}
+
+func treeQuery(ctx context.Context, node interface{}, results interface{}, ancestors bool, opts []FindOptions) error {
+	rv := reflect.ValueOf(results)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("goodm: results must be a pointer to a slice, got %T", results)
+	}
+	schema, id, err := treeNode(node)
+	if err != nil {
+		return err
+	}
+
+	var opt FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpFind, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: node,
+		Filter: bson.D{{Key: "_id", Value: id}},
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		coll := getCollection(db, schema)
+
+		var cursor *mongo.Cursor
+		var order []bson.ObjectID
+		switch {
+		case schema.TreePath != "" && ancestors:
+			path, err := storedTreePath(ctx, coll, schema, id)
+			if err != nil {
+				return err
+			}
+			if order, err = treePathIDs(path); err != nil {
+				return err
+			}
+			cursor, err = coll.Find(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: order}}}}, withComment(ctx, options.Find()))
+			if err != nil {
+				return fmt.Errorf("goodm: ancestors failed: %w", err)
+			}
+		case schema.TreePath != "":
+			path, err := storedTreePath(ctx, coll, schema, id)
+			if err != nil {
+				return err
+			}
+			prefix := "^" + regexp.QuoteMeta(childPath(path, id))
+			cursor, err = coll.Find(ctx, bson.D{{Key: schema.TreePath, Value: bson.D{{Key: "$regex", Value: prefix}}}},
+				withComment(ctx, options.Find()).SetSort(bson.D{{Key: schema.TreePath, Value: 1}, {Key: "_id", Value: 1}}))
+			if err != nil {
+				return fmt.Errorf("goodm: descendants failed: %w", err)
+			}
+		default:
+			cursor, err = coll.Aggregate(ctx, treeGraphLookup(schema, id, ancestors), withComment(ctx, options.Aggregate()))
+			if err != nil {
+				return fmt.Errorf("goodm: tree lookup failed: %w", err)
+			}
+		}
+		defer func() { _ = cursor.Close(ctx) }()
+
+		if err := cursor.All(ctx, results); err != nil {
+			return fmt.Errorf("goodm: cursor decode failed: %w", err)
+		}
+		if order != nil {
+			sortByIDs(rv.Elem(), order)
+		}
+		return afterLoad(ctx, results)
+	})
+}
+
+// treeGraphLookup returns the $graphLookup pipeline walking the parent refs
+// up from (ancestors) or down from the node with id, ordered root-most first.
+func treeGraphLookup(schema *Schema, id bson.ObjectID, ancestors bool) []bson.D {
+	startWith, from, to, order := "$"+schema.TreeParent, schema.TreeParent, "_id", -1
+	if !ancestors {
+		startWith, from, to, order = "$_id", "_id", schema.TreeParent, 1
+	}
+	return []bson.D{
+		{{Key: "$match", Value: bson.D{{Key: "_id", Value: id}}}},
+		{{Key: "$graphLookup", Value: bson.D{
+			{Key: "from", Value: schema.Collection},
+			{Key: "startWith", Value: startWith},
+			{Key: "connectFromField", Value: from},
+			{Key: "connectToField", Value: to},
+			{Key: "as", Value: "nodes"},
+			{Key: "depthField", Value: "depth"},
+		}}},
+		{{Key: "$unwind", Value: "$nodes"}},
+		{{Key: "$sort", Value: bson.D{{Key: "nodes.depth", Value: order}, {Key: "nodes._id", Value: 1}}}},
+		{{Key: "$replaceRoot", Value: bson.D{{Key: "newRoot", Value: "$nodes"}}}},
+		{{Key: "$unset", Value: "depth"}},
+	}
+}
+
+// sortByIDs reorders a slice of models to follow ids.
+func sortByIDs(slice reflect.Value, ids []bson.ObjectID) {
+	pos := make(map[bson.ObjectID]int, len(ids))
+	for i, id := range ids {
+		pos[id] = i
+	}
+	sorted := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+	byPos := make([]reflect.Value, len(ids))
+	for i := 0; i < slice.Len(); i++ {
+		id, err := getModelID(elemModel(slice.Index(i)))
+		if p, ok := pos[id]; ok && err == nil {
+			byPos[p] = slice.Index(i)
+		}
+	}
+	for _, v := range byPos {
+		if v.IsValid() {
+			sorted = reflect.Append(sorted, v)
+		}
+	}
+	slice.Set(sorted)
+}
+
+// MoveSubtree makes newParent the parent of node, or makes node a root when
+// newParent is zero. With a tree=path field, the paths of node and all its
+// descendants are rewritten. Moving a node under itself or one of its
+// descendants returns an error. node's parent, path, UpdatedAt, and Version
+// are updated in place.
+//
+// The node and its descendants are written with two updates and no hooks;
+// wrap MoveSubtree in WithTransaction to make the move atomic.
+//
+// Example:
+//
+//	err := goodm.MoveSubtree(ctx, &category, newParent.ID)
+func MoveSubtree(ctx context.Context, node interface{}, newParent bson.ObjectID, opts ...UpdateOptions) error {
+	schema, id, err := treeNode(node)
+	if err != nil {
+		return err
+	}
+	if newParent == id {
+		return fmt.Errorf("goodm: cannot move a tree node under itself")
+	}
+
+	var opt UpdateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return runMiddleware(ctx, &OpInfo{
+		Operation: OpUpdateMany, Collection: schema.Collection,
+		ModelName: schema.ModelName, Model: node,
+		Filter: bson.D{{Key: "_id", Value: id}},
+	}, func(ctx context.Context) error {
+		db, err := getDB(opt.DB)
+		if err != nil {
+			return err
+		}
+		bindOpDB(ctx, db)
+		coll := getCollection(db, schema)
+
+		// Reject cycles: node must not be an ancestor of the new parent.
+		if !newParent.IsZero() {
+			var above []bson.ObjectID
+			if schema.TreePath != "" {
+				path, err := storedTreePath(ctx, coll, schema, newParent)
+				if err != nil {
+					return err
+				}
+				if above, err = treePathIDs(path); err != nil {
+					return err
+				}
+			} else {
+				if above, err = graphLookupIDs(ctx, coll, schema, newParent); err != nil {
+					return err
+				}
+			}
+			for _, a := range above {
+				if a == id {
+					return fmt.Errorf("goodm: cannot move a tree node under its own descendant")
+				}
+			}
+		}
+
+		now := clockNow()
+		set := bson.D{{Key: schema.base().updatedAt, Value: now}}
+		var unset bson.D
+		if newParent.IsZero() {
+			unset = bson.D{{Key: schema.TreeParent, Value: ""}}
+		} else {
+			set = append(set, bson.E{Key: schema.TreeParent, Value: newParent})
+		}
+
+		var oldPrefix, newPath string
+		if schema.TreePath != "" {
+			oldPath, err := storedTreePath(ctx, coll, schema, id)
+			if err != nil {
+				return err
+			}
+			newPath = treeRoot
+			if !newParent.IsZero() {
+				parentPath, err := storedTreePath(ctx, coll, schema, newParent)
+				if err != nil {
+					return err
+				}
+				newPath = childPath(parentPath, newParent)
+			}
+			oldPrefix = childPath(oldPath, id)
+			set = append(set, bson.E{Key: schema.TreePath, Value: newPath})
+		}
+
+		update := bson.D{{Key: "$set", Value: set}, {Key: "$inc", Value: bson.D{{Key: schema.base().version, Value: 1}}}}
+		if unset != nil {
+			update = append(update, bson.E{Key: "$unset", Value: unset})
+		}
+		result, err := coll.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update, withComment(ctx, options.UpdateOne()))
+		if err != nil {
+			return fmt.Errorf("goodm: move subtree failed: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			return ErrNotFound
+		}
+
+		if schema.TreePath != "" {
+			newPrefix := childPath(newPath, id)
+			_, err := coll.UpdateMany(ctx,
+				bson.D{{Key: schema.TreePath, Value: bson.D{{Key: "$regex", Value: "^" + regexp.QuoteMeta(oldPrefix)}}}},
+				bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: schema.TreePath, Value: bson.D{{Key: "$concat", Value: bson.A{
+					newPrefix,
+					bson.D{{Key: "$substrCP", Value: bson.A{"$" + schema.TreePath, len(oldPrefix), bson.D{{Key: "$strLenCP", Value: "$" + schema.TreePath}}}}},
+				}}}}}}}},
+				withComment(ctx, options.UpdateMany()))
+			if err != nil {
+				return fmt.Errorf("goodm: move subtree descendants failed: %w", err)
+			}
+			treeField(node, schema, schema.TreePath).SetString(newPath)
+		}
+
+		setTreeParentID(node, schema, newParent)
+		setUpdatedAt(node, now)
+		if v, err := getModelVersion(node); err == nil {
+			setModelVersion(node, v+1)
+		}
+		written := []string{schema.TreeParent, schema.base().updatedAt, schema.base().version}
+		if schema.TreePath != "" {
+			written = append(written, schema.TreePath)
+		}
+		trackWritten(node, written)
+		return nil
+	})
+}
+
+// graphLookupIDs returns the IDs of the ancestors of the node with id,
+// following parent refs.
+func graphLookupIDs(ctx context.Context, coll collection, schema *Schema, id bson.ObjectID) ([]bson.ObjectID, error) {
+	pipeline := treeGraphLookup(schema, id, true)
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.D{{Key: "_id", Value: 1}}}})
+	cursor, err := coll.Aggregate(ctx, pipeline, withComment(ctx, options.Aggregate()))
+	if err != nil {
+		return nil, fmt.Errorf("goodm: tree lookup failed: %w", err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var docs []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("goodm: tree lookup failed: %w", err)
+	}
+	ids := make([]bson.ObjectID, len(docs))
+	for i, d := range docs {
+		ids[i] = d.ID
+	}
+	return ids, nil
+}
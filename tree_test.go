@@ -0,0 +1,252 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testCategory struct {
+	Model  `bson:",inline"`
+	Name   string        `bson:"name"`
+	Parent bson.ObjectID `bson:"parent,omitempty" goodm:"tree=parent"`
+	Path   string        `bson:"path" goodm:"tree=path"`
+}
+
+type testFolder struct {
+	Model  `bson:",inline"`
+	Name   string         `bson:"name"`
+	Parent *bson.ObjectID `bson:"parent" goodm:"tree=parent"`
+}
+
+func registerTreeModels(t *testing.T) {
+	t.Helper()
+	if err := Register(&testCategory{}, "test_categories"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := Register(&testFolder{}, "test_folders"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testCategory")
+		delete(registry, "testFolder")
+		registryMu.Unlock()
+	})
+}
+
+func categoryNames(cats []testCategory) string {
+	names := make([]string, len(cats))
+	for i, c := range cats {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestRegister_Tree(t *testing.T) {
+	registerTreeModels(t)
+
+	schema, _ := Get("testCategory")
+	if schema.TreeParent != "parent" || schema.TreePath != "path" {
+		t.Fatalf("expected parent and path tree fields, got %q and %q", schema.TreeParent, schema.TreePath)
+	}
+	if !schema.GetField("parent").Index || !schema.GetField("path").Index {
+		t.Fatal("expected tree fields to be indexed")
+	}
+	folders, _ := Get("testFolder")
+	if folders.TreeParent != "parent" || folders.TreePath != "" {
+		t.Fatalf("expected a parent-only tree, got %q and %q", folders.TreeParent, folders.TreePath)
+	}
+
+	type badRole struct {
+		Model  `bson:",inline"`
+		Parent bson.ObjectID `bson:"parent" goodm:"tree=up"`
+	}
+	type badType struct {
+		Model  `bson:",inline"`
+		Parent string `bson:"parent" goodm:"tree=parent"`
+	}
+	type pathOnly struct {
+		Model `bson:",inline"`
+		Path  string `bson:"path" goodm:"tree=path"`
+	}
+	for _, m := range []interface{}{&badRole{}, &badType{}, &pathOnly{}} {
+		if err := Register(m, "test_bad_trees"); err == nil {
+			t.Fatalf("expected %T to be rejected", m)
+		}
+	}
+}
+
+func TestTree_MaterializedPath(t *testing.T) {
+	ctx := useTestStore(t)
+	registerTreeModels(t)
+
+	root := &testCategory{Name: "root"}
+	if err := Create(ctx, root); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if root.Path != "/" {
+		t.Fatalf("expected a root path, got %q", root.Path)
+	}
+	child := &testCategory{Name: "child", Parent: root.ID}
+	if err := Create(ctx, child); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if want := "/" + root.ID.Hex() + "/"; child.Path != want {
+		t.Fatalf("expected path %q, got %q", want, child.Path)
+	}
+
+	// CreateMany resolves parents created earlier in the batch.
+	leafID := bson.NewObjectID()
+	batch := []testCategory{
+		{Model: Model{ID: leafID}, Name: "leaf", Parent: child.ID},
+		{Name: "leaf2", Parent: leafID},
+		{Name: "sibling", Parent: root.ID},
+	}
+	if _, err := CreateMany(ctx, batch); err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	if want := child.Path + child.ID.Hex() + "/" + leafID.Hex() + "/"; batch[1].Path != want {
+		t.Fatalf("expected path %q, got %q", want, batch[1].Path)
+	}
+
+	var ancestors []testCategory
+	if err := Ancestors(ctx, &batch[1], &ancestors); err != nil {
+		t.Fatalf("ancestors: %v", err)
+	}
+	if got := categoryNames(ancestors); got != "root,child,leaf" {
+		t.Fatalf("expected root,child,leaf, got %s", got)
+	}
+
+	var descendants []testCategory
+	if err := Descendants(ctx, child, &descendants); err != nil {
+		t.Fatalf("descendants: %v", err)
+	}
+	if got := categoryNames(descendants); got != "leaf,leaf2" {
+		t.Fatalf("expected leaf,leaf2, got %s", got)
+	}
+
+	orphan := &testCategory{Name: "orphan", Parent: bson.NewObjectID()}
+	if err := Create(ctx, orphan); err == nil {
+		t.Fatal("expected a missing parent to be rejected")
+	}
+}
+
+func TestMoveSubtree_RejectsCycles(t *testing.T) {
+	ctx := useTestStore(t)
+	registerTreeModels(t)
+
+	root := &testCategory{Name: "root"}
+	if err := Create(ctx, root); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	child := &testCategory{Name: "child", Parent: root.ID}
+	if err := Create(ctx, child); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := MoveSubtree(ctx, root, root.ID); err == nil {
+		t.Fatal("expected moving a node under itself to fail")
+	}
+	if err := MoveSubtree(ctx, root, child.ID); err == nil {
+		t.Fatal("expected moving a node under its descendant to fail")
+	}
+	if err := Ancestors(ctx, &testUser{Model: Model{ID: bson.NewObjectID()}}, &[]testUser{}); err == nil {
+		t.Fatal("expected a model without a tree=parent field to be rejected")
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestMoveSubtree_Path(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+	registerTreeModels(t)
+
+	a := &testCategory{Name: "a"}
+	b := &testCategory{Name: "b"}
+	if _, err := CreateMany(ctx, []*testCategory{a, b}); err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	child := &testCategory{Name: "child", Parent: a.ID}
+	if err := Create(ctx, child); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	leaf := &testCategory{Name: "leaf", Parent: child.ID}
+	if err := Create(ctx, leaf); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if err := MoveSubtree(ctx, child, b.ID); err != nil {
+		t.Fatalf("move subtree: %v", err)
+	}
+	if child.Parent != b.ID || child.Path != "/"+b.ID.Hex()+"/" || child.Version != 1 {
+		t.Fatalf("expected the model to be updated in place, got %+v", child)
+	}
+	moved := &testCategory{}
+	if err := FindOne(ctx, bson.D{{Key: "_id", Value: leaf.ID}}, moved); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if want := child.Path + child.ID.Hex() + "/"; moved.Path != want {
+		t.Fatalf("expected descendant path %q, got %q", want, moved.Path)
+	}
+
+	var ancestors []testCategory
+	if err := Ancestors(ctx, moved, &ancestors); err != nil {
+		t.Fatalf("ancestors: %v", err)
+	}
+	if got := categoryNames(ancestors); got != "b,child" {
+		t.Fatalf("expected b,child, got %s", got)
+	}
+
+	if err := MoveSubtree(ctx, child, bson.ObjectID{}); err != nil {
+		t.Fatalf("move to root: %v", err)
+	}
+	if !child.Parent.IsZero() || child.Path != "/" {
+		t.Fatalf("expected child to become a root, got %+v", child)
+	}
+}
+
+func TestTree_GraphLookup(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+	registerTreeModels(t)
+
+	root := &testFolder{Name: "root"}
+	if err := Create(ctx, root); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	child := &testFolder{Name: "child", Parent: &root.ID}
+	if err := Create(ctx, child); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	leaf := &testFolder{Name: "leaf", Parent: &child.ID}
+	if err := Create(ctx, leaf); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var ancestors []testFolder
+	if err := Ancestors(ctx, leaf, &ancestors); err != nil {
+		t.Fatalf("ancestors: %v", err)
+	}
+	if len(ancestors) != 2 || ancestors[0].Name != "root" || ancestors[1].Name != "child" {
+		t.Fatalf("expected root then child, got %+v", ancestors)
+	}
+	var descendants []testFolder
+	if err := Descendants(ctx, root, &descendants); err != nil {
+		t.Fatalf("descendants: %v", err)
+	}
+	if len(descendants) != 2 || descendants[0].Name != "child" || descendants[1].Name != "leaf" {
+		t.Fatalf("expected child then leaf, got %+v", descendants)
+	}
+
+	if err := MoveSubtree(ctx, root, leaf.ID); err == nil {
+		t.Fatal("expected moving a node under its descendant to fail")
+	}
+	if err := MoveSubtree(ctx, leaf, root.ID); err != nil {
+		t.Fatalf("move subtree: %v", err)
+	}
+	if leaf.Parent == nil || *leaf.Parent != root.ID {
+		t.Fatalf("expected leaf to move under root, got %v", leaf.Parent)
+	}
+}
@@ -0,0 +1,219 @@
+package goodm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dwoolworth/goodm/internal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TypeScriptOptions configures GenerateTypeScript.
+type TypeScriptOptions struct {
+	// BSONNames names properties after the bson tags instead of the names
+	// encoding/json uses (the json tag, or else the Go field name).
+	BSONNames bool
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	decimal128Type = reflect.TypeOf(bson.Decimal128{})
+	bsonDocTypes   = map[reflect.Type]bool{
+		reflect.TypeOf(bson.D{}): true, reflect.TypeOf(bson.M{}): true, reflect.TypeOf(bson.Raw{}): true,
+	}
+)
+
+// GenerateTypeScript renders the given schemas as TypeScript interfaces, one
+// per model plus one per subdocument struct, so frontend code can share the
+// models' shape. ObjectIDs, times (ISO 8601), and Decimal128 values become
+// strings, enum fields become unions of their values, pointers may be null,
+// and omitempty and select=false fields are optional.
+//
+// Example:
+//
+//	os.WriteFile("types.d.ts", []byte(goodm.GenerateTypeScript(goodm.GetAll())), 0o644)
+func GenerateTypeScript(schemas map[string]*Schema, opts ...TypeScriptOptions) string {
+	var opt TypeScriptOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	g := &tsGenerator{opt: opt, models: map[reflect.Type]string{}, nested: map[reflect.Type]bool{}}
+	names := make([]string, 0, len(schemas))
+	for name, s := range schemas {
+		names = append(names, name)
+		g.models[s.modelType] = s.ModelName
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("// Code generated by goodm gen ts. DO NOT EDIT.\n")
+	for _, name := range names {
+		s := schemas[name]
+		g.writeInterface(&b, s.ModelName, s.modelType)
+	}
+
+	// Subdocument types are discovered while writing, so emit them until no
+	// new ones appear.
+	written := map[reflect.Type]bool{}
+	for {
+		var pending []reflect.Type
+		for t := range g.nested {
+			if !written[t] {
+				pending = append(pending, t)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		sort.Slice(pending, func(i, j int) bool { return pending[i].Name() < pending[j].Name() })
+		for _, t := range pending {
+			written[t] = true
+			g.writeInterface(&b, t.Name(), t)
+		}
+	}
+	return b.String()
+}
+
+type tsGenerator struct {
+	opt    TypeScriptOptions
+	models map[reflect.Type]string // registered model types and their names
+	nested map[reflect.Type]bool   // subdocument struct types referenced so far
+}
+
+func (g *tsGenerator) writeInterface(b *strings.Builder, name string, t reflect.Type) {
+	fmt.Fprintf(b, "\nexport interface %s {\n", name)
+	for _, f := range internal.StructFields(t) {
+		prop, optional, ok := wireName(f, g.opt.BSONNames)
+		if !ok {
+			continue
+		}
+		tag := ParseGoodmTag(f.Tag.Get("goodm"))
+		if tag.Hidden {
+			optional = true
+		}
+
+		typ := g.tsType(f.Type)
+		if len(tag.Enum) > 0 {
+			typ = tsEnum(f.Type, tag.Enum)
+		}
+		q := ""
+		if optional {
+			q = "?"
+		}
+		comment := ""
+		if base := derefType(f.Type); base == timeType {
+			comment = " // ISO 8601"
+		}
+		if tag.Deprecated {
+			note := ""
+			if tag.DeprecatedNote != "" {
+				note = " " + strings.ReplaceAll(tag.DeprecatedNote, "*/", "* /")
+			}
+			fmt.Fprintf(b, "  /** @deprecated%s */\n", note)
+		}
+		fmt.Fprintf(b, "  %s%s: %s;%s\n", tsPropName(prop), q, typ, comment)
+	}
+	b.WriteString("}\n")
+}
+
+// wireName returns the property name a field is serialized under and whether
+// it is omitted when empty, following encoding/json or, with bsonNames, the
+// bson tag. ok is false for fields that are not serialized.
+func wireName(f reflect.StructField, bsonNames bool) (name string, omitempty, ok bool) {
+	if bsonNames {
+		name, omitempty = ParseBSONTag(f.Tag.Get("bson"))
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		return name, omitempty, name != "-"
+	}
+	name, omitempty = ParseBSONTag(f.Tag.Get("json"))
+	if name == "-" {
+		return "", false, false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, omitempty, true
+}
+
+// derefType strips pointers and slices from t.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// tsType returns the TypeScript type for a Go type.
+func (g *tsGenerator) tsType(t reflect.Type) string {
+	switch {
+	case t == objectIDType, t == timeType, t == decimal128Type:
+		return "string"
+	case bsonDocTypes[t]:
+		return "Record<string, unknown>"
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.tsType(t.Elem()) + " | null"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // base64
+		}
+		elem := g.tsType(t.Elem())
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		return "Record<string, " + g.tsType(t.Elem()) + ">"
+	case reflect.Struct:
+		if name, ok := g.models[t]; ok {
+			return name
+		}
+		if t.Name() == "" {
+			return "Record<string, unknown>"
+		}
+		g.nested[t] = true
+		return t.Name()
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "unknown"
+}
+
+// tsEnum returns the union of an enum field's values, as an array type for
+// slice fields.
+func tsEnum(t reflect.Type, values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	union := strings.Join(quoted, " | ")
+	if t.Kind() == reflect.Slice {
+		return "(" + union + ")[]"
+	}
+	return union
+}
+
+// tsPropName quotes property names that are not valid identifiers.
+func tsPropName(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
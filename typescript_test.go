@@ -0,0 +1,78 @@
+package goodm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testTSAddress struct {
+	City string `bson:"city" json:"city"`
+	Zip  string `bson:"zip,omitempty" json:"zip,omitempty"`
+}
+
+type testTSOrder struct {
+	Model    `bson:",inline"`
+	Status   string            `bson:"status" json:"status" goodm:"enum=open|paid"`
+	Total    float64           `bson:"total" json:"total"`
+	Items    []string          `bson:"items" json:"items"`
+	ShipTo   *testTSAddress    `bson:"ship_to" json:"shipTo"`
+	Owner    bson.ObjectID     `bson:"owner" json:"owner" goodm:"ref=test_users"`
+	Secret   string            `bson:"secret" json:"-"`
+	Token    string            `bson:"token" json:"token" goodm:"select=false"`
+	PaidAt   time.Time         `bson:"paid_at" json:"paidAt"`
+	Meta     map[string]int    `bson:"meta" json:"meta"`
+	Customer *testUser         `bson:"-" json:"customer"`
+	Extra    map[string]string `bson:"x-extra" json:"x-extra"`
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	useTestStore(t)
+	if err := Register(&testTSOrder{}, "test_ts_orders"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testTSOrder")
+		registryMu.Unlock()
+	})
+
+	ts := GenerateTypeScript(GetAll())
+	for _, want := range []string{
+		"export interface testTSOrder {",
+		"  ID: string;",
+		"  CreatedAt: string; // ISO 8601",
+		`  status: "open" | "paid";`,
+		"  total: number;",
+		"  items: string[];",
+		"  shipTo: testTSAddress | null;",
+		"  owner: string;",
+		"  token?: string;",
+		"  paidAt: string; // ISO 8601",
+		"  meta: Record<string, number>;",
+		"  customer: testUser | null;",
+		`  "x-extra": Record<string, string>;`,
+		"export interface testTSAddress {",
+		"  zip?: string;",
+		"export interface testUser {",
+	} {
+		if !strings.Contains(ts, want+"\n") {
+			t.Fatalf("expected %q in output:\n%s", want, ts)
+		}
+	}
+	if strings.Contains(ts, "Secret") || strings.Contains(ts, "secret") {
+		t.Fatalf("expected json:\"-\" field to be skipped:\n%s", ts)
+	}
+
+	ts = GenerateTypeScript(GetAll(), TypeScriptOptions{BSONNames: true})
+	for _, want := range []string{"  _id?: string;", "  ship_to: testTSAddress | null;", "  secret: string;"} {
+		if !strings.Contains(ts, want+"\n") {
+			t.Fatalf("expected %q in bson-named output:\n%s", want, ts)
+		}
+	}
+	if strings.Contains(ts, "customer") {
+		t.Fatalf("expected bson:\"-\" field to be skipped with BSONNames:\n%s", ts)
+	}
+}
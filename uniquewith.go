@@ -0,0 +1,81 @@
+package goodm
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// checkUniqueWith validates the uniquewith=a|b scopes declared on schema's
+// field tags and adds a unique compound index on the scope fields followed by
+// the field, so Enforce creates it like any other compound index.
+func checkUniqueWith(schema *Schema) error {
+	for _, f := range schema.Fields {
+		if len(f.UniqueWith) == 0 {
+			continue
+		}
+		if f.Unique {
+			return fmt.Errorf("goodm: %s.%s: uniquewith cannot be combined with unique", schema.ModelName, f.Name)
+		}
+		fields := make([]string, 0, len(f.UniqueWith)+1)
+		for _, scope := range f.UniqueWith {
+			if !schema.HasField(scope) || scope == f.BSONName {
+				return fmt.Errorf("goodm: %s.%s: uniquewith field %q is not a field of the model", schema.ModelName, f.Name, scope)
+			}
+			fields = append(fields, scope)
+		}
+		schema.CompoundIndexes = append(schema.CompoundIndexes, CompoundIndex{
+			Fields:    append(fields, indexedName(f)),
+			Unique:    true,
+			Collation: f.Collation,
+		})
+	}
+	return nil
+}
+
+// uniqueWithErrors translates a duplicate key error on a uniquewith index into
+// a validation error naming the field and its scope. It returns nil for any
+// other error.
+func uniqueWithErrors(schema *Schema, err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	msg := err.Error()
+	var errs ValidationErrors
+	for _, f := range schema.Fields {
+		if len(f.UniqueWith) == 0 {
+			continue
+		}
+		name := compoundIndexName(CompoundIndex{Fields: append(append([]string{}, f.UniqueWith...), indexedName(f))})
+		if !mentionsIndex(msg, name) {
+			continue
+		}
+		errs = append(errs, ValidationError{
+			Field:   f.BSONName,
+			Message: fmt.Sprintf("value must be unique within %s", strings.Join(f.UniqueWith, ", ")),
+		})
+	}
+	if errs == nil {
+		return nil
+	}
+	return errs
+}
+
+// mentionsIndex reports whether a duplicate key error message names index.
+func mentionsIndex(msg, index string) bool {
+	for rest := msg; ; {
+		i := strings.Index(rest, "index: "+index)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len("index: "+index):]
+		if rest == "" || !isIndexNameChar(rest[0]) {
+			return true
+		}
+	}
+}
+
+func isIndexNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,93 @@
+package goodm
+
+import (
+	"errors"
+	"testing"
+)
+
+type testMember struct {
+	Model    `bson:",inline"`
+	TenantID string `bson:"tenant_id"`
+	Email    string `bson:"email" goodm:"uniquewith=tenant_id"`
+}
+
+func TestRegister_UniqueWith(t *testing.T) {
+	if err := Register(&testMember{}, "test_members"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testMember")
+		registryMu.Unlock()
+	})
+
+	schema, _ := Get("testMember")
+	if len(schema.CompoundIndexes) != 1 {
+		t.Fatalf("expected 1 compound index, got %d", len(schema.CompoundIndexes))
+	}
+	if ci := schema.CompoundIndexes[0]; !ci.Unique || compoundIndexName(ci) != "tenant_id_1_email_1" {
+		t.Fatalf("expected a unique tenant_id_1_email_1 index, got %+v", ci)
+	}
+
+	type unknownScope struct {
+		Model `bson:",inline"`
+		Email string `bson:"email" goodm:"uniquewith=org"`
+	}
+	if err := Register(&unknownScope{}, "test_bad_uniquewith"); err == nil {
+		t.Fatal("expected an unknown scope field to be rejected")
+	}
+}
+
+func TestUniqueWith_ValidationError(t *testing.T) {
+	ctx := useTestStore(t)
+	if err := Register(&testMember{}, "test_members"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testMember")
+		registryMu.Unlock()
+	})
+
+	if _, err := CreateMany(ctx, []testMember{
+		{TenantID: "a", Email: "x@test.com"},
+		{TenantID: "b", Email: "x@test.com"},
+	}); err != nil {
+		t.Fatalf("expected the same email in other tenants to be allowed: %v", err)
+	}
+
+	dup := &testMember{TenantID: "a", Email: "x@test.com"}
+	err := Create(ctx, dup)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 1 {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if verrs[0].Field != "email" || verrs[0].Message != "value must be unique within tenant_id" {
+		t.Fatalf("unexpected validation error: %+v", verrs[0])
+	}
+
+	other := &testMember{TenantID: "c", Email: "x@test.com"}
+	if err := Create(ctx, other); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	other.TenantID = "b"
+	if err := Update(ctx, other); !errors.As(err, &verrs) {
+		t.Fatalf("expected Update to return a validation error, got %v", err)
+	}
+	if err := UpdateFields(ctx, other, map[string]interface{}{"tenant_id": "a"}); !errors.As(err, &verrs) {
+		t.Fatalf("expected UpdateFields to return a validation error, got %v", err)
+	}
+}
+
+func TestMentionsIndex(t *testing.T) {
+	msg := "E11000 duplicate key error collection: db.members index: tenant_id_1_email_1 dup key: { tenant_id: \"a\" }"
+	if !mentionsIndex(msg, "tenant_id_1_email_1") {
+		t.Fatal("expected the index to be found")
+	}
+	if !mentionsIndex("write errors: [E11000 duplicate key error collection: members index: tenant_id_1_email_1]", "tenant_id_1_email_1") {
+		t.Fatal("expected the index to be found at the end of the message")
+	}
+	if mentionsIndex(msg, "email_1") || mentionsIndex(msg, "tenant_id_1") {
+		t.Fatal("expected other indexes not to match")
+	}
+}
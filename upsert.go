@@ -0,0 +1,103 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// errUpsertMatched reports that the insert half of an Upsert found a matching
+// document written since the lookup, so Upsert must replace it instead.
+var errUpsertMatched = errors.New("goodm: upsert filter matched an existing document")
+
+// Upsert saves model as the document matching filter: it creates the document
+// when none matches, and otherwise replaces the matching document, taking over
+// its ID, CreatedAt, and Version. The insert runs the full Create lifecycle
+// (defaults, BeforeCreate/AfterCreate, validation) and the replace the full
+// Update lifecycle (immutable fields, BeforeSave/AfterSave, validation,
+// versioning).
+//
+// The insert only happens if still no document matches filter, so concurrent
+// upserts with the same filter never create duplicates: the one that loses
+// the race replaces the winner's document instead. If the document changes or
+// disappears between the lookup and the replace, Upsert looks it up again,
+// and returns ErrUpsertConflict if it keeps doing so. The lookup and the
+// insert see the same documents: soft-deleted documents and documents of
+// other polymorphic kinds never match filter.
+//
+// Example:
+//
+//	err := goodm.Upsert(ctx, bson.D{{Key: "email", Value: "alice@example.com"}}, &User{
+//	    Email: "alice@example.com",
+//	    Name:  "Alice",
+//	})
+func Upsert(ctx context.Context, filter interface{}, model interface{}, opts ...UpdateOptions) error {
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return err
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+	filter, err = coerceFilter(schema, filter)
+	if err != nil {
+		return err
+	}
+	var opt UpdateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// prepared records that an insert lost its race after Create prepared
+	// the model, so a later insert does not run the hooks, sequences, and
+	// slugs again.
+	prepared := false
+	for attempt := 0; ; attempt++ {
+		if attempt > defaultConflictRetries {
+			return ErrUpsertConflict
+		}
+		existing := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+		err := FindOne(ctx, filter, existing, FindOptions{DB: opt.DB})
+		if errors.Is(err, ErrNotFound) {
+			if prepared {
+				setModelVersion(model, 0)
+			}
+			err = Create(ctx, model, CreateOptions{DB: opt.DB, upsertFilter: filter, prepared: prepared})
+			if err != errUpsertMatched {
+				return err
+			}
+			prepared = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		id, _ := getModelID(existing)
+		version, _ := getModelVersion(existing)
+		setModelID(model, id)
+		setModelVersion(model, version)
+		copyCreatedAt(model, existing)
+		err = Update(ctx, model, opt)
+		if err != ErrNotFound && err != ErrVersionConflict {
+			return err
+		}
+	}
+}
+
+// insertIfNone inserts doc unless a document matches filter, in one atomic
+// upsert. It returns errUpsertMatched if one does.
+func insertIfNone(ctx context.Context, coll collection, filter, doc interface{}) error {
+	res, err := coll.UpdateOne(ctx, filter, bson.D{{Key: "$setOnInsert", Value: doc}},
+		withComment(ctx, options.UpdateOne()).SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return errUpsertMatched
+	}
+	return nil
+}
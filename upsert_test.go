@@ -0,0 +1,207 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestUpsert(t *testing.T) {
+	ctx := useTestStore(t)
+	filter := bson.D{{Key: "email", Value: "up@test.com"}}
+
+	u := &testHookUser{Email: "up@test.com", Name: "First"}
+	if err := Upsert(ctx, filter, u); err != nil {
+		t.Fatalf("Upsert insert: %v", err)
+	}
+	if u.ID.IsZero() || u.Version != 0 {
+		t.Errorf("expected a new document at version 0, got %+v", u.Model)
+	}
+	if want := []string{"before_create", "after_create"}; !reflect.DeepEqual(u.Events, want) {
+		t.Errorf("expected create hooks %v, got %v", want, u.Events)
+	}
+
+	again := &testHookUser{Email: "up@test.com", Name: "Second"}
+	if err := Upsert(ctx, filter, again); err != nil {
+		t.Fatalf("Upsert replace: %v", err)
+	}
+	if again.ID != u.ID || again.Version != 1 || !again.CreatedAt.Equal(u.CreatedAt.Truncate(time.Millisecond)) {
+		t.Errorf("expected the existing document to be replaced, got %+v (first %+v)", again.Model, u.Model)
+	}
+	if want := []string{"before_save", "after_save"}; !reflect.DeepEqual(again.Events, want) {
+		t.Errorf("expected save hooks %v, got %v", want, again.Events)
+	}
+
+	var found []testHookUser
+	if err := Find(ctx, filter, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(found) != 1 || found[0].Name != "Second" {
+		t.Errorf("expected one replaced document, got %+v", found)
+	}
+
+	if err := Upsert(ctx, bson.D{{Key: "email", Value: "bad@test.com"}}, &testHookUser{Email: "bad@test.com"}); err == nil {
+		t.Error("expected a validation error for a missing required field")
+	}
+	if n, _ := activeTestStore().Collection("test_hook_users").CountDocuments(ctx, bson.D{}); n != 1 {
+		t.Errorf("expected the invalid model not to be stored, got %d documents", n)
+	}
+}
+
+func TestUpsert_LostInsertRace(t *testing.T) {
+	ctx := useTestStore(t)
+	filter := bson.D{{Key: "email", Value: "race@test.com"}}
+
+	// Another writer creates the document between Upsert's lookup and its insert.
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	var rival *testHookUser
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		if op.Operation == OpCreate && rival == nil {
+			rival = &testHookUser{Email: "race@test.com", Name: "Rival"}
+			if err := Create(ctx, rival); err != nil {
+				return err
+			}
+		}
+		return next(ctx)
+	})
+
+	u := &testHookUser{Email: "race@test.com", Name: "Mine"}
+	if err := Upsert(ctx, filter, u); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if u.ID != rival.ID || u.Version != 1 {
+		t.Errorf("expected the rival document to be replaced, got %+v (rival %+v)", u.Model, rival.Model)
+	}
+	var found []testHookUser
+	if err := Find(ctx, filter, &found); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(found) != 1 || found[0].Name != "Mine" {
+		t.Errorf("expected one document holding the upserted model, got %+v", found)
+	}
+}
+
+func TestUpsert_Concurrent(t *testing.T) {
+	ctx := useTestStore(t)
+	filter := bson.D{{Key: "email", Value: "many@test.com"}}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Upsert(ctx, filter, &testHookUser{Email: "many@test.com", Name: "Many"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Upsert: %v", err)
+		}
+	}
+	if n, _ := activeTestStore().Collection("test_hook_users").CountDocuments(ctx, filter); n != 1 {
+		t.Errorf("expected concurrent upserts to leave one document, got %d", n)
+	}
+}
+
+func TestUpsert_IgnoresOutOfScopeMatches(t *testing.T) {
+	ctx := useTestStore(t)
+	defer registerPaymentModels(t)()
+	if err := Register(&testComment{}, "comments"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "testComment")
+		registryMu.Unlock()
+	})
+
+	// A soft-deleted document matching the filter does not block the insert.
+	old := &testComment{Post: "p1", Body: "pinned"}
+	if err := Create(ctx, old); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := Delete(ctx, old); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	c := &testComment{Post: "p1", Body: "pinned"}
+	if err := Upsert(ctx, bson.D{{Key: "body", Value: "pinned"}}, c); err != nil {
+		t.Fatalf("upsert over a deleted comment: %v", err)
+	}
+	if c.ID == old.ID || c.IsDeleted() {
+		t.Errorf("expected a new live comment, got %+v", c.SoftDeleteModel)
+	}
+
+	// Nor does a document of another kind in a polymorphic collection.
+	if err := Create(ctx, &testBankPayment{Amount: 20}); err != nil {
+		t.Fatalf("create bank: %v", err)
+	}
+	card := &testCardPayment{Amount: 20, Last4: "4242"}
+	if err := Upsert(ctx, bson.D{{Key: "amount", Value: 20}}, card); err != nil {
+		t.Fatalf("upsert beside a bank payment: %v", err)
+	}
+	if n, _ := activeTestStore().Collection("test_payments").CountDocuments(ctx, bson.D{}); n != 2 {
+		t.Errorf("expected a card and a bank payment, got %d documents", n)
+	}
+}
+
+func TestUpsert_RetriesDoNotRepeatCreate(t *testing.T) {
+	ctx := useTestStore(t)
+	filter := bson.D{{Key: "email", Value: "flaky@test.com"}}
+	users := activeTestStore().Collection("test_hook_users")
+
+	// A rival creates the document before the insert and removes it before
+	// the replace, so the insert has to be retried once.
+	ClearMiddleware()
+	t.Cleanup(ClearMiddleware)
+	rivals := 1
+	Use(func(ctx context.Context, op *OpInfo, next func(context.Context) error) error {
+		if rivals > 0 && op.Operation == OpCreate && op.Model.(*testHookUser).Name == "Mine" {
+			if _, err := users.InsertOne(ctx, bson.D{{Key: "_id", Value: bson.NewObjectID()}, {Key: "email", Value: "flaky@test.com"}, {Key: "name", Value: "Rival"}}); err != nil {
+				return err
+			}
+		}
+		if rivals > 0 && op.Operation == OpUpdate {
+			rivals--
+			if _, err := users.DeleteMany(ctx, filter); err != nil {
+				return err
+			}
+		}
+		return next(ctx)
+	})
+
+	u := &testHookUser{Email: "flaky@test.com", Name: "Mine"}
+	if err := Upsert(ctx, filter, u); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	creates := 0
+	for _, e := range u.Events {
+		if e == "before_create" {
+			creates++
+		}
+	}
+	if creates != 1 || u.Version != 0 {
+		t.Errorf("expected the model to be prepared once and inserted at version 0, got %v at version %d", u.Events, u.Version)
+	}
+	var found []testHookUser
+	if err := Find(ctx, filter, &found); err != nil || len(found) != 1 || found[0].ID != u.ID {
+		t.Errorf("expected the model to be stored once, got %+v (%v)", found, err)
+	}
+
+	rivals = 100
+	v := &testHookUser{Email: "flaky@test.com", Name: "Mine"}
+	if _, err := users.DeleteMany(ctx, filter); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := Upsert(ctx, filter, v); !errors.Is(err, ErrUpsertConflict) {
+		t.Errorf("expected ErrUpsertConflict once the retries ran out, got %v", err)
+	}
+}
@@ -1,8 +1,13 @@
 package goodm
 
 import (
+	"context"
 	"fmt"
+	"math/big"
 	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 // Validate checks a model instance against its schema.
@@ -16,6 +21,131 @@ func Validate(model interface{}, schema *Schema) []ValidationError {
 	return validateFields(v, schema.Fields, "")
 }
 
+// ValidateMany validates every model in models, a slice of registered models
+// or pointers to them, without writing anything. It returns the errors of the
+// invalid models keyed by slice index; a nil map means all are valid.
+//
+// Example:
+//
+//	invalid, err := goodm.ValidateMany(users)
+//	for i, errs := range invalid {
+//	    log.Printf("row %d: %v", i, errs)
+//	}
+func ValidateMany(models interface{}) (map[int]ValidationErrors, error) {
+	rv := reflect.ValueOf(models)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("goodm: ValidateMany expects a slice, got %T", models)
+	}
+	var invalid map[int]ValidationErrors
+	for i := 0; i < rv.Len(); i++ {
+		model := rv.Index(i)
+		if model.Kind() != reflect.Ptr {
+			model = model.Addr()
+		}
+		schema, err := getSchemaForModel(model.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if errs := Validate(model.Interface(), schema); len(errs) > 0 {
+			if invalid == nil {
+				invalid = make(map[int]ValidationErrors)
+			}
+			invalid[i] = errs
+		}
+	}
+	return invalid, nil
+}
+
+// defaultMaxInvalid is how many invalid documents ValidateCollection keeps.
+const defaultMaxInvalid = 100
+
+// ValidateCollectionOptions configures ValidateCollection.
+type ValidateCollectionOptions struct {
+	DB         *mongo.Database
+	Filter     interface{}                 // restricts the documents checked (default: all)
+	MaxInvalid int                         // invalid documents kept in the report (default 100)
+	OnInvalid  func(InvalidDocument) error // called for every invalid document; an error stops the scan
+}
+
+// InvalidDocument is a stored document that fails validation.
+type InvalidDocument struct {
+	ID     bson.ObjectID
+	Errors ValidationErrors
+}
+
+// CollectionValidation reports the outcome of ValidateCollection.
+type CollectionValidation struct {
+	Checked      int               // documents checked
+	InvalidCount int               // documents that failed validation
+	Invalid      []InvalidDocument // the first MaxInvalid of them
+}
+
+// ValidateCollection streams the stored documents of model's collection and
+// validates each against the schema, for checking existing data after a
+// schema change or before a migration. Documents are decoded one at a time,
+// so collections of any size can be checked. A document that cannot be
+// decoded into the model is reported as invalid with a single error for
+// the whole document.
+//
+// Example:
+//
+//	report, err := goodm.ValidateCollection(ctx, &User{})
+//	log.Printf("%d of %d users are invalid", report.InvalidCount, report.Checked)
+func ValidateCollection(ctx context.Context, model interface{}, opts ...ValidateCollectionOptions) (*CollectionValidation, error) {
+	var opt ValidateCollectionOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	schema, err := getSchemaForModel(model)
+	if err != nil {
+		return nil, err
+	}
+	if opt.MaxInvalid <= 0 {
+		opt.MaxInvalid = defaultMaxInvalid
+	}
+	filter := opt.Filter
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	cursor, err := FindCursor(ctx, filter, model, FindOptions{DB: opt.DB, IncludeHidden: true})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	report := &CollectionValidation{}
+	for cursor.Next(ctx) {
+		report.Checked++
+		doc := reflect.New(schema.modelType).Interface()
+		var errs ValidationErrors
+		if err := cursor.Decode(doc); err != nil {
+			errs = ValidationErrors{{Field: "(document)", Message: fmt.Sprintf("cannot decode: %v", err)}}
+		} else {
+			errs = Validate(doc, schema)
+		}
+		if len(errs) == 0 {
+			continue
+		}
+
+		id, _ := cursor.Current.Lookup("_id").ObjectIDOK()
+		invalid := InvalidDocument{ID: id, Errors: errs}
+		report.InvalidCount++
+		if len(report.Invalid) < opt.MaxInvalid {
+			report.Invalid = append(report.Invalid, invalid)
+		}
+		if opt.OnInvalid != nil {
+			if err := opt.OnInvalid(invalid); err != nil {
+				return report, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return report, fmt.Errorf("goodm: validate collection failed: %w", err)
+	}
+	return report, nil
+}
+
 // validateFields recursively validates struct fields, producing dotted error paths
 // for nested subdocuments (e.g. "address.street", "items[0].name").
 func validateFields(v reflect.Value, fields []FieldSchema, pathPrefix string) []ValidationError {
@@ -61,6 +191,20 @@ func validateFields(v reflect.Value, fields []FieldSchema, pathPrefix string) []
 			}
 		}
 
+		// Precision / Scale: digits of decimal values
+		if fs.Precision != nil || fs.Scale != nil {
+			if err := validateDecimal(fv, fs, fieldPath); err != nil {
+				errs = append(errs, *err)
+			}
+		}
+
+		// DateOnly: time values must be dates at midnight UTC
+		if fs.DateOnly && !fv.IsZero() {
+			if err := validateDateOnly(fv, fieldPath); err != nil {
+				errs = append(errs, *err)
+			}
+		}
+
 		// Recurse into subdocuments
 		errs = append(errs, validateSubFields(fv, fs, fieldPath)...)
 	}
@@ -82,9 +226,17 @@ func validateEnum(fv reflect.Value, enum []string, fieldPath string) *Validation
 	}
 }
 
-// validateMin checks that fv meets the minimum length (strings) or value (numerics).
+// validateMin checks that fv meets the minimum length (strings) or value
+// (numerics and decimals).
 func validateMin(fv reflect.Value, min int, fieldPath string) *ValidationError {
-	if fv.Kind() == reflect.String {
+	if r, ok := toRat(fv); ok {
+		if r.Cmp(big.NewRat(int64(min), 1)) < 0 {
+			return &ValidationError{
+				Field:   fieldPath,
+				Message: fmt.Sprintf("value %s is less than minimum %d", formatDecimal(r), min),
+			}
+		}
+	} else if fv.Kind() == reflect.String {
 		if fv.Len() < min {
 			return &ValidationError{
 				Field:   fieldPath,
@@ -102,9 +254,17 @@ func validateMin(fv reflect.Value, min int, fieldPath string) *ValidationError {
 	return nil
 }
 
-// validateMax checks that fv does not exceed the maximum length (strings) or value (numerics).
+// validateMax checks that fv does not exceed the maximum length (strings) or
+// value (numerics and decimals).
 func validateMax(fv reflect.Value, max int, fieldPath string) *ValidationError {
-	if fv.Kind() == reflect.String {
+	if r, ok := toRat(fv); ok {
+		if r.Cmp(big.NewRat(int64(max), 1)) > 0 {
+			return &ValidationError{
+				Field:   fieldPath,
+				Message: fmt.Sprintf("value %s exceeds maximum %d", formatDecimal(r), max),
+			}
+		}
+	} else if fv.Kind() == reflect.String {
 		if fv.Len() > max {
 			return &ValidationError{
 				Field:   fieldPath,
@@ -2,6 +2,8 @@ package goodm
 
 import (
 	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 func TestValidate_StringMinLength(t *testing.T) {
@@ -380,3 +382,69 @@ func TestValidate_DeeplyNested(t *testing.T) {
 func intPtr(n int) *int {
 	return &n
 }
+
+func TestValidateMany(t *testing.T) {
+	useTestStore(t)
+	users := []testUser{
+		{Email: "a@test.com", Name: "A"},
+		{Name: "No Email"},
+		{Email: "c@test.com", Name: "C", Role: "owner"},
+	}
+	invalid, err := ValidateMany(users)
+	if err != nil {
+		t.Fatalf("ValidateMany: %v", err)
+	}
+	if len(invalid) != 2 || invalid[1][0].Field != "email" || invalid[2][0].Field != "role" {
+		t.Errorf("unexpected result: %v", invalid)
+	}
+
+	invalid, err = ValidateMany([]*testUser{{Email: "a@test.com", Name: "A"}})
+	if err != nil || invalid != nil {
+		t.Errorf("expected no invalid models, got %v, %v", invalid, err)
+	}
+	if _, err := ValidateMany(users[0]); err == nil {
+		t.Error("expected an error for a non-slice")
+	}
+}
+
+func TestValidateCollection(t *testing.T) {
+	ctx := useTestStore(t)
+	coll := activeTestStore().Collection("test_users")
+	valid := bson.NewObjectID()
+	docs := []bson.D{
+		{{Key: "_id", Value: valid}, {Key: "email", Value: "a@test.com"}, {Key: "name", Value: "A"}},
+		{{Key: "_id", Value: bson.NewObjectID()}, {Key: "name", Value: "No Email"}},
+		{{Key: "_id", Value: bson.NewObjectID()}, {Key: "email", Value: "c@test.com"}, {Key: "name", Value: "C"}, {Key: "age", Value: "old"}},
+		{{Key: "_id", Value: bson.NewObjectID()}, {Key: "email", Value: "d@test.com"}, {Key: "name", Value: "D"}, {Key: "role", Value: "owner"}},
+	}
+	for _, doc := range docs {
+		if _, err := coll.InsertOne(ctx, doc); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+
+	var seen []bson.ObjectID
+	report, err := ValidateCollection(ctx, &testUser{}, ValidateCollectionOptions{
+		MaxInvalid: 2,
+		OnInvalid: func(d InvalidDocument) error {
+			seen = append(seen, d.ID)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ValidateCollection: %v", err)
+	}
+	if report.Checked != 4 || report.InvalidCount != 3 || len(report.Invalid) != 2 || len(seen) != 3 {
+		t.Fatalf("unexpected report: %+v (seen %d)", report, len(seen))
+	}
+	if report.Invalid[1].Errors[0].Field != "(document)" {
+		t.Errorf("decode failure not reported: %v", report.Invalid[1].Errors)
+	}
+
+	report, err = ValidateCollection(ctx, &testUser{}, ValidateCollectionOptions{
+		Filter: bson.D{{Key: "_id", Value: valid}},
+	})
+	if err != nil || report.Checked != 1 || report.InvalidCount != 0 {
+		t.Errorf("unexpected filtered report: %+v, %v", report, err)
+	}
+}
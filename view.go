@@ -0,0 +1,260 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dwoolworth/goodm/internal/memstore"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DefaultViewCheckpoints is the collection where StartView stores the resume
+// token of each view.
+const DefaultViewCheckpoints = "goodm_view_checkpoints"
+
+// View declares a collection derived from a source model and kept up to date
+// from the source collection's change stream. Each view document has the _id
+// of the source document it was mapped from.
+type View struct {
+	Collection string      // view collection; also identifies the view's checkpoint
+	Source     interface{} // registered source model, e.g. &Order{}
+
+	// Map returns the view document for a created, updated, or replaced
+	// source document, or nil to remove it from the view. Its _id, if set,
+	// must be the source document's. Deleting a source document deletes its
+	// view document without calling Map.
+	Map func(ctx context.Context, ev ChangeEvent) (interface{}, error)
+
+	// Backfill is an aggregation pipeline over the source collection that
+	// produces view documents. It fills the view the first time it starts,
+	// with a $merge into Collection appended. If nil, Map is called for
+	// every existing source document instead.
+	Backfill []bson.D
+
+	Checkpoints string          // checkpoint collection (default DefaultViewCheckpoints)
+	DB          *mongo.Database // database of the source and view (default: the global database)
+}
+
+// ChangeEvent is a change to a source document, passed to View.Map.
+type ChangeEvent struct {
+	Operation string        // "insert", "update", or "replace"; "backfill" during the initial fill
+	ID        bson.RawValue // the source document's _id
+	Document  bson.Raw      // the source document after the change
+}
+
+// Decode unmarshals the source document into v, typically a pointer to the
+// source model.
+func (ev ChangeEvent) Decode(v interface{}) error {
+	return unmarshalBSON(ev.Document, v)
+}
+
+// viewCheckpoint is the stored resume position of a view.
+type viewCheckpoint struct {
+	View        string    `bson:"_id"`
+	ResumeToken bson.Raw  `bson:"resume_token"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+}
+
+// changeEventDoc is the part of a change stream event a view reads.
+type changeEventDoc struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID bson.RawValue `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument bson.Raw `bson:"fullDocument"`
+}
+
+// StartView starts a worker maintaining a materialized view. On its first
+// start the view is backfilled from the existing source documents; after
+// that, and on every later start, the worker tails the source collection's
+// change stream, resuming after the last event it applied. The resume token
+// is checkpointed after every event, so a restarted worker continues where
+// it stopped, replaying at most the event in flight. Applying an event
+// replaces or deletes one view document by _id, which makes replays harmless.
+//
+// Change streams require a replica set or sharded cluster, and the source
+// collection's oplog must still hold the checkpoint when the worker restarts.
+// The worker stops at the first error, such as a failing Map; check Err or
+// Stop.
+//
+// Example:
+//
+//	w, err := goodm.StartView(ctx, goodm.View{
+//	    Collection: "order_summaries",
+//	    Source:     &Order{},
+//	    Map: func(ctx context.Context, ev goodm.ChangeEvent) (interface{}, error) {
+//	        var o Order
+//	        if err := ev.Decode(&o); err != nil {
+//	            return nil, err
+//	        }
+//	        return bson.M{"customer": o.Customer, "total": o.Total, "status": o.Status}, nil
+//	    },
+//	})
+//	defer w.Stop()
+func StartView(ctx context.Context, v View) (*Worker, error) {
+	if v.Collection == "" {
+		return nil, fmt.Errorf("goodm: view requires a Collection")
+	}
+	if v.Map == nil {
+		return nil, fmt.Errorf("goodm: view %s requires a Map function", v.Collection)
+	}
+	schema, err := getSchemaForModel(v.Source)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Collection == v.Collection {
+		return nil, fmt.Errorf("goodm: view %s cannot be its own source", v.Collection)
+	}
+	if v.Checkpoints == "" {
+		v.Checkpoints = DefaultViewCheckpoints
+	}
+	db, err := getDB(v.DB)
+	if err != nil {
+		return nil, err
+	}
+	if db.mem != nil {
+		return nil, fmt.Errorf("goodm: view %s: %w", v.Collection, memstore.ErrUnsupported)
+	}
+
+	return startWorker(ctx, func(ctx context.Context) error {
+		return runView(ctx, db.Database, schema, v)
+	}), nil
+}
+
+// runView backfills the view if it has no checkpoint, then applies change
+// events until ctx is done.
+func runView(ctx context.Context, db *mongo.Database, schema *Schema, v View) error {
+	source := db.Collection(schema.Collection)
+	checkpoints := db.Collection(v.Checkpoints)
+
+	var cp viewCheckpoint
+	err := checkpoints.FindOne(ctx, bson.D{{Key: "_id", Value: v.Collection}}).Decode(&cp)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("goodm: view %s: failed to load checkpoint: %w", v.Collection, err)
+	}
+
+	// Open the stream before backfilling, so changes made during the
+	// backfill are applied afterwards.
+	csOpts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if cp.ResumeToken != nil {
+		csOpts.SetResumeAfter(cp.ResumeToken)
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.D{
+		{Key: "operationType", Value: bson.D{{Key: "$in", Value: bson.A{"insert", "update", "replace", "delete"}}}},
+	}}}}
+	stream, err := source.Watch(ctx, pipeline, csOpts)
+	if err != nil {
+		return fmt.Errorf("goodm: view %s: failed to watch %s: %w", v.Collection, schema.Collection, err)
+	}
+	defer stream.Close(context.Background())
+
+	if cp.ResumeToken == nil {
+		if err := backfillView(ctx, db, source, v); err != nil {
+			return err
+		}
+		if err := saveViewCheckpoint(ctx, checkpoints, v.Collection, stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+
+	view := db.Collection(v.Collection)
+	for stream.Next(ctx) {
+		var ev changeEventDoc
+		if err := stream.Decode(&ev); err != nil {
+			return fmt.Errorf("goodm: view %s: failed to decode change event: %w", v.Collection, err)
+		}
+		if err := applyViewEvent(ctx, view, v, ev); err != nil {
+			return err
+		}
+		if err := saveViewCheckpoint(ctx, checkpoints, v.Collection, stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+	if err := stream.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("goodm: view %s: change stream failed: %w", v.Collection, err)
+	}
+	return ctx.Err()
+}
+
+// backfillView fills the view from the existing source documents, with the
+// Backfill pipeline or by mapping each document.
+func backfillView(ctx context.Context, db *mongo.Database, source *mongo.Collection, v View) error {
+	if v.Backfill != nil {
+		pipeline := append(append([]bson.D{}, v.Backfill...), bson.D{{Key: "$merge", Value: bson.D{
+			{Key: "into", Value: v.Collection},
+			{Key: "whenMatched", Value: "replace"},
+		}}})
+		cur, err := source.Aggregate(ctx, pipeline)
+		if err != nil {
+			return fmt.Errorf("goodm: view %s: backfill failed: %w", v.Collection, err)
+		}
+		return cur.Close(ctx)
+	}
+
+	cur, err := source.Find(ctx, bson.D{})
+	if err != nil {
+		return fmt.Errorf("goodm: view %s: backfill failed: %w", v.Collection, err)
+	}
+	defer cur.Close(ctx)
+	view := db.Collection(v.Collection)
+	for cur.Next(ctx) {
+		ev := changeEventDoc{OperationType: "backfill", FullDocument: append(bson.Raw(nil), cur.Current...)}
+		ev.DocumentKey.ID = ev.FullDocument.Lookup("_id")
+		if err := applyViewEvent(ctx, view, v, ev); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("goodm: view %s: backfill failed: %w", v.Collection, err)
+	}
+	return nil
+}
+
+// applyViewEvent replaces or deletes the view document of one source
+// document.
+func applyViewEvent(ctx context.Context, view *mongo.Collection, v View, ev changeEventDoc) error {
+	id := ev.DocumentKey.ID
+	filter := bson.D{{Key: "_id", Value: id}}
+	if ev.OperationType == "delete" {
+		if _, err := view.DeleteOne(ctx, filter); err != nil {
+			return fmt.Errorf("goodm: view %s: failed to delete %s: %w", v.Collection, id, err)
+		}
+		return nil
+	}
+	if ev.FullDocument == nil {
+		return nil // deleted before the update was looked up; its delete event follows
+	}
+
+	doc, err := v.Map(ctx, ChangeEvent{Operation: ev.OperationType, ID: id, Document: ev.FullDocument})
+	if err != nil {
+		return fmt.Errorf("goodm: view %s: map %s: %w", v.Collection, id, err)
+	}
+	if doc == nil {
+		if _, err := view.DeleteOne(ctx, filter); err != nil {
+			return fmt.Errorf("goodm: view %s: failed to delete %s: %w", v.Collection, id, err)
+		}
+		return nil
+	}
+	if _, err := view.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true)); err != nil {
+		return fmt.Errorf("goodm: view %s: failed to write %s: %w", v.Collection, id, err)
+	}
+	return nil
+}
+
+// saveViewCheckpoint records the view's resume token.
+func saveViewCheckpoint(ctx context.Context, checkpoints *mongo.Collection, view string, token bson.Raw) error {
+	if token == nil {
+		return nil
+	}
+	_, err := checkpoints.ReplaceOne(ctx, bson.D{{Key: "_id", Value: view}},
+		viewCheckpoint{View: view, ResumeToken: token, UpdatedAt: time.Now()},
+		options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("goodm: view %s: failed to save checkpoint: %w", view, err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dwoolworth/goodm/internal/memstore"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func userNameView(ctx context.Context, ev ChangeEvent) (interface{}, error) {
+	var u testUser
+	if err := ev.Decode(&u); err != nil {
+		return nil, err
+	}
+	if u.Role == "hidden" {
+		return nil, nil
+	}
+	return bson.M{"name": u.Name, "op": ev.Operation}, nil
+}
+
+func TestStartView_Validation(t *testing.T) {
+	useTestStore(t)
+	tests := map[string]struct {
+		view View
+		want string
+	}{
+		"no collection": {View{Source: &testUser{}, Map: userNameView}, "requires a Collection"},
+		"no map":        {View{Collection: "user_names", Source: &testUser{}}, "requires a Map"},
+		"unregistered":  {View{Collection: "user_names", Source: &struct{ Model }{}, Map: userNameView}, "not registered"},
+		"own source":    {View{Collection: "test_users", Source: &testUser{}, Map: userNameView}, "its own source"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := StartView(context.Background(), tt.view)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestStartView_TestStoreUnsupported(t *testing.T) {
+	useTestStore(t)
+	_, err := StartView(context.Background(), View{Collection: "user_names", Source: &testUser{}, Map: userNameView})
+	if !errors.Is(err, memstore.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+// waitForView polls the view collection until check passes or times out.
+func waitForView(t *testing.T, check func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !check() {
+		if time.Now().After(deadline) {
+			t.Fatal("view did not catch up")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestStartView_Integration(t *testing.T) {
+	ctx, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	existing := &testUser{Email: "old@test.com", Name: "Old"}
+	if err := Create(ctx, existing); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	w, err := StartView(ctx, View{Collection: "user_names", Source: &testUser{}, Map: userNameView})
+	if err != nil {
+		t.Fatalf("StartView: %v", err)
+	}
+	defer w.Stop()
+
+	view := db.Collection("user_names")
+	viewDoc := func(id bson.ObjectID) bson.M {
+		var doc bson.M
+		if err := view.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&doc); err != nil {
+			return nil
+		}
+		return doc
+	}
+	waitForView(t, func() bool {
+		if err := w.Err(); err != nil {
+			t.Skipf("change streams not available: %v", err)
+		}
+		doc := viewDoc(existing.ID)
+		return doc != nil && doc["op"] == "backfill"
+	})
+
+	created := &testUser{Email: "new@test.com", Name: "New"}
+	if err := Create(ctx, created); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	waitForView(t, func() bool { return viewDoc(created.ID) != nil })
+
+	if err := Delete(ctx, existing); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	waitForView(t, func() bool { return viewDoc(existing.ID) == nil })
+
+	if err := w.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	var cp viewCheckpoint
+	if err := db.Collection(DefaultViewCheckpoints).FindOne(ctx, bson.D{{Key: "_id", Value: "user_names"}}).Decode(&cp); err != nil {
+		t.Fatalf("expected a checkpoint: %v", err)
+	}
+	if cp.ResumeToken == nil {
+		t.Error("checkpoint has no resume token")
+	}
+}
@@ -0,0 +1,32 @@
+package goodm
+
+import (
+	"context"
+	"reflect"
+)
+
+// computeVirtuals calls ComputeVirtuals on a decoded result. target may be a
+// pointer to a struct or a pointer to a slice of structs or struct pointers.
+func computeVirtuals(ctx context.Context, target interface{}) error {
+	if cv, ok := target.(ComputeVirtuals); ok {
+		return cv.ComputeVirtuals(ctx)
+	}
+
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return nil
+	}
+	sv := rv.Elem()
+	for i := 0; i < sv.Len(); i++ {
+		elem := sv.Index(i)
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			continue
+		}
+		if cv, ok := elemModel(elem).(ComputeVirtuals); ok {
+			if err := cv.ComputeVirtuals(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type testPerson struct {
+	Model    `bson:",inline"`
+	First    string `bson:"first" goodm:"required"`
+	Last     string `bson:"last"`
+	FullName string `bson:"-"`
+}
+
+func (p *testPerson) ComputeVirtuals(ctx context.Context) error {
+	if p.First == "fail" {
+		return errors.New("compute failed")
+	}
+	p.FullName = p.First + " " + p.Last
+	return nil
+}
+
+func TestComputeVirtuals_Single(t *testing.T) {
+	p := &testPerson{First: "Ada", Last: "Lovelace"}
+	if err := computeVirtuals(context.Background(), p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.FullName != "Ada Lovelace" {
+		t.Fatalf("expected Ada Lovelace, got %q", p.FullName)
+	}
+}
+
+func TestComputeVirtuals_Slices(t *testing.T) {
+	people := []testPerson{{First: "A", Last: "B"}, {First: "C", Last: "D"}}
+	if err := computeVirtuals(context.Background(), &people); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if people[0].FullName != "A B" || people[1].FullName != "C D" {
+		t.Fatalf("unexpected virtuals: %+v", people)
+	}
+
+	ptrs := []*testPerson{{First: "E", Last: "F"}, nil}
+	if err := computeVirtuals(context.Background(), &ptrs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ptrs[0].FullName != "E F" {
+		t.Fatalf("expected E F, got %q", ptrs[0].FullName)
+	}
+}
+
+func TestComputeVirtuals_Error(t *testing.T) {
+	people := []testPerson{{First: "fail"}}
+	if err := computeVirtuals(context.Background(), &people); err == nil {
+		t.Fatal("expected error from ComputeVirtuals")
+	}
+}
+
+func TestComputeVirtuals_NotImplemented(t *testing.T) {
+	users := []testUser{{Name: "x"}}
+	if err := computeVirtuals(context.Background(), &users); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// --- integration tests (require MongoDB) ---
+
+func TestFind_ComputesVirtuals(t *testing.T) {
+	ctx, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := Register(&testPerson{}, "test_people"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "testPerson")
+		registryMu.Unlock()
+	}()
+
+	if err := Create(ctx, &testPerson{First: "Grace", Last: "Hopper"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	found := &testPerson{}
+	if err := FindOne(ctx, bson.D{{Key: "first", Value: "Grace"}}, found); err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	if found.FullName != "Grace Hopper" {
+		t.Fatalf("expected Grace Hopper, got %q", found.FullName)
+	}
+
+	var all []testPerson
+	if err := Find(ctx, bson.D{}, &all); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(all) != 1 || all[0].FullName != "Grace Hopper" {
+		t.Fatalf("expected virtuals on Find results, got %+v", all)
+	}
+}
@@ -0,0 +1,143 @@
+package goodm
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// WindowFields describes a $setWindowFields stage: documents are grouped into
+// partitions, ordered within each partition, and each output field is
+// computed over a window of neighboring documents.
+//
+// Example:
+//
+//	goodm.NewPipeline(&Reading{}).SetWindowFields(goodm.WindowFields{
+//	    PartitionBy: "sensor",
+//	    SortBy:      bson.D{{Key: "at", Value: 1}},
+//	    Output: []goodm.WindowOutput{
+//	        goodm.MovingAverage("avg_temp", "temp", 3),
+//	        goodm.Derivative("rate", "temp", "hour"),
+//	    },
+//	})
+type WindowFields struct {
+	// PartitionBy groups documents into partitions. A string names a field
+	// and is prefixed with "$"; any other value is used as an expression.
+	// Nil puts all documents in one partition.
+	PartitionBy interface{}
+
+	// SortBy orders documents within each partition. Required by rank
+	// operators and document windows.
+	SortBy bson.D
+
+	// Output lists the computed fields.
+	Output []WindowOutput
+}
+
+// WindowOutput is one computed field of a $setWindowFields stage.
+type WindowOutput struct {
+	Field    string        // output field name
+	Operator string        // window operator, e.g. "$avg" or "$rank"
+	Arg      interface{}   // operator argument; nil for operators that take none
+	Window   *WindowBounds // documents the operator sees; nil for the whole partition
+}
+
+// WindowBounds limits a window operator to a range of documents around the
+// current one. Bounds are integers relative to the current document, or
+// "current" and "unbounded".
+type WindowBounds struct {
+	Documents []interface{} // [lower, upper] positions in sort order
+	Range     []interface{} // [lower, upper] offsets of the SortBy field's value
+	Unit      string        // time unit of Range for date sort fields, e.g. "hour"
+}
+
+// Over returns a copy of o computed over the given window.
+func (o WindowOutput) Over(w WindowBounds) WindowOutput {
+	o.Window = &w
+	return o
+}
+
+// Rank outputs the document's rank in its partition, with gaps after ties.
+func Rank(field string) WindowOutput {
+	return WindowOutput{Field: field, Operator: "$rank"}
+}
+
+// DenseRank outputs the document's rank in its partition, without gaps after
+// ties.
+func DenseRank(field string) WindowOutput {
+	return WindowOutput{Field: field, Operator: "$denseRank"}
+}
+
+// DocumentNumber outputs the document's position in its partition, from 1.
+func DocumentNumber(field string) WindowOutput {
+	return WindowOutput{Field: field, Operator: "$documentNumber"}
+}
+
+// MovingAverage outputs the average of input over the current document and
+// the n-1 documents before it.
+func MovingAverage(field, input string, n int) WindowOutput {
+	return WindowOutput{
+		Field: field, Operator: "$avg", Arg: "$" + input,
+		Window: &WindowBounds{Documents: []interface{}{1 - n, "current"}},
+	}
+}
+
+// Derivative outputs the rate of change of input between the previous and
+// the current document, per unit of the date SortBy field (e.g. "hour").
+// An empty unit divides by the raw difference of a numeric sort field.
+func Derivative(field, input, unit string) WindowOutput {
+	arg := bson.D{{Key: "input", Value: "$" + input}}
+	if unit != "" {
+		arg = append(arg, bson.E{Key: "unit", Value: unit})
+	}
+	return WindowOutput{
+		Field: field, Operator: "$derivative", Arg: arg,
+		Window: &WindowBounds{Documents: []interface{}{-1, "current"}},
+	}
+}
+
+// SetWindowFields adds a $setWindowFields stage.
+func (p *Pipeline) SetWindowFields(w WindowFields) *Pipeline {
+	p.stages = append(p.stages, bson.D{{Key: "$setWindowFields", Value: w.spec()}})
+	return p
+}
+
+// spec returns the $setWindowFields stage document.
+func (w WindowFields) spec() bson.D {
+	var spec bson.D
+	switch by := w.PartitionBy.(type) {
+	case nil:
+	case string:
+		spec = append(spec, bson.E{Key: "partitionBy", Value: "$" + by})
+	default:
+		spec = append(spec, bson.E{Key: "partitionBy", Value: by})
+	}
+	if len(w.SortBy) > 0 {
+		spec = append(spec, bson.E{Key: "sortBy", Value: w.SortBy})
+	}
+
+	output := make(bson.D, 0, len(w.Output))
+	for _, o := range w.Output {
+		arg := o.Arg
+		if arg == nil {
+			arg = bson.D{}
+		}
+		field := bson.D{{Key: o.Operator, Value: arg}}
+		if o.Window != nil {
+			field = append(field, bson.E{Key: "window", Value: o.Window.spec()})
+		}
+		output = append(output, bson.E{Key: o.Field, Value: field})
+	}
+	return append(spec, bson.E{Key: "output", Value: output})
+}
+
+// spec returns the window document of a window operator.
+func (b WindowBounds) spec() bson.D {
+	var spec bson.D
+	if b.Documents != nil {
+		spec = append(spec, bson.E{Key: "documents", Value: bson.A(b.Documents)})
+	}
+	if b.Range != nil {
+		spec = append(spec, bson.E{Key: "range", Value: bson.A(b.Range)})
+	}
+	if b.Unit != "" {
+		spec = append(spec, bson.E{Key: "unit", Value: b.Unit})
+	}
+	return spec
+}
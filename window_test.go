@@ -0,0 +1,60 @@
+package goodm
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestPipeline_SetWindowFields(t *testing.T) {
+	p := NewPipeline(&testUser{}).SetWindowFields(WindowFields{
+		PartitionBy: "role",
+		SortBy:      bson.D{{Key: "age", Value: 1}},
+		Output: []WindowOutput{
+			Rank("rank"),
+			MovingAverage("avg_age", "age", 3),
+			Derivative("rate", "age", "hour"),
+		},
+	})
+
+	want := bson.D{{Key: "$setWindowFields", Value: bson.D{
+		{Key: "partitionBy", Value: "$role"},
+		{Key: "sortBy", Value: bson.D{{Key: "age", Value: 1}}},
+		{Key: "output", Value: bson.D{
+			{Key: "rank", Value: bson.D{{Key: "$rank", Value: bson.D{}}}},
+			{Key: "avg_age", Value: bson.D{
+				{Key: "$avg", Value: "$age"},
+				{Key: "window", Value: bson.D{{Key: "documents", Value: bson.A{-2, "current"}}}},
+			}},
+			{Key: "rate", Value: bson.D{
+				{Key: "$derivative", Value: bson.D{{Key: "input", Value: "$age"}, {Key: "unit", Value: "hour"}}},
+				{Key: "window", Value: bson.D{{Key: "documents", Value: bson.A{-1, "current"}}}},
+			}},
+		}},
+	}}}
+	if got := p.Stages()[0]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected stage:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestWindowFields_ExpressionPartitionAndRange(t *testing.T) {
+	partition := bson.D{{Key: "$year", Value: "$created_at"}}
+	out := WindowOutput{Field: "total", Operator: "$sum", Arg: "$age"}.
+		Over(WindowBounds{Range: []interface{}{-7, 0}, Unit: "day"})
+
+	spec := WindowFields{PartitionBy: partition, Output: []WindowOutput{out, DenseRank("dense")}}.spec()
+	want := bson.D{
+		{Key: "partitionBy", Value: partition},
+		{Key: "output", Value: bson.D{
+			{Key: "total", Value: bson.D{
+				{Key: "$sum", Value: "$age"},
+				{Key: "window", Value: bson.D{{Key: "range", Value: bson.A{-7, 0}}, {Key: "unit", Value: "day"}}},
+			}},
+			{Key: "dense", Value: bson.D{{Key: "$denseRank", Value: bson.D{}}}},
+		}},
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Fatalf("unexpected spec:\n got %v\nwant %v", spec, want)
+	}
+}
@@ -0,0 +1,55 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+)
+
+// Worker is a background job run by goodm, such as a materialized view
+// started with StartView. It runs until its context is canceled, Stop is
+// called, or it fails.
+type Worker struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// startWorker runs fn in a new goroutine with a cancelable child of ctx.
+func startWorker(ctx context.Context, fn func(ctx context.Context) error) *Worker {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &Worker{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		defer cancel()
+		err := fn(ctx)
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			err = nil
+		}
+		w.err = err
+	}()
+	return w
+}
+
+// Stop cancels the worker, waits for it to exit, and returns the error that
+// ended it, if any. Stopping is not an error.
+func (w *Worker) Stop() error {
+	w.cancel()
+	<-w.done
+	return w.err
+}
+
+// Done returns a channel that is closed when the worker has exited.
+func (w *Worker) Done() <-chan struct{} {
+	return w.done
+}
+
+// Err returns the error that ended the worker, or nil while it is running or
+// if it was stopped.
+func (w *Worker) Err() error {
+	select {
+	case <-w.done:
+		return w.err
+	default:
+		return nil
+	}
+}
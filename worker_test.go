@@ -0,0 +1,57 @@
+package goodm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorker_Stop(t *testing.T) {
+	w := startWorker(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := w.Err(); err != nil {
+		t.Fatalf("Err while running = %v", err)
+	}
+	if err := w.Stop(); err != nil {
+		t.Fatalf("Stop = %v, want nil", err)
+	}
+	select {
+	case <-w.Done():
+	default:
+		t.Fatal("Done not closed after Stop")
+	}
+}
+
+func TestWorker_Error(t *testing.T) {
+	errBoom := errors.New("boom")
+	w := startWorker(context.Background(), func(ctx context.Context) error {
+		return errBoom
+	})
+	select {
+	case <-w.Done():
+	case <-time.After(time.Second):
+		t.Fatal("worker did not exit")
+	}
+	if !errors.Is(w.Err(), errBoom) {
+		t.Errorf("Err = %v, want boom", w.Err())
+	}
+	if !errors.Is(w.Stop(), errBoom) {
+		t.Error("Stop did not return the worker's error")
+	}
+}
+
+func TestWorker_ParentCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := startWorker(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cancel()
+	<-w.Done()
+	if err := w.Err(); err != nil {
+		t.Errorf("Err = %v, want nil after cancellation", err)
+	}
+}